@@ -31,6 +31,22 @@ canary:
   {{- printf "\n" -}}
 {{- end -}}
 
+{{/*
+This template lists the message queue types with a trigger deployed, comma separated, for the controller to
+advertise to the CLI.
+*/}}
+{{- define "messageQueueTypes" -}}
+{{- $types := list -}}
+{{- if .Values.nats.enabled }}{{ $types = append $types "nats-streaming" }}{{ end -}}
+{{- if .Values.azureStorageQueue.enabled }}{{ $types = append $types "azure-storage-queue" }}{{ end -}}
+{{- if .Values.kafka.enabled }}{{ $types = append $types "kafka" }}{{ end -}}
+{{- if .Values.rabbitmq.enabled }}{{ $types = append $types "rabbitmq" }}{{ end -}}
+{{- if .Values.gcpPubSub.enabled }}{{ $types = append $types "gcp-pubsub" }}{{ end -}}
+{{- if .Values.azureServiceBus.enabled }}{{ $types = append $types "azure-servicebus" }}{{ end -}}
+{{- if .Values.natsJetStream.enabled }}{{ $types = append $types "nats-jetstream" }}{{ end -}}
+{{- join "," $types -}}
+{{- end -}}
+
 {{/*
 This template generates the image name for the deployment depending on the value of "repository" field in values.yaml file.
 */}}
@@ -24,6 +24,7 @@ import (
 	"strconv"
 
 	"contrib.go.opencensus.io/exporter/jaeger"
+	"contrib.go.opencensus.io/exporter/ocagent"
 	docopt "github.com/docopt/docopt-go"
 	"go.opencensus.io/trace"
 	"go.uber.org/zap"
@@ -33,6 +34,7 @@ import (
 	"github.com/fission/fission/pkg/executor"
 	"github.com/fission/fission/pkg/info"
 	"github.com/fission/fission/pkg/kubewatcher"
+	"github.com/fission/fission/pkg/logforwarder"
 	functionLogger "github.com/fission/fission/pkg/logger"
 	messagequeue "github.com/fission/fission/pkg/mqtrigger"
 	"github.com/fission/fission/pkg/router"
@@ -47,7 +49,8 @@ func runController(logger *zap.Logger, port int) {
 
 func runRouter(logger *zap.Logger, port int, executorUrl string) {
 	router.Start(logger, port, executorUrl)
-	logger.Fatal("router exited")
+	logger.Info("router shut down")
+	os.Exit(0)
 }
 
 func runExecutor(logger *zap.Logger, port int, fissionNamespace, functionNamespace, envBuilderNamespace string) {
@@ -88,8 +91,8 @@ func runStorageSvc(logger *zap.Logger, port int, filePath string) {
 		filePath, subdir, port, enableArchivePruner)
 }
 
-func runBuilderMgr(logger *zap.Logger, storageSvcUrl string, envBuilderNamespace string) {
-	err := buildermgr.Start(logger, storageSvcUrl, envBuilderNamespace)
+func runBuilderMgr(logger *zap.Logger, storageSvcUrl string, executorUrl string, routerUrl string, envBuilderNamespace string) {
+	err := buildermgr.Start(logger, storageSvcUrl, executorUrl, routerUrl, envBuilderNamespace)
 	if err != nil {
 		logger.Fatal("error starting builder manager", zap.Error(err))
 	}
@@ -100,6 +103,15 @@ func runLogger() {
 	log.Fatalf("Error: Logger exited.")
 }
 
+func runLogForwarder(logger *zap.Logger) {
+	err := logforwarder.Start(logger)
+	if err != nil {
+		logger.Fatal("error starting log forwarder", zap.Error(err))
+	}
+	logger.Info("log forwarder shut down")
+	os.Exit(0)
+}
+
 func getPort(logger *zap.Logger, portArg interface{}) int {
 	portArgStr := portArg.(string)
 	port, err := strconv.Atoi(portArgStr)
@@ -119,7 +131,8 @@ func getStringArgWithDefault(arg interface{}, defaultValue string) string {
 
 func registerTraceExporter(logger *zap.Logger, arguments map[string]interface{}) error {
 	collectorEndpoint := getStringArgWithDefault(arguments["--collectorEndpoint"], "")
-	if collectorEndpoint == "" {
+	otelCollectorEndpoint := getStringArgWithDefault(arguments["--otelCollectorEndpoint"], "")
+	if collectorEndpoint == "" && otelCollectorEndpoint == "" {
 		logger.Info("skipping trace exporter registration")
 		return nil
 	}
@@ -144,24 +157,40 @@ func registerTraceExporter(logger *zap.Logger, arguments map[string]interface{})
 		serviceName = "Fission-StorageSvc"
 	}
 
-	exporter, err := jaeger.NewExporter(jaeger.Options{
-		CollectorEndpoint: collectorEndpoint,
-		Process: jaeger.Process{
-			ServiceName: serviceName,
-			Tags: []jaeger.Tag{
-				jaeger.BoolTag("fission", true),
-			},
-		},
-	})
+	samplingRate, err := strconv.ParseFloat(os.Getenv("TRACING_SAMPLING_RATE"), 32)
 	if err != nil {
 		return err
 	}
 
-	samplingRate, err := strconv.ParseFloat(os.Getenv("TRACING_SAMPLING_RATE"), 32)
-	if err != nil {
-		return err
+	if collectorEndpoint != "" {
+		exporter, err := jaeger.NewExporter(jaeger.Options{
+			CollectorEndpoint: collectorEndpoint,
+			Process: jaeger.Process{
+				ServiceName: serviceName,
+				Tags: []jaeger.Tag{
+					jaeger.BoolTag("fission", true),
+				},
+			},
+		})
+		if err != nil {
+			return err
+		}
+		trace.RegisterExporter(exporter)
+	}
+
+	// The OpenTelemetry Collector receives the spans with its opencensus receiver
+	// and exports them to the configured tracing backends.
+	if otelCollectorEndpoint != "" {
+		exporter, err := ocagent.NewExporter(
+			ocagent.WithInsecure(),
+			ocagent.WithAddress(otelCollectorEndpoint),
+			ocagent.WithServiceName(serviceName))
+		if err != nil {
+			return err
+		}
+		trace.RegisterExporter(exporter)
 	}
-	trace.RegisterExporter(exporter)
+
 	trace.ApplyConfig(trace.Config{DefaultSampler: trace.ProbabilitySampler(samplingRate)})
 	return nil
 }
@@ -197,17 +226,19 @@ Use it to start one or more of the fission servers:
 
 Usage:
   fission-bundle --controllerPort=<port> [--collectorEndpoint=<url>]
-  fission-bundle --routerPort=<port> [--executorUrl=<url>] [--collectorEndpoint=<url>]
-  fission-bundle --executorPort=<port> [--namespace=<namespace>] [--fission-namespace=<namespace>] [--collectorEndpoint=<url>]
+  fission-bundle --routerPort=<port> [--executorUrl=<url>] [--collectorEndpoint=<url>] [--otelCollectorEndpoint=<address>]
+  fission-bundle --executorPort=<port> [--namespace=<namespace>] [--fission-namespace=<namespace>] [--collectorEndpoint=<url>] [--otelCollectorEndpoint=<address>]
   fission-bundle --kubewatcher [--routerUrl=<url>] [--collectorEndpoint=<url>]
   fission-bundle --storageServicePort=<port> --filePath=<filePath> [--collectorEndpoint=<url>]
-  fission-bundle --builderMgr [--storageSvcUrl=<url>] [--envbuilder-namespace=<namespace>] [--collectorEndpoint=<url>]
+  fission-bundle --builderMgr [--storageSvcUrl=<url>] [--executorUrl=<url>] [--routerUrl=<url>] [--envbuilder-namespace=<namespace>] [--collectorEndpoint=<url>] [--otelCollectorEndpoint=<address>]
   fission-bundle --timer [--routerUrl=<url>] [--collectorEndpoint=<url>]
   fission-bundle --mqt   [--routerUrl=<url>] [--collectorEndpoint=<url>]
   fission-bundle --logger
+  fission-bundle --logForwarder
   fission-bundle --version
 Options:
   --collectorEndpoint=<url> Jaeger HTTP Thrift collector URL.
+  --otelCollectorEndpoint=<address> OpenTelemetry Collector address of the opencensus receiver, e.g. otel-collector:55678.
   --controllerPort=<port>         Port that the controller should listen on.
   --routerPort=<port>             Port that the router should listen on.
   --executorPort=<port>           Port that the executor should listen on.
@@ -222,6 +253,7 @@ Options:
   --timer                         Start Timer.
   --mqt                           Start message queue trigger.
   --builderMgr                    Start builder manager.
+  --logForwarder                  Start the log forwarder sidecar of a function pod.
   --version                       Print version information
 `
 
@@ -288,13 +320,17 @@ Options:
 	}
 
 	if arguments["--builderMgr"] == true {
-		runBuilderMgr(logger, storageSvcUrl, envBuilderNs)
+		runBuilderMgr(logger, storageSvcUrl, executorUrl, routerUrl, envBuilderNs)
 	}
 
 	if arguments["--logger"] == true {
 		runLogger()
 	}
 
+	if arguments["--logForwarder"] == true {
+		runLogForwarder(logger)
+	}
+
 	if arguments["--storageServicePort"] != nil {
 		port := getPort(logger, arguments["--storageServicePort"])
 		filePath := arguments["--filePath"].(string)
@@ -1,13 +1,20 @@
 package main
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
+	"io"
 	"io/ioutil"
 	"log"
+	"net"
 	"net/http"
+	// profiles are captured by the executor, see `fission fn profile`
+	_ "net/http/pprof"
 	"os"
 	"path/filepath"
 	"plugin"
+	"sync"
 
 	"github.com/pkg/errors"
 	"go.uber.org/zap"
@@ -17,6 +24,19 @@ import (
 
 const (
 	CODE_PATH = "/userfunc/user"
+
+	// RUNTIME_SOCKET_ENV is the path of the unix socket to serve the
+	// specialize request of fetcher on, set by the executor.
+	RUNTIME_SOCKET_ENV = "FISSION_RUNTIME_SOCKET"
+
+	// CHECKSUM_HEADER carries the SHA-256 of the loaded file back to
+	// fetcher.
+	CHECKSUM_HEADER = "X-Fission-Checksum"
+
+	// FUNCTION_NAME_HEADER and FUNCTION_NAMESPACE_HEADER are set by the
+	// router, they pick the function served by a multiplexed pod.
+	FUNCTION_NAME_HEADER      = "X-Fission-Function-Name"
+	FUNCTION_NAMESPACE_HEADER = "X-Fission-Function-Namespace"
 )
 
 type (
@@ -35,11 +55,71 @@ type (
 		// URL to expose this function at. Optional; defaults
 		// to "/".
 		URL string `json:"url"`
+
+		// Metadata of the function, used to dispatch requests
+		// to functions loaded into a multiplexed pod.
+		FunctionMetadata *FunctionMetadata
+
+		// Checksum of the file fetched into FilePath, the SHA-256 of
+		// the file is returned in CHECKSUM_HEADER if set. Optional.
+		Checksum *Checksum `json:"checksum,omitempty"`
+	}
+
+	FunctionMetadata struct {
+		Name      string `json:"name"`
+		Namespace string `json:"namespace"`
+	}
+
+	Checksum struct {
+		Type string `json:"type"`
+		Sum  string `json:"sum"`
 	}
 )
 
 var userFunc http.HandlerFunc
 
+// multiplexed holds the entrypoints loaded by /v3/load, by
+// "namespace/name" of the function.
+var multiplexed = struct {
+	sync.RWMutex
+	funcs map[string]http.HandlerFunc
+}{funcs: make(map[string]http.HandlerFunc)}
+
+func functionKey(namespace, name string) string {
+	return namespace + "/" + name
+}
+
+// handlerFor returns the entrypoint loaded for the function a request is
+// for, or the function the pod was specialized for.
+func handlerFor(r *http.Request) http.HandlerFunc {
+	name := r.Header.Get(FUNCTION_NAME_HEADER)
+	if len(name) == 0 {
+		return userFunc
+	}
+	multiplexed.RLock()
+	defer multiplexed.RUnlock()
+	if h, ok := multiplexed.funcs[functionKey(r.Header.Get(FUNCTION_NAMESPACE_HEADER), name)]; ok {
+		return h
+	}
+	return userFunc
+}
+
+// fileChecksum returns the hex encoded SHA-256 of the file at path.
+func fileChecksum(path string) (string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", errors.Wrap(err, "error opening code path")
+	}
+	defer f.Close()
+
+	h := sha256.New()
+	_, err = io.Copy(h, f)
+	if err != nil {
+		return "", errors.Wrap(err, "error reading code path")
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
 func loadPlugin(logger *zap.Logger, codePath, entrypoint string) (http.HandlerFunc, error) {
 
 	// if codepath's a directory, load the file inside it
@@ -168,6 +248,21 @@ func specializeHandlerV2(logger *zap.Logger) func(http.ResponseWriter, *http.Req
 			}
 		}
 
+		// hash the file as it is loaded, fetcher compares the digest
+		// with the one of the file it fetched
+		var sum string
+		if loadreq.Checksum != nil {
+			sum, err = fileChecksum(loadreq.FilePath)
+			if err != nil {
+				logger.Error("error computing checksum of code path",
+					zap.Error(err),
+					zap.String("code_path", loadreq.FilePath))
+				w.WriteHeader(http.StatusInternalServerError)
+				w.Write([]byte(err.Error()))
+				return
+			}
+		}
+
 		logger.Info("specializing ...")
 		userFunc, err = loadPlugin(logger, loadreq.FilePath, loadreq.FunctionName)
 		if err != nil {
@@ -177,10 +272,54 @@ func specializeHandlerV2(logger *zap.Logger) func(http.ResponseWriter, *http.Req
 			w.Write([]byte(errors.Wrap(err, e).Error()))
 			return
 		}
+		if len(sum) > 0 {
+			w.Header().Set(CHECKSUM_HEADER, sum)
+		}
 		logger.Info("done")
 	}
 }
 
+// loadHandlerV3 loads the entrypoint of another function from the package
+// the pod was specialized with, so that the pod serves more functions.
+func loadHandlerV3(logger *zap.Logger) func(http.ResponseWriter, *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if userFunc == nil {
+			w.WriteHeader(http.StatusBadRequest)
+			w.Write([]byte("Not specialized"))
+			return
+		}
+
+		body, err := ioutil.ReadAll(r.Body)
+		if err != nil {
+			logger.Error("error reading request body", zap.Error(err))
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		var loadreq FunctionLoadRequest
+		err = json.Unmarshal(body, &loadreq)
+		if err != nil || loadreq.FunctionMetadata == nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+
+		key := functionKey(loadreq.FunctionMetadata.Namespace, loadreq.FunctionMetadata.Name)
+		logger.Info("loading function ...", zap.String("function", key))
+		h, err := loadPlugin(logger, loadreq.FilePath, loadreq.FunctionName)
+		if err != nil {
+			e := "error loading function"
+			logger.Error(e, zap.Error(err), zap.String("function", key))
+			w.WriteHeader(http.StatusInternalServerError)
+			w.Write([]byte(errors.Wrap(err, e).Error()))
+			return
+		}
+
+		multiplexed.Lock()
+		multiplexed.funcs[key] = h
+		multiplexed.Unlock()
+		logger.Info("done", zap.String("function", key))
+	}
+}
+
 func readinessProbeHandler(w http.ResponseWriter, r *http.Request) {
 	w.WriteHeader(http.StatusOK)
 }
@@ -195,6 +334,7 @@ func main() {
 	http.HandleFunc("/healthz", readinessProbeHandler)
 	http.HandleFunc("/specialize", specializeHandler(logger.Named("specialize_handler")))
 	http.HandleFunc("/v2/specialize", specializeHandlerV2(logger.Named("specialize_v2_handler")))
+	http.HandleFunc("/v3/load", loadHandlerV3(logger.Named("load_v3_handler")))
 
 	// Generic route -- all http requests go to the user function.
 	http.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
@@ -203,9 +343,21 @@ func main() {
 			w.Write([]byte("Generic container: no requests supported"))
 			return
 		}
-		userFunc(w, r)
+		withUsage(handlerFor(r))(w, r)
 	})
 
+	// fetcher prefers the unix socket in the shared volume for specialization
+	if socketPath := os.Getenv(RUNTIME_SOCKET_ENV); len(socketPath) > 0 {
+		os.Remove(socketPath)
+		listener, err := net.Listen("unix", socketPath)
+		if err != nil {
+			logger.Error("error listening on runtime socket, only listening on 8888", zap.Error(err), zap.String("socket", socketPath))
+		} else {
+			logger.Info("listening on runtime socket ...", zap.String("socket", socketPath))
+			go http.Serve(listener, nil)
+		}
+	}
+
 	logger.Info("listening on 8888 ...")
 	http.ListenAndServe(":8888", nil)
 }
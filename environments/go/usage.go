@@ -0,0 +1,56 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"sync/atomic"
+	"syscall"
+	"time"
+)
+
+// USAGE_CPU_TRAILER reports the CPU time of an invocation to the router,
+// which aggregates it into the usage metrics of the function.
+const USAGE_CPU_TRAILER = "X-Fission-Usage-Cpu-Seconds"
+
+var (
+	// running and started count the invocations, to tell whether an
+	// invocation ran alone
+	running int32
+	started uint64
+)
+
+func processCPUTime() (time.Duration, error) {
+	var ru syscall.Rusage
+	err := syscall.Getrusage(syscall.RUSAGE_SELF, &ru)
+	if err != nil {
+		return 0, err
+	}
+	return time.Duration(ru.Utime.Nano() + ru.Stime.Nano()), nil
+}
+
+// withUsage reports the CPU time of the invocations of h in a trailer. The
+// CPU time of the process can only be attributed to an invocation that ran
+// alone, concurrent invocations aren't reported.
+func withUsage(h http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		defer atomic.AddInt32(&running, -1)
+		alone := atomic.AddInt32(&running, 1) == 1
+		gen := atomic.AddUint64(&started, 1)
+
+		before, err := processCPUTime()
+		if !alone || err != nil {
+			h(w, r)
+			return
+		}
+
+		// the trailer has to be announced before the body is written
+		w.Header().Set("Trailer", USAGE_CPU_TRAILER)
+
+		h(w, r)
+
+		after, err := processCPUTime()
+		if err == nil && atomic.LoadUint64(&started) == gen {
+			w.Header().Set(USAGE_CPU_TRAILER, fmt.Sprintf("%.6f", (after-before).Seconds()))
+		}
+	}
+}
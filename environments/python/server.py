@@ -4,7 +4,7 @@ import logging
 import os
 import sys
 
-from flask import Flask, request, abort, g
+from flask import Flask, request, abort, g, has_request_context
 from gevent.pywsgi import WSGIServer
 import bjoern
 
@@ -12,6 +12,16 @@ import bjoern
 IS_PY2 = (sys.version_info.major == 2)
 
 
+class RequestIdFilter(logging.Filter):
+    # Tag log lines with the request ID set by the router, so that
+    # `fission fn logs --reqid` finds all logs of an invocation.
+    def filter(self, record):
+        record.request_id = '-'
+        if has_request_context():
+            record.request_id = request.headers.get('X-Fission-Request-Id', '-')
+        return True
+
+
 def import_src(path):
     if IS_PY2:
         import imp
@@ -37,8 +47,9 @@ class FuncApp(Flask):
         #
         self.root.setLevel(loglevel)
         self.ch.setLevel(loglevel)
+        self.ch.addFilter(RequestIdFilter())
         self.ch.setFormatter(logging.Formatter(
-            '%(asctime)s - %(levelname)s - %(message)s'))
+            '%(asctime)s - %(levelname)s - request_id=%(request_id)s - %(message)s'))
         self.logger.addHandler(self.ch)
 
         #
@@ -22,6 +22,20 @@ const (
 	DEFAULT_FUNCTION_TIMEOUT  int    = 60
 )
 
+const (
+	// HTTPMethodAny makes a HTTP trigger serve requests of any method.
+	HTTPMethodAny = "*"
+)
+
+const (
+	// HTTPTriggerProtocolHTTP proxies the requests of a HTTP trigger with
+	// HTTP/1.1 to the function pod.
+	HTTPTriggerProtocolHTTP = "http"
+	// HTTPTriggerProtocolGRPC proxies the requests of a HTTP trigger with
+	// HTTP/2 to the function pod, keeping gRPC streams open end-to-end.
+	HTTPTriggerProtocolGRPC = "grpc"
+)
+
 const (
 	//LastUpdateTimestamp env variable is used for updating configmaps and secrets in pods
 	LastUpdateTimestamp string = "LASTUPDATE_TIMESTAMP"
@@ -38,6 +52,10 @@ const (
 
 	// ArchiveTypeUrl means the package contents are at the specified URL.
 	ArchiveTypeUrl ArchiveType = "url"
+
+	// ArchiveTypeGit means the package contents are in the Git repository
+	// at the specified URL, which is cloned at build time.
+	ArchiveTypeGit ArchiveType = "git"
 )
 
 const (
@@ -48,6 +66,16 @@ const (
 	BuildStatusNone      = "none"
 )
 
+const (
+	// PostBuildHookTypePublish uploads the deployment archive with a PUT
+	// request, e.g. to a raw repository of an artifact registry.
+	PostBuildHookTypePublish PostBuildHookType = "publish"
+
+	// PostBuildHookTypeWebhook posts the build event with the archive URL,
+	// checksum and SBOM as JSON.
+	PostBuildHookTypeWebhook PostBuildHookType = "webhook"
+)
+
 const (
 	AllowedFunctionsPerContainerSingle   = "single"
 	AllowedFunctionsPerContainerInfinite = "infinite"
@@ -56,12 +84,30 @@ const (
 const (
 	ExecutorTypePoolmgr   = "poolmgr"
 	ExecutorTypeNewdeploy = "newdeploy"
+	// ExecutorTypeContainer runs the image of the PodSpec of a function as
+	// a deployment, without an environment or a package.
+	ExecutorTypeContainer = "container"
 )
 
 const (
 	StrategyTypeExecution = "execution"
 )
 
+const (
+	IsolationModeShared     = "shared"
+	IsolationModeInvocation = "invocation"
+)
+
+const (
+	SpreadTopologyNode = "node"
+	SpreadTopologyZone = "zone"
+)
+
+const (
+	FunctionVolumeTypePVC      = "pvc"
+	FunctionVolumeTypeEmptyDir = "emptydir"
+)
+
 const (
 	SharedVolumeUserfunc   = "userfunc"
 	SharedVolumePackages   = "packages"
@@ -70,11 +116,42 @@ const (
 )
 
 const (
-	MessageQueueTypeNats  = "nats-streaming"
-	MessageQueueTypeASQ   = "azure-storage-queue"
-	MessageQueueTypeKafka = "kafka"
+	MessageQueueTypeNats            = "nats-streaming"
+	MessageQueueTypeASQ             = "azure-storage-queue"
+	MessageQueueTypeKafka           = "kafka"
+	MessageQueueTypeRabbitMQ        = "rabbitmq"
+	MessageQueueTypeGCPPubSub       = "gcp-pubsub"
+	MessageQueueTypeAzureServiceBus = "azure-servicebus"
+	MessageQueueTypeNatsJetStream   = "nats-jetstream"
+)
+
+const (
+	// RetryOn5xx retries the calls the function answered with a 5xx status.
+	RetryOn5xx = "5xx"
+
+	// RetryOnConnectFailure retries the calls that failed to connect to
+	// the function pod.
+	RetryOnConnectFailure = "connect-failure"
 )
 
+const (
+	// HTTPTriggerAuthJWT authenticates requests with a HMAC signed JSON
+	// Web Token in the Authorization header.
+	HTTPTriggerAuthJWT = "jwt"
+
+	// HTTPTriggerAuthAPIKey authenticates requests with an API key in
+	// the X-Fission-Api-Key header.
+	HTTPTriggerAuthAPIKey = "apikey"
+
+	// HTTPTriggerAuthJWTKey is the entry of the secret of a JWT trigger
+	// holding the key tokens are signed with.
+	HTTPTriggerAuthJWTKey = "key"
+)
+
+// APIVersionHeaderDefault is the request header with the API version of
+// HTTP triggers with API versioning.
+const APIVersionHeaderDefault = "X-API-Version"
+
 const (
 	// FunctionReferenceFunctionName means that the function
 	// reference is simply by function name.
@@ -82,6 +159,10 @@ const (
 
 	FunctionReferenceTypeFunctionWeights = "function-weights"
 
+	// FunctionReferenceTypeFunctionAlias means that the function
+	// reference is by the name of a function alias.
+	FunctionReferenceTypeFunctionAlias = "alias"
+
 	// Other function reference types we'd like to support:
 	//   Versioned function, latest version
 	//   Versioned function. by semver "latest compatible"
@@ -103,6 +184,25 @@ const (
 	MaxIterationsForCanaryConfig = 10
 )
 
+const (
+	// CanaryWebhookFormatJSON posts the canary config event as JSON.
+	CanaryWebhookFormatJSON CanaryWebhookFormat = "json"
+
+	// CanaryWebhookFormatSlack posts a Slack incoming webhook message.
+	CanaryWebhookFormatSlack CanaryWebhookFormat = "slack"
+)
+
 const (
 	DefaultSpecializationTimeOut = 120
 )
+
+const (
+	// AnnotationRunAfterBuild set to "true" on a function, or on a package for
+	// all of its functions, makes buildermgr invoke the function once after
+	// every successful build of the package.
+	AnnotationRunAfterBuild = "fission.io/run-after-build"
+
+	// AnnotationRunAfterBuildPath is the path under the function URL the
+	// function is invoked at after a build.
+	AnnotationRunAfterBuildPath = "fission.io/run-after-build-path"
+)
@@ -55,6 +55,12 @@ func (p *Package) GetObjectKind() schema.ObjectKind {
 func (c *CanaryConfig) GetObjectKind() schema.ObjectKind {
 	return &c.TypeMeta
 }
+func (a *FunctionAlias) GetObjectKind() schema.ObjectKind {
+	return &a.TypeMeta
+}
+func (p *CanaryPolicy) GetObjectKind() schema.ObjectKind {
+	return &p.TypeMeta
+}
 
 func (r *Recorder) GetObjectKind() schema.ObjectKind {
 	return &r.TypeMeta
@@ -84,6 +90,12 @@ func (p *Package) GetObjectMeta() metav1.Object {
 func (c *CanaryConfig) GetObjectMeta() metav1.Object {
 	return &c.Metadata
 }
+func (a *FunctionAlias) GetObjectMeta() metav1.Object {
+	return &a.Metadata
+}
+func (p *CanaryPolicy) GetObjectMeta() metav1.Object {
+	return &p.Metadata
+}
 
 func (r *Recorder) GetObjectMeta() metav1.Object {
 	return &r.Metadata
@@ -118,6 +130,14 @@ func (cl *CanaryConfigList) GetObjectKind() schema.ObjectKind {
 	return &cl.TypeMeta
 }
 
+func (al *FunctionAliasList) GetObjectKind() schema.ObjectKind {
+	return &al.TypeMeta
+}
+
+func (pl *CanaryPolicyList) GetObjectKind() schema.ObjectKind {
+	return &pl.TypeMeta
+}
+
 func (fl *FunctionList) GetListMeta() metav1.ListInterface {
 	return &fl.Metadata
 }
@@ -148,6 +168,14 @@ func (cl *CanaryConfigList) GetListMeta() metav1.ListInterface {
 	return &cl.Metadata
 }
 
+func (al *FunctionAliasList) GetListMeta() metav1.ListInterface {
+	return &al.Metadata
+}
+
+func (pl *CanaryPolicyList) GetListMeta() metav1.ListInterface {
+	return &pl.Metadata
+}
+
 func validateMetadata(field string, m metav1.ObjectMeta) error {
 	return ValidateKubeReference(field, m.Name, m.Namespace)
 }
@@ -244,6 +272,36 @@ func (kl *KubernetesWatchTriggerList) Validate() error {
 	return result
 }
 
+func (c *CanaryConfig) Validate() error {
+	result := &multierror.Error{}
+
+	result = multierror.Append(result,
+		validateMetadata("CanaryConfig", c.Metadata),
+		c.Spec.Validate())
+
+	return result.ErrorOrNil()
+}
+
+func (a *FunctionAlias) Validate() error {
+	result := &multierror.Error{}
+
+	result = multierror.Append(result,
+		validateMetadata("FunctionAlias", a.Metadata),
+		a.Spec.Validate())
+
+	return result.ErrorOrNil()
+}
+
+func (p *CanaryPolicy) Validate() error {
+	result := &multierror.Error{}
+
+	result = multierror.Append(result,
+		validateMetadata("CanaryPolicy", p.Metadata),
+		p.Spec.Validate())
+
+	return result.ErrorOrNil()
+}
+
 func (t *TimeTrigger) Validate() error {
 	result := &multierror.Error{}
 
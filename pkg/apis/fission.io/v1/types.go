@@ -20,6 +20,7 @@ import (
 	"time"
 
 	apiv1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
@@ -192,6 +193,45 @@ type (
 		Items []CanaryConfig `json:"items"`
 	}
 
+	// FunctionAlias is a named, weighted reference to one or more
+	// functions. Triggers referencing an alias follow it when the alias is
+	// updated, so shifting traffic doesn't require editing the triggers.
+	// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+	FunctionAlias struct {
+		metav1.TypeMeta `json:",inline"`
+		Metadata        metav1.ObjectMeta `json:"metadata"`
+		Spec            FunctionAliasSpec `json:"spec"`
+	}
+
+	// FunctionAliasList is a list of FunctionAliases.
+	// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+	FunctionAliasList struct {
+		metav1.TypeMeta `json:",inline"`
+		Metadata        metav1.ListMeta `json:"metadata"`
+
+		Items []FunctionAlias `json:"items"`
+	}
+
+	// CanaryPolicy makes the controller start a canary deployment whenever
+	// a function of its namespace that an HTTP trigger routes to is
+	// updated. The previous version of the function is kept as a snapshot
+	// and traffic is shifted from it to the updated function.
+	// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+	CanaryPolicy struct {
+		metav1.TypeMeta `json:",inline"`
+		Metadata        metav1.ObjectMeta `json:"metadata"`
+		Spec            CanaryPolicySpec  `json:"spec"`
+	}
+
+	// CanaryPolicyList is a list of CanaryPolicies.
+	// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+	CanaryPolicyList struct {
+		metav1.TypeMeta `json:",inline"`
+		Metadata        metav1.ListMeta `json:"metadata"`
+
+		Items []CanaryPolicy `json:"items"`
+	}
+
 	//
 	// Functions and packages
 	//
@@ -217,10 +257,11 @@ type (
 	// Package contains or references a collection of source or
 	// binary files.
 	Archive struct {
-		// Type defines how the package is specified: literal or URL.
+		// Type defines how the package is specified: literal, URL or Git.
 		// Available value:
 		//  - literal
 		//  - url
+		//  - git (source archives only)
 		Type ArchiveType `json:"type,omitempty"`
 
 		// Literal contents of the package. Can be used for
@@ -233,6 +274,22 @@ type (
 		// Checksum ensures the integrity of packages
 		// refereced by URL. Ignored for literals.
 		Checksum Checksum `json:"checksum,omitempty"`
+
+		// Git is the revision and credentials of a git archive, whose URL
+		// is the https or ssh URL of the repository.
+		Git *GitSource `json:"git,omitempty"`
+	}
+
+	// GitSource references a revision of a Git repository.
+	GitSource struct {
+		// Ref is the branch, tag or commit to check out, defaults to the
+		// default branch of the repository.
+		Ref string `json:"ref,omitempty"`
+
+		// Secret is a secret in the package namespace with the credentials
+		// to clone a private repository: the username and password keys for
+		// https, or the ssh-privatekey and optional known_hosts keys for ssh.
+		Secret string `json:"secret,omitempty"`
 	}
 
 	// EnvironmentReference is a reference to a environment.
@@ -272,6 +329,26 @@ type (
 		// BuildCommand is a custom build command that builder used to build the source archive.
 		BuildCommand string `json:"buildcmd,omitempty"`
 
+		// BuildSteps is a list of build steps run one after another in the same
+		// workspace, instead of the single build command. The build stops at the
+		// first failed step.
+		BuildSteps []BuildStep `json:"buildsteps,omitempty"`
+
+		// BuildEnv is the environment variables set in the builder
+		// container for the builds of this package only.
+		BuildEnv map[string]string `json:"buildenv,omitempty"`
+
+		// BuildSecrets are the secrets in the namespace of the package
+		// whose entries are set as environment variables of the builds,
+		// e.g. the tokens of private package registries. They are never
+		// stored in the builder image, the package or the build log.
+		BuildSecrets []string `json:"buildsecrets,omitempty"`
+
+		// PostBuildHooks are run by buildermgr in order after the
+		// deployment archive of a successful build is uploaded, to feed
+		// the artifact to release pipelines.
+		PostBuildHooks []PostBuildHook `json:"postbuildhooks,omitempty"`
+
 		// In the future, we can have a debug build here too
 	}
 
@@ -281,10 +358,112 @@ type (
 		BuildStatus BuildStatus `json:"buildstatus,omitempty"`
 
 		// BuildLog stores build log during the compilation.
+		// It may be truncated, the full log is referenced by BuildLogURL.
 		BuildLog string `json:"buildlog,omitempty"` // output of the build (errors etc)
 
+		// BuildLogURL is the storage service URL of the full, structured build log.
+		BuildLogURL string `json:"buildlogurl,omitempty"`
+
 		// LastUpdateTimestamp will store the timestamp the package was last updated
 		LastUpdateTimestamp time.Time `json:"lastUpdateTimestamp,omitempty"`
+
+		// BuildAttempts records each attempt of the latest build,
+		// including the ones automatically retried by buildermgr.
+		BuildAttempts []BuildAttempt `json:"buildattempts,omitempty"`
+
+		// BuildPod is the builder pod of the latest build, the logs of a
+		// running build are followed on it.
+		BuildPod *BuildPod `json:"buildpod,omitempty"`
+
+		// BuildSteps is the status of each build step of the latest build.
+		BuildSteps []BuildStepStatus `json:"buildsteps,omitempty"`
+	}
+
+	// BuildStep is a single step of a multi-step package build.
+	BuildStep struct {
+		// Name of the step, unique in the package.
+		Name string `json:"name"`
+
+		// Command is run by a shell in the source package directory, with the
+		// same environment variables as the build command.
+		Command string `json:"command"`
+
+		// Image the step runs with. The steps share the workspace of the
+		// builder pod, so the image must be the builder image of the
+		// environment, which is also the default.
+		Image string `json:"image,omitempty"`
+	}
+
+	// PostBuildHookType is what a post-build hook does with the artifact.
+	PostBuildHookType string
+
+	// PostBuildHook publishes the deployment archive of a package build or
+	// notifies a webhook about it.
+	PostBuildHook struct {
+		// Name of the hook, unique in the package.
+		Name string `json:"name"`
+
+		// Type is PostBuildHookTypePublish or PostBuildHookTypeWebhook.
+		Type PostBuildHookType `json:"type"`
+
+		// URL the archive is uploaded to or the webhook is posted to.
+		URL string `json:"url"`
+
+		// Secret is the name of a secret in the namespace of the package
+		// whose entries are set as headers of the hook requests, e.g. an
+		// Authorization header for the registry.
+		Secret string `json:"secret,omitempty"`
+
+		// SBOMPath is the path of a software bill of materials in the
+		// deployment archive, which is sent along with the webhook.
+		SBOMPath string `json:"sbompath,omitempty"`
+
+		// Required fails the build if the hook fails, otherwise the
+		// failure is only recorded in the build log.
+		Required bool `json:"required,omitempty"`
+	}
+
+	// BuildStepStatus is the result of a build step.
+	BuildStepStatus struct {
+		Name string `json:"name"`
+
+		// Status is the build status of the step, steps after a failed
+		// step aren't run and stay in pending state.
+		Status BuildStatus `json:"status"`
+
+		// Message is the error of a failed step.
+		Message string `json:"message,omitempty"`
+
+		StartTimestamp  time.Time `json:"startTimestamp,omitempty"`
+		FinishTimestamp time.Time `json:"finishTimestamp,omitempty"`
+	}
+
+	// BuildPod is the builder pod a package build runs on.
+	BuildPod struct {
+		Namespace string `json:"namespace"`
+		Name      string `json:"name"`
+
+		// StartTimestamp is the time the build started on the pod, the
+		// earlier logs of the pod belong to other builds.
+		StartTimestamp time.Time `json:"startTimestamp,omitempty"`
+	}
+
+	// BuildAttempt is the result of a single attempt to build a package.
+	BuildAttempt struct {
+		// Attempt is the 1-based number of the attempt.
+		Attempt int `json:"attempt"`
+
+		// Status is the build status of the attempt.
+		Status BuildStatus `json:"status"`
+
+		// Message is the error of a failed attempt.
+		Message string `json:"message,omitempty"`
+
+		// BuildLogURL is the storage service URL of the full build log of the attempt.
+		BuildLogURL string `json:"buildlogurl,omitempty"`
+
+		StartTimestamp  time.Time `json:"startTimestamp,omitempty"`
+		FinishTimestamp time.Time `json:"finishTimestamp,omitempty"`
 	}
 
 	// PackageRef is a reference to the package.
@@ -319,6 +498,9 @@ type (
 	// StrategyType is the strategy to be used for function execution
 	StrategyType string
 
+	// IsolationMode is how the invocations of a function share its pods
+	IsolationMode string
+
 	// FunctionSpec describes the contents of the function.
 	FunctionSpec struct {
 		// Environment is the build and runtime environment that this function is
@@ -329,6 +511,19 @@ type (
 		// Reference to a package containing deployment and optionally the source.
 		Package FunctionPackageRef `json:"package"`
 
+		// PodSpec is the pod of a function with the container executor
+		// type, which has neither an environment nor a package. The first
+		// container runs the image of the function and serves HTTP requests
+		// on its first container port, 8888 if it has none.
+		PodSpec *apiv1.PodSpec `json:"podspec,omitempty"`
+
+		// PrebuiltImage is an image of the environment runtime with the
+		// code of the function baked in, e.g. built in CI. Functions with
+		// a prebuilt image have no package, the newdeploy executor runs
+		// the image instead of the runtime image of the environment and
+		// specializes it without fetching an archive.
+		PrebuiltImage *PrebuiltImage `json:"prebuiltimage,omitempty"`
+
 		// Reference to a list of secrets.
 		Secrets []SecretReference `json:"secrets"`
 
@@ -346,6 +541,93 @@ type (
 		// FunctionTimeout provides a maximum amount of duration wihtin which a request for a particular function execution should be complete.
 		// This is optional. If not specified default value will be taken as 60s
 		FunctionTimeout int `json:"functionTimeout,omitempty"`
+
+		// Concurrency is the maximum number of requests each router instance
+		// sends to the function at the same time. This is optional, 0 means
+		// unlimited.
+		Concurrency int `json:"concurrency,omitempty"`
+
+		// RequestQueueLength is the number of requests beyond Concurrency
+		// that router holds until a running request completes. Requests
+		// exceeding the queue are rejected with 429 Too Many Requests.
+		RequestQueueLength int `json:"requestQueueLength,omitempty"`
+
+		// IdleTimeout is the number of seconds without requests after which
+		// the newdeploy executor scales the function down to its MinScale,
+		// down to zero pods with a MinScale of 0. This is optional, 0 means
+		// the default idle timeout of the executor.
+		IdleTimeout int `json:"idletimeout,omitempty"`
+
+		// NodeSelector and Tolerations constrain the nodes function pods are
+		// scheduled on. They are only applied by the newdeploy and container
+		// executors; poolmgr pods are scheduled with the PodSpec of the
+		// environment runtime.
+		NodeSelector map[string]string  `json:"nodeselector,omitempty"`
+		Tolerations  []apiv1.Toleration `json:"tolerations,omitempty"`
+
+		// Spread spreads the pods of the function across nodes or zones.
+		// This is optional.
+		Spread *Spread `json:"spread,omitempty"`
+
+		// Volumes are mounted into the function container. Like the node
+		// selector, they are only applied by the newdeploy and container
+		// executors, poolmgr pods are created before they're specialized.
+		Volumes []FunctionVolume `json:"volumes,omitempty"`
+	}
+
+	// PrebuiltImage is an immutable image of a function and its environment.
+	PrebuiltImage struct {
+		// Image is the image, it should have an immutable tag or digest.
+		Image string `json:"image"`
+
+		// Path is the absolute path of the code in the image, which the
+		// environment loads on specialization. It must be outside the
+		// directory shared with the fetcher (/userfunc), which is mounted
+		// over the image. Optional: if it's empty the pod isn't specialized
+		// at all, the image serves the function by itself.
+		Path string `json:"path,omitempty"`
+	}
+
+	// FunctionVolumeType is the kind of volume mounted into a function pod.
+	FunctionVolumeType string
+
+	// FunctionVolume is a persistent volume claim, e.g. a shared model, or
+	// an emptyDir scratch space mounted into the function container.
+	FunctionVolume struct {
+		// Type is the kind of volume, pvc or emptydir.
+		Type FunctionVolumeType `json:"type"`
+
+		// Name is the name of the claim of a pvc volume, and names an
+		// emptydir volume.
+		Name string `json:"name"`
+
+		// MountPath is the absolute path the volume is mounted at.
+		MountPath string `json:"mountPath"`
+
+		// ReadOnly mounts the volume read-only.
+		ReadOnly bool `json:"readOnly,omitempty"`
+
+		// SizeLimit is the size of an emptydir volume; the pod is evicted
+		// if it writes more. Optional, the default is unlimited.
+		SizeLimit *resource.Quantity `json:"sizeLimit,omitempty"`
+	}
+
+	// SpreadTopology is the failure domain pods are spread across.
+	SpreadTopology string
+
+	// Spread spreads the replicas of a function across failure domains, so
+	// that the failure of a single node or zone doesn't take out all of
+	// them. The newdeploy and container executors set a pod anti-affinity
+	// on the function pods; poolmgr prefers pool pods in a domain that has
+	// no pod of the function yet.
+	Spread struct {
+		// Topology is the failure domain, a node or a zone.
+		Topology SpreadTopology `json:"topology"`
+
+		// Required makes the spread a hard constraint, pods that would
+		// share a domain with another pod of the function stay pending.
+		// Otherwise it's a preference of the scheduler.
+		Required bool `json:"required,omitempty"`
 	}
 
 	// InvokeStrategy is a set of controls over how the function executes.
@@ -399,6 +681,26 @@ type (
 		// This is the timeout setting for executor to wait for pod specialization.
 		// Currently, only newdeploy utilizes this value.
 		SpecializationTimeout int
+
+		// Multiplex lets poolmgr load the function into a pod already
+		// specialized for another multiplexed function with the same
+		// package, secrets and config maps, instead of specializing a pod
+		// for it. The environment has to support loading more entrypoints,
+		// see types.FunctionLoadPath; otherwise the function gets its own
+		// pod. Only for poolmgr.
+		Multiplex bool
+
+		// Isolation is how invocations of the function share pods. With
+		// "invocation", each invocation runs in a fresh pod that is
+		// deleted once it returns, pods are never reused. Defaults to
+		// "shared". Only for poolmgr.
+		Isolation IsolationMode
+
+		// PrespecializedPods is the number of pods poolmgr keeps
+		// specialized ahead of invocations of a function isolated per
+		// invocation, so that an invocation doesn't wait for its pod to
+		// be specialized.
+		PrespecializedPods int
 	}
 
 	FunctionReferenceType string
@@ -412,9 +714,11 @@ type (
 		// Available value:
 		// - name
 		// - function-weights
+		// - alias
 		Type FunctionReferenceType `json:"type"`
 
-		// Name of the function.
+		// Name of the function, or of the function alias for the
+		// alias reference type.
 		Name string `json:"name"`
 
 		// Function Reference by weight. this map contains function name as key and its weight
@@ -490,6 +794,30 @@ type (
 		// - ImagePullPolicy
 		// - ReadinessProbe
 		Container *apiv1.Container `json:"container,omitempty"`
+
+		// (Optional) PoolSize is the number of builder pods of the
+		// environment, so that builds don't queue behind a single pod.
+		// Defaults to 1.
+		PoolSize int `json:"poolsize,omitempty"`
+
+		// (Optional) Cache is a volume shared by the builds of the
+		// environment, so that the dependencies downloaded by a build are
+		// reused by the following ones.
+		Cache *BuildCache `json:"cache,omitempty"`
+	}
+
+	// BuildCache is a PersistentVolumeClaim mounted into the builder pods
+	// of an environment. The builds get a directory of it keyed on the
+	// checksum of the dependency lockfile of the source package, and the
+	// package managers are pointed at it, e.g. with PIP_CACHE_DIR.
+	BuildCache struct {
+		// Size of the volume, e.g. 2Gi.
+		Size resource.Quantity `json:"size"`
+
+		// (Optional) StorageClassName of the volume, the default storage
+		// class of the cluster if empty. It must support ReadWriteMany if
+		// the builder PoolSize is above 1.
+		StorageClassName string `json:"storageClassName,omitempty"`
 	}
 
 	// EnvironmentSpec contains with builder, runtime and some other related environment settings.
@@ -542,6 +870,16 @@ type (
 		// or unarchived file should be placed, which is then used by specialize handler.
 		// (This is mainly for the JVM environment because .jar is one kind of zip archive.)
 		KeepArchive bool `json:"keeparchive"`
+
+		// (Optional) RuntimeClassName is the name of the Kubernetes RuntimeClass used by
+		// the function and builder pods of the environment, e.g. a gVisor or Kata Containers
+		// runtime class to sandbox untrusted code. Defaults to the default container runtime.
+		RuntimeClassName string `json:"runtimeClassName,omitempty"`
+
+		// (Optional) ImagePullSecret is the name of the docker registry secret used to pull
+		// the runtime and builder images of the environment from a private registry. The secret
+		// must exist in the namespace of the function and builder pods.
+		ImagePullSecret string `json:"imagepullsecret,omitempty"`
 	}
 
 	AllowedFunctionsPerContainer string
@@ -560,9 +898,23 @@ type (
 		// RelativeURL is the exposed URL for external client to access a function with.
 		RelativeURL string `json:"relativeurl"`
 
+		// Prefix routes the requests of all the paths under it to the
+		// function instead of RelativeURL, e.g. /api/v1/ for a REST
+		// framework running in the function. The path is forwarded to the
+		// function with the prefix stripped.
+		Prefix string `json:"prefix,omitempty"`
+
+		// KeepPrefix forwards the full request path of a Prefix trigger
+		// to the function.
+		KeepPrefix bool `json:"keepprefix,omitempty"`
+
 		// HTTP method to access a function.
 		Method string `json:"method"`
 
+		// Methods are the HTTP methods to access a function, HTTPMethodAny
+		// matches all of them. Method is ignored if Methods is set.
+		Methods []string `json:"methods,omitempty"`
+
 		// FunctionReference is a reference to the target function.
 		FunctionReference FunctionReference `json:"functionref"`
 
@@ -572,6 +924,151 @@ type (
 		// TODO: make IngressConfig a independent Fission resource
 		// IngressConfig for router to set up Ingress.
 		IngressConfig IngressConfig `json:"ingressconfig"`
+
+		// If AllowWebsocket is true, router proxies websocket upgrade requests
+		// to the function and keeps the connection open until it's idle.
+		AllowWebsocket bool `json:"allowwebsocket,omitempty"`
+
+		// Protocol the router speaks to the function pod, http (default) or
+		// grpc. gRPC calls are proxied with HTTP/2 and keep the request path,
+		// the router must serve HTTP/2 to callers for them.
+		Protocol string `json:"protocol,omitempty"`
+
+		// FaultInjection makes router delay or fail a percentage of the
+		// requests of the trigger, for testing the resilience of callers.
+		FaultInjection *FaultInjection `json:"faultinjection,omitempty"`
+
+		// Timeouts overrides the timeouts of the calls router makes to the
+		// function for the requests of the trigger.
+		Timeouts *UpstreamTimeouts `json:"timeouts,omitempty"`
+
+		// RateLimit makes router reject the requests of the trigger above
+		// the rate with 429 Too Many Requests.
+		RateLimit *RateLimit `json:"ratelimit,omitempty"`
+
+		// MaxConcurrentRequests caps the requests of the trigger in flight
+		// at once, requests above the cap are shed with 503 Service
+		// Unavailable. Each router instance enforces it separately, 0
+		// means no cap.
+		MaxConcurrentRequests int `json:"maxconcurrentrequests,omitempty"`
+
+		// RetryPolicy overrides which failed calls to the function router
+		// retries for the requests of the trigger.
+		RetryPolicy *RetryPolicy `json:"retrypolicy,omitempty"`
+
+		// Auth makes router authenticate the requests of the trigger
+		// before calling the function, unauthenticated requests are
+		// rejected with 401 Unauthorized.
+		Auth *HTTPTriggerAuth `json:"auth,omitempty"`
+
+		// APIVersioning makes router send the requests of the trigger
+		// to a function per API version, requests without a version go
+		// to FunctionReference.
+		APIVersioning *APIVersioning `json:"apiversioning,omitempty"`
+	}
+
+	// APIVersioning maps the API version of the requests of a HTTP
+	// trigger to functions, so that versions of an API can be served at
+	// the same URL.
+	APIVersioning struct {
+		// Header is the request header with the version, defaults to
+		// APIVersionHeaderDefault.
+		Header string `json:"header,omitempty"`
+
+		// Accept reads the version from the Accept header as well, from
+		// a version parameter of the media type, e.g.
+		// application/json;version=2, or from a vendor media type, e.g.
+		// application/vnd.example.v2+json. Header takes precedence.
+		Accept bool `json:"accept,omitempty"`
+
+		// Functions maps versions to the names of functions in the
+		// namespace of the trigger. Requests with other versions are
+		// rejected with 400 Bad Request.
+		Functions map[string]string `json:"functions"`
+	}
+
+	// HTTPTriggerAuthType is the kind of credentials of a HTTP trigger.
+	HTTPTriggerAuthType string
+
+	// HTTPTriggerAuth is the authentication of the requests of a HTTP
+	// trigger. The keys are in a secret in the namespace of the trigger.
+	HTTPTriggerAuth struct {
+		// Type is HTTPTriggerAuthJWT or HTTPTriggerAuthAPIKey.
+		Type HTTPTriggerAuthType `json:"type"`
+
+		// Secret is the name of the secret with the keys. For JWT, its
+		// HTTPTriggerAuthJWTKey entry is the HMAC key tokens are signed
+		// with. For API keys, every entry is a key, named after the
+		// client it's given to.
+		Secret string `json:"secret"`
+
+		// Issuer and Audience, if set, must match the iss and aud claims
+		// of the tokens.
+		Issuer   string `json:"issuer,omitempty"`
+		Audience string `json:"audience,omitempty"`
+	}
+
+	// RetryPolicy is the retries of the calls router makes to the function
+	// of a HTTP trigger. The retries back off like the router default ones.
+	RetryPolicy struct {
+		// Retries is the max number of retries of a request, 0 means the
+		// function is called once.
+		Retries int `json:"retries"`
+
+		// RetryOn is the failures that are retried, RetryOn5xx and
+		// RetryOnConnectFailure.
+		RetryOn []string `json:"retryon,omitempty"`
+	}
+
+	// RateLimit is a token bucket limiting the request rate of a HTTP
+	// trigger, each router instance enforces it separately.
+	RateLimit struct {
+		// RequestsPerSecond is the rate the bucket refills at.
+		RequestsPerSecond int `json:"rps"`
+
+		// Burst is the size of the bucket, i.e. the requests allowed at
+		// once, defaults to RequestsPerSecond.
+		Burst int `json:"burst,omitempty"`
+
+		// PerClientIP gives every client IP address a bucket of its own
+		// instead of sharing one bucket among all clients.
+		PerClientIP bool `json:"perclientip,omitempty"`
+	}
+
+	// UpstreamTimeouts are the timeouts in milliseconds of the calls router
+	// makes to a function, 0 means the timeout of the function or the
+	// router default is used.
+	UpstreamTimeouts struct {
+		// ConnectTimeout is the max time to establish a connection to a
+		// function pod, before router retries with backoff.
+		ConnectTimeout int `json:"connecttimeout,omitempty"`
+
+		// ResponseHeaderTimeout is the max time to wait for the response
+		// headers of the function once the request is sent.
+		ResponseHeaderTimeout int `json:"responseheadertimeout,omitempty"`
+
+		// TotalTimeout is the max time of a call to the function, it takes
+		// precedence over the function timeout.
+		TotalTimeout int `json:"totaltimeout,omitempty"`
+	}
+
+	// FaultInjection is the faults router injects into the requests of a
+	// HTTP trigger. A request is delayed first and then aborted, if both
+	// faults are chosen for it.
+	FaultInjection struct {
+		// DelayPercentage is the percentage of requests to delay, from 0 to 100.
+		DelayPercentage int `json:"delaypercentage,omitempty"`
+
+		// Delay is the time in milliseconds router waits before routing a
+		// delayed request to the function.
+		Delay int `json:"delay,omitempty"`
+
+		// AbortPercentage is the percentage of requests to abort, from 0 to 100.
+		AbortPercentage int `json:"abortpercentage,omitempty"`
+
+		// AbortStatus is the HTTP status code returned for an aborted
+		// request without calling the function, defaults to 503.
+		AbortStatus int `json:"abortstatus,omitempty"`
 	}
 
 	// IngressConfig is for router to set up Ingress.
@@ -619,10 +1116,10 @@ type (
 		// when receiving messages from subscribed topic.
 		FunctionReference FunctionReference `json:"functionref"`
 
-		// Type of message queue (NATS, Kafka, AzureQueue)
+		// Type of message queue (NATS, Kafka, AzureQueue, RabbitMQ, GCP Pub/Sub, Azure Service Bus, NATS JetStream)
 		MessageQueueType MessageQueueType `json:"messageQueueType"`
 
-		// Subscribed topic
+		// Subscribed topic, or the queue to consume from for RabbitMQ
 		Topic string `json:"topic"`
 
 		// Topic for message queue trigger to sent response from function.
@@ -636,6 +1133,140 @@ type (
 
 		// Content type of payload
 		ContentType string `json:"contentType"`
+
+		// Kafka specific connection settings. If not set, the brokers
+		// configured for the mqtrigger deployment are used.
+		Kafka *KafkaConfig `json:"kafka,omitempty"`
+
+		// RabbitMQ specific settings. If not set, the queue is consumed from
+		// the default exchange with the credentials of the AMQP URL configured
+		// for the mqtrigger deployment.
+		RabbitMQ *RabbitMQConfig `json:"rabbitmq,omitempty"`
+
+		// GCP Pub/Sub specific settings. If not set, a subscription of the
+		// trigger is created in the project configured for the mqtrigger
+		// deployment.
+		GCPPubSub *GCPPubSubConfig `json:"gcpPubSub,omitempty"`
+
+		// Azure Service Bus specific settings. If not set, the topic is a
+		// queue without sessions.
+		AzureServiceBus *AzureServiceBusConfig `json:"azureServiceBus,omitempty"`
+
+		// NATS JetStream specific settings, required for nats-jetstream
+		// triggers since the stream of the topic has to be given.
+		NatsJetStream *NatsJetStreamConfig `json:"natsJetStream,omitempty"`
+
+		// Secret is the name of a secret in the trigger namespace holding the
+		// credentials to connect to the message queue, so that they're not
+		// kept in the trigger spec or the mqtrigger deployment. The "username"
+		// and "password" keys are used for authentication (SASL/PLAIN for
+		// Kafka), the "ca.crt", "tls.crt" and "tls.key" keys enable TLS with
+		// the given CA and client certificates. For GCP Pub/Sub, the
+		// "credentials.json" key holds a service account key, for Azure
+		// Service Bus the "connectionString" key the connection string of
+		// the namespace. Only Kafka, RabbitMQ, GCP Pub/Sub, Azure Service
+		// Bus and NATS JetStream triggers, which connect to the message
+		// queue separately, support it.
+		Secret string `json:"secret,omitempty"`
+	}
+
+	// KafkaConfig holds the connection settings of a Kafka message queue trigger.
+	// The SASL credentials are only taken from the Secret of the trigger.
+	KafkaConfig struct {
+		// List of Kafka brokers, e.g. broker-1:9092
+		Brokers []string `json:"brokers,omitempty"`
+
+		// Consumer group of the trigger. Defaults to the UID of the trigger.
+		ConsumerGroup string `json:"consumerGroup,omitempty"`
+
+		// TLS settings
+		TLS *KafkaTLSConfig `json:"tls,omitempty"`
+	}
+
+	// KafkaTLSConfig defines the TLS settings used to connect to Kafka brokers.
+	KafkaTLSConfig struct {
+		Enabled bool `json:"enabled"`
+
+		// Skip the verification of the broker certificates.
+		InsecureSkipVerify bool `json:"insecureSkipVerify,omitempty"`
+	}
+
+	// RabbitMQConfig holds the AMQP settings of a RabbitMQ message queue trigger.
+	RabbitMQConfig struct {
+		// Exchange the queue is bound to. The response and error topics are
+		// used as routing keys to publish to this exchange. Defaults to the
+		// default exchange, which routes to the queue named by the routing key.
+		Exchange string `json:"exchange,omitempty"`
+
+		// Routing key the queue is bound to the exchange with. Defaults to the queue name.
+		RoutingKey string `json:"routingKey,omitempty"`
+
+		// Number of unacknowledged messages delivered to the trigger at a time.
+		// Defaults to 1, so that messages are processed one by one.
+		Prefetch int `json:"prefetch,omitempty"`
+
+		// Name of the secret in the trigger namespace with the "username" and
+		// "password" keys used to connect to the broker.
+		// Deprecated: use MessageQueueTriggerSpec.Secret, which supports TLS certificates too.
+		CredentialSecret string `json:"credentialSecret,omitempty"`
+	}
+
+	// GCPPubSubConfig holds the settings of a GCP Pub/Sub message queue trigger.
+	GCPPubSubConfig struct {
+		// ID of the project of the topics and the subscription. Defaults to
+		// the project configured for the mqtrigger deployment.
+		ProjectID string `json:"projectID,omitempty"`
+
+		// Subscription of the topic the messages are pulled from. It's
+		// created if it doesn't exist. Defaults to "fission-<trigger UID>".
+		Subscription string `json:"subscription,omitempty"`
+
+		// Seconds Pub/Sub waits for a message to be acknowledged before
+		// redelivering it, used when the subscription is created. The
+		// deadline of a message is extended while the function is retried
+		// up to MaxRetries times, so it only matters if the mqtrigger
+		// stops. Defaults to 10, up to 600.
+		AckDeadline int `json:"ackDeadline,omitempty"`
+	}
+
+	// AzureServiceBusConfig holds the settings of an Azure Service Bus
+	// message queue trigger. Messages the function fails on are
+	// dead-lettered with the error, besides being sent to the error topic.
+	AzureServiceBusConfig struct {
+		// Subscription of the topic the messages are received from. If set,
+		// the trigger topic is a Service Bus topic, otherwise a queue.
+		Subscription string `json:"subscription,omitempty"`
+
+		// Sessions receives the messages of a session-enabled queue or
+		// subscription one session at a time, in order. Responses and
+		// errors are sent in the session of the message.
+		Sessions bool `json:"sessions,omitempty"`
+	}
+
+	// NatsJetStreamConfig holds the settings of a NATS JetStream message
+	// queue trigger. The messages of the topic are received from a durable
+	// consumer of the stream, and acknowledged once the function succeeded
+	// on them, so that they're redelivered if the mqtrigger stops.
+	NatsJetStreamConfig struct {
+		// Stream the topic is a subject of.
+		Stream string `json:"stream"`
+
+		// Durable is the name of the durable consumer of the stream, created
+		// if it doesn't exist. The consumer isn't deleted along with the
+		// trigger, so a trigger of the same consumer resumes where the
+		// previous one stopped. Defaults to "fission-<trigger UID>".
+		Durable string `json:"durable,omitempty"`
+
+		// Seconds JetStream waits for a message to be acknowledged before
+		// redelivering it, used when the consumer is created. The trigger
+		// signals progress while the function runs, so it only matters if
+		// the mqtrigger stops. Defaults to 30.
+		AckWait int `json:"ackWait,omitempty"`
+
+		// MaxDeliver is the number of times a message the function fails on
+		// is delivered before it's sent to the error topic and dropped.
+		// Defaults to 1, delivering every message once.
+		MaxDeliver int `json:"maxDeliver,omitempty"`
 	}
 
 	// RecorderSpec defines a policy for recording requests and responses
@@ -660,6 +1291,10 @@ type (
 		// Cron schedule
 		Cron string `json:"cron"`
 
+		// (Optional) Timezone is the IANA time zone name, e.g. America/New_York,
+		// in which the cron schedule is evaluated. Defaults to UTC.
+		Timezone string `json:"timezone,omitempty"`
+
 		// The reference to function
 		FunctionReference `json:"functionref"`
 	}
@@ -686,10 +1321,92 @@ type (
 		// Threshold in percentage beyond which the new version of the function is considered unstable
 		FailureThreshold int         `json:"failurethreshold"`
 		FailureType      FailureType `json:"failureType"`
+
+		// LatencyThreshold is the max 99th percentile latency in milliseconds
+		// of the new version of the function, 0 disables the check.
+		LatencyThreshold int `json:"latencythreshold,omitempty"`
+
+		// SuccessQueries are PromQL expressions which must evaluate to a
+		// non-zero value for the new version to be considered stable, and
+		// FailureQueries are PromQL expressions which roll the new version back
+		// if any of them evaluates to a non-zero value. An expression without
+		// data defers the decision to the next interval. Expressions are Go
+		// templates with the fields .Function, .OldFunction, .Namespace,
+		// .Path, .Method (a regular expression of the methods of the trigger,
+		// to match with =~) and .Window (the weight increment interval), e.g.
+		// sum(rate(my_errors_total{function="{{.Function}}"}[{{.Window}}])) > 5
+		SuccessQueries []string `json:"successqueries,omitempty"`
+		FailureQueries []string `json:"failurequeries,omitempty"`
+
+		// OnSuccessWebhook and OnFailureWebhook are URLs the canary config
+		// manager POSTs to when the new version is promoted or rolled back.
+		OnSuccessWebhook string `json:"onsuccesswebhook,omitempty"`
+		OnFailureWebhook string `json:"onfailurewebhook,omitempty"`
+
+		// WebhookFormat is the payload format of the webhooks, json or slack (default: json).
+		WebhookFormat CanaryWebhookFormat `json:"webhookformat,omitempty"`
 	}
 
+	// CanaryWebhookFormat is the payload format of canary config webhooks.
+	CanaryWebhookFormat string
+
 	// CanaryConfig Status
 	CanaryConfigStatus struct {
 		Status string `json:"status"`
 	}
+
+	// FunctionAliasSpec is the specification of a function alias.
+	FunctionAliasSpec struct {
+		// FunctionWeights maps the names of the functions the alias points
+		// to, in the namespace of the alias, to the percentage of the
+		// traffic each of them gets. The weights add up to 100.
+		FunctionWeights map[string]int `json:"functionweights"`
+	}
+
+	// CanaryPolicySpec holds the parameters of the canary configs created
+	// for updated functions, see CanaryConfigSpec.
+	CanaryPolicySpec struct {
+		// Weight increment step for function
+		WeightIncrement int `json:"weightincrement"`
+
+		// Weight increment interval, string representation of time.Duration, ex : 1m, 2h, 2d (default: "2m")
+		WeightIncrementDuration string `json:"duration"`
+
+		// Threshold in percentage beyond which the new version of the function is considered unstable
+		FailureThreshold int         `json:"failurethreshold"`
+		FailureType      FailureType `json:"failureType"`
+
+		// LatencyThreshold is the max 99th percentile latency in milliseconds
+		// of the new version of the function, 0 disables the check.
+		LatencyThreshold int `json:"latencythreshold,omitempty"`
+	}
 )
+
+// GetMethods returns the HTTP methods of the trigger, Methods or Method
+// for the triggers created before Methods.
+func (spec HTTPTriggerSpec) GetMethods() []string {
+	if len(spec.Methods) > 0 {
+		return spec.Methods
+	}
+	return []string{spec.Method}
+}
+
+// GetPath returns the path the trigger is routed at, Prefix for the
+// triggers routing a path prefix.
+func (spec HTTPTriggerSpec) GetPath() string {
+	if len(spec.Prefix) > 0 {
+		return spec.Prefix
+	}
+	return spec.RelativeURL
+}
+
+// HasMethod returns whether the trigger serves requests of the given
+// HTTP method.
+func (spec HTTPTriggerSpec) HasMethod(method string) bool {
+	for _, m := range spec.GetMethods() {
+		if m == method || m == HTTPMethodAny {
+			return true
+		}
+	}
+	return false
+}
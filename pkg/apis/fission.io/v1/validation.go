@@ -19,8 +19,12 @@ package v1
 import (
 	"fmt"
 	"net/http"
+	"net/url"
+	"path"
 	"regexp"
 	"strings"
+	"text/template"
+	"time"
 
 	"github.com/hashicorp/go-multierror"
 	nsUtil "github.com/nats-io/nats-streaming-server/util"
@@ -40,6 +44,22 @@ var (
 	validAzureQueueName = regexp.MustCompile("^[a-z0-9][a-z0-9\\-]*[a-z0-9]$")
 	// Need to use raw string to support escape sequence for - & . chars
 	validKafkaTopicName = regexp.MustCompile(`^[a-zA-Z0-9][a-zA-Z0-9\-\._]*[a-zA-Z0-9]$`)
+	// Pub/Sub topic and subscription IDs, see https://cloud.google.com/pubsub/docs/admin#resource_names
+	validGCPPubSubName = regexp.MustCompile(`^[a-zA-Z][a-zA-Z0-9\-\._~+%]{2,254}$`)
+	// Service Bus queue and topic names, and subscription names, see
+	// https://docs.microsoft.com/en-us/azure/azure-resource-manager/management/resource-name-rules#microsoftservicebus
+	validAzureServiceBusName             = regexp.MustCompile(`^[a-zA-Z0-9]([a-zA-Z0-9\-\._/]{0,258}[a-zA-Z0-9])?$`)
+	validAzureServiceBusSubscriptionName = regexp.MustCompile(`^[a-zA-Z0-9]([a-zA-Z0-9\-\._]{0,48}[a-zA-Z0-9])?$`)
+	// NATS subjects are tokens separated by dots, wildcards aren't allowed
+	// in the subjects of triggers, see https://docs.nats.io/nats-concepts/subjects
+	validNatsSubject = regexp.MustCompile(`^[^.\s*>]+(\.[^.\s*>]+)*$`)
+	// JetStream stream and consumer names can't have dots, wildcards or
+	// path separators
+	validNatsJetStreamName = regexp.MustCompile(`^[^.\s*>/\\]+$`)
+	// scp-like ssh URL of a Git repository, e.g. git@github.com:org/repo.git
+	scpLikeGitURL = regexp.MustCompile(`^[a-zA-Z0-9_.\-]+@[a-zA-Z0-9.\-]+:[^/].*$`)
+	// API versions of HTTP triggers, e.g. 2, v2 or 2020-01-01
+	validAPIVersion = regexp.MustCompile(`^[a-zA-Z0-9._\-]+$`)
 )
 
 type (
@@ -170,10 +190,40 @@ func IsTopicValid(mqType MessageQueueType, topic string) bool {
 		return len(topic) >= 3 && len(topic) <= 63 && validAzureQueueName.MatchString(topic)
 	case MessageQueueTypeKafka:
 		return IsValidKafkaTopic(topic)
+	case MessageQueueTypeRabbitMQ:
+		return IsValidRabbitMQQueue(topic)
+	case MessageQueueTypeGCPPubSub:
+		return IsValidGCPPubSubName(topic)
+	case MessageQueueTypeAzureServiceBus:
+		return IsValidAzureServiceBusName(topic)
+	case MessageQueueTypeNatsJetStream:
+		return IsValidNatsSubject(topic)
 	}
 	return false
 }
 
+// NATS subjects are dot separated tokens without whitespace.
+func IsValidNatsSubject(subject string) bool {
+	return validNatsSubject.MatchString(subject)
+}
+
+// Queue and topic names of Azure Service Bus are up to 260 characters,
+// starting and ending with a letter or a number.
+func IsValidAzureServiceBusName(name string) bool {
+	return validAzureServiceBusName.MatchString(name)
+}
+
+// Topic and subscription IDs of GCP Pub/Sub are 3 to 255 characters starting
+// with a letter, the "goog" prefix is reserved.
+func IsValidGCPPubSubName(name string) bool {
+	return validGCPPubSubName.MatchString(name) && !strings.HasPrefix(name, "goog")
+}
+
+// Queue names of RabbitMQ are up to 255 bytes, the "amq." prefix is reserved for the broker.
+func IsValidRabbitMQQueue(name string) bool {
+	return len(name) > 0 && len(name) <= 255 && !strings.HasPrefix(name, "amq.")
+}
+
 // The validation is based on Kafka's internal implementation: https://github.com/apache/kafka/blob/trunk/clients/src/main/java/org/apache/kafka/common/internals/Topic.java
 func IsValidKafkaTopic(topic string) bool {
 	if len(topic) == 0 {
@@ -191,6 +241,17 @@ func IsValidKafkaTopic(topic string) bool {
 	return true
 }
 
+// IsValidGitURL returns true if the url is an https, http or ssh URL of a
+// Git repository, including the scp-like syntax, e.g. git@github.com:org/repo.git
+func IsValidGitURL(url string) bool {
+	for _, prefix := range []string{"https://", "http://", "ssh://"} {
+		if strings.HasPrefix(url, prefix) {
+			return len(url) > len(prefix)
+		}
+	}
+	return scpLikeGitURL.MatchString(url)
+}
+
 func IsValidCronSpec(spec string) error {
 	_, err := cron.Parse(spec)
 	return err
@@ -216,6 +277,13 @@ func (archive Archive) Validate() error {
 	if len(archive.Type) > 0 {
 		switch archive.Type {
 		case ArchiveTypeLiteral, ArchiveTypeUrl: // no op
+		case ArchiveTypeGit:
+			if !IsValidGitURL(archive.URL) {
+				result = multierror.Append(result, MakeValidationErr(ErrorInvalidValue, "Archive.URL", archive.URL, "not a valid https or ssh URL of a Git repository"))
+			}
+			if archive.Git != nil && len(archive.Git.Secret) > 0 {
+				result = multierror.Append(result, ValidateKubeName("Archive.Git.Secret", archive.Git.Secret))
+			}
 		default:
 			result = multierror.Append(result, MakeValidationErr(ErrorUnsupportedType, "Archive.Type", archive.Type, "not a valid archive type"))
 		}
@@ -257,6 +325,86 @@ func (spec PackageSpec) Validate() error {
 		}
 	}
 
+	if spec.Deployment.Type == ArchiveTypeGit {
+		result = multierror.Append(result, MakeValidationErr(ErrorUnsupportedType, "PackageSpec.Deployment.Type", spec.Deployment.Type, "only source archives can be in a Git repository"))
+	}
+
+	if len(spec.BuildSteps) > 0 && len(spec.BuildCommand) > 0 {
+		result = multierror.Append(result, MakeValidationErr(ErrorInvalidObject, "PackageSpec.BuildSteps", spec.BuildCommand, "build steps can not be used with a build command"))
+	}
+
+	names := make(map[string]bool)
+	for i, step := range spec.BuildSteps {
+		result = multierror.Append(result, step.Validate())
+		if names[step.Name] {
+			result = multierror.Append(result, MakeValidationErr(ErrorInvalidValue, fmt.Sprintf("PackageSpec.BuildSteps[%v].Name", i), step.Name, "duplicate build step name"))
+		}
+		names[step.Name] = true
+	}
+
+	for name := range spec.BuildEnv {
+		e := validation.IsEnvVarName(name)
+		if len(e) > 0 {
+			result = multierror.Append(result, MakeValidationErr(ErrorInvalidValue, "PackageSpec.BuildEnv", name, e...))
+		}
+	}
+
+	for _, secret := range spec.BuildSecrets {
+		result = multierror.Append(result, ValidateKubeName("PackageSpec.BuildSecrets", secret))
+	}
+
+	hookNames := make(map[string]bool)
+	for i, hook := range spec.PostBuildHooks {
+		result = multierror.Append(result, hook.Validate())
+		if hookNames[hook.Name] {
+			result = multierror.Append(result, MakeValidationErr(ErrorInvalidValue, fmt.Sprintf("PackageSpec.PostBuildHooks[%v].Name", i), hook.Name, "duplicate post-build hook name"))
+		}
+		hookNames[hook.Name] = true
+	}
+
+	return result.ErrorOrNil()
+}
+
+func (hook PostBuildHook) Validate() error {
+	result := &multierror.Error{}
+
+	result = multierror.Append(result, ValidateKubeName("PostBuildHook.Name", hook.Name))
+
+	switch hook.Type {
+	case PostBuildHookTypePublish:
+		if len(hook.SBOMPath) > 0 {
+			result = multierror.Append(result, MakeValidationErr(ErrorInvalidValue, "PostBuildHook.SBOMPath", hook.SBOMPath, "only webhooks send a SBOM"))
+		}
+	case PostBuildHookTypeWebhook: // no op
+	default:
+		result = multierror.Append(result, MakeValidationErr(ErrorUnsupportedType, "PostBuildHook.Type", hook.Type, "not a supported post-build hook type, use publish or webhook"))
+	}
+
+	u, err := url.Parse(hook.URL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || len(u.Host) == 0 {
+		result = multierror.Append(result, MakeValidationErr(ErrorInvalidValue, "PostBuildHook.URL", hook.URL, "not a valid http or https URL"))
+	}
+
+	if len(hook.Secret) > 0 {
+		result = multierror.Append(result, ValidateKubeName("PostBuildHook.Secret", hook.Secret))
+	}
+
+	if len(hook.SBOMPath) > 0 && (path.IsAbs(hook.SBOMPath) || strings.HasPrefix(path.Clean(hook.SBOMPath), "..")) {
+		result = multierror.Append(result, MakeValidationErr(ErrorInvalidValue, "PostBuildHook.SBOMPath", hook.SBOMPath, "must be a relative path in the deployment archive"))
+	}
+
+	return result.ErrorOrNil()
+}
+
+func (step BuildStep) Validate() error {
+	result := &multierror.Error{}
+
+	result = multierror.Append(result, ValidateKubeName("BuildStep.Name", step.Name))
+
+	if len(strings.TrimSpace(step.Command)) == 0 {
+		result = multierror.Append(result, MakeValidationErr(ErrorInvalidValue, "BuildStep.Command", step.Command, "build step command can not be empty"))
+	}
+
 	return result.ErrorOrNil()
 }
 
@@ -291,7 +439,15 @@ func (spec FunctionSpec) Validate() error {
 		result = multierror.Append(result, spec.Environment.Validate())
 	}
 
-	if spec.Package != (FunctionPackageRef{}) {
+	if spec.PrebuiltImage != nil {
+		result = multierror.Append(result, spec.PrebuiltImage.Validate())
+		if spec.Package.PackageRef != (PackageRef{}) {
+			result = multierror.Append(result, MakeValidationErr(ErrorInvalidValue, "FunctionSpec.Package", spec.Package.PackageRef, "functions with a prebuilt image have no package"))
+		}
+		if spec.InvokeStrategy.ExecutionStrategy.ExecutorType != ExecutorTypeNewdeploy {
+			result = multierror.Append(result, MakeValidationErr(ErrorInvalidValue, "FunctionSpec.PrebuiltImage", spec.PrebuiltImage.Image, "only newdeploy functions have a prebuilt image"))
+		}
+	} else if spec.Package != (FunctionPackageRef{}) {
 		result = multierror.Append(result, spec.Package.Validate())
 	}
 
@@ -306,6 +462,54 @@ func (spec FunctionSpec) Validate() error {
 		result = multierror.Append(result, spec.InvokeStrategy.Validate())
 	}
 
+	if spec.InvokeStrategy.ExecutionStrategy.ExecutorType == ExecutorTypeContainer {
+		if spec.PodSpec == nil || len(spec.PodSpec.Containers) == 0 || len(spec.PodSpec.Containers[0].Image) == 0 {
+			result = multierror.Append(result, MakeValidationErr(ErrorInvalidValue, "FunctionSpec.PodSpec", spec.PodSpec, "container functions need a container image"))
+		}
+	} else if spec.PodSpec != nil {
+		result = multierror.Append(result, MakeValidationErr(ErrorInvalidValue, "FunctionSpec.PodSpec", spec.PodSpec, "only container functions have a pod spec"))
+	}
+
+	if spec.Concurrency < 0 {
+		result = multierror.Append(result, MakeValidationErr(ErrorInvalidValue, "FunctionSpec.Concurrency", spec.Concurrency, "must not be negative"))
+	}
+	if spec.RequestQueueLength < 0 {
+		result = multierror.Append(result, MakeValidationErr(ErrorInvalidValue, "FunctionSpec.RequestQueueLength", spec.RequestQueueLength, "must not be negative"))
+	}
+	if spec.RequestQueueLength > 0 && spec.Concurrency == 0 {
+		result = multierror.Append(result, MakeValidationErr(ErrorInvalidValue, "FunctionSpec.RequestQueueLength", spec.RequestQueueLength, "requires a concurrency limit"))
+	}
+	if spec.IdleTimeout < 0 {
+		result = multierror.Append(result, MakeValidationErr(ErrorInvalidValue, "FunctionSpec.IdleTimeout", spec.IdleTimeout, "must not be negative"))
+	}
+
+	if spec.Spread != nil {
+		result = multierror.Append(result, spec.Spread.Validate())
+	}
+
+	mountPaths := make(map[string]bool)
+	for _, v := range spec.Volumes {
+		result = multierror.Append(result, v.Validate())
+		if mountPaths[v.MountPath] {
+			result = multierror.Append(result, MakeValidationErr(ErrorInvalidValue, "FunctionVolume.MountPath", v.MountPath, "more than one volume mounted at the path"))
+		}
+		mountPaths[v.MountPath] = true
+	}
+
+	switch spec.InvokeStrategy.ExecutionStrategy.ExecutorType {
+	case ExecutorTypeNewdeploy, ExecutorTypeContainer:
+	default:
+		if len(spec.NodeSelector) > 0 {
+			result = multierror.Append(result, MakeValidationErr(ErrorInvalidValue, "FunctionSpec.NodeSelector", spec.NodeSelector, "only newdeploy and container functions have a node selector"))
+		}
+		if len(spec.Tolerations) > 0 {
+			result = multierror.Append(result, MakeValidationErr(ErrorInvalidValue, "FunctionSpec.Tolerations", spec.Tolerations, "only newdeploy and container functions have tolerations"))
+		}
+		if len(spec.Volumes) > 0 {
+			result = multierror.Append(result, MakeValidationErr(ErrorInvalidValue, "FunctionSpec.Volumes", spec.Volumes, "only newdeploy and container functions have volumes"))
+		}
+	}
+
 	// TODO Add below validation warning
 	/*if spec.FunctionTimeout <= 0 {
 		result = multierror.Append(result, MakeValidationErr(ErrorInvalidValue, "FunctionTimeout value", spec.FunctionTimeout, "not a valid value. Should always be more than 0"))
@@ -314,6 +518,54 @@ func (spec FunctionSpec) Validate() error {
 	return result.ErrorOrNil()
 }
 
+func (spread Spread) Validate() error {
+	switch spread.Topology {
+	case SpreadTopologyNode, SpreadTopologyZone:
+		return nil
+	default:
+		return MakeValidationErr(ErrorUnsupportedType, "Spread.Topology", spread.Topology, "not a supported topology, must be node or zone")
+	}
+}
+
+func (img PrebuiltImage) Validate() error {
+	result := &multierror.Error{}
+
+	if len(img.Image) == 0 {
+		result = multierror.Append(result, MakeValidationErr(ErrorInvalidValue, "PrebuiltImage.Image", img.Image, "must not be empty"))
+	}
+	if len(img.Path) > 0 && !path.IsAbs(img.Path) {
+		result = multierror.Append(result, MakeValidationErr(ErrorInvalidValue, "PrebuiltImage.Path", img.Path, "must be an absolute path"))
+	}
+
+	return result.ErrorOrNil()
+}
+
+func (v FunctionVolume) Validate() error {
+	result := &multierror.Error{}
+
+	switch v.Type {
+	case FunctionVolumeTypePVC:
+		if v.SizeLimit != nil {
+			result = multierror.Append(result, MakeValidationErr(ErrorInvalidValue, "FunctionVolume.SizeLimit", v.SizeLimit.String(), "only emptydir volumes have a size limit"))
+		}
+	case FunctionVolumeTypeEmptyDir:
+		if v.SizeLimit != nil && v.SizeLimit.Sign() <= 0 {
+			result = multierror.Append(result, MakeValidationErr(ErrorInvalidValue, "FunctionVolume.SizeLimit", v.SizeLimit.String(), "must be greater than 0"))
+		}
+	default:
+		result = multierror.Append(result, MakeValidationErr(ErrorUnsupportedType, "FunctionVolume.Type", v.Type, "not a supported volume type, must be pvc or emptydir"))
+	}
+
+	if len(v.Name) == 0 {
+		result = multierror.Append(result, MakeValidationErr(ErrorInvalidValue, "FunctionVolume.Name", v.Name, "must not be empty"))
+	}
+	if !path.IsAbs(v.MountPath) {
+		result = multierror.Append(result, MakeValidationErr(ErrorInvalidValue, "FunctionVolume.MountPath", v.MountPath, "must be an absolute path"))
+	}
+
+	return result.ErrorOrNil()
+}
+
 func (is InvokeStrategy) Validate() error {
 	result := &multierror.Error{}
 
@@ -332,12 +584,12 @@ func (es ExecutionStrategy) Validate() error {
 	result := &multierror.Error{}
 
 	switch es.ExecutorType {
-	case ExecutorTypeNewdeploy, ExecutorTypePoolmgr: // no op
+	case ExecutorTypeNewdeploy, ExecutorTypePoolmgr, ExecutorTypeContainer: // no op
 	default:
 		result = multierror.Append(result, MakeValidationErr(ErrorUnsupportedType, "ExecutionStrategy.ExecutorType", es.ExecutorType, "not a valid executor type"))
 	}
 
-	if es.ExecutorType == ExecutorTypeNewdeploy {
+	if es.ExecutorType == ExecutorTypeNewdeploy || es.ExecutorType == ExecutorTypeContainer {
 		if es.MinScale < 0 {
 			result = multierror.Append(result, MakeValidationErr(ErrorInvalidValue, "ExecutionStrategy.MinScale", es.MinScale, "minimum scale must be greater or equal to 0"))
 		}
@@ -360,6 +612,29 @@ func (es ExecutionStrategy) Validate() error {
 		//}
 	}
 
+	if es.Multiplex && es.ExecutorType != ExecutorTypePoolmgr {
+		result = multierror.Append(result, MakeValidationErr(ErrorInvalidValue, "ExecutionStrategy.Multiplex", es.Multiplex, "multiplexing is only supported by the poolmgr executor"))
+	}
+
+	switch es.Isolation {
+	case "", IsolationModeShared: // no op
+	case IsolationModeInvocation:
+		if es.ExecutorType != ExecutorTypePoolmgr {
+			result = multierror.Append(result, MakeValidationErr(ErrorInvalidValue, "ExecutionStrategy.Isolation", es.Isolation, "isolation per invocation is only supported by the poolmgr executor"))
+		}
+		if es.Multiplex {
+			result = multierror.Append(result, MakeValidationErr(ErrorInvalidValue, "ExecutionStrategy.Isolation", es.Isolation, "a function isolated per invocation cannot be multiplexed"))
+		}
+	default:
+		result = multierror.Append(result, MakeValidationErr(ErrorUnsupportedType, "ExecutionStrategy.Isolation", es.Isolation, "not a valid isolation mode"))
+	}
+
+	if es.PrespecializedPods < 0 {
+		result = multierror.Append(result, MakeValidationErr(ErrorInvalidValue, "ExecutionStrategy.PrespecializedPods", es.PrespecializedPods, "number of prespecialized pods must be greater or equal to 0"))
+	} else if es.PrespecializedPods > 0 && es.Isolation != IsolationModeInvocation {
+		result = multierror.Append(result, MakeValidationErr(ErrorInvalidValue, "ExecutionStrategy.PrespecializedPods", es.PrespecializedPods, "pods are only prespecialized for functions isolated per invocation"))
+	}
+
 	return result.ErrorOrNil()
 }
 
@@ -369,11 +644,12 @@ func (ref FunctionReference) Validate() error {
 	switch ref.Type {
 	case FunctionReferenceTypeFunctionName: // no op
 	case FunctionReferenceTypeFunctionWeights: // no op
+	case FunctionReferenceTypeFunctionAlias: // no op
 	default:
 		result = multierror.Append(result, MakeValidationErr(ErrorUnsupportedType, "FunctionReference.Type", ref.Type, "not a valid function reference type"))
 	}
 
-	if ref.Type == FunctionReferenceTypeFunctionName {
+	if ref.Type == FunctionReferenceTypeFunctionName || ref.Type == FunctionReferenceTypeFunctionAlias {
 		result = multierror.Append(result, ValidateKubeName("FunctionReference.Name", ref.Name))
 	}
 
@@ -395,8 +671,17 @@ func (runtime Runtime) Validate() error {
 }
 
 func (builder Builder) Validate() error {
-	// do nothing for now
-	return nil
+	result := &multierror.Error{}
+
+	if builder.PoolSize < 0 {
+		result = multierror.Append(result, MakeValidationErr(ErrorInvalidValue, "Builder.PoolSize", builder.PoolSize, "must be greater or equal to 0"))
+	}
+
+	if builder.Cache != nil && builder.Cache.Size.Sign() <= 0 {
+		result = multierror.Append(result, MakeValidationErr(ErrorInvalidValue, "Builder.Cache.Size", builder.Cache.Size.String(), "must be greater than 0"))
+	}
+
+	return result.ErrorOrNil()
 }
 
 func (spec EnvironmentSpec) Validate() error {
@@ -424,17 +709,42 @@ func (spec EnvironmentSpec) Validate() error {
 		result = multierror.Append(result, MakeValidationErr(ErrorInvalidValue, "EnvironmentSpec.Poolsize", spec.Poolsize, "Poolsize must be greater or equal to 0"))
 	}
 
+	if len(spec.RuntimeClassName) > 0 {
+		e := validation.IsDNS1123Subdomain(spec.RuntimeClassName)
+		if len(e) > 0 {
+			result = multierror.Append(result, MakeValidationErr(ErrorInvalidValue, "EnvironmentSpec.RuntimeClassName", spec.RuntimeClassName, e...))
+		}
+	}
+
+	if len(spec.ImagePullSecret) > 0 {
+		e := validation.IsDNS1123Subdomain(spec.ImagePullSecret)
+		if len(e) > 0 {
+			result = multierror.Append(result, MakeValidationErr(ErrorInvalidValue, "EnvironmentSpec.ImagePullSecret", spec.ImagePullSecret, e...))
+		}
+	}
+
 	return result.ErrorOrNil()
 }
 
 func (spec HTTPTriggerSpec) Validate() error {
 	result := &multierror.Error{}
 
-	switch spec.Method {
-	case http.MethodGet, http.MethodHead, http.MethodPost, http.MethodPut, http.MethodPatch,
-		http.MethodDelete, http.MethodConnect, http.MethodOptions, http.MethodTrace: // no op
-	default:
-		result = multierror.Append(result, MakeValidationErr(ErrorUnsupportedType, "HTTPTriggerSpec.Method", spec.Method, "not a valid HTTP method"))
+	field := "HTTPTriggerSpec.Method"
+	if len(spec.Methods) > 0 {
+		field = "HTTPTriggerSpec.Methods"
+	}
+	methods := spec.GetMethods()
+	for _, method := range methods {
+		switch method {
+		case http.MethodGet, http.MethodHead, http.MethodPost, http.MethodPut, http.MethodPatch,
+			http.MethodDelete, http.MethodConnect, http.MethodOptions, http.MethodTrace: // no op
+		case HTTPMethodAny:
+			if len(methods) > 1 {
+				result = multierror.Append(result, MakeValidationErr(ErrorInvalidValue, field, methods, "'*' matches all methods, no other method can be given"))
+			}
+		default:
+			result = multierror.Append(result, MakeValidationErr(ErrorUnsupportedType, field, method, "not a valid HTTP method"))
+		}
 	}
 
 	result = multierror.Append(result, spec.FunctionReference.Validate())
@@ -448,6 +758,185 @@ func (spec HTTPTriggerSpec) Validate() error {
 
 	result = multierror.Append(result, spec.IngressConfig.Validate())
 
+	if len(spec.Prefix) > 0 {
+		if len(spec.RelativeURL) > 0 {
+			result = multierror.Append(result, MakeValidationErr(ErrorInvalidValue, "HTTPTriggerSpec.Prefix", spec.Prefix, "can not be used with RelativeURL"))
+		}
+		if !strings.HasPrefix(spec.Prefix, "/") {
+			result = multierror.Append(result, MakeValidationErr(ErrorInvalidValue, "HTTPTriggerSpec.Prefix", spec.Prefix, "must start with '/'"))
+		}
+		if strings.ContainsAny(spec.Prefix, "{}") {
+			result = multierror.Append(result, MakeValidationErr(ErrorInvalidValue, "HTTPTriggerSpec.Prefix", spec.Prefix, "path parameters are not supported in prefixes"))
+		}
+	} else if spec.KeepPrefix {
+		result = multierror.Append(result, MakeValidationErr(ErrorInvalidValue, "HTTPTriggerSpec.KeepPrefix", spec.KeepPrefix, "can only be used with Prefix"))
+	}
+
+	// websocket handshake is always a GET request
+	if spec.AllowWebsocket && !spec.HasMethod(http.MethodGet) {
+		result = multierror.Append(result, MakeValidationErr(ErrorInvalidValue, "HTTPTriggerSpec.AllowWebsocket", spec.AllowWebsocket, "websocket is only supported with method GET"))
+	}
+
+	switch spec.Protocol {
+	case "", HTTPTriggerProtocolHTTP: // no op
+	case HTTPTriggerProtocolGRPC:
+		// gRPC calls are always POST requests
+		if !spec.HasMethod(http.MethodPost) {
+			result = multierror.Append(result, MakeValidationErr(ErrorInvalidValue, "HTTPTriggerSpec.Protocol", spec.Protocol, "grpc is only supported with method POST"))
+		}
+		if spec.AllowWebsocket {
+			result = multierror.Append(result, MakeValidationErr(ErrorInvalidValue, "HTTPTriggerSpec.Protocol", spec.Protocol, "grpc cannot be used with websocket"))
+		}
+	default:
+		result = multierror.Append(result, MakeValidationErr(ErrorUnsupportedType, "HTTPTriggerSpec.Protocol", spec.Protocol, "not a supported protocol, use http or grpc"))
+	}
+
+	if spec.FaultInjection != nil {
+		result = multierror.Append(result, spec.FaultInjection.Validate())
+	}
+
+	if spec.Timeouts != nil {
+		result = multierror.Append(result, spec.Timeouts.Validate())
+	}
+
+	if spec.RateLimit != nil {
+		result = multierror.Append(result, spec.RateLimit.Validate())
+	}
+
+	if spec.MaxConcurrentRequests < 0 {
+		result = multierror.Append(result, MakeValidationErr(ErrorInvalidValue, "HTTPTriggerSpec.MaxConcurrentRequests", spec.MaxConcurrentRequests, "must not be negative"))
+	}
+
+	if spec.RetryPolicy != nil {
+		result = multierror.Append(result, spec.RetryPolicy.Validate())
+	}
+
+	if spec.Auth != nil {
+		result = multierror.Append(result, spec.Auth.Validate())
+	}
+
+	if spec.APIVersioning != nil {
+		result = multierror.Append(result, spec.APIVersioning.Validate())
+	}
+
+	return result.ErrorOrNil()
+}
+
+func (versioning APIVersioning) Validate() error {
+	result := &multierror.Error{}
+
+	if len(versioning.Header) > 0 {
+		e := validation.IsHTTPHeaderName(versioning.Header)
+		if len(e) > 0 {
+			result = multierror.Append(result, MakeValidationErr(ErrorInvalidValue, "HTTPTriggerSpec.APIVersioning.Header", versioning.Header, e...))
+		}
+	}
+	if len(versioning.Functions) == 0 {
+		result = multierror.Append(result, MakeValidationErr(ErrorInvalidValue, "HTTPTriggerSpec.APIVersioning.Functions", versioning.Functions, "must map at least one version to a function"))
+	}
+	for version, fnName := range versioning.Functions {
+		if !validAPIVersion.MatchString(version) {
+			result = multierror.Append(result, MakeValidationErr(ErrorInvalidValue, "HTTPTriggerSpec.APIVersioning.Functions", version, "version must consist of alphanumeric characters, '-', '_' or '.'"))
+		}
+		result = multierror.Append(result, ValidateKubeName("HTTPTriggerSpec.APIVersioning.Functions", fnName))
+	}
+
+	return result.ErrorOrNil()
+}
+
+func (auth HTTPTriggerAuth) Validate() error {
+	result := &multierror.Error{}
+
+	switch auth.Type {
+	case HTTPTriggerAuthJWT:
+	case HTTPTriggerAuthAPIKey:
+		if len(auth.Issuer) > 0 || len(auth.Audience) > 0 {
+			result = multierror.Append(result, MakeValidationErr(ErrorInvalidValue, "HTTPTriggerSpec.Auth", auth.Type, "issuer and audience are only checked for jwt"))
+		}
+	default:
+		result = multierror.Append(result, MakeValidationErr(ErrorUnsupportedType, "HTTPTriggerSpec.Auth.Type", auth.Type, "not a valid auth type, must be jwt or apikey"))
+	}
+
+	if len(auth.Secret) == 0 {
+		result = multierror.Append(result, MakeValidationErr(ErrorInvalidValue, "HTTPTriggerSpec.Auth.Secret", auth.Secret, "need a secret with the keys"))
+	} else if e := validation.IsDNS1123Subdomain(auth.Secret); len(e) > 0 {
+		result = multierror.Append(result, MakeValidationErr(ErrorInvalidValue, "HTTPTriggerSpec.Auth.Secret", auth.Secret, e...))
+	}
+
+	return result.ErrorOrNil()
+}
+
+func (policy RetryPolicy) Validate() error {
+	result := &multierror.Error{}
+
+	if policy.Retries < 0 {
+		result = multierror.Append(result, MakeValidationErr(ErrorInvalidValue, "HTTPTriggerSpec.RetryPolicy.Retries", policy.Retries, "must not be negative"))
+	}
+	if policy.Retries > 0 && len(policy.RetryOn) == 0 {
+		result = multierror.Append(result, MakeValidationErr(ErrorInvalidValue, "HTTPTriggerSpec.RetryPolicy.RetryOn", policy.RetryOn, "must be set when retries is set"))
+	}
+	for _, cond := range policy.RetryOn {
+		switch cond {
+		case RetryOn5xx, RetryOnConnectFailure: // no op
+		default:
+			result = multierror.Append(result, MakeValidationErr(ErrorInvalidValue, "HTTPTriggerSpec.RetryPolicy.RetryOn", cond, fmt.Sprintf("must be one of %v, %v", RetryOn5xx, RetryOnConnectFailure)))
+		}
+	}
+
+	return result.ErrorOrNil()
+}
+
+func (limit RateLimit) Validate() error {
+	result := &multierror.Error{}
+
+	if limit.RequestsPerSecond <= 0 {
+		result = multierror.Append(result, MakeValidationErr(ErrorInvalidValue, "HTTPTriggerSpec.RateLimit.RequestsPerSecond", limit.RequestsPerSecond, "must be greater than 0"))
+	}
+	if limit.Burst < 0 {
+		result = multierror.Append(result, MakeValidationErr(ErrorInvalidValue, "HTTPTriggerSpec.RateLimit.Burst", limit.Burst, "must not be negative"))
+	}
+
+	return result.ErrorOrNil()
+}
+
+func (fault FaultInjection) Validate() error {
+	result := &multierror.Error{}
+
+	if fault.DelayPercentage < 0 || fault.DelayPercentage > 100 {
+		result = multierror.Append(result, MakeValidationErr(ErrorInvalidValue, "HTTPTriggerSpec.FaultInjection.DelayPercentage", fault.DelayPercentage, "must be between 0 and 100"))
+	}
+	if fault.Delay < 0 {
+		result = multierror.Append(result, MakeValidationErr(ErrorInvalidValue, "HTTPTriggerSpec.FaultInjection.Delay", fault.Delay, "must not be negative"))
+	}
+	if fault.DelayPercentage > 0 && fault.Delay == 0 {
+		result = multierror.Append(result, MakeValidationErr(ErrorInvalidValue, "HTTPTriggerSpec.FaultInjection.Delay", fault.Delay, "must be set when delay percentage is set"))
+	}
+	if fault.AbortPercentage < 0 || fault.AbortPercentage > 100 {
+		result = multierror.Append(result, MakeValidationErr(ErrorInvalidValue, "HTTPTriggerSpec.FaultInjection.AbortPercentage", fault.AbortPercentage, "must be between 0 and 100"))
+	}
+	if fault.AbortStatus != 0 && (fault.AbortStatus < 400 || fault.AbortStatus > 599) {
+		result = multierror.Append(result, MakeValidationErr(ErrorInvalidValue, "HTTPTriggerSpec.FaultInjection.AbortStatus", fault.AbortStatus, "must be an HTTP error status between 400 and 599"))
+	}
+
+	return result.ErrorOrNil()
+}
+
+func (timeouts UpstreamTimeouts) Validate() error {
+	result := &multierror.Error{}
+
+	if timeouts.ConnectTimeout < 0 {
+		result = multierror.Append(result, MakeValidationErr(ErrorInvalidValue, "HTTPTriggerSpec.Timeouts.ConnectTimeout", timeouts.ConnectTimeout, "must not be negative"))
+	}
+	if timeouts.ResponseHeaderTimeout < 0 {
+		result = multierror.Append(result, MakeValidationErr(ErrorInvalidValue, "HTTPTriggerSpec.Timeouts.ResponseHeaderTimeout", timeouts.ResponseHeaderTimeout, "must not be negative"))
+	}
+	if timeouts.TotalTimeout < 0 {
+		result = multierror.Append(result, MakeValidationErr(ErrorInvalidValue, "HTTPTriggerSpec.Timeouts.TotalTimeout", timeouts.TotalTimeout, "must not be negative"))
+	}
+	if timeouts.TotalTimeout > 0 && timeouts.ResponseHeaderTimeout > timeouts.TotalTimeout {
+		result = multierror.Append(result, MakeValidationErr(ErrorInvalidValue, "HTTPTriggerSpec.Timeouts.ResponseHeaderTimeout", timeouts.ResponseHeaderTimeout, "must not be longer than the total timeout"))
+	}
+
 	return result.ErrorOrNil()
 }
 
@@ -518,13 +1007,26 @@ func (spec KubernetesWatchTriggerSpec) Validate() error {
 	return result.ErrorOrNil()
 }
 
+func (config KafkaConfig) Validate() error {
+	result := &multierror.Error{}
+
+	for _, broker := range config.Brokers {
+		if len(strings.TrimSpace(broker)) == 0 {
+			result = multierror.Append(result, MakeValidationErr(ErrorInvalidValue, "KafkaConfig.Brokers", config.Brokers, "broker address cannot be empty"))
+			break
+		}
+	}
+
+	return result.ErrorOrNil()
+}
+
 func (spec MessageQueueTriggerSpec) Validate() error {
 	result := &multierror.Error{}
 
 	result = multierror.Append(result, spec.FunctionReference.Validate())
 
 	switch spec.MessageQueueType {
-	case MessageQueueTypeNats, MessageQueueTypeASQ, MessageQueueTypeKafka: // no op
+	case MessageQueueTypeNats, MessageQueueTypeASQ, MessageQueueTypeKafka, MessageQueueTypeRabbitMQ, MessageQueueTypeGCPPubSub, MessageQueueTypeAzureServiceBus, MessageQueueTypeNatsJetStream: // no op
 	default:
 		result = multierror.Append(result, MakeValidationErr(ErrorUnsupportedType, "MessageQueueTriggerSpec.MessageQueueType", spec.MessageQueueType, "not a supported message queue type"))
 	}
@@ -537,6 +1039,106 @@ func (spec MessageQueueTriggerSpec) Validate() error {
 		result = multierror.Append(result, MakeValidationErr(ErrorInvalidValue, "MessageQueueTriggerSpec.ResponseTopic", spec.ResponseTopic, "not a valid topic"))
 	}
 
+	if spec.Kafka != nil {
+		if spec.MessageQueueType != MessageQueueTypeKafka {
+			result = multierror.Append(result, MakeValidationErr(ErrorInvalidValue, "MessageQueueTriggerSpec.Kafka", spec.MessageQueueType, "kafka settings are only allowed for kafka message queue type"))
+		}
+		result = multierror.Append(result, spec.Kafka.Validate())
+	}
+
+	if spec.RabbitMQ != nil {
+		if spec.MessageQueueType != MessageQueueTypeRabbitMQ {
+			result = multierror.Append(result, MakeValidationErr(ErrorInvalidValue, "MessageQueueTriggerSpec.RabbitMQ", spec.MessageQueueType, "rabbitmq settings are only allowed for rabbitmq message queue type"))
+		}
+		result = multierror.Append(result, spec.RabbitMQ.Validate())
+	}
+
+	if spec.GCPPubSub != nil {
+		if spec.MessageQueueType != MessageQueueTypeGCPPubSub {
+			result = multierror.Append(result, MakeValidationErr(ErrorInvalidValue, "MessageQueueTriggerSpec.GCPPubSub", spec.MessageQueueType, "gcp pubsub settings are only allowed for gcp-pubsub message queue type"))
+		}
+		result = multierror.Append(result, spec.GCPPubSub.Validate())
+	}
+
+	if spec.AzureServiceBus != nil {
+		if spec.MessageQueueType != MessageQueueTypeAzureServiceBus {
+			result = multierror.Append(result, MakeValidationErr(ErrorInvalidValue, "MessageQueueTriggerSpec.AzureServiceBus", spec.MessageQueueType, "azure service bus settings are only allowed for azure-servicebus message queue type"))
+		}
+		result = multierror.Append(result, spec.AzureServiceBus.Validate())
+	}
+
+	if spec.NatsJetStream != nil {
+		if spec.MessageQueueType != MessageQueueTypeNatsJetStream {
+			result = multierror.Append(result, MakeValidationErr(ErrorInvalidValue, "MessageQueueTriggerSpec.NatsJetStream", spec.MessageQueueType, "nats jetstream settings are only allowed for nats-jetstream message queue type"))
+		}
+		result = multierror.Append(result, spec.NatsJetStream.Validate())
+	} else if spec.MessageQueueType == MessageQueueTypeNatsJetStream {
+		result = multierror.Append(result, MakeValidationErr(ErrorInvalidValue, "MessageQueueTriggerSpec.NatsJetStream", spec.NatsJetStream, "the stream of nats-jetstream triggers is required"))
+	}
+
+	if len(spec.Secret) > 0 {
+		switch spec.MessageQueueType {
+		case MessageQueueTypeKafka, MessageQueueTypeRabbitMQ, MessageQueueTypeGCPPubSub, MessageQueueTypeAzureServiceBus, MessageQueueTypeNatsJetStream:
+			result = multierror.Append(result, ValidateKubeName("MessageQueueTriggerSpec.Secret", spec.Secret))
+		default:
+			result = multierror.Append(result, MakeValidationErr(ErrorInvalidValue, "MessageQueueTriggerSpec.Secret", spec.MessageQueueType, "connection secrets are only supported for kafka, rabbitmq, gcp-pubsub, azure-servicebus and nats-jetstream message queue types"))
+		}
+	}
+
+	return result.ErrorOrNil()
+}
+
+func (config RabbitMQConfig) Validate() error {
+	result := &multierror.Error{}
+
+	if config.Prefetch < 0 {
+		result = multierror.Append(result, MakeValidationErr(ErrorInvalidValue, "RabbitMQConfig.Prefetch", config.Prefetch, "prefetch must be greater or equal to 0"))
+	}
+
+	if len(config.CredentialSecret) > 0 {
+		result = multierror.Append(result, ValidateKubeName("RabbitMQConfig.CredentialSecret", config.CredentialSecret))
+	}
+
+	return result.ErrorOrNil()
+}
+
+func (config GCPPubSubConfig) Validate() error {
+	result := &multierror.Error{}
+
+	if len(config.Subscription) > 0 && !IsValidGCPPubSubName(config.Subscription) {
+		result = multierror.Append(result, MakeValidationErr(ErrorInvalidValue, "GCPPubSubConfig.Subscription", config.Subscription, "not a valid subscription ID"))
+	}
+
+	if config.AckDeadline != 0 && (config.AckDeadline < 10 || config.AckDeadline > 600) {
+		result = multierror.Append(result, MakeValidationErr(ErrorInvalidValue, "GCPPubSubConfig.AckDeadline", config.AckDeadline, "ack deadline must be between 10 and 600 seconds"))
+	}
+
+	return result.ErrorOrNil()
+}
+
+func (config AzureServiceBusConfig) Validate() error {
+	if len(config.Subscription) > 0 && !validAzureServiceBusSubscriptionName.MatchString(config.Subscription) {
+		return MakeValidationErr(ErrorInvalidValue, "AzureServiceBusConfig.Subscription", config.Subscription, "not a valid subscription name")
+	}
+	return nil
+}
+
+func (config NatsJetStreamConfig) Validate() error {
+	result := &multierror.Error{}
+
+	if !validNatsJetStreamName.MatchString(config.Stream) {
+		result = multierror.Append(result, MakeValidationErr(ErrorInvalidValue, "NatsJetStreamConfig.Stream", config.Stream, "not a valid stream name"))
+	}
+	if len(config.Durable) > 0 && !validNatsJetStreamName.MatchString(config.Durable) {
+		result = multierror.Append(result, MakeValidationErr(ErrorInvalidValue, "NatsJetStreamConfig.Durable", config.Durable, "not a valid consumer name"))
+	}
+	if config.AckWait < 0 {
+		result = multierror.Append(result, MakeValidationErr(ErrorInvalidValue, "NatsJetStreamConfig.AckWait", config.AckWait, "ack wait must not be negative"))
+	}
+	if config.MaxDeliver < 0 {
+		result = multierror.Append(result, MakeValidationErr(ErrorInvalidValue, "NatsJetStreamConfig.MaxDeliver", config.MaxDeliver, "max deliver must not be negative"))
+	}
+
 	return result.ErrorOrNil()
 }
 
@@ -575,7 +1177,101 @@ func (spec TimeTriggerSpec) Validate() error {
 		result = multierror.Append(result, MakeValidationErr(ErrorInvalidValue, "TimeTriggerSpec.Cron", spec.Cron, "not a valid cron spec"))
 	}
 
+	if len(spec.Timezone) > 0 {
+		_, err = time.LoadLocation(spec.Timezone)
+		if err != nil {
+			result = multierror.Append(result, MakeValidationErr(ErrorInvalidValue, "TimeTriggerSpec.Timezone", spec.Timezone, "not a valid time zone"))
+		}
+	}
+
 	result = multierror.Append(result, spec.FunctionReference.Validate())
 
 	return result.ErrorOrNil()
 }
+
+func (spec CanaryConfigSpec) Validate() error {
+	result := &multierror.Error{}
+
+	_, err := time.ParseDuration(spec.WeightIncrementDuration)
+	if err != nil {
+		result = multierror.Append(result, MakeValidationErr(ErrorInvalidValue, "CanaryConfigSpec.WeightIncrementDuration", spec.WeightIncrementDuration, "not a valid duration"))
+	}
+	if spec.LatencyThreshold < 0 {
+		result = multierror.Append(result, MakeValidationErr(ErrorInvalidValue, "CanaryConfigSpec.LatencyThreshold", spec.LatencyThreshold, "must not be negative"))
+	}
+	for _, query := range spec.SuccessQueries {
+		_, err := template.New("query").Parse(query)
+		if err != nil {
+			result = multierror.Append(result, MakeValidationErr(ErrorInvalidValue, "CanaryConfigSpec.SuccessQueries", query, err.Error()))
+		}
+	}
+	for _, query := range spec.FailureQueries {
+		_, err := template.New("query").Parse(query)
+		if err != nil {
+			result = multierror.Append(result, MakeValidationErr(ErrorInvalidValue, "CanaryConfigSpec.FailureQueries", query, err.Error()))
+		}
+	}
+	for _, webhook := range []struct{ field, url string }{
+		{"CanaryConfigSpec.OnSuccessWebhook", spec.OnSuccessWebhook},
+		{"CanaryConfigSpec.OnFailureWebhook", spec.OnFailureWebhook},
+	} {
+		if len(webhook.url) == 0 {
+			continue
+		}
+		u, err := url.Parse(webhook.url)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || len(u.Host) == 0 {
+			result = multierror.Append(result, MakeValidationErr(ErrorInvalidValue, webhook.field, webhook.url, "not a valid http or https URL"))
+		}
+	}
+	switch spec.WebhookFormat {
+	case "", CanaryWebhookFormatJSON, CanaryWebhookFormatSlack: // no op
+	default:
+		result = multierror.Append(result, MakeValidationErr(ErrorUnsupportedType, "CanaryConfigSpec.WebhookFormat", spec.WebhookFormat, "not a supported webhook format"))
+	}
+
+	return result.ErrorOrNil()
+}
+
+func (spec FunctionAliasSpec) Validate() error {
+	result := &multierror.Error{}
+
+	if len(spec.FunctionWeights) == 0 {
+		result = multierror.Append(result, MakeValidationErr(ErrorInvalidValue, "FunctionAliasSpec.FunctionWeights", spec.FunctionWeights, "must reference at least one function"))
+	}
+	sum := 0
+	for name, weight := range spec.FunctionWeights {
+		result = multierror.Append(result, ValidateKubeName("FunctionAliasSpec.FunctionWeights", name))
+		if weight < 0 || weight > 100 {
+			result = multierror.Append(result, MakeValidationErr(ErrorInvalidValue, "FunctionAliasSpec.FunctionWeights", weight, "must be between 0 and 100"))
+		}
+		sum += weight
+	}
+	if len(spec.FunctionWeights) > 0 && sum != 100 {
+		result = multierror.Append(result, MakeValidationErr(ErrorInvalidValue, "FunctionAliasSpec.FunctionWeights", sum, "weights must add up to 100"))
+	}
+
+	return result.ErrorOrNil()
+}
+
+func (spec CanaryPolicySpec) Validate() error {
+	result := &multierror.Error{}
+
+	if spec.WeightIncrement <= 0 || spec.WeightIncrement > 100 {
+		result = multierror.Append(result, MakeValidationErr(ErrorInvalidValue, "CanaryPolicySpec.WeightIncrement", spec.WeightIncrement, "must be between 1 and 100"))
+	}
+	_, err := time.ParseDuration(spec.WeightIncrementDuration)
+	if err != nil {
+		result = multierror.Append(result, MakeValidationErr(ErrorInvalidValue, "CanaryPolicySpec.WeightIncrementDuration", spec.WeightIncrementDuration, "not a valid duration"))
+	}
+	if spec.FailureThreshold < 0 || spec.FailureThreshold > 100 {
+		result = multierror.Append(result, MakeValidationErr(ErrorInvalidValue, "CanaryPolicySpec.FailureThreshold", spec.FailureThreshold, "must be between 0 and 100"))
+	}
+	if spec.FailureType != FailureTypeStatusCode {
+		result = multierror.Append(result, MakeValidationErr(ErrorUnsupportedType, "CanaryPolicySpec.FailureType", spec.FailureType, "not a supported failure type"))
+	}
+	if spec.LatencyThreshold < 0 {
+		result = multierror.Append(result, MakeValidationErr(ErrorInvalidValue, "CanaryPolicySpec.LatencyThreshold", spec.LatencyThreshold, "must not be negative"))
+	}
+
+	return result.ErrorOrNil()
+}
@@ -41,6 +41,11 @@ const (
 	// supported environment variables
 	envSrcPkg    = "SRC_PKG"
 	envDeployPkg = "DEPLOY_PKG"
+	// name of the build step being run, only set in multi-step builds
+	envBuildStep = "BUILD_STEP"
+
+	// build steps are shell commands
+	stepShell = "/bin/sh"
 )
 
 type (
@@ -52,16 +57,40 @@ type (
 		// 1. SRC_PKG: path to source package directory
 		// 2. DEPLOY_PKG: path to deployment package directory
 		BuildCommand string `json:"command"`
+		// Steps to run one after another instead of the build command.
+		BuildSteps []BuildStep `json:"steps,omitempty"`
+		// Environment variables of this build in the form of KEY=VALUE,
+		// they may hold secrets so only their names are logged.
+		Env []string `json:"env,omitempty"`
 	}
 
 	PackageBuildResponse struct {
 		ArtifactFilename string `json:"artifactFilename"`
 		BuildLogs        string `json:"buildLogs"`
+		// Steps has the result of each build step that was run.
+		Steps []BuildStepResult `json:"steps,omitempty"`
+	}
+
+	// BuildStep is a step of a multi-step build.
+	BuildStep struct {
+		Name    string `json:"name"`
+		Command string `json:"command"`
+	}
+
+	// BuildStepResult is the result of a build step.
+	BuildStepResult struct {
+		Name            string    `json:"name"`
+		Succeeded       bool      `json:"succeeded"`
+		Message         string    `json:"message,omitempty"`
+		BuildLogs       string    `json:"buildLogs"`
+		StartTimestamp  time.Time `json:"startTimestamp"`
+		FinishTimestamp time.Time `json:"finishTimestamp"`
 	}
 
 	Builder struct {
 		logger           *zap.Logger
 		sharedVolumePath string
+		cacheDir         string
 	}
 )
 
@@ -69,6 +98,7 @@ func MakeBuilder(logger *zap.Logger, sharedVolumePath string) *Builder {
 	return &Builder{
 		logger:           logger.Named("builder"),
 		sharedVolumePath: sharedVolumePath,
+		cacheDir:         os.Getenv(EnvBuildCacheDir),
 	}
 }
 
@@ -81,7 +111,7 @@ func (builder *Builder) Handler(w http.ResponseWriter, r *http.Request) {
 	if r.Method != "POST" {
 		e := "method not allowed"
 		builder.logger.Error(e, zap.String("http_method", r.Method))
-		builder.reply(w, "", fmt.Sprintf("%s: %s", e, r.Method), http.StatusMethodNotAllowed)
+		builder.reply(w, "", fmt.Sprintf("%s: %s", e, r.Method), nil, http.StatusMethodNotAllowed)
 		return
 	}
 
@@ -96,7 +126,7 @@ func (builder *Builder) Handler(w http.ResponseWriter, r *http.Request) {
 	if err != nil {
 		e := "error reading request body"
 		builder.logger.Error(e, zap.Error(err))
-		builder.reply(w, "", fmt.Sprintf("%s: %s", e, err.Error()), http.StatusInternalServerError)
+		builder.reply(w, "", fmt.Sprintf("%s: %s", e, err.Error()), nil, http.StatusInternalServerError)
 		return
 	}
 	var req PackageBuildRequest
@@ -104,38 +134,90 @@ func (builder *Builder) Handler(w http.ResponseWriter, r *http.Request) {
 	if err != nil {
 		e := "error parsing json body"
 		builder.logger.Error(e, zap.Error(err))
-		builder.reply(w, "", fmt.Sprintf("%s: %s", e, err.Error()), http.StatusBadRequest)
+		builder.reply(w, "", fmt.Sprintf("%s: %s", e, err.Error()), nil, http.StatusBadRequest)
 		return
 	}
-	builder.logger.Info("builder received request", zap.Any("request", req))
+	logReq := req
+	logReq.Env = nil
+	builder.logger.Info("builder received request", zap.Any("request", logReq), zap.Strings("env", envNames(req.Env)))
 
 	builder.logger.Info("starting build")
 	srcPkgPath := filepath.Join(builder.sharedVolumePath, req.SrcPkgFilename)
 	deployPkgFilename := fmt.Sprintf("%v-%v", req.SrcPkgFilename, strings.ToLower(uniuri.NewLen(6)))
 	deployPkgPath := filepath.Join(builder.sharedVolumePath, deployPkgFilename)
-	buildCmd := req.BuildCommand
-	if len(buildCmd) == 0 {
-		// use default build command
-		buildCmd = "/build"
+
+	var buildLogs string
+	var steps []BuildStepResult
+	if len(req.BuildSteps) > 0 {
+		buildLogs, steps, err = builder.buildSteps(req.BuildSteps, srcPkgPath, deployPkgPath, req.Env)
+	} else {
+		buildCmd := req.BuildCommand
+		if len(buildCmd) == 0 {
+			// use default build command
+			buildCmd = "/build"
+		}
+		buildLogs, err = builder.build(srcPkgPath, deployPkgPath, nil, req.Env, buildCmd)
 	}
-	buildLogs, err := builder.build(buildCmd, srcPkgPath, deployPkgPath)
 	if err != nil {
 		e := "error building source package"
 		builder.logger.Error(e, zap.Error(err))
 
 		// append error at the end of build logs
 		buildLogs += fmt.Sprintf("%s: %s\n", e, err.Error())
-		builder.reply(w, deployPkgFilename, buildLogs, http.StatusInternalServerError)
+		builder.reply(w, deployPkgFilename, buildLogs, steps, http.StatusInternalServerError)
 		return
 	}
 
-	builder.reply(w, deployPkgFilename, buildLogs, http.StatusOK)
+	builder.reply(w, deployPkgFilename, buildLogs, steps, http.StatusOK)
+}
+
+// buildSteps runs the build steps one after another on the same source
+// and deployment package, and stops at the first failed step.
+func (builder *Builder) buildSteps(steps []BuildStep, srcPkgPath string, deployPkgPath string, buildEnv []string) (string, []BuildStepResult, error) {
+	var buildLogs string
+	results := make([]BuildStepResult, 0, len(steps))
+
+	for _, step := range steps {
+		builder.logger.Info("starting build step", zap.String("step", step.Name))
+		result := BuildStepResult{
+			Name:           step.Name,
+			StartTimestamp: time.Now().UTC(),
+		}
+
+		env := []string{fmt.Sprintf("%v=%v", envBuildStep, step.Name)}
+		logs, err := builder.build(srcPkgPath, deployPkgPath, env, buildEnv, stepShell, "-c", step.Command)
+		result.BuildLogs = logs
+		result.FinishTimestamp = time.Now().UTC()
+		buildLogs += logs
+
+		if err != nil {
+			result.Message = err.Error()
+			results = append(results, result)
+			return buildLogs, results, errors.Wrapf(err, "build step %q failed", step.Name)
+		}
+
+		result.Succeeded = true
+		results = append(results, result)
+	}
+
+	return buildLogs, results, nil
 }
 
-func (builder *Builder) reply(w http.ResponseWriter, pkgFilename string, buildLogs string, statusCode int) {
+// envNames returns the names of the environment variables in the form of
+// KEY=VALUE, without their values.
+func envNames(env []string) []string {
+	names := make([]string, 0, len(env))
+	for _, e := range env {
+		names = append(names, strings.SplitN(e, "=", 2)[0])
+	}
+	return names
+}
+
+func (builder *Builder) reply(w http.ResponseWriter, pkgFilename string, buildLogs string, steps []BuildStepResult, statusCode int) {
 	resp := PackageBuildResponse{
 		ArtifactFilename: pkgFilename,
 		BuildLogs:        buildLogs,
+		Steps:            steps,
 	}
 
 	rBody, err := json.Marshal(resp)
@@ -152,8 +234,10 @@ func (builder *Builder) reply(w http.ResponseWriter, pkgFilename string, buildLo
 	w.Write(rBody)
 }
 
-func (builder *Builder) build(command string, srcPkgPath string, deployPkgPath string) (string, error) {
-	cmd := exec.Command(command)
+// build runs the command on the source package. The build environment
+// variables of the package are set last and only their names are printed.
+func (builder *Builder) build(srcPkgPath string, deployPkgPath string, env []string, buildEnv []string, command string, args ...string) (string, error) {
+	cmd := exec.Command(command, args...)
 
 	fi, err := os.Stat(srcPkgPath)
 	if err != nil {
@@ -170,6 +254,18 @@ func (builder *Builder) build(command string, srcPkgPath string, deployPkgPath s
 		fmt.Sprintf("%v=%v", envSrcPkg, srcPkgPath),
 		fmt.Sprintf("%v=%v", envDeployPkg, deployPkgPath),
 	)
+	cmd.Env = append(cmd.Env, env...)
+
+	// reuse downloaded dependencies across builds if the environment has a build cache
+	if len(builder.cacheDir) > 0 {
+		cacheEnv, err := builder.prepareCache(cmd.Dir)
+		if err != nil {
+			// a broken cache should never fail the build
+			builder.logger.Error("error preparing build cache", zap.Error(err))
+		} else {
+			cmd.Env = append(cmd.Env, cacheEnv...)
+		}
+	}
 
 	stdout, err := cmd.StdoutPipe()
 	if err != nil {
@@ -185,8 +281,12 @@ func (builder *Builder) build(command string, srcPkgPath string, deployPkgPath s
 
 	fmt.Printf("\n=== Build Logs ===")
 	// Init logs
-	fmt.Printf("command=%v\n", command)
+	fmt.Printf("command=%v\n", strings.Join(cmd.Args, " "))
 	fmt.Printf("env=%v\n", cmd.Env)
+	if len(buildEnv) > 0 {
+		fmt.Printf("buildenv=%v\n", envNames(buildEnv))
+		cmd.Env = append(cmd.Env, buildEnv...)
+	}
 
 	out := io.MultiReader(stdout, stderr)
 	scanner := bufio.NewScanner(out)
@@ -0,0 +1,102 @@
+/*
+Copyright 2019 The Fission Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package builder
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	// EnvBuildCacheDir is set by the builder manager to the mount path of
+	// the environment's build cache volume, if the environment has one.
+	EnvBuildCacheDir = "BUILD_CACHE_DIR"
+
+	// envBuildCache is exposed to build commands and points to the cache
+	// directory picked for the current source package.
+	envBuildCache = "BUILD_CACHE"
+
+	defaultCacheKey = "default"
+)
+
+// lockfiles lists the dependency manifests used to key the build cache,
+// in order of preference.
+var lockfiles = []string{
+	"requirements.txt",
+	"Pipfile.lock",
+	"package-lock.json",
+	"yarn.lock",
+	"go.sum",
+	"Gemfile.lock",
+	"composer.lock",
+	"pom.xml",
+}
+
+// getCacheKey returns the sha256 checksum of the first lockfile found
+// at the top of the source package, or defaultCacheKey if there is none.
+func getCacheKey(srcPkgPath string) (string, error) {
+	for _, name := range lockfiles {
+		f, err := os.Open(filepath.Join(srcPkgPath, name))
+		if os.IsNotExist(err) {
+			continue
+		} else if err != nil {
+			return "", errors.Wrapf(err, "error opening lockfile %q", name)
+		}
+
+		h := sha256.New()
+		_, err = io.Copy(h, f)
+		f.Close()
+		if err != nil {
+			return "", errors.Wrapf(err, "error reading lockfile %q", name)
+		}
+		return hex.EncodeToString(h.Sum(nil)), nil
+	}
+	return defaultCacheKey, nil
+}
+
+// prepareCache creates the cache directory for the source package and
+// returns the environment variables pointing common package managers at it.
+func (builder *Builder) prepareCache(srcPkgPath string) ([]string, error) {
+	key, err := getCacheKey(srcPkgPath)
+	if err != nil {
+		return nil, err
+	}
+
+	dir := filepath.Join(builder.cacheDir, key)
+	err = os.MkdirAll(dir, 0755)
+	if err != nil {
+		return nil, errors.Wrapf(err, "error creating build cache directory %q", dir)
+	}
+
+	return []string{
+		fmt.Sprintf("%v=%v", envBuildCache, dir),
+		fmt.Sprintf("PIP_CACHE_DIR=%v", filepath.Join(dir, "pip")),
+		fmt.Sprintf("npm_config_cache=%v", filepath.Join(dir, "npm")),
+		fmt.Sprintf("YARN_CACHE_FOLDER=%v", filepath.Join(dir, "yarn")),
+		fmt.Sprintf("GOMODCACHE=%v", filepath.Join(dir, "gomod")),
+		fmt.Sprintf("GOCACHE=%v", filepath.Join(dir, "gocache")),
+		fmt.Sprintf("BUNDLE_PATH=%v", filepath.Join(dir, "bundle")),
+		fmt.Sprintf("COMPOSER_CACHE_DIR=%v", filepath.Join(dir, "composer")),
+		fmt.Sprintf("MAVEN_OPTS=-Dmaven.repo.local=%v", filepath.Join(dir, "m2")),
+	}, nil
+}
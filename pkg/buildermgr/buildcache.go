@@ -0,0 +1,168 @@
+/*
+Copyright 2019 The Fission Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package buildermgr
+
+import (
+	"fmt"
+
+	"github.com/pkg/errors"
+	"go.uber.org/zap"
+	apiv1 "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+
+	fv1 "github.com/fission/fission/pkg/apis/fission.io/v1"
+	"github.com/fission/fission/pkg/builder"
+)
+
+const (
+	LABEL_BUILD_CACHE = "buildCache"
+
+	buildCacheVolume    = "build-cache"
+	buildCacheMountPath = "/build-cache"
+)
+
+// getBuildCacheName returns the name of the PVC holding the build cache of an
+// environment. Unlike builder deployments, the claim is not tied to the
+// environment's resource version so that the cache survives env updates.
+func getBuildCacheName(envName string) string {
+	return fmt.Sprintf("%v-build-cache", envName)
+}
+
+func (envw *environmentWatcher) getBuildCacheLabels(envName string, envNamespace string) map[string]string {
+	return map[string]string{
+		LABEL_ENV_NAME:         envName,
+		LABEL_ENV_NAMESPACE:    envNamespace,
+		LABEL_BUILD_CACHE:      "true",
+		LABEL_DEPLOYMENT_OWNER: BUILDER_MGR,
+	}
+}
+
+// ensureBuildCache creates the build cache PVC for the environment if it
+// does not exist yet. An existing claim is reused as-is.
+func (envw *environmentWatcher) ensureBuildCache(env *fv1.Environment, ns string) (*apiv1.PersistentVolumeClaim, error) {
+	name := getBuildCacheName(env.Metadata.Name)
+	pvc, err := envw.kubernetesClient.CoreV1().PersistentVolumeClaims(ns).Get(name, metav1.GetOptions{})
+	if err == nil {
+		return pvc, nil
+	} else if !k8serrors.IsNotFound(err) {
+		return nil, errors.Wrapf(err, "error getting build cache %s.%s", name, ns)
+	}
+
+	// pods of a builder pool may be scheduled on different nodes
+	accessMode := apiv1.ReadWriteOnce
+	if env.Spec.Builder.PoolSize > 1 {
+		accessMode = apiv1.ReadWriteMany
+	}
+
+	pvc = &apiv1.PersistentVolumeClaim{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: ns,
+			Name:      name,
+			Labels:    envw.getBuildCacheLabels(env.Metadata.Name, ns),
+		},
+		Spec: apiv1.PersistentVolumeClaimSpec{
+			AccessModes: []apiv1.PersistentVolumeAccessMode{accessMode},
+			Resources: apiv1.ResourceRequirements{
+				Requests: apiv1.ResourceList{
+					apiv1.ResourceStorage: env.Spec.Builder.Cache.Size,
+				},
+			},
+		},
+	}
+	if len(env.Spec.Builder.Cache.StorageClassName) > 0 {
+		pvc.Spec.StorageClassName = &env.Spec.Builder.Cache.StorageClassName
+	}
+
+	envw.logger.Info("creating build cache", zap.String("pvc_name", name), zap.String("pvc_namespace", ns))
+	pvc, err = envw.kubernetesClient.CoreV1().PersistentVolumeClaims(ns).Create(pvc)
+	if err != nil {
+		return nil, errors.Wrapf(err, "error creating build cache %s.%s", name, ns)
+	}
+	return pvc, nil
+}
+
+// addBuildCacheToPodSpec mounts the build cache PVC into the builder container
+// and tells the builder where to find it.
+func addBuildCacheToPodSpec(podSpec *apiv1.PodSpec, containerName string, pvcName string) error {
+	podSpec.Volumes = append(podSpec.Volumes, apiv1.Volume{
+		Name: buildCacheVolume,
+		VolumeSource: apiv1.VolumeSource{
+			PersistentVolumeClaim: &apiv1.PersistentVolumeClaimVolumeSource{
+				ClaimName: pvcName,
+			},
+		},
+	})
+
+	for i := range podSpec.Containers {
+		container := &podSpec.Containers[i]
+		if container.Name != containerName {
+			continue
+		}
+		container.VolumeMounts = append(container.VolumeMounts, apiv1.VolumeMount{
+			Name:      buildCacheVolume,
+			MountPath: buildCacheMountPath,
+		})
+		container.Env = append(container.Env, apiv1.EnvVar{
+			Name:  builder.EnvBuildCacheDir,
+			Value: buildCacheMountPath,
+		})
+		return nil
+	}
+	return fmt.Errorf("could not find container %q in pod spec", containerName)
+}
+
+// cleanupBuildCaches removes the build caches of deleted environments and of
+// environments that no longer ask for one.
+func (envw *environmentWatcher) cleanupBuildCaches(envList []fv1.Environment) {
+	envs := make(map[string]*fv1.Environment)
+	for i := range envList {
+		env := envList[i]
+		ns := envw.builderNamespace
+		if env.Metadata.Namespace != metav1.NamespaceDefault {
+			ns = env.Metadata.Namespace
+		}
+		envs[fmt.Sprintf("%v-%v", env.Metadata.Name, ns)] = &env
+	}
+
+	sel := map[string]string{
+		LABEL_BUILD_CACHE:      "true",
+		LABEL_DEPLOYMENT_OWNER: BUILDER_MGR,
+	}
+	pvcList, err := envw.kubernetesClient.CoreV1().PersistentVolumeClaims(metav1.NamespaceAll).List(
+		metav1.ListOptions{
+			LabelSelector: labels.Set(sel).AsSelector().String(),
+		})
+	if err != nil {
+		envw.logger.Error("error getting the build cache list", zap.Error(err))
+		return
+	}
+
+	for _, pvc := range pvcList.Items {
+		key := fmt.Sprintf("%v-%v", pvc.ObjectMeta.Labels[LABEL_ENV_NAME], pvc.ObjectMeta.Labels[LABEL_ENV_NAMESPACE])
+		if env, ok := envs[key]; ok && env.Spec.Builder.Cache != nil {
+			continue
+		}
+		err := envw.kubernetesClient.CoreV1().PersistentVolumeClaims(pvc.ObjectMeta.Namespace).Delete(pvc.ObjectMeta.Name, &delOpt)
+		if err != nil {
+			envw.logger.Error("error removing build cache", zap.Error(err),
+				zap.String("pvc_name", pvc.ObjectMeta.Name),
+				zap.String("pvc_namespace", pvc.ObjectMeta.Namespace))
+		}
+	}
+}
@@ -17,15 +17,27 @@ limitations under the License.
 package buildermgr
 
 import (
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
 	"github.com/pkg/errors"
 	"go.uber.org/zap"
 
 	"github.com/fission/fission/pkg/crd"
+	executorClient "github.com/fission/fission/pkg/executor/client"
 	fetcherConfig "github.com/fission/fission/pkg/fetcher/config"
+	"github.com/fission/fission/pkg/health"
+	"github.com/fission/fission/pkg/publisher"
+	"github.com/fission/fission/pkg/types"
 )
 
+// healthAddr is the address buildermgr serves /healthz and /readyz at.
+const healthAddr = ":8888"
+
 // Start the buildermgr service.
-func Start(logger *zap.Logger, storageSvcUrl string, envBuilderNamespace string) error {
+func Start(logger *zap.Logger, storageSvcUrl string, executorUrl string, routerUrl string, envBuilderNamespace string) error {
 	bmLogger := logger.Named("builder_manager")
 
 	fissionClient, kubernetesClient, _, err := crd.MakeFissionClient()
@@ -47,8 +59,67 @@ func Start(logger *zap.Logger, storageSvcUrl string, envBuilderNamespace string)
 	go envWatcher.watchEnvironments()
 
 	pkgWatcher := makePackageWatcher(bmLogger, fissionClient,
-		kubernetesClient, envBuilderNamespace, storageSvcUrl)
+		kubernetesClient, envBuilderNamespace, storageSvcUrl, getBuildRetryConfig(bmLogger))
+	pkgWatcher.refreshStrategy = getPackageRefreshStrategy(bmLogger)
+	if len(pkgWatcher.refreshStrategy) > 0 {
+		pkgWatcher.executor = executorClient.MakeClient(bmLogger, executorUrl)
+	}
+	pkgWatcher.publisher = publisher.MakeWebhookPublisher(bmLogger, routerUrl)
 	go pkgWatcher.watchPackages(fissionClient, kubernetesClient, envBuilderNamespace)
 
+	checker := health.MakeChecker("buildermgr")
+	checker.AddCheck("crd", fissionClient.CheckCRDs)
+	checker.AddCheck("storage", health.HTTPCheck(strings.TrimSuffix(storageSvcUrl, "/")+"/healthz"))
+	go checker.Serve(bmLogger, healthAddr)
+
 	select {}
 }
+
+// getBuildRetryConfig reads the retry policy of failed package builds from
+// the environment. Failed builds are not retried by default.
+func getBuildRetryConfig(logger *zap.Logger) buildRetryConfig {
+	config := buildRetryConfig{
+		count:   0,
+		backoff: 10 * time.Second,
+	}
+
+	if s := os.Getenv("BUILDER_BUILD_RETRY_COUNT"); len(s) > 0 {
+		count, err := strconv.Atoi(s)
+		if err != nil || count < 0 {
+			logger.Error("failed to parse build retry count from 'BUILDER_BUILD_RETRY_COUNT' - set to the default value",
+				zap.Error(err),
+				zap.String("value", s),
+				zap.Int("default", config.count))
+		} else {
+			config.count = count
+		}
+	}
+
+	if s := os.Getenv("BUILDER_BUILD_RETRY_BACKOFF"); len(s) > 0 {
+		backoff, err := time.ParseDuration(s)
+		if err != nil || backoff < 0 {
+			logger.Error("failed to parse build retry backoff from 'BUILDER_BUILD_RETRY_BACKOFF' - set to the default value",
+				zap.Error(err),
+				zap.String("value", s),
+				zap.Duration("default", config.backoff))
+		} else {
+			config.backoff = backoff
+		}
+	}
+
+	return config
+}
+
+// getPackageRefreshStrategy reads how functions pick up rebuilt packages
+// from the environment. Pods are not refreshed by default.
+func getPackageRefreshStrategy(logger *zap.Logger) types.PackageRefreshStrategy {
+	strategy := types.PackageRefreshStrategy(os.Getenv("BUILDER_PACKAGE_REFRESH_STRATEGY"))
+	switch strategy {
+	case "", types.PackageRefreshImmediate, types.PackageRefreshLazy:
+		return strategy
+	default:
+		logger.Error("failed to parse package refresh strategy from 'BUILDER_PACKAGE_REFRESH_STRATEGY' - pods are not refreshed",
+			zap.String("value", string(strategy)))
+		return ""
+	}
+}
@@ -0,0 +1,179 @@
+/*
+Copyright 2019 The Fission Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package buildermgr
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/pkg/errors"
+
+	fv1 "github.com/fission/fission/pkg/apis/fission.io/v1"
+	"github.com/fission/fission/pkg/builder"
+	storageSvcClient "github.com/fission/fission/pkg/storagesvc/client"
+	"github.com/fission/fission/pkg/types"
+)
+
+type (
+	// buildLog collects the logs of each step of a package build, and
+	// the status of the build steps of a multi-step build.
+	buildLog struct {
+		steps      []types.PackageBuildLogStep
+		buildSteps []fv1.BuildStepStatus
+	}
+)
+
+func newBuildLog(step string, log string) *buildLog {
+	l := &buildLog{}
+	l.append(step, log)
+	return l
+}
+
+// append adds log to the given step. Logs of consecutive calls
+// with the same step are concatenated.
+func (l *buildLog) append(step string, log string) {
+	if len(log) == 0 {
+		return
+	}
+	if n := len(l.steps); n > 0 && l.steps[n-1].Name == step {
+		l.steps[n-1].Log += log
+		return
+	}
+	l.steps = append(l.steps, types.PackageBuildLogStep{
+		Name: step,
+		Log:  log,
+	})
+}
+
+// pendingBuildSteps returns the status of build steps that haven't run yet.
+func pendingBuildSteps(steps []fv1.BuildStep) []fv1.BuildStepStatus {
+	if len(steps) == 0 {
+		return nil
+	}
+	status := make([]fv1.BuildStepStatus, len(steps))
+	for i, step := range steps {
+		status[i] = fv1.BuildStepStatus{
+			Name:   step.Name,
+			Status: fv1.BuildStatusPending,
+		}
+	}
+	return status
+}
+
+// appendBuildResponse adds the logs of a builder response. The logs of
+// a multi-step build are kept per build step, as "build/<step name>".
+func (l *buildLog) appendBuildResponse(resp *builder.PackageBuildResponse) {
+	if len(resp.Steps) == 0 {
+		l.append(types.BuildStepBuild, resp.BuildLogs)
+		return
+	}
+
+	for _, result := range resp.Steps {
+		l.append(fmt.Sprintf("%v/%v", types.BuildStepBuild, result.Name), result.BuildLogs)
+
+		status := fv1.BuildStepStatus{
+			Name:            result.Name,
+			Status:          fv1.BuildStatusSucceeded,
+			Message:         result.Message,
+			StartTimestamp:  result.StartTimestamp,
+			FinishTimestamp: result.FinishTimestamp,
+		}
+		if !result.Succeeded {
+			status.Status = fv1.BuildStatusFailed
+		}
+
+		found := false
+		for i := range l.buildSteps {
+			if l.buildSteps[i].Name == result.Name {
+				l.buildSteps[i] = status
+				found = true
+			}
+		}
+		if !found {
+			l.buildSteps = append(l.buildSteps, status)
+		}
+	}
+}
+
+// stepStatus returns the status of the build steps, nil if the build
+// isn't a multi-step build.
+func (l *buildLog) stepStatus() []fv1.BuildStepStatus {
+	if l == nil {
+		return nil
+	}
+	return l.buildSteps
+}
+
+func (l *buildLog) String() string {
+	if l == nil {
+		return ""
+	}
+	var sb strings.Builder
+	for _, step := range l.steps {
+		sb.WriteString(step.Log)
+	}
+	return sb.String()
+}
+
+// summary returns the build log that fits in the package status. If the
+// log is too long, only its tail is kept, since that's where build errors
+// usually are.
+func (l *buildLog) summary() string {
+	s := l.String()
+	if len(s) <= types.BuildLogSizeLimit {
+		return s
+	}
+	start := len(s) - types.BuildLogSizeLimit
+	for start < len(s) && !utf8.RuneStart(s[start]) {
+		start++
+	}
+	return fmt.Sprintf("[build log truncated, use 'fission package logs' to get the full log]\n...%s", s[start:])
+}
+
+// store uploads the structured build log to the storage service and
+// returns the URL to download it.
+func (l *buildLog) store(ctx context.Context, storageSvcUrl string) (string, error) {
+	content, err := json.Marshal(types.PackageBuildLog{Steps: l.steps})
+	if err != nil {
+		return "", errors.Wrap(err, "error marshaling build log")
+	}
+
+	tmpFile, err := ioutil.TempFile("", "fission-build-log-")
+	if err != nil {
+		return "", errors.Wrap(err, "error creating build log file")
+	}
+	defer os.Remove(tmpFile.Name())
+
+	_, err = tmpFile.Write(content)
+	tmpFile.Close()
+	if err != nil {
+		return "", errors.Wrap(err, "error writing build log file")
+	}
+
+	ssClient := storageSvcClient.MakeClient(storageSvcUrl)
+	id, err := ssClient.Upload(ctx, tmpFile.Name(), nil)
+	if err != nil {
+		return "", errors.Wrap(err, "error uploading build log to storage service")
+	}
+
+	return ssClient.GetUrl(id), nil
+}
@@ -0,0 +1,56 @@
+/*
+Copyright 2019 The Fission Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package buildermgr
+
+import (
+	"strings"
+	"testing"
+	"unicode/utf8"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/fission/fission/pkg/types"
+)
+
+func TestBuildLogSummary(t *testing.T) {
+	var l *buildLog
+	assert.Equal(t, "", l.summary())
+
+	l = newBuildLog(types.BuildStepFetch, "fetched\n")
+	l.append(types.BuildStepBuild, "built\n")
+	assert.Equal(t, "fetched\nbuilt\n", l.summary())
+
+	// a log at the limit is kept as is
+	l = newBuildLog(types.BuildStepBuild, strings.Repeat("a", types.BuildLogSizeLimit))
+	assert.Equal(t, strings.Repeat("a", types.BuildLogSizeLimit), l.summary())
+
+	// only the tail of a longer log is kept
+	l = newBuildLog(types.BuildStepBuild, "head"+strings.Repeat("a", types.BuildLogSizeLimit-1)+"error")
+	s := l.summary()
+	assert.True(t, strings.HasPrefix(s, "[build log truncated"))
+	assert.True(t, strings.HasSuffix(s, "error"))
+	assert.False(t, strings.Contains(s, "head"))
+	tail := s[strings.Index(s, "...")+3:]
+	assert.Equal(t, types.BuildLogSizeLimit, len(tail))
+
+	// the log isn't cut in the middle of a character
+	l = newBuildLog(types.BuildStepBuild, strings.Repeat("é", types.BuildLogSizeLimit))
+	s = l.summary()
+	assert.True(t, utf8.ValidString(s))
+	tail = s[strings.Index(s, "...")+3:]
+	assert.True(t, len(tail) <= types.BuildLogSizeLimit)
+}
@@ -20,12 +20,16 @@ import (
 	"context"
 	"fmt"
 	"net/http"
+	"sort"
 	"strings"
 	"time"
 
 	"github.com/dchest/uniuri"
 	"github.com/pkg/errors"
+	"go.opencensus.io/trace"
 	"go.uber.org/zap"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
 
 	fv1 "github.com/fission/fission/pkg/apis/fission.io/v1"
 	"github.com/fission/fission/pkg/builder"
@@ -43,21 +47,34 @@ import (
 // 3. Send upload request to fetcher to upload deployment package.
 // 4. Return upload response and build logs.
 // *. Return build logs and error if any one of steps above failed.
-func buildPackage(ctx context.Context, logger *zap.Logger, fissionClient *crd.FissionClient, envBuilderNamespace string,
-	storageSvcUrl string, pkg *fv1.Package) (uploadResp *types.ArchiveUploadResponse, buildLogs string, err error) {
+// All requests are sent to the builder pod at builderHost, since the fetched
+// source isn't shared between the pods of an environment builder.
+func buildPackage(ctx context.Context, logger *zap.Logger, fissionClient *crd.FissionClient, k8sClient *kubernetes.Clientset, builderHost string,
+	storageSvcUrl string, pkg *fv1.Package) (uploadResp *types.ArchiveUploadResponse, buildLogs *buildLog, err error) {
+
+	buildLogs = &buildLog{}
 
 	env, err := fissionClient.Environments(pkg.Spec.Environment.Namespace).Get(pkg.Spec.Environment.Name)
 	if err != nil {
 		e := "error getting environment CRD info"
 		logger.Error(e, zap.Error(err))
 		e = fmt.Sprintf("%s: %v", e, err)
-		return nil, e, ferror.MakeError(http.StatusInternalServerError, e)
+		buildLogs.append(types.BuildStepPrepare, e)
+		return nil, buildLogs, ferror.MakeError(http.StatusInternalServerError, e)
+	}
+
+	buildEnv, err := getBuildEnv(k8sClient, pkg)
+	if err != nil {
+		e := "error getting build environment variables"
+		logger.Error(e, zap.Error(err))
+		e = fmt.Sprintf("%s: %v", e, err)
+		buildLogs.append(types.BuildStepPrepare, e)
+		return nil, buildLogs, ferror.MakeError(http.StatusInternalServerError, e)
 	}
 
-	svcName := fmt.Sprintf("%v-%v.%v", env.Metadata.Name, env.Metadata.ResourceVersion, envBuilderNamespace)
 	srcPkgFilename := fmt.Sprintf("%v-%v", pkg.Metadata.Name, strings.ToLower(uniuri.NewLen(6)))
-	fetcherC := fetcherClient.MakeClient(logger, fmt.Sprintf("http://%v:8000", svcName))
-	builderC := builderClient.MakeClient(logger, fmt.Sprintf("http://%v:8001", svcName))
+	fetcherC := fetcherClient.MakeClient(logger, fmt.Sprintf("http://%v:8000", builderHost))
+	builderC := builderClient.MakeClient(logger, fmt.Sprintf("http://%v:8001", builderHost))
 
 	fetchReq := &types.FunctionFetchRequest{
 		FetchType:   types.FETCH_SOURCE,
@@ -67,12 +84,15 @@ func buildPackage(ctx context.Context, logger *zap.Logger, fissionClient *crd.Fi
 	}
 
 	// send fetch request to fetcher
-	err = fetcherC.Fetch(ctx, fetchReq)
+	fetchCtx, span := trace.StartSpan(ctx, "buildermgr.fetchSource")
+	err = fetcherC.Fetch(fetchCtx, fetchReq)
+	span.End()
 	if err != nil {
 		e := "error fetching source package"
 		logger.Error(e, zap.Error(err))
 		e = fmt.Sprintf("%s: %v", e, err)
-		return nil, e, ferror.MakeError(http.StatusInternalServerError, e)
+		buildLogs.append(types.BuildStepFetch, e)
+		return nil, buildLogs, ferror.MakeError(http.StatusInternalServerError, e)
 	}
 
 	buildCmd := pkg.Spec.BuildCommand
@@ -83,20 +103,42 @@ func buildPackage(ctx context.Context, logger *zap.Logger, fissionClient *crd.Fi
 	pkgBuildReq := &builder.PackageBuildRequest{
 		SrcPkgFilename: srcPkgFilename,
 		BuildCommand:   buildCmd,
+		Env:            buildEnv,
+	}
+
+	if len(pkg.Spec.BuildSteps) > 0 {
+		buildLogs.buildSteps = pendingBuildSteps(pkg.Spec.BuildSteps)
+		for _, step := range pkg.Spec.BuildSteps {
+			// the steps share the workspace of the builder pod, which only
+			// runs the builder image of the environment
+			if len(step.Image) > 0 && step.Image != env.Spec.Builder.Image {
+				e := fmt.Sprintf("build step %q can't run with image %q, build steps can only use the builder image %q of environment %q",
+					step.Name, step.Image, env.Spec.Builder.Image, env.Metadata.Name)
+				logger.Error(e)
+				buildLogs.append(types.BuildStepPrepare, e+"\n")
+				return nil, buildLogs, ferror.MakeError(http.StatusBadRequest, e)
+			}
+			pkgBuildReq.BuildSteps = append(pkgBuildReq.BuildSteps, builder.BuildStep{
+				Name:    step.Name,
+				Command: step.Command,
+			})
+		}
 	}
 
 	logger.Info("started building with source package", zap.String("source_package", srcPkgFilename))
 	// send build request to builder
+	_, span = trace.StartSpan(ctx, "buildermgr.runBuild")
 	buildResp, err := builderC.Build(pkgBuildReq)
+	span.End()
 	if err != nil {
 		e := fmt.Sprintf("Error building deployment package: %v", err)
-		var buildLogs string
 		if buildResp != nil {
-			buildLogs = buildResp.BuildLogs
+			buildLogs.appendBuildResponse(buildResp)
 		}
-		buildLogs += fmt.Sprintf("%v\n", e)
+		buildLogs.append(types.BuildStepBuild, fmt.Sprintf("%v\n", e))
 		return nil, buildLogs, ferror.MakeError(http.StatusInternalServerError, e)
 	}
+	buildLogs.appendBuildResponse(buildResp)
 
 	logger.Info("build succeed", zap.String("source_package", srcPkgFilename), zap.String("deployment_package", buildResp.ArtifactFilename))
 
@@ -110,24 +152,83 @@ func buildPackage(ctx context.Context, logger *zap.Logger, fissionClient *crd.Fi
 
 	logger.Info("started uploading deployment package", zap.String("deployment_package", buildResp.ArtifactFilename))
 	// ask fetcher to upload the deployment package
-	uploadResp, err = fetcherC.Upload(ctx, uploadReq)
+	uploadCtx, span := trace.StartSpan(ctx, "buildermgr.uploadDeployment")
+	uploadResp, err = fetcherC.Upload(uploadCtx, uploadReq)
+	span.End()
 	if err != nil {
 		e := fmt.Sprintf("Error uploading deployment package: %v", err)
-		buildResp.BuildLogs += fmt.Sprintf("%v\n", e)
-		return nil, buildResp.BuildLogs, ferror.MakeError(http.StatusInternalServerError, e)
+		buildLogs.append(types.BuildStepUpload, fmt.Sprintf("%v\n", e))
+		return nil, buildLogs, ferror.MakeError(http.StatusInternalServerError, e)
 	}
 
-	return uploadResp, buildResp.BuildLogs, nil
+	return uploadResp, buildLogs, nil
 }
 
-func updatePackage(logger *zap.Logger, fissionClient *crd.FissionClient,
-	pkg *fv1.Package, status fv1.BuildStatus, buildLogs string,
+// getBuildEnv returns the build environment variables of the package in
+// the form of KEY=VALUE, with the entries of its build secrets after the
+// variables given in the spec.
+func getBuildEnv(k8sClient *kubernetes.Clientset, pkg *fv1.Package) ([]string, error) {
+	var env []string
+	env = append(env, sortedEnv(pkg.Spec.BuildEnv)...)
+
+	for _, name := range pkg.Spec.BuildSecrets {
+		secret, err := k8sClient.CoreV1().Secrets(pkg.Metadata.Namespace).Get(name, metav1.GetOptions{})
+		if err != nil {
+			return nil, errors.Wrapf(err, "error getting build secret %v", name)
+		}
+		data := make(map[string]string, len(secret.Data))
+		for k, v := range secret.Data {
+			data[k] = string(v)
+		}
+		env = append(env, sortedEnv(data)...)
+	}
+
+	return env, nil
+}
+
+// sortedEnv returns the variables in the form of KEY=VALUE sorted by name.
+func sortedEnv(vars map[string]string) []string {
+	env := make([]string, 0, len(vars))
+	for k, v := range vars {
+		env = append(env, fmt.Sprintf("%v=%v", k, v))
+	}
+	sort.Strings(env)
+	return env
+}
+
+// updatePackage updates the package status. The full build log, if any, is
+// stored in the storage service and only its tail is kept in the status.
+func updatePackage(logger *zap.Logger, fissionClient *crd.FissionClient, storageSvcUrl string,
+	pkg *fv1.Package, status fv1.BuildStatus, buildLogs *buildLog,
 	uploadResp *types.ArchiveUploadResponse) (*fv1.Package, error) {
 
 	pkg.Status = fv1.PackageStatus{
 		BuildStatus:         status,
-		BuildLog:            buildLogs,
+		BuildLog:            buildLogs.summary(),
 		LastUpdateTimestamp: time.Now().UTC(),
+		BuildAttempts:       pkg.Status.BuildAttempts,
+		BuildPod:            pkg.Status.BuildPod,
+		BuildSteps:          buildLogs.stepStatus(),
+	}
+
+	if status == fv1.BuildStatusRunning && pkg.Status.BuildSteps == nil {
+		pkg.Status.BuildSteps = pendingBuildSteps(pkg.Spec.BuildSteps)
+	}
+
+	if buildLogs != nil && len(buildLogs.steps) > 0 {
+		buildLogUrl, err := buildLogs.store(context.Background(), storageSvcUrl)
+		if err != nil {
+			// the log summary is still kept in package status
+			logger.Error("error storing full build log", zap.Error(err),
+				zap.String("package_name", pkg.Metadata.Name),
+				zap.String("package_namespace", pkg.Metadata.Namespace))
+		} else {
+			pkg.Status.BuildLogURL = buildLogUrl
+			// the log belongs to the latest build attempt
+			if n := len(pkg.Status.BuildAttempts); n > 0 && len(pkg.Status.BuildAttempts[n-1].BuildLogURL) == 0 {
+				pkg.Status.BuildAttempts[n-1].BuildLogURL = buildLogUrl
+			}
+		}
 	}
 
 	if uploadResp != nil {
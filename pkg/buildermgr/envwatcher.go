@@ -295,6 +295,8 @@ func (envw *environmentWatcher) service() {
 				}
 				delete(envw.cache, key)
 			}
+
+			envw.cleanupBuildCaches(req.envList)
 		}
 	}
 }
@@ -351,6 +353,13 @@ func (envw *environmentWatcher) createBuilder(env *fv1.Environment, ns string) (
 			return nil, errors.Wrapf(err, "error creating %q in ns: %s", types.FissionBuilderSA, ns)
 		}
 
+		if env.Spec.Builder.Cache != nil {
+			_, err = envw.ensureBuildCache(env, ns)
+			if err != nil {
+				return nil, errors.Wrap(err, "error creating build cache")
+			}
+		}
+
 		deploy, err = envw.createBuilderDeployment(env, ns)
 		if err != nil {
 			return nil, errors.Wrap(err, "error creating builder deployment")
@@ -456,6 +465,9 @@ func (envw *environmentWatcher) createBuilderDeployment(env *fv1.Environment, ns
 	name := fmt.Sprintf("%v-%v", env.Metadata.Name, env.Metadata.ResourceVersion)
 	sel := envw.getLabels(env.Metadata.Name, ns, env.Metadata.ResourceVersion)
 	var replicas int32 = 1
+	if env.Spec.Builder.PoolSize > 0 {
+		replicas = int32(env.Spec.Builder.PoolSize)
+	}
 
 	podAnnotations := env.Metadata.Annotations
 	if podAnnotations == nil {
@@ -508,6 +520,8 @@ func (envw *environmentWatcher) createBuilderDeployment(env *fv1.Environment, ns
 				Spec: apiv1.PodSpec{
 					Containers:         []apiv1.Container{*container},
 					ServiceAccountName: "fission-builder",
+					RuntimeClassName:   util.GetRuntimeClassName(env),
+					ImagePullSecrets:   util.GetImagePullSecrets(env),
 				},
 			},
 		},
@@ -518,6 +532,13 @@ func (envw *environmentWatcher) createBuilderDeployment(env *fv1.Environment, ns
 		return nil, err
 	}
 
+	if env.Spec.Builder.Cache != nil {
+		err = addBuildCacheToPodSpec(&deployment.Spec.Template.Spec, "builder", getBuildCacheName(env.Metadata.Name))
+		if err != nil {
+			return nil, err
+		}
+	}
+
 	envw.logger.Info("creating builder deployment", zap.String("deployment", name))
 	_, err = envw.kubernetesClient.AppsV1().Deployments(ns).Create(deployment)
 	if err != nil {
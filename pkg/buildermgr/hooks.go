@@ -0,0 +1,94 @@
+/*
+Copyright 2019 The Fission Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package buildermgr
+
+import (
+	"strconv"
+	"strings"
+
+	"go.uber.org/zap"
+
+	fv1 "github.com/fission/fission/pkg/apis/fission.io/v1"
+	"github.com/fission/fission/pkg/utils"
+)
+
+const (
+	// headers of the invocation after a build, so that functions can
+	// tell which build invoked them
+	headerPackageName            = "X-Fission-Package-Name"
+	headerPackageNamespace       = "X-Fission-Package-Namespace"
+	headerPackageResourceVersion = "X-Fission-Package-Resource-Version"
+)
+
+// getRunAfterBuildTarget returns the router path to invoke the function at
+// after a build of the package, and false if neither the function nor the
+// package ask for it. The annotations of the function take precedence.
+func getRunAfterBuildTarget(pkg *fv1.Package, fn *fv1.Function) (string, bool) {
+	run, path := false, ""
+	for _, annotations := range []map[string]string{pkg.Metadata.Annotations, fn.Metadata.Annotations} {
+		if v, ok := annotations[fv1.AnnotationRunAfterBuild]; ok {
+			run, _ = strconv.ParseBool(v)
+		}
+		if v, ok := annotations[fv1.AnnotationRunAfterBuildPath]; ok {
+			path = v
+		}
+	}
+	if !run {
+		return "", false
+	}
+
+	target := utils.UrlForFunction(fn.Metadata.Name, fn.Metadata.Namespace)
+	if len(path) > 0 {
+		target += "/" + strings.TrimPrefix(path, "/")
+	}
+	return target, true
+}
+
+// runAfterBuild invokes the functions of the package that ask for it with
+// the run-after-build annotation. The invocations go through the router and
+// are retried on failure, so the functions should be idempotent.
+func (pkgw *packageWatcher) runAfterBuild(pkg *fv1.Package, fns []fv1.Function) {
+	if pkgw.publisher == nil {
+		return
+	}
+
+	headers := map[string]string{
+		headerPackageName:            pkg.Metadata.Name,
+		headerPackageNamespace:       pkg.Metadata.Namespace,
+		headerPackageResourceVersion: pkg.Metadata.ResourceVersion,
+	}
+
+	for i := range fns {
+		fn := &fns[i]
+		if fn.Spec.Package.PackageRef.Name != pkg.Metadata.Name ||
+			fn.Spec.Package.PackageRef.Namespace != pkg.Metadata.Namespace {
+			continue
+		}
+
+		target, ok := getRunAfterBuildTarget(pkg, fn)
+		if !ok {
+			continue
+		}
+
+		pkgw.logger.Info("invoking function after package build",
+			zap.String("function_name", fn.Metadata.Name),
+			zap.String("function_namespace", fn.Metadata.Namespace),
+			zap.String("package_name", pkg.Metadata.Name),
+			zap.String("target", target))
+		pkgw.publisher.Publish("", headers, target)
+	}
+}
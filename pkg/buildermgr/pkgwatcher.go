@@ -21,6 +21,7 @@ import (
 	"fmt"
 	"time"
 
+	"go.opencensus.io/trace"
 	"go.uber.org/zap"
 	apiv1 "k8s.io/api/core/v1"
 	k8serrors "k8s.io/apimachinery/pkg/api/errors"
@@ -32,6 +33,8 @@ import (
 	fv1 "github.com/fission/fission/pkg/apis/fission.io/v1"
 	"github.com/fission/fission/pkg/cache"
 	"github.com/fission/fission/pkg/crd"
+	executorClient "github.com/fission/fission/pkg/executor/client"
+	"github.com/fission/fission/pkg/publisher"
 	"github.com/fission/fission/pkg/types"
 	"github.com/fission/fission/pkg/utils"
 )
@@ -45,11 +48,32 @@ type (
 		pkgStore         k8sCache.Store
 		builderNamespace string
 		storageSvcUrl    string
+		buildRetry       buildRetryConfig
+
+		// refreshStrategy decides how the functions using a rebuilt
+		// package get new pods, empty disables the refresh.
+		refreshStrategy types.PackageRefreshStrategy
+		executor        *executorClient.Client
+
+		// publisher invokes functions through the router after builds
+		publisher publisher.Publisher
+	}
+
+	// buildRetryConfig is the policy of automatically retrying failed builds.
+	buildRetryConfig struct {
+		// count is the max number of retries, 0 disables retry.
+		count int
+		// backoff is the wait time before the first retry, it doubles
+		// for every following retry.
+		backoff time.Duration
 	}
 )
 
+// maxBuildRetryBackoff is the upper bound of the wait time between build retries.
+const maxBuildRetryBackoff = 5 * time.Minute
+
 func makePackageWatcher(logger *zap.Logger, fissionClient *crd.FissionClient, k8sClientSet *kubernetes.Clientset,
-	builderNamespace string, storageSvcUrl string) *packageWatcher {
+	builderNamespace string, storageSvcUrl string, buildRetry buildRetryConfig) *packageWatcher {
 	lw := k8sCache.NewListWatchFromClient(k8sClientSet.CoreV1().RESTClient(), "pods", metav1.NamespaceAll, fields.Everything())
 	store, controller := k8sCache.NewInformer(lw, &apiv1.Pod{}, 30*time.Second, k8sCache.ResourceEventHandlerFuncs{})
 	go controller.Run(make(chan struct{}))
@@ -61,6 +85,7 @@ func makePackageWatcher(logger *zap.Logger, fissionClient *crd.FissionClient, k8
 		podStore:         store,
 		builderNamespace: builderNamespace,
 		storageSvcUrl:    storageSvcUrl,
+		buildRetry:       buildRetry,
 	}
 	return pkgw
 }
@@ -71,7 +96,8 @@ func makePackageWatcher(logger *zap.Logger, fissionClient *crd.FissionClient, k8
 // 1. Check package status
 // 2. Update package status to running state
 // 3. Check environment builder pod status
-// 4. Call buildPackage to build package
+// 4. Call buildPackage to build package, retry on failure if configured
+// 4a. Run the post-build hooks of the package
 // 5. Update package resource in package ref of functions that share the same package
 // 6. Update package status to succeed state
 // *. Update package status to failed state,if any one of steps above failed/time out
@@ -92,7 +118,10 @@ func (pkgw *packageWatcher) build(buildCache *cache.Cache, srcpkg *fv1.Package)
 
 	pkgw.logger.Info("starting build for package", zap.String("package_name", srcpkg.Metadata.Name), zap.String("resource_version", srcpkg.Metadata.ResourceVersion))
 
-	pkg, err := updatePackage(pkgw.logger, pkgw.fissionClient, srcpkg, fv1.BuildStatusRunning, "", nil)
+	// Attempts of the previous build are not relevant to this one.
+	srcpkg.Status.BuildAttempts = nil
+	srcpkg.Status.BuildPod = nil
+	pkg, err := updatePackage(pkgw.logger, pkgw.fissionClient, pkgw.storageSvcUrl, srcpkg, fv1.BuildStatusRunning, nil, nil)
 	if err != nil {
 		pkgw.logger.Error("error setting package pending state", zap.Error(err))
 		return
@@ -102,8 +131,8 @@ func (pkgw *packageWatcher) build(buildCache *cache.Cache, srcpkg *fv1.Package)
 	if k8serrors.IsNotFound(err) {
 		e := "environment does not exist"
 		pkgw.logger.Error(e, zap.String("environment", pkg.Spec.Environment.Name))
-		updatePackage(pkgw.logger, pkgw.fissionClient, pkg,
-			fv1.BuildStatusFailed, fmt.Sprintf("%s: %q", e, pkg.Spec.Environment.Name), nil)
+		updatePackage(pkgw.logger, pkgw.fissionClient, pkgw.storageSvcUrl, pkg, fv1.BuildStatusFailed,
+			newBuildLog(types.BuildStepPrepare, fmt.Sprintf("%s: %q", e, pkg.Spec.Environment.Name)), nil)
 		return
 	}
 
@@ -148,10 +177,14 @@ func (pkgw *packageWatcher) build(buildCache *cache.Cache, srcpkg *fv1.Package)
 				podIsReady = podIsReady && cStatus.Ready
 			}
 
-			if !podIsReady {
-				pkgw.logger.Info("builder pod is not ready for environment, will retry again later", zap.String("environment", pkg.Spec.Environment.Name))
+			// the builder of the environment may have several pods, try the
+			// next one
+			if !podIsReady || len(pod.Status.PodIP) == 0 {
+				pkgw.logger.Info("builder pod is not ready for environment, will retry again later",
+					zap.String("environment", pkg.Spec.Environment.Name),
+					zap.String("pod", pod.ObjectMeta.Name))
 				time.Sleep(time.Duration(i*1) * time.Second)
-				break
+				continue
 			}
 
 			// Add the package getter rolebinding to builder sa
@@ -171,11 +204,50 @@ func (pkgw *packageWatcher) build(buildCache *cache.Cache, srcpkg *fv1.Package)
 					zap.String("package", fmt.Sprintf("%s.%s", pkg.Metadata.Name, pkg.Metadata.Namespace)))
 			}
 
-			ctx := context.Background()
-			uploadResp, buildLogs, err := buildPackage(ctx, pkgw.logger, pkgw.fissionClient, builderNs, pkgw.storageSvcUrl, pkg)
+			// the fetcher of builder reads the git secret of the source in the package namespace
+			if pkg.Spec.Source.Type == fv1.ArchiveTypeGit && pkg.Spec.Source.Git != nil && len(pkg.Spec.Source.Git.Secret) > 0 {
+				err := utils.SetupRoleBinding(pkgw.logger, pkgw.k8sClient, types.SecretConfigMapGetterRB, pkg.Metadata.Namespace, types.SecretConfigMapGetterCR, types.ClusterRole, types.FissionBuilderSA, builderNs)
+				if err != nil {
+					pkgw.logger.Error("error setting up role binding for git secret",
+						zap.Error(err),
+						zap.String("role_binding", types.SecretConfigMapGetterRB),
+						zap.String("package_name", pkg.Metadata.Name),
+						zap.String("package_namespace", pkg.Metadata.Namespace))
+					continue
+				}
+			}
+
+			// record the builder pod, so that the logs of the build can be followed
+			pkg.Status.BuildPod = &fv1.BuildPod{
+				Namespace:      pod.ObjectMeta.Namespace,
+				Name:           pod.ObjectMeta.Name,
+				StartTimestamp: time.Now().UTC(),
+			}
+			if updatedPkg, err := updatePackage(pkgw.logger, pkgw.fissionClient, pkgw.storageSvcUrl, pkg, fv1.BuildStatusRunning, nil, nil); err == nil {
+				pkg = updatedPkg
+			}
+
+			var uploadResp *types.ArchiveUploadResponse
+			var buildLogs *buildLog
+			ctx, span := trace.StartSpan(context.Background(), "buildermgr.build")
+			span.AddAttributes(
+				trace.StringAttribute(utils.TraceAttrPackageName, pkg.Metadata.Name),
+				trace.StringAttribute(utils.TraceAttrPackageNamespace, pkg.Metadata.Namespace))
+			pkg, uploadResp, buildLogs, err = pkgw.buildWithRetry(ctx, pod.Status.PodIP, pkg)
+			if err != nil {
+				span.SetStatus(trace.Status{Code: trace.StatusCodeUnknown, Message: err.Error()})
+			}
+			span.End()
 			if err != nil {
 				pkgw.logger.Error("error building package", zap.Error(err), zap.String("package_name", pkg.Metadata.Name))
-				updatePackage(pkgw.logger, pkgw.fissionClient, pkg, types.BuildStatusFailed, buildLogs, nil)
+				updatePackage(pkgw.logger, pkgw.fissionClient, pkgw.storageSvcUrl, pkg, types.BuildStatusFailed, buildLogs, nil)
+				return
+			}
+
+			err = pkgw.runPostBuildHooks(context.Background(), pkg, uploadResp, buildLogs)
+			if err != nil {
+				pkgw.logger.Error("error running post-build hooks", zap.Error(err), zap.String("package_name", pkg.Metadata.Name))
+				updatePackage(pkgw.logger, pkgw.fissionClient, pkgw.storageSvcUrl, pkg, types.BuildStatusFailed, buildLogs, nil)
 				return
 			}
 
@@ -186,8 +258,8 @@ func (pkgw *packageWatcher) build(buildCache *cache.Cache, srcpkg *fv1.Package)
 			if err != nil {
 				e := "error getting function list"
 				pkgw.logger.Error(e, zap.Error(err))
-				buildLogs += fmt.Sprintf("%s: %v\n", e, err)
-				updatePackage(pkgw.logger, pkgw.fissionClient, pkg, fv1.BuildStatusFailed, buildLogs, nil)
+				buildLogs.append(types.BuildStepUpdate, fmt.Sprintf("%s: %v\n", e, err))
+				updatePackage(pkgw.logger, pkgw.fissionClient, pkgw.storageSvcUrl, pkg, fv1.BuildStatusFailed, buildLogs, nil)
 			}
 
 			// A package may be used by multiple functions. Update
@@ -202,33 +274,101 @@ func (pkgw *packageWatcher) build(buildCache *cache.Cache, srcpkg *fv1.Package)
 					if err != nil {
 						e := "error updating function package resource version"
 						pkgw.logger.Error(e, zap.Error(err))
-						buildLogs += fmt.Sprintf("%s: %v\n", e, err)
-						updatePackage(pkgw.logger, pkgw.fissionClient, pkg, fv1.BuildStatusFailed, buildLogs, nil)
+						buildLogs.append(types.BuildStepUpdate, fmt.Sprintf("%s: %v\n", e, err))
+						updatePackage(pkgw.logger, pkgw.fissionClient, pkgw.storageSvcUrl, pkg, fv1.BuildStatusFailed, buildLogs, nil)
 						return
 					}
 				}
 			}
 
-			_, err = updatePackage(pkgw.logger, pkgw.fissionClient, pkg,
+			updatedPkg, err := updatePackage(pkgw.logger, pkgw.fissionClient, pkgw.storageSvcUrl, pkg,
 				types.BuildStatusSucceeded, buildLogs, uploadResp)
 			if err != nil {
 				pkgw.logger.Error("error updating package info", zap.Error(err), zap.String("package_name", pkg.Metadata.Name))
-				updatePackage(pkgw.logger, pkgw.fissionClient, pkg, types.BuildStatusFailed, buildLogs, nil)
+				updatePackage(pkgw.logger, pkgw.fissionClient, pkgw.storageSvcUrl, pkg, types.BuildStatusFailed, buildLogs, nil)
 				return
 			}
 
+			pkgw.refreshFunctions(updatedPkg)
+			pkgw.runAfterBuild(updatedPkg, fnList.Items)
+
 			pkgw.logger.Info("completed package build request", zap.String("package_name", pkg.Metadata.Name))
 			return
 		}
 	}
 	// build timeout
-	updatePackage(pkgw.logger, pkgw.fissionClient, pkg,
-		types.BuildStatusFailed, "Build timeout due to environment builder not ready", nil)
+	updatePackage(pkgw.logger, pkgw.fissionClient, pkgw.storageSvcUrl, pkg, types.BuildStatusFailed,
+		newBuildLog(types.BuildStepPrepare, "Build timeout due to environment builder not ready"), nil)
 
 	pkgw.logger.Error("max retries exceeded in building source package, timeout due to environment builder not ready",
 		zap.String("package", fmt.Sprintf("%s.%s", pkg.Metadata.Name, pkg.Metadata.Namespace)))
 }
 
+// buildWithRetry calls buildPackage and retries failed builds with an exponential
+// backoff according to the retry config of the watcher. Each attempt is recorded
+// in the package status, and the package stays in running state until the last
+// attempt. It returns the latest version of the package.
+func (pkgw *packageWatcher) buildWithRetry(ctx context.Context, builderHost string, pkg *fv1.Package) (
+	*fv1.Package, *types.ArchiveUploadResponse, *buildLog, error) {
+
+	backoff := pkgw.buildRetry.backoff
+	for attempt := 1; ; attempt++ {
+		result := fv1.BuildAttempt{
+			Attempt:        attempt,
+			Status:         fv1.BuildStatusSucceeded,
+			StartTimestamp: time.Now().UTC(),
+		}
+
+		uploadResp, buildLogs, err := buildPackage(ctx, pkgw.logger, pkgw.fissionClient, pkgw.k8sClient, builderHost, pkgw.storageSvcUrl, pkg)
+
+		result.FinishTimestamp = time.Now().UTC()
+		if err != nil {
+			result.Status = fv1.BuildStatusFailed
+			result.Message = err.Error()
+		}
+		pkg.Status.BuildAttempts = append(pkg.Status.BuildAttempts, result)
+
+		if err == nil || attempt > pkgw.buildRetry.count {
+			return pkg, uploadResp, buildLogs, err
+		}
+
+		pkgw.logger.Info("package build failed, will retry again later",
+			zap.Error(err),
+			zap.String("package_name", pkg.Metadata.Name),
+			zap.String("package_namespace", pkg.Metadata.Namespace),
+			zap.Int("attempt", attempt),
+			zap.Duration("backoff", backoff))
+		buildLogs.append(types.BuildStepBuild, fmt.Sprintf("Build attempt %v failed, retrying in %v\n", attempt, backoff))
+
+		// Record the failed attempt and its log while waiting for the next one.
+		updatedPkg, uerr := updatePackage(pkgw.logger, pkgw.fissionClient, pkgw.storageSvcUrl, pkg,
+			fv1.BuildStatusRunning, buildLogs, nil)
+		if uerr != nil {
+			// The package may have been changed by others, give up on this build.
+			return pkg, nil, buildLogs, err
+		}
+		pkg = updatedPkg
+
+		select {
+		case <-ctx.Done():
+			return pkg, nil, buildLogs, err
+		case <-time.After(backoff):
+		}
+
+		backoff = nextBuildRetryBackoff(backoff)
+	}
+}
+
+// nextBuildRetryBackoff doubles the wait time between build retries, up
+// to maxBuildRetryBackoff.
+func nextBuildRetryBackoff(backoff time.Duration) time.Duration {
+	backoff *= 2
+	if backoff > maxBuildRetryBackoff {
+		backoff = maxBuildRetryBackoff
+	}
+	return backoff
+}
+
 func (pkgw *packageWatcher) watchPackages(fissionClient *crd.FissionClient,
 	kubernetesClient *kubernetes.Clientset, builderNamespace string) {
 	buildCache := cache.MakeCache(0, 0)
@@ -246,3 +386,33 @@ func (pkgw *packageWatcher) watchPackages(fissionClient *crd.FissionClient,
 	pkgw.pkgStore = pkgStore
 	controller.Run(make(chan struct{}))
 }
+
+// refreshFunctions asks the executor to replace the pods of the functions
+// using the rebuilt package, so that they run the new build. A failure
+// doesn't fail the build, new pods pick up the package anyway.
+func (pkgw *packageWatcher) refreshFunctions(pkg *fv1.Package) {
+	if len(pkgw.refreshStrategy) == 0 {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	resp, err := pkgw.executor.RefreshPackage(ctx, &types.PackageRefreshRequest{
+		Package:  pkg.Metadata,
+		Strategy: pkgw.refreshStrategy,
+	})
+	if err != nil {
+		pkgw.logger.Error("error refreshing functions of rebuilt package",
+			zap.Error(err),
+			zap.String("package_name", pkg.Metadata.Name),
+			zap.String("package_namespace", pkg.Metadata.Namespace))
+		return
+	}
+
+	pkgw.logger.Info("refreshed functions of rebuilt package",
+		zap.String("package_name", pkg.Metadata.Name),
+		zap.String("package_namespace", pkg.Metadata.Namespace),
+		zap.String("strategy", string(pkgw.refreshStrategy)),
+		zap.Strings("functions", resp.Functions))
+}
@@ -0,0 +1,45 @@
+/*
+Copyright 2019 The Fission Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package buildermgr
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNextBuildRetryBackoff(t *testing.T) {
+	backoff := 10 * time.Second
+	var backoffs []time.Duration
+	for i := 0; i < 7; i++ {
+		backoffs = append(backoffs, backoff)
+		backoff = nextBuildRetryBackoff(backoff)
+	}
+	assert.Equal(t, []time.Duration{
+		10 * time.Second,
+		20 * time.Second,
+		40 * time.Second,
+		80 * time.Second,
+		160 * time.Second,
+		maxBuildRetryBackoff,
+		maxBuildRetryBackoff,
+	}, backoffs)
+
+	// a backoff configured above the cap is capped too
+	assert.Equal(t, maxBuildRetryBackoff, nextBuildRetryBackoff(10*time.Minute))
+}
@@ -0,0 +1,271 @@
+/*
+Copyright 2019 The Fission Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package buildermgr
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path"
+	"time"
+
+	"github.com/pkg/errors"
+	"go.uber.org/zap"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	fv1 "github.com/fission/fission/pkg/apis/fission.io/v1"
+	"github.com/fission/fission/pkg/types"
+)
+
+const (
+	postBuildHookTimeout    = 5 * time.Minute
+	postBuildHookMaxRetries = 3
+)
+
+// postBuildEvent is the JSON payload of post-build webhooks.
+type postBuildEvent struct {
+	Package         string       `json:"package"`
+	Namespace       string       `json:"namespace"`
+	ResourceVersion string       `json:"resourceVersion"`
+	Environment     string       `json:"environment"`
+	ArchiveURL      string       `json:"archiveUrl"`
+	Checksum        fv1.Checksum `json:"checksum"`
+	SBOM            string       `json:"sbom,omitempty"`
+	Timestamp       time.Time    `json:"timestamp"`
+}
+
+// runPostBuildHooks runs the post-build hooks of the package in order and
+// records their results in the build log. It returns an error if a
+// required hook failed, the hooks after it aren't run.
+func (pkgw *packageWatcher) runPostBuildHooks(ctx context.Context, pkg *fv1.Package,
+	uploadResp *types.ArchiveUploadResponse, buildLogs *buildLog) error {
+	if len(pkg.Spec.PostBuildHooks) == 0 {
+		return nil
+	}
+
+	// the archive is downloaded once, by the first hook that needs it
+	var archivePath string
+	var archiveErr error
+	archive := func() (string, error) {
+		if len(archivePath) == 0 && archiveErr == nil {
+			archivePath, archiveErr = downloadArchive(ctx, uploadResp.ArchiveDownloadUrl)
+			archiveErr = errors.Wrap(archiveErr, "error downloading deployment archive")
+		}
+		return archivePath, archiveErr
+	}
+	defer func() {
+		if len(archivePath) > 0 {
+			os.Remove(archivePath)
+		}
+	}()
+
+	for i := range pkg.Spec.PostBuildHooks {
+		hook := &pkg.Spec.PostBuildHooks[i]
+		err := pkgw.runPostBuildHook(ctx, pkg, hook, uploadResp, archive)
+		if err != nil {
+			pkgw.logger.Error("post-build hook failed",
+				zap.Error(err),
+				zap.String("hook", hook.Name),
+				zap.String("package_name", pkg.Metadata.Name),
+				zap.String("package_namespace", pkg.Metadata.Namespace))
+			buildLogs.append(types.BuildStepHooks, fmt.Sprintf("post-build hook %v failed: %v\n", hook.Name, err))
+			if hook.Required {
+				return errors.Wrapf(err, "required post-build hook %v failed", hook.Name)
+			}
+			continue
+		}
+
+		pkgw.logger.Info("post-build hook succeeded",
+			zap.String("hook", hook.Name),
+			zap.String("package_name", pkg.Metadata.Name),
+			zap.String("package_namespace", pkg.Metadata.Namespace))
+		buildLogs.append(types.BuildStepHooks, fmt.Sprintf("post-build hook %v succeeded\n", hook.Name))
+	}
+	return nil
+}
+
+func (pkgw *packageWatcher) runPostBuildHook(ctx context.Context, pkg *fv1.Package, hook *fv1.PostBuildHook,
+	uploadResp *types.ArchiveUploadResponse, archive func() (string, error)) error {
+	headers := make(http.Header)
+	if len(hook.Secret) > 0 {
+		secret, err := pkgw.k8sClient.CoreV1().Secrets(pkg.Metadata.Namespace).Get(hook.Secret, metav1.GetOptions{})
+		if err != nil {
+			return errors.Wrapf(err, "error getting secret %v", hook.Secret)
+		}
+		for k, v := range secret.Data {
+			headers.Set(k, string(v))
+		}
+	}
+
+	switch hook.Type {
+	case fv1.PostBuildHookTypePublish:
+		headers.Set("Content-Type", "application/octet-stream")
+		if uploadResp.Checksum.Type == fv1.ChecksumTypeSHA256 {
+			headers.Set("X-Checksum-Sha256", uploadResp.Checksum.Sum)
+		}
+		archivePath, err := archive()
+		if err != nil {
+			return err
+		}
+		return sendHookRequest(ctx, http.MethodPut, hook.URL, headers, func() (io.ReadCloser, error) {
+			return os.Open(archivePath)
+		})
+
+	case fv1.PostBuildHookTypeWebhook:
+		event := postBuildEvent{
+			Package:         pkg.Metadata.Name,
+			Namespace:       pkg.Metadata.Namespace,
+			ResourceVersion: pkg.Metadata.ResourceVersion,
+			Environment:     pkg.Spec.Environment.Name,
+			ArchiveURL:      uploadResp.ArchiveDownloadUrl,
+			Checksum:        uploadResp.Checksum,
+			Timestamp:       time.Now().UTC(),
+		}
+		if len(hook.SBOMPath) > 0 {
+			archivePath, err := archive()
+			if err != nil {
+				return err
+			}
+			sbom, err := readArchiveFile(archivePath, hook.SBOMPath)
+			if err != nil {
+				return errors.Wrap(err, "error reading SBOM")
+			}
+			event.SBOM = string(sbom)
+		}
+		payload, err := json.Marshal(event)
+		if err != nil {
+			return errors.Wrap(err, "error marshaling post-build event")
+		}
+		headers.Set("Content-Type", "application/json")
+		return sendHookRequest(ctx, http.MethodPost, hook.URL, headers, func() (io.ReadCloser, error) {
+			return ioutil.NopCloser(bytes.NewReader(payload)), nil
+		})
+
+	default:
+		return fmt.Errorf("unsupported post-build hook type %q", hook.Type)
+	}
+}
+
+// sendHookRequest sends a hook request, retrying on errors and server
+// errors. body is called for every attempt.
+func sendHookRequest(ctx context.Context, method string, url string, headers http.Header, body func() (io.ReadCloser, error)) error {
+	client := &http.Client{Timeout: postBuildHookTimeout}
+
+	var err error
+	for i := 0; i < postBuildHookMaxRetries; i++ {
+		if i > 0 {
+			time.Sleep(time.Duration(i) * time.Second)
+		}
+
+		var reqBody io.ReadCloser
+		reqBody, err = body()
+		if err != nil {
+			return err
+		}
+
+		var req *http.Request
+		req, err = http.NewRequest(method, url, reqBody)
+		if err != nil {
+			reqBody.Close()
+			return err
+		}
+		req = req.WithContext(ctx)
+		for k, v := range headers {
+			req.Header[k] = v
+		}
+		if f, ok := reqBody.(*os.File); ok {
+			if fi, err := f.Stat(); err == nil {
+				req.ContentLength = fi.Size()
+			}
+		}
+
+		var resp *http.Response
+		resp, err = client.Do(req)
+		if err != nil {
+			continue
+		}
+		resp.Body.Close()
+
+		if resp.StatusCode < 300 {
+			return nil
+		}
+		err = fmt.Errorf("hook returned status %v", resp.StatusCode)
+		if resp.StatusCode < 500 {
+			// the request won't get better with retries
+			return err
+		}
+	}
+	return errors.Wrapf(err, "error sending hook request after %v attempts", postBuildHookMaxRetries)
+}
+
+// downloadArchive downloads the deployment archive from the storage service
+// to a temporary file and returns its path.
+func downloadArchive(ctx context.Context, url string) (string, error) {
+	req, err := http.NewRequest(http.MethodGet, url, nil)
+	if err != nil {
+		return "", err
+	}
+	resp, err := http.DefaultClient.Do(req.WithContext(ctx))
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("storage service returned status %v", resp.StatusCode)
+	}
+
+	tmpFile, err := ioutil.TempFile("", "fission-deployment-")
+	if err != nil {
+		return "", err
+	}
+	_, err = io.Copy(tmpFile, resp.Body)
+	tmpFile.Close()
+	if err != nil {
+		os.Remove(tmpFile.Name())
+		return "", err
+	}
+	return tmpFile.Name(), nil
+}
+
+// readArchiveFile reads a file of a zip deployment archive.
+func readArchiveFile(archivePath string, name string) ([]byte, error) {
+	r, err := zip.OpenReader(archivePath)
+	if err != nil {
+		return nil, errors.Wrap(err, "deployment archive is not a zip file")
+	}
+	defer r.Close()
+
+	name = path.Clean(name)
+	for _, f := range r.File {
+		if path.Clean(f.Name) != name {
+			continue
+		}
+		rc, err := f.Open()
+		if err != nil {
+			return nil, err
+		}
+		defer rc.Close()
+		return ioutil.ReadAll(rc)
+	}
+	return nil, fmt.Errorf("file %v not found in deployment archive", name)
+}
@@ -0,0 +1,135 @@
+/*
+Copyright 2019 The Fission Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package canaryconfigmgr
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/pkg/errors"
+	"go.uber.org/zap"
+
+	fv1 "github.com/fission/fission/pkg/apis/fission.io/v1"
+)
+
+// canaryQueryParams are the fields available to the PromQL expressions of a
+// canary config.
+type canaryQueryParams struct {
+	Function    string
+	OldFunction string
+	Namespace   string
+	Path        string
+	Method      string
+	Window      string
+}
+
+// triggerMethodRegex returns the regular expression matching the method
+// label of the metrics of the requests of a HTTP trigger.
+func triggerMethodRegex(trigger *fv1.HTTPTrigger) string {
+	if trigger.Spec.HasMethod(fv1.HTTPMethodAny) {
+		return ".+"
+	}
+	return strings.Join(trigger.Spec.GetMethods(), "|")
+}
+
+func renderCanaryQuery(query string, params canaryQueryParams) (string, error) {
+	tmpl, err := template.New("query").Parse(query)
+	if err != nil {
+		return "", errors.Wrapf(err, "error parsing query %q", query)
+	}
+	var buf bytes.Buffer
+	err = tmpl.Execute(&buf, params)
+	if err != nil {
+		return "", errors.Wrapf(err, "error rendering query %q", query)
+	}
+	return buf.String(), nil
+}
+
+// checkCanaryMetrics checks the latency threshold and the custom PromQL
+// expressions of a canary config against the new version of the function.
+// It returns the reason to roll the new version back, or deferred if some
+// check had no data to decide on in this interval.
+func (canaryCfgMgr *canaryConfigMgr) checkCanaryMetrics(canaryConfig *fv1.CanaryConfig, trigger *fv1.HTTPTrigger) (reason string, deferred bool, err error) {
+	if canaryConfig.Spec.LatencyThreshold > 0 {
+		latency, found, err := canaryCfgMgr.promClient.GetFunctionLatency(trigger.Spec.GetPath(), triggerMethodRegex(trigger),
+			canaryConfig.Spec.NewFunction, canaryConfig.Metadata.Namespace)
+		if err != nil {
+			return "", false, errors.Wrap(err, "error getting function latency")
+		}
+		if !found {
+			return "", true, nil
+		}
+		threshold := time.Duration(canaryConfig.Spec.LatencyThreshold) * time.Millisecond
+		if latency > threshold.Seconds() {
+			return fmt.Sprintf("99th percentile latency %vs is above the threshold %v", latency, threshold), false, nil
+		}
+	}
+
+	params := canaryQueryParams{
+		Function:    canaryConfig.Spec.NewFunction,
+		OldFunction: canaryConfig.Spec.OldFunction,
+		Namespace:   canaryConfig.Metadata.Namespace,
+		Path:        trigger.Spec.GetPath(),
+		Method:      triggerMethodRegex(trigger),
+		Window:      canaryConfig.Spec.WeightIncrementDuration,
+	}
+
+	for _, query := range canaryConfig.Spec.FailureQueries {
+		val, found, err := canaryCfgMgr.evaluateCanaryQuery(query, params)
+		if err != nil {
+			return "", false, err
+		}
+		if found && val != 0 {
+			return fmt.Sprintf("failure query %q evaluated to %v", query, val), false, nil
+		}
+		deferred = deferred || !found
+	}
+
+	for _, query := range canaryConfig.Spec.SuccessQueries {
+		val, found, err := canaryCfgMgr.evaluateCanaryQuery(query, params)
+		if err != nil {
+			return "", false, err
+		}
+		if found && val == 0 {
+			return fmt.Sprintf("success query %q evaluated to 0", query), false, nil
+		}
+		deferred = deferred || !found
+	}
+
+	return "", deferred, nil
+}
+
+func (canaryCfgMgr *canaryConfigMgr) evaluateCanaryQuery(query string, params canaryQueryParams) (float64, bool, error) {
+	queryString, err := renderCanaryQuery(query, params)
+	if err != nil {
+		return 0, false, err
+	}
+
+	val, found, err := canaryCfgMgr.promClient.EvaluateQuery(queryString)
+	if err != nil {
+		return 0, false, err
+	}
+
+	canaryCfgMgr.logger.Info("canary query evaluated",
+		zap.String("query", queryString),
+		zap.Float64("value", val),
+		zap.Bool("found", found))
+	return val, found, nil
+}
@@ -273,7 +273,7 @@ func (canaryCfgMgr *canaryConfigMgr) RollForwardOrBack(canaryConfig *fv1.CanaryC
 
 	if triggerObj.Spec.FunctionReference.Type == types.FunctionReferenceTypeFunctionWeights &&
 		triggerObj.Spec.FunctionReference.FunctionWeights[canaryConfig.Spec.NewFunction] != 0 {
-		failurePercent, err := canaryCfgMgr.promClient.GetFunctionFailurePercentage(triggerObj.Spec.RelativeURL, triggerObj.Spec.Method,
+		failurePercent, err := canaryCfgMgr.promClient.GetFunctionFailurePercentage(triggerObj.Spec.GetPath(), triggerMethodRegex(triggerObj),
 			canaryConfig.Spec.NewFunction, canaryConfig.Metadata.Namespace, canaryConfig.Spec.WeightIncrementDuration)
 
 		if err != nil {
@@ -295,7 +295,7 @@ func (canaryCfgMgr *canaryConfigMgr) RollForwardOrBack(canaryConfig *fv1.CanaryC
 		if failurePercent == -1 {
 			// this means there were no requests triggered to this url during this window. return here and check back
 			// during next iteration
-			canaryCfgMgr.logger.Info("total requests received for url is 0", zap.String("url", triggerObj.Spec.RelativeURL))
+			canaryCfgMgr.logger.Info("total requests received for url is 0", zap.String("url", triggerObj.Spec.GetPath()))
 			return
 		}
 
@@ -307,7 +307,8 @@ func (canaryCfgMgr *canaryConfigMgr) RollForwardOrBack(canaryConfig *fv1.CanaryC
 				zap.String("namespace", canaryConfig.Metadata.Namespace),
 				zap.String("version", canaryConfig.Metadata.ResourceVersion))
 			ticker.Stop()
-			err := canaryCfgMgr.rollback(canaryConfig, triggerObj)
+			err := canaryCfgMgr.rollback(canaryConfig, triggerObj,
+				fmt.Sprintf("failure percentage %.2f%% crossed the threshold %v%%", failurePercent, canaryConfig.Spec.FailureThreshold))
 			if err != nil {
 				canaryCfgMgr.logger.Error("error rolling back canary config",
 					zap.Error(err),
@@ -318,6 +319,45 @@ func (canaryCfgMgr *canaryConfigMgr) RollForwardOrBack(canaryConfig *fv1.CanaryC
 			close(quit)
 			return
 		}
+
+		reason, deferred, err := canaryCfgMgr.checkCanaryMetrics(canaryConfig, triggerObj)
+		if err != nil {
+			// silently ignore. wait for next window to increment weight
+			canaryCfgMgr.logger.Error("error checking canary metrics",
+				zap.Error(err),
+				zap.String("name", canaryConfig.Metadata.Name),
+				zap.String("namespace", canaryConfig.Metadata.Namespace),
+				zap.String("version", canaryConfig.Metadata.ResourceVersion))
+			return
+		}
+
+		if len(reason) > 0 {
+			canaryCfgMgr.logger.Error("canary metrics check failed, so rolling back",
+				zap.String("reason", reason),
+				zap.String("name", canaryConfig.Metadata.Name),
+				zap.String("namespace", canaryConfig.Metadata.Namespace),
+				zap.String("version", canaryConfig.Metadata.ResourceVersion))
+			ticker.Stop()
+			err := canaryCfgMgr.rollback(canaryConfig, triggerObj, reason)
+			if err != nil {
+				canaryCfgMgr.logger.Error("error rolling back canary config",
+					zap.Error(err),
+					zap.String("name", canaryConfig.Metadata.Name),
+					zap.String("namespace", canaryConfig.Metadata.Namespace),
+					zap.String("version", canaryConfig.Metadata.ResourceVersion))
+			}
+			close(quit)
+			return
+		}
+
+		if deferred {
+			// some metrics have no data yet, check back during next iteration
+			canaryCfgMgr.logger.Info("canary metrics have no data in this window",
+				zap.String("name", canaryConfig.Metadata.Name),
+				zap.String("namespace", canaryConfig.Metadata.Namespace),
+				zap.String("version", canaryConfig.Metadata.ResourceVersion))
+			return
+		}
 	}
 
 	doneProcessingCanaryConfig, err := canaryCfgMgr.rollForward(canaryConfig, triggerObj)
@@ -351,6 +391,7 @@ func (canaryCfgMgr *canaryConfigMgr) RollForwardOrBack(canaryConfig *fv1.CanaryC
 			zap.String("name", canaryConfig.Metadata.Name),
 			zap.String("namespace", canaryConfig.Metadata.Namespace),
 			zap.String("version", canaryConfig.Metadata.ResourceVersion))
+		canaryCfgMgr.notify(canaryConfig, types.CanaryConfigStatusSucceeded, "")
 		close(quit)
 		return
 	}
@@ -437,7 +478,7 @@ func (canaryCfgMgr *canaryConfigMgr) updateCanaryConfigStatusWithRetries(cfgName
 	return err
 }
 
-func (canaryCfgMgr *canaryConfigMgr) rollback(canaryConfig *fv1.CanaryConfig, trigger *fv1.HTTPTrigger) error {
+func (canaryCfgMgr *canaryConfigMgr) rollback(canaryConfig *fv1.CanaryConfig, trigger *fv1.HTTPTrigger, reason string) error {
 	functionWeights := trigger.Spec.FunctionReference.FunctionWeights
 	functionWeights[canaryConfig.Spec.NewFunction] = 0
 	functionWeights[canaryConfig.Spec.OldFunction] = 100
@@ -447,6 +488,8 @@ func (canaryCfgMgr *canaryConfigMgr) rollback(canaryConfig *fv1.CanaryConfig, tr
 	err = canaryCfgMgr.updateCanaryConfigStatusWithRetries(canaryConfig.Metadata.Name, canaryConfig.Metadata.Namespace,
 		types.CanaryConfigStatusFailed)
 
+	canaryCfgMgr.notify(canaryConfig, types.CanaryConfigStatusFailed, reason)
+
 	return err
 }
 
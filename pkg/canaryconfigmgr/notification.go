@@ -0,0 +1,145 @@
+/*
+Copyright 2019 The Fission Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package canaryconfigmgr
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/pkg/errors"
+	"go.uber.org/zap"
+
+	fv1 "github.com/fission/fission/pkg/apis/fission.io/v1"
+)
+
+const (
+	webhookTimeout    = 10 * time.Second
+	webhookMaxRetries = 3
+)
+
+// canaryEvent is the JSON payload of the canary config webhooks.
+type canaryEvent struct {
+	Name        string    `json:"name"`
+	Namespace   string    `json:"namespace"`
+	Trigger     string    `json:"trigger"`
+	NewFunction string    `json:"newFunction"`
+	OldFunction string    `json:"oldFunction"`
+	Status      string    `json:"status"`
+	Reason      string    `json:"reason,omitempty"`
+	Timestamp   time.Time `json:"timestamp"`
+}
+
+// makeWebhookPayload returns the webhook payload of the outcome of a canary
+// config in the webhook format of the config.
+func makeWebhookPayload(canaryConfig *fv1.CanaryConfig, status string, reason string, now time.Time) ([]byte, error) {
+	event := canaryEvent{
+		Name:        canaryConfig.Metadata.Name,
+		Namespace:   canaryConfig.Metadata.Namespace,
+		Trigger:     canaryConfig.Spec.Trigger,
+		NewFunction: canaryConfig.Spec.NewFunction,
+		OldFunction: canaryConfig.Spec.OldFunction,
+		Status:      status,
+		Reason:      reason,
+		Timestamp:   now.UTC(),
+	}
+
+	if canaryConfig.Spec.WebhookFormat != fv1.CanaryWebhookFormatSlack {
+		return json.Marshal(event)
+	}
+
+	var text string
+	if status == fv1.CanaryConfigStatusSucceeded {
+		text = fmt.Sprintf(":white_check_mark: Canary config `%v.%v` promoted function `%v`, it now receives all the traffic of trigger `%v`.",
+			event.Name, event.Namespace, event.NewFunction, event.Trigger)
+	} else {
+		text = fmt.Sprintf(":rotating_light: Canary config `%v.%v` rolled back function `%v` to `%v` on trigger `%v`: %v",
+			event.Name, event.Namespace, event.NewFunction, event.OldFunction, event.Trigger, event.Reason)
+	}
+	return json.Marshal(map[string]string{"text": text})
+}
+
+// notify posts the outcome of a canary config to its webhook in the
+// background. Failures are only logged, they don't change the outcome.
+func (canaryCfgMgr *canaryConfigMgr) notify(canaryConfig *fv1.CanaryConfig, status string, reason string) {
+	webhook := canaryConfig.Spec.OnFailureWebhook
+	if status == fv1.CanaryConfigStatusSucceeded {
+		webhook = canaryConfig.Spec.OnSuccessWebhook
+	}
+	if len(webhook) == 0 {
+		return
+	}
+
+	payload, err := makeWebhookPayload(canaryConfig, status, reason, time.Now())
+	if err != nil {
+		canaryCfgMgr.logger.Error("error making canary config webhook payload",
+			zap.Error(err),
+			zap.String("name", canaryConfig.Metadata.Name),
+			zap.String("namespace", canaryConfig.Metadata.Namespace))
+		return
+	}
+
+	go func() {
+		err := postWebhook(webhook, payload)
+		if err != nil {
+			canaryCfgMgr.logger.Error("error calling canary config webhook",
+				zap.Error(err),
+				zap.String("webhook", webhook),
+				zap.String("status", status),
+				zap.String("name", canaryConfig.Metadata.Name),
+				zap.String("namespace", canaryConfig.Metadata.Namespace))
+			return
+		}
+		canaryCfgMgr.logger.Info("called canary config webhook",
+			zap.String("webhook", webhook),
+			zap.String("status", status),
+			zap.String("name", canaryConfig.Metadata.Name),
+			zap.String("namespace", canaryConfig.Metadata.Namespace))
+	}()
+}
+
+// postWebhook posts the payload to the webhook, retrying on errors and
+// server errors.
+func postWebhook(webhook string, payload []byte) error {
+	client := &http.Client{Timeout: webhookTimeout}
+
+	var err error
+	for i := 0; i < webhookMaxRetries; i++ {
+		if i > 0 {
+			time.Sleep(time.Duration(i) * time.Second)
+		}
+
+		var resp *http.Response
+		resp, err = client.Post(webhook, "application/json", bytes.NewReader(payload))
+		if err != nil {
+			continue
+		}
+		resp.Body.Close()
+
+		if resp.StatusCode < 300 {
+			return nil
+		}
+		err = fmt.Errorf("webhook returned status %v", resp.StatusCode)
+		if resp.StatusCode < 500 {
+			// the request won't get better with retries
+			return err
+		}
+	}
+	return errors.Wrapf(err, "error posting to webhook after %v attempts", webhookMaxRetries)
+}
@@ -0,0 +1,83 @@
+/*
+Copyright 2019 The Fission Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package canaryconfigmgr
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	fv1 "github.com/fission/fission/pkg/apis/fission.io/v1"
+)
+
+func TestMakeWebhookPayload(t *testing.T) {
+	now := time.Date(2019, 10, 1, 12, 0, 0, 0, time.UTC)
+	cfg := &fv1.CanaryConfig{
+		Metadata: metav1.ObjectMeta{Name: "canary", Namespace: "default"},
+		Spec: fv1.CanaryConfigSpec{
+			Trigger:     "hello",
+			NewFunction: "hello-v2",
+			OldFunction: "hello-v1",
+		},
+	}
+
+	payload, err := makeWebhookPayload(cfg, fv1.CanaryConfigStatusFailed, "failure percentage 20.00% crossed the threshold 10%", now)
+	assert.NoError(t, err)
+	var event canaryEvent
+	assert.NoError(t, json.Unmarshal(payload, &event))
+	assert.Equal(t, canaryEvent{
+		Name:        "canary",
+		Namespace:   "default",
+		Trigger:     "hello",
+		NewFunction: "hello-v2",
+		OldFunction: "hello-v1",
+		Status:      fv1.CanaryConfigStatusFailed,
+		Reason:      "failure percentage 20.00% crossed the threshold 10%",
+		Timestamp:   now,
+	}, event)
+
+	cfg.Spec.WebhookFormat = fv1.CanaryWebhookFormatSlack
+	payload, err = makeWebhookPayload(cfg, fv1.CanaryConfigStatusSucceeded, "", now)
+	assert.NoError(t, err)
+	var msg map[string]string
+	assert.NoError(t, json.Unmarshal(payload, &msg))
+	assert.Contains(t, msg["text"], "promoted function `hello-v2`")
+}
+
+func TestPostWebhook(t *testing.T) {
+	var calls int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		if strings.HasSuffix(r.URL.Path, "/bad") {
+			w.WriteHeader(http.StatusBadRequest)
+		}
+	}))
+	defer server.Close()
+
+	assert.NoError(t, postWebhook(server.URL+"/ok", []byte("{}")))
+	assert.Equal(t, 1, calls)
+
+	// client errors aren't retried
+	assert.Error(t, postWebhook(server.URL+"/bad", []byte("{}")))
+	assert.Equal(t, 2, calls)
+}
@@ -18,6 +18,7 @@ package canaryconfigmgr
 
 import (
 	"fmt"
+	"math"
 	"time"
 
 	"github.com/pkg/errors"
@@ -75,14 +76,14 @@ func (promApiClient *PrometheusApiClient) GetFunctionFailurePercentage(path, met
 }
 
 func (promApiClient *PrometheusApiClient) GetRequestsToFuncInWindow(path string, method string, funcName string, funcNs string, window string) (float64, error) {
-	queryString := fmt.Sprintf("fission_function_calls_total{path=\"%s\",method=\"%s\",name=\"%s\",namespace=\"%s\"}[%v]", path, method, funcName, funcNs, window)
+	queryString := fmt.Sprintf("fission_function_calls_total{path=\"%s\",method=~\"%s\",name=\"%s\",namespace=\"%s\"}[%v]", path, method, funcName, funcNs, window)
 
 	reqs, err := promApiClient.executeQuery(queryString)
 	if err != nil {
 		return 0, errors.Wrapf(err, "error executing query: %s", queryString)
 	}
 
-	queryString = fmt.Sprintf("fission_function_calls_total{path=\"%s\",method=\"%s\",name=\"%s\",namespace=\"%s\"} offset %v", path, method, funcName, funcNs, window)
+	queryString = fmt.Sprintf("fission_function_calls_total{path=\"%s\",method=~\"%s\",name=\"%s\",namespace=\"%s\"} offset %v", path, method, funcName, funcNs, window)
 
 	reqsInPrevWindow, err := promApiClient.executeQuery(queryString)
 	if err != nil {
@@ -100,14 +101,14 @@ func (promApiClient *PrometheusApiClient) GetRequestsToFuncInWindow(path string,
 }
 
 func (promApiClient *PrometheusApiClient) GetTotalFailedRequestsToFuncInWindow(funcName string, funcNs string, path string, method string, window string) (float64, error) {
-	queryString := fmt.Sprintf("fission_function_errors_total{name=\"%s\",namespace=\"%s\",path=\"%s\", method=\"%s\"}[%v]", funcName, funcNs, path, method, window)
+	queryString := fmt.Sprintf("fission_function_errors_total{name=\"%s\",namespace=\"%s\",path=\"%s\", method=~\"%s\"}[%v]", funcName, funcNs, path, method, window)
 
 	failedRequests, err := promApiClient.executeQuery(queryString)
 	if err != nil {
 		return 0, errors.Wrapf(err, "error executing query: %s", queryString)
 	}
 
-	queryString = fmt.Sprintf("fission_function_errors_total{name=\"%s\",namespace=\"%s\",path=\"%s\", method=\"%s\"} offset %v", funcName, funcNs, path, method, window)
+	queryString = fmt.Sprintf("fission_function_errors_total{name=\"%s\",namespace=\"%s\",path=\"%s\", method=~\"%s\"} offset %v", funcName, funcNs, path, method, window)
 
 	failedReqsInPrevWindow, err := promApiClient.executeQuery(queryString)
 	if err != nil {
@@ -124,10 +125,75 @@ func (promApiClient *PrometheusApiClient) GetTotalFailedRequestsToFuncInWindow(f
 	return failedReqsInCurrentWindow, nil
 }
 
+// GetFunctionLatency returns the highest 99th percentile latency in seconds
+// of a function across the router instances, false means there is no data.
+func (promApiClient *PrometheusApiClient) GetFunctionLatency(path, method, funcName, funcNs string) (float64, bool, error) {
+	queryString := fmt.Sprintf("max(fission_function_duration_seconds{path=\"%s\",method=~\"%s\",name=\"%s\",namespace=\"%s\",quantile=\"0.99\"})", path, method, funcName, funcNs)
+
+	latency, found, err := promApiClient.evaluateQuery(queryString)
+	if err != nil {
+		return 0, false, errors.Wrapf(err, "error executing query: %s", queryString)
+	}
+	// a quantile is NaN if the function had no requests recently
+	if math.IsNaN(latency) {
+		return 0, false, nil
+	}
+	return latency, found, nil
+}
+
+// EvaluateQuery returns the value of a PromQL expression, false means the
+// expression returned no data.
+func (promApiClient *PrometheusApiClient) EvaluateQuery(queryString string) (float64, bool, error) {
+	val, found, err := promApiClient.evaluateQuery(queryString)
+	if err != nil {
+		return 0, false, errors.Wrapf(err, "error executing query: %s", queryString)
+	}
+	return val, found, nil
+}
+
+// EvaluateRangeQuery returns the values of a PromQL expression at each step
+// between start and end, summed across the series. Steps without data are 0.
+func (promApiClient *PrometheusApiClient) EvaluateRangeQuery(queryString string, start time.Time, end time.Time, step time.Duration) ([]float64, error) {
+	val, warn, err := promApiClient.client.QueryRange(context.Background(), queryString, prometheusv1.Range{
+		Start: start,
+		End:   end,
+		Step:  step,
+	})
+	if err != nil {
+		return nil, errors.Wrapf(err, "error executing query: %s", queryString)
+	}
+
+	if warn != nil {
+		promApiClient.logger.Warn("receive prometheus client query warning", zap.Any("msg", warn))
+	}
+
+	matrixVal, ok := val.(model.Matrix)
+	if !ok {
+		return nil, fmt.Errorf("unexpected return value type %v of range query: %s", val.Type(), queryString)
+	}
+
+	values := make([]float64, int(end.Sub(start)/step)+1)
+	for _, elem := range matrixVal {
+		for _, pair := range elem.Values {
+			i := int(pair.Timestamp.Time().Sub(start) / step)
+			if i < 0 || i >= len(values) || math.IsNaN(float64(pair.Value)) {
+				continue
+			}
+			values[i] += float64(pair.Value)
+		}
+	}
+	return values, nil
+}
+
 func (promApiClient *PrometheusApiClient) executeQuery(queryString string) (float64, error) {
+	val, _, err := promApiClient.evaluateQuery(queryString)
+	return val, err
+}
+
+func (promApiClient *PrometheusApiClient) evaluateQuery(queryString string) (float64, bool, error) {
 	val, warn, err := promApiClient.client.Query(context.Background(), queryString, time.Now())
 	if err != nil {
-		return 0, errors.Wrapf(err, "error querying prometheus")
+		return 0, false, errors.Wrapf(err, "error querying prometheus")
 	}
 
 	if warn != nil {
@@ -137,7 +203,7 @@ func (promApiClient *PrometheusApiClient) executeQuery(queryString string) (floa
 	switch {
 	case val.Type() == model.ValScalar:
 		scalarVal := val.(*model.Scalar)
-		return float64(scalarVal.Value), nil
+		return float64(scalarVal.Value), true, nil
 
 	case val.Type() == model.ValVector:
 		vectorVal := val.(model.Vector)
@@ -145,7 +211,7 @@ func (promApiClient *PrometheusApiClient) executeQuery(queryString string) (floa
 		for _, elem := range vectorVal {
 			total = total + float64(elem.Value)
 		}
-		return total, nil
+		return total, len(vectorVal) > 0, nil
 
 	case val.Type() == model.ValMatrix:
 		matrixVal := val.(model.Matrix)
@@ -153,11 +219,11 @@ func (promApiClient *PrometheusApiClient) executeQuery(queryString string) (floa
 		for _, elem := range matrixVal {
 			total += float64(elem.Values[len(elem.Values)-1].Value)
 		}
-		return total, nil
+		return total, len(matrixVal) > 0, nil
 
 	default:
 		promApiClient.logger.Info("return value type of prometheus query was unrecognized",
 			zap.Any("type", val.Type()))
-		return 0, nil
+		return 0, false, nil
 	}
 }
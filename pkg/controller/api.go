@@ -17,10 +17,12 @@ limitations under the License.
 package controller
 
 import (
+	"encoding/json"
 	"fmt"
 	"net/http"
 	"os"
 	"runtime/debug"
+	"strconv"
 	"strings"
 
 	"github.com/gorilla/mux"
@@ -32,8 +34,11 @@ import (
 
 	"github.com/fission/fission/pkg/crd"
 	ferror "github.com/fission/fission/pkg/error"
+	executorClient "github.com/fission/fission/pkg/executor/client"
 	"github.com/fission/fission/pkg/fission-cli/logdb"
+	"github.com/fission/fission/pkg/health"
 	"github.com/fission/fission/pkg/info"
+	"github.com/fission/fission/pkg/types"
 )
 
 var podNamespace string
@@ -56,6 +61,23 @@ type (
 		functionNamespace string
 		useIstio          bool
 		featureStatus     map[string]string
+
+		// archiveUploadMaxSize is the max size in bytes of an archive uploaded through the controller.
+		archiveUploadMaxSize int64
+		// archiveUploadToken is the bearer token required for archive uploads, which are rejected if it's empty.
+		archiveUploadToken string
+		// archiveLiteralSizeLimit is the max size in bytes of a package literal, advertised to the CLI.
+		archiveLiteralSizeLimit int64
+		// policyChecker, if set, checks objects against the policy webhook before they are persisted.
+		policyChecker *policyChecker
+		// executor captures profiles of function pods.
+		executor *executorClient.Client
+		// auditLog, if set, records the resources created, updated and deleted through the API.
+		auditLog *auditLog
+		// health serves the readiness of the controller.
+		health *health.Checker
+		// prometheusUrl, if set, is the address of the Prometheus server queried for function metrics.
+		prometheusUrl string
 	}
 
 	logDBConfig struct {
@@ -89,6 +111,13 @@ func MakeAPI(logger *zap.Logger, featureStatus map[string]string) (*API, error)
 		api.workflowApiUrl = "http://workflows-apiserver"
 	}
 
+	u = os.Getenv("EXECUTOR_URL")
+	if len(u) > 0 {
+		api.executor = executorClient.MakeClient(logger, u)
+	} else {
+		api.executor = executorClient.MakeClient(logger, "http://executor")
+	}
+
 	fnNs := os.Getenv("FISSION_FUNCTION_NAMESPACE")
 	if len(fnNs) > 0 {
 		api.functionNamespace = fnNs
@@ -96,8 +125,20 @@ func MakeAPI(logger *zap.Logger, featureStatus map[string]string) (*API, error)
 		api.functionNamespace = "fission-function"
 	}
 
+	api.archiveUploadMaxSize, api.archiveUploadToken = getArchiveUploadConfig(logger)
+	api.archiveLiteralSizeLimit = getArchiveLiteralSizeLimit(logger)
+
+	api.policyChecker = makePolicyChecker(logger)
+
+	api.auditLog = makeAuditLog(logger, api.kubernetesClient, podNamespace, api.maxPackageBodySize())
+
 	api.featureStatus = featureStatus
 
+	api.health = health.MakeChecker("controller")
+	api.health.AddCheck("crd", api.fissionClient.CheckCRDs)
+
+	api.prometheusUrl = strings.TrimSuffix(os.Getenv("PROMETHEUS_URL"), "/")
+
 	return api, err
 }
 
@@ -130,6 +171,39 @@ func (api *API) extractQueryParamFromRequest(r *http.Request, queryParam string)
 	return values.Get(queryParam)
 }
 
+// listOptionsFromRequest returns the selectors and pagination of a list
+// request, given by the labelSelector, fieldSelector, limit and continue
+// query parameters.
+func (api *API) listOptionsFromRequest(r *http.Request) (metav1.ListOptions, error) {
+	opts := metav1.ListOptions{
+		LabelSelector: api.extractQueryParamFromRequest(r, "labelSelector"),
+		FieldSelector: api.extractQueryParamFromRequest(r, "fieldSelector"),
+		Continue:      api.extractQueryParamFromRequest(r, "continue"),
+	}
+	if limit := api.extractQueryParamFromRequest(r, "limit"); len(limit) > 0 {
+		l, err := strconv.ParseInt(limit, 10, 64)
+		if err != nil || l < 0 {
+			return opts, ferror.MakeError(ferror.ErrorInvalidArgument, fmt.Sprintf("invalid limit '%v'", limit))
+		}
+		opts.Limit = l
+	}
+	return opts, nil
+}
+
+// respondWithList writes the items of a list page, and the token to get the
+// next page in the continue header.
+func (api *API) respondWithList(w http.ResponseWriter, items interface{}, continueToken string) {
+	resp, err := json.Marshal(items)
+	if err != nil {
+		api.respondWithError(w, err)
+		return
+	}
+	if len(continueToken) > 0 {
+		w.Header().Set(types.ListContinueHeader, continueToken)
+	}
+	api.respondWithSuccess(w, resp)
+}
+
 // check if namespace exists, if not create it.
 func (api *API) createNsIfNotExists(ns string) error {
 	if ns == metav1.NamespaceDefault {
@@ -156,7 +230,9 @@ func (api *API) getLogDBConfig(dbType string) logDBConfig {
 	url := os.Getenv(fmt.Sprintf("%s_URL", dbType))
 	if url == "" {
 		// set up default database url
-		url = logdb.INFLUXDB_URL
+		if backend, ok := logdb.GetBackend(strings.ToLower(dbType)); ok {
+			url = backend.DefaultURL
+		}
 	}
 	username := os.Getenv(fmt.Sprintf("%s_USERNAME", dbType))
 	password := os.Getenv(fmt.Sprintf("%s_PASSWORD", dbType))
@@ -168,8 +244,10 @@ func (api *API) getLogDBConfig(dbType string) logDBConfig {
 }
 
 func (api *API) HomeHandler(w http.ResponseWriter, r *http.Request) {
+	serverInfo := info.ApiInfo()
+	serverInfo.ArchiveLiteralSizeLimit = api.archiveLiteralSizeLimit
 	w.Header().Set("Content-Type", "application/json; charset=utf-8")
-	fmt.Fprintf(w, info.ApiInfo().String())
+	fmt.Fprint(w, serverInfo.String())
 }
 
 func (api *API) ApiVersionMismatchHandler(w http.ResponseWriter, r *http.Request) {
@@ -196,6 +274,7 @@ func (api *API) GetSvcName(w http.ResponseWriter, r *http.Request) {
 func (api *API) Serve(port int) {
 	r := mux.NewRouter()
 	r.HandleFunc("/healthz", api.HealthHandler).Methods("GET")
+	r.HandleFunc("/readyz", api.health.ReadyzHandler).Methods("GET")
 	// Give a useful error message if an older CLI attempts to make a request
 	r.HandleFunc(`/v1/{rest:[a-zA-Z0-9=\-\/]+}`, api.ApiVersionMismatchHandler)
 	r.HandleFunc("/", api.HomeHandler)
@@ -205,12 +284,16 @@ func (api *API) Serve(port int) {
 	r.HandleFunc("/v2/packages/{package}", api.PackageApiGet).Methods("GET")
 	r.HandleFunc("/v2/packages/{package}", api.PackageApiUpdate).Methods("PUT")
 	r.HandleFunc("/v2/packages/{package}", api.PackageApiDelete).Methods("DELETE")
+	r.HandleFunc("/v2/packages/{package}/buildlogs", api.PackageApiBuildLogs).Methods("GET")
 
 	r.HandleFunc("/v2/functions", api.FunctionApiList).Methods("GET")
 	r.HandleFunc("/v2/functions", api.FunctionApiCreate).Methods("POST")
 	r.HandleFunc("/v2/functions/{function}", api.FunctionApiGet).Methods("GET")
 	r.HandleFunc("/v2/functions/{function}", api.FunctionApiUpdate).Methods("PUT")
 	r.HandleFunc("/v2/functions/{function}", api.FunctionApiDelete).Methods("DELETE")
+	r.HandleFunc("/v2/functions/{function}/profile", api.FunctionProfile).Methods("POST")
+	r.HandleFunc("/v2/functions/{function}/metrics", api.FunctionMetrics).Methods("GET")
+	r.HandleFunc("/v2/functions/{function}/stats", api.FunctionStats).Methods("GET")
 
 	r.HandleFunc("/v2/triggers/http", api.HTTPTriggerApiList).Methods("GET")
 	r.HandleFunc("/v2/triggers/http", api.HTTPTriggerApiCreate).Methods("POST")
@@ -252,6 +335,8 @@ func (api *API) Serve(port int) {
 	r.HandleFunc("/v2/records/function/{function}", api.RecordsApiFilterByFunction).Methods("GET")
 	r.HandleFunc("/v2/records/trigger/{trigger}", api.RecordsApiFilterByTrigger).Methods("GET")
 	r.HandleFunc("/v2/records/time", api.RecordsApiFilterByTime).Methods("GET")
+	r.HandleFunc("/v2/records/export", api.RecordsApiExport).Methods("GET")
+	r.HandleFunc("/v2/records/import", api.RecordsApiImport).Methods("POST")
 
 	r.HandleFunc("/v2/replay/{reqUID}", api.ReplayByReqUID).Methods("GET")
 
@@ -264,14 +349,42 @@ func (api *API) Serve(port int) {
 	r.HandleFunc("/v2/canaryconfigs/{canaryConfig}", api.CanaryConfigApiDelete).Methods("DELETE")
 	r.HandleFunc("/v2/canaryconfigs", api.CanaryConfigApiList).Methods("GET")
 
+	r.HandleFunc("/v2/aliases", api.FunctionAliasApiList).Methods("GET")
+	r.HandleFunc("/v2/aliases", api.FunctionAliasApiCreate).Methods("POST")
+	r.HandleFunc("/v2/aliases/{alias}", api.FunctionAliasApiGet).Methods("GET")
+	r.HandleFunc("/v2/aliases/{alias}", api.FunctionAliasApiUpdate).Methods("PUT")
+	r.HandleFunc("/v2/aliases/{alias}", api.FunctionAliasApiDelete).Methods("DELETE")
+
+	r.HandleFunc("/v2/canarypolicies", api.CanaryPolicyApiList).Methods("GET")
+	r.HandleFunc("/v2/canarypolicies", api.CanaryPolicyApiCreate).Methods("POST")
+	r.HandleFunc("/v2/canarypolicies/{canaryPolicy}", api.CanaryPolicyApiGet).Methods("GET")
+	r.HandleFunc("/v2/canarypolicies/{canaryPolicy}", api.CanaryPolicyApiUpdate).Methods("PUT")
+	r.HandleFunc("/v2/canarypolicies/{canaryPolicy}", api.CanaryPolicyApiDelete).Methods("DELETE")
+
+	r.HandleFunc("/v2/archives", api.ArchiveUpload).Methods("POST")
+
+	r.HandleFunc("/v2/audit", api.AuditApiList).Methods("GET")
+	r.HandleFunc("/v2/audit/{event}", api.AuditApiGet).Methods("GET")
+
+	r.HandleFunc("/v2/logdbs", api.LogDBApiList).Methods("GET")
+	r.HandleFunc("/v2/features", api.FeatureApiGet).Methods("GET")
+
 	r.HandleFunc("/proxy/{dbType}", api.FunctionLogsApiPost).Methods("POST")
-	r.HandleFunc("/proxy/storage/v1/archive", api.StorageServiceProxy)
+	r.HandleFunc("/proxy/{dbType}/write", api.FunctionLogsWriteApiPost).Methods("POST")
+	// archives are only downloaded through the proxy, uploads go through
+	// /v2/archives with its size, content type and token checks
+	r.HandleFunc("/proxy/storage/v1/archive", api.StorageServiceProxy).Methods("GET")
 	r.HandleFunc("/proxy/logs/{function}", api.FunctionPodLogs).Methods("POST")
 	r.HandleFunc("/proxy/workflows-apiserver/{path:.*}", api.WorkflowApiserverProxy)
 	r.HandleFunc("/proxy/svcname", api.GetSvcName).Queries("application", "").Methods("GET")
+	// after svcname, the queries of loki are GETs
+	r.HandleFunc("/proxy/{dbType}", api.FunctionLogsApiPost).Methods("GET")
 
 	r.Handle("/v2/apidocs.json", openAPI()).Methods("GET")
 
+	r.Use(api.requestMiddleware)
+	r.Use(api.auditLog.middleware(r))
+
 	address := fmt.Sprintf(":%v", port)
 
 	api.logger.Info("server started", zap.Int("port", port))
@@ -0,0 +1,217 @@
+/*
+Copyright 2019 The Fission Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"mime"
+	"net/http"
+	"os"
+	"strings"
+
+	"github.com/dustin/go-humanize"
+	"github.com/emicklei/go-restful"
+	restfulspec "github.com/emicklei/go-restful-openapi"
+	"github.com/go-openapi/spec"
+	"go.uber.org/zap"
+
+	ferror "github.com/fission/fission/pkg/error"
+	"github.com/fission/fission/pkg/storagesvc"
+	storageSvcClient "github.com/fission/fission/pkg/storagesvc/client"
+	"github.com/fission/fission/pkg/types"
+)
+
+const (
+	// ArchiveUploadFormField is the name of the multipart form field
+	// that holds the uploaded archive.
+	ArchiveUploadFormField = "uploadfile"
+
+	// default max size of an archive uploaded through the controller
+	defaultArchiveUploadMaxSize = 256 * 1024 * 1024
+)
+
+func RegisterArchiveRoute(ws *restful.WebService) {
+	tags := []string{"Archive"}
+	specTag = append(specTag, spec.Tag{TagProps: spec.TagProps{Name: "Archive", Description: "Archive Operation"}})
+
+	ws.Route(
+		ws.POST("/v2/archives").
+			Doc("Upload archive").
+			Metadata(restfulspec.KeyOpenAPITags, tags).
+			To(func(req *restful.Request, resp *restful.Response) {
+				resp.ResponseWriter.WriteHeader(http.StatusOK)
+			}).
+			Consumes("multipart/form-data").
+			Param(ws.FormParameter(ArchiveUploadFormField, "Archive file").DataType("file").Required(true)).
+			Produces(restful.MIME_JSON).
+			Writes(storagesvc.UploadResponse{}).
+			Returns(http.StatusCreated, "ID of uploaded archive", storagesvc.UploadResponse{}))
+}
+
+// getArchiveUploadConfig reads the archive upload size limit and the
+// authentication token from the environment.
+func getArchiveUploadConfig(logger *zap.Logger) (int64, string) {
+	maxSize := int64(defaultArchiveUploadMaxSize)
+	if s := os.Getenv("ARCHIVE_UPLOAD_MAX_SIZE"); len(s) > 0 {
+		size, err := humanize.ParseBytes(s)
+		if err != nil {
+			logger.Error("failed to parse archive upload max size from 'ARCHIVE_UPLOAD_MAX_SIZE' - set to the default value",
+				zap.Error(err),
+				zap.String("value", s),
+				zap.Int64("default", maxSize))
+		} else {
+			maxSize = int64(size)
+		}
+	}
+	token := os.Getenv("ARCHIVE_UPLOAD_TOKEN")
+	if len(token) == 0 {
+		logger.Warn("no archive upload token set in 'ARCHIVE_UPLOAD_TOKEN' - archive uploads are disabled")
+	}
+	return maxSize, token
+}
+
+// getArchiveLiteralSizeLimit reads the max size of package literals from the
+// environment. CRDs are stored in etcd, so the limit shouldn't go much
+// beyond 1MiB.
+func getArchiveLiteralSizeLimit(logger *zap.Logger) int64 {
+	limit := types.ArchiveLiteralSizeLimit
+	if s := os.Getenv("ARCHIVE_LITERAL_SIZE_LIMIT"); len(s) > 0 {
+		size, err := humanize.ParseBytes(s)
+		if err != nil || size == 0 {
+			logger.Error("failed to parse archive literal size limit from 'ARCHIVE_LITERAL_SIZE_LIMIT' - set to the default value",
+				zap.Error(err),
+				zap.String("value", s),
+				zap.Int64("default", limit))
+		} else {
+			limit = int64(size)
+		}
+	}
+	return limit
+}
+
+// checkArchiveUploadAuth verifies the bearer token of the request. Uploads
+// are rejected if the controller wasn't configured with a token.
+func (a *API) checkArchiveUploadAuth(r *http.Request) error {
+	if len(a.archiveUploadToken) == 0 {
+		return ferror.MakeError(ferror.ErrorNotAuthorized, "archive uploads are disabled, no archive upload token is configured")
+	}
+	token := strings.TrimSpace(strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer"))
+	if subtle.ConstantTimeCompare([]byte(token), []byte(a.archiveUploadToken)) != 1 {
+		return ferror.MakeError(ferror.ErrorNotAuthorized, "missing or invalid archive upload token")
+	}
+	return nil
+}
+
+// ArchiveUpload receives a multipart archive upload from the CLI, validates it,
+// and stores it in the storage service. It's the only way to upload archives
+// through the controller. The request is fully consumed by the controller
+// before being forwarded, so ingresses and load balancers only ever see a
+// plain multipart POST.
+func (a *API) ArchiveUpload(w http.ResponseWriter, r *http.Request) {
+	err := a.checkArchiveUploadAuth(r)
+	if err != nil {
+		a.respondWithError(w, err)
+		return
+	}
+
+	mediaType, _, err := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	if err != nil || mediaType != "multipart/form-data" {
+		a.respondWithError(w, ferror.MakeError(ferror.ErrorInvalidArgument,
+			fmt.Sprintf("unsupported content type %q, expected multipart/form-data", r.Header.Get("Content-Type"))))
+		return
+	}
+
+	if r.ContentLength > a.archiveUploadMaxSize {
+		a.respondWithError(w, ferror.MakeError(ferror.ErrorSizeLimitExceeded,
+			fmt.Sprintf("archive size exceeds the limit of %v", humanize.Bytes(uint64(a.archiveUploadMaxSize)))))
+		return
+	}
+	r.Body = http.MaxBytesReader(w, r.Body, a.archiveUploadMaxSize)
+
+	reader, err := r.MultipartReader()
+	if err != nil {
+		a.respondWithError(w, ferror.MakeError(ferror.ErrorInvalidArgument, fmt.Sprintf("error reading multipart request: %v", err)))
+		return
+	}
+
+	// Stream the archive part to a temporary file, the storage service
+	// requires the file size in advance.
+	var tmpFile *os.File
+	for {
+		part, err := reader.NextPart()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			a.respondWithError(w, ferror.MakeError(ferror.ErrorInvalidArgument, fmt.Sprintf("error reading multipart request: %v", err)))
+			return
+		}
+		if part.FormName() != ArchiveUploadFormField {
+			part.Close()
+			continue
+		}
+
+		tmpFile, err = ioutil.TempFile("", "fission-archive-")
+		if err != nil {
+			part.Close()
+			a.respondWithError(w, err)
+			return
+		}
+		defer os.Remove(tmpFile.Name())
+		defer tmpFile.Close()
+
+		_, err = io.Copy(tmpFile, part)
+		part.Close()
+		if err != nil {
+			if strings.Contains(err.Error(), "request body too large") {
+				err = ferror.MakeError(ferror.ErrorSizeLimitExceeded,
+					fmt.Sprintf("archive size exceeds the limit of %v", humanize.Bytes(uint64(a.archiveUploadMaxSize))))
+			}
+			a.respondWithError(w, err)
+			return
+		}
+		break
+	}
+
+	if tmpFile == nil {
+		a.respondWithError(w, ferror.MakeError(ferror.ErrorInvalidArgument,
+			fmt.Sprintf("missing form field %q", ArchiveUploadFormField)))
+		return
+	}
+
+	ssClient := storageSvcClient.MakeClient(a.storageServiceUrl)
+	id, err := ssClient.Upload(r.Context(), tmpFile.Name(), nil)
+	if err != nil {
+		a.logger.Error("error uploading archive to storage service", zap.Error(err))
+		a.respondWithError(w, err)
+		return
+	}
+
+	resp, err := json.Marshal(storagesvc.UploadResponse{ID: id})
+	if err != nil {
+		a.respondWithError(w, err)
+		return
+	}
+	// the header must be set before the status is written
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.WriteHeader(http.StatusCreated)
+	a.respondWithSuccess(w, resp)
+}
@@ -0,0 +1,45 @@
+/*
+Copyright 2019 The Fission Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	tassert "github.com/stretchr/testify/assert"
+)
+
+func TestCheckArchiveUploadAuth(t *testing.T) {
+	request := func(auth string) *http.Request {
+		r := httptest.NewRequest("POST", "/v2/archives", nil)
+		if len(auth) > 0 {
+			r.Header.Set("Authorization", auth)
+		}
+		return r
+	}
+
+	// uploads are rejected if no token is configured
+	api := &API{}
+	tassert.Error(t, api.checkArchiveUploadAuth(request("")))
+	tassert.Error(t, api.checkArchiveUploadAuth(request("Bearer ")))
+
+	api.archiveUploadToken = "s3cr3t"
+	tassert.Error(t, api.checkArchiveUploadAuth(request("")))
+	tassert.Error(t, api.checkArchiveUploadAuth(request("Bearer wrong")))
+	tassert.NoError(t, api.checkArchiveUploadAuth(request("Bearer s3cr3t")))
+}
@@ -0,0 +1,433 @@
+/*
+Copyright 2019 The Fission Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gorilla/mux"
+	"go.uber.org/zap"
+	apiv1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+
+	ferror "github.com/fission/fission/pkg/error"
+	"github.com/fission/fission/pkg/types"
+)
+
+const (
+	auditLabel    = "fission-audit"
+	auditEventKey = "event"
+
+	defaultAuditMaxEvents = 1000
+	auditPruneInterval    = 10 * time.Minute
+
+	// objects larger than this aren't recorded, so that events fit into
+	// a config map
+	auditMaxObjectSize = 256 * 1024
+)
+
+// auditedKinds maps the API paths of resources to their kinds.
+var auditedKinds = map[string]string{
+	"/v2/packages":              "Package",
+	"/v2/functions":             "Function",
+	"/v2/triggers/http":         "HTTPTrigger",
+	"/v2/environments":          "Environment",
+	"/v2/watches":               "KubernetesWatchTrigger",
+	"/v2/triggers/time":         "TimeTrigger",
+	"/v2/triggers/messagequeue": "MessageQueueTrigger",
+	"/v2/recorders":             "Recorder",
+	"/v2/canaryconfigs":         "CanaryConfig",
+	"/v2/aliases":               "FunctionAlias",
+	"/v2/canarypolicies":        "CanaryPolicy",
+}
+
+type (
+	// auditLog records the resources created, updated and deleted through
+	// the API as config maps in the namespace of the controller, so that
+	// "who changed this trigger" can be answered without audit logs of the
+	// cluster. The oldest events beyond maxEvents are pruned.
+	auditLog struct {
+		logger           *zap.Logger
+		kubernetesClient *kubernetes.Clientset
+		namespace        string
+		maxEvents        int
+		// maxBodySize caps the request bodies read for events, packages
+		// are the largest resources
+		maxBodySize int64
+	}
+
+	// statusRecorder keeps the status code written by a handler.
+	statusRecorder struct {
+		http.ResponseWriter
+		status int
+	}
+)
+
+// makeAuditLog returns an audit log configured from the environment, or nil
+// if the audit log isn't enabled.
+func makeAuditLog(logger *zap.Logger, kubernetesClient *kubernetes.Clientset, namespace string, maxBodySize int64) *auditLog {
+	var enabled bool
+	if s := os.Getenv("AUDIT_LOG_ENABLED"); len(s) > 0 {
+		b, err := strconv.ParseBool(s)
+		if err != nil {
+			logger.Error("failed to parse audit log enabled from 'AUDIT_LOG_ENABLED' - set to the default value",
+				zap.Error(err),
+				zap.String("value", s),
+				zap.Bool("default", enabled))
+		} else {
+			enabled = b
+		}
+	}
+	if !enabled {
+		return nil
+	}
+
+	maxEvents := defaultAuditMaxEvents
+	if s := os.Getenv("AUDIT_LOG_MAX_EVENTS"); len(s) > 0 {
+		n, err := strconv.Atoi(s)
+		if err != nil || n <= 0 {
+			logger.Error("failed to parse audit log max events from 'AUDIT_LOG_MAX_EVENTS' - set to the default value",
+				zap.Error(err),
+				zap.String("value", s),
+				zap.Int("default", maxEvents))
+		} else {
+			maxEvents = n
+		}
+	}
+
+	logger.Info("audit log enabled",
+		zap.String("namespace", namespace),
+		zap.Int("max_events", maxEvents))
+
+	al := &auditLog{
+		logger:           logger.Named("audit_log"),
+		kubernetesClient: kubernetesClient,
+		namespace:        namespace,
+		maxEvents:        maxEvents,
+		maxBodySize:      maxBodySize,
+	}
+	go al.pruneLoop()
+	return al
+}
+
+func (sr *statusRecorder) WriteHeader(status int) {
+	sr.status = status
+	sr.ResponseWriter.WriteHeader(status)
+}
+
+// Flush lets streaming handlers flush through the recorder.
+func (sr *statusRecorder) Flush() {
+	if flusher, ok := sr.ResponseWriter.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
+
+// auditedOperation returns the kind of the resource and the operation of an
+// API request, or empty strings if the request isn't audited.
+func auditedOperation(r *http.Request) (string, string) {
+	route := mux.CurrentRoute(r)
+	if route == nil {
+		return "", ""
+	}
+	tmpl, err := route.GetPathTemplate()
+	if err != nil {
+		return "", ""
+	}
+
+	var operation string
+	switch r.Method {
+	case http.MethodPost:
+		operation = types.AuditOperationCreate
+	case http.MethodPut:
+		operation = types.AuditOperationUpdate
+		tmpl = tmpl[:strings.LastIndex(tmpl, "/")]
+	case http.MethodDelete:
+		operation = types.AuditOperationDelete
+		tmpl = tmpl[:strings.LastIndex(tmpl, "/")]
+	default:
+		return "", ""
+	}
+
+	kind, ok := auditedKinds[tmpl]
+	if !ok {
+		return "", ""
+	}
+	return kind, operation
+}
+
+// middleware records the successful mutations of resources served by the
+// handlers. The resource before an update or delete is read from the GET
+// handler of the router.
+func (al *auditLog) middleware(router http.Handler) mux.MiddlewareFunc {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			kind, operation := auditedOperation(r)
+			if al == nil || len(kind) == 0 {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			var body []byte
+			if r.Body != nil {
+				if r.ContentLength > al.maxBodySize {
+					http.Error(w, "Request body too large", http.StatusRequestEntityTooLarge)
+					return
+				}
+				var err error
+				body, err = ioutil.ReadAll(http.MaxBytesReader(w, r.Body, al.maxBodySize))
+				if err != nil {
+					if strings.Contains(err.Error(), "request body too large") {
+						http.Error(w, "Request body too large", http.StatusRequestEntityTooLarge)
+					} else {
+						http.Error(w, "Failed to read request", http.StatusInternalServerError)
+					}
+					return
+				}
+				r.Body = ioutil.NopCloser(bytes.NewReader(body))
+			}
+
+			// the controller doesn't authenticate clients, the user is
+			// the one the client claims to be
+			event := types.AuditEvent{
+				ClientUser: r.Header.Get(types.AuditUserHeader),
+				RemoteAddr: r.RemoteAddr,
+				Operation:  operation,
+				Kind:       kind,
+			}
+			if len(event.ClientUser) == 0 {
+				event.ClientUser = "unknown"
+			}
+
+			if operation == types.AuditOperationDelete {
+				for _, name := range mux.Vars(r) {
+					event.Name = name
+				}
+				event.Namespace = r.URL.Query().Get("namespace")
+				if len(event.Namespace) == 0 {
+					event.Namespace = metav1.NamespaceDefault
+				}
+			} else {
+				var obj struct {
+					Metadata metav1.ObjectMeta `json:"metadata"`
+				}
+				// a malformed body is rejected by the handler
+				json.Unmarshal(body, &obj)
+				event.Name = obj.Metadata.Name
+				event.Namespace = obj.Metadata.Namespace
+				event.NewObject = body
+			}
+
+			if operation != types.AuditOperationCreate {
+				event.OldObject = al.getObject(router, r, event.Namespace)
+			}
+
+			sr := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+			next.ServeHTTP(sr, r)
+			if sr.status >= http.StatusBadRequest {
+				return
+			}
+
+			event.Time = time.Now().UTC()
+			al.record(&event)
+		})
+	}
+}
+
+// getObject gets the resource of the request from the router, nil if the
+// resource doesn't exist.
+func (al *auditLog) getObject(router http.Handler, r *http.Request, namespace string) json.RawMessage {
+	u := fmt.Sprintf("%v?namespace=%v", r.URL.Path, url.QueryEscape(namespace))
+	req, err := http.NewRequest(http.MethodGet, u, nil)
+	if err != nil {
+		return nil
+	}
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+	if rec.Code != http.StatusOK {
+		return nil
+	}
+	return rec.Body.Bytes()
+}
+
+// record stores the event. Failing to record an event doesn't fail the
+// request, since the change has been made already.
+func (al *auditLog) record(event *types.AuditEvent) {
+	if len(event.OldObject)+len(event.NewObject) > auditMaxObjectSize {
+		event.OldObject = nil
+		event.NewObject = nil
+		event.Truncated = true
+	}
+
+	data, err := json.Marshal(event)
+	if err != nil {
+		al.logger.Error("error encoding audit event", zap.Error(err))
+		return
+	}
+
+	cm := &apiv1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			GenerateName: "fission-audit-",
+			Labels: map[string]string{
+				auditLabel: "true",
+			},
+		},
+		Data: map[string]string{
+			auditEventKey: string(data),
+		},
+	}
+	_, err = al.kubernetesClient.CoreV1().ConfigMaps(al.namespace).Create(cm)
+	if err != nil {
+		al.logger.Error("error recording audit event",
+			zap.Error(err),
+			zap.String("operation", event.Operation),
+			zap.String("kind", event.Kind),
+			zap.String("name", event.Name),
+			zap.String("namespace", event.Namespace),
+			zap.String("client_user", event.ClientUser))
+	}
+}
+
+// list returns the recorded events, oldest first.
+func (al *auditLog) list() ([]types.AuditEvent, error) {
+	cms, err := al.kubernetesClient.CoreV1().ConfigMaps(al.namespace).List(metav1.ListOptions{
+		LabelSelector: auditLabel + "=true",
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	events := make([]types.AuditEvent, 0, len(cms.Items))
+	for _, cm := range cms.Items {
+		event, err := decodeAuditEvent(&cm)
+		if err != nil {
+			al.logger.Error("error decoding audit event", zap.Error(err), zap.String("config_map", cm.Name))
+			continue
+		}
+		events = append(events, *event)
+	}
+	sort.SliceStable(events, func(i, j int) bool {
+		return events[i].Time.Before(events[j].Time)
+	})
+	return events, nil
+}
+
+func decodeAuditEvent(cm *apiv1.ConfigMap) (*types.AuditEvent, error) {
+	var event types.AuditEvent
+	err := json.Unmarshal([]byte(cm.Data[auditEventKey]), &event)
+	if err != nil {
+		return nil, err
+	}
+	event.ID = cm.Name
+	return &event, nil
+}
+
+// pruneLoop deletes the oldest events beyond the max number of events.
+func (al *auditLog) pruneLoop() {
+	for {
+		events, err := al.list()
+		if err != nil {
+			al.logger.Error("error listing audit events", zap.Error(err))
+		} else if len(events) > al.maxEvents {
+			for _, event := range events[:len(events)-al.maxEvents] {
+				err := al.kubernetesClient.CoreV1().ConfigMaps(al.namespace).Delete(event.ID, &metav1.DeleteOptions{})
+				if err != nil {
+					al.logger.Error("error deleting audit event", zap.Error(err), zap.String("id", event.ID))
+				}
+			}
+		}
+		time.Sleep(auditPruneInterval)
+	}
+}
+
+// AuditApiList lists the audit events, optionally filtered by the kind,
+// namespace and name of the resource.
+func (a *API) AuditApiList(w http.ResponseWriter, r *http.Request) {
+	if a.auditLog == nil {
+		a.respondWithError(w, ferror.MakeError(ferror.ErrorNotFound, "audit log is not enabled"))
+		return
+	}
+
+	events, err := a.auditLog.list()
+	if err != nil {
+		a.respondWithError(w, err)
+		return
+	}
+
+	kind := a.extractQueryParamFromRequest(r, "kind")
+	ns := a.extractQueryParamFromRequest(r, "namespace")
+	name := a.extractQueryParamFromRequest(r, "name")
+	filtered := make([]types.AuditEvent, 0, len(events))
+	for _, event := range events {
+		if (len(kind) > 0 && !strings.EqualFold(kind, event.Kind)) ||
+			(len(ns) > 0 && ns != event.Namespace) ||
+			(len(name) > 0 && name != event.Name) {
+			continue
+		}
+		filtered = append(filtered, event)
+	}
+
+	resp, err := json.Marshal(filtered)
+	if err != nil {
+		a.respondWithError(w, err)
+		return
+	}
+	a.respondWithSuccess(w, resp)
+}
+
+func (a *API) AuditApiGet(w http.ResponseWriter, r *http.Request) {
+	if a.auditLog == nil {
+		a.respondWithError(w, ferror.MakeError(ferror.ErrorNotFound, "audit log is not enabled"))
+		return
+	}
+
+	id := mux.Vars(r)["event"]
+	cm, err := a.kubernetesClient.CoreV1().ConfigMaps(a.auditLog.namespace).Get(id, metav1.GetOptions{})
+	if err != nil {
+		a.respondWithError(w, err)
+		return
+	}
+	// don't expose other config maps of the namespace
+	if cm.Labels[auditLabel] != "true" {
+		a.respondWithError(w, ferror.MakeError(ferror.ErrorNotFound, fmt.Sprintf("audit event %v not found", id)))
+		return
+	}
+
+	event, err := decodeAuditEvent(cm)
+	if err != nil {
+		a.respondWithError(w, err)
+		return
+	}
+
+	resp, err := json.Marshal(event)
+	if err != nil {
+		a.respondWithError(w, err)
+		return
+	}
+	a.respondWithSuccess(w, resp)
+}
@@ -0,0 +1,85 @@
+/*
+Copyright 2019 The Fission Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gorilla/mux"
+	tassert "github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+
+	"github.com/fission/fission/pkg/types"
+)
+
+func TestAuditedOperation(t *testing.T) {
+	var kind, operation string
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		kind, operation = auditedOperation(r)
+	}
+	r := mux.NewRouter()
+	r.HandleFunc("/v2/triggers/http", handler).Methods("GET", "POST")
+	r.HandleFunc("/v2/triggers/http/{httpTrigger}", handler).Methods("GET", "PUT", "DELETE")
+	r.HandleFunc("/v2/functions/{function}/profile", handler).Methods("POST")
+
+	tests := []struct {
+		method    string
+		path      string
+		kind      string
+		operation string
+	}{
+		{"POST", "/v2/triggers/http", "HTTPTrigger", types.AuditOperationCreate},
+		{"PUT", "/v2/triggers/http/foo", "HTTPTrigger", types.AuditOperationUpdate},
+		{"DELETE", "/v2/triggers/http/foo?namespace=bar", "HTTPTrigger", types.AuditOperationDelete},
+		{"GET", "/v2/triggers/http", "", ""},
+		{"GET", "/v2/triggers/http/foo", "", ""},
+		// not a mutation of a resource
+		{"POST", "/v2/functions/foo/profile", "", ""},
+	}
+	for _, test := range tests {
+		kind, operation = "x", "x"
+		r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(test.method, test.path, nil))
+		tassert.Equal(t, test.kind, kind, "%v %v", test.method, test.path)
+		tassert.Equal(t, test.operation, operation, "%v %v", test.method, test.path)
+	}
+}
+
+func TestAuditMiddlewareBodySize(t *testing.T) {
+	called := false
+	r := mux.NewRouter()
+	r.HandleFunc("/v2/triggers/http", func(w http.ResponseWriter, r *http.Request) {
+		called = true
+	}).Methods("POST")
+	al := &auditLog{logger: zap.NewNop(), maxBodySize: 16}
+	r.Use(al.middleware(r))
+
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, httptest.NewRequest("POST", "/v2/triggers/http", strings.NewReader(strings.Repeat("x", 17))))
+	tassert.Equal(t, http.StatusRequestEntityTooLarge, rec.Code)
+	tassert.False(t, called)
+
+	// the size isn't always known upfront
+	req := httptest.NewRequest("POST", "/v2/triggers/http", strings.NewReader(strings.Repeat("x", 17)))
+	req.ContentLength = -1
+	rec = httptest.NewRecorder()
+	r.ServeHTTP(rec, req)
+	tassert.Equal(t, http.StatusRequestEntityTooLarge, rec.Code)
+	tassert.False(t, called)
+}
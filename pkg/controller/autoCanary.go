@@ -0,0 +1,389 @@
+/*
+Copyright 2019 The Fission Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"reflect"
+
+	"go.uber.org/zap"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	fv1 "github.com/fission/fission/pkg/apis/fission.io/v1"
+	config "github.com/fission/fission/pkg/featureconfig"
+	"github.com/fission/fission/pkg/types"
+)
+
+const (
+	// canarySnapshotOfLabel labels the snapshots of functions and packages
+	// with the name of the resource they were taken of.
+	canarySnapshotOfLabel = "fission.io/canary-snapshot-of"
+	// canarySnapshotAnnotation annotates an updated package with the name of
+	// the snapshot of its previous version.
+	canarySnapshotAnnotation = "fission.io/canary-snapshot"
+)
+
+type (
+	// autoCanary is a canary deployment started for a function update by
+	// the canary policy of the namespace. The triggers routing to the
+	// function are switched to the stable version of the function before
+	// the function is updated.
+	autoCanary struct {
+		policy *fv1.CanaryPolicy
+		// triggers are the switched triggers, refs their function references
+		// before the switch and stable the function each of them routes to.
+		triggers []fv1.HTTPTrigger
+		refs     []fv1.FunctionReference
+		stable   []string
+		// obsolete are the snapshots of earlier updates no trigger routes to
+		// anymore.
+		obsolete []string
+	}
+)
+
+// canaryPolicy returns the canary policy of the namespace, or nil if it has
+// none or the canary feature isn't running, as nothing would then shift the
+// traffic of the switched triggers to the updated functions.
+func (a *API) canaryPolicy(ns string) *fv1.CanaryPolicy {
+	policies, err := a.fissionClient.CanaryPolicies(ns).List(metav1.ListOptions{})
+	if err != nil {
+		a.logger.Error("error listing canary policies", zap.Error(err), zap.String("namespace", ns))
+		return nil
+	}
+	if len(policies.Items) == 0 {
+		return nil
+	}
+
+	featureConfig, err := config.GetFeatureConfig()
+	if err != nil || !featureConfig.CanaryConfig.IsEnabled || len(a.featureStatus[config.CanaryFeature]) > 0 {
+		a.logger.Info("canary feature isn't running, ignoring canary policy",
+			zap.String("canary_policy", policies.Items[0].Metadata.Name), zap.String("namespace", ns))
+		return nil
+	}
+	return &policies.Items[0]
+}
+
+// snapshotName returns the name of the snapshot of a resource at a
+// resource version.
+func snapshotName(name string, resourceVersion string) string {
+	suffix := "-" + resourceVersion
+	if len(name)+len(suffix) > 63 {
+		name = name[:63-len(suffix)]
+	}
+	return name + suffix
+}
+
+// stableFunction returns the function serving the traffic of a trigger
+// routing to fn, and false if the trigger doesn't route to fn or a canary
+// of fn is in progress on it. After a rollback that's the snapshot the
+// traffic was rolled back to.
+func (a *API) stableFunction(t *fv1.HTTPTrigger, fn string) (string, bool) {
+	ref := t.Spec.FunctionReference
+	switch ref.Type {
+	case fv1.FunctionReferenceTypeFunctionName:
+		return fn, ref.Name == fn
+	case fv1.FunctionReferenceTypeFunctionWeights:
+		weight, ok := ref.FunctionWeights[fn]
+		if !ok {
+			return "", false
+		}
+		if weight == 100 {
+			return fn, true
+		}
+		if weight > 0 || len(ref.FunctionWeights) != 2 {
+			return "", false
+		}
+		for name := range ref.FunctionWeights {
+			if name == fn {
+				continue
+			}
+			snapshot, err := a.fissionClient.Functions(t.Metadata.Namespace).Get(name)
+			if err != nil || snapshot.Metadata.Labels[canarySnapshotOfLabel] != fn {
+				return "", false
+			}
+			return name, true
+		}
+	}
+	return "", false
+}
+
+// snapshotPackage keeps a copy of a package about to be updated if a
+// function using it is subject to the canary policy of the namespace, so
+// that the snapshot of the function can keep running the previous code.
+// The updated package is annotated with the name of the copy.
+func (a *API) snapshotPackage(pkg *fv1.Package) {
+	delete(pkg.Metadata.Annotations, canarySnapshotAnnotation)
+
+	ns := pkg.Metadata.Namespace
+	if a.canaryPolicy(ns) == nil {
+		return
+	}
+
+	old, err := a.fissionClient.Packages(ns).Get(pkg.Metadata.Name)
+	if err != nil || reflect.DeepEqual(old.Spec, pkg.Spec) {
+		return
+	}
+
+	fns, err := a.fissionClient.Functions(ns).List(metav1.ListOptions{})
+	if err != nil {
+		a.logger.Error("error listing functions", zap.Error(err), zap.String("namespace", ns))
+		return
+	}
+	triggers, err := a.fissionClient.HTTPTriggers(ns).List(metav1.ListOptions{})
+	if err != nil {
+		a.logger.Error("error listing http triggers", zap.Error(err), zap.String("namespace", ns))
+		return
+	}
+	used := false
+	for _, fn := range fns.Items {
+		if fn.Spec.Package.PackageRef.Name != pkg.Metadata.Name {
+			continue
+		}
+		for i := range triggers.Items {
+			if _, ok := a.stableFunction(&triggers.Items[i], fn.Metadata.Name); ok {
+				used = true
+			}
+		}
+	}
+	if !used {
+		return
+	}
+
+	snapshot := &fv1.Package{
+		Metadata: metav1.ObjectMeta{
+			Name:      snapshotName(old.Metadata.Name, old.Metadata.ResourceVersion),
+			Namespace: ns,
+			Labels:    map[string]string{canarySnapshotOfLabel: old.Metadata.Name},
+		},
+		Spec:   old.Spec,
+		Status: old.Status,
+	}
+	_, err = a.fissionClient.Packages(ns).Create(snapshot)
+	if err != nil && !kerrors.IsAlreadyExists(err) {
+		a.logger.Error("error creating package snapshot", zap.Error(err),
+			zap.String("package", old.Metadata.Name), zap.String("namespace", ns))
+		return
+	}
+
+	if pkg.Metadata.Annotations == nil {
+		pkg.Metadata.Annotations = make(map[string]string)
+	}
+	pkg.Metadata.Annotations[canarySnapshotAnnotation] = snapshot.Metadata.Name
+}
+
+// startAutoCanary switches the triggers routing to a function about to be
+// updated to the stable version of the function, a snapshot of the current
+// version unless a rollback already routes the traffic to one. It returns
+// nil if the function isn't subject to the canary policy of its namespace.
+func (a *API) startAutoCanary(f *fv1.Function) *autoCanary {
+	ns := f.Metadata.Namespace
+	if len(f.Metadata.Labels[canarySnapshotOfLabel]) > 0 {
+		return nil
+	}
+	policy := a.canaryPolicy(ns)
+	if policy == nil {
+		return nil
+	}
+
+	old, err := a.fissionClient.Functions(ns).Get(f.Metadata.Name)
+	if err != nil || reflect.DeepEqual(old.Spec, f.Spec) {
+		return nil
+	}
+
+	triggers, err := a.fissionClient.HTTPTriggers(ns).List(metav1.ListOptions{})
+	if err != nil {
+		a.logger.Error("error listing http triggers", zap.Error(err), zap.String("namespace", ns))
+		return nil
+	}
+
+	ac := &autoCanary{policy: policy}
+	var snapshot *fv1.Function
+	for _, t := range triggers.Items {
+		stable, ok := a.stableFunction(&t, f.Metadata.Name)
+		if !ok {
+			continue
+		}
+		if stable == f.Metadata.Name {
+			if snapshot == nil {
+				snapshot = a.snapshotFunction(old, f)
+				if snapshot == nil {
+					continue
+				}
+			}
+			stable = snapshot.Metadata.Name
+		}
+
+		// drop the snapshots of earlier updates from the weights
+		for name := range t.Spec.FunctionReference.FunctionWeights {
+			if name != f.Metadata.Name && name != stable {
+				ac.obsolete = append(ac.obsolete, name)
+			}
+		}
+
+		ref := t.Spec.FunctionReference
+		t.Spec.FunctionReference = fv1.FunctionReference{
+			Type:            fv1.FunctionReferenceTypeFunctionWeights,
+			FunctionWeights: map[string]int{f.Metadata.Name: 0, stable: 100},
+		}
+		tnew, err := a.fissionClient.HTTPTriggers(ns).Update(&t)
+		if err != nil {
+			a.logger.Error("error switching http trigger to the stable version of the function", zap.Error(err),
+				zap.String("trigger", t.Metadata.Name), zap.String("namespace", ns))
+			continue
+		}
+		ac.triggers = append(ac.triggers, *tnew)
+		ac.refs = append(ac.refs, ref)
+		ac.stable = append(ac.stable, stable)
+	}
+	if len(ac.triggers) == 0 {
+		return nil
+	}
+	return ac
+}
+
+// snapshotFunction creates a copy of a function before an update, running
+// the snapshot of its package if the update comes with new code in the same
+// package. It returns nil if the previous code wasn't kept.
+func (a *API) snapshotFunction(old *fv1.Function, f *fv1.Function) *fv1.Function {
+	ns := old.Metadata.Namespace
+	spec := old.Spec
+	oldPkg, newPkg := old.Spec.Package.PackageRef, f.Spec.Package.PackageRef
+	if oldPkg.Name == newPkg.Name && oldPkg.ResourceVersion != newPkg.ResourceVersion {
+		pkg, err := a.fissionClient.Packages(oldPkg.Namespace).Get(oldPkg.Name)
+		if err != nil {
+			return nil
+		}
+		name, ok := pkg.Metadata.Annotations[canarySnapshotAnnotation]
+		if !ok {
+			a.logger.Info("previous version of package wasn't kept, skipping canary",
+				zap.String("function", old.Metadata.Name), zap.String("package", oldPkg.Name), zap.String("namespace", ns))
+			return nil
+		}
+		pkgSnapshot, err := a.fissionClient.Packages(oldPkg.Namespace).Get(name)
+		if err != nil {
+			return nil
+		}
+		spec.Package.PackageRef.Name = pkgSnapshot.Metadata.Name
+		spec.Package.PackageRef.ResourceVersion = pkgSnapshot.Metadata.ResourceVersion
+	}
+
+	snapshot := &fv1.Function{
+		Metadata: metav1.ObjectMeta{
+			Name:      snapshotName(old.Metadata.Name, old.Metadata.ResourceVersion),
+			Namespace: ns,
+			Labels:    map[string]string{canarySnapshotOfLabel: old.Metadata.Name},
+		},
+		Spec: spec,
+	}
+	created, err := a.fissionClient.Functions(ns).Create(snapshot)
+	if kerrors.IsAlreadyExists(err) {
+		created, err = a.fissionClient.Functions(ns).Get(snapshot.Metadata.Name)
+	}
+	if err != nil {
+		a.logger.Error("error creating function snapshot", zap.Error(err),
+			zap.String("function", old.Metadata.Name), zap.String("namespace", ns))
+		return nil
+	}
+	return created
+}
+
+// abortAutoCanary restores the function references of the switched
+// triggers after the function update failed.
+func (a *API) abortAutoCanary(ac *autoCanary) {
+	for i, t := range ac.triggers {
+		t.Spec.FunctionReference = ac.refs[i]
+		_, err := a.fissionClient.HTTPTriggers(t.Metadata.Namespace).Update(&t)
+		if err != nil {
+			a.logger.Error("error restoring http trigger", zap.Error(err),
+				zap.String("trigger", t.Metadata.Name), zap.String("namespace", t.Metadata.Namespace))
+		}
+	}
+}
+
+// finishAutoCanary creates the canary configs shifting the traffic of the
+// switched triggers to the updated function, and deletes the obsolete
+// snapshots.
+func (a *API) finishAutoCanary(ac *autoCanary, f *fv1.Function) {
+	ns := f.Metadata.Namespace
+	for i, t := range ac.triggers {
+		canaryCfg := &fv1.CanaryConfig{
+			Metadata: metav1.ObjectMeta{
+				Name:      snapshotName(t.Metadata.Name, f.Metadata.ResourceVersion),
+				Namespace: ns,
+			},
+			Spec: fv1.CanaryConfigSpec{
+				Trigger:                 t.Metadata.Name,
+				NewFunction:             f.Metadata.Name,
+				OldFunction:             ac.stable[i],
+				WeightIncrement:         ac.policy.Spec.WeightIncrement,
+				WeightIncrementDuration: ac.policy.Spec.WeightIncrementDuration,
+				FailureThreshold:        ac.policy.Spec.FailureThreshold,
+				FailureType:             ac.policy.Spec.FailureType,
+				LatencyThreshold:        ac.policy.Spec.LatencyThreshold,
+			},
+			Status: fv1.CanaryConfigStatus{
+				Status: types.CanaryConfigStatusPending,
+			},
+		}
+		_, err := a.fissionClient.CanaryConfigs(ns).Create(canaryCfg)
+		if err != nil {
+			a.logger.Error("error creating canary config", zap.Error(err),
+				zap.String("trigger", t.Metadata.Name), zap.String("namespace", ns))
+			continue
+		}
+		a.logger.Info("started canary of updated function",
+			zap.String("canary_config", canaryCfg.Metadata.Name),
+			zap.String("function", f.Metadata.Name),
+			zap.String("namespace", ns))
+	}
+
+	for _, name := range ac.obsolete {
+		snapshot, err := a.fissionClient.Functions(ns).Get(name)
+		if err != nil || snapshot.Metadata.Labels[canarySnapshotOfLabel] != f.Metadata.Name {
+			continue
+		}
+		err = a.fissionClient.Functions(ns).Delete(name, &metav1.DeleteOptions{})
+		if err != nil {
+			a.logger.Error("error deleting function snapshot", zap.Error(err),
+				zap.String("function", name), zap.String("namespace", ns))
+			continue
+		}
+		a.deletePackageSnapshot(&snapshot.Spec.Package.PackageRef)
+	}
+}
+
+// deletePackageSnapshot deletes a package snapshot no function uses anymore.
+func (a *API) deletePackageSnapshot(ref *fv1.PackageRef) {
+	pkg, err := a.fissionClient.Packages(ref.Namespace).Get(ref.Name)
+	if err != nil || len(pkg.Metadata.Labels[canarySnapshotOfLabel]) == 0 {
+		return
+	}
+	fns, err := a.fissionClient.Functions(ref.Namespace).List(metav1.ListOptions{})
+	if err != nil {
+		return
+	}
+	for _, fn := range fns.Items {
+		if fn.Spec.Package.PackageRef.Name == ref.Name {
+			return
+		}
+	}
+	err = a.fissionClient.Packages(ref.Namespace).Delete(ref.Name, &metav1.DeleteOptions{})
+	if err != nil {
+		a.logger.Error("error deleting package snapshot", zap.Error(err),
+			zap.String("package", ref.Name), zap.String("namespace", ref.Namespace))
+	}
+}
@@ -0,0 +1,33 @@
+/*
+Copyright 2019 The Fission Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"strings"
+	"testing"
+
+	tassert "github.com/stretchr/testify/assert"
+)
+
+func TestSnapshotName(t *testing.T) {
+	tassert.Equal(t, "hello-1234", snapshotName("hello", "1234"))
+
+	long := strings.Repeat("a", 63)
+	name := snapshotName(long, "1234")
+	tassert.Equal(t, 63, len(name))
+	tassert.True(t, strings.HasSuffix(name, "a-1234"))
+}
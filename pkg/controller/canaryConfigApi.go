@@ -180,19 +180,19 @@ func (a *API) CanaryConfigApiList(w http.ResponseWriter, r *http.Request) {
 		ns = metav1.NamespaceDefault
 	}
 
-	canaryCfgs, err := a.fissionClient.CanaryConfigs(ns).List(metav1.ListOptions{})
+	opts, err := a.listOptionsFromRequest(r)
 	if err != nil {
 		a.respondWithError(w, err)
 		return
 	}
 
-	resp, err := json.Marshal(canaryCfgs.Items)
+	canaryCfgs, err := a.fissionClient.CanaryConfigs(ns).List(opts)
 	if err != nil {
 		a.respondWithError(w, err)
 		return
 	}
 
-	a.respondWithSuccess(w, resp)
+	a.respondWithList(w, canaryCfgs.Items, canaryCfgs.Metadata.Continue)
 }
 
 func (a *API) CanaryConfigApiUpdate(w http.ResponseWriter, r *http.Request) {
@@ -0,0 +1,269 @@
+/*
+Copyright 2019 The Fission Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+
+	"github.com/emicklei/go-restful"
+	restfulspec "github.com/emicklei/go-restful-openapi"
+	"github.com/go-openapi/spec"
+	"github.com/gorilla/mux"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	fv1 "github.com/fission/fission/pkg/apis/fission.io/v1"
+	ferror "github.com/fission/fission/pkg/error"
+	config "github.com/fission/fission/pkg/featureconfig"
+)
+
+func RegisterCanaryPolicyRoute(ws *restful.WebService) {
+	tags := []string{"CanaryPolicy"}
+	specTag = append(specTag, spec.Tag{TagProps: spec.TagProps{Name: "CanaryPolicy", Description: "CanaryPolicy Operation"}})
+
+	ws.Route(
+		ws.GET("/v2/canarypolicies").
+			Doc("List all canary policies").
+			Metadata(restfulspec.KeyOpenAPITags, tags).
+			To(func(req *restful.Request, resp *restful.Response) {
+				resp.ResponseWriter.WriteHeader(http.StatusOK)
+			}).
+			Param(ws.QueryParameter("namespace", "Namespace of canaryPolicy").DataType("string").DefaultValue(metav1.NamespaceAll).Required(false)).
+			Produces(restful.MIME_JSON).
+			Writes([]fv1.CanaryPolicy{}).
+			Returns(http.StatusOK, "List of canaryPolicies", []fv1.CanaryPolicy{}))
+
+	ws.Route(
+		ws.POST("/v2/canarypolicies").
+			Doc("Create canary policy").
+			Metadata(restfulspec.KeyOpenAPITags, tags).
+			To(func(req *restful.Request, resp *restful.Response) {
+				resp.ResponseWriter.WriteHeader(http.StatusOK)
+			}).
+			Produces(restful.MIME_JSON).
+			Reads(fv1.CanaryPolicy{}).
+			Writes(metav1.ObjectMeta{}).
+			Returns(http.StatusCreated, "Metadata of created canaryPolicy", metav1.ObjectMeta{}))
+
+	ws.Route(
+		ws.GET("/v2/canarypolicies/{canaryPolicy}").
+			Doc("Get detail of canary policy").
+			Metadata(restfulspec.KeyOpenAPITags, tags).
+			To(func(req *restful.Request, resp *restful.Response) {
+				resp.ResponseWriter.WriteHeader(http.StatusOK)
+			}).
+			Param(ws.PathParameter("canaryPolicy", "CanaryPolicy name").DataType("string").DefaultValue("").Required(true)).
+			Param(ws.QueryParameter("namespace", "Namespace of canaryPolicy").DataType("string").DefaultValue(metav1.NamespaceAll).Required(false)).
+			Produces(restful.MIME_JSON).
+			Writes(fv1.CanaryPolicy{}). // on the response
+			Returns(http.StatusOK, "A canaryPolicy", fv1.CanaryPolicy{}))
+
+	ws.Route(
+		ws.PUT("/v2/canarypolicies/{canaryPolicy}").
+			Doc("Update canary policy").
+			Metadata(restfulspec.KeyOpenAPITags, tags).
+			To(func(req *restful.Request, resp *restful.Response) {
+				resp.ResponseWriter.WriteHeader(http.StatusOK)
+			}).
+			Param(ws.PathParameter("canaryPolicy", "CanaryPolicy name").DataType("string").DefaultValue("").Required(true)).
+			Produces(restful.MIME_JSON).
+			Reads(fv1.CanaryPolicy{}).
+			Writes(metav1.ObjectMeta{}). // on the response
+			Returns(http.StatusOK, "Metadata of updated canaryPolicy", metav1.ObjectMeta{}))
+
+	ws.Route(
+		ws.DELETE("/v2/canarypolicies/{canaryPolicy}").
+			Doc("Delete canary policy").
+			Metadata(restfulspec.KeyOpenAPITags, tags).
+			To(func(req *restful.Request, resp *restful.Response) {
+				resp.ResponseWriter.WriteHeader(http.StatusOK)
+			}).
+			Param(ws.PathParameter("canaryPolicy", "CanaryPolicy name").DataType("string").DefaultValue("").Required(true)).
+			Param(ws.QueryParameter("namespace", "Namespace of canaryPolicy").DataType("string").DefaultValue(metav1.NamespaceAll).Required(false)).
+			Produces(restful.MIME_JSON).
+			Returns(http.StatusOK, "Only HTTP status returned", nil))
+}
+
+func (a *API) CanaryPolicyApiList(w http.ResponseWriter, r *http.Request) {
+	ns := a.extractQueryParamFromRequest(r, "namespace")
+	if len(ns) == 0 {
+		ns = metav1.NamespaceAll
+	}
+
+	opts, err := a.listOptionsFromRequest(r)
+	if err != nil {
+		a.respondWithError(w, err)
+		return
+	}
+
+	policies, err := a.fissionClient.CanaryPolicies(ns).List(opts)
+	if err != nil {
+		a.respondWithError(w, err)
+		return
+	}
+
+	a.respondWithList(w, policies.Items, policies.Metadata.Continue)
+}
+
+func (a *API) CanaryPolicyApiCreate(w http.ResponseWriter, r *http.Request) {
+	featureErr := a.featureStatus[config.CanaryFeature]
+	if len(featureErr) > 0 {
+		a.respondWithError(w, ferror.MakeError(http.StatusInternalServerError, fmt.Sprintf("Error enabling canary feature: %v", featureErr)))
+		return
+	}
+
+	body, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		a.respondWithError(w, err)
+		return
+	}
+
+	var policy fv1.CanaryPolicy
+	err = json.Unmarshal(body, &policy)
+	if err != nil {
+		a.respondWithError(w, err)
+		return
+	}
+
+	err = a.policyChecker.check(r.Context(), policyOperationCreate, "CanaryPolicy", &policy.Metadata, &policy)
+	if err != nil {
+		a.respondWithError(w, err)
+		return
+	}
+
+	// check if namespace exists, if not create it.
+	err = a.createNsIfNotExists(policy.Metadata.Namespace)
+	if err != nil {
+		a.respondWithError(w, err)
+		return
+	}
+
+	// a namespace has at most one policy, so the parameters of the canary
+	// configs created for updated functions are unambiguous.
+	policies, err := a.fissionClient.CanaryPolicies(policy.Metadata.Namespace).List(metav1.ListOptions{})
+	if err != nil {
+		a.respondWithError(w, err)
+		return
+	}
+	if len(policies.Items) > 0 {
+		err = ferror.MakeError(ferror.ErrorNameExists,
+			fmt.Sprintf("namespace '%v' already has canary policy '%v'", policy.Metadata.Namespace, policies.Items[0].Metadata.Name))
+		a.respondWithError(w, err)
+		return
+	}
+
+	policyNew, err := a.fissionClient.CanaryPolicies(policy.Metadata.Namespace).Create(&policy)
+	if err != nil {
+		a.respondWithError(w, err)
+		return
+	}
+
+	resp, err := json.Marshal(policyNew.Metadata)
+	if err != nil {
+		a.respondWithError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusCreated)
+	a.respondWithSuccess(w, resp)
+}
+
+func (a *API) CanaryPolicyApiGet(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	name := vars["canaryPolicy"]
+	ns := a.extractQueryParamFromRequest(r, "namespace")
+	if len(ns) == 0 {
+		ns = metav1.NamespaceDefault
+	}
+
+	policy, err := a.fissionClient.CanaryPolicies(ns).Get(name)
+	if err != nil {
+		a.respondWithError(w, err)
+		return
+	}
+
+	resp, err := json.Marshal(policy)
+	if err != nil {
+		a.respondWithError(w, err)
+		return
+	}
+
+	a.respondWithSuccess(w, resp)
+}
+
+func (a *API) CanaryPolicyApiUpdate(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	name := vars["canaryPolicy"]
+
+	body, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		a.respondWithError(w, err)
+		return
+	}
+
+	var policy fv1.CanaryPolicy
+	err = json.Unmarshal(body, &policy)
+	if err != nil {
+		a.respondWithError(w, err)
+		return
+	}
+
+	if name != policy.Metadata.Name {
+		err = ferror.MakeError(ferror.ErrorInvalidArgument, "CanaryPolicy name doesn't match URL")
+		a.respondWithError(w, err)
+		return
+	}
+
+	err = a.policyChecker.check(r.Context(), policyOperationUpdate, "CanaryPolicy", &policy.Metadata, &policy)
+	if err != nil {
+		a.respondWithError(w, err)
+		return
+	}
+
+	policyNew, err := a.fissionClient.CanaryPolicies(policy.Metadata.Namespace).Update(&policy)
+	if err != nil {
+		a.respondWithError(w, err)
+		return
+	}
+
+	resp, err := json.Marshal(policyNew.Metadata)
+	if err != nil {
+		a.respondWithError(w, err)
+		return
+	}
+
+	a.respondWithSuccess(w, resp)
+}
+
+func (a *API) CanaryPolicyApiDelete(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	name := vars["canaryPolicy"]
+	ns := a.extractQueryParamFromRequest(r, "namespace")
+	if len(ns) == 0 {
+		ns = metav1.NamespaceDefault
+	}
+
+	err := a.fissionClient.CanaryPolicies(ns).Delete(name, &metav1.DeleteOptions{})
+	if err != nil {
+		a.respondWithError(w, err)
+		return
+	}
+
+	a.respondWithSuccess(w, []byte(""))
+}
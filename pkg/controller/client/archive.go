@@ -0,0 +1,125 @@
+/*
+Copyright 2019 The Fission Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"os"
+	"path/filepath"
+
+	"golang.org/x/net/context/ctxhttp"
+
+	"github.com/fission/fission/pkg/storagesvc"
+)
+
+// ArchiveUpload uploads the local file to the storage service through the
+// controller and returns the archive ID. The multipart body is streamed from
+// disk with an exact Content-Length, so it passes through ingresses and load
+// balancers that reject chunked uploads. authToken is sent as a bearer token
+// if it's not empty.
+func (c *Client) ArchiveUpload(ctx context.Context, filePath string, authToken string) (string, error) {
+	return c.ArchiveUploadWithProgress(ctx, filePath, authToken, nil)
+}
+
+// UploadProgressFunc is called with the number of bytes of the file sent so
+// far and the size of the file while it's uploaded.
+type UploadProgressFunc func(sent int64, total int64)
+
+// ArchiveUploadWithProgress is ArchiveUpload calling progress, if it's not
+// nil, as the file is read. It's called on every read, so it should be cheap.
+func (c *Client) ArchiveUploadWithProgress(ctx context.Context, filePath string, authToken string, progress UploadProgressFunc) (string, error) {
+	f, err := os.Open(filePath)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	fi, err := f.Stat()
+	if err != nil {
+		return "", err
+	}
+
+	// Write the multipart header and trailer separately so that the file
+	// content does not need to be buffered in memory.
+	head := &bytes.Buffer{}
+	bodyWriter := multipart.NewWriter(head)
+	_, err = bodyWriter.CreateFormFile("uploadfile", filepath.Base(filePath))
+	if err != nil {
+		return "", err
+	}
+	headLen := head.Len()
+	err = bodyWriter.Close()
+	if err != nil {
+		return "", err
+	}
+	tail := bytes.NewReader(head.Bytes()[headLen:])
+	head.Truncate(headLen)
+
+	var content io.Reader = f
+	if progress != nil {
+		content = &progressReader{r: f, total: fi.Size(), progress: progress}
+	}
+	body := io.MultiReader(head, content, tail)
+	req, err := http.NewRequest(http.MethodPost, c.url("archives"), body)
+	if err != nil {
+		return "", err
+	}
+	req.ContentLength = int64(headLen) + fi.Size() + tail.Size()
+	req.Header.Set("Content-Type", bodyWriter.FormDataContentType())
+	if len(authToken) > 0 {
+		req.Header.Set("Authorization", "Bearer "+authToken)
+	}
+
+	resp, err := ctxhttp.Do(ctx, &http.Client{}, req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	respBody, err := c.handleCreateResponse(resp)
+	if err != nil {
+		return "", err
+	}
+
+	var ur storagesvc.UploadResponse
+	err = json.Unmarshal(respBody, &ur)
+	if err != nil {
+		return "", err
+	}
+
+	return ur.ID, nil
+}
+
+// progressReader reports the bytes read from r to progress.
+type progressReader struct {
+	r        io.Reader
+	sent     int64
+	total    int64
+	progress UploadProgressFunc
+}
+
+func (pr *progressReader) Read(p []byte) (int, error) {
+	n, err := pr.r.Read(p)
+	pr.sent += int64(n)
+	pr.progress(pr.sent, pr.total)
+	return n, err
+}
@@ -0,0 +1,73 @@
+/*
+Copyright 2019 The Fission Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package client
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+
+	"github.com/fission/fission/pkg/types"
+)
+
+// AuditList lists the audit events of the resources, oldest first. Empty
+// kind, namespace or name match any resource.
+func (c *Client) AuditList(kind string, namespace string, name string) ([]types.AuditEvent, error) {
+	q := url.Values{}
+	q.Set("kind", kind)
+	q.Set("namespace", namespace)
+	q.Set("name", name)
+
+	resp, err := http.Get(c.url("audit?" + q.Encode()))
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	body, err := c.handleResponse(resp)
+	if err != nil {
+		return nil, err
+	}
+
+	events := make([]types.AuditEvent, 0)
+	err = json.Unmarshal(body, &events)
+	if err != nil {
+		return nil, err
+	}
+	return events, nil
+}
+
+func (c *Client) AuditGet(id string) (*types.AuditEvent, error) {
+	resp, err := http.Get(c.url(fmt.Sprintf("audit/%v", id)))
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	body, err := c.handleResponse(resp)
+	if err != nil {
+		return nil, err
+	}
+
+	var event types.AuditEvent
+	err = json.Unmarshal(body, &event)
+	if err != nil {
+		return nil, err
+	}
+	return &event, nil
+}
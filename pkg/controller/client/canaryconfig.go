@@ -17,7 +17,6 @@ limitations under the License.
 package client
 
 import (
-	"bytes"
 	"encoding/json"
 	"fmt"
 	"net/http"
@@ -28,12 +27,17 @@ import (
 )
 
 func (c *Client) CanaryConfigCreate(canaryConf *fv1.CanaryConfig) (*metav1.ObjectMeta, error) {
+	err := canaryConf.Validate()
+	if err != nil {
+		return nil, fv1.AggregateValidationErrors("CanaryConfig", err)
+	}
+
 	reqbody, err := json.Marshal(canaryConf)
 	if err != nil {
 		return nil, err
 	}
 
-	resp, err := http.Post(c.url("canaryconfigs"), "application/json", bytes.NewReader(reqbody))
+	resp, err := c.post("canaryconfigs", "application/json", reqbody)
 	if err != nil {
 		return nil, err
 	}
@@ -78,6 +82,11 @@ func (c *Client) CanaryConfigGet(m *metav1.ObjectMeta) (*fv1.CanaryConfig, error
 }
 
 func (c *Client) CanaryConfigUpdate(canaryConf *fv1.CanaryConfig) (*metav1.ObjectMeta, error) {
+	err := canaryConf.Validate()
+	if err != nil {
+		return nil, fv1.AggregateValidationErrors("CanaryConfig", err)
+	}
+
 	reqbody, err := json.Marshal(canaryConf)
 	if err != nil {
 		return nil, err
@@ -111,23 +120,28 @@ func (c *Client) CanaryConfigDelete(m *metav1.ObjectMeta) error {
 }
 
 func (c *Client) CanaryConfigList(ns string) ([]fv1.CanaryConfig, error) {
-	relativeUrl := fmt.Sprintf("canaryconfigs?namespace=%v", ns)
-	resp, err := http.Get(c.url(relativeUrl))
-	if err != nil {
-		return nil, err
-	}
-	defer resp.Body.Close()
+	return c.CanaryConfigListWithOptions(ns, ListOptions{})
+}
 
-	body, err := c.handleResponse(resp)
+func (c *Client) CanaryConfigListWithOptions(ns string, opts ListOptions) ([]fv1.CanaryConfig, error) {
+	canaryCfgs := make([]fv1.CanaryConfig, 0)
+	err := listAll(opts, func(opts ListOptions) (string, error) {
+		page, cont, err := c.CanaryConfigListPage(ns, opts)
+		canaryCfgs = append(canaryCfgs, page...)
+		return cont, err
+	})
 	if err != nil {
 		return nil, err
 	}
+	return canaryCfgs, nil
+}
 
+func (c *Client) CanaryConfigListPage(ns string, opts ListOptions) ([]fv1.CanaryConfig, string, error) {
+	relativeUrl := fmt.Sprintf("canaryconfigs?namespace=%v", ns)
 	canaryCfgs := make([]fv1.CanaryConfig, 0)
-	err = json.Unmarshal(body, &canaryCfgs)
+	cont, err := c.list(relativeUrl, opts, &canaryCfgs)
 	if err != nil {
-		return nil, err
+		return nil, "", err
 	}
-
-	return canaryCfgs, nil
+	return canaryCfgs, cont, nil
 }
@@ -0,0 +1,146 @@
+/*
+Copyright 2019 The Fission Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package client
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	fv1 "github.com/fission/fission/pkg/apis/fission.io/v1"
+)
+
+func (c *Client) CanaryPolicyCreate(policy *fv1.CanaryPolicy) (*metav1.ObjectMeta, error) {
+	err := policy.Validate()
+	if err != nil {
+		return nil, fv1.AggregateValidationErrors("CanaryPolicy", err)
+	}
+
+	reqbody, err := json.Marshal(policy)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := c.post("canarypolicies", "application/json", reqbody)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	body, err := c.handleCreateResponse(resp)
+	if err != nil {
+		return nil, err
+	}
+
+	var m metav1.ObjectMeta
+	err = json.Unmarshal(body, &m)
+	if err != nil {
+		return nil, err
+	}
+
+	return &m, nil
+}
+
+func (c *Client) CanaryPolicyGet(m *metav1.ObjectMeta) (*fv1.CanaryPolicy, error) {
+	relativeUrl := fmt.Sprintf("canarypolicies/%v", m.Name)
+	relativeUrl += fmt.Sprintf("?namespace=%v", m.Namespace)
+
+	resp, err := http.Get(c.url(relativeUrl))
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	body, err := c.handleResponse(resp)
+	if err != nil {
+		return nil, err
+	}
+
+	var policy fv1.CanaryPolicy
+	err = json.Unmarshal(body, &policy)
+	if err != nil {
+		return nil, err
+	}
+
+	return &policy, nil
+}
+
+func (c *Client) CanaryPolicyUpdate(policy *fv1.CanaryPolicy) (*metav1.ObjectMeta, error) {
+	err := policy.Validate()
+	if err != nil {
+		return nil, fv1.AggregateValidationErrors("CanaryPolicy", err)
+	}
+
+	reqbody, err := json.Marshal(policy)
+	if err != nil {
+		return nil, err
+	}
+	relativeUrl := fmt.Sprintf("canarypolicies/%v", policy.Metadata.Name)
+
+	resp, err := c.put(relativeUrl, "application/json", reqbody)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	body, err := c.handleResponse(resp)
+	if err != nil {
+		return nil, err
+	}
+
+	var m metav1.ObjectMeta
+	err = json.Unmarshal(body, &m)
+	if err != nil {
+		return nil, err
+	}
+	return &m, nil
+}
+
+func (c *Client) CanaryPolicyDelete(m *metav1.ObjectMeta) error {
+	relativeUrl := fmt.Sprintf("canarypolicies/%v", m.Name)
+	relativeUrl += fmt.Sprintf("?namespace=%v", m.Namespace)
+	return c.delete(relativeUrl)
+}
+
+func (c *Client) CanaryPolicyList(ns string) ([]fv1.CanaryPolicy, error) {
+	return c.CanaryPolicyListWithOptions(ns, ListOptions{})
+}
+
+func (c *Client) CanaryPolicyListWithOptions(ns string, opts ListOptions) ([]fv1.CanaryPolicy, error) {
+	policies := make([]fv1.CanaryPolicy, 0)
+	err := listAll(opts, func(opts ListOptions) (string, error) {
+		page, cont, err := c.CanaryPolicyListPage(ns, opts)
+		policies = append(policies, page...)
+		return cont, err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return policies, nil
+}
+
+func (c *Client) CanaryPolicyListPage(ns string, opts ListOptions) ([]fv1.CanaryPolicy, string, error) {
+	relativeUrl := fmt.Sprintf("canarypolicies?namespace=%v", ns)
+	policies := make([]fv1.CanaryPolicy, 0)
+	cont, err := c.list(relativeUrl, opts, &policies)
+	if err != nil {
+		return nil, "", err
+	}
+	return policies, cont, nil
+}
@@ -24,6 +24,8 @@ import (
 	"fmt"
 	"io/ioutil"
 	"net/http"
+	"os"
+	"os/user"
 	"strings"
 	"time"
 
@@ -31,16 +33,44 @@ import (
 
 	ferror "github.com/fission/fission/pkg/error"
 	"github.com/fission/fission/pkg/info"
+	"github.com/fission/fission/pkg/types"
 )
 
 type (
 	Client struct {
 		Url string
+		// User is sent to the controller for its audit log.
+		User string
 	}
 )
 
 func MakeClient(serverUrl string) *Client {
-	return &Client{Url: strings.TrimSuffix(serverUrl, "/")}
+	return &Client{
+		Url:  strings.TrimSuffix(serverUrl, "/"),
+		User: currentUser(),
+	}
+}
+
+// currentUser returns the user for the audit log, $FISSION_USER or the
+// local user and host name.
+func currentUser() string {
+	if u := os.Getenv("FISSION_USER"); len(u) > 0 {
+		return u
+	}
+	name := "unknown"
+	if u, err := user.Current(); err == nil {
+		name = u.Username
+	}
+	if host, err := os.Hostname(); err == nil {
+		name = fmt.Sprintf("%v@%v", name, host)
+	}
+	return name
+}
+
+func (c *Client) setUser(req *http.Request) {
+	if len(c.User) > 0 {
+		req.Header.Set(types.AuditUserHeader, c.User)
+	}
 }
 
 func (c *Client) delete(relativeUrl string) error {
@@ -48,6 +78,7 @@ func (c *Client) delete(relativeUrl string) error {
 	if err != nil {
 		return err
 	}
+	c.setUser(req)
 
 	resp, err := http.DefaultClient.Do(req)
 	if err != nil {
@@ -73,6 +104,17 @@ func (c *Client) put(relativeUrl string, contentType string, body []byte) (*http
 		return nil, err
 	}
 	req.Header.Set("Content-type", contentType)
+	c.setUser(req)
+	return http.DefaultClient.Do(req)
+}
+
+func (c *Client) post(relativeUrl string, contentType string, body []byte) (*http.Response, error) {
+	req, err := http.NewRequest("POST", c.url(relativeUrl), bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-type", contentType)
+	c.setUser(req)
 	return http.DefaultClient.Do(req)
 }
 
@@ -17,7 +17,6 @@ limitations under the License.
 package client
 
 import (
-	"bytes"
 	"encoding/json"
 	"fmt"
 	"net/http"
@@ -43,7 +42,7 @@ func (c *Client) EnvironmentCreate(env *fv1.Environment) (*metav1.ObjectMeta, er
 		return nil, err
 	}
 
-	resp, err := http.Post(c.url("environments"), "application/json", bytes.NewReader(data))
+	resp, err := c.post("environments", "application/json", data)
 	if err != nil {
 		return nil, err
 	}
@@ -122,23 +121,28 @@ func (c *Client) EnvironmentDelete(m *metav1.ObjectMeta) error {
 }
 
 func (c *Client) EnvironmentList(ns string) ([]fv1.Environment, error) {
-	relativeUrl := fmt.Sprintf("environments?namespace=%v", ns)
-	resp, err := http.Get(c.url(relativeUrl))
-	if err != nil {
-		return nil, err
-	}
-	defer resp.Body.Close()
+	return c.EnvironmentListWithOptions(ns, ListOptions{})
+}
 
-	body, err := c.handleResponse(resp)
+func (c *Client) EnvironmentListWithOptions(ns string, opts ListOptions) ([]fv1.Environment, error) {
+	envs := make([]fv1.Environment, 0)
+	err := listAll(opts, func(opts ListOptions) (string, error) {
+		page, cont, err := c.EnvironmentListPage(ns, opts)
+		envs = append(envs, page...)
+		return cont, err
+	})
 	if err != nil {
 		return nil, err
 	}
+	return envs, nil
+}
 
+func (c *Client) EnvironmentListPage(ns string, opts ListOptions) ([]fv1.Environment, string, error) {
+	relativeUrl := fmt.Sprintf("environments?namespace=%v", ns)
 	envs := make([]fv1.Environment, 0)
-	err = json.Unmarshal(body, &envs)
+	cont, err := c.list(relativeUrl, opts, &envs)
 	if err != nil {
-		return nil, err
+		return nil, "", err
 	}
-
-	return envs, nil
+	return envs, cont, nil
 }
@@ -0,0 +1,46 @@
+/*
+Copyright 2019 The Fission Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package client
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/fission/fission/pkg/types"
+)
+
+// FeatureGet returns the optional features and components installed along
+// with the controller.
+func (c *Client) FeatureGet() (*types.FeatureInfo, error) {
+	resp, err := http.Get(c.url("features"))
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	body, err := c.handleResponse(resp)
+	if err != nil {
+		return nil, err
+	}
+
+	var features types.FeatureInfo
+	err = json.Unmarshal(body, &features)
+	if err != nil {
+		return nil, err
+	}
+	return &features, nil
+}
@@ -21,10 +21,12 @@ import (
 	"encoding/json"
 	"fmt"
 	"net/http"
+	"time"
 
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 
 	fv1 "github.com/fission/fission/pkg/apis/fission.io/v1"
+	"github.com/fission/fission/pkg/types"
 )
 
 func (c *Client) FunctionCreate(f *fv1.Function) (*metav1.ObjectMeta, error) {
@@ -38,7 +40,7 @@ func (c *Client) FunctionCreate(f *fv1.Function) (*metav1.ObjectMeta, error) {
 		return nil, err
 	}
 
-	resp, err := http.Post(c.url("functions"), "application/json", bytes.NewReader(reqbody))
+	resp, err := c.post("functions", "application/json", reqbody)
 	if err != nil {
 		return nil, err
 	}
@@ -134,7 +136,76 @@ func (c *Client) FunctionDelete(m *metav1.ObjectMeta) error {
 }
 
 func (c *Client) FunctionList(functionNamespace string) ([]fv1.Function, error) {
+	return c.FunctionListWithOptions(functionNamespace, ListOptions{})
+}
+
+// FunctionListWithOptions lists all the functions selected by opts, getting
+// them from the controller a page at a time.
+func (c *Client) FunctionListWithOptions(functionNamespace string, opts ListOptions) ([]fv1.Function, error) {
+	funcs := make([]fv1.Function, 0)
+	err := listAll(opts, func(opts ListOptions) (string, error) {
+		page, cont, err := c.FunctionListPage(functionNamespace, opts)
+		funcs = append(funcs, page...)
+		return cont, err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return funcs, nil
+}
+
+// FunctionListPage lists a page of the functions selected by opts, and
+// returns the token to get the next page, which is empty on the last page.
+func (c *Client) FunctionListPage(functionNamespace string, opts ListOptions) ([]fv1.Function, string, error) {
 	relativeUrl := fmt.Sprintf("functions?namespace=%v", functionNamespace)
+	funcs := make([]fv1.Function, 0)
+	cont, err := c.list(relativeUrl, opts, &funcs)
+	if err != nil {
+		return nil, "", err
+	}
+	return funcs, cont, nil
+}
+
+// FunctionProfile captures a profile of the running pods of a function, it
+// blocks for the duration of the capture.
+func (c *Client) FunctionProfile(m *metav1.ObjectMeta, profileType string, seconds int) (*types.FunctionProfileResponse, error) {
+	reqbody, err := json.Marshal(types.FunctionProfileRequest{
+		Type:    profileType,
+		Seconds: seconds,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	relativeUrl := fmt.Sprintf("functions/%v/profile", m.Name)
+	relativeUrl += fmt.Sprintf("?namespace=%v", m.Namespace)
+
+	resp, err := http.Post(c.url(relativeUrl), "application/json", bytes.NewReader(reqbody))
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	body, err := c.handleResponse(resp)
+	if err != nil {
+		return nil, err
+	}
+
+	profiles := &types.FunctionProfileResponse{}
+	err = json.Unmarshal(body, profiles)
+	if err != nil {
+		return nil, err
+	}
+
+	return profiles, nil
+}
+
+// FunctionMetrics returns a summary of the invocations of a function in the
+// time window ending now.
+func (c *Client) FunctionMetrics(m *metav1.ObjectMeta, since time.Duration) (*types.FunctionMetrics, error) {
+	relativeUrl := fmt.Sprintf("functions/%v/metrics", m.Name)
+	relativeUrl += fmt.Sprintf("?namespace=%v&since=%v", m.Namespace, since)
+
 	resp, err := http.Get(c.url(relativeUrl))
 	if err != nil {
 		return nil, err
@@ -146,11 +217,37 @@ func (c *Client) FunctionList(functionNamespace string) ([]fv1.Function, error)
 		return nil, err
 	}
 
-	funcs := make([]fv1.Function, 0)
-	err = json.Unmarshal(body, &funcs)
+	metrics := &types.FunctionMetrics{}
+	err = json.Unmarshal(body, metrics)
 	if err != nil {
 		return nil, err
 	}
 
-	return funcs, nil
+	return metrics, nil
+}
+
+// FunctionStats returns the invocations, cold starts and latency of a
+// function the routers recorded in the time window ending now.
+func (c *Client) FunctionStats(m *metav1.ObjectMeta, since time.Duration) (*types.FunctionStats, error) {
+	relativeUrl := fmt.Sprintf("functions/%v/stats", m.Name)
+	relativeUrl += fmt.Sprintf("?namespace=%v&since=%v", m.Namespace, since)
+
+	resp, err := http.Get(c.url(relativeUrl))
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	body, err := c.handleResponse(resp)
+	if err != nil {
+		return nil, err
+	}
+
+	stats := &types.FunctionStats{}
+	err = json.Unmarshal(body, stats)
+	if err != nil {
+		return nil, err
+	}
+
+	return stats, nil
 }
@@ -0,0 +1,146 @@
+/*
+Copyright 2019 The Fission Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package client
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	fv1 "github.com/fission/fission/pkg/apis/fission.io/v1"
+)
+
+func (c *Client) FunctionAliasCreate(alias *fv1.FunctionAlias) (*metav1.ObjectMeta, error) {
+	err := alias.Validate()
+	if err != nil {
+		return nil, fv1.AggregateValidationErrors("FunctionAlias", err)
+	}
+
+	reqbody, err := json.Marshal(alias)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := c.post("aliases", "application/json", reqbody)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	body, err := c.handleCreateResponse(resp)
+	if err != nil {
+		return nil, err
+	}
+
+	var m metav1.ObjectMeta
+	err = json.Unmarshal(body, &m)
+	if err != nil {
+		return nil, err
+	}
+
+	return &m, nil
+}
+
+func (c *Client) FunctionAliasGet(m *metav1.ObjectMeta) (*fv1.FunctionAlias, error) {
+	relativeUrl := fmt.Sprintf("aliases/%v", m.Name)
+	relativeUrl += fmt.Sprintf("?namespace=%v", m.Namespace)
+
+	resp, err := http.Get(c.url(relativeUrl))
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	body, err := c.handleResponse(resp)
+	if err != nil {
+		return nil, err
+	}
+
+	var alias fv1.FunctionAlias
+	err = json.Unmarshal(body, &alias)
+	if err != nil {
+		return nil, err
+	}
+
+	return &alias, nil
+}
+
+func (c *Client) FunctionAliasUpdate(alias *fv1.FunctionAlias) (*metav1.ObjectMeta, error) {
+	err := alias.Validate()
+	if err != nil {
+		return nil, fv1.AggregateValidationErrors("FunctionAlias", err)
+	}
+
+	reqbody, err := json.Marshal(alias)
+	if err != nil {
+		return nil, err
+	}
+	relativeUrl := fmt.Sprintf("aliases/%v", alias.Metadata.Name)
+
+	resp, err := c.put(relativeUrl, "application/json", reqbody)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	body, err := c.handleResponse(resp)
+	if err != nil {
+		return nil, err
+	}
+
+	var m metav1.ObjectMeta
+	err = json.Unmarshal(body, &m)
+	if err != nil {
+		return nil, err
+	}
+	return &m, nil
+}
+
+func (c *Client) FunctionAliasDelete(m *metav1.ObjectMeta) error {
+	relativeUrl := fmt.Sprintf("aliases/%v", m.Name)
+	relativeUrl += fmt.Sprintf("?namespace=%v", m.Namespace)
+	return c.delete(relativeUrl)
+}
+
+func (c *Client) FunctionAliasList(ns string) ([]fv1.FunctionAlias, error) {
+	return c.FunctionAliasListWithOptions(ns, ListOptions{})
+}
+
+func (c *Client) FunctionAliasListWithOptions(ns string, opts ListOptions) ([]fv1.FunctionAlias, error) {
+	aliases := make([]fv1.FunctionAlias, 0)
+	err := listAll(opts, func(opts ListOptions) (string, error) {
+		page, cont, err := c.FunctionAliasListPage(ns, opts)
+		aliases = append(aliases, page...)
+		return cont, err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return aliases, nil
+}
+
+func (c *Client) FunctionAliasListPage(ns string, opts ListOptions) ([]fv1.FunctionAlias, string, error) {
+	relativeUrl := fmt.Sprintf("aliases?namespace=%v", ns)
+	aliases := make([]fv1.FunctionAlias, 0)
+	cont, err := c.list(relativeUrl, opts, &aliases)
+	if err != nil {
+		return nil, "", err
+	}
+	return aliases, cont, nil
+}
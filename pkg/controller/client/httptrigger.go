@@ -17,7 +17,6 @@ limitations under the License.
 package client
 
 import (
-	"bytes"
 	"encoding/json"
 	"fmt"
 	"net/http"
@@ -38,7 +37,7 @@ func (c *Client) HTTPTriggerCreate(t *fv1.HTTPTrigger) (*metav1.ObjectMeta, erro
 		return nil, err
 	}
 
-	resp, err := http.Post(c.url("triggers/http"), "application/json", bytes.NewReader(reqbody))
+	resp, err := c.post("triggers/http", "application/json", reqbody)
 	if err != nil {
 		return nil, err
 	}
@@ -120,23 +119,28 @@ func (c *Client) HTTPTriggerDelete(m *metav1.ObjectMeta) error {
 }
 
 func (c *Client) HTTPTriggerList(triggerNamespace string) ([]fv1.HTTPTrigger, error) {
-	relativeUrl := fmt.Sprintf("triggers/http?namespace=%v", triggerNamespace)
-	resp, err := http.Get(c.url(relativeUrl))
-	if err != nil {
-		return nil, err
-	}
-	defer resp.Body.Close()
+	return c.HTTPTriggerListWithOptions(triggerNamespace, ListOptions{})
+}
 
-	body, err := c.handleResponse(resp)
+func (c *Client) HTTPTriggerListWithOptions(triggerNamespace string, opts ListOptions) ([]fv1.HTTPTrigger, error) {
+	triggers := make([]fv1.HTTPTrigger, 0)
+	err := listAll(opts, func(opts ListOptions) (string, error) {
+		page, cont, err := c.HTTPTriggerListPage(triggerNamespace, opts)
+		triggers = append(triggers, page...)
+		return cont, err
+	})
 	if err != nil {
 		return nil, err
 	}
+	return triggers, nil
+}
 
+func (c *Client) HTTPTriggerListPage(triggerNamespace string, opts ListOptions) ([]fv1.HTTPTrigger, string, error) {
+	relativeUrl := fmt.Sprintf("triggers/http?namespace=%v", triggerNamespace)
 	triggers := make([]fv1.HTTPTrigger, 0)
-	err = json.Unmarshal(body, &triggers)
+	cont, err := c.list(relativeUrl, opts, &triggers)
 	if err != nil {
-		return nil, err
+		return nil, "", err
 	}
-
-	return triggers, nil
+	return triggers, cont, nil
 }
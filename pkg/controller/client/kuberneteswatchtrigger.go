@@ -17,7 +17,6 @@ limitations under the License.
 package client
 
 import (
-	"bytes"
 	"encoding/json"
 	"fmt"
 	"net/http"
@@ -39,7 +38,7 @@ func (c *Client) WatchCreate(w *fv1.KubernetesWatchTrigger) (*metav1.ObjectMeta,
 		return nil, err
 	}
 
-	resp, err := http.Post(c.url("watches"), "application/json", bytes.NewReader(reqbody))
+	resp, err := c.post("watches", "application/json", reqbody)
 	if err != nil {
 		return nil, err
 	}
@@ -95,23 +94,28 @@ func (c *Client) WatchDelete(m *metav1.ObjectMeta) error {
 }
 
 func (c *Client) WatchList(ns string) ([]fv1.KubernetesWatchTrigger, error) {
-	relativeUrl := fmt.Sprintf("watches?namespace=%v", ns)
-	resp, err := http.Get(c.url(relativeUrl))
-	if err != nil {
-		return nil, err
-	}
-	defer resp.Body.Close()
+	return c.WatchListWithOptions(ns, ListOptions{})
+}
 
-	body, err := c.handleResponse(resp)
+func (c *Client) WatchListWithOptions(ns string, opts ListOptions) ([]fv1.KubernetesWatchTrigger, error) {
+	watches := make([]fv1.KubernetesWatchTrigger, 0)
+	err := listAll(opts, func(opts ListOptions) (string, error) {
+		page, cont, err := c.WatchListPage(ns, opts)
+		watches = append(watches, page...)
+		return cont, err
+	})
 	if err != nil {
 		return nil, err
 	}
+	return watches, nil
+}
 
+func (c *Client) WatchListPage(ns string, opts ListOptions) ([]fv1.KubernetesWatchTrigger, string, error) {
+	relativeUrl := fmt.Sprintf("watches?namespace=%v", ns)
 	watches := make([]fv1.KubernetesWatchTrigger, 0)
-	err = json.Unmarshal(body, &watches)
+	cont, err := c.list(relativeUrl, opts, &watches)
 	if err != nil {
-		return nil, err
+		return nil, "", err
 	}
-
-	return watches, err
+	return watches, cont, nil
 }
@@ -0,0 +1,107 @@
+/*
+Copyright 2016 The Fission Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package client
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/fission/fission/pkg/types"
+)
+
+// defaultListPageSize is the number of objects the list methods ask the
+// controller for at a time, so that listing many objects doesn't time out.
+const defaultListPageSize = 500
+
+type (
+	// ListOptions selects the objects to list and paginates them. A Limit
+	// of 0 lists all the selected objects at once.
+	ListOptions struct {
+		LabelSelector string
+		FieldSelector string
+		Limit         int64
+		Continue      string
+	}
+)
+
+func (opts ListOptions) query() url.Values {
+	q := url.Values{}
+	if len(opts.LabelSelector) > 0 {
+		q.Set("labelSelector", opts.LabelSelector)
+	}
+	if len(opts.FieldSelector) > 0 {
+		q.Set("fieldSelector", opts.FieldSelector)
+	}
+	if opts.Limit > 0 {
+		q.Set("limit", fmt.Sprintf("%v", opts.Limit))
+	}
+	if len(opts.Continue) > 0 {
+		q.Set("continue", opts.Continue)
+	}
+	return q
+}
+
+// list gets a page of a list endpoint into items, and returns the token to
+// get the next page, which is empty on the last page.
+func (c *Client) list(relativeUrl string, opts ListOptions, items interface{}) (string, error) {
+	if q := opts.query().Encode(); len(q) > 0 {
+		if strings.Contains(relativeUrl, "?") {
+			relativeUrl += "&" + q
+		} else {
+			relativeUrl += "?" + q
+		}
+	}
+
+	resp, err := http.Get(c.url(relativeUrl))
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	body, err := c.handleResponse(resp)
+	if err != nil {
+		return "", err
+	}
+
+	err = json.Unmarshal(body, items)
+	if err != nil {
+		return "", err
+	}
+
+	return resp.Header.Get(types.ListContinueHeader), nil
+}
+
+// listAll calls listPage until the last page, starting with opts and
+// following the continue tokens it returns.
+func listAll(opts ListOptions, listPage func(opts ListOptions) (string, error)) error {
+	if opts.Limit == 0 {
+		opts.Limit = defaultListPageSize
+	}
+	for {
+		cont, err := listPage(opts)
+		if err != nil {
+			return err
+		}
+		if len(cont) == 0 {
+			return nil
+		}
+		opts.Continue = cont
+	}
+}
@@ -17,7 +17,6 @@ limitations under the License.
 package client
 
 import (
-	"bytes"
 	"encoding/json"
 	"fmt"
 	"net/http"
@@ -38,7 +37,7 @@ func (c *Client) MessageQueueTriggerCreate(t *fv1.MessageQueueTrigger) (*metav1.
 		return nil, err
 	}
 
-	resp, err := http.Post(c.url("triggers/messagequeue"), "application/json", bytes.NewReader(reqbody))
+	resp, err := c.post("triggers/messagequeue", "application/json", reqbody)
 	if err != nil {
 		return nil, err
 	}
@@ -120,28 +119,32 @@ func (c *Client) MessageQueueTriggerDelete(m *metav1.ObjectMeta) error {
 }
 
 func (c *Client) MessageQueueTriggerList(mqType string, ns string) ([]fv1.MessageQueueTrigger, error) {
-	relativeUrl := "triggers/messagequeue"
-	if len(mqType) > 0 {
-		// TODO remove this, replace with field selector
-		relativeUrl += fmt.Sprintf("?mqtype=%v&namespace=%v", mqType, ns)
-	}
+	return c.MessageQueueTriggerListWithOptions(mqType, ns, ListOptions{})
+}
 
-	resp, err := http.Get(c.url(relativeUrl))
+func (c *Client) MessageQueueTriggerListWithOptions(mqType string, ns string, opts ListOptions) ([]fv1.MessageQueueTrigger, error) {
+	triggers := make([]fv1.MessageQueueTrigger, 0)
+	err := listAll(opts, func(opts ListOptions) (string, error) {
+		page, cont, err := c.MessageQueueTriggerListPage(mqType, ns, opts)
+		triggers = append(triggers, page...)
+		return cont, err
+	})
 	if err != nil {
 		return nil, err
 	}
-	defer resp.Body.Close()
+	return triggers, nil
+}
 
-	body, err := c.handleResponse(resp)
-	if err != nil {
-		return nil, err
+func (c *Client) MessageQueueTriggerListPage(mqType string, ns string, opts ListOptions) ([]fv1.MessageQueueTrigger, string, error) {
+	relativeUrl := "triggers/messagequeue"
+	if len(mqType) > 0 {
+		// TODO remove this, replace with field selector
+		relativeUrl += fmt.Sprintf("?mqtype=%v&namespace=%v", mqType, ns)
 	}
-
 	triggers := make([]fv1.MessageQueueTrigger, 0)
-	err = json.Unmarshal(body, &triggers)
+	cont, err := c.list(relativeUrl, opts, &triggers)
 	if err != nil {
-		return nil, err
+		return nil, "", err
 	}
-
-	return triggers, nil
+	return triggers, cont, nil
 }
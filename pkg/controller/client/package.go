@@ -17,14 +17,15 @@ limitations under the License.
 package client
 
 import (
-	"bytes"
 	"encoding/json"
 	"fmt"
+	"io"
 	"net/http"
 
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 
 	fv1 "github.com/fission/fission/pkg/apis/fission.io/v1"
+	ferror "github.com/fission/fission/pkg/error"
 )
 
 func (c *Client) PackageCreate(f *fv1.Package) (*metav1.ObjectMeta, error) {
@@ -38,7 +39,7 @@ func (c *Client) PackageCreate(f *fv1.Package) (*metav1.ObjectMeta, error) {
 		return nil, err
 	}
 
-	resp, err := http.Post(c.url("packages"), "application/json", bytes.NewReader(reqbody))
+	resp, err := c.post("packages", "application/json", reqbody)
 	if err != nil {
 		return nil, err
 	}
@@ -82,6 +83,23 @@ func (c *Client) PackageGet(m *metav1.ObjectMeta) (*fv1.Package, error) {
 	return &f, nil
 }
 
+// PackageBuildLogs streams the logs of the running build of a package,
+// the stream ends when the build is over.
+func (c *Client) PackageBuildLogs(m *metav1.ObjectMeta) (io.ReadCloser, error) {
+	relativeUrl := fmt.Sprintf("packages/%v/buildlogs", m.Name)
+	relativeUrl += fmt.Sprintf("?namespace=%v", m.Namespace)
+
+	resp, err := http.Get(c.url(relativeUrl))
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		defer resp.Body.Close()
+		return nil, ferror.MakeErrorFromHTTP(resp)
+	}
+	return resp.Body, nil
+}
+
 func (c *Client) PackageUpdate(f *fv1.Package) (*metav1.ObjectMeta, error) {
 	err := f.Validate()
 	if err != nil {
@@ -120,23 +138,28 @@ func (c *Client) PackageDelete(m *metav1.ObjectMeta) error {
 }
 
 func (c *Client) PackageList(pkgNamespace string) ([]fv1.Package, error) {
-	relativeUrl := fmt.Sprintf("packages?namespace=%v", pkgNamespace)
-	resp, err := http.Get(c.url(relativeUrl))
-	if err != nil {
-		return nil, err
-	}
-	defer resp.Body.Close()
+	return c.PackageListWithOptions(pkgNamespace, ListOptions{})
+}
 
-	body, err := c.handleResponse(resp)
+func (c *Client) PackageListWithOptions(pkgNamespace string, opts ListOptions) ([]fv1.Package, error) {
+	pkgs := make([]fv1.Package, 0)
+	err := listAll(opts, func(opts ListOptions) (string, error) {
+		page, cont, err := c.PackageListPage(pkgNamespace, opts)
+		pkgs = append(pkgs, page...)
+		return cont, err
+	})
 	if err != nil {
 		return nil, err
 	}
+	return pkgs, nil
+}
 
-	funcs := make([]fv1.Package, 0)
-	err = json.Unmarshal(body, &funcs)
+func (c *Client) PackageListPage(pkgNamespace string, opts ListOptions) ([]fv1.Package, string, error) {
+	relativeUrl := fmt.Sprintf("packages?namespace=%v", pkgNamespace)
+	pkgs := make([]fv1.Package, 0)
+	cont, err := c.list(relativeUrl, opts, &pkgs)
 	if err != nil {
-		return nil, err
+		return nil, "", err
 	}
-
-	return funcs, nil
+	return pkgs, cont, nil
 }
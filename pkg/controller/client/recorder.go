@@ -17,14 +17,16 @@ limitations under the License.
 package client
 
 import (
-	"bytes"
 	"encoding/json"
 	"fmt"
+	"io"
+	"io/ioutil"
 	"net/http"
 
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 
 	fv1 "github.com/fission/fission/pkg/apis/fission.io/v1"
+	ferror "github.com/fission/fission/pkg/error"
 	"github.com/fission/fission/pkg/redis/build/gen"
 )
 
@@ -39,7 +41,7 @@ func (c *Client) RecorderCreate(r *fv1.Recorder) (*metav1.ObjectMeta, error) {
 		return nil, err
 	}
 
-	resp, err := http.Post(c.url("recorders"), "application/json", bytes.NewReader(reqbody))
+	resp, err := c.post("recorders", "application/json", reqbody)
 	if err != nil {
 		return nil, err
 	}
@@ -236,3 +238,53 @@ func (c *Client) RecordsByTime(from string, to string) ([]*redisCache.RecordedEn
 
 	return records, nil
 }
+
+// RecordsExport writes all records to w as newline-delimited JSON.
+func (c *Client) RecordsExport(w io.Writer) error {
+	resp, err := http.Get(c.url("records/export"))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != 200 {
+		return ferror.MakeErrorFromHTTP(resp)
+	}
+
+	_, err = io.Copy(w, resp.Body)
+	return err
+}
+
+// RecordsImport stores the newline-delimited JSON records read from r,
+// adding them to the given recorder if it isn't empty, and returns the
+// number of records imported.
+func (c *Client) RecordsImport(r io.Reader, recorder string) (int, error) {
+	reqbody, err := ioutil.ReadAll(r)
+	if err != nil {
+		return 0, err
+	}
+
+	relativeUrl := "records/import"
+	if len(recorder) > 0 {
+		relativeUrl += fmt.Sprintf("?recorder=%v", recorder)
+	}
+
+	resp, err := c.post(relativeUrl, "application/x-ndjson", reqbody)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+
+	body, err := c.handleResponse(resp)
+	if err != nil {
+		return 0, err
+	}
+
+	result := make(map[string]int)
+	err = json.Unmarshal(body, &result)
+	if err != nil {
+		return 0, err
+	}
+
+	return result["imported"], nil
+}
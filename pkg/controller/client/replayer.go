@@ -21,8 +21,13 @@ import (
 	"net/http"
 )
 
-func (c *Client) ReplayByReqUID(reqUID string) ([]string, error) {
+// ReplayByReqUID replays a recorded request. If targetFunction isn't empty,
+// the request is sent to that function instead of its recorded path.
+func (c *Client) ReplayByReqUID(reqUID string, targetFunction string, targetNamespace string) ([]string, error) {
 	relativeUrl := fmt.Sprintf("replay/%v", reqUID)
+	if len(targetFunction) > 0 {
+		relativeUrl += fmt.Sprintf("?function=%v&namespace=%v", targetFunction, targetNamespace)
+	}
 
 	resp, err := http.Get(c.url(relativeUrl))
 	if err != nil {
@@ -17,7 +17,6 @@ limitations under the License.
 package client
 
 import (
-	"bytes"
 	"encoding/json"
 	"fmt"
 	"net/http"
@@ -38,7 +37,7 @@ func (c *Client) TimeTriggerCreate(t *fv1.TimeTrigger) (*metav1.ObjectMeta, erro
 		return nil, err
 	}
 
-	resp, err := http.Post(c.url("triggers/time"), "application/json", bytes.NewReader(reqbody))
+	resp, err := c.post("triggers/time", "application/json", reqbody)
 	if err != nil {
 		return nil, err
 	}
@@ -120,23 +119,28 @@ func (c *Client) TimeTriggerDelete(m *metav1.ObjectMeta) error {
 }
 
 func (c *Client) TimeTriggerList(ns string) ([]fv1.TimeTrigger, error) {
-	relativeUrl := fmt.Sprintf("triggers/time?namespace=%v", ns)
-	resp, err := http.Get(c.url(relativeUrl))
-	if err != nil {
-		return nil, err
-	}
-	defer resp.Body.Close()
+	return c.TimeTriggerListWithOptions(ns, ListOptions{})
+}
 
-	body, err := c.handleResponse(resp)
+func (c *Client) TimeTriggerListWithOptions(ns string, opts ListOptions) ([]fv1.TimeTrigger, error) {
+	triggers := make([]fv1.TimeTrigger, 0)
+	err := listAll(opts, func(opts ListOptions) (string, error) {
+		page, cont, err := c.TimeTriggerListPage(ns, opts)
+		triggers = append(triggers, page...)
+		return cont, err
+	})
 	if err != nil {
 		return nil, err
 	}
+	return triggers, nil
+}
 
+func (c *Client) TimeTriggerListPage(ns string, opts ListOptions) ([]fv1.TimeTrigger, string, error) {
+	relativeUrl := fmt.Sprintf("triggers/time?namespace=%v", ns)
 	triggers := make([]fv1.TimeTrigger, 0)
-	err = json.Unmarshal(body, &triggers)
+	cont, err := c.list(relativeUrl, opts, &triggers)
 	if err != nil {
-		return nil, err
+		return nil, "", err
 	}
-
-	return triggers, nil
+	return triggers, cont, nil
 }
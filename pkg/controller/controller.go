@@ -53,5 +53,6 @@ func Start(logger *zap.Logger, port int, unitTestFlag bool) {
 	if err != nil {
 		cLogger.Fatal("failed to start controller", zap.Error(err))
 	}
+	go serveMetric(cLogger)
 	api.Serve(port)
 }
@@ -105,19 +105,19 @@ func (a *API) EnvironmentApiList(w http.ResponseWriter, r *http.Request) {
 		ns = metav1.NamespaceAll
 	}
 
-	envs, err := a.fissionClient.Environments(ns).List(metav1.ListOptions{})
+	opts, err := a.listOptionsFromRequest(r)
 	if err != nil {
 		a.respondWithError(w, err)
 		return
 	}
 
-	resp, err := json.Marshal(envs.Items)
+	envs, err := a.fissionClient.Environments(ns).List(opts)
 	if err != nil {
 		a.respondWithError(w, err)
 		return
 	}
 
-	a.respondWithSuccess(w, resp)
+	a.respondWithList(w, envs.Items, envs.Metadata.Continue)
 }
 
 func (a *API) EnvironmentApiCreate(w http.ResponseWriter, r *http.Request) {
@@ -135,6 +135,12 @@ func (a *API) EnvironmentApiCreate(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	err = a.policyChecker.check(r.Context(), policyOperationCreate, "Environment", &env.Metadata, &env)
+	if err != nil {
+		a.respondWithError(w, err)
+		return
+	}
+
 	// check if namespace exists, if not create it.
 	err = a.createNsIfNotExists(env.Metadata.Namespace)
 	if err != nil {
@@ -205,6 +211,12 @@ func (a *API) EnvironmentApiUpdate(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	err = a.policyChecker.check(r.Context(), policyOperationUpdate, "Environment", &env.Metadata, &env)
+	if err != nil {
+		a.respondWithError(w, err)
+		return
+	}
+
 	enew, err := a.fissionClient.Environments(env.Metadata.Namespace).Update(&env)
 	if err != nil {
 		a.respondWithError(w, err)
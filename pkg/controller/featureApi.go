@@ -0,0 +1,76 @@
+/*
+Copyright 2018 The Fission Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"encoding/json"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+
+	config "github.com/fission/fission/pkg/featureconfig"
+	"github.com/fission/fission/pkg/types"
+)
+
+// FeatureApiGet returns the optional features and components installed
+// along with the controller. The CLI checks them before creating resources
+// that can't work without them, rather than failing later on the server.
+func (a *API) FeatureApiGet(w http.ResponseWriter, r *http.Request) {
+	features := types.FeatureInfo{
+		LogDB:    getLogDBInfo(),
+		Canary:   a.isCanaryEnabled(),
+		Recorder: true,
+	}
+	// installs not setting these can't tell, the CLI doesn't check them
+	if mqTypes, ok := os.LookupEnv("MESSAGE_QUEUE_TYPES"); ok {
+		features.MessageQueueTypes = getMessageQueueTypes(mqTypes)
+	}
+	if recorder, ok := os.LookupEnv("RECORDER_ENABLED"); ok {
+		features.Recorder, _ = strconv.ParseBool(recorder)
+	}
+
+	resp, err := json.Marshal(features)
+	if err != nil {
+		a.respondWithError(w, err)
+		return
+	}
+	a.respondWithSuccess(w, resp)
+}
+
+// isCanaryEnabled returns true if the canary feature is enabled and its
+// config manager started.
+func (a *API) isCanaryEnabled() bool {
+	featureConfig, err := config.GetFeatureConfig()
+	if err != nil || !featureConfig.CanaryConfig.IsEnabled {
+		return false
+	}
+	return len(a.featureStatus[config.CanaryFeature]) == 0
+}
+
+// getMessageQueueTypes parses the comma separated message queue types
+// with a trigger deployed, as set by the chart.
+func getMessageQueueTypes(value string) []string {
+	mqTypes := make([]string, 0)
+	for _, mqType := range strings.Split(value, ",") {
+		mqType = strings.TrimSpace(mqType)
+		if len(mqType) > 0 {
+			mqTypes = append(mqTypes, mqType)
+		}
+	}
+	return mqTypes
+}
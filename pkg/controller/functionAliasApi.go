@@ -0,0 +1,247 @@
+/*
+Copyright 2019 The Fission Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+
+	"github.com/emicklei/go-restful"
+	restfulspec "github.com/emicklei/go-restful-openapi"
+	"github.com/go-openapi/spec"
+	"github.com/gorilla/mux"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	fv1 "github.com/fission/fission/pkg/apis/fission.io/v1"
+	ferror "github.com/fission/fission/pkg/error"
+)
+
+func RegisterFunctionAliasRoute(ws *restful.WebService) {
+	tags := []string{"FunctionAlias"}
+	specTag = append(specTag, spec.Tag{TagProps: spec.TagProps{Name: "FunctionAlias", Description: "FunctionAlias Operation"}})
+
+	ws.Route(
+		ws.GET("/v2/aliases").
+			Doc("List all function aliases").
+			Metadata(restfulspec.KeyOpenAPITags, tags).
+			To(func(req *restful.Request, resp *restful.Response) {
+				resp.ResponseWriter.WriteHeader(http.StatusOK)
+			}).
+			Param(ws.QueryParameter("namespace", "Namespace of functionAlias").DataType("string").DefaultValue(metav1.NamespaceAll).Required(false)).
+			Produces(restful.MIME_JSON).
+			Writes([]fv1.FunctionAlias{}).
+			Returns(http.StatusOK, "List of functionAliases", []fv1.FunctionAlias{}))
+
+	ws.Route(
+		ws.POST("/v2/aliases").
+			Doc("Create function alias").
+			Metadata(restfulspec.KeyOpenAPITags, tags).
+			To(func(req *restful.Request, resp *restful.Response) {
+				resp.ResponseWriter.WriteHeader(http.StatusOK)
+			}).
+			Produces(restful.MIME_JSON).
+			Reads(fv1.FunctionAlias{}).
+			Writes(metav1.ObjectMeta{}).
+			Returns(http.StatusCreated, "Metadata of created functionAlias", metav1.ObjectMeta{}))
+
+	ws.Route(
+		ws.GET("/v2/aliases/{alias}").
+			Doc("Get detail of function alias").
+			Metadata(restfulspec.KeyOpenAPITags, tags).
+			To(func(req *restful.Request, resp *restful.Response) {
+				resp.ResponseWriter.WriteHeader(http.StatusOK)
+			}).
+			Param(ws.PathParameter("alias", "FunctionAlias name").DataType("string").DefaultValue("").Required(true)).
+			Param(ws.QueryParameter("namespace", "Namespace of functionAlias").DataType("string").DefaultValue(metav1.NamespaceAll).Required(false)).
+			Produces(restful.MIME_JSON).
+			Writes(fv1.FunctionAlias{}). // on the response
+			Returns(http.StatusOK, "A functionAlias", fv1.FunctionAlias{}))
+
+	ws.Route(
+		ws.PUT("/v2/aliases/{alias}").
+			Doc("Update function alias").
+			Metadata(restfulspec.KeyOpenAPITags, tags).
+			To(func(req *restful.Request, resp *restful.Response) {
+				resp.ResponseWriter.WriteHeader(http.StatusOK)
+			}).
+			Param(ws.PathParameter("alias", "FunctionAlias name").DataType("string").DefaultValue("").Required(true)).
+			Produces(restful.MIME_JSON).
+			Reads(fv1.FunctionAlias{}).
+			Writes(metav1.ObjectMeta{}). // on the response
+			Returns(http.StatusOK, "Metadata of updated functionAlias", metav1.ObjectMeta{}))
+
+	ws.Route(
+		ws.DELETE("/v2/aliases/{alias}").
+			Doc("Delete function alias").
+			Metadata(restfulspec.KeyOpenAPITags, tags).
+			To(func(req *restful.Request, resp *restful.Response) {
+				resp.ResponseWriter.WriteHeader(http.StatusOK)
+			}).
+			Param(ws.PathParameter("alias", "FunctionAlias name").DataType("string").DefaultValue("").Required(true)).
+			Param(ws.QueryParameter("namespace", "Namespace of functionAlias").DataType("string").DefaultValue(metav1.NamespaceAll).Required(false)).
+			Produces(restful.MIME_JSON).
+			Returns(http.StatusOK, "Only HTTP status returned", nil))
+}
+
+func (a *API) FunctionAliasApiList(w http.ResponseWriter, r *http.Request) {
+	ns := a.extractQueryParamFromRequest(r, "namespace")
+	if len(ns) == 0 {
+		ns = metav1.NamespaceAll
+	}
+
+	opts, err := a.listOptionsFromRequest(r)
+	if err != nil {
+		a.respondWithError(w, err)
+		return
+	}
+
+	aliases, err := a.fissionClient.FunctionAliases(ns).List(opts)
+	if err != nil {
+		a.respondWithError(w, err)
+		return
+	}
+
+	a.respondWithList(w, aliases.Items, aliases.Metadata.Continue)
+}
+
+func (a *API) FunctionAliasApiCreate(w http.ResponseWriter, r *http.Request) {
+	body, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		a.respondWithError(w, err)
+		return
+	}
+
+	var alias fv1.FunctionAlias
+	err = json.Unmarshal(body, &alias)
+	if err != nil {
+		a.respondWithError(w, err)
+		return
+	}
+
+	err = a.policyChecker.check(r.Context(), policyOperationCreate, "FunctionAlias", &alias.Metadata, &alias)
+	if err != nil {
+		a.respondWithError(w, err)
+		return
+	}
+
+	// check if namespace exists, if not create it.
+	err = a.createNsIfNotExists(alias.Metadata.Namespace)
+	if err != nil {
+		a.respondWithError(w, err)
+		return
+	}
+
+	aliasNew, err := a.fissionClient.FunctionAliases(alias.Metadata.Namespace).Create(&alias)
+	if err != nil {
+		a.respondWithError(w, err)
+		return
+	}
+
+	resp, err := json.Marshal(aliasNew.Metadata)
+	if err != nil {
+		a.respondWithError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusCreated)
+	a.respondWithSuccess(w, resp)
+}
+
+func (a *API) FunctionAliasApiGet(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	name := vars["alias"]
+	ns := a.extractQueryParamFromRequest(r, "namespace")
+	if len(ns) == 0 {
+		ns = metav1.NamespaceDefault
+	}
+
+	alias, err := a.fissionClient.FunctionAliases(ns).Get(name)
+	if err != nil {
+		a.respondWithError(w, err)
+		return
+	}
+
+	resp, err := json.Marshal(alias)
+	if err != nil {
+		a.respondWithError(w, err)
+		return
+	}
+
+	a.respondWithSuccess(w, resp)
+}
+
+func (a *API) FunctionAliasApiUpdate(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	name := vars["alias"]
+
+	body, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		a.respondWithError(w, err)
+		return
+	}
+
+	var alias fv1.FunctionAlias
+	err = json.Unmarshal(body, &alias)
+	if err != nil {
+		a.respondWithError(w, err)
+		return
+	}
+
+	if name != alias.Metadata.Name {
+		err = ferror.MakeError(ferror.ErrorInvalidArgument, "FunctionAlias name doesn't match URL")
+		a.respondWithError(w, err)
+		return
+	}
+
+	err = a.policyChecker.check(r.Context(), policyOperationUpdate, "FunctionAlias", &alias.Metadata, &alias)
+	if err != nil {
+		a.respondWithError(w, err)
+		return
+	}
+
+	aliasNew, err := a.fissionClient.FunctionAliases(alias.Metadata.Namespace).Update(&alias)
+	if err != nil {
+		a.respondWithError(w, err)
+		return
+	}
+
+	resp, err := json.Marshal(aliasNew.Metadata)
+	if err != nil {
+		a.respondWithError(w, err)
+		return
+	}
+
+	a.respondWithSuccess(w, resp)
+}
+
+func (a *API) FunctionAliasApiDelete(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	name := vars["alias"]
+	ns := a.extractQueryParamFromRequest(r, "namespace")
+	if len(ns) == 0 {
+		ns = metav1.NamespaceDefault
+	}
+
+	err := a.fissionClient.FunctionAliases(ns).Delete(name, &metav1.DeleteOptions{})
+	if err != nil {
+		a.respondWithError(w, err)
+		return
+	}
+
+	a.respondWithSuccess(w, []byte(""))
+}
@@ -18,13 +18,18 @@ package controller
 
 import (
 	"encoding/json"
-	"errors"
+	"fmt"
 	"io"
 	"io/ioutil"
+	"math"
 	"net/http"
 	"net/http/httputil"
 	"net/url"
+	"os"
+	"path"
 	"sort"
+	"strings"
+	"time"
 
 	"github.com/emicklei/go-restful"
 	restfulspec "github.com/emicklei/go-restful-openapi"
@@ -33,12 +38,18 @@ import (
 	"go.uber.org/zap"
 	apiv1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
-	restclient "k8s.io/client-go/rest"
 
 	fv1 "github.com/fission/fission/pkg/apis/fission.io/v1"
+	"github.com/fission/fission/pkg/canaryconfigmgr"
 	ferror "github.com/fission/fission/pkg/error"
+	"github.com/fission/fission/pkg/fission-cli/logdb"
+	"github.com/fission/fission/pkg/types"
 )
 
+// functionMetricsSteps is the number of intervals of the invocation series
+// of function metrics.
+const functionMetricsSteps = 20
+
 func RegisterFunctionRoute(ws *restful.WebService) {
 	tags := []string{"Function"}
 	specTag = append(specTag, spec.Tag{TagProps: spec.TagProps{Name: "Function", Description: "Function Operation"}})
@@ -104,6 +115,48 @@ func RegisterFunctionRoute(ws *restful.WebService) {
 			Param(ws.QueryParameter("namespace", "Namespace of function").DataType("string").DefaultValue(metav1.NamespaceAll).Required(false)).
 			Produces(restful.MIME_JSON).
 			Returns(http.StatusOK, "Only HTTP status returned", nil))
+
+	ws.Route(
+		ws.POST("/v2/functions/{function}/profile").
+			Doc("Capture a profile of the running pods of function").
+			Metadata(restfulspec.KeyOpenAPITags, tags).
+			To(func(req *restful.Request, resp *restful.Response) {
+				resp.ResponseWriter.WriteHeader(http.StatusOK)
+			}).
+			Param(ws.PathParameter("function", "Function name").DataType("string").DefaultValue("").Required(true)).
+			Param(ws.QueryParameter("namespace", "Namespace of function").DataType("string").DefaultValue(metav1.NamespaceAll).Required(false)).
+			Produces(restful.MIME_JSON).
+			Reads(types.FunctionProfileRequest{}).
+			Writes(types.FunctionProfileResponse{}). // on the response
+			Returns(http.StatusOK, "Profiles of function pods", types.FunctionProfileResponse{}))
+
+	ws.Route(
+		ws.GET("/v2/functions/{function}/metrics").
+			Doc("Get a summary of the invocations of function from Prometheus").
+			Metadata(restfulspec.KeyOpenAPITags, tags).
+			To(func(req *restful.Request, resp *restful.Response) {
+				resp.ResponseWriter.WriteHeader(http.StatusOK)
+			}).
+			Param(ws.PathParameter("function", "Function name").DataType("string").DefaultValue("").Required(true)).
+			Param(ws.QueryParameter("namespace", "Namespace of function").DataType("string").DefaultValue(metav1.NamespaceAll).Required(false)).
+			Param(ws.QueryParameter("since", "Time window of the summary, e.g. 1h").DataType("string").DefaultValue("1h").Required(false)).
+			Produces(restful.MIME_JSON).
+			Writes(types.FunctionMetrics{}). // on the response
+			Returns(http.StatusOK, "Metrics of function", types.FunctionMetrics{}))
+
+	ws.Route(
+		ws.GET("/v2/functions/{function}/stats").
+			Doc("Get the invocations, cold starts and latency of function recorded by the routers").
+			Metadata(restfulspec.KeyOpenAPITags, tags).
+			To(func(req *restful.Request, resp *restful.Response) {
+				resp.ResponseWriter.WriteHeader(http.StatusOK)
+			}).
+			Param(ws.PathParameter("function", "Function name").DataType("string").DefaultValue("").Required(true)).
+			Param(ws.QueryParameter("namespace", "Namespace of function").DataType("string").DefaultValue(metav1.NamespaceAll).Required(false)).
+			Param(ws.QueryParameter("since", "Time window of the stats, e.g. 1h, at most 24h").DataType("string").DefaultValue("1h").Required(false)).
+			Produces(restful.MIME_JSON).
+			Writes(types.FunctionStats{}). // on the response
+			Returns(http.StatusOK, "Stats of function", types.FunctionStats{}))
 }
 
 func (a *API) getIstioServiceLabels(fnName string) map[string]string {
@@ -118,19 +171,19 @@ func (a *API) FunctionApiList(w http.ResponseWriter, r *http.Request) {
 		ns = metav1.NamespaceAll
 	}
 
-	funcs, err := a.fissionClient.Functions(ns).List(metav1.ListOptions{})
+	opts, err := a.listOptionsFromRequest(r)
 	if err != nil {
 		a.respondWithError(w, err)
 		return
 	}
 
-	resp, err := json.Marshal(funcs.Items)
+	funcs, err := a.fissionClient.Functions(ns).List(opts)
 	if err != nil {
 		a.respondWithError(w, err)
 		return
 	}
 
-	a.respondWithSuccess(w, resp)
+	a.respondWithList(w, funcs.Items, funcs.Metadata.Continue)
 }
 
 func (a *API) FunctionApiCreate(w http.ResponseWriter, r *http.Request) {
@@ -147,6 +200,12 @@ func (a *API) FunctionApiCreate(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	err = a.policyChecker.check(r.Context(), policyOperationCreate, "Function", &f.Metadata, &f)
+	if err != nil {
+		a.respondWithError(w, err)
+		return
+	}
+
 	// check if namespace exists, if not create it.
 	err = a.createNsIfNotExists(f.Metadata.Namespace)
 	if err != nil {
@@ -215,12 +274,27 @@ func (a *API) FunctionApiUpdate(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	err = a.policyChecker.check(r.Context(), policyOperationUpdate, "Function", &f.Metadata, &f)
+	if err != nil {
+		a.respondWithError(w, err)
+		return
+	}
+
+	canary := a.startAutoCanary(&f)
+
 	fnew, err := a.fissionClient.Functions(f.Metadata.Namespace).Update(&f)
 	if err != nil {
+		if canary != nil {
+			a.abortAutoCanary(canary)
+		}
 		a.respondWithError(w, err)
 		return
 	}
 
+	if canary != nil {
+		a.finishAutoCanary(canary, fnew)
+	}
+
 	resp, err := json.Marshal(fnew.Metadata)
 	if err != nil {
 		a.respondWithError(w, err)
@@ -249,12 +323,33 @@ func (a *API) FunctionApiDelete(w http.ResponseWriter, r *http.Request) {
 // FunctionLogsApiPost establishes a proxy server to log database, and redirect
 // query command send from client to database then proxy back the db response.
 func (a *API) FunctionLogsApiPost(w http.ResponseWriter, r *http.Request) {
+	a.proxyLogDB(w, r, "")
+}
+
+// FunctionLogsWriteApiPost proxies the writes of the log forwarder sidecars
+// of function pods to the write API of the log database, the sidecars don't
+// have the credentials of the database.
+func (a *API) FunctionLogsWriteApiPost(w http.ResponseWriter, r *http.Request) {
+	a.proxyLogDB(w, r, "write")
+}
+
+// proxyLogDB proxies a request to the log database, to the endpoint of the
+// database URL or to apiPath next to it.
+func (a *API) proxyLogDB(w http.ResponseWriter, r *http.Request, apiPath string) {
 	vars := mux.Vars(r)
 	// get dbType from url
 	dbType := vars["dbType"]
+	if _, ok := logdb.GetBackend(dbType); !ok {
+		a.respondWithError(w, ferror.MakeError(ferror.ErrorNotFound, fmt.Sprintf("log database type %q is not supported", dbType)))
+		return
+	}
 
 	// find correspond db http url
 	dbCnf := a.getLogDBConfig(dbType)
+	if len(dbCnf.httpURL) == 0 {
+		a.respondWithError(w, ferror.MakeError(ferror.ErrorNotFound, fmt.Sprintf("log database %q isn't proxied, set %s_URL", dbType, strings.ToUpper(dbType))))
+		return
+	}
 
 	svcUrl, err := url.Parse(dbCnf.httpURL)
 	if err != nil {
@@ -269,6 +364,9 @@ func (a *API) FunctionLogsApiPost(w http.ResponseWriter, r *http.Request) {
 		req.URL.Scheme = svcUrl.Scheme
 		req.URL.Host = svcUrl.Host
 		req.URL.Path = svcUrl.Path
+		if len(apiPath) > 0 {
+			req.URL.Path = path.Join(path.Dir(svcUrl.Path), apiPath)
+		}
 		// set up http basic auth for database authentication
 		req.SetBasicAuth(dbCnf.username, dbCnf.password)
 	}
@@ -278,14 +376,62 @@ func (a *API) FunctionLogsApiPost(w http.ResponseWriter, r *http.Request) {
 	proxy.ServeHTTP(w, r)
 }
 
-// FunctionPodLogs : Get logs for a function directly from pod
+// LogDBApiList lists the types of log databases function logs can be
+// queried from.
+func (a *API) LogDBApiList(w http.ResponseWriter, r *http.Request) {
+	resp, err := json.Marshal(getLogDBInfo())
+	if err != nil {
+		a.respondWithError(w, err)
+		return
+	}
+	a.respondWithSuccess(w, resp)
+}
+
+// getLogDBInfo returns the types of log databases function logs can be
+// queried from. Kubernetes is always there, the other types if their URL
+// is set or they are the default.
+func getLogDBInfo() types.LogDBInfo {
+	info := types.LogDBInfo{
+		Default: os.Getenv("LOGDB_DEFAULT_TYPE"),
+		Types:   make([]string, 0),
+	}
+	if len(info.Default) == 0 {
+		info.Default = logdb.INFLUXDB
+	}
+	for _, dbType := range logdb.Types() {
+		if dbType == logdb.KUBERNETES || dbType == info.Default ||
+			len(os.Getenv(fmt.Sprintf("%s_URL", strings.ToUpper(dbType)))) > 0 {
+			info.Types = append(info.Types, dbType)
+		}
+	}
+	return info
+}
+
+// FunctionPodLogs : Get logs for a function directly from pod. The logs of
+// all the pods of the function are returned, newest pod first, unless the
+// pod query param picks one. With previous=true the logs of the terminated
+// containers of the pods are returned instead, so that crash looping
+// functions can be debugged. With timestamps=true every line is prefixed
+// with its RFC3339 timestamp, since drops the lines older than the
+// RFC3339 time given.
 func (a *API) FunctionPodLogs(w http.ResponseWriter, r *http.Request) {
 	vars := mux.Vars(r)
 	fnName := vars["function"]
-	ns := vars["namespace"]
-
+	ns := a.extractQueryParamFromRequest(r, "namespace")
 	if len(ns) == 0 {
-		ns = "fission-function"
+		ns = metav1.NamespaceDefault
+	}
+	podName := a.extractQueryParamFromRequest(r, "pod")
+	previous := a.extractQueryParamFromRequest(r, "previous") == "true"
+	timestamps := a.extractQueryParamFromRequest(r, "timestamps") == "true"
+	var sinceTime *metav1.Time
+	if since := a.extractQueryParamFromRequest(r, "since"); len(since) > 0 {
+		t, err := time.Parse(time.RFC3339Nano, since)
+		if err != nil {
+			a.respondWithError(w, ferror.MakeError(ferror.ErrorInvalidArgument, fmt.Sprintf("invalid since time %q: %v", since, err)))
+			return
+		}
+		sinceTime = &metav1.Time{Time: t}
 	}
 
 	f, err := a.fissionClient.Functions(ns).Get(fnName)
@@ -293,49 +439,210 @@ func (a *API) FunctionPodLogs(w http.ResponseWriter, r *http.Request) {
 		a.respondWithError(w, err)
 		return
 	}
-	envName := f.Spec.Environment.Name
 
+	// Get function Pods first. Pods of newdeploy functions live in the
+	// function namespace and the ones of poolmgr in the env pool namespace,
+	// the function UID label is on both.
+	selector := types.FUNCTION_UID + "=" + string(f.Metadata.UID)
+	podList, err := a.kubernetesClient.CoreV1().Pods(metav1.NamespaceAll).List(metav1.ListOptions{LabelSelector: selector})
 	if err != nil {
 		a.respondWithError(w, err)
 		return
 	}
 
-	// Get function Pods first
-	selector := "functionName=" + fnName
-	podList, err := a.kubernetesClient.CoreV1().Pods(ns).List(metav1.ListOptions{LabelSelector: selector})
-	if err != nil {
-		a.respondWithError(w, err)
+	pods := make([]apiv1.Pod, 0, len(podList.Items))
+	for _, pod := range podList.Items {
+		if len(podName) > 0 && pod.ObjectMeta.Name != podName {
+			continue
+		}
+		if previous && !hasTerminatedContainer(&pod, functionContainerName(f, &pod)) {
+			continue
+		}
+		pods = append(pods, pod)
+	}
+	if len(pods) == 0 {
+		if previous {
+			a.respondWithError(w, ferror.MakeError(ferror.ErrorNotFound, "no pods with terminated containers found"))
+		} else {
+			a.respondWithError(w, ferror.MakeError(ferror.ErrorNotFound, "no active pods found"))
+		}
 		return
 	}
-
-	// Get the logs for last Pod executed
-	pods := podList.Items
 	sort.Slice(pods, func(i, j int) bool {
 		itime := pods[i].ObjectMeta.CreationTimestamp.Time
 		jtime := pods[j].ObjectMeta.CreationTimestamp.Time
 		return itime.After(jtime)
 	})
 
-	podLogOpts := apiv1.PodLogOptions{Container: envName} // Only the env container, not fetcher
-	var podLogsReq *restclient.Request
-	if len(pods) > 0 {
-		podLogsReq = a.kubernetesClient.CoreV1().Pods(ns).GetLogs(pods[0].ObjectMeta.Name, &podLogOpts)
-	} else {
-		a.respondWithError(w, errors.New("No active pods found"))
+	for _, pod := range pods {
+		if len(pods) > 1 {
+			fmt.Fprintf(w, "=== Pod %v ===\n", pod.ObjectMeta.Name)
+		}
+
+		// Only the function container, not fetcher
+		podLogOpts := apiv1.PodLogOptions{
+			Container:  functionContainerName(f, &pod),
+			Previous:   previous,
+			Timestamps: timestamps,
+			SinceTime:  sinceTime,
+		}
+		podLogs, err := a.kubernetesClient.CoreV1().Pods(pod.ObjectMeta.Namespace).GetLogs(pod.ObjectMeta.Name, &podLogOpts).Stream()
+		if err != nil {
+			if len(pods) == 1 {
+				a.respondWithError(w, err)
+				return
+			}
+			fmt.Fprintf(w, "error getting logs: %v\n", err)
+			continue
+		}
+		_, err = io.Copy(w, podLogs)
+		podLogs.Close()
+		if err != nil {
+			a.logger.Error("error copying pod logs", zap.Error(err), zap.String("pod", pod.ObjectMeta.Name))
+			return
+		}
+	}
+}
+
+// functionContainerName returns the name of the container running the
+// function in a function pod.
+func functionContainerName(f *fv1.Function, pod *apiv1.Pod) string {
+	if pod.ObjectMeta.Labels[types.EXECUTOR_TYPE] == fv1.ExecutorTypeNewdeploy {
+		return f.Metadata.Name
+	}
+	return f.Spec.Environment.Name
+}
+
+// hasTerminatedContainer returns whether the container of the pod has
+// been restarted, i.e. has logs of a previous instance.
+func hasTerminatedContainer(pod *apiv1.Pod, container string) bool {
+	for _, status := range pod.Status.ContainerStatuses {
+		if status.Name == container {
+			return status.RestartCount > 0 || status.LastTerminationState.Terminated != nil
+		}
+	}
+	return false
+}
+
+// FunctionProfile captures a profile of the running pods of a function through the executor.
+func (a *API) FunctionProfile(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	name := vars["function"]
+	ns := a.extractQueryParamFromRequest(r, "namespace")
+	if len(ns) == 0 {
+		ns = metav1.NamespaceDefault
+	}
+
+	body, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		a.respondWithError(w, err)
+		return
+	}
+
+	var req types.FunctionProfileRequest
+	err = json.Unmarshal(body, &req)
+	if err != nil {
+		a.respondWithError(w, ferror.MakeError(ferror.ErrorInvalidArgument, "failed to parse profile request"))
+		return
+	}
+	req.Function = metav1.ObjectMeta{Name: name, Namespace: ns}
+
+	profiles, err := a.executor.ProfileFunction(r.Context(), &req)
+	if err != nil {
+		a.respondWithError(w, err)
 		return
 	}
 
-	podLogs, err := podLogsReq.Stream()
+	resp, err := json.Marshal(profiles)
 	if err != nil {
 		a.respondWithError(w, err)
 		return
 	}
-	defer podLogs.Close()
+	a.respondWithSuccess(w, resp)
+}
+
+// FunctionMetrics summarizes the invocations of a function over a time
+// window from the metrics the router and executor export to Prometheus.
+func (a *API) FunctionMetrics(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	name := vars["function"]
+	ns := a.extractQueryParamFromRequest(r, "namespace")
+	if len(ns) == 0 {
+		ns = metav1.NamespaceDefault
+	}
 
-	_, err = io.Copy(w, podLogs)
+	window := time.Hour
+	if since := a.extractQueryParamFromRequest(r, "since"); len(since) > 0 {
+		var err error
+		window, err = time.ParseDuration(since)
+		if err != nil || window < functionMetricsSteps*time.Second {
+			a.respondWithError(w, ferror.MakeError(ferror.ErrorInvalidArgument,
+				fmt.Sprintf("invalid time window %q, it must be at least %v", since, functionMetricsSteps*time.Second)))
+			return
+		}
+	}
+
+	if len(a.prometheusUrl) == 0 {
+		a.respondWithError(w, ferror.MakeError(ferror.ErrorNotImplmented, "function metrics are not available, PROMETHEUS_URL of the controller is not set"))
+		return
+	}
+
+	f, err := a.fissionClient.Functions(ns).Get(name)
 	if err != nil {
 		a.respondWithError(w, err)
 		return
 	}
-	return
+
+	promClient, err := canaryconfigmgr.MakePrometheusClient(a.logger, a.prometheusUrl)
+	if err != nil {
+		a.respondWithError(w, err)
+		return
+	}
+
+	rangeStr := fmt.Sprintf("%ds", int(window.Seconds()))
+	labels := fmt.Sprintf("name=%q,namespace=%q", name, ns)
+	metrics := types.FunctionMetrics{Window: window}
+	queries := []struct {
+		value *float64
+		query string
+	}{
+		{&metrics.Invocations, fmt.Sprintf("sum(increase(fission_function_calls_total{%v}[%v]))", labels, rangeStr)},
+		{&metrics.Errors, fmt.Sprintf("sum(increase(fission_function_errors_total{%v}[%v]))", labels, rangeStr)},
+		{&metrics.ColdStarts, fmt.Sprintf("sum(increase(fission_cold_starts_total{funcuid=%q}[%v]))", f.Metadata.UID, rangeStr)},
+		// quantiles of summaries can't be aggregated, the worst ones of
+		// the router instances are reported
+		{&metrics.LatencyP50, fmt.Sprintf("max(max_over_time(fission_function_duration_seconds{%v,quantile=\"0.5\"}[%v]))", labels, rangeStr)},
+		{&metrics.LatencyP90, fmt.Sprintf("max(max_over_time(fission_function_duration_seconds{%v,quantile=\"0.9\"}[%v]))", labels, rangeStr)},
+		{&metrics.LatencyP95, fmt.Sprintf("max(max_over_time(fission_function_duration_seconds{%v,quantile=\"0.95\"}[%v]))", labels, rangeStr)},
+		{&metrics.LatencyP99, fmt.Sprintf("max(max_over_time(fission_function_duration_seconds{%v,quantile=\"0.99\"}[%v]))", labels, rangeStr)},
+	}
+	for _, q := range queries {
+		val, found, err := promClient.EvaluateQuery(q.query)
+		if err != nil {
+			a.respondWithError(w, err)
+			return
+		}
+		if found && !math.IsNaN(val) {
+			*q.value = val
+		}
+	}
+
+	step := window / functionMetricsSteps
+	end := time.Now()
+	series, err := promClient.EvaluateRangeQuery(
+		fmt.Sprintf("sum(increase(fission_function_calls_total{%v}[%ds]))", labels, int(step.Seconds())),
+		end.Add(-window+step), end, step)
+	if err != nil {
+		// the summary is still useful without the series
+		a.logger.Error("error querying invocation series", zap.Error(err), zap.String("function", name))
+	} else {
+		metrics.InvocationSeries = series
+	}
+
+	resp, err := json.Marshal(metrics)
+	if err != nil {
+		a.respondWithError(w, err)
+		return
+	}
+	a.respondWithSuccess(w, resp)
 }
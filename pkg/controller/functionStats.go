@@ -0,0 +1,222 @@
+/*
+Copyright 2019 The Fission Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sync"
+	"time"
+
+	"github.com/gorilla/mux"
+	"go.uber.org/zap"
+	apiv1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	ferror "github.com/fission/fission/pkg/error"
+	"github.com/fission/fission/pkg/types"
+)
+
+const (
+	// functionStatsMaxWindow is how long the routers keep function stats.
+	functionStatsMaxWindow = 24 * time.Hour
+
+	// routerSelector selects the router pods, they serve the function
+	// stats on their metrics port.
+	routerSelector   = "application=fission-router"
+	routerMetricPort = 8080
+)
+
+// FunctionStats collects the invocations, cold starts and latency of a
+// function from each router and sums them up.
+func (a *API) FunctionStats(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	name := vars["function"]
+	ns := a.extractQueryParamFromRequest(r, "namespace")
+	if len(ns) == 0 {
+		ns = metav1.NamespaceDefault
+	}
+
+	window := time.Hour
+	if since := a.extractQueryParamFromRequest(r, "since"); len(since) > 0 {
+		var err error
+		window, err = time.ParseDuration(since)
+		if err != nil || window <= 0 || window > functionStatsMaxWindow {
+			a.respondWithError(w, ferror.MakeError(ferror.ErrorInvalidArgument,
+				fmt.Sprintf("invalid time window %q, it must be positive and at most %v", since, functionStatsMaxWindow)))
+			return
+		}
+	}
+
+	_, err := a.fissionClient.Functions(ns).Get(name)
+	if err != nil {
+		a.respondWithError(w, err)
+		return
+	}
+
+	podList, err := a.kubernetesClient.CoreV1().Pods(podNamespace).List(metav1.ListOptions{LabelSelector: routerSelector})
+	if err != nil {
+		a.respondWithError(w, err)
+		return
+	}
+
+	query := url.Values{}
+	query.Set("namespace", ns)
+	query.Set("name", name)
+	query.Set("since", window.String())
+
+	ctx, cancel := context.WithTimeout(r.Context(), 10*time.Second)
+	defer cancel()
+
+	var lock sync.Mutex
+	var wg sync.WaitGroup
+	var counts []*types.FunctionStatsCounts
+	stats := types.FunctionStats{Window: window}
+	for _, pod := range podList.Items {
+		if pod.Status.Phase != apiv1.PodRunning || len(pod.Status.PodIP) == 0 {
+			continue
+		}
+		wg.Add(1)
+		go func(pod apiv1.Pod) {
+			defer wg.Done()
+			c, err := getRouterFunctionStats(ctx, fmt.Sprintf("http://%v:%v/stats/functions?%v", pod.Status.PodIP, routerMetricPort, query.Encode()))
+			lock.Lock()
+			defer lock.Unlock()
+			if err != nil {
+				a.logger.Error("error getting function stats from router",
+					zap.Error(err),
+					zap.String("pod", pod.Name),
+					zap.String("function", name))
+				stats.Unreachable++
+				return
+			}
+			counts = append(counts, c)
+		}(pod)
+	}
+	wg.Wait()
+
+	if len(counts) == 0 && stats.Unreachable > 0 {
+		a.respondWithError(w, ferror.MakeError(ferror.ErrorInternal, "no router could be queried for function stats"))
+		return
+	}
+	sumFunctionStats(&stats, counts)
+
+	resp, err := json.Marshal(stats)
+	if err != nil {
+		a.respondWithError(w, err)
+		return
+	}
+	a.respondWithSuccess(w, resp)
+}
+
+func getRouterFunctionStats(ctx context.Context, statsUrl string) (*types.FunctionStatsCounts, error) {
+	req, err := http.NewRequest(http.MethodGet, statsUrl, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := http.DefaultClient.Do(req.WithContext(ctx))
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, ferror.MakeErrorFromHTTP(resp)
+	}
+
+	counts := &types.FunctionStatsCounts{}
+	err = json.NewDecoder(resp.Body).Decode(counts)
+	if err != nil {
+		return nil, err
+	}
+	return counts, nil
+}
+
+// sumFunctionStats adds up the counts of the routers, the latency quantiles
+// are computed from the sum of their latency buckets.
+func sumFunctionStats(stats *types.FunctionStats, counts []*types.FunctionStatsCounts) {
+	var bounds []float64
+	var buckets []uint64
+	for _, c := range counts {
+		stats.Routers++
+		stats.Invocations += c.Invocations
+		stats.Errors += c.Errors
+		stats.ColdStarts += c.ColdStarts
+
+		// routers of different versions may not share the buckets, the
+		// latency of the ones not matching the first is left out
+		if bounds == nil {
+			bounds = c.LatencyBounds
+			buckets = make([]uint64, len(bounds)+1)
+		}
+		if !equalBounds(bounds, c.LatencyBounds) || len(c.LatencyCounts) != len(buckets) {
+			continue
+		}
+		for i, n := range c.LatencyCounts {
+			buckets[i] += n
+		}
+	}
+
+	stats.LatencyP50 = latencyQuantile(0.5, bounds, buckets)
+	stats.LatencyP95 = latencyQuantile(0.95, bounds, buckets)
+	stats.LatencyP99 = latencyQuantile(0.99, bounds, buckets)
+}
+
+func equalBounds(a, b []float64) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// latencyQuantile interpolates the q quantile of the latency linearly within
+// the bucket it falls into, the same way Prometheus does for histograms.
+// Latencies past the last bound are reported as the last bound.
+func latencyQuantile(q float64, bounds []float64, buckets []uint64) float64 {
+	var total uint64
+	for _, n := range buckets {
+		total += n
+	}
+	if total == 0 || len(bounds) == 0 {
+		return 0
+	}
+
+	rank := q * float64(total)
+	var cumulative uint64
+	for i, n := range buckets {
+		if n == 0 || float64(cumulative+n) < rank {
+			cumulative += n
+			continue
+		}
+		if i == len(bounds) {
+			return bounds[len(bounds)-1]
+		}
+		lower := 0.0
+		if i > 0 {
+			lower = bounds[i-1]
+		}
+		return lower + (bounds[i]-lower)*(rank-float64(cumulative))/float64(n)
+	}
+	return bounds[len(bounds)-1]
+}
@@ -0,0 +1,56 @@
+/*
+Copyright 2019 The Fission Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"testing"
+
+	tassert "github.com/stretchr/testify/assert"
+
+	"github.com/fission/fission/pkg/types"
+)
+
+func TestSumFunctionStats(t *testing.T) {
+	bounds := []float64{0.1, 0.2, 0.4}
+	counts := []*types.FunctionStatsCounts{
+		{Invocations: 60, Errors: 1, ColdStarts: 2, LatencyBounds: bounds, LatencyCounts: []uint64{50, 10, 0, 0}},
+		{Invocations: 40, Errors: 2, ColdStarts: 1, LatencyBounds: bounds, LatencyCounts: []uint64{0, 30, 8, 2}},
+		// a router with other buckets still counts
+		{Invocations: 5, LatencyBounds: []float64{1}, LatencyCounts: []uint64{5, 0}},
+	}
+
+	stats := types.FunctionStats{}
+	sumFunctionStats(&stats, counts)
+	tassert.Equal(t, 3, stats.Routers)
+	tassert.Equal(t, uint64(105), stats.Invocations)
+	tassert.Equal(t, uint64(3), stats.Errors)
+	tassert.Equal(t, uint64(3), stats.ColdStarts)
+
+	// 100 latencies: 50 up to 0.1s, 40 up to 0.2s, 8 up to 0.4s, 2 slower
+	tassert.InDelta(t, 0.1, stats.LatencyP50, 1e-9)
+	tassert.InDelta(t, 0.325, stats.LatencyP95, 1e-9)
+	tassert.InDelta(t, 0.4, stats.LatencyP99, 1e-9)
+}
+
+func TestLatencyQuantile(t *testing.T) {
+	bounds := []float64{1, 2}
+	tassert.Equal(t, float64(0), latencyQuantile(0.5, bounds, []uint64{0, 0, 0}))
+	tassert.Equal(t, float64(0), latencyQuantile(0.5, nil, nil))
+	tassert.InDelta(t, 0.5, latencyQuantile(0.5, bounds, []uint64{4, 0, 0}), 1e-9)
+	tassert.InDelta(t, 1.5, latencyQuantile(0.5, bounds, []uint64{0, 4, 0}), 1e-9)
+	tassert.Equal(t, float64(2), latencyQuantile(0.5, bounds, []uint64{0, 0, 4}))
+}
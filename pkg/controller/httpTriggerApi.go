@@ -105,19 +105,29 @@ func (a *API) HTTPTriggerApiList(w http.ResponseWriter, r *http.Request) {
 		ns = metav1.NamespaceAll
 	}
 
-	triggers, err := a.fissionClient.HTTPTriggers(ns).List(metav1.ListOptions{})
+	opts, err := a.listOptionsFromRequest(r)
 	if err != nil {
 		a.respondWithError(w, err)
 		return
 	}
 
-	resp, err := json.Marshal(triggers.Items)
+	triggers, err := a.fissionClient.HTTPTriggers(ns).List(opts)
 	if err != nil {
 		a.respondWithError(w, err)
 		return
 	}
 
-	a.respondWithSuccess(w, resp)
+	a.respondWithList(w, triggers.Items, triggers.Metadata.Continue)
+}
+
+// methodsOverlap returns whether two HTTP triggers share a method.
+func methodsOverlap(a, b fv1.HTTPTriggerSpec) bool {
+	for _, method := range a.GetMethods() {
+		if method == fv1.HTTPMethodAny || b.HasMethod(method) {
+			return true
+		}
+	}
+	return false
 }
 
 // checkHTTPTriggerDuplicates checks whether the tuple (Method, Host, URL) is duplicate or not.
@@ -131,7 +141,7 @@ func (a *API) checkHTTPTriggerDuplicates(t *fv1.HTTPTrigger) error {
 			// Same resource. No need to check.
 			continue
 		}
-		if ht.Spec.RelativeURL == t.Spec.RelativeURL && ht.Spec.Method == t.Spec.Method && ht.Spec.Host == t.Spec.Host {
+		if ht.Spec.GetPath() == t.Spec.GetPath() && methodsOverlap(ht.Spec, t.Spec) && ht.Spec.Host == t.Spec.Host {
 			return ferror.MakeError(ferror.ErrorNameExists,
 				fmt.Sprintf("HTTPTrigger with same Host, URL & method already exists (%v)",
 					ht.Metadata.Name))
@@ -161,6 +171,12 @@ func (a *API) HTTPTriggerApiCreate(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	err = a.policyChecker.check(r.Context(), policyOperationCreate, "HTTPTrigger", &t.Metadata, &t)
+	if err != nil {
+		a.respondWithError(w, err)
+		return
+	}
+
 	// check if namespace exists, if not create it.
 	err = a.createNsIfNotExists(t.Metadata.Namespace)
 	if err != nil {
@@ -236,6 +252,12 @@ func (a *API) HTTPTriggerApiUpdate(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	err = a.policyChecker.check(r.Context(), policyOperationUpdate, "HTTPTrigger", &t.Metadata, &t)
+	if err != nil {
+		a.respondWithError(w, err)
+		return
+	}
+
 	tnew, err := a.fissionClient.HTTPTriggers(t.Metadata.Namespace).Update(&t)
 	if err != nil {
 		a.respondWithError(w, err)
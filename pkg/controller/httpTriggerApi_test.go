@@ -0,0 +1,41 @@
+/*
+Copyright 2019 The Fission Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"testing"
+
+	tassert "github.com/stretchr/testify/assert"
+
+	fv1 "github.com/fission/fission/pkg/apis/fission.io/v1"
+)
+
+func TestMethodsOverlap(t *testing.T) {
+	get := fv1.HTTPTriggerSpec{Method: "GET"}
+	post := fv1.HTTPTriggerSpec{Method: "POST"}
+	getPost := fv1.HTTPTriggerSpec{Methods: []string{"GET", "POST"}}
+	putDelete := fv1.HTTPTriggerSpec{Methods: []string{"PUT", "DELETE"}}
+	anyMethod := fv1.HTTPTriggerSpec{Methods: []string{fv1.HTTPMethodAny}}
+
+	tassert.True(t, methodsOverlap(get, get))
+	tassert.False(t, methodsOverlap(get, post))
+	tassert.True(t, methodsOverlap(get, getPost))
+	tassert.True(t, methodsOverlap(getPost, post))
+	tassert.False(t, methodsOverlap(getPost, putDelete))
+	tassert.True(t, methodsOverlap(anyMethod, putDelete))
+	tassert.True(t, methodsOverlap(post, anyMethod))
+}
@@ -0,0 +1,112 @@
+/*
+Copyright 2019 The Fission Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"go.uber.org/zap"
+
+	"github.com/fission/fission/pkg/types"
+)
+
+// slowRequestThreshold is the duration beyond which successful reads are
+// logged along with the mutations and the failed requests.
+const slowRequestThreshold = time.Second
+
+var (
+	metricAddr = ":8080"
+
+	// API requests served by the controller
+	// path: the route of the request, e.g. /v2/functions/{function}
+	// method: the HTTP method
+	// code: the HTTP status code
+	apiRequests = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "fission_controller_requests_total",
+			Help: "Count of API requests served by the controller",
+		},
+		[]string{"path", "method", "code"},
+	)
+	apiRequestDuration = prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Name:    "fission_controller_request_duration_seconds",
+			Help:    "Duration of API requests served by the controller.",
+			Buckets: prometheus.DefBuckets,
+		},
+		[]string{"path", "method"},
+	)
+)
+
+func init() {
+	prometheus.MustRegister(apiRequests)
+	prometheus.MustRegister(apiRequestDuration)
+}
+
+func serveMetric(logger *zap.Logger) {
+	// Expose the registered metrics via HTTP.
+	http.Handle("/metrics", promhttp.Handler())
+	err := http.ListenAndServe(metricAddr, nil)
+
+	logger.Fatal("done listening on metrics endpoint", zap.Error(err))
+}
+
+// requestMiddleware records the metrics of the API requests and logs them.
+// Mutations, failed requests and slow reads are logged at info level, other
+// requests at debug level, so that slow CLI operations can be traced to the
+// API calls behind them.
+func (api *API) requestMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		path := r.URL.Path
+		if route := mux.CurrentRoute(r); route != nil {
+			if tmpl, err := route.GetPathTemplate(); err == nil {
+				path = tmpl
+			}
+		}
+
+		start := time.Now()
+		sr := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+		next.ServeHTTP(sr, r)
+		duration := time.Since(start)
+
+		apiRequests.WithLabelValues(path, r.Method, strconv.Itoa(sr.status)).Inc()
+		apiRequestDuration.WithLabelValues(path, r.Method).Observe(duration.Seconds())
+
+		// probes are too frequent to log
+		if path == "/healthz" || path == "/readyz" {
+			return
+		}
+		logFunc := api.logger.Debug
+		if r.Method != http.MethodGet || sr.status >= http.StatusBadRequest || duration > slowRequestThreshold {
+			logFunc = api.logger.Info
+		}
+		logFunc("api request",
+			zap.String("method", r.Method),
+			zap.String("path", r.URL.Path),
+			zap.String("route", path),
+			zap.Int("status", sr.status),
+			zap.Duration("duration", duration),
+			zap.String("remote_addr", r.RemoteAddr),
+			zap.String("user", r.Header.Get(types.AuditUserHeader)),
+			zap.String("user_agent", r.UserAgent()))
+	})
+}
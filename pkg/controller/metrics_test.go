@@ -0,0 +1,46 @@
+/*
+Copyright 2019 The Fission Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gorilla/mux"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	tassert "github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+)
+
+func TestRequestMiddleware(t *testing.T) {
+	api := &API{logger: zap.NewNop()}
+	r := mux.NewRouter()
+	r.HandleFunc("/v2/functions/{function}", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+	}).Methods("GET")
+	r.Use(api.requestMiddleware)
+
+	counter := apiRequests.WithLabelValues("/v2/functions/{function}", "GET", "404")
+	before := testutil.ToFloat64(counter)
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest("GET", "/v2/functions/foo", nil))
+	tassert.Equal(t, http.StatusNotFound, w.Code)
+	// requests are counted by route, not by the name of the resource
+	tassert.Equal(t, before+1, testutil.ToFloat64(counter))
+}
@@ -105,17 +105,19 @@ func (a *API) MessageQueueTriggerApiList(w http.ResponseWriter, r *http.Request)
 		ns = metav1.NamespaceAll
 	}
 
-	triggers, err := a.fissionClient.MessageQueueTriggers(ns).List(metav1.ListOptions{})
+	opts, err := a.listOptionsFromRequest(r)
 	if err != nil {
 		a.respondWithError(w, err)
 		return
 	}
-	resp, err := json.Marshal(triggers.Items)
+
+	triggers, err := a.fissionClient.MessageQueueTriggers(ns).List(opts)
 	if err != nil {
 		a.respondWithError(w, err)
 		return
 	}
-	a.respondWithSuccess(w, resp)
+
+	a.respondWithList(w, triggers.Items, triggers.Metadata.Continue)
 }
 
 func (a *API) MessageQueueTriggerApiCreate(w http.ResponseWriter, r *http.Request) {
@@ -132,6 +134,12 @@ func (a *API) MessageQueueTriggerApiCreate(w http.ResponseWriter, r *http.Reques
 		return
 	}
 
+	err = a.policyChecker.check(r.Context(), policyOperationCreate, "MessageQueueTrigger", &mqTrigger.Metadata, &mqTrigger)
+	if err != nil {
+		a.respondWithError(w, err)
+		return
+	}
+
 	// check if namespace exists, if not create it.
 	err = a.createNsIfNotExists(mqTrigger.Metadata.Namespace)
 	if err != nil {
@@ -198,6 +206,12 @@ func (a *API) MessageQueueTriggerApiUpdate(w http.ResponseWriter, r *http.Reques
 		return
 	}
 
+	err = a.policyChecker.check(r.Context(), policyOperationUpdate, "MessageQueueTrigger", &mqTrigger.Metadata, &mqTrigger)
+	if err != nil {
+		a.respondWithError(w, err)
+		return
+	}
+
 	tnew, err := a.fissionClient.MessageQueueTriggers(mqTrigger.Metadata.Namespace).Update(&mqTrigger)
 	if err != nil {
 		a.respondWithError(w, err)
@@ -51,6 +51,11 @@ func openAPIWebService() *restful.WebService {
 	RegisterWatchRoute(ws)
 	RegisterTimeTriggerRoute(ws)
 	RegisterCanaryConfigRoute(ws)
+	RegisterFunctionAliasRoute(ws)
+	RegisterCanaryPolicyRoute(ws)
+
+	// archive
+	RegisterArchiveRoute(ws)
 
 	// proxy
 	RegisterStorageServiceProxyRoute(ws)
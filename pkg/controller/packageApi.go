@@ -17,23 +17,35 @@ limitations under the License.
 package controller
 
 import (
+	"encoding/base64"
 	"encoding/json"
 	"fmt"
-	"io/ioutil"
 	"net/http"
+	"strings"
+	"time"
 
 	"github.com/dustin/go-humanize"
 	"github.com/emicklei/go-restful"
 	restfulspec "github.com/emicklei/go-restful-openapi"
-	"github.com/fission/fission/pkg/types"
 	"github.com/go-openapi/spec"
 	"github.com/gorilla/mux"
+	apiv1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 
 	fv1 "github.com/fission/fission/pkg/apis/fission.io/v1"
 	ferror "github.com/fission/fission/pkg/error"
 )
 
+const (
+	// buildLogsPollInterval is how often the status of a package is checked
+	// while its build logs are streamed.
+	buildLogsPollInterval = 2 * time.Second
+
+	// packageMetadataMaxSize is the room left in package requests for
+	// everything but the literals.
+	packageMetadataMaxSize = 64 * 1024
+)
+
 func RegisterPackageRoute(ws *restful.WebService) {
 	tags := []string{"Package"}
 	specTag = append(specTag, spec.Tag{TagProps: spec.TagProps{Name: "Package", Description: "Package Operation"}})
@@ -106,45 +118,59 @@ func (a *API) PackageApiList(w http.ResponseWriter, r *http.Request) {
 	if len(ns) == 0 {
 		ns = metav1.NamespaceAll
 	}
-	funcs, err := a.fissionClient.Packages(ns).List(metav1.ListOptions{})
+	opts, err := a.listOptionsFromRequest(r)
 	if err != nil {
 		a.respondWithError(w, err)
 		return
 	}
 
-	resp, err := json.Marshal(funcs.Items)
+	funcs, err := a.fissionClient.Packages(ns).List(opts)
 	if err != nil {
 		a.respondWithError(w, err)
 		return
 	}
 
-	a.respondWithSuccess(w, resp)
+	a.respondWithList(w, funcs.Items, funcs.Metadata.Continue)
 }
 
-func (a *API) PackageApiCreate(w http.ResponseWriter, r *http.Request) {
-	body, err := ioutil.ReadAll(r.Body)
-	if err != nil {
-		a.respondWithError(w, err)
-		return
+// maxPackageBodySize returns the max size of a package request, two literals
+// at the size limit once base64 encoded in JSON.
+func (a *API) maxPackageBodySize() int64 {
+	return 2*int64(base64.StdEncoding.EncodedLen(int(a.archiveLiteralSizeLimit))) + packageMetadataMaxSize
+}
+
+// decodePackage streams the package from the request body. Oversized
+// requests are rejected before being read in full.
+func (a *API) decodePackage(w http.ResponseWriter, r *http.Request) (*fv1.Package, error) {
+	maxBodySize := a.maxPackageBodySize()
+	if r.ContentLength > maxBodySize {
+		return nil, ferror.MakeError(ferror.ErrorSizeLimitExceeded,
+			fmt.Sprintf("Package literal larger than %s", humanize.Bytes(uint64(a.archiveLiteralSizeLimit))))
 	}
 
 	var f fv1.Package
-	err = json.Unmarshal(body, &f)
+	err := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxBodySize)).Decode(&f)
 	if err != nil {
-		a.respondWithError(w, err)
-		return
+		if strings.Contains(err.Error(), "request body too large") {
+			err = ferror.MakeError(ferror.ErrorSizeLimitExceeded,
+				fmt.Sprintf("Package literal larger than %s", humanize.Bytes(uint64(a.archiveLiteralSizeLimit))))
+		}
+		return nil, err
 	}
 
 	// Ensure size limits
-	if len(f.Spec.Source.Literal) > int(types.ArchiveLiteralSizeLimit) {
-		err := ferror.MakeError(ferror.ErrorInvalidArgument,
-			fmt.Sprintf("Package literal larger than %s", humanize.Bytes(uint64(types.ArchiveLiteralSizeLimit))))
-		a.respondWithError(w, err)
-		return
+	for _, literal := range [][]byte{f.Spec.Source.Literal, f.Spec.Deployment.Literal} {
+		if int64(len(literal)) > a.archiveLiteralSizeLimit {
+			return nil, ferror.MakeError(ferror.ErrorInvalidArgument,
+				fmt.Sprintf("Package literal larger than %s", humanize.Bytes(uint64(a.archiveLiteralSizeLimit))))
+		}
 	}
-	if len(f.Spec.Deployment.Literal) > int(types.ArchiveLiteralSizeLimit) {
-		err := ferror.MakeError(ferror.ErrorInvalidArgument,
-			fmt.Sprintf("Package literal larger than %s", humanize.Bytes(uint64(types.ArchiveLiteralSizeLimit))))
+	return &f, nil
+}
+
+func (a *API) PackageApiCreate(w http.ResponseWriter, r *http.Request) {
+	f, err := a.decodePackage(w, r)
+	if err != nil {
 		a.respondWithError(w, err)
 		return
 	}
@@ -156,7 +182,7 @@ func (a *API) PackageApiCreate(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	fnew, err := a.fissionClient.Packages(f.Metadata.Namespace).Create(&f)
+	fnew, err := a.fissionClient.Packages(f.Metadata.Namespace).Create(f)
 	if err != nil {
 		a.respondWithError(w, err)
 		return
@@ -204,14 +230,7 @@ func (a *API) PackageApiUpdate(w http.ResponseWriter, r *http.Request) {
 	vars := mux.Vars(r)
 	name := vars["package"]
 
-	body, err := ioutil.ReadAll(r.Body)
-	if err != nil {
-		a.respondWithError(w, err)
-		return
-	}
-
-	var f fv1.Package
-	err = json.Unmarshal(body, &f)
+	f, err := a.decodePackage(w, r)
 	if err != nil {
 		a.respondWithError(w, err)
 		return
@@ -223,7 +242,9 @@ func (a *API) PackageApiUpdate(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	fnew, err := a.fissionClient.Packages(f.Metadata.Namespace).Update(&f)
+	a.snapshotPackage(f)
+
+	fnew, err := a.fissionClient.Packages(f.Metadata.Namespace).Update(f)
 	if err != nil {
 		a.respondWithError(w, err)
 		return
@@ -253,3 +274,84 @@ func (a *API) PackageApiDelete(w http.ResponseWriter, r *http.Request) {
 
 	a.respondWithSuccess(w, []byte(""))
 }
+
+// PackageApiBuildLogs streams the logs of the builder pod running the build
+// of a package, until the build is over or moves to another pod.
+func (a *API) PackageApiBuildLogs(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	name := vars["package"]
+	ns := a.extractQueryParamFromRequest(r, "namespace")
+	if len(ns) == 0 {
+		ns = metav1.NamespaceDefault
+	}
+
+	pkg, err := a.fissionClient.Packages(ns).Get(name)
+	if err != nil {
+		a.respondWithError(w, err)
+		return
+	}
+	buildPod := pkg.Status.BuildPod
+	if pkg.Status.BuildStatus != fv1.BuildStatusRunning || buildPod == nil {
+		a.respondWithError(w, ferror.MakeError(ferror.ErrorNotFound,
+			fmt.Sprintf("package %v isn't being built, status: %v", name, pkg.Status.BuildStatus)))
+		return
+	}
+
+	podLogs, err := a.kubernetesClient.CoreV1().Pods(buildPod.Namespace).GetLogs(buildPod.Name, &apiv1.PodLogOptions{
+		Container: "builder",
+		Follow:    true,
+		SinceTime: &metav1.Time{Time: buildPod.StartTimestamp},
+	}).Stream()
+	if err != nil {
+		a.respondWithError(w, err)
+		return
+	}
+	defer podLogs.Close()
+
+	// The builder pod keeps running after the build, so the log stream is
+	// closed once the package leaves the build. The stream is kept for one
+	// more interval for the last lines of the build.
+	done := make(chan struct{})
+	defer close(done)
+	go func() {
+		ticker := time.NewTicker(buildLogsPollInterval)
+		defer ticker.Stop()
+		finished := false
+		for {
+			select {
+			case <-done:
+				return
+			case <-r.Context().Done():
+				podLogs.Close()
+				return
+			case <-ticker.C:
+				if finished {
+					podLogs.Close()
+					return
+				}
+				p, err := a.fissionClient.Packages(ns).Get(name)
+				finished = err != nil || p.Status.BuildStatus != fv1.BuildStatusRunning ||
+					p.Status.BuildPod == nil || *p.Status.BuildPod != *buildPod
+			}
+		}
+	}()
+
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	w.WriteHeader(http.StatusOK)
+	flusher, _ := w.(http.Flusher)
+	buf := make([]byte, 32*1024)
+	for {
+		n, err := podLogs.Read(buf)
+		if n > 0 {
+			if _, werr := w.Write(buf[:n]); werr != nil {
+				return
+			}
+			if flusher != nil {
+				flusher.Flush()
+			}
+		}
+		if err != nil {
+			return
+		}
+	}
+}
@@ -0,0 +1,204 @@
+/*
+Copyright 2019 The Fission Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	ferror "github.com/fission/fission/pkg/error"
+)
+
+const (
+	policyOperationCreate = "CREATE"
+	policyOperationUpdate = "UPDATE"
+
+	defaultPolicyWebhookTimeout = 5 * time.Second
+)
+
+type (
+	// policyChecker asks an external policy engine whether an object may be
+	// persisted. The request and response follow the OPA data API, so the
+	// webhook URL can point to an OPA decision directly, e.g.
+	// http://opa:8181/v1/data/fission/admission.
+	policyChecker struct {
+		logger   *zap.Logger
+		url      string
+		client   *http.Client
+		failOpen bool
+	}
+
+	policyRequest struct {
+		Input policyInput `json:"input"`
+	}
+
+	// policyInput is available to policies as `input`.
+	policyInput struct {
+		Operation string      `json:"operation"`
+		Kind      string      `json:"kind"`
+		Namespace string      `json:"namespace"`
+		Name      string      `json:"name"`
+		Object    interface{} `json:"object"`
+	}
+
+	policyResponse struct {
+		Result *policyDecision `json:"result"`
+	}
+
+	// policyDecision is the result of the policy, e.g. in Rego:
+	//
+	//   allowed { count(reasons) == 0 }
+	//   reasons[msg] { ... }
+	policyDecision struct {
+		Allowed bool     `json:"allowed"`
+		Reasons []string `json:"reasons,omitempty"`
+	}
+)
+
+// makePolicyChecker returns a policy checker configured from the environment,
+// or nil if no policy webhook is set.
+func makePolicyChecker(logger *zap.Logger) *policyChecker {
+	u := os.Getenv("POLICY_WEBHOOK_URL")
+	if len(u) == 0 {
+		return nil
+	}
+
+	timeout := defaultPolicyWebhookTimeout
+	if s := os.Getenv("POLICY_WEBHOOK_TIMEOUT"); len(s) > 0 {
+		t, err := time.ParseDuration(s)
+		if err != nil {
+			logger.Error("failed to parse policy webhook timeout from 'POLICY_WEBHOOK_TIMEOUT' - set to the default value",
+				zap.Error(err),
+				zap.String("value", s),
+				zap.Duration("default", timeout))
+		} else {
+			timeout = t
+		}
+	}
+
+	var failOpen bool
+	if s := os.Getenv("POLICY_WEBHOOK_FAIL_OPEN"); len(s) > 0 {
+		b, err := strconv.ParseBool(s)
+		if err != nil {
+			logger.Error("failed to parse policy webhook fail open from 'POLICY_WEBHOOK_FAIL_OPEN' - set to the default value",
+				zap.Error(err),
+				zap.String("value", s),
+				zap.Bool("default", failOpen))
+		} else {
+			failOpen = b
+		}
+	}
+
+	logger.Info("policy webhook enabled",
+		zap.String("url", u),
+		zap.Duration("timeout", timeout),
+		zap.Bool("fail_open", failOpen))
+
+	return &policyChecker{
+		logger:   logger.Named("policy_checker"),
+		url:      u,
+		client:   &http.Client{Timeout: timeout},
+		failOpen: failOpen,
+	}
+}
+
+// check returns an error if the policy denies the operation on the object.
+// If the webhook can't be reached, the operation is denied unless the checker
+// fails open.
+func (pc *policyChecker) check(ctx context.Context, operation string, kind string, meta *metav1.ObjectMeta, obj interface{}) error {
+	if pc == nil {
+		return nil
+	}
+
+	logger := pc.logger.With(
+		zap.String("operation", operation),
+		zap.String("kind", kind),
+		zap.String("name", meta.Name),
+		zap.String("namespace", meta.Namespace))
+
+	decision, err := pc.query(ctx, policyInput{
+		Operation: operation,
+		Kind:      kind,
+		Namespace: meta.Namespace,
+		Name:      meta.Name,
+		Object:    obj,
+	})
+	if err != nil {
+		if pc.failOpen {
+			logger.Error("error querying policy webhook - allowing", zap.Error(err))
+			return nil
+		}
+		logger.Error("error querying policy webhook - denying", zap.Error(err))
+		return ferror.MakeError(ferror.ErrorInternal, fmt.Sprintf("error checking policy: %v", err))
+	}
+
+	if !decision.Allowed {
+		logger.Info("policy denied", zap.Strings("reasons", decision.Reasons))
+		msg := fmt.Sprintf("%v %v denied by policy", kind, meta.Name)
+		if len(decision.Reasons) > 0 {
+			msg = fmt.Sprintf("%v: %v", msg, strings.Join(decision.Reasons, "; "))
+		}
+		return ferror.MakeError(ferror.ErrorNotAuthorized, msg)
+	}
+
+	logger.Info("policy allowed")
+	return nil
+}
+
+func (pc *policyChecker) query(ctx context.Context, input policyInput) (*policyDecision, error) {
+	body, err := json.Marshal(policyRequest{Input: input})
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequest(http.MethodPost, pc.url, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := pc.client.Do(req.WithContext(ctx))
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, ferror.MakeErrorFromHTTP(resp)
+	}
+
+	var pr policyResponse
+	err = json.NewDecoder(resp.Body).Decode(&pr)
+	if err != nil {
+		return nil, fmt.Errorf("error decoding policy decision: %v", err)
+	}
+	// OPA returns no result if the policy is undefined
+	if pr.Result == nil {
+		return nil, fmt.Errorf("policy webhook returned no result")
+	}
+	return pr.Result, nil
+}
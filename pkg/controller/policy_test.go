@@ -0,0 +1,102 @@
+/*
+Copyright 2019 The Fission Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	tassert "github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	fv1 "github.com/fission/fission/pkg/apis/fission.io/v1"
+	ferror "github.com/fission/fission/pkg/error"
+)
+
+func TestPolicyChecker(t *testing.T) {
+	var input policyInput
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req policyRequest
+		err := json.NewDecoder(r.Body).Decode(&req)
+		tassert.Nil(t, err)
+		input = req.Input
+
+		// deny public routes
+		var ht fv1.HTTPTrigger
+		b, _ := json.Marshal(input.Object)
+		json.Unmarshal(b, &ht)
+		if ht.Spec.RelativeURL == "/public" {
+			w.Write([]byte(`{"result": {"allowed": false, "reasons": ["public routes are not allowed"]}}`))
+			return
+		}
+		w.Write([]byte(`{"result": {"allowed": true}}`))
+	}))
+	defer ts.Close()
+
+	pc := &policyChecker{
+		logger: zap.NewNop(),
+		url:    ts.URL,
+		client: &http.Client{Timeout: time.Second},
+	}
+
+	ht := &fv1.HTTPTrigger{
+		Metadata: metav1.ObjectMeta{Name: "foo", Namespace: "default"},
+		Spec:     fv1.HTTPTriggerSpec{RelativeURL: "/foo"},
+	}
+	err := pc.check(context.Background(), policyOperationCreate, "HTTPTrigger", &ht.Metadata, ht)
+	tassert.Nil(t, err)
+	tassert.Equal(t, policyOperationCreate, input.Operation)
+	tassert.Equal(t, "HTTPTrigger", input.Kind)
+	tassert.Equal(t, "foo", input.Name)
+	tassert.Equal(t, "default", input.Namespace)
+
+	ht.Spec.RelativeURL = "/public"
+	err = pc.check(context.Background(), policyOperationUpdate, "HTTPTrigger", &ht.Metadata, ht)
+	tassert.NotNil(t, err)
+	fe, ok := err.(ferror.Error)
+	tassert.True(t, ok)
+	tassert.Equal(t, http.StatusForbidden, fe.HTTPStatus())
+	tassert.Contains(t, fe.Message, "public routes are not allowed")
+
+	// a nil checker allows everything
+	var nilChecker *policyChecker
+	tassert.Nil(t, nilChecker.check(context.Background(), policyOperationCreate, "HTTPTrigger", &ht.Metadata, ht))
+}
+
+func TestPolicyCheckerUnreachable(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// undefined policy
+		w.Write([]byte(`{}`))
+	}))
+	defer ts.Close()
+
+	meta := &metav1.ObjectMeta{Name: "foo", Namespace: "default"}
+	pc := &policyChecker{
+		logger: zap.NewNop(),
+		url:    ts.URL,
+		client: &http.Client{Timeout: time.Second},
+	}
+	tassert.NotNil(t, pc.check(context.Background(), policyOperationCreate, "Function", meta, nil))
+
+	pc.failOpen = true
+	tassert.Nil(t, pc.check(context.Background(), policyOperationCreate, "Function", meta, nil))
+}
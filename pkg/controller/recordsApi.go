@@ -17,6 +17,8 @@ limitations under the License.
 package controller
 
 import (
+	"bytes"
+	"encoding/json"
 	"net/http"
 
 	"github.com/gorilla/mux"
@@ -93,3 +95,37 @@ func (a *API) RecordsApiFilterByTime(w http.ResponseWriter, r *http.Request) {
 	}
 	a.respondWithSuccess(w, resp)
 }
+
+// RecordsApiExport returns all records as newline-delimited JSON, which
+// RecordsApiImport accepts to restore them in another cluster.
+func (a *API) RecordsApiExport(w http.ResponseWriter, r *http.Request) {
+	var buf bytes.Buffer
+	_, err := redis.RecordsExport(a.logger.Named("redis"), &buf)
+	if err != nil {
+		a.respondWithError(w, err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/x-ndjson")
+	_, err = w.Write(buf.Bytes())
+	if err != nil {
+		a.respondWithError(w, err)
+	}
+}
+
+func (a *API) RecordsApiImport(w http.ResponseWriter, r *http.Request) {
+	defer r.Body.Close()
+	recorder := r.FormValue("recorder")
+
+	count, err := redis.RecordsImport(a.logger.Named("redis"), r.Body, recorder)
+	if err != nil {
+		a.respondWithError(w, err)
+		return
+	}
+
+	resp, err := json.Marshal(map[string]int{"imported": count})
+	if err != nil {
+		a.respondWithError(w, err)
+		return
+	}
+	a.respondWithSuccess(w, resp)
+}
@@ -21,8 +21,10 @@ import (
 	"net/http"
 
 	"github.com/gorilla/mux"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 
 	"github.com/fission/fission/pkg/redis"
+	"github.com/fission/fission/pkg/utils"
 )
 
 func (a *API) ReplayByReqUID(w http.ResponseWriter, r *http.Request) {
@@ -31,7 +33,17 @@ func (a *API) ReplayByReqUID(w http.ResponseWriter, r *http.Request) {
 
 	routerUrl := fmt.Sprintf("http://router.%v", podNamespace)
 
-	resp, err := redis.ReplayByReqUID(a.logger, routerUrl, queriedID)
+	// Replay against another function instead of the recorded path if asked to
+	var targetPath string
+	if fn := a.extractQueryParamFromRequest(r, "function"); len(fn) > 0 {
+		ns := a.extractQueryParamFromRequest(r, "namespace")
+		if len(ns) == 0 {
+			ns = metav1.NamespaceDefault
+		}
+		targetPath = utils.UrlForFunction(fn, ns)
+	}
+
+	resp, err := redis.ReplayByReqUID(a.logger, routerUrl, queriedID, targetPath)
 	if err != nil {
 		a.respondWithError(w, err)
 		return
@@ -33,13 +33,6 @@ func RegisterStorageServiceProxyRoute(ws *restful.WebService) {
 	specTag = append(specTag, spec.Tag{TagProps: spec.TagProps{Name: "StorageServiceProxy", Description: "StorageServiceProxy Operation"}})
 
 	// workaround as go-restful has to set HTTP method explicitly.
-	ws.Route(
-		ws.POST("/proxy/storage/v1/archive").
-			Doc("Create archive").
-			Metadata(restfulspec.KeyOpenAPITags, tags).
-			To(func(req *restful.Request, resp *restful.Response) {
-				resp.ResponseWriter.WriteHeader(http.StatusOK)
-			}))
 	ws.Route(
 		ws.GET("/proxy/storage/v1/archive").
 			Doc("Get archive").
@@ -47,13 +40,6 @@ func RegisterStorageServiceProxyRoute(ws *restful.WebService) {
 			To(func(req *restful.Request, resp *restful.Response) {
 				resp.ResponseWriter.WriteHeader(http.StatusOK)
 			}))
-	ws.Route(
-		ws.DELETE("/proxy/storage/v1/archive").
-			Doc("Delete archive").
-			Metadata(restfulspec.KeyOpenAPITags, tags).
-			To(func(req *restful.Request, resp *restful.Response) {
-				resp.ResponseWriter.WriteHeader(http.StatusOK)
-			}))
 }
 
 func (api *API) StorageServiceProxy(w http.ResponseWriter, r *http.Request) {
@@ -105,19 +105,19 @@ func (a *API) TimeTriggerApiList(w http.ResponseWriter, r *http.Request) {
 		ns = metav1.NamespaceAll
 	}
 
-	triggers, err := a.fissionClient.TimeTriggers(ns).List(metav1.ListOptions{})
+	opts, err := a.listOptionsFromRequest(r)
 	if err != nil {
 		a.respondWithError(w, err)
 		return
 	}
 
-	resp, err := json.Marshal(triggers.Items)
+	triggers, err := a.fissionClient.TimeTriggers(ns).List(opts)
 	if err != nil {
 		a.respondWithError(w, err)
 		return
 	}
 
-	a.respondWithSuccess(w, resp)
+	a.respondWithList(w, triggers.Items, triggers.Metadata.Continue)
 }
 
 func (a *API) TimeTriggerApiCreate(w http.ResponseWriter, r *http.Request) {
@@ -142,6 +142,12 @@ func (a *API) TimeTriggerApiCreate(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	err = a.policyChecker.check(r.Context(), policyOperationCreate, "TimeTrigger", &t.Metadata, &t)
+	if err != nil {
+		a.respondWithError(w, err)
+		return
+	}
+
 	// check if namespace exists, if not create it.
 	err = a.createNsIfNotExists(t.Metadata.Namespace)
 	if err != nil {
@@ -218,6 +224,12 @@ func (a *API) TimeTriggerApiUpdate(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	err = a.policyChecker.check(r.Context(), policyOperationUpdate, "TimeTrigger", &t.Metadata, &t)
+	if err != nil {
+		a.respondWithError(w, err)
+		return
+	}
+
 	tnew, err := a.fissionClient.TimeTriggers(t.Metadata.Namespace).Update(&t)
 	if err != nil {
 		a.respondWithError(w, err)
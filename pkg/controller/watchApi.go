@@ -104,19 +104,19 @@ func (a *API) WatchApiList(w http.ResponseWriter, r *http.Request) {
 		ns = metav1.NamespaceAll
 	}
 
-	watches, err := a.fissionClient.KubernetesWatchTriggers(ns).List(metav1.ListOptions{})
+	opts, err := a.listOptionsFromRequest(r)
 	if err != nil {
 		a.respondWithError(w, err)
 		return
 	}
 
-	resp, err := json.Marshal(watches.Items)
+	watches, err := a.fissionClient.KubernetesWatchTriggers(ns).List(opts)
 	if err != nil {
 		a.respondWithError(w, err)
 		return
 	}
 
-	a.respondWithSuccess(w, resp)
+	a.respondWithList(w, watches.Items, watches.Metadata.Continue)
 }
 
 func (a *API) WatchApiCreate(w http.ResponseWriter, r *http.Request) {
@@ -135,6 +135,12 @@ func (a *API) WatchApiCreate(w http.ResponseWriter, r *http.Request) {
 
 	// TODO check for duplicate watches
 	// TODO check for duplicate watches -> we probably wont need it?
+	err = a.policyChecker.check(r.Context(), policyOperationCreate, "KubernetesWatchTrigger", &watch.Metadata, &watch)
+	if err != nil {
+		a.respondWithError(w, err)
+		return
+	}
+
 	// check if namespace exists, if not create it.
 	err = a.createNsIfNotExists(watch.Metadata.Namespace)
 	if err != nil {
@@ -0,0 +1,122 @@
+/*
+Copyright 2019 The Fission Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package crd
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/watch"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/rest"
+
+	fv1 "github.com/fission/fission/pkg/apis/fission.io/v1"
+)
+
+type (
+	CanaryPolicyInterface interface {
+		Create(*fv1.CanaryPolicy) (*fv1.CanaryPolicy, error)
+		Get(name string) (*fv1.CanaryPolicy, error)
+		Update(*fv1.CanaryPolicy) (*fv1.CanaryPolicy, error)
+		Delete(name string, options *metav1.DeleteOptions) error
+		List(opts metav1.ListOptions) (*fv1.CanaryPolicyList, error)
+		Watch(opts metav1.ListOptions) (watch.Interface, error)
+	}
+
+	canaryPolicyClient struct {
+		client    *rest.RESTClient
+		namespace string
+	}
+)
+
+func MakeCanaryPolicyInterface(crdClient *rest.RESTClient, namespace string) CanaryPolicyInterface {
+	return &canaryPolicyClient{
+		client:    crdClient,
+		namespace: namespace,
+	}
+}
+
+func (c *canaryPolicyClient) Create(f *fv1.CanaryPolicy) (*fv1.CanaryPolicy, error) {
+	var result fv1.CanaryPolicy
+	err := c.client.Post().
+		Resource("canarypolicies").
+		Namespace(c.namespace).
+		Body(f).
+		Do().Into(&result)
+	if err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+func (c *canaryPolicyClient) Get(name string) (*fv1.CanaryPolicy, error) {
+	var result fv1.CanaryPolicy
+	err := c.client.Get().
+		Resource("canarypolicies").
+		Namespace(c.namespace).
+		Name(name).
+		Do().Into(&result)
+	if err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+func (c *canaryPolicyClient) Update(f *fv1.CanaryPolicy) (*fv1.CanaryPolicy, error) {
+	var result fv1.CanaryPolicy
+	err := c.client.Put().
+		Resource("canarypolicies").
+		Namespace(c.namespace).
+		Name(f.Metadata.Name).
+		Body(f).
+		Do().Into(&result)
+	if err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+func (c *canaryPolicyClient) Delete(name string, opts *metav1.DeleteOptions) error {
+	return c.client.Delete().
+		Namespace(c.namespace).
+		Resource("canarypolicies").
+		Name(name).
+		Body(opts).
+		Do().
+		Error()
+}
+
+func (c *canaryPolicyClient) List(opts metav1.ListOptions) (*fv1.CanaryPolicyList, error) {
+	var result fv1.CanaryPolicyList
+	err := c.client.Get().
+		Namespace(c.namespace).
+		Resource("canarypolicies").
+		VersionedParams(&opts, scheme.ParameterCodec).
+		Do().
+		Into(&result)
+	if err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+func (c *canaryPolicyClient) Watch(opts metav1.ListOptions) (watch.Interface, error) {
+	return c.client.Get().
+		Prefix("watch").
+		Namespace(c.namespace).
+		Resource("canarypolicies").
+		VersionedParams(&opts, scheme.ParameterCodec).
+		Watch()
+}
@@ -169,6 +169,20 @@ func configureClient(config *rest.Config) {
 				&metav1.ListOptions{},
 				&metav1.DeleteOptions{},
 			)
+			scheme.AddKnownTypes(
+				groupversion,
+				&fv1.FunctionAlias{},
+				&fv1.FunctionAliasList{},
+				&metav1.ListOptions{},
+				&metav1.DeleteOptions{},
+			)
+			scheme.AddKnownTypes(
+				groupversion,
+				&fv1.CanaryPolicy{},
+				&fv1.CanaryPolicyList{},
+				&metav1.ListOptions{},
+				&metav1.DeleteOptions{},
+			)
 			return nil
 		})
 	schemeBuilder.AddToScheme(scheme.Scheme)
@@ -233,9 +247,22 @@ func (fc *FissionClient) Packages(ns string) PackageInterface {
 func (fc *FissionClient) CanaryConfigs(ns string) CanaryConfigInterface {
 	return MakeCanaryConfigInterface(fc.crdClient, ns)
 }
+func (fc *FissionClient) FunctionAliases(ns string) FunctionAliasInterface {
+	return MakeFunctionAliasInterface(fc.crdClient, ns)
+}
+func (fc *FissionClient) CanaryPolicies(ns string) CanaryPolicyInterface {
+	return MakeCanaryPolicyInterface(fc.crdClient, ns)
+}
 func (fc *FissionClient) WaitForCRDs() error {
 	return waitForCRDs(fc.crdClient)
 }
+
+// CheckCRDs returns an error if the CRDs can't be accessed.
+func (fc *FissionClient) CheckCRDs() error {
+	fi := MakeFunctionInterface(fc.crdClient, metav1.NamespaceDefault)
+	_, err := fi.List(metav1.ListOptions{Limit: 1})
+	return err
+}
 func (fc *FissionClient) GetCrdClient() *rest.RESTClient {
 	return fc.crdClient
 }
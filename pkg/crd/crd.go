@@ -205,6 +205,38 @@ func EnsureFissionCRDs(logger *zap.Logger, clientset *apiextensionsclient.Client
 				},
 			},
 		},
+		// FunctionAlias: weighted reference to functions for triggers
+		{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: "functionaliases.fission.io",
+			},
+			Spec: apiextensionsv1beta1.CustomResourceDefinitionSpec{
+				Group:   crdGroupName,
+				Version: crdVersion,
+				Scope:   apiextensionsv1beta1.NamespaceScoped,
+				Names: apiextensionsv1beta1.CustomResourceDefinitionNames{
+					Kind:     "FunctionAlias",
+					Plural:   "functionaliases",
+					Singular: "functionalias",
+				},
+			},
+		},
+		// CanaryPolicy: canary deployment of updated functions of a namespace
+		{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: "canarypolicies.fission.io",
+			},
+			Spec: apiextensionsv1beta1.CustomResourceDefinitionSpec{
+				Group:   crdGroupName,
+				Version: crdVersion,
+				Scope:   apiextensionsv1beta1.NamespaceScoped,
+				Names: apiextensionsv1beta1.CustomResourceDefinitionNames{
+					Kind:     "CanaryPolicy",
+					Plural:   "canarypolicies",
+					Singular: "canarypolicy",
+				},
+			},
+		},
 	}
 	for _, crd := range crds {
 		err := ensureCRD(logger, clientset, &crd)
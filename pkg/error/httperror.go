@@ -58,6 +58,8 @@ func MakeErrorFromHTTP(resp *http.Response) error {
 		errCode = ErrorNameExists
 	case http.StatusRequestTimeout:
 		errCode = ErrorRequestTimeout
+	case http.StatusRequestEntityTooLarge:
+		errCode = ErrorSizeLimitExceeded
 	default:
 		errCode = ErrorInternal
 	}
@@ -83,6 +85,10 @@ func (err Error) HTTPStatus() int {
 		code = http.StatusNotFound
 	case ErrorNameExists:
 		code = http.StatusConflict
+	case ErrorSizeLimitExceeded:
+		code = http.StatusRequestEntityTooLarge
+	case ErrorRequestTimeout:
+		code = http.StatusRequestTimeout
 	default:
 		code = http.StatusInternalServerError
 	}
@@ -22,17 +22,33 @@ import (
 	"fmt"
 	"io/ioutil"
 	"net/http"
+	"strconv"
 	"strings"
+	"time"
 
 	"github.com/gorilla/mux"
 	"go.opencensus.io/plugin/ochttp"
+	"go.opencensus.io/trace"
 	"go.uber.org/zap"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 
 	ferror "github.com/fission/fission/pkg/error"
+	"github.com/fission/fission/pkg/executor/util"
+	"github.com/fission/fission/pkg/health"
+	"github.com/fission/fission/pkg/types"
+	"github.com/fission/fission/pkg/utils"
 )
 
 func (executor *Executor) getServiceForFunctionApi(w http.ResponseWriter, r *http.Request) {
+	executor.serveServiceForFunction(w, r, executor.getServiceForFunction)
+}
+
+func (executor *Executor) getIsolatedServiceForFunctionApi(w http.ResponseWriter, r *http.Request) {
+	executor.serveServiceForFunction(w, r, executor.getIsolatedServiceForFunction)
+}
+
+func (executor *Executor) serveServiceForFunction(w http.ResponseWriter, r *http.Request,
+	getService func(context.Context, *metav1.ObjectMeta) (string, bool, error)) {
 	body, err := ioutil.ReadAll(r.Body)
 	if err != nil {
 		http.Error(w, "Failed to read request", http.StatusInternalServerError)
@@ -47,7 +63,19 @@ func (executor *Executor) getServiceForFunctionApi(w http.ResponseWriter, r *htt
 		return
 	}
 
-	serviceName, err := executor.getServiceForFunction(r.Context(), &m)
+	ctx := r.Context()
+	if timeout := r.Header.Get(types.DeadlineTimeoutHeader); len(timeout) > 0 {
+		ms, err := strconv.ParseInt(timeout, 10, 64)
+		if err != nil {
+			http.Error(w, fmt.Sprintf("Failed to parse %v header", types.DeadlineTimeoutHeader), http.StatusBadRequest)
+			return
+		}
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, time.Duration(ms)*time.Millisecond)
+		defer cancel()
+	}
+
+	serviceName, coldStart, err := getService(ctx, &m)
 	if err != nil {
 		code, msg := ferror.GetHTTPError(err)
 		executor.logger.Error("error getting service for function",
@@ -58,6 +86,9 @@ func (executor *Executor) getServiceForFunctionApi(w http.ResponseWriter, r *htt
 		return
 	}
 
+	if coldStart {
+		w.Header().Set(types.ColdStartHeader, "true")
+	}
 	w.Write([]byte(serviceName))
 }
 
@@ -70,7 +101,15 @@ func (executor *Executor) getServiceForFunctionApi(w http.ResponseWriter, r *htt
 // stale addresses are not returned to the router.
 // To make it optimal, plan is to add an eager cache invalidator function that watches for pod deletion events and
 // invalidates the cache entry if the pod address was cached.
-func (executor *Executor) getServiceForFunction(ctx context.Context, m *metav1.ObjectMeta) (string, error) {
+// It also returns whether a new service was created for the function, i.e. whether it's a cold start.
+func (executor *Executor) getServiceForFunction(ctx context.Context, m *metav1.ObjectMeta) (string, bool, error) {
+	ctx, span := trace.StartSpan(ctx, "executor.getServiceForFunction")
+	defer span.End()
+	span.AddAttributes(utils.FunctionTraceAttributes(m.Name, m.Namespace)...)
+
+	// Replace the pods of a function still running the previous build of its package
+	executor.refreshIfStale(m)
+
 	// Check function -> svc cache
 	executor.logger.Debug("checking for cached function service",
 		zap.String("function_name", m.Name),
@@ -80,7 +119,8 @@ func (executor *Executor) getServiceForFunction(ctx context.Context, m *metav1.O
 	if err == nil {
 		if executor.isValidAddress(fsvc) {
 			// Cached, return svc address
-			return fsvc.Address, nil
+			span.AddAttributes(trace.BoolAttribute(utils.TraceAttrColdStart, false))
+			return fsvc.Address, false, nil
 		} else {
 			executor.logger.Debug("deleting cache entry for invalid address",
 				zap.String("function_name", m.Name),
@@ -90,6 +130,14 @@ func (executor *Executor) getServiceForFunction(ctx context.Context, m *metav1.O
 		}
 	}
 
+	span.AddAttributes(trace.BoolAttribute(utils.TraceAttrColdStart, true))
+
+	err = util.CheckDeadline(ctx, m)
+	if err != nil {
+		span.SetStatus(trace.Status{Code: trace.StatusCodeDeadlineExceeded, Message: err.Error()})
+		return "", false, err
+	}
+
 	respChan := make(chan *createFuncServiceResponse)
 	executor.requestChan <- &createFuncServiceRequest{
 		ctx:      ctx,
@@ -98,9 +146,61 @@ func (executor *Executor) getServiceForFunction(ctx context.Context, m *metav1.O
 	}
 	resp := <-respChan
 	if resp.err != nil {
-		return "", resp.err
+		span.SetStatus(trace.Status{Code: trace.StatusCodeUnavailable, Message: resp.err.Error()})
+		return "", false, resp.err
+	}
+	return resp.funcSvc.Address, true, resp.err
+}
+
+// getIsolatedServiceForFunction returns the address of a pod for a single
+// invocation of a function isolated per invocation. Nothing is cached or
+// shared, the router releases the pod once the invocation is done. Each
+// invocation specializes a pod, so it's always a cold start.
+func (executor *Executor) getIsolatedServiceForFunction(ctx context.Context, m *metav1.ObjectMeta) (string, bool, error) {
+	ctx, span := trace.StartSpan(ctx, "executor.getIsolatedServiceForFunction")
+	defer span.End()
+	span.AddAttributes(utils.FunctionTraceAttributes(m.Name, m.Namespace)...)
+
+	err := util.CheckDeadline(ctx, m)
+	if err != nil {
+		span.SetStatus(trace.Status{Code: trace.StatusCodeDeadlineExceeded, Message: err.Error()})
+		return "", false, err
+	}
+
+	fsvc, err := executor.gpm.GetIsolatedFuncSvc(ctx, m)
+	if err != nil {
+		executor.logger.Error("error getting isolated service for function",
+			zap.Error(err),
+			zap.String("function_name", m.Name),
+			zap.String("function_namespace", m.Namespace))
+		span.SetStatus(trace.Status{Code: trace.StatusCodeUnavailable, Message: err.Error()})
+		return "", false, err
 	}
-	return resp.funcSvc.Address, resp.err
+	return fsvc.Address, true, nil
+}
+
+// releaseService deletes the pod of an invocation of a function isolated
+// per invocation.
+func (executor *Executor) releaseService(w http.ResponseWriter, r *http.Request) {
+	body, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		executor.logger.Error("failed to read release service request", zap.Error(err))
+		http.Error(w, "Failed to read request", http.StatusInternalServerError)
+		return
+	}
+	svcName := string(body)
+	svcHost := strings.TrimPrefix(svcName, "http://")
+
+	err = executor.gpm.ReleaseIsolatedFuncSvc(svcHost)
+	if err != nil {
+		code, msg := ferror.GetHTTPError(err)
+		executor.logger.Error("error releasing function service",
+			zap.Error(err),
+			zap.String("service", svcName))
+		http.Error(w, msg, code)
+		return
+	}
+	w.WriteHeader(http.StatusOK)
 }
 
 // find funcSvc and update its atime
@@ -114,6 +214,10 @@ func (executor *Executor) tapService(w http.ResponseWriter, r *http.Request) {
 	svcName := string(body)
 	svcHost := strings.TrimPrefix(svcName, "http://")
 
+	// newdeploy scales idle functions down by the access time in its own
+	// cache, poolmgr functions aren't in it
+	executor.ndm.TapService(svcHost)
+
 	err = executor.fsCache.TouchByAddress(svcHost)
 	if err != nil {
 		executor.logger.Error("error tapping function service",
@@ -142,12 +246,21 @@ func (executor *Executor) Serve(port int) {
 	r := mux.NewRouter()
 	r.HandleFunc("/v2/getServiceForFunction", executor.getServiceForFunctionApi).Methods("POST")
 	r.HandleFunc("/v2/tapService", executor.tapService).Methods("POST")
+	r.HandleFunc("/v2/getIsolatedServiceForFunction", executor.getIsolatedServiceForFunctionApi).Methods("POST")
+	r.HandleFunc("/v2/releaseService", executor.releaseService).Methods("POST")
+	r.HandleFunc("/v2/profileFunction", executor.profileFunctionApi).Methods("POST")
+	r.HandleFunc("/v2/refreshPackage", executor.refreshPackageApi).Methods("POST")
 	r.HandleFunc("/healthz", executor.healthHandler).Methods("GET")
 
+	checker := health.MakeChecker("executor")
+	checker.AddCheck("crd", executor.fissionClient.CheckCRDs)
+	r.HandleFunc("/readyz", checker.ReadyzHandler).Methods("GET")
+
 	address := fmt.Sprintf(":%v", port)
 
 	err := http.ListenAndServe(address, &ochttp.Handler{
-		Handler: r,
+		Handler:     r,
+		Propagation: utils.TracePropagation,
 	})
 	executor.logger.Fatal("done listening", zap.Error(err))
 }
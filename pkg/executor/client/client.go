@@ -23,6 +23,7 @@ import (
 	"io/ioutil"
 	"net/http"
 	"net/url"
+	"strconv"
 	"strings"
 	"time"
 
@@ -33,6 +34,8 @@ import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 
 	ferror "github.com/fission/fission/pkg/error"
+	"github.com/fission/fission/pkg/types"
+	"github.com/fission/fission/pkg/utils"
 )
 
 type Client struct {
@@ -50,37 +53,132 @@ func MakeClient(logger *zap.Logger, executorUrl string) *Client {
 		tappedByUrl: make(map[string]bool),
 		requestChan: make(chan string),
 		httpClient: &http.Client{
-			Transport: &ochttp.Transport{},
+			Transport: &ochttp.Transport{Propagation: utils.TracePropagation},
 		},
 	}
 	go c.service()
 	return c
 }
 
-func (c *Client) GetServiceForFunction(ctx context.Context, metadata *metav1.ObjectMeta) (string, error) {
-	executorUrl := c.executorUrl + "/v2/getServiceForFunction"
+// GetServiceForFunction returns the address of the service of a function, and
+// whether the executor had to specialize a pod for it, i.e. a cold start.
+func (c *Client) GetServiceForFunction(ctx context.Context, metadata *metav1.ObjectMeta) (string, bool, error) {
+	return c.getServiceForFunction(ctx, c.executorUrl+"/v2/getServiceForFunction", metadata)
+}
+
+// GetIsolatedServiceForFunction returns the address of a pod for a single
+// invocation of a function isolated per invocation. The pod has to be
+// released with ReleaseService once the invocation is done.
+func (c *Client) GetIsolatedServiceForFunction(ctx context.Context, metadata *metav1.ObjectMeta) (string, bool, error) {
+	return c.getServiceForFunction(ctx, c.executorUrl+"/v2/getIsolatedServiceForFunction", metadata)
+}
 
+func (c *Client) getServiceForFunction(ctx context.Context, executorUrl string, metadata *metav1.ObjectMeta) (string, bool, error) {
 	body, err := json.Marshal(metadata)
 	if err != nil {
-		return "", errors.Wrap(err, "could not marshal request body for getting service for function")
+		return "", false, errors.Wrap(err, "could not marshal request body for getting service for function")
 	}
 
-	resp, err := ctxhttp.Post(ctx, c.httpClient, executorUrl, "application/json", bytes.NewReader(body))
+	req, err := http.NewRequest(http.MethodPost, executorUrl, bytes.NewReader(body))
 	if err != nil {
-		return "", errors.Wrap(err, "error posting to getting service for function")
+		return "", false, errors.Wrap(err, "could not create request for getting service for function")
+	}
+	req.Header.Set("Content-Type", "application/json")
+	// pass the time left to the executor, the connection to it may
+	// outlive the caller's context, e.g. behind a proxy
+	if deadline, ok := ctx.Deadline(); ok {
+		req.Header.Set(types.DeadlineTimeoutHeader, strconv.FormatInt(time.Until(deadline).Nanoseconds()/int64(time.Millisecond), 10))
+	}
+
+	resp, err := ctxhttp.Do(ctx, c.httpClient, req)
+	if err != nil {
+		return "", false, errors.Wrap(err, "error posting to getting service for function")
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode != 200 {
-		return "", ferror.MakeErrorFromHTTP(resp)
+		return "", false, ferror.MakeErrorFromHTTP(resp)
 	}
 
 	svcName, err := ioutil.ReadAll(resp.Body)
 	if err != nil {
-		return "", errors.Wrap(err, "error reading response body from getting service for function")
+		return "", false, errors.Wrap(err, "error reading response body from getting service for function")
+	}
+
+	return string(svcName), resp.Header.Get(types.ColdStartHeader) == "true", nil
+}
+
+// ReleaseService tells the executor that the invocation served by the pod
+// of an isolated function is done, the pod is deleted.
+func (c *Client) ReleaseService(ctx context.Context, serviceUrl *url.URL) error {
+	executorUrl := c.executorUrl + "/v2/releaseService"
+
+	resp, err := ctxhttp.Post(ctx, c.httpClient, executorUrl, "application/octet-stream", strings.NewReader(serviceUrl.String()))
+	if err != nil {
+		return errors.Wrap(err, "error posting to releasing service")
 	}
+	defer resp.Body.Close()
 
-	return string(svcName), nil
+	if resp.StatusCode != 200 {
+		return ferror.MakeErrorFromHTTP(resp)
+	}
+	return nil
+}
+
+// ProfileFunction captures a profile of the running pods of a function,
+// it blocks for the duration of the capture.
+func (c *Client) ProfileFunction(ctx context.Context, req *types.FunctionProfileRequest) (*types.FunctionProfileResponse, error) {
+	executorUrl := c.executorUrl + "/v2/profileFunction"
+
+	body, err := json.Marshal(req)
+	if err != nil {
+		return nil, errors.Wrap(err, "could not marshal request body for profiling function")
+	}
+
+	resp, err := ctxhttp.Post(ctx, c.httpClient, executorUrl, "application/json", bytes.NewReader(body))
+	if err != nil {
+		return nil, errors.Wrap(err, "error posting to profiling function")
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != 200 {
+		return nil, ferror.MakeErrorFromHTTP(resp)
+	}
+
+	profiles := &types.FunctionProfileResponse{}
+	err = json.NewDecoder(resp.Body).Decode(profiles)
+	if err != nil {
+		return nil, errors.Wrap(err, "error decoding response body from profiling function")
+	}
+	return profiles, nil
+}
+
+// RefreshPackage asks the executor to replace the pods of the functions
+// using the package, after it's rebuilt.
+func (c *Client) RefreshPackage(ctx context.Context, req *types.PackageRefreshRequest) (*types.PackageRefreshResponse, error) {
+	executorUrl := c.executorUrl + "/v2/refreshPackage"
+
+	body, err := json.Marshal(req)
+	if err != nil {
+		return nil, errors.Wrap(err, "could not marshal request body for refreshing package")
+	}
+
+	resp, err := ctxhttp.Post(ctx, c.httpClient, executorUrl, "application/json", bytes.NewReader(body))
+	if err != nil {
+		return nil, errors.Wrap(err, "error posting to refreshing package")
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != 200 {
+		return nil, ferror.MakeErrorFromHTTP(resp)
+	}
+
+	refreshResp := &types.PackageRefreshResponse{}
+	err = json.NewDecoder(resp.Body).Decode(refreshResp)
+	if err != nil {
+		return nil, errors.Wrap(err, "error decoding response body from refreshing package")
+	}
+	return refreshResp, nil
 }
 
 func (c *Client) service() {
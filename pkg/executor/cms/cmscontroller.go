@@ -165,7 +165,7 @@ func recyclePods(logger *zap.Logger, funcs []fv1.Function, ndm *nd.NewDeploy, gp
 		var err error
 
 		switch f.Spec.InvokeStrategy.ExecutionStrategy.ExecutorType {
-		case fv1.ExecutorTypeNewdeploy:
+		case fv1.ExecutorTypeNewdeploy, fv1.ExecutorTypeContainer:
 			err = ndm.RefreshFuncPods(logger, f)
 		case fv1.ExecutorTypePoolmgr:
 			err = gpm.RefreshFuncPods(logger, f)
@@ -27,8 +27,11 @@ import (
 	"github.com/dchest/uniuri"
 	"github.com/pkg/errors"
 	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"go.opencensus.io/trace"
 	"go.uber.org/zap"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	k8sTypes "k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes"
 
 	fv1 "github.com/fission/fission/pkg/apis/fission.io/v1"
 	"github.com/fission/fission/pkg/crd"
@@ -38,6 +41,7 @@ import (
 	"github.com/fission/fission/pkg/executor/poolmgr"
 	"github.com/fission/fission/pkg/executor/reaper"
 	fetcherConfig "github.com/fission/fission/pkg/fetcher/config"
+	"github.com/fission/fission/pkg/utils"
 )
 
 type (
@@ -48,11 +52,16 @@ type (
 		ndm *newdeploy.NewDeploy
 		cms *cms.ConfigSecretController
 
-		fissionClient *crd.FissionClient
-		fsCache       *fscache.FunctionServiceCache
+		fissionClient    *crd.FissionClient
+		kubernetesClient *kubernetes.Clientset
+		fsCache          *fscache.FunctionServiceCache
 
 		requestChan chan *createFuncServiceRequest
 		fsCreateWg  map[string]*sync.WaitGroup
+
+		// functions to refresh on the next lookup, see PackageRefreshLazy
+		staleFuncs    map[k8sTypes.UID]bool
+		staleFuncLock sync.Mutex
 	}
 	createFuncServiceRequest struct {
 		ctx      context.Context
@@ -66,17 +75,19 @@ type (
 	}
 )
 
-func MakeExecutor(logger *zap.Logger, gpm *poolmgr.GenericPoolManager, ndm *newdeploy.NewDeploy, cms *cms.ConfigSecretController, fissionClient *crd.FissionClient, fsCache *fscache.FunctionServiceCache) *Executor {
+func MakeExecutor(logger *zap.Logger, gpm *poolmgr.GenericPoolManager, ndm *newdeploy.NewDeploy, cms *cms.ConfigSecretController, fissionClient *crd.FissionClient, kubernetesClient *kubernetes.Clientset, fsCache *fscache.FunctionServiceCache) *Executor {
 	executor := &Executor{
-		logger:        logger.Named("executor"),
-		gpm:           gpm,
-		ndm:           ndm,
-		cms:           cms,
-		fissionClient: fissionClient,
-		fsCache:       fsCache,
+		logger:           logger.Named("executor"),
+		gpm:              gpm,
+		ndm:              ndm,
+		cms:              cms,
+		fissionClient:    fissionClient,
+		kubernetesClient: kubernetesClient,
+		fsCache:          fsCache,
 
 		requestChan: make(chan *createFuncServiceRequest),
 		fsCreateWg:  make(map[string]*sync.WaitGroup),
+		staleFuncs:  make(map[k8sTypes.UID]bool),
 	}
 	go executor.serveCreateFuncServices()
 
@@ -151,16 +162,24 @@ func (executor *Executor) createServiceForFunction(ctx context.Context, meta *me
 		zap.String("function_name", meta.Name),
 		zap.String("function_namespace", meta.Namespace))
 
+	// The span covers the cold start of the function, i.e. choosing a
+	// pod and specializing it, or waiting for the deployment to be ready.
+	ctx, span := trace.StartSpan(ctx, "executor.createServiceForFunction")
+	defer span.End()
+	span.AddAttributes(utils.FunctionTraceAttributes(meta.Name, meta.Namespace)...)
+
 	executorType, err := executor.getFunctionExecutorType(meta)
 	if err != nil {
+		span.SetStatus(trace.Status{Code: trace.StatusCodeNotFound, Message: err.Error()})
 		return nil, err
 	}
+	span.AddAttributes(trace.StringAttribute(utils.TraceAttrExecutorType, string(executorType)))
 
 	var fsvc *fscache.FuncSvc
 	var fsvcErr error
 
 	switch executorType {
-	case fv1.ExecutorTypeNewdeploy:
+	case fv1.ExecutorTypeNewdeploy, fv1.ExecutorTypeContainer:
 		fsvc, fsvcErr = executor.ndm.GetFuncSvc(ctx, meta)
 	default:
 		fsvc, fsvcErr = executor.gpm.GetFuncSvc(ctx, meta)
@@ -173,6 +192,7 @@ func (executor *Executor) createServiceForFunction(ctx context.Context, meta *me
 			zap.String("function_name", meta.Name),
 			zap.String("function_namespace", meta.Namespace))
 		fsvcErr = errors.Wrap(fsvcErr, fmt.Sprintf("[%s] %s", meta.Name, e))
+		span.SetStatus(trace.Status{Code: trace.StatusCodeUnavailable, Message: fsvcErr.Error()})
 	} else if fsvc != nil {
 		_, err = executor.fsCache.Add(*fsvc)
 		if err != nil {
@@ -241,7 +261,7 @@ func StartExecutor(logger *zap.Logger, fissionNamespace string, functionNamespac
 
 	cms := cms.MakeConfigSecretController(logger, fissionClient, kubernetesClient, ndm, gpm)
 
-	api := MakeExecutor(logger, gpm, ndm, cms, fissionClient, fsCache)
+	api := MakeExecutor(logger, gpm, ndm, cms, fissionClient, kubernetesClient, fsCache)
 
 	go api.Serve(port)
 	go serveMetric(logger)
@@ -0,0 +1,107 @@
+/*
+Copyright 2019 The Fission Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package newdeploy
+
+import (
+	"time"
+
+	appsv1 "k8s.io/api/apps/v1"
+	apiv1 "k8s.io/api/core/v1"
+
+	fv1 "github.com/fission/fission/pkg/apis/fission.io/v1"
+	"github.com/fission/fission/pkg/executor/util"
+	"github.com/fission/fission/pkg/types"
+)
+
+// defaultContainerPort is the port container functions serve HTTP on if
+// their container has no port, the port of the environment runtimes.
+const defaultContainerPort = 8888
+
+// isNewdeployFunction returns whether the function runs on deployments of
+// the newdeploy manager, i.e. uses the newdeploy or the container executor
+// type.
+func isNewdeployFunction(fn *fv1.Function) bool {
+	executorType := fn.Spec.InvokeStrategy.ExecutionStrategy.ExecutorType
+	return executorType == fv1.ExecutorTypeNewdeploy || executorType == fv1.ExecutorTypeContainer
+}
+
+func isContainerFunction(fn *fv1.Function) bool {
+	return fn.Spec.InvokeStrategy.ExecutionStrategy.ExecutorType == fv1.ExecutorTypeContainer
+}
+
+// getFunctionEnv returns the environment of the function. Container
+// functions have none, they get an empty environment so that the objects
+// of all functions are handled alike.
+func (deploy *NewDeploy) getFunctionEnv(fn *fv1.Function) (*fv1.Environment, error) {
+	if isContainerFunction(fn) {
+		return &fv1.Environment{}, nil
+	}
+	return deploy.fissionClient.Environments(fn.Spec.Environment.Namespace).Get(fn.Spec.Environment.Name)
+}
+
+// functionPort returns the port the pods of the function serve HTTP on.
+func functionPort(fn *fv1.Function) int32 {
+	if isContainerFunction(fn) && fn.Spec.PodSpec != nil && len(fn.Spec.PodSpec.Containers) > 0 {
+		ports := fn.Spec.PodSpec.Containers[0].Ports
+		if len(ports) > 0 {
+			return ports[0].ContainerPort
+		}
+	}
+	return defaultContainerPort
+}
+
+// getContainerDeploymentSpec returns the deployment of a container
+// function, which runs the pod spec of the function as is apart from the
+// name of the first container, so that the logs of the function can be
+// found, and the variable updated to roll out new pods.
+func (deploy *NewDeploy) getContainerDeploymentSpec(fn *fv1.Function, deployName string, deployLabels map[string]string) (*appsv1.Deployment, error) {
+	podSpec := fn.Spec.PodSpec.DeepCopy()
+
+	container := &podSpec.Containers[0]
+	container.Name = fn.Metadata.Name
+	if len(container.Ports) == 0 {
+		container.Ports = []apiv1.ContainerPort{
+			{
+				Name:          "http-env",
+				ContainerPort: int32(defaultContainerPort),
+			},
+		}
+	}
+	if len(container.ImagePullPolicy) == 0 {
+		container.ImagePullPolicy = deploy.runtimeImagePullPolicy
+	}
+	if container.Resources.Requests == nil && container.Resources.Limits == nil {
+		container.Resources = fn.Spec.Resources
+	}
+	container.Env = append(container.Env, apiv1.EnvVar{
+		Name:  fv1.LastUpdateTimestamp,
+		Value: time.Now().String(),
+	})
+
+	// the log forwarder sidecar reads the logs of the function with the
+	// service account of the fetcher
+	if len(podSpec.ServiceAccountName) == 0 {
+		podSpec.ServiceAccountName = types.FissionFetcherSA
+	}
+	deploy.fetcherConfig.AddLogForwarderToPodSpec(podSpec, fn.Metadata.Name)
+	util.ApplyFunctionScheduling(podSpec, fn)
+	util.ApplyFunctionVolumes(podSpec, fn)
+
+	replicas := int32(fn.Spec.InvokeStrategy.ExecutionStrategy.MinScale)
+	podLabels := util.PodLabels(deployLabels, &fn.Metadata)
+	return makeDeployment(deployName, deployLabels, podLabels, replicas, util.PodAnnotations(&fn.Metadata), podSpec), nil
+}
@@ -115,23 +115,42 @@ func (deploy *NewDeploy) setupRBACObjs(deployNamespace string, fn *fv1.Function)
 		return err
 	}
 
-	// create a cluster role binding for the fetcher SA, if not already created, granting access to do a get on packages in any ns
-	err = utils.SetupRoleBinding(deploy.logger, deploy.kubernetesClient, types.PackageGetterRB, fn.Spec.Package.PackageRef.Namespace, types.PackageGetterCR, types.ClusterRole, types.FissionFetcherSA, deployNamespace)
+	// container functions have no fetcher, only the log forwarder uses the service account
+	if isContainerFunction(fn) {
+		return deploy.fetcherConfig.SetupLogForwarderRoleBinding(deploy.logger, deploy.kubernetesClient, deployNamespace)
+	}
+
+	// create a cluster role binding for the fetcher SA, if not already created, granting access to do a get on packages in any ns,
+	// functions with a prebuilt image have no package to get
+	if fn.Spec.PrebuiltImage == nil {
+		err = utils.SetupRoleBinding(deploy.logger, deploy.kubernetesClient, types.PackageGetterRB, fn.Spec.Package.PackageRef.Namespace, types.PackageGetterCR, types.ClusterRole, types.FissionFetcherSA, deployNamespace)
+		if err != nil {
+			deploy.logger.Error("error creating role binding for function",
+				zap.Error(err),
+				zap.String("role_binding", types.PackageGetterRB),
+				zap.String("function_name", fn.Metadata.Name),
+				zap.String("function_namespace", fn.Metadata.Namespace))
+			return err
+		}
+	}
+
+	// create rolebinding in function namespace for fetcherSA.envNamespace to be able to get secrets and configmaps
+	err = utils.SetupRoleBinding(deploy.logger, deploy.kubernetesClient, types.SecretConfigMapGetterRB, fn.Metadata.Namespace, types.SecretConfigMapGetterCR, types.ClusterRole, types.FissionFetcherSA, deployNamespace)
 	if err != nil {
 		deploy.logger.Error("error creating role binding for function",
 			zap.Error(err),
-			zap.String("role_binding", types.PackageGetterRB),
+			zap.String("role_binding", types.SecretConfigMapGetterRB),
 			zap.String("function_name", fn.Metadata.Name),
 			zap.String("function_namespace", fn.Metadata.Namespace))
 		return err
 	}
 
-	// create rolebinding in function namespace for fetcherSA.envNamespace to be able to get secrets and configmaps
-	err = utils.SetupRoleBinding(deploy.logger, deploy.kubernetesClient, types.SecretConfigMapGetterRB, fn.Metadata.Namespace, types.SecretConfigMapGetterCR, types.ClusterRole, types.FissionFetcherSA, deployNamespace)
+	// let the log forwarder sidecars read the logs of the function pods, if enabled
+	err = deploy.fetcherConfig.SetupLogForwarderRoleBinding(deploy.logger, deploy.kubernetesClient, deployNamespace)
 	if err != nil {
 		deploy.logger.Error("error creating role binding for function",
 			zap.Error(err),
-			zap.String("role_binding", types.SecretConfigMapGetterRB),
+			zap.String("role_binding", types.PodLogReaderRB),
 			zap.String("function_name", fn.Metadata.Name),
 			zap.String("function_namespace", fn.Metadata.Namespace))
 		return err
@@ -164,6 +183,10 @@ func (deploy *NewDeploy) deleteDeployment(ns string, name string) error {
 func (deploy *NewDeploy) getDeploymentSpec(fn *fv1.Function, env *fv1.Environment,
 	deployName string, deployLabels map[string]string) (*appsv1.Deployment, error) {
 
+	if isContainerFunction(fn) {
+		return deploy.getContainerDeploymentSpec(fn, deployName, deployLabels)
+	}
+
 	replicas := int32(fn.Spec.InvokeStrategy.ExecutionStrategy.MinScale)
 
 	gracePeriodSeconds := int64(6 * 60)
@@ -171,32 +194,12 @@ func (deploy *NewDeploy) getDeploymentSpec(fn *fv1.Function, env *fv1.Environmen
 		gracePeriodSeconds = env.Spec.TerminationGracePeriod
 	}
 
-	podAnnotations := env.Metadata.Annotations
-	if podAnnotations == nil {
-		podAnnotations = make(map[string]string)
-	}
+	podAnnotations := util.PodAnnotations(&env.Metadata, &fn.Metadata)
 	if deploy.useIstio && env.Spec.AllowAccessToExternalNetwork {
 		podAnnotations["sidecar.istio.io/inject"] = "false"
 	}
 	resources := deploy.getResources(env, fn)
 
-	// Set maxUnavailable and maxSurge to 20% is because we want
-	// fission to rollout newer function version gradually without
-	// affecting any online service. For example, if you set maxSurge
-	// to 100%, the new ReplicaSet scales up immediately and may
-	// consume all remaining compute resources which might be an
-	// issue if a cluster's resource is on a budget.
-	// TODO: add to ExecutionStrategy so that the user
-	// can do more fine control over different functions.
-	maxUnavailable := intstr.FromString("20%")
-	maxSurge := intstr.FromString("20%")
-
-	// Newdeploy updates the environment variable "LastUpdateTimestamp" of deployment
-	// whenever a configmap/secret gets an update, but it also leaves multiple ReplicaSets for
-	// rollback purpose. Since fission always update a deployment instead of performing a
-	// rollback, set RevisionHistoryLimit to 0 to disable this feature.
-	revisionHistoryLimit := int32(0)
-
 	container, err := util.MergeContainer(&apiv1.Container{
 		Name:                   fn.Metadata.Name,
 		Image:                  env.Spec.Runtime.Image,
@@ -230,8 +233,69 @@ func (deploy *NewDeploy) getDeploymentSpec(fn *fv1.Function, env *fv1.Environmen
 	if err != nil {
 		return nil, err
 	}
+	if fn.Spec.PrebuiltImage != nil {
+		// the prebuilt image replaces the runtime image, also one set in
+		// the container of the environment
+		container.Image = fn.Spec.PrebuiltImage.Image
+	}
+
+	podLabels := util.PodLabels(deployLabels, &env.Metadata, &fn.Metadata)
+	deployment := makeDeployment(deployName, deployLabels, podLabels, replicas, podAnnotations, &apiv1.PodSpec{
+		Containers:                    []apiv1.Container{*container},
+		ServiceAccountName:            "fission-fetcher",
+		TerminationGracePeriodSeconds: &gracePeriodSeconds,
+		RuntimeClassName:              util.GetRuntimeClassName(env),
+		ImagePullSecrets:              util.GetImagePullSecrets(env),
+	})
+
+	// Order of merging is important here - first fetcher, then containers and lastly pod spec
+	err = deploy.fetcherConfig.AddSpecializingFetcherToPodSpec(
+		&deployment.Spec.Template.Spec,
+		fn.Metadata.Name,
+		fn,
+		env,
+	)
+	if err != nil {
+		return nil, err
+	}
+	deploy.fetcherConfig.AddLogForwarderToPodSpec(&deployment.Spec.Template.Spec, fn.Metadata.Name)
+
+	if env.Spec.Runtime.PodSpec != nil {
+		newPodSpec, err := util.MergePodSpec(&deployment.Spec.Template.Spec, env.Spec.Runtime.PodSpec)
+		if err != nil {
+			return nil, err
+		}
+		deployment.Spec.Template.Spec = *newPodSpec
+	}
+	util.ApplyFunctionScheduling(&deployment.Spec.Template.Spec, fn)
+	util.ApplyFunctionVolumes(&deployment.Spec.Template.Spec, fn)
 
-	deployment := &appsv1.Deployment{
+	return deployment, nil
+}
+
+// makeDeployment returns the deployment running the pods of a function.
+// The pods are selected by deployLabels, podLabels must include them.
+func makeDeployment(deployName string, deployLabels map[string]string, podLabels map[string]string,
+	replicas int32, podAnnotations map[string]string, podSpec *apiv1.PodSpec) *appsv1.Deployment {
+
+	// Set maxUnavailable and maxSurge to 20% is because we want
+	// fission to rollout newer function version gradually without
+	// affecting any online service. For example, if you set maxSurge
+	// to 100%, the new ReplicaSet scales up immediately and may
+	// consume all remaining compute resources which might be an
+	// issue if a cluster's resource is on a budget.
+	// TODO: add to ExecutionStrategy so that the user
+	// can do more fine control over different functions.
+	maxUnavailable := intstr.FromString("20%")
+	maxSurge := intstr.FromString("20%")
+
+	// Newdeploy updates the environment variable "LastUpdateTimestamp" of deployment
+	// whenever a configmap/secret gets an update, but it also leaves multiple ReplicaSets for
+	// rollback purpose. Since fission always update a deployment instead of performing a
+	// rollback, set RevisionHistoryLimit to 0 to disable this feature.
+	revisionHistoryLimit := int32(0)
+
+	return &appsv1.Deployment{
 		ObjectMeta: metav1.ObjectMeta{
 			Name:   deployName,
 			Labels: deployLabels,
@@ -243,14 +307,10 @@ func (deploy *NewDeploy) getDeploymentSpec(fn *fv1.Function, env *fv1.Environmen
 			},
 			Template: apiv1.PodTemplateSpec{
 				ObjectMeta: metav1.ObjectMeta{
-					Labels:      deployLabels,
+					Labels:      podLabels,
 					Annotations: podAnnotations,
 				},
-				Spec: apiv1.PodSpec{
-					Containers:                    []apiv1.Container{*container},
-					ServiceAccountName:            "fission-fetcher",
-					TerminationGracePeriodSeconds: &gracePeriodSeconds,
-				},
+				Spec: *podSpec,
 			},
 			Strategy: appsv1.DeploymentStrategy{
 				Type: appsv1.RollingUpdateDeploymentStrategyType,
@@ -262,27 +322,6 @@ func (deploy *NewDeploy) getDeploymentSpec(fn *fv1.Function, env *fv1.Environmen
 			RevisionHistoryLimit: &revisionHistoryLimit,
 		},
 	}
-
-	// Order of merging is important here - first fetcher, then containers and lastly pod spec
-	err = deploy.fetcherConfig.AddSpecializingFetcherToPodSpec(
-		&deployment.Spec.Template.Spec,
-		fn.Metadata.Name,
-		fn,
-		env,
-	)
-	if err != nil {
-		return nil, err
-	}
-
-	if env.Spec.Runtime.PodSpec != nil {
-		newPodSpec, err := util.MergePodSpec(&deployment.Spec.Template.Spec, env.Spec.Runtime.PodSpec)
-		if err != nil {
-			return nil, err
-		}
-		deployment.Spec.Template.Spec = *newPodSpec
-	}
-
-	return deployment, nil
 }
 
 // getResources overrides only the resources which are overridden at function level otherwise
@@ -382,7 +421,7 @@ func (deploy *NewDeploy) deleteHpa(ns string, name string) error {
 	return deploy.kubernetesClient.AutoscalingV1().HorizontalPodAutoscalers(ns).Delete(name, &metav1.DeleteOptions{})
 }
 
-func (deploy *NewDeploy) createOrGetSvc(deployLabels map[string]string, svcName string, svcNamespace string) (*apiv1.Service, error) {
+func (deploy *NewDeploy) createOrGetSvc(deployLabels map[string]string, svcName string, svcNamespace string, targetPort int32) (*apiv1.Service, error) {
 	existingSvc, err := deploy.kubernetesClient.CoreV1().Services(svcNamespace).Get(svcName, metav1.GetOptions{})
 	if err == nil {
 		return existingSvc, err
@@ -397,7 +436,7 @@ func (deploy *NewDeploy) createOrGetSvc(deployLabels map[string]string, svcName
 					{
 						Name:       "http-env",
 						Port:       int32(80),
-						TargetPort: intstr.FromInt(8888),
+						TargetPort: intstr.FromInt(int(targetPort)),
 					},
 				},
 				Selector: deployLabels,
@@ -20,6 +20,7 @@ import (
 	"context"
 	"fmt"
 	"os"
+	"reflect"
 	"strconv"
 	"strings"
 	"time"
@@ -37,6 +38,7 @@ import (
 	"k8s.io/apimachinery/pkg/fields"
 	"k8s.io/apimachinery/pkg/labels"
 	k8sTypes "k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/intstr"
 	"k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/rest"
 	k8sCache "k8s.io/client-go/tools/cache"
@@ -48,6 +50,11 @@ import (
 	"github.com/fission/fission/pkg/types"
 )
 
+// idleObjectReaperInterval is how often idle function deployments are
+// looked for, so that a deployment is scaled down at most this long after
+// its idle timeout.
+const idleObjectReaperInterval = 30 * time.Second
+
 type (
 	NewDeploy struct {
 		logger *zap.Logger
@@ -72,6 +79,8 @@ type (
 		envStore      k8sCache.Store
 		envController k8sCache.Controller
 
+		// idlePodReapTime is the default idle timeout of functions,
+		// see fv1.FunctionSpec.IdleTimeout.
 		idlePodReapTime time.Duration
 	}
 )
@@ -94,6 +103,18 @@ func MakeNewDeploy(
 		enableIstio = istio
 	}
 
+	idlePodReapTime := 2 * time.Minute
+	if len(os.Getenv("NEWDEPLOY_IDLE_TIMEOUT")) > 0 {
+		timeout, err := time.ParseDuration(os.Getenv("NEWDEPLOY_IDLE_TIMEOUT"))
+		if err != nil || timeout <= 0 {
+			logger.Error("failed to parse 'NEWDEPLOY_IDLE_TIMEOUT', use default value",
+				zap.Error(err), zap.String("value", os.Getenv("NEWDEPLOY_IDLE_TIMEOUT")),
+				zap.Duration("default", idlePodReapTime))
+		} else {
+			idlePodReapTime = timeout
+		}
+	}
+
 	nd := &NewDeploy{
 		logger: logger.Named("new_deploy"),
 
@@ -110,7 +131,7 @@ func MakeNewDeploy(
 		runtimeImagePullPolicy: utils.GetImagePullPolicy(os.Getenv("RUNTIME_IMAGE_PULL_POLICY")),
 		useIstio:               enableIstio,
 
-		idlePodReapTime: 2 * time.Minute,
+		idlePodReapTime: idlePodReapTime,
 	}
 
 	if nd.crdClient != nil {
@@ -226,16 +247,12 @@ func (deploy *NewDeploy) GetFuncSvc(ctx context.Context, metadata *metav1.Object
 // RefreshFuncPods deleted pods related to the function so that new pods are replenished
 func (deploy *NewDeploy) RefreshFuncPods(logger *zap.Logger, f fv1.Function) error {
 
-	env, err := deploy.fissionClient.Environments(f.Spec.Environment.Namespace).Get(f.Spec.Environment.Name)
+	env, err := deploy.getFunctionEnv(&f)
 	if err != nil {
 		return err
 	}
 
-	funcLabels := deploy.getDeployLabels(f.Metadata, metav1.ObjectMeta{
-		Name:      f.Spec.Environment.Name,
-		Namespace: f.Spec.Environment.Namespace,
-		UID:       env.Metadata.UID,
-	})
+	funcLabels := deploy.getDeployLabels(f.Metadata, env.Metadata)
 
 	dep, err := deploy.kubernetesClient.AppsV1().Deployments(metav1.NamespaceAll).List(metav1.ListOptions{
 		LabelSelector: labels.Set(funcLabels).AsSelector().String(),
@@ -263,7 +280,7 @@ func (deploy *NewDeploy) RefreshFuncPods(logger *zap.Logger, f fv1.Function) err
 }
 
 func (deploy *NewDeploy) createFunction(fn *fv1.Function, firstcreate bool) (*fscache.FuncSvc, error) {
-	if fn.Spec.InvokeStrategy.ExecutionStrategy.ExecutorType != fv1.ExecutorTypeNewdeploy {
+	if !isNewdeployFunction(fn) {
 		return nil, nil
 	}
 
@@ -292,7 +309,7 @@ func (deploy *NewDeploy) createFunction(fn *fv1.Function, firstcreate bool) (*fs
 }
 
 func (deploy *NewDeploy) deleteFunction(fn *fv1.Function) error {
-	if fn.Spec.InvokeStrategy.ExecutionStrategy.ExecutorType != fv1.ExecutorTypeNewdeploy {
+	if !isNewdeployFunction(fn) {
 		return nil
 	}
 	err := deploy.fnDelete(fn)
@@ -303,9 +320,7 @@ func (deploy *NewDeploy) deleteFunction(fn *fv1.Function) error {
 }
 
 func (deploy *NewDeploy) fnCreate(fn *fv1.Function, firstcreate bool) (*fscache.FuncSvc, error) {
-	env, err := deploy.fissionClient.
-		Environments(fn.Spec.Environment.Namespace).
-		Get(fn.Spec.Environment.Name)
+	env, err := deploy.getFunctionEnv(fn)
 	if err != nil {
 		return nil, err
 	}
@@ -333,7 +348,7 @@ func (deploy *NewDeploy) fnCreate(fn *fv1.Function, firstcreate bool) (*fscache.
 	// Since newdeploy waits for pods of deployment to be ready,
 	// change the order of kubeObject creation (create service first,
 	// then deployment) to take advantage of waiting time.
-	svc, err := deploy.createOrGetSvc(deployLabels, objName, ns)
+	svc, err := deploy.createOrGetSvc(deployLabels, objName, ns, functionPort(fn))
 	if err != nil {
 		deploy.logger.Error("error creating service", zap.Error(err), zap.String("service", objName))
 		go deploy.cleanupNewdeploy(ns, objName)
@@ -407,14 +422,12 @@ func (deploy *NewDeploy) updateFunction(oldFn *fv1.Function, newFn *fv1.Function
 	}
 
 	// Ignoring updates to functions which are not of NewDeployment type
-	if newFn.Spec.InvokeStrategy.ExecutionStrategy.ExecutorType != fv1.ExecutorTypeNewdeploy &&
-		oldFn.Spec.InvokeStrategy.ExecutionStrategy.ExecutorType != fv1.ExecutorTypeNewdeploy {
+	if !isNewdeployFunction(newFn) && !isNewdeployFunction(oldFn) {
 		return nil
 	}
 
 	// Executor type is no longer New Deployment
-	if newFn.Spec.InvokeStrategy.ExecutionStrategy.ExecutorType != fv1.ExecutorTypeNewdeploy &&
-		oldFn.Spec.InvokeStrategy.ExecutionStrategy.ExecutorType == fv1.ExecutorTypeNewdeploy {
+	if !isNewdeployFunction(newFn) && isNewdeployFunction(oldFn) {
 		deploy.logger.Info("function does not use new deployment executor anymore, deleting resources",
 			zap.Any("function", newFn))
 		// IMP - pass the oldFn, as the new/modified function is not in cache
@@ -422,8 +435,7 @@ func (deploy *NewDeploy) updateFunction(oldFn *fv1.Function, newFn *fv1.Function
 	}
 
 	// Executor type changed to New Deployment from something else
-	if oldFn.Spec.InvokeStrategy.ExecutionStrategy.ExecutorType != fv1.ExecutorTypeNewdeploy &&
-		newFn.Spec.InvokeStrategy.ExecutionStrategy.ExecutorType == fv1.ExecutorTypeNewdeploy {
+	if !isNewdeployFunction(oldFn) && isNewdeployFunction(newFn) {
 		deploy.logger.Info("function type changed to new deployment, creating resources",
 			zap.Any("old_function", oldFn.Metadata),
 			zap.Any("new_function", newFn.Metadata))
@@ -461,6 +473,11 @@ func (deploy *NewDeploy) updateFunction(oldFn *fv1.Function, newFn *fv1.Function
 
 		if newFn.Spec.InvokeStrategy.ExecutionStrategy.MinScale != oldFn.Spec.InvokeStrategy.ExecutionStrategy.MinScale {
 			replicas := int32(newFn.Spec.InvokeStrategy.ExecutionStrategy.MinScale)
+			// HPA doesn't allow zero minReplicas, a MinScale of zero is
+			// handled by the idle object reaper instead
+			if replicas < 1 {
+				replicas = 1
+			}
 			hpa.Spec.MinReplicas = &replicas
 			hpaChanged = true
 		}
@@ -487,7 +504,16 @@ func (deploy *NewDeploy) updateFunction(oldFn *fv1.Function, newFn *fv1.Function
 
 	if oldFn.Spec.Environment != newFn.Spec.Environment ||
 		oldFn.Spec.Package.PackageRef != newFn.Spec.Package.PackageRef ||
-		oldFn.Spec.Package.FunctionName != newFn.Spec.Package.FunctionName {
+		oldFn.Spec.Package.FunctionName != newFn.Spec.Package.FunctionName ||
+		oldFn.Spec.InvokeStrategy.ExecutionStrategy.ExecutorType != newFn.Spec.InvokeStrategy.ExecutionStrategy.ExecutorType ||
+		!reflect.DeepEqual(oldFn.Spec.PodSpec, newFn.Spec.PodSpec) ||
+		!reflect.DeepEqual(oldFn.Spec.PrebuiltImage, newFn.Spec.PrebuiltImage) ||
+		!reflect.DeepEqual(oldFn.Spec.NodeSelector, newFn.Spec.NodeSelector) ||
+		!reflect.DeepEqual(oldFn.Spec.Tolerations, newFn.Spec.Tolerations) ||
+		!reflect.DeepEqual(oldFn.Spec.Spread, newFn.Spec.Spread) ||
+		!reflect.DeepEqual(oldFn.Spec.Volumes, newFn.Spec.Volumes) ||
+		!reflect.DeepEqual(oldFn.Metadata.Labels, newFn.Metadata.Labels) ||
+		!reflect.DeepEqual(oldFn.Metadata.Annotations, newFn.Metadata.Annotations) {
 		deployChanged = true
 	}
 
@@ -514,8 +540,7 @@ func (deploy *NewDeploy) updateFunction(oldFn *fv1.Function, newFn *fv1.Function
 	}
 
 	if deployChanged == true {
-		env, err := deploy.fissionClient.Environments(newFn.Spec.Environment.Namespace).
-			Get(newFn.Spec.Environment.Name)
+		env, err := deploy.getFunctionEnv(newFn)
 		if err != nil {
 			deploy.updateStatus(oldFn, err, "failed to get environment while updating function")
 			return err
@@ -558,6 +583,22 @@ func (deploy *NewDeploy) updateFuncDeployment(fn *fv1.Function, env *fv1.Environ
 		return err
 	}
 
+	// the port of a container function may have changed
+	svc, err := deploy.kubernetesClient.CoreV1().Services(ns).Get(fnObjName, metav1.GetOptions{})
+	if err != nil {
+		deploy.updateStatus(fn, err, "failed to get service while updating function")
+		return err
+	}
+	targetPort := intstr.FromInt(int(functionPort(fn)))
+	if len(svc.Spec.Ports) > 0 && svc.Spec.Ports[0].TargetPort != targetPort {
+		svc.Spec.Ports[0].TargetPort = targetPort
+		_, err = deploy.kubernetesClient.CoreV1().Services(ns).Update(svc)
+		if err != nil {
+			deploy.updateStatus(fn, err, "failed to update service while updating function")
+			return err
+		}
+	}
+
 	return nil
 }
 
@@ -670,12 +711,27 @@ func (deploy *NewDeploy) IsValid(fsvc *fscache.FuncSvc) bool {
 	return false
 }
 
-// idleObjectReaper reaps objects after certain idle time
-func (deploy *NewDeploy) idleObjectReaper() {
+// TapService marks the function service at the address as used, so that
+// the idle object reaper doesn't scale down a function serving requests.
+func (deploy *NewDeploy) TapService(svcHost string) error {
+	return deploy.fsCache.TouchByAddress(svcHost)
+}
 
-	pollSleep := time.Duration(deploy.idlePodReapTime)
+// idleTimeout returns how long the function may be idle before its
+// deployment is scaled down to MinScale.
+func (deploy *NewDeploy) idleTimeout(fn *fv1.Function) time.Duration {
+	if fn.Spec.IdleTimeout > 0 {
+		return time.Duration(fn.Spec.IdleTimeout) * time.Second
+	}
+	return deploy.idlePodReapTime
+}
+
+// idleObjectReaper scales the deployments of functions idle for longer
+// than their idle timeout down to MinScale. A function scaled down to zero
+// replicas is scaled up again on its next request.
+func (deploy *NewDeploy) idleObjectReaper() {
 	for {
-		time.Sleep(pollSleep)
+		time.Sleep(idleObjectReaperInterval)
 
 		envs, err := deploy.fissionClient.Environments(metav1.NamespaceAll).List(metav1.ListOptions{})
 		if err != nil {
@@ -687,7 +743,9 @@ func (deploy *NewDeploy) idleObjectReaper() {
 			envList[env.Metadata.UID] = struct{}{}
 		}
 
-		funcSvcs, err := deploy.fsCache.ListOld(deploy.idlePodReapTime)
+		// the idle timeout of a function may be shorter than the default,
+		// it's checked for every function below
+		funcSvcs, err := deploy.fsCache.ListOld(idleObjectReaperInterval)
 		if err != nil {
 			deploy.logger.Error("error reaping idle pods", zap.Error(err))
 			continue
@@ -700,7 +758,8 @@ func (deploy *NewDeploy) idleObjectReaper() {
 
 			// For function with the environment that no longer exists, executor
 			// scales down the deployment as usual and prints log to notify user.
-			if _, ok := envList[fsvc.Environment.Metadata.UID]; !ok {
+			// Container functions have no environment.
+			if _, ok := envList[fsvc.Environment.Metadata.UID]; !ok && len(fsvc.Environment.Metadata.UID) > 0 {
 				deploy.logger.Error("function environment no longer exists",
 					zap.String("environment", fsvc.Environment.Metadata.Name),
 					zap.String("function", fsvc.Name))
@@ -717,6 +776,10 @@ func (deploy *NewDeploy) idleObjectReaper() {
 				continue
 			}
 
+			if time.Since(fsvc.Atime) < deploy.idleTimeout(fn) {
+				continue
+			}
+
 			deployObj := getDeploymentObj(fsvc.KubernetesObjects)
 			if deployObj == nil {
 				deploy.logger.Error("error finding function deployment", zap.Error(err), zap.String("function", fsvc.Function.Name))
@@ -134,6 +134,8 @@ func (gpm *GenericPoolManager) makeFuncController(fissionClient *crd.FissionClie
 							zap.Any("selectors", sel))
 					}
 				}
+
+				go gpm.fillIsolationPipeline(fn)
 			},
 
 			DeleteFunc: func(obj interface{}) {
@@ -161,6 +163,8 @@ func (gpm *GenericPoolManager) makeFuncController(fissionClient *crd.FissionClie
 
 					}
 				}
+
+				gpm.dropIsolationPipeline(&fn.Metadata)
 			},
 
 			UpdateFunc: func(oldObj, newObj interface{}) {
@@ -201,6 +205,10 @@ func (gpm *GenericPoolManager) makeFuncController(fissionClient *crd.FissionClie
 							zap.String("function_namespace", newFunc.Metadata.Namespace))
 					}
 				}
+
+				// pods specialized ahead run the previous version of the function
+				gpm.dropIsolationPipeline(&oldFunc.Metadata)
+				go gpm.fillIsolationPipeline(newFunc)
 			},
 		})
 
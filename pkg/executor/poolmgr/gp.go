@@ -30,6 +30,7 @@ import (
 	"github.com/fission/fission/pkg/utils"
 	multierror "github.com/hashicorp/go-multierror"
 	"github.com/pkg/errors"
+	"go.opencensus.io/trace"
 	"go.uber.org/zap"
 	appsv1 "k8s.io/api/apps/v1"
 	apiv1 "k8s.io/api/core/v1"
@@ -40,6 +41,7 @@ import (
 
 	fv1 "github.com/fission/fission/pkg/apis/fission.io/v1"
 	"github.com/fission/fission/pkg/crd"
+	ferror "github.com/fission/fission/pkg/error"
 	"github.com/fission/fission/pkg/executor/fscache"
 	"github.com/fission/fission/pkg/executor/util"
 	fetcherClient "github.com/fission/fission/pkg/fetcher/client"
@@ -67,11 +69,15 @@ type (
 		labelsForPool          map[string]string
 		requestChannel         chan *choosePodRequest
 		fetcherConfig          *fetcherConfig.Config
+		multiplex              *multiplexGroups // pods shared by functions of the same package
+		isolated               *isolatedPods    // pods of functions isolated per invocation
 	}
 
 	// serialize the choosing of pods so that choices don't conflict
 	choosePodRequest struct {
 		newLabels       map[string]string
+		newAnnotations  map[string]string
+		spreadFunction  *fv1.Function
 		responseChannel chan *choosePodResponse
 	}
 	choosePodResponse struct {
@@ -91,7 +97,9 @@ func MakeGenericPool(
 	fsCache *fscache.FunctionServiceCache,
 	fetcherConfig *fetcherConfig.Config,
 	instanceId string,
-	enableIstio bool) (*GenericPool, error) {
+	enableIstio bool,
+	multiplex *multiplexGroups,
+	isolated *isolatedPods) (*GenericPool, error) {
 
 	gpLogger := logger.Named("generic_pool")
 
@@ -114,6 +122,8 @@ func MakeGenericPool(
 		poolInstanceId:    uniuri.NewLen(8),
 		fetcherConfig:     fetcherConfig,
 		instanceId:        instanceId,
+		multiplex:         multiplex,
+		isolated:          isolated,
 		useSvc:            false,       // defaults off -- svc takes a second or more to become routable, slowing cold start
 		useIstio:          enableIstio, // defaults off -- istio integration requires pod relabeling and it takes a second or more to become routable, slowing cold start
 	}
@@ -126,6 +136,11 @@ func MakeGenericPool(
 		return nil, errors.Wrapf(err, "error creating fetcher service account in namespace %q", gp.namespace)
 	}
 
+	err = fetcherConfig.SetupLogForwarderRoleBinding(gp.logger, gp.kubernetesClient, gp.namespace)
+	if err != nil {
+		return nil, errors.Wrapf(err, "error creating log forwarder role binding in namespace %q", gp.namespace)
+	}
+
 	// Labels for generic deployment/RS/pods.
 	gp.labelsForPool = gp.getDeployLabels()
 
@@ -157,7 +172,7 @@ func (gp *GenericPool) choosePodService() {
 	for {
 		select {
 		case req := <-gp.requestChannel:
-			pod, err := gp._choosePod(req.newLabels)
+			pod, err := gp._choosePod(req.newLabels, req.newAnnotations, req.spreadFunction)
 			if err != nil {
 				req.responseChannel <- &choosePodResponse{error: err}
 				continue
@@ -168,10 +183,19 @@ func (gp *GenericPool) choosePodService() {
 }
 
 // choosePod picks a ready pod from the pool and relabels it, waiting if necessary.
+// The annotations are added to the ones of the pod when it's relabeled. If the
+// function is spread, pods in a failure domain without a pod of the function are
+// preferred.
 // returns the pod API object.
-func (gp *GenericPool) choosePod(newLabels map[string]string) (*apiv1.Pod, error) {
+func (gp *GenericPool) choosePod(newLabels map[string]string, newAnnotations map[string]string, fn *fv1.Function) (*apiv1.Pod, error) {
+	var spreadFunction *fv1.Function
+	if fn.Spec.Spread != nil {
+		spreadFunction = fn
+	}
 	req := &choosePodRequest{
 		newLabels:       newLabels,
+		newAnnotations:  newAnnotations,
+		spreadFunction:  spreadFunction,
 		responseChannel: make(chan *choosePodResponse),
 	}
 	gp.requestChannel <- req
@@ -180,7 +204,7 @@ func (gp *GenericPool) choosePod(newLabels map[string]string) (*apiv1.Pod, error
 }
 
 // _choosePod is called serially by choosePodService
-func (gp *GenericPool) _choosePod(newLabels map[string]string) (*apiv1.Pod, error) {
+func (gp *GenericPool) _choosePod(newLabels map[string]string, newAnnotations map[string]string, spreadFunction *fv1.Function) (*apiv1.Pod, error) {
 	startTime := time.Now()
 	for {
 		// Retries took too long, error out.
@@ -224,6 +248,10 @@ func (gp *GenericPool) _choosePod(newLabels map[string]string) (*apiv1.Pod, erro
 			continue
 		}
 
+		if spreadFunction != nil {
+			readyPods = gp.spreadPods(readyPods, spreadFunction)
+		}
+
 		// Pick a ready pod.  For now just choose randomly;
 		// ideally we'd care about which node it's running on,
 		// and make a good scheduling decision.
@@ -234,6 +262,12 @@ func (gp *GenericPool) _choosePod(newLabels map[string]string) (*apiv1.Pod, erro
 			// modified, this should fail; in that case just
 			// retry.
 			chosenPod.ObjectMeta.Labels = newLabels
+			if len(newAnnotations) > 0 && chosenPod.ObjectMeta.Annotations == nil {
+				chosenPod.ObjectMeta.Annotations = make(map[string]string)
+			}
+			for k, v := range newAnnotations {
+				chosenPod.ObjectMeta.Annotations[k] = v
+			}
 			_, err = gp.kubernetesClient.CoreV1().Pods(gp.namespace).Update(chosenPod)
 			if err != nil {
 				gp.logger.Error("failed to relabel pod", zap.Error(err), zap.String("pod", chosenPod.ObjectMeta.Name))
@@ -246,6 +280,67 @@ func (gp *GenericPool) _choosePod(newLabels map[string]string) (*apiv1.Pod, erro
 	}
 }
 
+// spreadPods returns the ready pods in failure domains without a pod of the
+// function, or all of them if there are none. The spread is best-effort, the
+// pool pods are already scheduled, so any error leaves the choice as it is.
+func (gp *GenericPool) spreadPods(readyPods []*apiv1.Pod, fn *fv1.Function) []*apiv1.Pod {
+	fnPods, err := gp.kubernetesClient.CoreV1().Pods(gp.namespace).List(
+		metav1.ListOptions{
+			LabelSelector: labels.Set(util.FunctionPodSelector(fn)).AsSelector().String(),
+		})
+	if err != nil {
+		gp.logger.Warn("error listing function pods to spread them", zap.Error(err), zap.String("function", fn.Metadata.Name))
+		return readyPods
+	}
+	if len(fnPods.Items) == 0 {
+		return readyPods
+	}
+
+	topologyKey := util.SpreadTopologyKey(fn.Spec.Spread.Topology)
+	nodeDomains := make(map[string]string)
+	domain := func(pod *apiv1.Pod) (string, error) {
+		if fn.Spec.Spread.Topology == fv1.SpreadTopologyNode {
+			return pod.Spec.NodeName, nil
+		}
+		d, ok := nodeDomains[pod.Spec.NodeName]
+		if !ok {
+			node, err := gp.kubernetesClient.CoreV1().Nodes().Get(pod.Spec.NodeName, metav1.GetOptions{})
+			if err != nil {
+				return "", err
+			}
+			d = node.ObjectMeta.Labels[topologyKey]
+			nodeDomains[pod.Spec.NodeName] = d
+		}
+		return d, nil
+	}
+
+	usedDomains := make(map[string]bool)
+	for i := range fnPods.Items {
+		d, err := domain(&fnPods.Items[i])
+		if err != nil {
+			gp.logger.Warn("error getting the node of a function pod to spread it", zap.Error(err), zap.String("function", fn.Metadata.Name))
+			return readyPods
+		}
+		usedDomains[d] = true
+	}
+
+	spreadPods := make([]*apiv1.Pod, 0, len(readyPods))
+	for _, pod := range readyPods {
+		d, err := domain(pod)
+		if err != nil {
+			gp.logger.Warn("error getting the node of a pool pod to spread a function", zap.Error(err), zap.String("function", fn.Metadata.Name))
+			return readyPods
+		}
+		if !usedDomains[d] {
+			spreadPods = append(spreadPods, pod)
+		}
+	}
+	if len(spreadPods) == 0 {
+		return readyPods
+	}
+	return spreadPods
+}
+
 func (gp *GenericPool) labelsForFunction(metadata *metav1.ObjectMeta) map[string]string {
 	label := gp.getDeployLabels()
 	label[types.FUNCTION_NAME] = metadata.Name
@@ -299,7 +394,9 @@ func (gp *GenericPool) getFetcherUrl(podIP string) string {
 // specializePod chooses a pod, copies the required user-defined function to that pod
 // (via fetcher), and calls the function-run container to load it, resulting in a
 // specialized pod.
-func (gp *GenericPool) specializePod(ctx context.Context, pod *apiv1.Pod, metadata *metav1.ObjectMeta) error {
+func (gp *GenericPool) specializePod(ctx context.Context, pod *apiv1.Pod, fn *fv1.Function) error {
+	metadata := &fn.Metadata
+
 	// for fetcher we don't need to create a service, just talk to the pod directly
 	podIP := pod.Status.PodIP
 	if len(podIP) == 0 {
@@ -315,20 +412,13 @@ func (gp *GenericPool) specializePod(ctx context.Context, pod *apiv1.Pod, metada
 	fetcherUrl := gp.getFetcherUrl(podIP)
 	gp.logger.Info("calling fetcher to copy function", zap.String("function", metadata.Name), zap.String("url", fetcherUrl))
 
-	fn, err := gp.fissionClient.
-		Functions(metadata.Namespace).
-		Get(metadata.Name)
-	if err != nil {
-		return err
-	}
-
 	specializeReq := gp.fetcherConfig.NewSpecializeRequest(fn, gp.env)
 
 	gp.logger.Info("specializing pod", zap.String("function", metadata.Name))
 
 	// Fetcher will download user function to share volume of pod, and
 	// invoke environment specialize api for pod specialization.
-	err = fetcherClient.MakeClient(gp.logger, fetcherUrl).Specialize(ctx, &specializeReq)
+	err := fetcherClient.MakeClient(gp.logger, fetcherUrl).Specialize(ctx, &specializeReq)
 	if err != nil {
 		return err
 	}
@@ -351,10 +441,7 @@ func (gp *GenericPool) createPool() error {
 		gracePeriodSeconds = gp.env.Spec.TerminationGracePeriod
 	}
 
-	podAnnotations := gp.env.Metadata.Annotations
-	if podAnnotations == nil {
-		podAnnotations = make(map[string]string)
-	}
+	podAnnotations := util.PodAnnotations(&gp.env.Metadata)
 	if gp.useIstio && gp.env.Spec.AllowAccessToExternalNetwork {
 		podAnnotations["sidecar.istio.io/inject"] = "false"
 	}
@@ -409,12 +496,14 @@ func (gp *GenericPool) createPool() error {
 			},
 			Template: apiv1.PodTemplateSpec{
 				ObjectMeta: metav1.ObjectMeta{
-					Labels:      gp.labelsForPool,
+					Labels:      util.PodLabels(gp.labelsForPool, &gp.env.Metadata),
 					Annotations: podAnnotations,
 				},
 				Spec: apiv1.PodSpec{
 					Containers:         []apiv1.Container{*container},
 					ServiceAccountName: "fission-fetcher",
+					RuntimeClassName:   util.GetRuntimeClassName(gp.env),
+					ImagePullSecrets:   util.GetImagePullSecrets(gp.env),
 					// TerminationGracePeriodSeconds should be equal to the
 					// sleep time of preStop to make sure that SIGTERM is sent
 					// to pod after 6 mins.
@@ -429,6 +518,7 @@ func (gp *GenericPool) createPool() error {
 	if err != nil {
 		return err
 	}
+	gp.fetcherConfig.AddLogForwarderToPodSpec(&deployment.Spec.Template.Spec, gp.env.Metadata.Name)
 
 	if gp.env.Spec.Runtime.PodSpec != nil {
 		newPodSpec, err := util.MergePodSpec(&deployment.Spec.Template.Spec, gp.env.Spec.Runtime.PodSpec)
@@ -511,7 +601,6 @@ func (gp *GenericPool) createSvc(name string, labels map[string]string) (*apiv1.
 
 func (gp *GenericPool) GetFuncSvc(ctx context.Context, m *metav1.ObjectMeta) (*fscache.FuncSvc, error) {
 	gp.logger.Info("choosing pod from pool", zap.String("function", m.Name))
-	newLabels := gp.labelsForFunction(m)
 
 	if gp.useIstio {
 		// Istio only allows accessing pod through k8s service, and requests come to
@@ -554,12 +643,56 @@ func (gp *GenericPool) GetFuncSvc(ctx context.Context, m *metav1.ObjectMeta) (*f
 		}
 	}
 
-	pod, err := gp.choosePod(newLabels)
+	// the request may have waited for a while, don't take a pod out of
+	// the pool if its caller gave up already
+	err := util.CheckDeadline(ctx, m)
+	if err != nil {
+		return nil, err
+	}
+
+	fn, err := gp.fissionClient.Functions(m.Namespace).Get(m.Name)
+	if err != nil {
+		return nil, err
+	}
+
+	// the router asks for a pod per invocation of an isolated function,
+	// its pods must never be cached for other invocations
+	if isIsolatedPerInvocation(fn) {
+		return nil, ferror.MakeError(ferror.ErrorInvalidArgument,
+			fmt.Sprintf("function %v in namespace %v is isolated per invocation, its pods are not shared", m.Name, m.Namespace))
+	}
+
+	// a function that opted into multiplexing is loaded into the pod of
+	// its group if there is one, instead of taking a pod out of the pool
+	var groupKey string
+	if fn.Spec.InvokeStrategy.ExecutionStrategy.Multiplex && !gp.useIstio {
+		groupKey = multiplexGroupKey(fn)
+		fsvc, err := gp.getMultiplexedFuncSvc(ctx, groupKey, m, fn)
+		if err == nil {
+			return fsvc, nil
+		}
+		gp.logger.Info("cannot multiplex function, specializing a pod for it",
+			zap.String("function", m.Name),
+			zap.String("reason", err.Error()))
+	}
+
+	// function pods carry the labels and annotations of the environment
+	// and the function, unless they are shared by all functions of the
+	// environment
+	newLabels := util.PodLabels(gp.labelsForFunction(m), &gp.env.Metadata, &fn.Metadata)
+	newAnnotations := util.PodAnnotations(&fn.Metadata)
+
+	_, span := trace.StartSpan(ctx, "poolmgr.choosePod")
+	pod, err := gp.choosePod(newLabels, newAnnotations, fn)
+	span.End()
 	if err != nil {
 		return nil, err
 	}
 
-	err = gp.specializePod(ctx, pod, m)
+	specializeCtx, span := trace.StartSpan(ctx, "poolmgr.specializePod")
+	span.AddAttributes(trace.StringAttribute("fission.pod", pod.ObjectMeta.Name))
+	err = gp.specializePod(specializeCtx, pod, fn)
+	span.End()
 	if err != nil {
 		gp.scheduleDeletePod(pod.ObjectMeta.Name)
 		return nil, err
@@ -623,6 +756,68 @@ func (gp *GenericPool) GetFuncSvc(ctx context.Context, m *metav1.ObjectMeta) (*f
 		Atime:             time.Now(),
 	}
 
+	_, err = gp.fsCache.Add(*fsvc)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(groupKey) > 0 {
+		specializeReq := gp.fetcherConfig.NewSpecializeRequest(fn, gp.env)
+		gp.multiplex.add(groupKey, pod, svcHost, specializeReq.LoadReq.FilePath, m)
+	}
+
+	return fsvc, nil
+}
+
+// getMultiplexedFuncSvc loads the function into the pod specialized for
+// another function of its group.
+func (gp *GenericPool) getMultiplexedFuncSvc(ctx context.Context, groupKey string, m *metav1.ObjectMeta, fn *fv1.Function) (*fscache.FuncSvc, error) {
+	g := gp.multiplex.get(groupKey)
+	if g == nil {
+		return nil, errors.New("no pod specialized for the package of the function yet")
+	}
+
+	pod, err := gp.kubernetesClient.CoreV1().Pods(g.pod.ObjectMeta.Namespace).Get(g.pod.ObjectMeta.Name, metav1.GetOptions{})
+	if err != nil || !utils.IsReadyPod(pod) || pod.ObjectMeta.DeletionTimestamp != nil {
+		gp.multiplex.remove(groupKey)
+		return nil, errors.Errorf("multiplexed pod %v is gone", g.pod.ObjectMeta.Name)
+	}
+
+	_, span := trace.StartSpan(ctx, "poolmgr.loadFunction")
+	span.AddAttributes(trace.StringAttribute("fission.pod", pod.ObjectMeta.Name))
+	err = loadFunction(ctx, g, fn, gp.env)
+	span.End()
+	if err != nil {
+		return nil, err
+	}
+	gp.multiplex.addMember(groupKey, m)
+
+	gp.logger.Info("loaded function into multiplexed pod",
+		zap.String("pod", pod.ObjectMeta.Name),
+		zap.String("function", m.Name),
+		zap.String("functionNamespace", m.Namespace),
+		zap.String("owner", g.owner.Name))
+
+	fsvc := &fscache.FuncSvc{
+		Name:        pod.ObjectMeta.Name,
+		Function:    m,
+		Environment: gp.env,
+		Address:     g.address,
+		KubernetesObjects: []apiv1.ObjectReference{
+			{
+				Kind:            "pod",
+				Name:            pod.ObjectMeta.Name,
+				APIVersion:      pod.TypeMeta.APIVersion,
+				Namespace:       pod.ObjectMeta.Namespace,
+				ResourceVersion: pod.ObjectMeta.ResourceVersion,
+				UID:             pod.ObjectMeta.UID,
+			},
+		},
+		Executor: fscache.POOLMGR,
+		Ctime:    time.Now(),
+		Atime:    time.Now(),
+	}
+
 	_, err = gp.fsCache.Add(*fsvc)
 	if err != nil {
 		return nil, err
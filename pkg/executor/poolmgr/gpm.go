@@ -18,6 +18,7 @@ package poolmgr
 
 import (
 	"context"
+	"fmt"
 	"os"
 	"strconv"
 	"strings"
@@ -35,6 +36,7 @@ import (
 	fv1 "github.com/fission/fission/pkg/apis/fission.io/v1"
 	"github.com/fission/fission/pkg/cache"
 	"github.com/fission/fission/pkg/crd"
+	ferror "github.com/fission/fission/pkg/error"
 	"github.com/fission/fission/pkg/executor/fscache"
 	"github.com/fission/fission/pkg/executor/reaper"
 	fetcherConfig "github.com/fission/fission/pkg/fetcher/config"
@@ -71,6 +73,9 @@ type (
 		pkgController  k8sCache.Controller
 
 		idlePodReapTime time.Duration
+
+		multiplex *multiplexGroups
+		isolated  *isolatedPods
 	}
 	request struct {
 		requestType
@@ -106,6 +111,8 @@ func MakeGenericPoolManager(
 		requestChannel:   make(chan *request),
 		idlePodReapTime:  2 * time.Minute,
 		fetcherConfig:    fetcherConfig,
+		multiplex:        makeMultiplexGroups(),
+		isolated:         makeIsolatedPods(),
 	}
 	go gpm.service()
 	go gpm.eagerPoolCreator()
@@ -130,6 +137,7 @@ func (gpm *GenericPoolManager) Run(ctx context.Context) {
 	go gpm.funcController.Run(ctx.Done())
 	go gpm.pkgController.Run(ctx.Done())
 	go gpm.idleObjectReaper()
+	go gpm.isolatedPodReaper()
 }
 
 func (gpm *GenericPoolManager) RefreshFuncPods(logger *zap.Logger, f fv1.Function) error {
@@ -198,7 +206,7 @@ func (gpm *GenericPoolManager) service() {
 
 				pool, err = MakeGenericPool(gpm.logger,
 					gpm.fissionClient, gpm.kubernetesClient, req.env, poolsize,
-					ns, gpm.namespace, gpm.fsCache, gpm.fetcherConfig, gpm.instanceId, gpm.enableIstio)
+					ns, gpm.namespace, gpm.fsCache, gpm.fetcherConfig, gpm.instanceId, gpm.enableIstio, gpm.multiplex, gpm.isolated)
 				if err != nil {
 					req.responseChannel <- &response{error: err}
 					continue
@@ -264,6 +272,65 @@ func (gpm *GenericPoolManager) GetFuncSvc(ctx context.Context, metadata *metav1.
 	return pool.GetFuncSvc(ctx, metadata)
 }
 
+// GetIsolatedFuncSvc returns a pod for a single invocation of a function
+// isolated per invocation.
+func (gpm *GenericPoolManager) GetIsolatedFuncSvc(ctx context.Context, metadata *metav1.ObjectMeta) (*fscache.FuncSvc, error) {
+	env, err := gpm.getFunctionEnv(metadata)
+	if err != nil {
+		return nil, err
+	}
+
+	pool, err := gpm.GetPool(env)
+	if err != nil {
+		return nil, err
+	}
+	return pool.GetIsolatedFuncSvc(ctx, metadata)
+}
+
+// ReleaseIsolatedFuncSvc deletes the pod of an invocation of a function
+// isolated per invocation once it's done.
+func (gpm *GenericPoolManager) ReleaseIsolatedFuncSvc(address string) error {
+	pod := gpm.isolated.release(address)
+	if pod == nil {
+		return ferror.MakeError(ferror.ErrorNotFound, fmt.Sprintf("no isolated pod at address %v", address))
+	}
+	gpm.logger.Info("deleting released isolated pod",
+		zap.String("pod", pod.pod.ObjectMeta.Name),
+		zap.String("function", pod.function.Name),
+		zap.String("functionNamespace", pod.function.Namespace))
+	return pod.delete(gpm.kubernetesClient)
+}
+
+// fillIsolationPipeline specializes pods ahead of the invocations of a
+// function isolated per invocation.
+func (gpm *GenericPoolManager) fillIsolationPipeline(fn *fv1.Function) {
+	if !isIsolatedPerInvocation(fn) || fn.Spec.InvokeStrategy.ExecutionStrategy.PrespecializedPods <= 0 {
+		return
+	}
+	env, err := gpm.getFunctionEnv(&fn.Metadata)
+	if err != nil {
+		gpm.logger.Error("error getting environment of isolated function", zap.Error(err), zap.String("function", fn.Metadata.Name))
+		return
+	}
+	pool, err := gpm.GetPool(env)
+	if err != nil {
+		gpm.logger.Error("error getting pool of isolated function", zap.Error(err), zap.String("function", fn.Metadata.Name))
+		return
+	}
+	pool.fillIsolationPipeline(fn)
+}
+
+// dropIsolationPipeline deletes the pods specialized ahead for a version
+// of a function.
+func (gpm *GenericPoolManager) dropIsolationPipeline(fn *metav1.ObjectMeta) {
+	for _, pod := range gpm.isolated.drop(fn) {
+		err := pod.delete(gpm.kubernetesClient)
+		if err != nil {
+			gpm.logger.Error("error deleting prespecialized pod", zap.Error(err), zap.String("pod", pod.pod.ObjectMeta.Name))
+		}
+	}
+}
+
 func (gpm *GenericPoolManager) getFunctionEnv(m *metav1.ObjectMeta) (*fv1.Environment, error) {
 	var env *fv1.Environment
 
@@ -410,9 +477,56 @@ func (gpm *GenericPoolManager) idleObjectReaper() {
 				continue
 			}
 
+			// a multiplexed pod stays while the function it was
+			// specialized for is in use, and takes the other
+			// functions loaded into it along when it goes
+			deletePod, members := gpm.multiplex.release(fsvc.Name, fsvc.Function)
+			if !deletePod {
+				continue
+			}
+			for i := range members {
+				msvc, err := gpm.fsCache.GetByFunction(&members[i])
+				if err == nil && msvc.Name == fsvc.Name {
+					gpm.fsCache.DeleteEntry(msvc)
+				}
+			}
+
 			for _, kubeobj := range fsvc.KubernetesObjects {
 				reaper.CleanupKubeObject(gpm.logger, gpm.kubernetesClient, &kubeobj)
 			}
 		}
 	}
 }
+
+// isolatedPodReaper deletes the pods of functions isolated per invocation
+// that are no longer needed: pods specialized ahead for a function that
+// was updated or deleted, and pods never released, e.g. when the router
+// went away during the invocation.
+func (gpm *GenericPoolManager) isolatedPodReaper() {
+	for {
+		time.Sleep(gpm.idlePodReapTime)
+
+		for _, m := range gpm.isolated.pipelineFunctions() {
+			fn, err := gpm.fissionClient.Functions(m.Namespace).Get(m.Name)
+			if err != nil && !k8serrors.IsNotFound(err) {
+				gpm.logger.Error("error getting isolated function", zap.Error(err), zap.String("function", m.Name))
+				continue
+			}
+			if err == nil && crd.CacheKey(&fn.Metadata) == crd.CacheKey(&m) {
+				continue
+			}
+			gpm.dropIsolationPipeline(&m)
+		}
+
+		for _, pod := range gpm.isolated.expired(time.Now()) {
+			gpm.logger.Warn("deleting isolated pod that was never released",
+				zap.String("pod", pod.pod.ObjectMeta.Name),
+				zap.String("function", pod.function.Name),
+				zap.String("functionNamespace", pod.function.Namespace))
+			err := pod.delete(gpm.kubernetesClient)
+			if err != nil {
+				gpm.logger.Error("error deleting isolated pod", zap.Error(err), zap.String("pod", pod.pod.ObjectMeta.Name))
+			}
+		}
+	}
+}
@@ -0,0 +1,337 @@
+/*
+Copyright 2019 The Fission Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package poolmgr
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	"go.opencensus.io/trace"
+	"go.uber.org/zap"
+	apiv1 "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+
+	fv1 "github.com/fission/fission/pkg/apis/fission.io/v1"
+	"github.com/fission/fission/pkg/crd"
+	"github.com/fission/fission/pkg/executor/fscache"
+	"github.com/fission/fission/pkg/executor/util"
+	"github.com/fission/fission/pkg/types"
+)
+
+type (
+	// isolatedPods keeps the pods of functions isolated per invocation.
+	// Such a pod serves a single invocation and is deleted when the router
+	// releases it, it's never put in the function service cache. Pods
+	// specialized ahead of invocations wait in the pipeline of their
+	// function until an invocation takes them.
+	isolatedPods struct {
+		lock      sync.Mutex
+		pipelines map[string]*isolationPipeline // function cache key -> pipeline
+		inUse     map[string]*isolatedPod       // address -> pod serving an invocation
+	}
+
+	isolationPipeline struct {
+		function metav1.ObjectMeta
+		ready    []*isolatedPod
+		pending  int // pods being specialized
+	}
+
+	isolatedPod struct {
+		pod      *apiv1.Pod
+		address  string
+		function metav1.ObjectMeta
+
+		// the pod is deleted if it isn't released by then, e.g. when the
+		// router went away during the invocation
+		expiry time.Time
+	}
+)
+
+func makeIsolatedPods() *isolatedPods {
+	return &isolatedPods{
+		pipelines: make(map[string]*isolationPipeline),
+		inUse:     make(map[string]*isolatedPod),
+	}
+}
+
+// take hands out a pod specialized ahead for the function, if there is
+// one ready.
+func (ip *isolatedPods) take(fn *metav1.ObjectMeta, expiry time.Time) *isolatedPod {
+	ip.lock.Lock()
+	defer ip.lock.Unlock()
+	p, ok := ip.pipelines[crd.CacheKey(fn)]
+	if !ok || len(p.ready) == 0 {
+		return nil
+	}
+	pod := p.ready[0]
+	p.ready = p.ready[1:]
+	pod.expiry = expiry
+	ip.inUse[pod.address] = pod
+	return pod
+}
+
+// use records a pod specialized for an invocation.
+func (ip *isolatedPods) use(pod *isolatedPod) {
+	ip.lock.Lock()
+	defer ip.lock.Unlock()
+	ip.inUse[pod.address] = pod
+}
+
+// reserve returns the number of pods to specialize to fill the pipeline
+// of the function up to size, they are counted as pending.
+func (ip *isolatedPods) reserve(fn *metav1.ObjectMeta, size int) int {
+	ip.lock.Lock()
+	defer ip.lock.Unlock()
+	key := crd.CacheKey(fn)
+	p, ok := ip.pipelines[key]
+	if !ok {
+		if size <= 0 {
+			return 0
+		}
+		p = &isolationPipeline{function: *fn}
+		ip.pipelines[key] = p
+	}
+	n := size - len(p.ready) - p.pending
+	if n <= 0 {
+		return 0
+	}
+	p.pending += n
+	return n
+}
+
+// fill adds a pod specialized ahead of invocations to the pipeline of its
+// function, a nil pod stands for a failed specialization. It returns false
+// if the pipeline is gone, i.e. the function changed meanwhile; the pod has
+// to be deleted then.
+func (ip *isolatedPods) fill(fn *metav1.ObjectMeta, pod *isolatedPod) bool {
+	ip.lock.Lock()
+	defer ip.lock.Unlock()
+	p, ok := ip.pipelines[crd.CacheKey(fn)]
+	if !ok {
+		return false
+	}
+	p.pending--
+	if pod != nil {
+		p.ready = append(p.ready, pod)
+	}
+	return true
+}
+
+// release forgets the pod serving an invocation at the address, it
+// returns nil for an unknown address.
+func (ip *isolatedPods) release(address string) *isolatedPod {
+	ip.lock.Lock()
+	defer ip.lock.Unlock()
+	pod, ok := ip.inUse[address]
+	if !ok {
+		return nil
+	}
+	delete(ip.inUse, address)
+	return pod
+}
+
+// pipelineFunctions returns the functions with a pipeline.
+func (ip *isolatedPods) pipelineFunctions() []metav1.ObjectMeta {
+	ip.lock.Lock()
+	defer ip.lock.Unlock()
+	fns := make([]metav1.ObjectMeta, 0, len(ip.pipelines))
+	for _, p := range ip.pipelines {
+		fns = append(fns, p.function)
+	}
+	return fns
+}
+
+// drop removes the pipeline of a function that was updated or deleted and
+// returns its ready pods. Pods still being specialized for it are deleted
+// once they are done, see fill.
+func (ip *isolatedPods) drop(fn *metav1.ObjectMeta) []*isolatedPod {
+	ip.lock.Lock()
+	defer ip.lock.Unlock()
+	key := crd.CacheKey(fn)
+	p, ok := ip.pipelines[key]
+	if !ok {
+		return nil
+	}
+	delete(ip.pipelines, key)
+	return p.ready
+}
+
+// expired removes and returns the pods serving an invocation past their
+// expiry.
+func (ip *isolatedPods) expired(now time.Time) []*isolatedPod {
+	ip.lock.Lock()
+	defer ip.lock.Unlock()
+	var pods []*isolatedPod
+	for address, pod := range ip.inUse {
+		if now.After(pod.expiry) {
+			pods = append(pods, pod)
+			delete(ip.inUse, address)
+		}
+	}
+	return pods
+}
+
+func (pod *isolatedPod) delete(kubernetesClient *kubernetes.Clientset) error {
+	err := kubernetesClient.CoreV1().Pods(pod.pod.ObjectMeta.Namespace).Delete(pod.pod.ObjectMeta.Name, nil)
+	if k8serrors.IsNotFound(err) {
+		return nil
+	}
+	return err
+}
+
+func (pod *isolatedPod) funcSvc(env *fv1.Environment) *fscache.FuncSvc {
+	return &fscache.FuncSvc{
+		Name:        pod.pod.ObjectMeta.Name,
+		Function:    &pod.function,
+		Environment: env,
+		Address:     pod.address,
+		KubernetesObjects: []apiv1.ObjectReference{
+			{
+				Kind:            "pod",
+				Name:            pod.pod.ObjectMeta.Name,
+				APIVersion:      pod.pod.TypeMeta.APIVersion,
+				Namespace:       pod.pod.ObjectMeta.Namespace,
+				ResourceVersion: pod.pod.ObjectMeta.ResourceVersion,
+				UID:             pod.pod.ObjectMeta.UID,
+			},
+		},
+		Executor: fscache.POOLMGR,
+		Ctime:    time.Now(),
+		Atime:    time.Now(),
+	}
+}
+
+func isIsolatedPerInvocation(fn *fv1.Function) bool {
+	return fn.Spec.InvokeStrategy.ExecutionStrategy.Isolation == fv1.IsolationModeInvocation
+}
+
+// GetIsolatedFuncSvc returns a pod for a single invocation of a function
+// isolated per invocation, one specialized ahead if there is one ready.
+// The pod has to be released with ReleaseIsolatedFuncSvc once the
+// invocation is done.
+func (gp *GenericPool) GetIsolatedFuncSvc(ctx context.Context, m *metav1.ObjectMeta) (*fscache.FuncSvc, error) {
+	fn, err := gp.fissionClient.Functions(m.Namespace).Get(m.Name)
+	if err != nil {
+		return nil, err
+	}
+
+	pod := gp.isolated.take(&fn.Metadata, time.Now().Add(gp.isolatedPodLifetime(fn)))
+	if pod == nil {
+		err = util.CheckDeadline(ctx, m)
+		if err != nil {
+			return nil, err
+		}
+		pod, err = gp.specializeIsolatedPod(ctx, fn)
+		if err != nil {
+			return nil, err
+		}
+		pod.expiry = time.Now().Add(gp.isolatedPodLifetime(fn))
+		gp.isolated.use(pod)
+		gp.fsCache.IncreaseColdStarts(m.Name, string(m.UID))
+	}
+
+	gp.logger.Info("isolated pod serving invocation",
+		zap.String("pod", pod.pod.ObjectMeta.Name),
+		zap.String("function", m.Name),
+		zap.String("functionNamespace", m.Namespace),
+		zap.String("address", pod.address))
+
+	// replace the pod taken out of the pipeline
+	go gp.fillIsolationPipeline(fn)
+
+	return pod.funcSvc(gp.env), nil
+}
+
+// isolatedPodLifetime is how long a pod serving an invocation is kept if
+// it's never released.
+func (gp *GenericPool) isolatedPodLifetime(fn *fv1.Function) time.Duration {
+	return time.Duration(fn.Spec.FunctionTimeout)*time.Second + gp.idlePodReapTime
+}
+
+// specializeIsolatedPod takes a pod out of the pool and specializes it for
+// the function, a pod that fails to specialize is deleted.
+func (gp *GenericPool) specializeIsolatedPod(ctx context.Context, fn *fv1.Function) (*isolatedPod, error) {
+	// the router has to talk to the pod itself, a service may send the
+	// invocation to any pod of the function
+	if gp.useSvc || gp.useIstio {
+		return nil, errors.Errorf("function %v cannot be isolated per invocation when pods are accessed through services, e.g. with istio", fn.Metadata.Name)
+	}
+	if gp.env.Spec.AllowedFunctionsPerContainer == types.AllowedFunctionsPerContainerInfinite {
+		return nil, errors.Errorf("function %v cannot be isolated per invocation, environment %v shares its pods among functions", fn.Metadata.Name, gp.env.Metadata.Name)
+	}
+
+	newLabels := util.PodLabels(gp.labelsForFunction(&fn.Metadata), &gp.env.Metadata, &fn.Metadata)
+	newAnnotations := util.PodAnnotations(&fn.Metadata)
+
+	_, span := trace.StartSpan(ctx, "poolmgr.choosePod")
+	pod, err := gp.choosePod(newLabels, newAnnotations, fn)
+	span.End()
+	if err != nil {
+		return nil, err
+	}
+
+	specializeCtx, span := trace.StartSpan(ctx, "poolmgr.specializePod")
+	span.AddAttributes(trace.StringAttribute("fission.pod", pod.ObjectMeta.Name))
+	err = gp.specializePod(specializeCtx, pod, fn)
+	span.End()
+	if err != nil {
+		gp.scheduleDeletePod(pod.ObjectMeta.Name)
+		return nil, err
+	}
+	gp.logger.Info("specialized isolated pod", zap.String("pod", pod.ObjectMeta.Name), zap.String("function", fn.Metadata.Name))
+
+	return &isolatedPod{
+		pod:      pod,
+		address:  fmt.Sprintf("%v:8888", pod.Status.PodIP),
+		function: fn.Metadata,
+	}, nil
+}
+
+// fillIsolationPipeline specializes pods ahead of invocations of the
+// function, up to its number of prespecialized pods.
+func (gp *GenericPool) fillIsolationPipeline(fn *fv1.Function) {
+	n := gp.isolated.reserve(&fn.Metadata, fn.Spec.InvokeStrategy.ExecutionStrategy.PrespecializedPods)
+	for i := 0; i < n; i++ {
+		go func() {
+			ctx, cancel := context.WithTimeout(context.Background(), gp.podReadyTimeout)
+			defer cancel()
+
+			pod, err := gp.specializeIsolatedPod(ctx, fn)
+			if err != nil {
+				gp.logger.Error("error prespecializing isolated pod",
+					zap.Error(err),
+					zap.String("function", fn.Metadata.Name),
+					zap.String("functionNamespace", fn.Metadata.Namespace))
+				gp.isolated.fill(&fn.Metadata, nil)
+				return
+			}
+			if !gp.isolated.fill(&fn.Metadata, pod) {
+				err = pod.delete(gp.kubernetesClient)
+				if err != nil {
+					gp.logger.Error("error deleting prespecialized pod of outdated function",
+						zap.Error(err),
+						zap.String("pod", pod.pod.ObjectMeta.Name))
+				}
+			}
+		}()
+	}
+}
@@ -0,0 +1,76 @@
+/*
+Copyright 2019 The Fission Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package poolmgr
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	apiv1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestIsolatedPodsPipeline(t *testing.T) {
+	ip := makeIsolatedPods()
+	fn := metav1.ObjectMeta{Name: "a", Namespace: "default", UID: "1", ResourceVersion: "1"}
+	pod := func(name, address string) *isolatedPod {
+		return &isolatedPod{
+			pod:      &apiv1.Pod{ObjectMeta: metav1.ObjectMeta{Name: name}},
+			address:  address,
+			function: fn,
+		}
+	}
+
+	assert.Equal(t, 0, ip.reserve(&fn, 0), "no pipeline without prespecialized pods")
+	assert.Nil(t, ip.take(&fn, time.Now()))
+
+	assert.Equal(t, 2, ip.reserve(&fn, 2))
+	assert.Equal(t, 0, ip.reserve(&fn, 2), "pending pods fill the pipeline")
+	assert.True(t, ip.fill(&fn, pod("p1", "10.0.0.1:8888")))
+	assert.True(t, ip.fill(&fn, nil))
+	assert.Equal(t, 1, ip.reserve(&fn, 2), "failed specialization is replaced")
+
+	taken := ip.take(&fn, time.Now().Add(time.Minute))
+	assert.NotNil(t, taken)
+	assert.Equal(t, "p1", taken.pod.ObjectMeta.Name)
+	assert.Nil(t, ip.take(&fn, time.Now()), "a pod is taken once")
+
+	// a pod is released once
+	assert.Equal(t, taken, ip.release("10.0.0.1:8888"))
+	assert.Nil(t, ip.release("10.0.0.1:8888"))
+
+	// the pipeline of an updated function is dropped, pods still being
+	// specialized for it are deleted
+	assert.Len(t, ip.pipelineFunctions(), 1)
+	assert.Empty(t, ip.drop(&fn))
+	assert.False(t, ip.fill(&fn, pod("p2", "10.0.0.2:8888")))
+	assert.Empty(t, ip.pipelineFunctions())
+}
+
+func TestIsolatedPodsExpired(t *testing.T) {
+	ip := makeIsolatedPods()
+	now := time.Now()
+	ip.use(&isolatedPod{pod: &apiv1.Pod{}, address: "10.0.0.1:8888", expiry: now.Add(-time.Second)})
+	ip.use(&isolatedPod{pod: &apiv1.Pod{}, address: "10.0.0.2:8888", expiry: now.Add(time.Minute)})
+
+	expired := ip.expired(now)
+	assert.Len(t, expired, 1)
+	assert.Equal(t, "10.0.0.1:8888", expired[0].address)
+	assert.Nil(t, ip.release("10.0.0.1:8888"), "expired pods are forgotten")
+	assert.NotNil(t, ip.release("10.0.0.2:8888"))
+}
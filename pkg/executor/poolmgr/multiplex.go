@@ -0,0 +1,202 @@
+/*
+Copyright 2019 The Fission Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package poolmgr
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/pkg/errors"
+	"golang.org/x/net/context/ctxhttp"
+	apiv1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	fv1 "github.com/fission/fission/pkg/apis/fission.io/v1"
+	"github.com/fission/fission/pkg/crd"
+	"github.com/fission/fission/pkg/types"
+)
+
+type (
+	// multiplexGroups keeps the pods specialized for functions that opted
+	// into multiplexing, so that other functions of the same group load
+	// their entrypoint into the pod instead of specializing another one.
+	multiplexGroups struct {
+		lock   sync.Mutex
+		groups map[string]*multiplexGroup // group key -> group
+		byPod  map[string]*multiplexGroup // pod name -> group
+	}
+
+	multiplexGroup struct {
+		key      string
+		pod      *apiv1.Pod
+		address  string
+		filePath string
+
+		// owner is the function the pod was specialized for, the
+		// address of the pod is cached and touched for it
+		owner   metav1.ObjectMeta
+		members map[string]metav1.ObjectMeta // function cache key -> function
+	}
+)
+
+func makeMultiplexGroups() *multiplexGroups {
+	return &multiplexGroups{
+		groups: make(map[string]*multiplexGroup),
+		byPod:  make(map[string]*multiplexGroup),
+	}
+}
+
+// multiplexGroupKey returns the key of the functions that can share a pod:
+// the pod has the package, secrets and config maps of the function it was
+// specialized for, so they have to be the same.
+func multiplexGroupKey(fn *fv1.Function) string {
+	pkgRef := fn.Spec.Package.PackageRef
+	parts := []string{
+		fmt.Sprintf("%v/%v/%v", pkgRef.Namespace, pkgRef.Name, pkgRef.ResourceVersion),
+	}
+
+	secrets := make([]string, 0, len(fn.Spec.Secrets))
+	for _, s := range fn.Spec.Secrets {
+		secrets = append(secrets, fmt.Sprintf("%v/%v", s.Namespace, s.Name))
+	}
+	sort.Strings(secrets)
+
+	cfgmaps := make([]string, 0, len(fn.Spec.ConfigMaps))
+	for _, c := range fn.Spec.ConfigMaps {
+		cfgmaps = append(cfgmaps, fmt.Sprintf("%v/%v", c.Namespace, c.Name))
+	}
+	sort.Strings(cfgmaps)
+
+	parts = append(parts, strings.Join(secrets, ","), strings.Join(cfgmaps, ","))
+	return strings.Join(parts, "|")
+}
+
+// get returns the group of a function, if a pod was specialized for one of
+// its functions.
+func (mg *multiplexGroups) get(key string) *multiplexGroup {
+	mg.lock.Lock()
+	defer mg.lock.Unlock()
+	g, ok := mg.groups[key]
+	if !ok {
+		return nil
+	}
+	gCopy := *g
+	return &gCopy
+}
+
+// add registers the pod specialized for the owner function. A group
+// specialized concurrently by another function is kept.
+func (mg *multiplexGroups) add(key string, pod *apiv1.Pod, address string, filePath string, owner *metav1.ObjectMeta) {
+	mg.lock.Lock()
+	defer mg.lock.Unlock()
+	if _, ok := mg.groups[key]; ok {
+		return
+	}
+	g := &multiplexGroup{
+		key:      key,
+		pod:      pod,
+		address:  address,
+		filePath: filePath,
+		owner:    *owner,
+		members:  make(map[string]metav1.ObjectMeta),
+	}
+	mg.groups[key] = g
+	mg.byPod[pod.ObjectMeta.Name] = g
+}
+
+// addMember records a function that loaded its entrypoint into the pod of
+// a group.
+func (mg *multiplexGroups) addMember(key string, fn *metav1.ObjectMeta) {
+	mg.lock.Lock()
+	defer mg.lock.Unlock()
+	if g, ok := mg.groups[key]; ok {
+		g.members[crd.CacheKey(fn)] = *fn
+	}
+}
+
+// remove forgets the group of a pod that was deleted or is no longer valid.
+func (mg *multiplexGroups) remove(key string) {
+	mg.lock.Lock()
+	defer mg.lock.Unlock()
+	if g, ok := mg.groups[key]; ok {
+		delete(mg.byPod, g.pod.ObjectMeta.Name)
+		delete(mg.groups, key)
+	}
+}
+
+// release is called when the function service of fn on a pod is idle. It
+// returns whether the pod can be deleted, along with the other functions
+// served by the pod, whose function services go away with it. A pod is
+// only deleted once the function it was specialized for is idle.
+func (mg *multiplexGroups) release(podName string, fn *metav1.ObjectMeta) (bool, []metav1.ObjectMeta) {
+	mg.lock.Lock()
+	defer mg.lock.Unlock()
+
+	g, ok := mg.byPod[podName]
+	if !ok {
+		// not a multiplexed pod
+		return true, nil
+	}
+	if crd.CacheKey(fn) != crd.CacheKey(&g.owner) {
+		return false, nil
+	}
+
+	members := make([]metav1.ObjectMeta, 0, len(g.members))
+	for _, m := range g.members {
+		members = append(members, m)
+	}
+	delete(mg.byPod, podName)
+	delete(mg.groups, g.key)
+	return true, members
+}
+
+// loadFunction asks the environment of a multiplexed pod to serve another
+// function, by loading its entrypoint from the package of the pod.
+// Requests are dispatched to the entrypoints by the function metadata
+// headers set by the router.
+func loadFunction(ctx context.Context, g *multiplexGroup, fn *fv1.Function, env *fv1.Environment) error {
+	loadReq := types.FunctionLoadRequest{
+		FilePath:         g.filePath,
+		FunctionName:     fn.Spec.Package.FunctionName,
+		FunctionMetadata: &fn.Metadata,
+		EnvVersion:       env.Spec.Version,
+	}
+	body, err := json.Marshal(loadReq)
+	if err != nil {
+		return err
+	}
+
+	url := fmt.Sprintf("http://%v%v", g.address, types.FunctionLoadPath)
+	resp, err := ctxhttp.Post(ctx, http.DefaultClient, url, "application/json", bytes.NewReader(body))
+	if err != nil {
+		return errors.Wrap(err, "error loading function into multiplexed pod")
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		msg, _ := ioutil.ReadAll(resp.Body)
+		return errors.Errorf("environment failed to load function into multiplexed pod, status %v: %v", resp.StatusCode, string(msg))
+	}
+	return nil
+}
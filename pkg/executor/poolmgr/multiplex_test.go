@@ -0,0 +1,71 @@
+/*
+Copyright 2019 The Fission Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package poolmgr
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	apiv1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	fv1 "github.com/fission/fission/pkg/apis/fission.io/v1"
+)
+
+func TestMultiplexGroupKey(t *testing.T) {
+	fn := func(name string, secrets ...string) *fv1.Function {
+		f := &fv1.Function{
+			Metadata: metav1.ObjectMeta{Name: name, Namespace: "default"},
+		}
+		f.Spec.Package.PackageRef = fv1.PackageRef{Namespace: "default", Name: "pkg", ResourceVersion: "1"}
+		for _, s := range secrets {
+			f.Spec.Secrets = append(f.Spec.Secrets, fv1.SecretReference{Namespace: "default", Name: s})
+		}
+		return f
+	}
+
+	assert.Equal(t, multiplexGroupKey(fn("a", "s1", "s2")), multiplexGroupKey(fn("b", "s2", "s1")))
+	assert.NotEqual(t, multiplexGroupKey(fn("a", "s1")), multiplexGroupKey(fn("b")))
+
+	other := fn("c")
+	other.Spec.Package.PackageRef.ResourceVersion = "2"
+	assert.NotEqual(t, multiplexGroupKey(fn("a")), multiplexGroupKey(other))
+}
+
+func TestMultiplexRelease(t *testing.T) {
+	mg := makeMultiplexGroups()
+	owner := metav1.ObjectMeta{Name: "a", Namespace: "default", UID: "1", ResourceVersion: "1"}
+	member := metav1.ObjectMeta{Name: "b", Namespace: "default", UID: "2", ResourceVersion: "1"}
+	pod := &apiv1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "pod"}}
+
+	mg.add("key", pod, "10.0.0.1:8888", "/userfunc/deployarchive", &owner)
+	mg.addMember("key", &member)
+	assert.NotNil(t, mg.get("key"))
+
+	ok, members := mg.release("other-pod", &member)
+	assert.True(t, ok, "pods of other functions are not multiplexed")
+	assert.Empty(t, members)
+
+	ok, _ = mg.release("pod", &member)
+	assert.False(t, ok, "pod is kept while its owner is in use")
+	assert.NotNil(t, mg.get("key"))
+
+	ok, members = mg.release("pod", &owner)
+	assert.True(t, ok)
+	assert.Equal(t, []metav1.ObjectMeta{member}, members)
+	assert.Nil(t, mg.get("key"))
+}
@@ -0,0 +1,179 @@
+/*
+Copyright 2019 The Fission Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package executor
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"regexp"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+	"golang.org/x/net/context/ctxhttp"
+	apiv1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+
+	ferror "github.com/fission/fission/pkg/error"
+	"github.com/fission/fission/pkg/types"
+)
+
+var validProfileType = regexp.MustCompile(`^[a-z]+$`)
+
+func (executor *Executor) profileFunctionApi(w http.ResponseWriter, r *http.Request) {
+	body, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		http.Error(w, "Failed to read request", http.StatusInternalServerError)
+		return
+	}
+
+	req := types.FunctionProfileRequest{}
+	err = json.Unmarshal(body, &req)
+	if err != nil {
+		http.Error(w, "Failed to parse request", http.StatusBadRequest)
+		return
+	}
+
+	resp, err := executor.profileFunction(r.Context(), &req)
+	if err != nil {
+		code, msg := ferror.GetHTTPError(err)
+		executor.logger.Error("error profiling function",
+			zap.Error(err),
+			zap.String("function", req.Function.Name),
+			zap.String("fission_http_error", msg))
+		http.Error(w, msg, code)
+		return
+	}
+
+	data, err := json.Marshal(resp)
+	if err != nil {
+		http.Error(w, "Failed to marshal response", http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.Write(data)
+}
+
+// profileFunction captures a profile from every running pod of a function
+// concurrently. The environment container serves the profiles in the pprof
+// HTTP format, a failure of a single pod is reported in its profile instead
+// of failing the whole capture.
+func (executor *Executor) profileFunction(ctx context.Context, req *types.FunctionProfileRequest) (*types.FunctionProfileResponse, error) {
+	if req.Seconds <= 0 || req.Seconds > types.MaxProfileSeconds {
+		return nil, ferror.MakeError(ferror.ErrorInvalidArgument,
+			fmt.Sprintf("profile duration must be between 1 and %v seconds", types.MaxProfileSeconds))
+	}
+	if len(req.Type) == 0 {
+		req.Type = types.ProfileTypeCPU
+	}
+	if !validProfileType.MatchString(req.Type) {
+		return nil, ferror.MakeError(ferror.ErrorInvalidArgument, fmt.Sprintf("invalid profile type %q", req.Type))
+	}
+
+	// Make sure the function exists before looking for its pods
+	_, err := executor.fissionClient.Functions(req.Function.Namespace).Get(req.Function.Name)
+	if err != nil {
+		return nil, err
+	}
+
+	selector := labels.Set{
+		types.FUNCTION_NAME:      req.Function.Name,
+		types.FUNCTION_NAMESPACE: req.Function.Namespace,
+	}
+	podList, err := executor.kubernetesClient.CoreV1().Pods(metav1.NamespaceAll).List(metav1.ListOptions{
+		LabelSelector: selector.AsSelector().String(),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var pods []apiv1.Pod
+	for _, pod := range podList.Items {
+		if pod.Status.Phase == apiv1.PodRunning && len(pod.Status.PodIP) > 0 && pod.DeletionTimestamp == nil {
+			pods = append(pods, pod)
+		}
+	}
+	if len(pods) == 0 {
+		return nil, ferror.MakeError(ferror.ErrorNotFound,
+			fmt.Sprintf("no running pods found for function %v, invoke the function before profiling it", req.Function.Name))
+	}
+
+	executor.logger.Info("profiling function",
+		zap.String("function_name", req.Function.Name),
+		zap.String("function_namespace", req.Function.Namespace),
+		zap.String("type", req.Type),
+		zap.Int("seconds", req.Seconds),
+		zap.Int("pods", len(pods)))
+
+	resp := &types.FunctionProfileResponse{
+		Profiles: make([]types.FunctionProfile, len(pods)),
+	}
+	var wg sync.WaitGroup
+	for i := range pods {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			resp.Profiles[i] = executor.profilePod(ctx, &pods[i], req)
+		}(i)
+	}
+	wg.Wait()
+
+	return resp, nil
+}
+
+func (executor *Executor) profilePod(ctx context.Context, pod *apiv1.Pod, req *types.FunctionProfileRequest) types.FunctionProfile {
+	profile := types.FunctionProfile{Pod: pod.Name}
+
+	path := req.Type
+	if path == types.ProfileTypeCPU {
+		path = "profile"
+	}
+	url := fmt.Sprintf("http://%v:8888/debug/pprof/%v?seconds=%v", pod.Status.PodIP, path, req.Seconds)
+
+	// leave some time for the environment to write the profile after the capture
+	ctx, cancel := context.WithTimeout(ctx, time.Duration(req.Seconds)*time.Second+30*time.Second)
+	defer cancel()
+
+	resp, err := ctxhttp.Get(ctx, nil, url)
+	if err != nil {
+		profile.Error = err.Error()
+		return profile
+	}
+	defer resp.Body.Close()
+
+	data, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		profile.Error = err.Error()
+		return profile
+	}
+	if resp.StatusCode != http.StatusOK {
+		if resp.StatusCode == http.StatusNotFound {
+			profile.Error = fmt.Sprintf("the environment does not support %v profiles", req.Type)
+		} else {
+			profile.Error = fmt.Sprintf("error capturing profile: %v %v", resp.Status, string(data))
+		}
+		return profile
+	}
+
+	profile.ContentType = resp.Header.Get("Content-Type")
+	profile.Data = data
+	return profile
+}
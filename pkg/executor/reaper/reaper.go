@@ -294,7 +294,8 @@ func CleanupRoleBindings(logger *zap.Logger, client *kubernetes.Clientset, fissi
 						break
 					}
 
-					if fn.Spec.InvokeStrategy.ExecutionStrategy.ExecutorType == types.ExecutorTypeNewdeploy {
+					if fn.Spec.InvokeStrategy.ExecutionStrategy.ExecutorType == types.ExecutorTypeNewdeploy ||
+						fn.Spec.InvokeStrategy.ExecutionStrategy.ExecutorType == types.ExecutorTypeContainer {
 						ndmFunc = true
 						break
 					}
@@ -0,0 +1,150 @@
+/*
+Copyright 2019 The Fission Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package executor
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+
+	"go.uber.org/zap"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	fv1 "github.com/fission/fission/pkg/apis/fission.io/v1"
+	ferror "github.com/fission/fission/pkg/error"
+	"github.com/fission/fission/pkg/executor/fscache"
+	"github.com/fission/fission/pkg/types"
+)
+
+func (executor *Executor) refreshPackageApi(w http.ResponseWriter, r *http.Request) {
+	body, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		http.Error(w, "Failed to read request", http.StatusInternalServerError)
+		return
+	}
+
+	req := types.PackageRefreshRequest{}
+	err = json.Unmarshal(body, &req)
+	if err != nil {
+		http.Error(w, "Failed to parse request", http.StatusBadRequest)
+		return
+	}
+
+	resp, err := executor.refreshPackage(&req)
+	if err != nil {
+		code, msg := ferror.GetHTTPError(err)
+		executor.logger.Error("error refreshing package functions",
+			zap.Error(err),
+			zap.String("package", req.Package.Name),
+			zap.String("fission_http_error", msg))
+		http.Error(w, msg, code)
+		return
+	}
+
+	data, err := json.Marshal(resp)
+	if err != nil {
+		http.Error(w, "Failed to marshal response", http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.Write(data)
+}
+
+// refreshPackage replaces the pods of the functions using the package, right
+// away or on the next request for the function depending on the strategy.
+// Failing to refresh a function is logged and doesn't stop the others.
+func (executor *Executor) refreshPackage(req *types.PackageRefreshRequest) (*types.PackageRefreshResponse, error) {
+	if req.Strategy != types.PackageRefreshImmediate && req.Strategy != types.PackageRefreshLazy {
+		return nil, ferror.MakeError(ferror.ErrorInvalidArgument,
+			fmt.Sprintf("invalid refresh strategy %q, must be %q or %q", req.Strategy, types.PackageRefreshImmediate, types.PackageRefreshLazy))
+	}
+
+	fnList, err := executor.fissionClient.Functions(req.Package.Namespace).List(metav1.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+
+	resp := &types.PackageRefreshResponse{}
+	for _, fn := range fnList.Items {
+		if fn.Spec.Package.PackageRef.Name != req.Package.Name ||
+			fn.Spec.Package.PackageRef.Namespace != req.Package.Namespace {
+			continue
+		}
+
+		if req.Strategy == types.PackageRefreshLazy {
+			executor.staleFuncLock.Lock()
+			executor.staleFuncs[fn.Metadata.UID] = true
+			executor.staleFuncLock.Unlock()
+		} else {
+			err := executor.refreshFunctionPods(fn)
+			if err != nil {
+				executor.logger.Error("error refreshing function pods",
+					zap.Error(err),
+					zap.String("function_name", fn.Metadata.Name),
+					zap.String("function_namespace", fn.Metadata.Namespace))
+				continue
+			}
+		}
+		resp.Functions = append(resp.Functions, fn.Metadata.Name)
+	}
+
+	executor.logger.Info("refreshed functions of package",
+		zap.String("package_name", req.Package.Name),
+		zap.String("package_namespace", req.Package.Namespace),
+		zap.String("strategy", string(req.Strategy)),
+		zap.Strings("functions", resp.Functions))
+	return resp, nil
+}
+
+// refreshIfStale replaces the pods of a function marked by a lazy package
+// refresh, before its function service is looked up.
+func (executor *Executor) refreshIfStale(m *metav1.ObjectMeta) {
+	executor.staleFuncLock.Lock()
+	stale := executor.staleFuncs[m.UID]
+	delete(executor.staleFuncs, m.UID)
+	executor.staleFuncLock.Unlock()
+	if !stale {
+		return
+	}
+
+	fn, err := executor.fissionClient.Functions(m.Namespace).Get(m.Name)
+	if err == nil {
+		err = executor.refreshFunctionPods(*fn)
+	}
+	if err != nil {
+		executor.logger.Error("error refreshing stale function pods",
+			zap.Error(err),
+			zap.String("function_name", m.Name),
+			zap.String("function_namespace", m.Namespace))
+	}
+}
+
+func (executor *Executor) refreshFunctionPods(fn fv1.Function) error {
+	var err error
+	switch fn.Spec.InvokeStrategy.ExecutionStrategy.ExecutorType {
+	case fv1.ExecutorTypeNewdeploy, fv1.ExecutorTypeContainer:
+		err = executor.ndm.RefreshFuncPods(executor.logger, fn)
+	default:
+		err = executor.gpm.RefreshFuncPods(executor.logger, fn)
+		// a function without a cached service has no specialized pods
+		if fscache.IsNotFoundError(err) {
+			err = nil
+		}
+	}
+	return err
+}
@@ -0,0 +1,36 @@
+/*
+Copyright 2019 The Fission Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package util
+
+import (
+	"context"
+	"fmt"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	ferror "github.com/fission/fission/pkg/error"
+)
+
+// CheckDeadline returns an error if the caller waiting for the service of
+// the function gave up already, so that no pod is specialized for nothing.
+func CheckDeadline(ctx context.Context, m *metav1.ObjectMeta) error {
+	if err := ctx.Err(); err != nil {
+		return ferror.MakeError(ferror.ErrorRequestTimeout,
+			fmt.Sprintf("not specializing function %v in namespace %v: %v", m.Name, m.Namespace, err))
+	}
+	return nil
+}
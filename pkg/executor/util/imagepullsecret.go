@@ -0,0 +1,32 @@
+/*
+Copyright 2019 The Fission Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package util
+
+import (
+	apiv1 "k8s.io/api/core/v1"
+
+	fv1 "github.com/fission/fission/pkg/apis/fission.io/v1"
+)
+
+// GetImagePullSecrets returns the image pull secrets for the pods of the
+// environment, or nil if the images are pulled without credentials.
+func GetImagePullSecrets(env *fv1.Environment) []apiv1.LocalObjectReference {
+	if len(env.Spec.ImagePullSecret) == 0 {
+		return nil
+	}
+	return []apiv1.LocalObjectReference{{Name: env.Spec.ImagePullSecret}}
+}
@@ -102,6 +102,10 @@ func MergePodSpec(srcPodSpec *apiv1.PodSpec, targetPodSpec *apiv1.PodSpec) (*api
 		srcPodSpec.PriorityClassName = targetPodSpec.PriorityClassName
 	}
 
+	if targetPodSpec.RuntimeClassName != nil {
+		srcPodSpec.RuntimeClassName = targetPodSpec.RuntimeClassName
+	}
+
 	if targetPodSpec.TerminationGracePeriodSeconds != nil {
 		srcPodSpec.TerminationGracePeriodSeconds = targetPodSpec.TerminationGracePeriodSeconds
 	}
@@ -0,0 +1,78 @@
+/*
+Copyright 2019 The Fission Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package util
+
+import (
+	apiv1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	fv1 "github.com/fission/fission/pkg/apis/fission.io/v1"
+)
+
+// lastAppliedConfigAnnotation is set by kubectl apply on the CRDs and
+// must not be copied to the pods.
+const lastAppliedConfigAnnotation = "kubectl.kubernetes.io/last-applied-configuration"
+
+// PodLabels returns the labels of a function pod: the user labels of the
+// given objects, in order, overridden by the labels fission uses to select
+// and manage the pod.
+func PodLabels(fissionLabels map[string]string, objs ...*metav1.ObjectMeta) map[string]string {
+	podLabels := make(map[string]string)
+	for _, obj := range objs {
+		for k, v := range obj.Labels {
+			podLabels[k] = v
+		}
+	}
+	for k, v := range fissionLabels {
+		podLabels[k] = v
+	}
+	return podLabels
+}
+
+// PodAnnotations returns the annotations of the given objects, in order,
+// for a function pod. The caller may add annotations of its own to the
+// returned map.
+func PodAnnotations(objs ...*metav1.ObjectMeta) map[string]string {
+	podAnnotations := make(map[string]string)
+	for _, obj := range objs {
+		for k, v := range obj.Annotations {
+			if k == lastAppliedConfigAnnotation {
+				continue
+			}
+			podAnnotations[k] = v
+		}
+	}
+	return podAnnotations
+}
+
+// ApplyFunctionScheduling adds the node selector, the tolerations and the
+// spread of the function to the pod spec. The node selector of the
+// function takes precedence over the one of the environment.
+func ApplyFunctionScheduling(podSpec *apiv1.PodSpec, fn *fv1.Function) {
+	if len(fn.Spec.NodeSelector) > 0 {
+		nodeSelector := make(map[string]string)
+		for k, v := range podSpec.NodeSelector {
+			nodeSelector[k] = v
+		}
+		for k, v := range fn.Spec.NodeSelector {
+			nodeSelector[k] = v
+		}
+		podSpec.NodeSelector = nodeSelector
+	}
+	podSpec.Tolerations = append(podSpec.Tolerations, fn.Spec.Tolerations...)
+	applyFunctionSpread(podSpec, fn)
+}
@@ -0,0 +1,86 @@
+/*
+Copyright 2019 The Fission Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package util
+
+import (
+	"reflect"
+	"testing"
+
+	apiv1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	fv1 "github.com/fission/fission/pkg/apis/fission.io/v1"
+)
+
+func TestPodLabels(t *testing.T) {
+	env := &metav1.ObjectMeta{Labels: map[string]string{"team": "a", "tier": "env"}}
+	fn := &metav1.ObjectMeta{Labels: map[string]string{"tier": "fn", "functionName": "spoofed"}}
+
+	labels := PodLabels(map[string]string{"functionName": "hello"}, env, fn)
+	expected := map[string]string{"team": "a", "tier": "fn", "functionName": "hello"}
+	if !reflect.DeepEqual(labels, expected) {
+		t.Errorf("expected labels %v, got %v", expected, labels)
+	}
+}
+
+func TestPodAnnotations(t *testing.T) {
+	env := &metav1.ObjectMeta{Annotations: map[string]string{
+		"a":                         "env",
+		lastAppliedConfigAnnotation: "{}",
+	}}
+	fn := &metav1.ObjectMeta{Annotations: map[string]string{"a": "fn", "b": "fn"}}
+
+	annotations := PodAnnotations(env, fn)
+	expected := map[string]string{"a": "fn", "b": "fn"}
+	if !reflect.DeepEqual(annotations, expected) {
+		t.Errorf("expected annotations %v, got %v", expected, annotations)
+	}
+
+	// the annotations of the objects must not be modified by the caller
+	annotations["c"] = "executor"
+	if _, ok := fn.Annotations["c"]; ok {
+		t.Error("annotations of the function were modified")
+	}
+}
+
+func TestApplyFunctionScheduling(t *testing.T) {
+	podSpec := &apiv1.PodSpec{
+		NodeSelector: map[string]string{"disk": "hdd", "zone": "a"},
+		Tolerations:  []apiv1.Toleration{{Key: "env", Operator: apiv1.TolerationOpExists}},
+	}
+	fn := &fv1.Function{Spec: fv1.FunctionSpec{
+		NodeSelector: map[string]string{"disk": "ssd"},
+		Tolerations:  []apiv1.Toleration{{Key: "gpu", Operator: apiv1.TolerationOpEqual, Value: "true", Effect: apiv1.TaintEffectNoSchedule}},
+	}}
+
+	ApplyFunctionScheduling(podSpec, fn)
+
+	expectedSelector := map[string]string{"disk": "ssd", "zone": "a"}
+	if !reflect.DeepEqual(podSpec.NodeSelector, expectedSelector) {
+		t.Errorf("expected node selector %v, got %v", expectedSelector, podSpec.NodeSelector)
+	}
+	if len(podSpec.Tolerations) != 2 || podSpec.Tolerations[1].Key != "gpu" {
+		t.Errorf("expected the toleration of the function to be added, got %v", podSpec.Tolerations)
+	}
+
+	// a function without scheduling constraints leaves the pod spec as is
+	podSpec = &apiv1.PodSpec{}
+	ApplyFunctionScheduling(podSpec, &fv1.Function{})
+	if podSpec.NodeSelector != nil || podSpec.Tolerations != nil {
+		t.Errorf("expected no scheduling constraints, got %v and %v", podSpec.NodeSelector, podSpec.Tolerations)
+	}
+}
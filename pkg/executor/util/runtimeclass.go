@@ -0,0 +1,31 @@
+/*
+Copyright 2019 The Fission Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package util
+
+import (
+	fv1 "github.com/fission/fission/pkg/apis/fission.io/v1"
+)
+
+// GetRuntimeClassName returns the runtime class name for the pods of the
+// environment, or nil to use the default container runtime of the cluster.
+func GetRuntimeClassName(env *fv1.Environment) *string {
+	if len(env.Spec.RuntimeClassName) == 0 {
+		return nil
+	}
+	runtimeClassName := env.Spec.RuntimeClassName
+	return &runtimeClassName
+}
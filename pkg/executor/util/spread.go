@@ -0,0 +1,81 @@
+/*
+Copyright 2019 The Fission Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package util
+
+import (
+	apiv1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	fv1 "github.com/fission/fission/pkg/apis/fission.io/v1"
+	"github.com/fission/fission/pkg/types"
+)
+
+const (
+	hostnameTopologyKey = "kubernetes.io/hostname"
+	zoneTopologyKey     = "failure-domain.beta.kubernetes.io/zone"
+)
+
+// SpreadTopologyKey returns the node label of the failure domain of a
+// spread topology.
+func SpreadTopologyKey(topology fv1.SpreadTopology) string {
+	if topology == fv1.SpreadTopologyZone {
+		return zoneTopologyKey
+	}
+	return hostnameTopologyKey
+}
+
+// FunctionPodSelector returns the labels of the pods of a function, both
+// executors set them on specialized pods.
+func FunctionPodSelector(fn *fv1.Function) map[string]string {
+	return map[string]string{
+		types.FUNCTION_NAME:      fn.Metadata.Name,
+		types.FUNCTION_NAMESPACE: fn.Metadata.Namespace,
+	}
+}
+
+// applyFunctionSpread adds a pod anti-affinity against the other pods of
+// the function to the pod spec, if the function is spread.
+func applyFunctionSpread(podSpec *apiv1.PodSpec, fn *fv1.Function) {
+	spread := fn.Spec.Spread
+	if spread == nil {
+		return
+	}
+
+	term := apiv1.PodAffinityTerm{
+		LabelSelector: &metav1.LabelSelector{
+			MatchLabels: FunctionPodSelector(fn),
+		},
+		TopologyKey: SpreadTopologyKey(spread.Topology),
+	}
+
+	if podSpec.Affinity == nil {
+		podSpec.Affinity = &apiv1.Affinity{}
+	}
+	if podSpec.Affinity.PodAntiAffinity == nil {
+		podSpec.Affinity.PodAntiAffinity = &apiv1.PodAntiAffinity{}
+	}
+	antiAffinity := podSpec.Affinity.PodAntiAffinity
+
+	if spread.Required {
+		antiAffinity.RequiredDuringSchedulingIgnoredDuringExecution = append(
+			antiAffinity.RequiredDuringSchedulingIgnoredDuringExecution, term)
+	} else {
+		antiAffinity.PreferredDuringSchedulingIgnoredDuringExecution = append(
+			antiAffinity.PreferredDuringSchedulingIgnoredDuringExecution,
+			apiv1.WeightedPodAffinityTerm{Weight: 100, PodAffinityTerm: term})
+	}
+}
@@ -0,0 +1,66 @@
+/*
+Copyright 2019 The Fission Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package util
+
+import (
+	"testing"
+
+	apiv1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	fv1 "github.com/fission/fission/pkg/apis/fission.io/v1"
+)
+
+func TestApplyFunctionSpread(t *testing.T) {
+	fn := &fv1.Function{
+		Metadata: metav1.ObjectMeta{Name: "hello", Namespace: "default"},
+		Spec: fv1.FunctionSpec{
+			Spread: &fv1.Spread{Topology: fv1.SpreadTopologyZone},
+		},
+	}
+
+	podSpec := &apiv1.PodSpec{}
+	ApplyFunctionScheduling(podSpec, fn)
+
+	antiAffinity := podSpec.Affinity.PodAntiAffinity
+	if len(antiAffinity.PreferredDuringSchedulingIgnoredDuringExecution) != 1 ||
+		len(antiAffinity.RequiredDuringSchedulingIgnoredDuringExecution) != 0 {
+		t.Fatalf("expected a preferred anti-affinity term, got %v", antiAffinity)
+	}
+	term := antiAffinity.PreferredDuringSchedulingIgnoredDuringExecution[0].PodAffinityTerm
+	if term.TopologyKey != zoneTopologyKey {
+		t.Errorf("expected topology key %v, got %v", zoneTopologyKey, term.TopologyKey)
+	}
+	if term.LabelSelector.MatchLabels["functionName"] != "hello" {
+		t.Errorf("expected the pods of the function to be selected, got %v", term.LabelSelector)
+	}
+
+	// a required spread keeps the affinity of the environment pod spec
+	fn.Spec.Spread = &fv1.Spread{Topology: fv1.SpreadTopologyNode, Required: true}
+	podSpec = &apiv1.PodSpec{
+		Affinity: &apiv1.Affinity{NodeAffinity: &apiv1.NodeAffinity{}},
+	}
+	ApplyFunctionScheduling(podSpec, fn)
+
+	if podSpec.Affinity.NodeAffinity == nil {
+		t.Error("expected the node affinity to be kept")
+	}
+	required := podSpec.Affinity.PodAntiAffinity.RequiredDuringSchedulingIgnoredDuringExecution
+	if len(required) != 1 || required[0].TopologyKey != hostnameTopologyKey {
+		t.Errorf("expected a required anti-affinity term on the node, got %v", required)
+	}
+}
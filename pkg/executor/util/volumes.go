@@ -0,0 +1,72 @@
+/*
+Copyright 2019 The Fission Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package util
+
+import (
+	"fmt"
+
+	apiv1 "k8s.io/api/core/v1"
+
+	fv1 "github.com/fission/fission/pkg/apis/fission.io/v1"
+)
+
+// functionVolumeName names the pod volume of a function volume apart from
+// the volumes of fission and of the environment.
+func functionVolumeName(i int) string {
+	return fmt.Sprintf("function-volume-%v", i)
+}
+
+// ApplyFunctionVolumes adds the volumes of the function to the pod spec
+// and mounts them into the function container, which is named after the
+// function.
+func ApplyFunctionVolumes(podSpec *apiv1.PodSpec, fn *fv1.Function) {
+	if len(fn.Spec.Volumes) == 0 {
+		return
+	}
+
+	var mounts []apiv1.VolumeMount
+	for i, v := range fn.Spec.Volumes {
+		volume := apiv1.Volume{Name: functionVolumeName(i)}
+		switch v.Type {
+		case fv1.FunctionVolumeTypePVC:
+			volume.VolumeSource.PersistentVolumeClaim = &apiv1.PersistentVolumeClaimVolumeSource{
+				ClaimName: v.Name,
+				ReadOnly:  v.ReadOnly,
+			}
+		case fv1.FunctionVolumeTypeEmptyDir:
+			volume.VolumeSource.EmptyDir = &apiv1.EmptyDirVolumeSource{}
+			if v.SizeLimit != nil {
+				sizeLimit := v.SizeLimit.DeepCopy()
+				volume.VolumeSource.EmptyDir.SizeLimit = &sizeLimit
+			}
+		default:
+			continue
+		}
+		podSpec.Volumes = append(podSpec.Volumes, volume)
+		mounts = append(mounts, apiv1.VolumeMount{
+			Name:      volume.Name,
+			MountPath: v.MountPath,
+			ReadOnly:  v.ReadOnly,
+		})
+	}
+
+	for i := range podSpec.Containers {
+		if podSpec.Containers[i].Name == fn.Metadata.Name {
+			podSpec.Containers[i].VolumeMounts = append(podSpec.Containers[i].VolumeMounts, mounts...)
+		}
+	}
+}
@@ -0,0 +1,66 @@
+/*
+Copyright 2019 The Fission Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package util
+
+import (
+	"testing"
+
+	apiv1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	fv1 "github.com/fission/fission/pkg/apis/fission.io/v1"
+)
+
+func TestApplyFunctionVolumes(t *testing.T) {
+	sizeLimit := resource.MustParse("1Gi")
+	fn := &fv1.Function{
+		Metadata: metav1.ObjectMeta{Name: "hello", Namespace: "default"},
+		Spec: fv1.FunctionSpec{
+			Volumes: []fv1.FunctionVolume{
+				{Type: fv1.FunctionVolumeTypePVC, Name: "models", MountPath: "/mnt/models", ReadOnly: true},
+				{Type: fv1.FunctionVolumeTypeEmptyDir, Name: "scratch", MountPath: "/scratch", SizeLimit: &sizeLimit},
+			},
+		},
+	}
+
+	podSpec := &apiv1.PodSpec{
+		Containers: []apiv1.Container{{Name: "hello"}, {Name: "fetcher"}},
+		Volumes:    []apiv1.Volume{{Name: "userfunc"}},
+	}
+	ApplyFunctionVolumes(podSpec, fn)
+
+	if len(podSpec.Volumes) != 3 {
+		t.Fatalf("expected the volumes to be added, got %v", podSpec.Volumes)
+	}
+	pvc := podSpec.Volumes[1].PersistentVolumeClaim
+	if pvc == nil || pvc.ClaimName != "models" || !pvc.ReadOnly {
+		t.Errorf("expected a read-only claim of models, got %v", podSpec.Volumes[1])
+	}
+	emptyDir := podSpec.Volumes[2].EmptyDir
+	if emptyDir == nil || emptyDir.SizeLimit == nil || emptyDir.SizeLimit.Cmp(sizeLimit) != 0 {
+		t.Errorf("expected an emptyDir of 1Gi, got %v", podSpec.Volumes[2])
+	}
+
+	mounts := podSpec.Containers[0].VolumeMounts
+	if len(mounts) != 2 || mounts[0].MountPath != "/mnt/models" || mounts[1].MountPath != "/scratch" {
+		t.Errorf("expected the volumes to be mounted into the function container, got %v", mounts)
+	}
+	if len(podSpec.Containers[1].VolumeMounts) != 0 {
+		t.Errorf("expected no volumes mounted into other containers, got %v", podSpec.Containers[1].VolumeMounts)
+	}
+}
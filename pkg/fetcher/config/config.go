@@ -2,10 +2,12 @@ package container
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"log"
 	"os"
 	"path/filepath"
+	"strconv"
 
 	"github.com/fission/fission/pkg/types"
 	"github.com/fission/fission/pkg/utils"
@@ -30,6 +32,10 @@ type Config struct {
 	sharedSecretPath string
 	sharedCfgMapPath string
 
+	// sharedVolumeMedium is the storage medium of the shared volume,
+	// "Memory" puts the function archive on tmpfs to speed up specialization
+	sharedVolumeMedium apiv1.StorageMedium
+
 	dockerRegistryAuthDomain string
 	dockerRegistryUsername   string
 	dockerRegistryPassword   string
@@ -37,6 +43,12 @@ type Config struct {
 	serviceAccount string
 
 	jaegerCollectorEndpoint string
+
+	// logForwarderImage is the image of the log forwarder sidecar of
+	// function pods, empty means function logs are left to the logger
+	// DaemonSet.
+	logForwarderImage    string
+	logForwarderEndpoint string
 }
 
 func getFetcherResources() (apiv1.ResourceRequirements, error) {
@@ -88,6 +100,19 @@ func MakeFetcherConfig(sharedMountPath string) (*Config, error) {
 		fetcherImagePullPolicy = "IfNotPresent"
 	}
 
+	sharedVolumeMedium := apiv1.StorageMedium(os.Getenv("FETCHER_SHARED_VOLUME_MEDIUM"))
+	if sharedVolumeMedium != apiv1.StorageMediumDefault && sharedVolumeMedium != apiv1.StorageMediumMemory {
+		return nil, fmt.Errorf("invalid FETCHER_SHARED_VOLUME_MEDIUM %q, must be empty or %q", sharedVolumeMedium, apiv1.StorageMediumMemory)
+	}
+
+	var logForwarderImage string
+	if enabled, _ := strconv.ParseBool(os.Getenv("LOG_FORWARDER_ENABLED")); enabled {
+		logForwarderImage = os.Getenv("LOG_FORWARDER_IMAGE")
+		if len(logForwarderImage) == 0 {
+			return nil, errors.New("LOG_FORWARDER_IMAGE must be set when LOG_FORWARDER_ENABLED is true")
+		}
+	}
+
 	return &Config{
 		resourceRequirements:    resources,
 		fetcherImage:            fetcherImage,
@@ -95,8 +120,11 @@ func MakeFetcherConfig(sharedMountPath string) (*Config, error) {
 		sharedMountPath:         sharedMountPath,
 		sharedSecretPath:        "/secrets",
 		sharedCfgMapPath:        "/configs",
+		sharedVolumeMedium:      sharedVolumeMedium,
 		jaegerCollectorEndpoint: os.Getenv("OPENCENSUS_TRACE_JAEGER_COLLECTOR_ENDPOINT"),
 		serviceAccount:          types.FissionFetcherSA,
+		logForwarderImage:       logForwarderImage,
+		logForwarderEndpoint:    os.Getenv("LOG_FORWARDER_ENDPOINT"),
 	}, nil
 }
 
@@ -123,6 +151,22 @@ func (cfg *Config) NewSpecializeRequest(fn *fv1.Function, env *fv1.Environment)
 		targetFilename = string(fn.Metadata.UID)
 	}
 
+	if fn.Spec.PrebuiltImage != nil {
+		return types.FunctionSpecializeRequest{
+			FetchReq: types.FunctionFetchRequest{
+				FetchType:  types.FETCH_NONE,
+				Secrets:    fn.Spec.Secrets,
+				ConfigMaps: fn.Spec.ConfigMaps,
+			},
+			LoadReq: types.FunctionLoadRequest{
+				FilePath:         fn.Spec.PrebuiltImage.Path,
+				FunctionName:     fn.Spec.Package.FunctionName,
+				FunctionMetadata: &fn.Metadata,
+				EnvVersion:       env.Spec.Version,
+			},
+		}
+	}
+
 	return types.FunctionSpecializeRequest{
 		FetchReq: types.FunctionFetchRequest{
 			FetchType: types.FETCH_DEPLOYMENT,
@@ -182,7 +226,9 @@ func (cfg *Config) volumesWithMounts() ([]apiv1.Volume, []apiv1.VolumeMount) {
 		{
 			Name: types.SharedVolumeUserfunc,
 			VolumeSource: apiv1.VolumeSource{
-				EmptyDir: &apiv1.EmptyDirVolumeSource{},
+				EmptyDir: &apiv1.EmptyDirVolumeSource{
+					Medium: cfg.sharedVolumeMedium,
+				},
 			},
 		},
 		{
@@ -282,6 +328,12 @@ func (cfg *Config) addFetcherToPodSpecWithCommand(podSpec *apiv1.PodSpec, mainCo
 
 		found = true
 		container.VolumeMounts = append(container.VolumeMounts, mounts...)
+		// runtimes supporting it serve the specialize request of fetcher on a
+		// unix socket in the shared volume rather than over the pod network
+		container.Env = append(container.Env, apiv1.EnvVar{
+			Name:  types.RuntimeSocketEnv,
+			Value: filepath.Join(cfg.sharedMountPath, types.RuntimeSocketName),
+		})
 		podSpec.Containers[ix] = container
 	}
 	if !found {
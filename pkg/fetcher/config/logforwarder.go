@@ -0,0 +1,62 @@
+package container
+
+import (
+	"go.uber.org/zap"
+	apiv1 "k8s.io/api/core/v1"
+	"k8s.io/client-go/kubernetes"
+
+	"github.com/fission/fission/pkg/types"
+	"github.com/fission/fission/pkg/utils"
+)
+
+// AddLogForwarderToPodSpec adds the log forwarder sidecar to a function
+// pod, if it's enabled. The sidecar follows the logs of the main container
+// through the Kubernetes API and writes them to the log database, so that
+// function logs need no logger DaemonSet with access to the node.
+func (cfg *Config) AddLogForwarderToPodSpec(podSpec *apiv1.PodSpec, mainContainerName string) {
+	if len(cfg.logForwarderImage) == 0 {
+		return
+	}
+
+	podSpec.Containers = append(podSpec.Containers, apiv1.Container{
+		Name:            "log-forwarder",
+		Image:           cfg.logForwarderImage,
+		ImagePullPolicy: cfg.fetcherImagePullPolicy,
+		Command:         []string{"/fission-bundle"},
+		Args:            []string{"--logForwarder"},
+		Env: []apiv1.EnvVar{
+			{
+				Name: types.LogForwarderPodNameEnv,
+				ValueFrom: &apiv1.EnvVarSource{
+					FieldRef: &apiv1.ObjectFieldSelector{FieldPath: "metadata.name"},
+				},
+			},
+			{
+				Name: types.LogForwarderPodNamespaceEnv,
+				ValueFrom: &apiv1.EnvVarSource{
+					FieldRef: &apiv1.ObjectFieldSelector{FieldPath: "metadata.namespace"},
+				},
+			},
+			{
+				Name:  types.LogForwarderContainerEnv,
+				Value: mainContainerName,
+			},
+			{
+				Name:  types.LogForwarderEndpointEnv,
+				Value: cfg.logForwarderEndpoint,
+			},
+		},
+		Resources:              cfg.resourceRequirements,
+		TerminationMessagePath: "/dev/termination-log",
+	})
+}
+
+// SetupLogForwarderRoleBinding lets the log forwarder sidecars of the
+// function pods in a namespace read the logs of their pods.
+func (cfg *Config) SetupLogForwarderRoleBinding(logger *zap.Logger, kubernetesClient *kubernetes.Clientset, namespace string) error {
+	if len(cfg.logForwarderImage) == 0 {
+		return nil
+	}
+	return utils.SetupRoleBinding(logger, kubernetesClient, types.PodLogReaderRB, namespace,
+		types.PodLogReaderCR, types.ClusterRole, types.FissionFetcherSA, namespace)
+}
@@ -17,7 +17,6 @@ limitations under the License.
 package fetcher
 
 import (
-	"bytes"
 	"context"
 	"crypto/sha256"
 	"encoding/hex"
@@ -58,6 +57,7 @@ type (
 		fissionClient    *crd.FissionClient
 		kubeClient       *kubernetes.Clientset
 		httpClient       *http.Client
+		runtime          *runtimeClient
 	}
 )
 
@@ -94,6 +94,7 @@ func MakeFetcher(logger *zap.Logger, sharedVolumePath string, sharedSecretPath s
 		httpClient: &http.Client{
 			Transport: &ochttp.Transport{},
 		},
+		runtime: makeRuntimeClient(sharedVolumePath),
 	}, nil
 }
 
@@ -310,8 +311,15 @@ func (fetcher *Fetcher) Fetch(ctx context.Context, pkg *fv1.Package, req types.F
 			}
 			archive = &pkg.Spec.Deployment
 		}
-		// get package data as literal or by url
-		if len(archive.Literal) > 0 {
+		// get package data as git repository, literal or by url
+		if archive.Type == fv1.ArchiveTypeGit {
+			err := fetcher.cloneGitArchive(ctx, pkg, archive, tmpPath)
+			if err != nil {
+				e := "failed to clone git repository"
+				fetcher.logger.Error(e, zap.Error(err), zap.String("url", archive.URL))
+				return http.StatusBadRequest, errors.Wrapf(err, "%s %s", e, archive.URL)
+			}
+		} else if len(archive.Literal) > 0 {
 			// write pkg.Literal into tmpPath
 			err := ioutil.WriteFile(tmpPath, archive.Literal, 0600)
 			if err != nil {
@@ -627,14 +635,18 @@ func (fetcher *Fetcher) SpecializePod(ctx context.Context, fetchReq types.Functi
 		fetcher.logger.Info("specialize request done", zap.Duration("elapsed_time", elapsed))
 	}()
 
-	pkg, err := fetcher.getPkgInformation(fetchReq)
-	if err != nil {
-		return errors.Wrap(err, "error getting package information")
-	}
+	var pkg *fv1.Package
+	var err error
+	if fetchReq.FetchType != types.FETCH_NONE {
+		pkg, err = fetcher.getPkgInformation(fetchReq)
+		if err != nil {
+			return errors.Wrap(err, "error getting package information")
+		}
 
-	_, err = fetcher.Fetch(ctx, pkg, fetchReq)
-	if err != nil {
-		return errors.Wrap(err, "error fetching deploy package")
+		_, err = fetcher.Fetch(ctx, pkg, fetchReq)
+		if err != nil {
+			return errors.Wrap(err, "error fetching deploy package")
+		}
 	}
 
 	_, err = fetcher.FetchSecretsAndCfgMaps(fetchReq.Secrets, fetchReq.ConfigMaps)
@@ -642,39 +654,51 @@ func (fetcher *Fetcher) SpecializePod(ctx context.Context, fetchReq types.Functi
 		return errors.Wrap(err, "error fetching secrets/configs")
 	}
 
+	if fetchReq.FetchType == types.FETCH_NONE && len(loadReq.FilePath) == 0 {
+		// the prebuilt image serves the function by itself
+		fetcher.logger.Info("no code to load, skipping specialization")
+		return nil
+	}
+
 	// Specialize the pod
 
 	maxRetries := 30
 	var contentType string
-	var specializeURL string
-	var reader *bytes.Reader
+	var specializePath string
+	var payload []byte
+
+	if fetchReq.FetchType == types.FETCH_DEPLOYMENT {
+		// FilePath is where the runtime sees the fetched file
+		loadReq.Checksum, err = fileChecksum(filepath.Join(fetcher.sharedVolumePath, fetchReq.Filename))
+		if err != nil {
+			return errors.Wrap(err, "error computing checksum of deploy package")
+		}
+	}
 
 	loadPayload, err := json.Marshal(loadReq)
 	if err != nil {
 		return errors.Wrap(err, "error encoding load request")
 	}
 
-	// Instead of using "localhost", here we use "127.0.0.1" for
-	// inter-pod communication to prevent wrongly record returned from DNS.
-
 	if loadReq.EnvVersion >= 2 {
 		contentType = "application/json"
-		specializeURL = "http://127.0.0.1:8888/v2/specialize"
-		reader = bytes.NewReader(loadPayload)
+		specializePath = "/v2/specialize"
+		payload = loadPayload
 		fetcher.logger.Info("calling environment v2 specialization endpoint")
 	} else {
 		contentType = "text/plain"
-		specializeURL = "http://127.0.0.1:8888/specialize"
-		reader = bytes.NewReader([]byte{})
+		specializePath = "/specialize"
+		payload = []byte{}
 		fetcher.logger.Info("calling environment v1 specialization endpoint")
 	}
 
 	for i := 0; i < maxRetries; i++ {
-		resp, err := http.Post(specializeURL, contentType, reader)
+		resp, viaSocket, err := fetcher.runtime.post(ctx, specializePath, contentType, payload)
 		if err == nil && resp.StatusCode < 300 {
 			// Success
 			resp.Body.Close()
-			return nil
+			fetcher.logger.Info("function pod specialized", zap.Bool("unix_socket", viaSocket))
+			return verifyRuntimeChecksum(resp, loadReq.Checksum)
 		}
 
 		netErr := network.Adapter(err)
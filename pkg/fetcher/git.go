@@ -0,0 +1,171 @@
+/*
+Copyright 2019 The Fission Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package fetcher
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/pkg/errors"
+	"go.uber.org/zap"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	fv1 "github.com/fission/fission/pkg/apis/fission.io/v1"
+)
+
+// Keys of the secret of a git archive, see fv1.GitSource.
+const (
+	gitSecretUsernameKey   = "username"
+	gitSecretPasswordKey   = "password"
+	gitSecretSSHKey        = "ssh-privatekey"
+	gitSecretKnownHostsKey = "known_hosts"
+)
+
+// gitAskPass answers the username and password prompts of git for https
+// repositories, so that the credentials never show up in the URL.
+const gitAskPass = `#!/bin/sh
+case "$1" in
+Username*) echo "$FISSION_GIT_USERNAME" ;;
+*) echo "$FISSION_GIT_PASSWORD" ;;
+esac
+`
+
+// cloneGitArchive clones the Git repository of a source archive into dir
+// and checks out the revision of the archive. The .git directory is removed,
+// so that the source is the same as an extracted archive.
+func (fetcher *Fetcher) cloneGitArchive(ctx context.Context, pkg *fv1.Package, archive *fv1.Archive, dir string) error {
+	var ref, secretName string
+	if archive.Git != nil {
+		ref = archive.Git.Ref
+		secretName = archive.Git.Secret
+	}
+
+	env := append(os.Environ(), "GIT_TERMINAL_PROMPT=0")
+	if len(secretName) > 0 {
+		secret, err := fetcher.kubeClient.CoreV1().Secrets(pkg.Metadata.Namespace).Get(secretName, metav1.GetOptions{})
+		if err != nil {
+			return errors.Wrapf(err, "error getting git secret %v", secretName)
+		}
+
+		credDir, err := ioutil.TempDir("", "git-credentials")
+		if err != nil {
+			return errors.Wrap(err, "error creating directory for git credentials")
+		}
+		defer os.RemoveAll(credDir)
+
+		credEnv, err := fetcher.gitCredentialEnv(secret.Data, credDir)
+		if err != nil {
+			return err
+		}
+		env = append(env, credEnv...)
+	}
+
+	fetcher.logger.Info("cloning git repository",
+		zap.String("url", archive.URL),
+		zap.String("ref", ref),
+		zap.String("package_name", pkg.Metadata.Name),
+		zap.String("package_namespace", pkg.Metadata.Namespace))
+
+	args := []string{"clone", "--depth", "1"}
+	if len(ref) > 0 {
+		args = append(args, "--branch", ref)
+	}
+	err := runGit(ctx, env, "", append(args, "--", archive.URL, dir)...)
+	if err != nil && len(ref) > 0 {
+		// a shallow clone only works with branches and tags, clone the
+		// whole repository for commits
+		os.RemoveAll(dir)
+		err = runGit(ctx, env, "", "clone", "--", archive.URL, dir)
+		if err == nil {
+			err = runGit(ctx, env, dir, "checkout", ref)
+		}
+	}
+	if err != nil {
+		return err
+	}
+
+	commit, err := gitOutput(ctx, env, dir, "rev-parse", "HEAD")
+	if err == nil {
+		fetcher.logger.Info("cloned git repository", zap.String("url", archive.URL), zap.String("commit", commit))
+	}
+
+	return os.RemoveAll(filepath.Join(dir, ".git"))
+}
+
+// gitCredentialEnv writes the credentials of the secret into credDir and
+// returns the environment variables for git to use them.
+func (fetcher *Fetcher) gitCredentialEnv(data map[string][]byte, credDir string) ([]string, error) {
+	if key, ok := data[gitSecretSSHKey]; ok {
+		keyFile := filepath.Join(credDir, "id")
+		err := ioutil.WriteFile(keyFile, key, 0600)
+		if err != nil {
+			return nil, errors.Wrap(err, "error writing ssh key")
+		}
+
+		hostKeyOpts := "-o StrictHostKeyChecking=no -o UserKnownHostsFile=/dev/null"
+		if knownHosts, ok := data[gitSecretKnownHostsKey]; ok {
+			knownHostsFile := filepath.Join(credDir, "known_hosts")
+			err = ioutil.WriteFile(knownHostsFile, knownHosts, 0600)
+			if err != nil {
+				return nil, errors.Wrap(err, "error writing known hosts")
+			}
+			hostKeyOpts = fmt.Sprintf("-o StrictHostKeyChecking=yes -o UserKnownHostsFile=%v", knownHostsFile)
+		} else {
+			fetcher.logger.Warn("git secret has no known_hosts key, the host key of the repository is not verified")
+		}
+		return []string{
+			fmt.Sprintf("GIT_SSH_COMMAND=ssh -i %v -o IdentitiesOnly=yes %v", keyFile, hostKeyOpts),
+		}, nil
+	}
+
+	if _, ok := data[gitSecretPasswordKey]; ok {
+		askPassFile := filepath.Join(credDir, "askpass.sh")
+		err := ioutil.WriteFile(askPassFile, []byte(gitAskPass), 0700)
+		if err != nil {
+			return nil, errors.Wrap(err, "error writing git askpass script")
+		}
+		return []string{
+			fmt.Sprintf("GIT_ASKPASS=%v", askPassFile),
+			fmt.Sprintf("FISSION_GIT_USERNAME=%s", data[gitSecretUsernameKey]),
+			fmt.Sprintf("FISSION_GIT_PASSWORD=%s", data[gitSecretPasswordKey]),
+		}, nil
+	}
+
+	return nil, errors.Errorf("git secret must have either the %q key or the %q and %q keys",
+		gitSecretSSHKey, gitSecretUsernameKey, gitSecretPasswordKey)
+}
+
+func runGit(ctx context.Context, env []string, dir string, args ...string) error {
+	_, err := gitOutput(ctx, env, dir, args...)
+	return err
+}
+
+func gitOutput(ctx context.Context, env []string, dir string, args ...string) (string, error) {
+	cmd := exec.CommandContext(ctx, "git", args...)
+	cmd.Env = env
+	cmd.Dir = dir
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		return "", errors.Errorf("git %v failed: %v: %v", args[0], err, strings.TrimSpace(string(out)))
+	}
+	return strings.TrimSpace(string(out)), nil
+}
@@ -0,0 +1,129 @@
+/*
+Copyright 2019 The Fission Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package fetcher
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+
+	"github.com/pkg/errors"
+
+	fv1 "github.com/fission/fission/pkg/apis/fission.io/v1"
+	"github.com/fission/fission/pkg/types"
+)
+
+// runtimeClient sends requests to the runtime container of the pod. It
+// uses the unix socket in the shared volume once the runtime serves one,
+// otherwise port 8888 of the loopback interface.
+type runtimeClient struct {
+	socketPath   string
+	socketClient *http.Client
+	tcpClient    *http.Client
+}
+
+func makeRuntimeClient(sharedVolumePath string) *runtimeClient {
+	socketPath := filepath.Join(sharedVolumePath, types.RuntimeSocketName)
+	return &runtimeClient{
+		socketPath: socketPath,
+		socketClient: &http.Client{
+			Transport: &http.Transport{
+				DialContext: func(ctx context.Context, _, _ string) (net.Conn, error) {
+					var dialer net.Dialer
+					return dialer.DialContext(ctx, "unix", socketPath)
+				},
+			},
+		},
+		tcpClient: &http.Client{},
+	}
+}
+
+// post posts the body to the path of the runtime, and returns whether the
+// request went over the unix socket.
+func (rc *runtimeClient) post(ctx context.Context, path string, contentType string, body []byte) (*http.Response, bool, error) {
+	client := rc.tcpClient
+	// Instead of using "localhost", here we use "127.0.0.1" for
+	// inter-pod communication to prevent wrongly record returned from DNS.
+	url := "http://127.0.0.1:8888" + path
+
+	useSocket := false
+	if info, err := os.Stat(rc.socketPath); err == nil && info.Mode()&os.ModeSocket != 0 {
+		useSocket = true
+		client = rc.socketClient
+		// the host is ignored by the socket dialer
+		url = "http://runtime" + path
+	}
+
+	req, err := http.NewRequest(http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return nil, useSocket, err
+	}
+	req.Header.Set("Content-Type", contentType)
+
+	resp, err := client.Do(req.WithContext(ctx))
+	return resp, useSocket, err
+}
+
+// fileChecksum returns the SHA-256 of the file fetched into path, for the
+// runtime to return the digest of the file it loads. It's nil if path is a
+// directory, the runtime decides which files of it to load.
+func fileChecksum(path string) (*fv1.Checksum, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, errors.Wrap(err, "error checking fetched file")
+	}
+	if !info.Mode().IsRegular() {
+		return nil, nil
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, errors.Wrap(err, "error opening fetched file")
+	}
+	defer f.Close()
+
+	h := sha256.New()
+	_, err = io.Copy(h, f)
+	if err != nil {
+		return nil, errors.Wrap(err, "error reading fetched file")
+	}
+	return &fv1.Checksum{
+		Type: fv1.ChecksumTypeSHA256,
+		Sum:  hex.EncodeToString(h.Sum(nil)),
+	}, nil
+}
+
+// verifyRuntimeChecksum compares the digest of the loaded file the runtime
+// returns in the specialize response with the one of the fetched file.
+// Runtimes not supporting the handshake don't set the header and aren't
+// checked.
+func verifyRuntimeChecksum(resp *http.Response, checksum *fv1.Checksum) error {
+	runtimeSum := resp.Header.Get(types.ChecksumHeader)
+	if checksum == nil || len(runtimeSum) == 0 {
+		return nil
+	}
+	if runtimeSum != checksum.Sum {
+		return errors.Errorf("runtime loaded the package with checksum %v, expected %v", runtimeSum, checksum.Sum)
+	}
+	return nil
+}
@@ -0,0 +1,73 @@
+/*
+Copyright 2019 The Fission Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package fetcher
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	fv1 "github.com/fission/fission/pkg/apis/fission.io/v1"
+	"github.com/fission/fission/pkg/types"
+)
+
+func TestFileChecksum(t *testing.T) {
+	dir, err := ioutil.TempDir("", "fetcher-test-")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	code := []byte("package main")
+	path := filepath.Join(dir, "user")
+	assert.NoError(t, ioutil.WriteFile(path, code, 0644))
+
+	sum := sha256.Sum256(code)
+	checksum, err := fileChecksum(path)
+	assert.NoError(t, err)
+	assert.Equal(t, &fv1.Checksum{Type: fv1.ChecksumTypeSHA256, Sum: hex.EncodeToString(sum[:])}, checksum)
+
+	// the runtime picks the files of a directory, there's nothing to compare
+	checksum, err = fileChecksum(dir)
+	assert.NoError(t, err)
+	assert.Nil(t, checksum)
+
+	_, err = fileChecksum(filepath.Join(dir, "missing"))
+	assert.Error(t, err)
+}
+
+func TestVerifyRuntimeChecksum(t *testing.T) {
+	checksum := &fv1.Checksum{Type: fv1.ChecksumTypeSHA256, Sum: "abc"}
+	response := func(sum string) *http.Response {
+		resp := &http.Response{Header: http.Header{}}
+		if len(sum) > 0 {
+			resp.Header.Set(types.ChecksumHeader, sum)
+		}
+		return resp
+	}
+
+	assert.NoError(t, verifyRuntimeChecksum(response("abc"), checksum))
+	assert.Error(t, verifyRuntimeChecksum(response("def"), checksum), "the runtime loaded another file")
+
+	// runtimes without the handshake, or nothing to compare
+	assert.NoError(t, verifyRuntimeChecksum(response(""), checksum))
+	assert.NoError(t, verifyRuntimeChecksum(response("def"), nil))
+}
@@ -0,0 +1,188 @@
+/*
+Copyright 2019 The Fission Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package fission_cli
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/urfave/cli"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	fv1 "github.com/fission/fission/pkg/apis/fission.io/v1"
+	"github.com/fission/fission/pkg/fission-cli/log"
+	"github.com/fission/fission/pkg/fission-cli/util"
+)
+
+// getAliasName returns the alias name given with --name or as the first
+// argument, e.g. "fission alias create payments-live --function payments-v42".
+func getAliasName(c *cli.Context) string {
+	if name := c.String("name"); len(name) > 0 {
+		return name
+	}
+	return c.Args().First()
+}
+
+// getAliasFunctionWeights returns the functions an alias points to along
+// with their weights. A single function gets all the traffic.
+func getAliasFunctionWeights(c *cli.Context) map[string]int {
+	functionList := c.StringSlice("function")
+	weightList := c.IntSlice("weight")
+
+	if len(functionList) == 1 && len(weightList) == 0 {
+		return map[string]int{functionList[0]: 100}
+	}
+	if len(functionList) != len(weightList) {
+		log.Fatal("Need a weight for each function, use --weight in the same order as --function")
+	}
+
+	functionWeights := make(map[string]int, len(functionList))
+	for i, fnName := range functionList {
+		functionWeights[fnName] = weightList[i]
+	}
+	return functionWeights
+}
+
+func aliasCreate(c *cli.Context) error {
+	client := util.GetApiClient(c.GlobalString("server"))
+
+	name := getAliasName(c)
+	if len(name) == 0 {
+		log.Fatal("Need a name for the alias, use --name")
+	}
+	if len(c.StringSlice("function")) == 0 {
+		log.Fatal("Need a function name for the alias, use --function")
+	}
+	fnNamespace := c.String("fnNamespace")
+
+	functionWeights := getAliasFunctionWeights(c)
+	fnList := make([]string, 0, len(functionWeights))
+	for fnName := range functionWeights {
+		fnList = append(fnList, fnName)
+	}
+	err := util.CheckFunctionExistence(client, fnList, fnNamespace)
+	if err != nil {
+		log.Warn(err.Error())
+	}
+
+	alias := &fv1.FunctionAlias{
+		Metadata: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: fnNamespace,
+		},
+		Spec: fv1.FunctionAliasSpec{
+			FunctionWeights: functionWeights,
+		},
+	}
+
+	_, err = client.FunctionAliasCreate(alias)
+	util.CheckErr(err, "create function alias")
+
+	fmt.Printf("alias '%v' created\n", name)
+	return nil
+}
+
+func aliasGet(c *cli.Context) error {
+	client := util.GetApiClient(c.GlobalString("server"))
+
+	name := getAliasName(c)
+	if len(name) == 0 {
+		log.Fatal("Need name of the alias, use --name")
+	}
+
+	alias, err := client.FunctionAliasGet(&metav1.ObjectMeta{
+		Name:      name,
+		Namespace: c.String("aliasNamespace"),
+	})
+	util.CheckErr(err, "get function alias")
+
+	printAliasSummary([]fv1.FunctionAlias{*alias})
+	return nil
+}
+
+// aliasUpdate points an alias to other functions or changes the weights of
+// the functions, the triggers referencing the alias follow it.
+func aliasUpdate(c *cli.Context) error {
+	client := util.GetApiClient(c.GlobalString("server"))
+
+	name := getAliasName(c)
+	if len(name) == 0 {
+		log.Fatal("Need name of the alias, use --name")
+	}
+	if len(c.StringSlice("function")) == 0 {
+		log.Fatal("Nothing to update. Use --function and --weight.")
+	}
+
+	alias, err := client.FunctionAliasGet(&metav1.ObjectMeta{
+		Name:      name,
+		Namespace: c.String("aliasNamespace"),
+	})
+	util.CheckErr(err, "get function alias")
+
+	alias.Spec.FunctionWeights = getAliasFunctionWeights(c)
+
+	_, err = client.FunctionAliasUpdate(alias)
+	util.CheckErr(err, "update function alias")
+
+	fmt.Printf("alias '%v' updated\n", name)
+	return nil
+}
+
+func aliasDelete(c *cli.Context) error {
+	client := util.GetApiClient(c.GlobalString("server"))
+
+	name := getAliasName(c)
+	if len(name) == 0 {
+		log.Fatal("Need name of the alias to delete, use --name")
+	}
+
+	err := client.FunctionAliasDelete(&metav1.ObjectMeta{
+		Name:      name,
+		Namespace: c.String("aliasNamespace"),
+	})
+	util.CheckErr(err, "delete function alias")
+
+	fmt.Printf("alias '%v' deleted\n", name)
+	return nil
+}
+
+func aliasList(c *cli.Context) error {
+	client := util.GetApiClient(c.GlobalString("server"))
+
+	aliases, err := client.FunctionAliasListWithOptions(c.String("aliasNamespace"), listOptions(c))
+	util.CheckErr(err, "list function aliases")
+
+	printAliasSummary(aliases)
+	return nil
+}
+
+func printAliasSummary(aliases []fv1.FunctionAlias) {
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 1, ' ', 0)
+	fmt.Fprintf(w, "%v\t%v\n", "NAME", "FUNCTION(s)")
+	for _, alias := range aliases {
+		functions := make([]string, 0, len(alias.Spec.FunctionWeights))
+		for fnName, weight := range alias.Spec.FunctionWeights {
+			functions = append(functions, fmt.Sprintf("%s:%v", fnName, weight))
+		}
+		sort.Strings(functions)
+		fmt.Fprintf(w, "%v\t%v\n", alias.Metadata.Name, strings.Join(functions, " "))
+	}
+	w.Flush()
+}
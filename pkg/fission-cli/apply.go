@@ -0,0 +1,373 @@
+/*
+Copyright 2019 The Fission Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package fission_cli
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"reflect"
+	"strings"
+
+	multierror "github.com/hashicorp/go-multierror"
+	"github.com/pkg/errors"
+	"github.com/urfave/cli"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	fv1 "github.com/fission/fission/pkg/apis/fission.io/v1"
+	"github.com/fission/fission/pkg/controller/client"
+	ferror "github.com/fission/fission/pkg/error"
+	"github.com/fission/fission/pkg/fission-cli/cmd/spec"
+	"github.com/fission/fission/pkg/fission-cli/log"
+	"github.com/fission/fission/pkg/fission-cli/util"
+)
+
+// resourceApply creates the resources of YAML files on the cluster, or
+// updates the ones that exist already. Unlike spec apply it needs no spec
+// directory: the resources aren't tracked with a deployment config, so
+// nothing is ever deleted, and archives aren't uploaded.
+func resourceApply(c *cli.Context) error {
+	files := c.StringSlice("file")
+	if len(files) == 0 {
+		log.Fatal("Need --file argument, or - to read the resources from stdin.")
+	}
+
+	fr, err := readResourceFiles(files)
+	util.CheckErr(err, "read resources")
+
+	fclient := util.GetApiClient(c.GlobalString("server"))
+	as, err := upsertResources(fclient, fr)
+	util.CheckErr(err, "apply resources")
+	printApplyStatus(as)
+	return nil
+}
+
+// readResourceFiles reads and validates the resources of YAML files with
+// one or more documents, "-" is stdin. Resources without a namespace go
+// to the default namespace.
+func readResourceFiles(paths []string) (*spec.FissionResources, error) {
+	fr := &spec.FissionResources{
+		SourceMap: spec.SourceMap{
+			Locations: make(map[string](map[string](map[string]spec.Location))),
+		},
+	}
+
+	result := &multierror.Error{}
+	for _, path := range paths {
+		var b []byte
+		var err error
+		if path == "-" {
+			b, err = ioutil.ReadAll(os.Stdin)
+		} else {
+			b, err = ioutil.ReadFile(path)
+		}
+		if err != nil {
+			return nil, err
+		}
+		for _, doc := range splitSpecDocs(b, path) {
+			err = fr.ParseYaml(doc.data, &spec.Location{
+				Path: doc.path,
+				Line: doc.line,
+			})
+			if err != nil {
+				result = multierror.Append(result, err)
+			}
+		}
+	}
+	if err := result.ErrorOrNil(); err != nil {
+		return nil, err
+	}
+
+	if len(fr.ArchiveUploadSpecs) > 0 {
+		return nil, errors.New("archives are uploaded from a spec directory, use 'fission spec apply'")
+	}
+
+	defaultResourceNamespaces(fr)
+
+	for _, p := range fr.Packages {
+		for _, url := range []string{p.Spec.Source.URL, p.Spec.Deployment.URL} {
+			if strings.HasPrefix(url, spec.ARCHIVE_URL_PREFIX) {
+				result = multierror.Append(result, fmt.Errorf("package '%v' references archive %v, which is uploaded from a spec directory, use 'fission spec apply'", p.Metadata.Name, url))
+			}
+		}
+		result = multierror.Append(result, p.Validate())
+	}
+	for _, e := range fr.Environments {
+		result = multierror.Append(result, e.Validate())
+	}
+	for _, f := range fr.Functions {
+		result = multierror.Append(result, f.Validate())
+	}
+	for _, t := range fr.HttpTriggers {
+		result = multierror.Append(result, t.Validate())
+	}
+	for _, t := range fr.KubernetesWatchTriggers {
+		result = multierror.Append(result, t.Validate())
+	}
+	for _, t := range fr.TimeTriggers {
+		result = multierror.Append(result, t.Validate())
+	}
+	for _, t := range fr.MessageQueueTriggers {
+		result = multierror.Append(result, t.Validate())
+	}
+	if err := result.ErrorOrNil(); err != nil {
+		return nil, err
+	}
+	return fr, nil
+}
+
+// defaultResourceNamespaces puts the resources without a namespace, and
+// the references of functions without one, in the default namespace.
+func defaultResourceNamespaces(fr *spec.FissionResources) {
+	setDefault := func(ns *string) {
+		if len(*ns) == 0 {
+			*ns = metav1.NamespaceDefault
+		}
+	}
+	for i := range fr.Environments {
+		setDefault(&fr.Environments[i].Metadata.Namespace)
+	}
+	for i := range fr.Packages {
+		p := &fr.Packages[i]
+		setDefault(&p.Metadata.Namespace)
+		setDefault(&p.Spec.Environment.Namespace)
+	}
+	for i := range fr.Functions {
+		f := &fr.Functions[i]
+		setDefault(&f.Metadata.Namespace)
+		setDefault(&f.Spec.Environment.Namespace)
+		if len(f.Spec.Package.PackageRef.Name) > 0 && len(f.Spec.Package.PackageRef.Namespace) == 0 {
+			f.Spec.Package.PackageRef.Namespace = f.Metadata.Namespace
+		}
+	}
+	for i := range fr.HttpTriggers {
+		setDefault(&fr.HttpTriggers[i].Metadata.Namespace)
+	}
+	for i := range fr.KubernetesWatchTriggers {
+		setDefault(&fr.KubernetesWatchTriggers[i].Metadata.Namespace)
+	}
+	for i := range fr.TimeTriggers {
+		setDefault(&fr.TimeTriggers[i].Metadata.Namespace)
+	}
+	for i := range fr.MessageQueueTriggers {
+		setDefault(&fr.MessageQueueTriggers[i].Metadata.Namespace)
+	}
+}
+
+// upsertResource creates a resource if get doesn't find it, or updates it
+// if its spec changed, and records what it did in ras. It returns the
+// metadata of the resource on the cluster.
+func upsertResource(ras *spec.ResourceApplyStatus, m *metav1.ObjectMeta,
+	get func() (existing *metav1.ObjectMeta, unchanged bool, err error),
+	create func() (*metav1.ObjectMeta, error),
+	update func() (*metav1.ObjectMeta, error)) (*metav1.ObjectMeta, error) {
+
+	existing, unchanged, err := get()
+	if ferror.IsNotFound(err) {
+		newmeta, err := create()
+		if err != nil {
+			return nil, err
+		}
+		ras.Created = append(ras.Created, newmeta)
+		return newmeta, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	if unchanged {
+		return existing, nil
+	}
+
+	m.ResourceVersion = existing.ResourceVersion
+	newmeta, err := update()
+	if err != nil {
+		return nil, err
+	}
+	ras.Updated = append(ras.Updated, newmeta)
+	return newmeta, nil
+}
+
+// upsertResources applies the resources in the order they reference each
+// other: environments, packages, functions and then triggers.
+func upsertResources(fclient *client.Client, fr *spec.FissionResources) (map[string]spec.ResourceApplyStatus, error) {
+	applyStatus := make(map[string]spec.ResourceApplyStatus)
+
+	var ras spec.ResourceApplyStatus
+	for i := range fr.Environments {
+		o := &fr.Environments[i]
+		_, err := upsertResource(&ras, &o.Metadata,
+			func() (*metav1.ObjectMeta, bool, error) {
+				existing, err := fclient.EnvironmentGet(&o.Metadata)
+				if err != nil {
+					return nil, false, err
+				}
+				return &existing.Metadata, reflect.DeepEqual(existing.Spec, o.Spec), nil
+			},
+			func() (*metav1.ObjectMeta, error) { return fclient.EnvironmentCreate(o) },
+			func() (*metav1.ObjectMeta, error) { return fclient.EnvironmentUpdate(o) })
+		if err != nil {
+			return nil, errors.Wrapf(err, "error applying environment %v", o.Metadata.Name)
+		}
+	}
+	applyStatus["environment"] = ras
+
+	// functions reference the resource version of their package
+	pkgMeta := make(map[string]metav1.ObjectMeta)
+	ras = spec.ResourceApplyStatus{}
+	for i := range fr.Packages {
+		o := &fr.Packages[i]
+		m, err := upsertResource(&ras, &o.Metadata,
+			func() (*metav1.ObjectMeta, bool, error) {
+				existing, err := fclient.PackageGet(&o.Metadata)
+				if err != nil {
+					return nil, false, err
+				}
+				return &existing.Metadata, keepPackage(existing, o) && existing.Status.BuildStatus == fv1.BuildStatusSucceeded, nil
+			},
+			func() (*metav1.ObjectMeta, error) { return fclient.PackageCreate(o) },
+			func() (*metav1.ObjectMeta, error) {
+				// a previous version may still be building, see applyPackages
+				pkg, err := waitForPackageBuild(fclient, o)
+				if err != nil {
+					fmt.Printf("Error waiting for package '%v' build, ignoring\n", o.Metadata.Name)
+					pkg = o
+				}
+				if pkg.Status.BuildStatus == fv1.BuildStatusFailed {
+					pkg.Status.BuildStatus = fv1.BuildStatusPending
+				}
+				return fclient.PackageUpdate(pkg)
+			})
+		if err != nil {
+			return nil, errors.Wrapf(err, "error applying package %v", o.Metadata.Name)
+		}
+		pkgMeta[mapKey(m)] = *m
+	}
+	applyStatus["package"] = ras
+
+	ras = spec.ResourceApplyStatus{}
+	for i := range fr.Functions {
+		o := &fr.Functions[i]
+		if len(o.Spec.Package.PackageRef.Name) > 0 {
+			ref := &metav1.ObjectMeta{
+				Namespace: o.Spec.Package.PackageRef.Namespace,
+				Name:      o.Spec.Package.PackageRef.Name,
+			}
+			m, ok := pkgMeta[mapKey(ref)]
+			if !ok {
+				// the package was created before, e.g. by fn create
+				pkg, err := fclient.PackageGet(ref)
+				if err != nil {
+					return nil, errors.Wrapf(err, "error getting package %v of function %v", ref.Name, o.Metadata.Name)
+				}
+				m = pkg.Metadata
+			}
+			o.Spec.Package.PackageRef.ResourceVersion = m.ResourceVersion
+		}
+		_, err := upsertResource(&ras, &o.Metadata,
+			func() (*metav1.ObjectMeta, bool, error) {
+				existing, err := fclient.FunctionGet(&o.Metadata)
+				if err != nil {
+					return nil, false, err
+				}
+				return &existing.Metadata, reflect.DeepEqual(existing.Spec, o.Spec), nil
+			},
+			func() (*metav1.ObjectMeta, error) { return fclient.FunctionCreate(o) },
+			func() (*metav1.ObjectMeta, error) { return fclient.FunctionUpdate(o) })
+		if err != nil {
+			return nil, errors.Wrapf(err, "error applying function %v", o.Metadata.Name)
+		}
+	}
+	applyStatus["function"] = ras
+
+	ras = spec.ResourceApplyStatus{}
+	for i := range fr.HttpTriggers {
+		o := &fr.HttpTriggers[i]
+		_, err := upsertResource(&ras, &o.Metadata,
+			func() (*metav1.ObjectMeta, bool, error) {
+				existing, err := fclient.HTTPTriggerGet(&o.Metadata)
+				if err != nil {
+					return nil, false, err
+				}
+				return &existing.Metadata, reflect.DeepEqual(existing.Spec, o.Spec), nil
+			},
+			func() (*metav1.ObjectMeta, error) { return fclient.HTTPTriggerCreate(o) },
+			func() (*metav1.ObjectMeta, error) { return fclient.HTTPTriggerUpdate(o) })
+		if err != nil {
+			return nil, errors.Wrapf(err, "error applying HTTP trigger %v", o.Metadata.Name)
+		}
+	}
+	applyStatus["HTTPTrigger"] = ras
+
+	ras = spec.ResourceApplyStatus{}
+	for i := range fr.KubernetesWatchTriggers {
+		o := &fr.KubernetesWatchTriggers[i]
+		_, err := upsertResource(&ras, &o.Metadata,
+			func() (*metav1.ObjectMeta, bool, error) {
+				existing, err := fclient.WatchGet(&o.Metadata)
+				if err != nil {
+					return nil, false, err
+				}
+				return &existing.Metadata, reflect.DeepEqual(existing.Spec, o.Spec), nil
+			},
+			func() (*metav1.ObjectMeta, error) { return fclient.WatchCreate(o) },
+			func() (*metav1.ObjectMeta, error) { return fclient.WatchUpdate(o) })
+		if err != nil {
+			return nil, errors.Wrapf(err, "error applying Kubernetes watch trigger %v", o.Metadata.Name)
+		}
+	}
+	applyStatus["KubernetesWatchTrigger"] = ras
+
+	ras = spec.ResourceApplyStatus{}
+	for i := range fr.TimeTriggers {
+		o := &fr.TimeTriggers[i]
+		_, err := upsertResource(&ras, &o.Metadata,
+			func() (*metav1.ObjectMeta, bool, error) {
+				existing, err := fclient.TimeTriggerGet(&o.Metadata)
+				if err != nil {
+					return nil, false, err
+				}
+				return &existing.Metadata, reflect.DeepEqual(existing.Spec, o.Spec), nil
+			},
+			func() (*metav1.ObjectMeta, error) { return fclient.TimeTriggerCreate(o) },
+			func() (*metav1.ObjectMeta, error) { return fclient.TimeTriggerUpdate(o) })
+		if err != nil {
+			return nil, errors.Wrapf(err, "error applying time trigger %v", o.Metadata.Name)
+		}
+	}
+	applyStatus["TimeTrigger"] = ras
+
+	ras = spec.ResourceApplyStatus{}
+	for i := range fr.MessageQueueTriggers {
+		o := &fr.MessageQueueTriggers[i]
+		_, err := upsertResource(&ras, &o.Metadata,
+			func() (*metav1.ObjectMeta, bool, error) {
+				existing, err := fclient.MessageQueueTriggerGet(&o.Metadata)
+				if err != nil {
+					return nil, false, err
+				}
+				return &existing.Metadata, reflect.DeepEqual(existing.Spec, o.Spec), nil
+			},
+			func() (*metav1.ObjectMeta, error) { return fclient.MessageQueueTriggerCreate(o) },
+			func() (*metav1.ObjectMeta, error) { return fclient.MessageQueueTriggerUpdate(o) })
+		if err != nil {
+			return nil, errors.Wrapf(err, "error applying message queue trigger %v", o.Metadata.Name)
+		}
+	}
+	applyStatus["MessageQueueTrigger"] = ras
+
+	return applyStatus, nil
+}
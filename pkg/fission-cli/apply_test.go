@@ -0,0 +1,127 @@
+package fission_cli
+
+import (
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	ferror "github.com/fission/fission/pkg/error"
+	"github.com/fission/fission/pkg/fission-cli/cmd/spec"
+)
+
+func TestReadResourceFiles(t *testing.T) {
+	dir, err := ioutil.TempDir("", "fission-apply")
+	assert.Nil(t, err)
+	defer os.RemoveAll(dir)
+
+	file := filepath.Join(dir, "resources.yaml")
+	err = ioutil.WriteFile(file, []byte(`kind: Environment
+metadata:
+  name: nodejs
+spec:
+  version: 2
+  runtime:
+    image: fission/node-env
+---
+kind: Function
+metadata:
+  name: hello
+  namespace: apps
+spec:
+  environment:
+    name: nodejs
+  package:
+    packageref:
+      name: hello-pkg
+    functionName: hello
+`), 0644)
+	assert.Nil(t, err)
+
+	fr, err := readResourceFiles([]string{file})
+	assert.Nil(t, err)
+	assert.Len(t, fr.Environments, 1)
+	assert.Len(t, fr.Functions, 1)
+
+	// resources without a namespace go to the default one, the references
+	// of functions to their own namespace
+	assert.Equal(t, metav1.NamespaceDefault, fr.Environments[0].Metadata.Namespace)
+	f := fr.Functions[0]
+	assert.Equal(t, "apps", f.Metadata.Namespace)
+	assert.Equal(t, metav1.NamespaceDefault, f.Spec.Environment.Namespace)
+	assert.Equal(t, "apps", f.Spec.Package.PackageRef.Namespace)
+
+	// archives can only be uploaded from a spec directory
+	err = ioutil.WriteFile(file, []byte(`kind: Package
+metadata:
+  name: hello-pkg
+spec:
+  environment:
+    name: nodejs
+  deployment:
+    type: url
+    url: archive://hello-archive
+`), 0644)
+	assert.Nil(t, err)
+	_, err = readResourceFiles([]string{file})
+	assert.NotNil(t, err)
+	assert.Contains(t, err.Error(), "fission spec apply")
+}
+
+func TestUpsertResource(t *testing.T) {
+	var created, updated bool
+	create := func() (*metav1.ObjectMeta, error) {
+		created = true
+		return &metav1.ObjectMeta{Name: "hello", ResourceVersion: "1"}, nil
+	}
+	update := func() (*metav1.ObjectMeta, error) {
+		updated = true
+		return &metav1.ObjectMeta{Name: "hello", ResourceVersion: "3"}, nil
+	}
+	existing := &metav1.ObjectMeta{Name: "hello", ResourceVersion: "2"}
+
+	// missing resources are created
+	var ras spec.ResourceApplyStatus
+	m := &metav1.ObjectMeta{Name: "hello"}
+	meta, err := upsertResource(&ras, m, func() (*metav1.ObjectMeta, bool, error) {
+		return nil, false, ferror.MakeError(ferror.ErrorNotFound, "not found")
+	}, create, update)
+	assert.Nil(t, err)
+	assert.True(t, created)
+	assert.False(t, updated)
+	assert.Equal(t, "1", meta.ResourceVersion)
+	assert.Len(t, ras.Created, 1)
+
+	// unchanged resources are left alone
+	created = false
+	ras = spec.ResourceApplyStatus{}
+	meta, err = upsertResource(&ras, m, func() (*metav1.ObjectMeta, bool, error) {
+		return existing, true, nil
+	}, create, update)
+	assert.Nil(t, err)
+	assert.False(t, created)
+	assert.False(t, updated)
+	assert.Equal(t, existing, meta)
+	assert.Empty(t, ras.Created)
+	assert.Empty(t, ras.Updated)
+
+	// changed resources are updated at the version on the cluster
+	meta, err = upsertResource(&ras, m, func() (*metav1.ObjectMeta, bool, error) {
+		return existing, false, nil
+	}, create, update)
+	assert.Nil(t, err)
+	assert.True(t, updated)
+	assert.Equal(t, "2", m.ResourceVersion)
+	assert.Equal(t, "3", meta.ResourceVersion)
+	assert.Len(t, ras.Updated, 1)
+
+	// other errors are returned
+	_, err = upsertResource(&ras, m, func() (*metav1.ObjectMeta, bool, error) {
+		return nil, false, errors.New("connection refused")
+	}, create, update)
+	assert.NotNil(t, err)
+}
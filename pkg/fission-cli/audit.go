@@ -0,0 +1,103 @@
+/*
+Copyright 2019 The Fission Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package fission_cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	"github.com/urfave/cli"
+
+	"github.com/fission/fission/pkg/fission-cli/log"
+	"github.com/fission/fission/pkg/fission-cli/util"
+	"github.com/fission/fission/pkg/types"
+)
+
+func auditList(c *cli.Context) error {
+	client := util.GetApiClient(c.GlobalString("server"))
+
+	events, err := client.AuditList(c.String("kind"), c.String("namespace"), c.String("name"))
+	util.CheckErr(err, "list audit events")
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 1, ' ', 0)
+	fmt.Fprintf(w, "%v\t%v\t%v\t%v\t%v\t%v\t%v\n", "TIME", "CLIENT USER", "OPERATION", "KIND", "NAMESPACE", "NAME", "ID")
+	for _, e := range events {
+		fmt.Fprintf(w, "%v\t%v\t%v\t%v\t%v\t%v\t%v\n",
+			e.Time.Local().Format(time.RFC3339), e.ClientUser, e.Operation, e.Kind, e.Namespace, e.Name, e.ID)
+	}
+	w.Flush()
+
+	return nil
+}
+
+// auditGet prints an audit event along with the changes it made to the
+// spec of the resource.
+func auditGet(c *cli.Context) error {
+	id := c.String("id")
+	if len(id) == 0 {
+		log.Fatal("Need an audit event ID, use --id")
+	}
+
+	client := util.GetApiClient(c.GlobalString("server"))
+	e, err := client.AuditGet(id)
+	util.CheckErr(err, "get audit event")
+
+	fmt.Printf("ID:        %v\n", e.ID)
+	fmt.Printf("Time:      %v\n", e.Time.Local().Format(time.RFC3339))
+	fmt.Printf("User:      %v (asserted by the client, not verified)\n", e.ClientUser)
+	fmt.Printf("Address:   %v\n", e.RemoteAddr)
+	fmt.Printf("Operation: %v\n", e.Operation)
+	fmt.Printf("Resource:  %v %v/%v\n", e.Kind, e.Namespace, e.Name)
+
+	if e.Truncated {
+		fmt.Println("\nThe resource was too large to be recorded.")
+		return nil
+	}
+
+	changes := diffSpecs(auditedSpec(e.OldObject), auditedSpec(e.NewObject))
+	if len(changes) == 0 {
+		fmt.Println("\nThe spec didn't change.")
+		return nil
+	}
+	fmt.Println("\nSpec changes:")
+	for _, ch := range changes {
+		switch e.Operation {
+		case types.AuditOperationCreate:
+			fmt.Printf("    %v: %v\n", ch.path, formatDiffValue(ch.newValue))
+		case types.AuditOperationDelete:
+			fmt.Printf("    %v: %v\n", ch.path, formatDiffValue(ch.oldValue))
+		default:
+			fmt.Printf("    %v: %v -> %v\n", ch.path, formatDiffValue(ch.oldValue), formatDiffValue(ch.newValue))
+		}
+	}
+	return nil
+}
+
+// auditedSpec returns the spec of a recorded resource, or nil if the
+// resource wasn't recorded.
+func auditedSpec(obj json.RawMessage) interface{} {
+	var o struct {
+		Spec interface{} `json:"spec"`
+	}
+	if len(obj) == 0 || json.Unmarshal(obj, &o) != nil {
+		return nil
+	}
+	return o.Spec
+}
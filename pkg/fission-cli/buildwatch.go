@@ -18,7 +18,12 @@ package fission_cli
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
+	"io/ioutil"
+	"os"
+	"sort"
+	"strings"
 	"time"
 
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -40,6 +45,26 @@ type (
 
 		// set of metadata in the app spec.  packages outside this set should be ignored.
 		pkgMeta map[string]metav1.ObjectMeta
+
+		// latest build status of the packages in the app spec
+		status map[string]fv1.BuildStatus
+
+		// stop watching once a build fails, instead of waiting for the
+		// remaining builds
+		failFast bool
+
+		// number of lines printed from the end of the log of a failed
+		// build, 0 prints all of it
+		logLines int
+	}
+
+	// packageBuildReport is the machine-readable result of waiting for
+	// package builds, packages are namespace:name keys.
+	packageBuildReport struct {
+		Succeeded []string `json:"succeeded"`
+		Failed    []string `json:"failed"`
+		// Pending builds were still running when the wait was aborted.
+		Pending []string `json:"pending"`
 	}
 )
 
@@ -48,6 +73,7 @@ func makePackageBuildWatcher(fclient *client.Client) *packageBuildWatcher {
 		fclient:  fclient,
 		finished: make(map[string]bool),
 		pkgMeta:  make(map[string]metav1.ObjectMeta),
+		status:   make(map[string]fv1.BuildStatus),
 	}
 }
 
@@ -58,6 +84,8 @@ func (w *packageBuildWatcher) addPackages(pkgMeta map[string]metav1.ObjectMeta)
 }
 
 func (w *packageBuildWatcher) watch(ctx context.Context) {
+	start := time.Now()
+	lastProgress := start
 	for {
 		// non-blocking check if we're cancelled
 		select {
@@ -82,6 +110,7 @@ func (w *packageBuildWatcher) watch(ctx context.Context) {
 			if pkg.Status.BuildStatus == types.BuildStatusNone {
 				continue
 			}
+			w.status[mapKey(&pkg.Metadata)] = pkg.Status.BuildStatus
 			if pkg.Status.BuildStatus == types.BuildStatusPending ||
 				pkg.Status.BuildStatus == types.BuildStatusRunning {
 				keepWaiting = true
@@ -90,6 +119,7 @@ func (w *packageBuildWatcher) watch(ctx context.Context) {
 		}
 
 		// print package status, and error logs if any
+		failed := false
 		for _, pkg := range buildpkgs {
 			k := pkgKey(&pkg)
 			if _, printed := w.finished[k]; printed {
@@ -97,7 +127,12 @@ func (w *packageBuildWatcher) watch(ctx context.Context) {
 			}
 			if pkg.Status.BuildStatus == types.BuildStatusFailed {
 				w.finished[k] = true
-				fmt.Printf("--- Build FAILED: ---\n%v\n------\n", pkg.Status.BuildLog)
+				failed = true
+				fmt.Printf("--- Build FAILED: %v/%v ---\n%v\n------\n", pkg.Metadata.Namespace, pkg.Metadata.Name,
+					tailLines(pkg.Status.BuildLog, w.logLines))
+				if w.logLines > 0 && strings.Count(strings.TrimSpace(pkg.Status.BuildLog), "\n") >= w.logLines {
+					fmt.Printf("Last %v lines shown, see 'fission pkg info --name %v --build-logs full' for the full log\n", w.logLines, pkg.Metadata.Name)
+				}
 			} else if pkg.Status.BuildStatus == types.BuildStatusSucceeded {
 				w.finished[k] = true
 				fmt.Printf("--- Build SUCCEEDED ---\n")
@@ -108,13 +143,68 @@ func (w *packageBuildWatcher) watch(ctx context.Context) {
 		}
 
 		// if there are no builds running, we can stop polling
-		if !keepWaiting {
+		if !keepWaiting || (failed && w.failFast) {
+			return
+		}
+
+		if time.Since(lastProgress) >= progressInterval {
+			lastProgress = time.Now()
+			fmt.Fprintln(os.Stderr, buildProgress(w.report().Pending, time.Since(start)))
+		}
+
+		select {
+		case <-ctx.Done():
 			return
+		case <-time.After(time.Second):
 		}
-		time.Sleep(time.Second)
 	}
 }
 
+// report returns the latest build results of the packages in the app spec.
+func (w *packageBuildWatcher) report() *packageBuildReport {
+	r := &packageBuildReport{
+		Succeeded: make([]string, 0),
+		Failed:    make([]string, 0),
+		Pending:   make([]string, 0),
+	}
+	for k, status := range w.status {
+		switch status {
+		case types.BuildStatusSucceeded:
+			r.Succeeded = append(r.Succeeded, k)
+		case types.BuildStatusFailed:
+			r.Failed = append(r.Failed, k)
+		case types.BuildStatusPending, types.BuildStatusRunning:
+			r.Pending = append(r.Pending, k)
+		}
+	}
+	sort.Strings(r.Succeeded)
+	sort.Strings(r.Failed)
+	sort.Strings(r.Pending)
+	return r
+}
+
+// writeReport writes the report as JSON to the file.
+func (r *packageBuildReport) writeReport(file string) error {
+	b, err := json.MarshalIndent(r, "", "  ")
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(file, b, 0644)
+}
+
+// tailLines returns the last n lines of s, or all of s if n is 0.
+func tailLines(s string, n int) string {
+	s = strings.TrimSpace(s)
+	if n <= 0 {
+		return s
+	}
+	lines := strings.Split(s, "\n")
+	if len(lines) <= n {
+		return s
+	}
+	return strings.Join(lines[len(lines)-n:], "\n")
+}
+
 func pkgKey(pkg *fv1.Package) string {
 	// packages are mutable so we want to keep track of them by resource version
 	return fmt.Sprintf("%v:%v:%v", pkg.Metadata.Name, pkg.Metadata.Namespace, pkg.Metadata.ResourceVersion)
@@ -0,0 +1,29 @@
+package fission_cli
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	fv1 "github.com/fission/fission/pkg/apis/fission.io/v1"
+)
+
+func TestTailLines(t *testing.T) {
+	log := "step 1\nstep 2\nerror: build failed\n"
+	assert.Equal(t, "step 1\nstep 2\nerror: build failed", tailLines(log, 0))
+	assert.Equal(t, "step 1\nstep 2\nerror: build failed", tailLines(log, 3))
+	assert.Equal(t, "step 2\nerror: build failed", tailLines(log, 2))
+}
+
+func TestPackageBuildReport(t *testing.T) {
+	w := makePackageBuildWatcher(nil)
+	w.status["default:b"] = fv1.BuildStatusFailed
+	w.status["default:a"] = fv1.BuildStatusFailed
+	w.status["default:c"] = fv1.BuildStatusSucceeded
+	w.status["default:d"] = fv1.BuildStatusRunning
+
+	r := w.report()
+	assert.Equal(t, []string{"default:a", "default:b"}, r.Failed)
+	assert.Equal(t, []string{"default:c"}, r.Succeeded)
+	assert.Equal(t, []string{"default:d"}, r.Pending)
+}
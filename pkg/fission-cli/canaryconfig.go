@@ -52,6 +52,9 @@ func canaryConfigCreate(c *cli.Context) error {
 	_, err := time.ParseDuration(incrementInterval)
 	util.CheckErr(err, "parsing time duration.")
 
+	err = checkCanaryEnabled(getServerFeatures(client))
+	util.CheckErr(err, "create canary config")
+
 	// check that the trigger exists in the same namespace.
 	m := &metav1.ObjectMeta{
 		Name:      trigger,
@@ -100,6 +103,12 @@ func canaryConfigCreate(c *cli.Context) error {
 			WeightIncrementDuration: incrementInterval,
 			FailureThreshold:        failureThreshold,
 			FailureType:             fv1.FailureTypeStatusCode,
+			LatencyThreshold:        int(c.Duration("latency-threshold") / time.Millisecond),
+			SuccessQueries:          c.StringSlice("success-query"),
+			FailureQueries:          c.StringSlice("failure-query"),
+			OnSuccessWebhook:        c.String("on-success-webhook"),
+			OnFailureWebhook:        c.String("on-failure-webhook"),
+			WebhookFormat:           fv1.CanaryWebhookFormat(c.String("webhook-format")),
 		},
 		Status: fv1.CanaryConfigStatus{
 			Status: fv1.CanaryConfigStatusPending,
@@ -137,6 +146,25 @@ func canaryConfigGet(c *cli.Context) error {
 		canaryCfg.Spec.FailureThreshold, canaryCfg.Spec.FailureType, canaryCfg.Status.Status)
 
 	w.Flush()
+
+	if canaryCfg.Spec.LatencyThreshold > 0 {
+		fmt.Printf("Latency threshold: %v\n", time.Duration(canaryCfg.Spec.LatencyThreshold)*time.Millisecond)
+	}
+	for _, query := range canaryCfg.Spec.SuccessQueries {
+		fmt.Printf("Success query: %v\n", query)
+	}
+	for _, query := range canaryCfg.Spec.FailureQueries {
+		fmt.Printf("Failure query: %v\n", query)
+	}
+	if len(canaryCfg.Spec.OnSuccessWebhook) > 0 {
+		fmt.Printf("On success webhook: %v\n", canaryCfg.Spec.OnSuccessWebhook)
+	}
+	if len(canaryCfg.Spec.OnFailureWebhook) > 0 {
+		fmt.Printf("On failure webhook: %v\n", canaryCfg.Spec.OnFailureWebhook)
+	}
+	if len(canaryCfg.Spec.WebhookFormat) > 0 {
+		fmt.Printf("Webhook format: %v\n", canaryCfg.Spec.WebhookFormat)
+	}
 	return nil
 }
 
@@ -182,6 +210,43 @@ func canaryConfigUpdate(c *cli.Context) error {
 		updateNeeded = true
 	}
 
+	if c.IsSet("latency-threshold") {
+		canaryCfg.Spec.LatencyThreshold = int(c.Duration("latency-threshold") / time.Millisecond)
+		updateNeeded = true
+	}
+
+	if c.IsSet("success-query") {
+		canaryCfg.Spec.SuccessQueries = c.StringSlice("success-query")
+		updateNeeded = true
+	}
+
+	if c.IsSet("failure-query") {
+		canaryCfg.Spec.FailureQueries = c.StringSlice("failure-query")
+		updateNeeded = true
+	}
+
+	// changing the webhooks doesn't start the rollout over
+	webhooksUpdated := false
+	if c.IsSet("on-success-webhook") {
+		canaryCfg.Spec.OnSuccessWebhook = c.String("on-success-webhook")
+		webhooksUpdated = true
+	}
+
+	if c.IsSet("on-failure-webhook") {
+		canaryCfg.Spec.OnFailureWebhook = c.String("on-failure-webhook")
+		webhooksUpdated = true
+	}
+
+	if c.IsSet("webhook-format") {
+		canaryCfg.Spec.WebhookFormat = fv1.CanaryWebhookFormat(c.String("webhook-format"))
+		webhooksUpdated = true
+	}
+
+	if !updateNeeded && webhooksUpdated {
+		_, err = client.CanaryConfigUpdate(canaryCfg)
+		util.CheckErr(err, "update canary config")
+	}
+
 	if updateNeeded {
 		canaryCfg.Status.Status = fv1.CanaryConfigStatusPending
 
@@ -219,7 +284,7 @@ func canaryConfigList(c *cli.Context) error {
 
 	ns := c.String("canaryNamespace")
 
-	canaryCfgs, err := client.CanaryConfigList(ns)
+	canaryCfgs, err := client.CanaryConfigListWithOptions(ns, listOptions(c))
 	util.CheckErr(err, "list canary config")
 
 	w := tabwriter.NewWriter(os.Stdout, 0, 0, 1, ' ', 0)
@@ -0,0 +1,169 @@
+/*
+Copyright 2019 The Fission Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package fission_cli
+
+import (
+	"fmt"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	"github.com/urfave/cli"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	fv1 "github.com/fission/fission/pkg/apis/fission.io/v1"
+	"github.com/fission/fission/pkg/fission-cli/log"
+	"github.com/fission/fission/pkg/fission-cli/util"
+)
+
+// canaryPolicyCreate creates the canary policy of a namespace. From then on
+// updating a function that an HTTP trigger routes to starts a canary
+// deployment from the previous version of the function.
+func canaryPolicyCreate(c *cli.Context) error {
+	client := util.GetApiClient(c.GlobalString("server"))
+
+	name := c.String("name")
+	if len(name) == 0 {
+		log.Fatal("Need a name, use --name.")
+	}
+
+	err := checkCanaryEnabled(getServerFeatures(client))
+	util.CheckErr(err, "create canary policy")
+
+	policy := &fv1.CanaryPolicy{
+		Metadata: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: c.String("canaryNamespace"),
+		},
+		Spec: fv1.CanaryPolicySpec{
+			WeightIncrement:         c.Int("increment-step"),
+			WeightIncrementDuration: c.String("increment-interval"),
+			FailureThreshold:        c.Int("failure-threshold"),
+			FailureType:             fv1.FailureTypeStatusCode,
+			LatencyThreshold:        int(c.Duration("latency-threshold") / time.Millisecond),
+		},
+	}
+
+	_, err = client.CanaryPolicyCreate(policy)
+	util.CheckErr(err, "create canary policy")
+
+	fmt.Printf("canary policy '%v' created, updates of functions in namespace '%v' are now rolled out gradually\n",
+		name, policy.Metadata.Namespace)
+	return nil
+}
+
+func canaryPolicyGet(c *cli.Context) error {
+	client := util.GetApiClient(c.GlobalString("server"))
+
+	name := c.String("name")
+	if len(name) == 0 {
+		log.Fatal("Need a name, use --name.")
+	}
+
+	policy, err := client.CanaryPolicyGet(&metav1.ObjectMeta{
+		Name:      name,
+		Namespace: c.String("canaryNamespace"),
+	})
+	util.CheckErr(err, "get canary policy")
+
+	printCanaryPolicySummary([]fv1.CanaryPolicy{*policy})
+	return nil
+}
+
+func canaryPolicyUpdate(c *cli.Context) error {
+	client := util.GetApiClient(c.GlobalString("server"))
+
+	name := c.String("name")
+	if len(name) == 0 {
+		log.Fatal("Need a name, use --name.")
+	}
+
+	policy, err := client.CanaryPolicyGet(&metav1.ObjectMeta{
+		Name:      name,
+		Namespace: c.String("canaryNamespace"),
+	})
+	util.CheckErr(err, "get canary policy")
+
+	updateNeeded := false
+	if c.IsSet("increment-step") {
+		policy.Spec.WeightIncrement = c.Int("increment-step")
+		updateNeeded = true
+	}
+	if c.IsSet("increment-interval") {
+		policy.Spec.WeightIncrementDuration = c.String("increment-interval")
+		updateNeeded = true
+	}
+	if c.IsSet("failure-threshold") {
+		policy.Spec.FailureThreshold = c.Int("failure-threshold")
+		updateNeeded = true
+	}
+	if c.IsSet("latency-threshold") {
+		policy.Spec.LatencyThreshold = int(c.Duration("latency-threshold") / time.Millisecond)
+		updateNeeded = true
+	}
+	if !updateNeeded {
+		log.Fatal("Nothing to update. Use --increment-step, --increment-interval, --failure-threshold or --latency-threshold.")
+	}
+
+	_, err = client.CanaryPolicyUpdate(policy)
+	util.CheckErr(err, "update canary policy")
+
+	fmt.Printf("canary policy '%v' updated\n", name)
+	return nil
+}
+
+func canaryPolicyDelete(c *cli.Context) error {
+	client := util.GetApiClient(c.GlobalString("server"))
+
+	name := c.String("name")
+	if len(name) == 0 {
+		log.Fatal("Need a name, use --name.")
+	}
+
+	err := client.CanaryPolicyDelete(&metav1.ObjectMeta{
+		Name:      name,
+		Namespace: c.String("canaryNamespace"),
+	})
+	util.CheckErr(err, "delete canary policy")
+
+	fmt.Printf("canary policy '%v' deleted\n", name)
+	return nil
+}
+
+func canaryPolicyList(c *cli.Context) error {
+	client := util.GetApiClient(c.GlobalString("server"))
+
+	policies, err := client.CanaryPolicyListWithOptions(c.String("canaryNamespace"), listOptions(c))
+	util.CheckErr(err, "list canary policies")
+
+	printCanaryPolicySummary(policies)
+	return nil
+}
+
+func printCanaryPolicySummary(policies []fv1.CanaryPolicy) {
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 1, ' ', 0)
+	fmt.Fprintf(w, "%v\t%v\t%v\t%v\t%v\t%v\n", "NAME", "NAMESPACE", "INCREMENT-STEP", "INCREMENT-INTERVAL", "FAILURE-THRESHOLD", "LATENCY-THRESHOLD")
+	for _, p := range policies {
+		latency := "-"
+		if p.Spec.LatencyThreshold > 0 {
+			latency = (time.Duration(p.Spec.LatencyThreshold) * time.Millisecond).String()
+		}
+		fmt.Fprintf(w, "%v\t%v\t%v\t%v\t%v\t%v\n", p.Metadata.Name, p.Metadata.Namespace,
+			p.Spec.WeightIncrement, p.Spec.WeightIncrementDuration, p.Spec.FailureThreshold, latency)
+	}
+	w.Flush()
+}
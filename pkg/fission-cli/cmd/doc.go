@@ -24,7 +24,12 @@ const (
 
 	FISSION_SERVER = "server"
 
-	RESOURCE_NAME = "name"
+	RESOURCE_NAME       = "name"
+	RESOURCE_LABEL      = "label"
+	RESOURCE_ANNOTATION = "annotation"
+
+	RESOURCE_SELECTOR       = "selector"
+	RESOURCE_SELECTOR_ALIAS = "l"
 
 	ENVIRONMENT_NAMESPACE          = "envNamespace"
 	ENVIRONMENT_NAMESPACE_ALIAS    = "envns"
@@ -37,6 +42,24 @@ const (
 	ENVIRONMENT_GRACE_PERIOD       = "graceperiod"
 	ENVIRONMENT_GRACE_PERIOD_ALIAS = "period"
 	ENVIRONMENT_VERSION            = "version"
+	ENVIRONMENT_RUNTIME_CLASS      = "runtimeclass"
+	ENVIRONMENT_IMAGE_PULL_SECRET  = "imagepullsecret"
+	ENVIRONMENT_BUILDER_POOLSIZE   = "builderpoolsize"
+	ENVIRONMENT_BUILD_CACHE        = "build-cache"
+	ENVIRONMENT_BUILD_CACHE_CLASS  = "build-cache-storage-class"
+	ENVIRONMENT_FORCE              = "force"
+
+	BENCHMARK_CODE        = "code"
+	BENCHMARK_REQUESTS    = "requests"
+	BENCHMARK_DURATION    = "duration"
+	BENCHMARK_CONCURRENCY = "concurrency"
+
+	CONFORMANCE_URL         = "url"
+	CONFORMANCE_CODE        = "code"
+	CONFORMANCE_FILEPATH    = "filepath"
+	CONFORMANCE_ENTRYPOINT  = "entrypoint"
+	CONFORMANCE_CONCURRENCY = "concurrency"
+	CONFORMANCE_TIMEOUT     = "timeout"
 
 	SPEC_SPEC    = "spec"
 	SPEC_SPECDIR = "specdir"
@@ -48,6 +71,14 @@ const (
 	RUNTIME_MINSCALE  = "minscale"
 	RUNTIME_MAXSCALE  = "maxscale"
 	RUNTIME_TARGETCPU = "targetcpu"
+
+	RUNTIME_NODESELECTOR = "nodeselector"
+	RUNTIME_TOLERATION   = "toleration"
+
+	BUILDER_MINCPU    = "buildermincpu"
+	BUILDER_MAXCPU    = "buildermaxcpu"
+	BUILDER_MINMEMORY = "builderminmemory"
+	BUILDER_MAXMEMORY = "buildermaxmemory"
 )
 
 // GetCliFlagName concatenates flag and its alias into a command flag name.
@@ -0,0 +1,387 @@
+/*
+Copyright 2019 The Fission Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package environment
+
+import (
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"text/tabwriter"
+	"time"
+
+	"github.com/dchest/uniuri"
+	"github.com/pkg/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	fv1 "github.com/fission/fission/pkg/apis/fission.io/v1"
+	"github.com/fission/fission/pkg/controller/client"
+	"github.com/fission/fission/pkg/fission-cli/cliwrapper/cli"
+	"github.com/fission/fission/pkg/fission-cli/cmd"
+	"github.com/fission/fission/pkg/fission-cli/log"
+	"github.com/fission/fission/pkg/fission-cli/util"
+)
+
+// helloWorldCode is the function deployed by the benchmark, keyed by a
+// substring of the environment image.
+var helloWorldCode = map[string]string{
+	"python": "def main():\n    return \"Hello, world!\\n\"\n",
+	"node":   "module.exports = async function(context) {\n    return {\n        status: 200,\n        body: \"Hello, world!\\n\"\n    };\n}\n",
+	"ruby":   "def handler\n  \"Hello, world!\\n\"\nend\n",
+	"php":    "<?php\necho \"Hello, world!\\n\";\n",
+}
+
+type (
+	BenchmarkSubCommand struct {
+		client      *client.Client
+		envs        []*fv1.Environment
+		code        []byte
+		routerURL   string
+		requests    int
+		duration    time.Duration
+		concurrency int
+		httpClient  *http.Client
+	}
+
+	// benchmarkResult is the performance of an environment on the cluster.
+	benchmarkResult struct {
+		env       *fv1.Environment
+		coldStart time.Duration
+		warmP50   time.Duration
+		warmP99   time.Duration
+		maxRPS    float64
+		errors    int
+		err       error
+	}
+)
+
+func Benchmark(flags cli.Input) error {
+	opts := BenchmarkSubCommand{
+		client: cmd.GetServer(flags),
+	}
+	return opts.do(flags)
+}
+
+func (opts *BenchmarkSubCommand) do(flags cli.Input) error {
+	err := opts.complete(flags)
+	if err != nil {
+		return err
+	}
+	return opts.run(flags)
+}
+
+func (opts *BenchmarkSubCommand) complete(flags cli.Input) error {
+	names := flags.StringSlice(cmd.RESOURCE_NAME)
+	if len(names) == 0 {
+		return errors.New("Need the name of at least one environment, use --name.")
+	}
+	for _, name := range names {
+		env, err := opts.client.EnvironmentGet(&metav1.ObjectMeta{
+			Name:      name,
+			Namespace: flags.String(cmd.ENVIRONMENT_NAMESPACE),
+		})
+		if err != nil {
+			return errors.Wrapf(err, "error getting environment %v", name)
+		}
+		opts.envs = append(opts.envs, env)
+	}
+
+	if codeFile := flags.String(cmd.BENCHMARK_CODE); len(codeFile) > 0 {
+		code, err := ioutil.ReadFile(codeFile)
+		if err != nil {
+			return errors.Wrapf(err, "error reading %v", codeFile)
+		}
+		opts.code = code
+	} else {
+		for _, env := range opts.envs {
+			if len(benchmarkCode(env)) == 0 {
+				return fmt.Errorf("no hello world function for the image %v of environment %v, use --code", env.Spec.Runtime.Image, env.Metadata.Name)
+			}
+		}
+	}
+
+	opts.requests = flags.Int(cmd.BENCHMARK_REQUESTS)
+	opts.duration = time.Duration(flags.Int(cmd.BENCHMARK_DURATION)) * time.Second
+	opts.concurrency = flags.Int(cmd.BENCHMARK_CONCURRENCY)
+	if opts.requests <= 0 || opts.duration <= 0 || opts.concurrency <= 0 {
+		return errors.New("requests, duration and concurrency must be greater than 0")
+	}
+
+	opts.routerURL = getRouterURL()
+	opts.httpClient = &http.Client{
+		Timeout: time.Minute,
+		Transport: &http.Transport{
+			MaxIdleConnsPerHost: opts.concurrency,
+		},
+	}
+	return nil
+}
+
+func (opts *BenchmarkSubCommand) run(flags cli.Input) error {
+	var results []benchmarkResult
+	for _, env := range opts.envs {
+		fmt.Printf("Benchmarking environment '%v'...\n", env.Metadata.Name)
+		result := opts.benchmark(env)
+		if result.err != nil {
+			log.Warn(fmt.Sprintf("Error benchmarking environment %v: %v", env.Metadata.Name, result.err))
+		}
+		results = append(results, result)
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 1, ' ', 0)
+	fmt.Fprintf(w, "%v\t%v\t%v\t%v\t%v\t%v\t%v\n", "ENVIRONMENT", "IMAGE", "COLD START", "WARM P50", "WARM P99", "MAX RPS", "ERRORS")
+	for _, r := range results {
+		if r.err != nil {
+			fmt.Fprintf(w, "%v\t%v\t%v\t%v\t%v\t%v\t%v\n", r.env.Metadata.Name, r.env.Spec.Runtime.Image, "-", "-", "-", "-", "failed")
+			continue
+		}
+		fmt.Fprintf(w, "%v\t%v\t%v\t%v\t%v\t%.1f\t%v\n", r.env.Metadata.Name, r.env.Spec.Runtime.Image,
+			r.coldStart.Round(time.Millisecond), r.warmP50.Round(time.Microsecond*100), r.warmP99.Round(time.Microsecond*100), r.maxRPS, r.errors)
+	}
+	w.Flush()
+	return nil
+}
+
+// benchmark deploys a hello world function to the environment, measures
+// its cold start, warm latency and throughput, and deletes it afterwards.
+func (opts *BenchmarkSubCommand) benchmark(env *fv1.Environment) benchmarkResult {
+	result := benchmarkResult{env: env}
+
+	code := opts.code
+	if len(code) == 0 {
+		code = []byte(benchmarkCode(env))
+	}
+
+	name := util.KubifyName(fmt.Sprintf("benchmark-%v-%v", env.Metadata.Name, uniuri.NewLen(4)))
+	pkg := &fv1.Package{
+		Metadata: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: env.Metadata.Namespace,
+		},
+		Spec: fv1.PackageSpec{
+			Environment: fv1.EnvironmentReference{
+				Name:      env.Metadata.Name,
+				Namespace: env.Metadata.Namespace,
+			},
+			Deployment: fv1.Archive{
+				Type:    fv1.ArchiveTypeLiteral,
+				Literal: code,
+			},
+		},
+		Status: fv1.PackageStatus{
+			BuildStatus:         fv1.BuildStatusNone,
+			LastUpdateTimestamp: time.Now().UTC(),
+		},
+	}
+	pkgMeta, err := opts.client.PackageCreate(pkg)
+	if err != nil {
+		result.err = errors.Wrap(err, "error creating package")
+		return result
+	}
+	defer func() {
+		err := opts.client.PackageDelete(pkgMeta)
+		if err != nil {
+			log.Warn(fmt.Sprintf("Error deleting package %v: %v", pkgMeta.Name, err))
+		}
+	}()
+
+	fn := &fv1.Function{
+		Metadata: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: env.Metadata.Namespace,
+		},
+		Spec: fv1.FunctionSpec{
+			Environment: fv1.EnvironmentReference{
+				Name:      env.Metadata.Name,
+				Namespace: env.Metadata.Namespace,
+			},
+			Package: fv1.FunctionPackageRef{
+				PackageRef: fv1.PackageRef{
+					Name:            pkgMeta.Name,
+					Namespace:       pkgMeta.Namespace,
+					ResourceVersion: pkgMeta.ResourceVersion,
+				},
+			},
+			InvokeStrategy: fv1.InvokeStrategy{
+				StrategyType: fv1.StrategyTypeExecution,
+				ExecutionStrategy: fv1.ExecutionStrategy{
+					ExecutorType: fv1.ExecutorTypePoolmgr,
+				},
+			},
+			FunctionTimeout: 60,
+		},
+	}
+	fnMeta, err := opts.client.FunctionCreate(fn)
+	if err != nil {
+		result.err = errors.Wrap(err, "error creating function")
+		return result
+	}
+	defer func() {
+		err := opts.client.FunctionDelete(fnMeta)
+		if err != nil {
+			log.Warn(fmt.Sprintf("Error deleting function %v: %v", fnMeta.Name, err))
+		}
+	}()
+
+	fnURL := fmt.Sprintf("http://%v/fission-function/%v", opts.routerURL, fnMeta.Name)
+	if fnMeta.Namespace != metav1.NamespaceDefault {
+		fnURL = fmt.Sprintf("http://%v/fission-function/%v/%v", opts.routerURL, fnMeta.Namespace, fnMeta.Name)
+	}
+
+	result.coldStart, err = opts.coldStart(fnURL)
+	if err != nil {
+		result.err = err
+		return result
+	}
+
+	latencies := make([]time.Duration, 0, opts.requests)
+	for i := 0; i < opts.requests; i++ {
+		latency, err := opts.invoke(fnURL)
+		if err != nil {
+			result.errors++
+			continue
+		}
+		latencies = append(latencies, latency)
+	}
+	if len(latencies) == 0 {
+		result.err = errors.New("all warm requests failed")
+		return result
+	}
+	sort.Slice(latencies, func(i, j int) bool { return latencies[i] < latencies[j] })
+	result.warmP50 = percentile(latencies, 50)
+	result.warmP99 = percentile(latencies, 99)
+
+	succeeded, failed := opts.load(fnURL)
+	result.maxRPS = float64(succeeded) / opts.duration.Seconds()
+	result.errors += failed
+
+	return result
+}
+
+// coldStart measures the latency of the first request to the function.
+// Router returns 404 until it learns about the new function, those
+// requests don't reach the executor and are not counted.
+func (opts *BenchmarkSubCommand) coldStart(fnURL string) (time.Duration, error) {
+	deadline := time.Now().Add(30 * time.Second)
+	for {
+		start := time.Now()
+		resp, err := opts.httpClient.Get(fnURL)
+		if err != nil {
+			return 0, errors.Wrap(err, "error calling function")
+		}
+		io.Copy(ioutil.Discard, resp.Body)
+		resp.Body.Close()
+		latency := time.Since(start)
+
+		switch {
+		case resp.StatusCode == http.StatusNotFound && time.Now().Before(deadline):
+			time.Sleep(500 * time.Millisecond)
+		case resp.StatusCode >= 400:
+			return 0, fmt.Errorf("error calling function: %v", resp.Status)
+		default:
+			return latency, nil
+		}
+	}
+}
+
+// invoke calls the function once and returns the latency of the request.
+func (opts *BenchmarkSubCommand) invoke(fnURL string) (time.Duration, error) {
+	start := time.Now()
+	resp, err := opts.httpClient.Get(fnURL)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	_, err = io.Copy(ioutil.Discard, resp.Body)
+	if err != nil {
+		return 0, err
+	}
+	if resp.StatusCode >= 400 {
+		return 0, fmt.Errorf("error calling function: %v", resp.Status)
+	}
+	return time.Since(start), nil
+}
+
+// load calls the function from concurrent workers for the benchmark
+// duration, and returns the number of succeeded and failed requests.
+func (opts *BenchmarkSubCommand) load(fnURL string) (int, int) {
+	var lock sync.Mutex
+	var succeeded, failed int
+
+	deadline := time.Now().Add(opts.duration)
+	var wg sync.WaitGroup
+	for i := 0; i < opts.concurrency; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for time.Now().Before(deadline) {
+				_, err := opts.invoke(fnURL)
+				lock.Lock()
+				if err != nil {
+					failed++
+				} else {
+					succeeded++
+				}
+				lock.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+
+	return succeeded, failed
+}
+
+// benchmarkCode returns the hello world function for the environment.
+func benchmarkCode(env *fv1.Environment) string {
+	return helloWorldCodeForImage(env.Spec.Runtime.Image)
+}
+
+// helloWorldCodeForImage returns the hello world function for the
+// environment image, or empty if there is none for its language.
+func helloWorldCodeForImage(image string) string {
+	for lang, code := range helloWorldCode {
+		if strings.Contains(image, lang) {
+			return code
+		}
+	}
+	return ""
+}
+
+// percentile returns the p-th percentile of sorted durations.
+func percentile(sorted []time.Duration, p int) time.Duration {
+	i := (len(sorted)*p+99)/100 - 1
+	if i < 0 {
+		i = 0
+	}
+	return sorted[i]
+}
+
+// getRouterURL returns the address of the router, from the FISSION_ROUTER
+// environment variable or a port forward to the router pod.
+func getRouterURL() string {
+	routerURL := os.Getenv("FISSION_ROUTER")
+	if len(routerURL) == 0 {
+		localRouterPort := util.SetupPortForward(util.GetFissionNamespace(),
+			"application=fission-router")
+		return "127.0.0.1:" + localRouterPort
+	}
+	return strings.TrimPrefix(routerURL, "http://")
+}
@@ -0,0 +1,559 @@
+/*
+Copyright 2019 The Fission Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package environment
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"os"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"strings"
+	"sync"
+	"text/tabwriter"
+	"time"
+
+	"github.com/dchest/uniuri"
+	"github.com/pkg/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	fv1 "github.com/fission/fission/pkg/apis/fission.io/v1"
+	"github.com/fission/fission/pkg/fission-cli/cliwrapper/cli"
+	"github.com/fission/fission/pkg/fission-cli/cmd"
+	"github.com/fission/fission/pkg/types"
+)
+
+const (
+	conformancePass = "PASS"
+	conformanceFail = "FAIL"
+	conformanceSkip = "SKIP"
+
+	// port environment containers serve functions on
+	conformanceEnvPort = 8888
+
+	// same as the shared volume path of function pods
+	conformanceUserfuncPath = "/userfunc"
+
+	// requests each concurrent client sends in the concurrency check
+	conformanceRequestsPerClient = 5
+)
+
+type (
+	ConformanceSubCommand struct {
+		image       string
+		url         string
+		version     int
+		code        []byte
+		checksum    *fv1.Checksum
+		filePath    string
+		entrypoint  string
+		concurrency int
+		httpClient  *http.Client
+	}
+
+	// conformanceResult is the outcome of a conformance check.
+	conformanceResult struct {
+		check  string
+		result string
+		detail string
+	}
+
+	// conformanceReport collects the results of the checks in order.
+	conformanceReport struct {
+		results []conformanceResult
+	}
+)
+
+func (r *conformanceReport) add(check string, result string, format string, args ...interface{}) {
+	r.results = append(r.results, conformanceResult{
+		check:  check,
+		result: result,
+		detail: fmt.Sprintf(format, args...),
+	})
+}
+
+func (r *conformanceReport) failed() int {
+	n := 0
+	for _, result := range r.results {
+		if result.result == conformanceFail {
+			n++
+		}
+	}
+	return n
+}
+
+// Conformance checks that an environment image implements the protocol
+// fission talks to environment containers with: specialization, error
+// handling, concurrent requests and loading more functions with /v3/load.
+func Conformance(flags cli.Input) error {
+	opts := ConformanceSubCommand{}
+	return opts.do(flags)
+}
+
+func (opts *ConformanceSubCommand) do(flags cli.Input) error {
+	err := opts.complete(flags)
+	if err != nil {
+		return err
+	}
+	return opts.run(flags)
+}
+
+func (opts *ConformanceSubCommand) complete(flags cli.Input) error {
+	opts.image = flags.String(cmd.ENVIRONMENT_IMAGE)
+	opts.url = strings.TrimSuffix(flags.String(cmd.CONFORMANCE_URL), "/")
+	opts.version = flags.Int(cmd.ENVIRONMENT_VERSION)
+
+	if name := flags.String(cmd.RESOURCE_NAME); len(name) > 0 {
+		env, err := cmd.GetServer(flags).EnvironmentGet(&metav1.ObjectMeta{
+			Name:      name,
+			Namespace: flags.String(cmd.ENVIRONMENT_NAMESPACE),
+		})
+		if err != nil {
+			return errors.Wrapf(err, "error getting environment %v", name)
+		}
+		if len(opts.image) == 0 {
+			opts.image = env.Spec.Runtime.Image
+		}
+		if !flags.IsSet(cmd.ENVIRONMENT_VERSION) {
+			opts.version = env.Spec.Version
+		}
+	}
+
+	if len(opts.image) == 0 && len(opts.url) == 0 {
+		return errors.New("Need the image to check, use --image, --name of an environment or --url of a running container.")
+	}
+	if len(opts.image) > 0 && len(opts.url) > 0 {
+		return errors.New("--url can not be used with --image or --name.")
+	}
+	if opts.version < 1 {
+		opts.version = 1
+	}
+
+	if codeFile := flags.String(cmd.CONFORMANCE_CODE); len(codeFile) > 0 {
+		code, err := ioutil.ReadFile(codeFile)
+		if err != nil {
+			return errors.Wrapf(err, "error reading %v", codeFile)
+		}
+		opts.code = code
+	} else if len(opts.url) == 0 {
+		opts.code = []byte(helloWorldCodeForImage(opts.image))
+		if len(opts.code) == 0 {
+			return fmt.Errorf("no hello world function for the image %v, use --code", opts.image)
+		}
+	}
+	if len(opts.code) > 0 {
+		sum := sha256.Sum256(opts.code)
+		opts.checksum = &fv1.Checksum{
+			Type: fv1.ChecksumTypeSHA256,
+			Sum:  hex.EncodeToString(sum[:]),
+		}
+	}
+
+	opts.filePath = flags.String(cmd.CONFORMANCE_FILEPATH)
+	if len(opts.filePath) == 0 {
+		opts.filePath = path.Join(conformanceUserfuncPath, opts.deployName())
+	}
+	opts.entrypoint = flags.String(cmd.CONFORMANCE_ENTRYPOINT)
+
+	opts.concurrency = flags.Int(cmd.CONFORMANCE_CONCURRENCY)
+	timeout := time.Duration(flags.Int(cmd.CONFORMANCE_TIMEOUT)) * time.Second
+	if opts.concurrency <= 0 || timeout <= 0 {
+		return errors.New("concurrency and timeout must be greater than 0")
+	}
+	opts.httpClient = &http.Client{
+		Timeout: timeout,
+		Transport: &http.Transport{
+			MaxIdleConnsPerHost: opts.concurrency,
+		},
+	}
+	return nil
+}
+
+func (opts *ConformanceSubCommand) run(flags cli.Input) error {
+	report := &conformanceReport{}
+
+	if len(opts.url) > 0 {
+		fmt.Printf("Checking the environment container at %v...\n", opts.url)
+		opts.checkErrors(report, opts.url, false)
+		opts.checkFunction(report, opts.url)
+	} else {
+		err := opts.runLocally(report)
+		if err != nil {
+			return err
+		}
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 1, ' ', 0)
+	fmt.Fprintf(w, "%v\t%v\t%v\n", "CHECK", "RESULT", "DETAIL")
+	for _, r := range report.results {
+		fmt.Fprintf(w, "%v\t%v\t%v\n", r.check, r.result, r.detail)
+	}
+	w.Flush()
+
+	if failed := report.failed(); failed > 0 {
+		return fmt.Errorf("%v of %v conformance checks failed", failed, len(report.results))
+	}
+	fmt.Println("The environment passed the conformance checks")
+	return nil
+}
+
+// runLocally checks the image with Docker. The error handling is checked
+// on a container of its own, since fission never specializes a pod again
+// once its specialization failed.
+func (opts *ConformanceSubCommand) runLocally(report *conformanceReport) error {
+	dockerPath, err := exec.LookPath("docker")
+	if err != nil {
+		return errors.Wrap(err, "error finding docker, local conformance checks require Docker")
+	}
+
+	fmt.Printf("Checking the error handling of image %v...\n", opts.image)
+	err = opts.withContainer(dockerPath, false, report, func(baseURL string) {
+		opts.checkErrors(report, baseURL, true)
+	})
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("Checking a function with image %v...\n", opts.image)
+	return opts.withContainer(dockerPath, true, report, func(baseURL string) {
+		opts.checkFunction(report, baseURL)
+	})
+}
+
+// withContainer runs the image with an empty function volume, or with the
+// function in it, and removes the container once check is done. The logs
+// of the container are printed if a check failed.
+func (opts *ConformanceSubCommand) withContainer(dockerPath string, withFunction bool, report *conformanceReport, check func(baseURL string)) error {
+	workDir, err := ioutil.TempDir("", "fission-conformance-")
+	if err != nil {
+		return errors.Wrap(err, "error creating function directory")
+	}
+	defer os.RemoveAll(workDir)
+	// the runtime may not run as the user owning the directory
+	err = os.Chmod(workDir, 0755)
+	if err != nil {
+		return errors.Wrap(err, "error creating function directory")
+	}
+
+	if withFunction {
+		err = ioutil.WriteFile(filepath.Join(workDir, opts.deployName()), opts.code, 0644)
+		if err != nil {
+			return errors.Wrap(err, "error writing function")
+		}
+	}
+
+	port, err := findFreePort()
+	if err != nil {
+		return errors.Wrap(err, "error finding a free local port")
+	}
+
+	container := fmt.Sprintf("fission-conformance-%v", strings.ToLower(uniuri.NewLen(6)))
+	args := []string{"run", "--detach", "--rm",
+		"--name", container,
+		"-p", fmt.Sprintf("127.0.0.1:%v:%v", port, conformanceEnvPort),
+		"-v", fmt.Sprintf("%v:%v", workDir, conformanceUserfuncPath),
+		opts.image,
+	}
+	out, err := exec.Command(dockerPath, args...).CombinedOutput()
+	if err != nil {
+		return errors.Wrapf(err, "error running image %v: %s", opts.image, out)
+	}
+	defer exec.Command(dockerPath, "rm", "--force", container).Run()
+
+	failed := report.failed()
+	check(fmt.Sprintf("http://127.0.0.1:%v", port))
+	if report.failed() > failed {
+		fmt.Printf("\n=== Container logs ===\n")
+		logs := exec.Command(dockerPath, "logs", container)
+		logs.Stdout = os.Stdout
+		logs.Stderr = os.Stdout
+		logs.Run()
+		fmt.Printf("======================\n\n")
+	}
+	return nil
+}
+
+// checkErrors checks that the container refuses requests before it's
+// specialized and survives a failed specialization. The specialization is
+// only checked on a fresh container.
+func (opts *ConformanceSubCommand) checkErrors(report *conformanceReport, baseURL string, fresh bool) {
+	status, err := opts.waitForContainer(baseURL)
+	if err != nil {
+		report.add("start", conformanceFail, "%v", err)
+		return
+	}
+	report.add("start", conformancePass, "listening")
+
+	if status < http.StatusMultipleChoices {
+		report.add("unspecialized request", conformanceFail, "got %v before specialization, requests should fail until a function is loaded", status)
+	} else {
+		report.add("unspecialized request", conformancePass, "got %v", status)
+	}
+
+	if !fresh {
+		report.add("failed specialization", conformanceSkip, "needs a fresh container, check the image with --image")
+		return
+	}
+
+	missing := path.Join(conformanceUserfuncPath, "conformance-missing-"+strings.ToLower(uniuri.NewLen(6)))
+	resp, err := opts.specialize(baseURL, missing)
+	switch {
+	case err != nil:
+		report.add("failed specialization", conformanceFail, "specializing a missing function: %v", err)
+		return
+	case resp.StatusCode < http.StatusMultipleChoices:
+		report.add("failed specialization", conformanceFail, "specializing a missing function got %v, it should fail", resp.StatusCode)
+	default:
+		report.add("failed specialization", conformancePass, "got %v", resp.StatusCode)
+	}
+
+	// a crashed container would leave the pod without an error message
+	resp, err = opts.httpClient.Get(baseURL + "/")
+	if err != nil {
+		report.add("error recovery", conformanceFail, "container stopped serving after the failed specialization: %v", err)
+		return
+	}
+	drain(resp)
+	report.add("error recovery", conformancePass, "still serving, got %v", resp.StatusCode)
+}
+
+// checkFunction specializes the container with the function, calls it, also
+// concurrently, and loads it once more at /v3/load.
+func (opts *ConformanceSubCommand) checkFunction(report *conformanceReport, baseURL string) {
+	if _, err := opts.waitForContainer(baseURL); err != nil {
+		report.add("start", conformanceFail, "%v", err)
+		return
+	}
+
+	start := time.Now()
+	resp, err := opts.specialize(baseURL, opts.filePath)
+	if err != nil {
+		report.add("specialization", conformanceFail, "%v", err)
+		return
+	}
+	if resp.StatusCode >= http.StatusMultipleChoices {
+		report.add("specialization", conformanceFail, "got %v", resp.StatusCode)
+		return
+	}
+	report.add("specialization", conformancePass, "took %v", time.Since(start).Round(time.Millisecond))
+
+	runtimeSum := resp.Header.Get(types.ChecksumHeader)
+	switch {
+	case opts.version < 2 || opts.checksum == nil:
+		report.add("checksum", conformanceSkip, "no checksum is sent")
+	case len(runtimeSum) == 0:
+		report.add("checksum", conformanceSkip, "the optional %v header isn't returned", types.ChecksumHeader)
+	case runtimeSum != opts.checksum.Sum:
+		report.add("checksum", conformanceFail, "got %v, expected %v", runtimeSum, opts.checksum.Sum)
+	default:
+		report.add("checksum", conformancePass, "matches")
+	}
+
+	start = time.Now()
+	err = opts.invoke(baseURL, nil)
+	if err != nil {
+		report.add("invocation", conformanceFail, "%v", err)
+		return
+	}
+	report.add("invocation", conformancePass, "took %v", time.Since(start).Round(time.Millisecond))
+
+	total := opts.concurrency * conformanceRequestsPerClient
+	if failed, err := opts.invokeConcurrently(baseURL); failed > 0 {
+		report.add("concurrency", conformanceFail, "%v of %v requests from %v clients failed, e.g. %v", failed, total, opts.concurrency, err)
+	} else {
+		report.add("concurrency", conformancePass, "%v requests from %v clients", total, opts.concurrency)
+	}
+
+	opts.checkLoad(report, baseURL)
+}
+
+// checkLoad loads the function once more under another name at /v3/load,
+// which is optional: environments without it aren't multiplexed.
+func (opts *ConformanceSubCommand) checkLoad(report *conformanceReport, baseURL string) {
+	if opts.version < 2 {
+		report.add("multiplexing", conformanceSkip, "v1 environments can't load functions")
+		return
+	}
+
+	fnMeta := &metav1.ObjectMeta{
+		Name:      "conformance-load",
+		Namespace: metav1.NamespaceDefault,
+	}
+	resp, err := opts.post(baseURL+types.FunctionLoadPath, types.FunctionLoadRequest{
+		FilePath:         opts.filePath,
+		FunctionName:     opts.entrypoint,
+		FunctionMetadata: fnMeta,
+		EnvVersion:       opts.version,
+	})
+	if err != nil {
+		report.add("multiplexing", conformanceFail, "%v", err)
+		return
+	}
+	switch resp.StatusCode {
+	case http.StatusNotFound, http.StatusMethodNotAllowed, http.StatusNotImplemented:
+		report.add("multiplexing", conformanceSkip, "%v isn't supported (got %v), functions won't share pods", types.FunctionLoadPath, resp.StatusCode)
+		return
+	case http.StatusOK:
+	default:
+		report.add("multiplexing", conformanceFail, "loading a function got %v", resp.StatusCode)
+		return
+	}
+
+	// the router tells the functions of a pod apart by these headers
+	err = opts.invoke(baseURL, http.Header{
+		"X-Fission-Function-Name":      []string{fnMeta.Name},
+		"X-Fission-Function-Namespace": []string{fnMeta.Namespace},
+	})
+	if err != nil {
+		report.add("multiplexing", conformanceFail, "calling the loaded function: %v", err)
+		return
+	}
+	report.add("multiplexing", conformancePass, "loaded and called a function at %v", types.FunctionLoadPath)
+}
+
+// waitForContainer waits until the container responds and returns the
+// status of its first response.
+func (opts *ConformanceSubCommand) waitForContainer(baseURL string) (int, error) {
+	deadline := time.Now().Add(opts.httpClient.Timeout)
+	for {
+		resp, err := opts.httpClient.Get(baseURL + "/")
+		if err == nil {
+			drain(resp)
+			return resp.StatusCode, nil
+		}
+		if time.Now().After(deadline) {
+			return 0, errors.Wrap(err, "container didn't start listening")
+		}
+		time.Sleep(500 * time.Millisecond)
+	}
+}
+
+// specialize loads the function at filePath the same way the fetcher does.
+func (opts *ConformanceSubCommand) specialize(baseURL string, filePath string) (*http.Response, error) {
+	if opts.version < 2 {
+		resp, err := opts.httpClient.Post(baseURL+"/specialize", "text/plain", bytes.NewReader(nil))
+		if err != nil {
+			return nil, err
+		}
+		drain(resp)
+		return resp, nil
+	}
+	return opts.post(baseURL+"/v2/specialize", types.FunctionLoadRequest{
+		FilePath:     filePath,
+		FunctionName: opts.entrypoint,
+		URL:          "/",
+		EnvVersion:   opts.version,
+		Checksum:     opts.checksum,
+	})
+}
+
+// post sends the load request as JSON, the response body is drained.
+func (opts *ConformanceSubCommand) post(url string, loadReq types.FunctionLoadRequest) (*http.Response, error) {
+	body, err := json.Marshal(loadReq)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := opts.httpClient.Post(url, "application/json", bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	drain(resp)
+	return resp, nil
+}
+
+// invoke calls the function and fails on error responses.
+func (opts *ConformanceSubCommand) invoke(baseURL string, header http.Header) error {
+	req, err := http.NewRequest(http.MethodGet, baseURL+"/", nil)
+	if err != nil {
+		return err
+	}
+	for k, v := range header {
+		req.Header[k] = v
+	}
+	resp, err := opts.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	body, _ := ioutil.ReadAll(io.LimitReader(resp.Body, 256))
+	if resp.StatusCode >= http.StatusBadRequest {
+		return fmt.Errorf("got %v: %v", resp.StatusCode, strings.TrimSpace(string(body)))
+	}
+	return nil
+}
+
+// invokeConcurrently calls the function from concurrent clients and
+// returns the number of failed requests and one of their errors.
+func (opts *ConformanceSubCommand) invokeConcurrently(baseURL string) (int, error) {
+	var lock sync.Mutex
+	var failed int
+	var lastErr error
+
+	var wg sync.WaitGroup
+	for i := 0; i < opts.concurrency; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < conformanceRequestsPerClient; j++ {
+				err := opts.invoke(baseURL, nil)
+				if err != nil {
+					lock.Lock()
+					failed++
+					lastErr = err
+					lock.Unlock()
+				}
+			}
+		}()
+	}
+	wg.Wait()
+
+	return failed, lastErr
+}
+
+// deployName is the file name of the function in the function volume,
+// v1 environments load the function from a fixed path.
+func (opts *ConformanceSubCommand) deployName() string {
+	if opts.version < 2 {
+		return "user"
+	}
+	return "deploy"
+}
+
+// drain reads and closes the body of a response, so that the connection
+// is reused.
+func drain(resp *http.Response) {
+	io.Copy(ioutil.Discard, resp.Body)
+	resp.Body.Close()
+}
+
+// findFreePort returns a local port nothing listens on at the moment.
+func findFreePort() (int, error) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return 0, err
+	}
+	defer listener.Close()
+	return listener.Addr().(*net.TCPAddr).Port, nil
+}
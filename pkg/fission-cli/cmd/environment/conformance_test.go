@@ -0,0 +1,93 @@
+package environment
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	fv1 "github.com/fission/fission/pkg/apis/fission.io/v1"
+	"github.com/fission/fission/pkg/types"
+)
+
+// fakeEnvironment implements the v2 specialization protocol and /v3/load.
+type fakeEnvironment struct {
+	lock        sync.Mutex
+	specialized bool
+	// servesBeforeSpecialization breaks the protocol
+	servesBeforeSpecialization bool
+}
+
+func (env *fakeEnvironment) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	env.lock.Lock()
+	defer env.lock.Unlock()
+
+	switch r.URL.Path {
+	case "/v2/specialize", types.FunctionLoadPath:
+		var loadReq types.FunctionLoadRequest
+		err := json.NewDecoder(r.Body).Decode(&loadReq)
+		if err != nil || loadReq.FilePath != "/userfunc/deploy" {
+			http.Error(w, "function not found", http.StatusInternalServerError)
+			return
+		}
+		if loadReq.Checksum != nil {
+			w.Header().Set(types.ChecksumHeader, loadReq.Checksum.Sum)
+		}
+		env.specialized = true
+	default:
+		if !env.specialized && !env.servesBeforeSpecialization {
+			http.Error(w, "not specialized", http.StatusInternalServerError)
+			return
+		}
+		w.Write([]byte("Hello, world!\n"))
+	}
+}
+
+func makeConformanceCommand() *ConformanceSubCommand {
+	return &ConformanceSubCommand{
+		version:     2,
+		checksum:    &fv1.Checksum{Type: fv1.ChecksumTypeSHA256, Sum: "abc"},
+		filePath:    "/userfunc/deploy",
+		concurrency: 2,
+		httpClient:  &http.Client{Timeout: 5 * time.Second},
+	}
+}
+
+func TestConformance(t *testing.T) {
+	opts := makeConformanceCommand()
+
+	errorsServer := httptest.NewServer(&fakeEnvironment{})
+	defer errorsServer.Close()
+	functionServer := httptest.NewServer(&fakeEnvironment{})
+	defer functionServer.Close()
+
+	report := &conformanceReport{}
+	opts.checkErrors(report, errorsServer.URL, true)
+	opts.checkFunction(report, functionServer.URL)
+
+	assert.Equal(t, 0, report.failed(), "%v", report.results)
+	var checks []string
+	for _, r := range report.results {
+		checks = append(checks, r.check)
+		assert.Equal(t, conformancePass, r.result, r.check)
+	}
+	assert.Equal(t, []string{"start", "unspecialized request", "failed specialization", "error recovery",
+		"specialization", "checksum", "invocation", "concurrency", "multiplexing"}, checks)
+}
+
+func TestConformanceFailure(t *testing.T) {
+	opts := makeConformanceCommand()
+
+	server := httptest.NewServer(&fakeEnvironment{servesBeforeSpecialization: true})
+	defer server.Close()
+
+	report := &conformanceReport{}
+	opts.checkErrors(report, server.URL, false)
+	assert.Equal(t, 1, report.failed())
+	assert.Equal(t, conformanceFail, report.results[1].result)
+	assert.Equal(t, conformanceSkip, report.results[2].result)
+}
@@ -21,6 +21,8 @@ import (
 
 	"github.com/hashicorp/go-multierror"
 	"github.com/pkg/errors"
+	apiv1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 
 	fv1 "github.com/fission/fission/pkg/apis/fission.io/v1"
@@ -145,6 +147,36 @@ func createEnvironmentFromCmd(flags cli.Input) (*fv1.Environment, error) {
 		e = multierror.Append(e, err)
 	}
 
+	builder := fv1.Builder{
+		Image:   envBuilderImg,
+		Command: envBuildCmd,
+	}
+	if flags.IsSet(cmd.ENVIRONMENT_BUILDER_POOLSIZE) || isBuilderResourceSet(flags) {
+		if len(envBuilderImg) == 0 {
+			e = multierror.Append(e, errors.New("builder pool size and resources require a builder, use --builder."))
+		}
+		builder.PoolSize = flags.Int(cmd.ENVIRONMENT_BUILDER_POOLSIZE)
+	}
+	if isBuildCacheSet(flags) {
+		if len(envBuilderImg) == 0 {
+			e = multierror.Append(e, errors.New("build cache requires a builder, use --builder."))
+		}
+		cache, err := getBuildCache(flags, nil)
+		if err != nil {
+			e = multierror.Append(e, err)
+		} else {
+			builder.Cache = cache
+		}
+	}
+	if isBuilderResourceSet(flags) {
+		builderResourceReq, err := cmd.GetBuilderResourceReqs(flags, nil)
+		if err != nil {
+			e = multierror.Append(e, err)
+		} else {
+			builder.Container = &apiv1.Container{Resources: *builderResourceReq}
+		}
+	}
+
 	if e.ErrorOrNil() != nil {
 		return nil, e.ErrorOrNil()
 	}
@@ -163,18 +195,26 @@ func createEnvironmentFromCmd(flags cli.Input) (*fv1.Environment, error) {
 			Runtime: fv1.Runtime{
 				Image: envImg,
 			},
-			Builder: fv1.Builder{
-				Image:   envBuilderImg,
-				Command: envBuildCmd,
-			},
+			Builder:                      builder,
 			Poolsize:                     poolsize,
 			Resources:                    *resourceReq,
 			AllowAccessToExternalNetwork: envExternalNetwork,
 			TerminationGracePeriod:       envGracePeriod,
 			KeepArchive:                  keepArchive,
+			RuntimeClassName:             flags.String(cmd.ENVIRONMENT_RUNTIME_CLASS),
+			ImagePullSecret:              flags.String(cmd.ENVIRONMENT_IMAGE_PULL_SECRET),
 		},
 	}
 
+	err = cmd.UpdateMetadataWithCmd(flags, &env.Metadata)
+	if err != nil {
+		return nil, err
+	}
+	err = updateRuntimeSchedulingWithCmd(env, flags)
+	if err != nil {
+		return nil, err
+	}
+
 	err = env.Validate()
 	if err != nil {
 		return nil, fv1.AggregateValidationErrors("Environment", err)
@@ -182,3 +222,63 @@ func createEnvironmentFromCmd(flags cli.Input) (*fv1.Environment, error) {
 
 	return env, nil
 }
+
+// isBuilderResourceSet returns true if any builder resource flag is set.
+func isBuilderResourceSet(flags cli.Input) bool {
+	return flags.IsSet(cmd.BUILDER_MINCPU) || flags.IsSet(cmd.BUILDER_MAXCPU) ||
+		flags.IsSet(cmd.BUILDER_MINMEMORY) || flags.IsSet(cmd.BUILDER_MAXMEMORY)
+}
+
+func isBuildCacheSet(flags cli.Input) bool {
+	return flags.IsSet(cmd.ENVIRONMENT_BUILD_CACHE) || flags.IsSet(cmd.ENVIRONMENT_BUILD_CACHE_CLASS)
+}
+
+// updateRuntimeSchedulingWithCmd sets the node selector and tolerations
+// of the flags on the runtime pod spec of the environment, which both
+// executors merge into the function pods.
+func updateRuntimeSchedulingWithCmd(env *fv1.Environment, flags cli.Input) error {
+	if !cmd.IsSchedulingSet(flags) {
+		return nil
+	}
+
+	podSpec := &apiv1.PodSpec{}
+	if env.Spec.Runtime.PodSpec != nil {
+		podSpec = env.Spec.Runtime.PodSpec.DeepCopy()
+	}
+	nodeSelector, tolerations, err := cmd.UpdateSchedulingWithCmd(flags, podSpec.NodeSelector, podSpec.Tolerations)
+	if err != nil {
+		return err
+	}
+	podSpec.NodeSelector = nodeSelector
+	podSpec.Tolerations = tolerations
+	env.Spec.Runtime.PodSpec = podSpec
+	return nil
+}
+
+// getBuildCache returns the build cache settings from the flags merged into
+// the existing ones, or nil if the build cache is disabled with a zero size.
+func getBuildCache(flags cli.Input, existing *fv1.BuildCache) (*fv1.BuildCache, error) {
+	cache := &fv1.BuildCache{}
+	if existing != nil {
+		cache = existing.DeepCopy()
+	}
+
+	if flags.IsSet(cmd.ENVIRONMENT_BUILD_CACHE) {
+		size, err := resource.ParseQuantity(flags.String(cmd.ENVIRONMENT_BUILD_CACHE))
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to parse build cache size %q", flags.String(cmd.ENVIRONMENT_BUILD_CACHE))
+		}
+		if size.IsZero() {
+			return nil, nil
+		}
+		cache.Size = size
+	}
+	if flags.IsSet(cmd.ENVIRONMENT_BUILD_CACHE_CLASS) {
+		cache.StorageClassName = flags.String(cmd.ENVIRONMENT_BUILD_CACHE_CLASS)
+	}
+
+	if cache.Size.IsZero() {
+		return nil, errors.Errorf("build cache requires a size, use --%v", cmd.ENVIRONMENT_BUILD_CACHE)
+	}
+	return cache, nil
+}
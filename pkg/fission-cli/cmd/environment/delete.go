@@ -19,6 +19,9 @@ package environment
 import (
 	"fmt"
 
+	"github.com/pkg/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
 	"github.com/fission/fission/pkg/controller/client"
 	"github.com/fission/fission/pkg/fission-cli/cliwrapper/cli"
 	cmdutils "github.com/fission/fission/pkg/fission-cli/cmd"
@@ -42,6 +45,29 @@ func (opts *DeleteSubCommand) do(flags cli.Input) error {
 		return err
 	}
 
+	usage, err := getEnvUsage(opts.client)
+	util.CheckErr(err, "find functions and packages of the environment")
+
+	if u, ok := usage[envKey(m.Namespace, m.Name)]; ok {
+		if !flags.Bool(cmdutils.ENVIRONMENT_FORCE) {
+			return errors.Errorf("environment '%v' is used by %v; they can't start or build without it, delete them first or use --%v to delete them with the environment",
+				m.Name, u, cmdutils.ENVIRONMENT_FORCE)
+		}
+
+		// delete the functions before their packages, so that no
+		// function is left with a missing package
+		for _, fn := range u.functions {
+			err = opts.client.FunctionDelete(&metav1.ObjectMeta{Namespace: fn.Metadata.Namespace, Name: fn.Metadata.Name})
+			util.CheckErr(err, fmt.Sprintf("delete function '%v'", fn.Metadata.Name))
+			fmt.Printf("function '%v' deleted\n", fn.Metadata.Name)
+		}
+		for _, pkg := range u.packages {
+			err = opts.client.PackageDelete(&metav1.ObjectMeta{Namespace: pkg.Metadata.Namespace, Name: pkg.Metadata.Name})
+			util.CheckErr(err, fmt.Sprintf("delete package '%v'", pkg.Metadata.Name))
+			fmt.Printf("package '%v' deleted\n", pkg.Metadata.Name)
+		}
+	}
+
 	err = opts.client.EnvironmentDelete(m)
 	util.CheckErr(err, "delete environment")
 
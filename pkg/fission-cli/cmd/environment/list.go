@@ -41,17 +41,27 @@ func List(flags cli.Input) error {
 func (opts *ListSubCommand) do(flags cli.Input) error {
 	envNamespace := flags.String(cmdutils.ENVIRONMENT_NAMESPACE)
 
-	envs, err := opts.client.EnvironmentList(envNamespace)
+	envs, err := opts.client.EnvironmentListWithOptions(envNamespace, client.ListOptions{
+		LabelSelector: flags.String(cmdutils.RESOURCE_SELECTOR),
+	})
 	util.CheckErr(err, "list environments")
 
+	usage, err := getEnvUsage(opts.client)
+	util.CheckErr(err, "list functions and packages")
+
 	w := tabwriter.NewWriter(os.Stdout, 0, 0, 1, ' ', 0)
-	fmt.Fprintf(w, "%v\t%v\t%v\t%v\t%v\t%v\t%v\t%v\t%v\t%v\t%v\n", "NAME", "UID", "IMAGE", "BUILDER_IMAGE", "POOLSIZE", "MINCPU", "MAXCPU", "MINMEMORY", "MAXMEMORY", "EXTNET", "GRACETIME")
+	fmt.Fprintf(w, "%v\t%v\t%v\t%v\t%v\t%v\t%v\t%v\t%v\t%v\t%v\t%v\t%v\n", "NAME", "UID", "IMAGE", "BUILDER_IMAGE", "POOLSIZE", "MINCPU", "MAXCPU", "MINMEMORY", "MAXMEMORY", "EXTNET", "GRACETIME", "FUNCTIONS", "PACKAGES")
 	for _, env := range envs {
-		fmt.Fprintf(w, "%v\t%v\t%v\t%v\t%v\t%v\t%v\t%v\t%v\t%v\t%v\n",
+		var functions, packages int
+		if u, ok := usage[envKey(env.Metadata.Namespace, env.Metadata.Name)]; ok {
+			functions, packages = len(u.functions), len(u.packages)
+		}
+		fmt.Fprintf(w, "%v\t%v\t%v\t%v\t%v\t%v\t%v\t%v\t%v\t%v\t%v\t%v\t%v\n",
 			env.Metadata.Name, env.Metadata.UID, env.Spec.Runtime.Image, env.Spec.Builder.Image, env.Spec.Poolsize,
 			env.Spec.Resources.Requests.Cpu(), env.Spec.Resources.Limits.Cpu(),
 			env.Spec.Resources.Requests.Memory(), env.Spec.Resources.Limits.Memory(),
-			env.Spec.AllowAccessToExternalNetwork, env.Spec.TerminationGracePeriod)
+			env.Spec.AllowAccessToExternalNetwork, env.Spec.TerminationGracePeriod,
+			functions, packages)
 	}
 	w.Flush()
 
@@ -21,6 +21,7 @@ import (
 	"fmt"
 
 	"github.com/hashicorp/go-multierror"
+	apiv1 "k8s.io/api/core/v1"
 
 	fv1 "github.com/fission/fission/pkg/apis/fission.io/v1"
 	"github.com/fission/fission/pkg/controller/client"
@@ -84,8 +85,11 @@ func updateExistingEnvironmentWithCmd(env *fv1.Environment, flags cli.Input) (*f
 	envBuildCmd := flags.String(cmd.ENVIRONMENT_BUILDCOMMAND)
 	envExternalNetwork := flags.Bool(cmd.ENVIRONMENT_EXTERNAL_NETWORK)
 
-	if len(envImg) == 0 && len(envBuilderImg) == 0 && len(envBuildCmd) == 0 {
-		e = multierror.Append(e, errors.New("need --image to specify env image, or use --builder to specify env builder, or use --buildcmd to specify new build command"))
+	if len(envImg) == 0 && len(envBuilderImg) == 0 && len(envBuildCmd) == 0 &&
+		!flags.IsSet(cmd.ENVIRONMENT_RUNTIME_CLASS) && !flags.IsSet(cmd.ENVIRONMENT_IMAGE_PULL_SECRET) &&
+		!flags.IsSet(cmd.ENVIRONMENT_BUILDER_POOLSIZE) && !isBuilderResourceSet(flags) && !isBuildCacheSet(flags) &&
+		!cmd.IsMetadataSet(flags) && !cmd.IsSchedulingSet(flags) {
+		e = multierror.Append(e, errors.New("need --image to specify env image, or use --builder to specify env builder, or use --buildcmd to specify new build command, or use --runtimeclass to specify new runtime class, or use --imagepullsecret to specify new image pull secret, or use --builderpoolsize and the builder resource flags to scale the builder, or use --build-cache to size the build cache, or use --label, --annotation, --nodeselector or --toleration to change the runtime pods"))
 	}
 
 	if len(envImg) > 0 {
@@ -103,6 +107,40 @@ func updateExistingEnvironmentWithCmd(env *fv1.Environment, flags cli.Input) (*f
 		env.Spec.Builder.Command = envBuildCmd
 	}
 
+	if (flags.IsSet(cmd.ENVIRONMENT_BUILDER_POOLSIZE) || isBuilderResourceSet(flags)) && len(env.Spec.Builder.Image) == 0 {
+		e = multierror.Append(e, errors.New("builder pool size and resources require a builder, use --builder."))
+	}
+
+	if flags.IsSet(cmd.ENVIRONMENT_BUILDER_POOLSIZE) {
+		env.Spec.Builder.PoolSize = flags.Int(cmd.ENVIRONMENT_BUILDER_POOLSIZE)
+	}
+
+	if isBuilderResourceSet(flags) {
+		container := &apiv1.Container{}
+		if env.Spec.Builder.Container != nil {
+			container = env.Spec.Builder.Container.DeepCopy()
+		}
+		builderResourceReq, err := cmd.GetBuilderResourceReqs(flags, &container.Resources)
+		if err != nil {
+			e = multierror.Append(e, err)
+		} else {
+			container.Resources = *builderResourceReq
+			env.Spec.Builder.Container = container
+		}
+	}
+
+	if isBuildCacheSet(flags) {
+		if len(env.Spec.Builder.Image) == 0 {
+			e = multierror.Append(e, errors.New("build cache requires a builder, use --builder."))
+		}
+		cache, err := getBuildCache(flags, env.Spec.Builder.Cache)
+		if err != nil {
+			e = multierror.Append(e, err)
+		} else {
+			env.Spec.Builder.Cache = cache
+		}
+	}
+
 	if flags.IsSet(cmd.ENVIRONMENT_POOLSIZE) {
 		env.Spec.Poolsize = flags.Int(cmd.ENVIRONMENT_POOLSIZE)
 	}
@@ -115,8 +153,25 @@ func updateExistingEnvironmentWithCmd(env *fv1.Environment, flags cli.Input) (*f
 		env.Spec.KeepArchive = flags.Bool(cmd.ENVIRONMENT_KEEPARCHIVE)
 	}
 
+	if flags.IsSet(cmd.ENVIRONMENT_RUNTIME_CLASS) {
+		env.Spec.RuntimeClassName = flags.String(cmd.ENVIRONMENT_RUNTIME_CLASS)
+	}
+
+	if flags.IsSet(cmd.ENVIRONMENT_IMAGE_PULL_SECRET) {
+		env.Spec.ImagePullSecret = flags.String(cmd.ENVIRONMENT_IMAGE_PULL_SECRET)
+	}
+
 	env.Spec.AllowAccessToExternalNetwork = envExternalNetwork
 
+	err := cmd.UpdateMetadataWithCmd(flags, &env.Metadata)
+	if err != nil {
+		e = multierror.Append(e, err)
+	}
+	err = updateRuntimeSchedulingWithCmd(env, flags)
+	if err != nil {
+		e = multierror.Append(e, err)
+	}
+
 	if flags.IsSet(cmd.RUNTIME_MINCPU) || flags.IsSet(cmd.RUNTIME_MAXCPU) ||
 		flags.IsSet(cmd.RUNTIME_MINMEMORY) || flags.IsSet(cmd.RUNTIME_MAXMEMORY) ||
 		flags.IsSet(cmd.RUNTIME_MINSCALE) || flags.IsSet(cmd.RUNTIME_MAXSCALE) {
@@ -0,0 +1,103 @@
+/*
+Copyright 2019 The Fission Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package environment
+
+import (
+	"fmt"
+	"strings"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	fv1 "github.com/fission/fission/pkg/apis/fission.io/v1"
+	"github.com/fission/fission/pkg/controller/client"
+)
+
+// envUsage are the functions and packages referencing an environment.
+// Functions of a deleted environment can't start new pods, and its
+// packages can't be rebuilt.
+type envUsage struct {
+	functions []fv1.Function
+	packages  []fv1.Package
+}
+
+func envKey(namespace string, name string) string {
+	return fmt.Sprintf("%v/%v", namespace, name)
+}
+
+// getEnvUsage returns the usage of the environments by the functions and
+// packages of all namespaces, keyed by envKey.
+func getEnvUsage(fclient *client.Client) (map[string]*envUsage, error) {
+	fns, err := fclient.FunctionList(metav1.NamespaceAll)
+	if err != nil {
+		return nil, err
+	}
+	pkgs, err := fclient.PackageList(metav1.NamespaceAll)
+	if err != nil {
+		return nil, err
+	}
+	return countEnvUsage(fns, pkgs), nil
+}
+
+func countEnvUsage(fns []fv1.Function, pkgs []fv1.Package) map[string]*envUsage {
+	usage := make(map[string]*envUsage)
+	get := func(ref fv1.EnvironmentReference) *envUsage {
+		k := envKey(ref.Namespace, ref.Name)
+		u, ok := usage[k]
+		if !ok {
+			u = &envUsage{}
+			usage[k] = u
+		}
+		return u
+	}
+
+	for _, fn := range fns {
+		// container functions have no environment
+		if len(fn.Spec.Environment.Name) == 0 {
+			continue
+		}
+		u := get(fn.Spec.Environment)
+		u.functions = append(u.functions, fn)
+	}
+	for _, pkg := range pkgs {
+		if len(pkg.Spec.Environment.Name) == 0 {
+			continue
+		}
+		u := get(pkg.Spec.Environment)
+		u.packages = append(u.packages, pkg)
+	}
+	return usage
+}
+
+// String lists the functions and packages as namespace/name.
+func (u *envUsage) String() string {
+	var parts []string
+	if len(u.functions) > 0 {
+		names := make([]string, 0, len(u.functions))
+		for _, fn := range u.functions {
+			names = append(names, envKey(fn.Metadata.Namespace, fn.Metadata.Name))
+		}
+		parts = append(parts, fmt.Sprintf("%v function(s): %v", len(u.functions), strings.Join(names, ", ")))
+	}
+	if len(u.packages) > 0 {
+		names := make([]string, 0, len(u.packages))
+		for _, pkg := range u.packages {
+			names = append(names, envKey(pkg.Metadata.Namespace, pkg.Metadata.Name))
+		}
+		parts = append(parts, fmt.Sprintf("%v package(s): %v", len(u.packages), strings.Join(names, ", ")))
+	}
+	return strings.Join(parts, " and ")
+}
@@ -0,0 +1,46 @@
+package environment
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	fv1 "github.com/fission/fission/pkg/apis/fission.io/v1"
+)
+
+func TestCountEnvUsage(t *testing.T) {
+	fn := func(ns, name, envNs, env string) fv1.Function {
+		return fv1.Function{
+			Metadata: metav1.ObjectMeta{Namespace: ns, Name: name},
+			Spec:     fv1.FunctionSpec{Environment: fv1.EnvironmentReference{Namespace: envNs, Name: env}},
+		}
+	}
+	pkg := func(ns, name, envNs, env string) fv1.Package {
+		return fv1.Package{
+			Metadata: metav1.ObjectMeta{Namespace: ns, Name: name},
+			Spec:     fv1.PackageSpec{Environment: fv1.EnvironmentReference{Namespace: envNs, Name: env}},
+		}
+	}
+
+	usage := countEnvUsage(
+		[]fv1.Function{
+			fn("default", "hello", "default", "nodejs"),
+			fn("team", "world", "default", "nodejs"),
+			fn("default", "py", "default", "python"),
+			// container function
+			fn("default", "nginx", "", ""),
+		},
+		[]fv1.Package{
+			pkg("default", "hello-pkg", "default", "nodejs"),
+			pkg("default", "go-pkg", "default", "go"),
+		})
+
+	assert.Len(t, usage, 3)
+	nodejs := usage[envKey("default", "nodejs")]
+	assert.Len(t, nodejs.functions, 2)
+	assert.Len(t, nodejs.packages, 1)
+	assert.Equal(t, "2 function(s): default/hello, team/world and 1 package(s): default/hello-pkg", nodejs.String())
+	assert.Equal(t, "1 function(s): default/py", usage[envKey("default", "python")].String())
+	assert.Equal(t, "1 package(s): default/go-pkg", usage[envKey("default", "go")].String())
+}
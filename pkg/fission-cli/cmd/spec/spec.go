@@ -42,6 +42,8 @@ var specDefaultEncoder = encoder.DefaultYAMLEncoder()
 const (
 	FISSION_DEPLOYMENT_NAME_KEY = "fission-name"
 	FISSION_DEPLOYMENT_UID_KEY  = "fission-uid"
+	// checksum of the spec of a resource when it was last applied
+	FISSION_SPEC_CHECKSUM_KEY = "fission-spec-checksum"
 
 	SPEC_API_VERSION          = "fission.io/v1"
 	ARCHIVE_URL_PREFIX string = "archive://"
@@ -77,6 +79,18 @@ You can edit any of the files in this directory, except 'fission-deployment-conf
 which contains a UID that you should never change.  To apply your changes simply use
 'fission spec apply'.
 
+Deployment environments
+-----------------------
+
+Specs may contain ${VAR} placeholders, which are substituted with the variables of the
+file given with 'fission spec apply --env-file prod.env', or of the environment.  Use $$
+for a literal $.
+
+The specs in 'overlays/<name>' are only read with 'fission spec apply --overlay <name>'.
+They patch the specs of the same kind, name and namespace, and specs of other resources
+are added.  For example, 'overlays/prod/env.yaml' may set only the poolsize of an
+environment for the prod cluster.
+
 fission-deployment-config.yaml
 ------------------------------
 
@@ -136,6 +150,32 @@ type (
 		ExcludeGlobs []string `json:"exclude,omitempty"`
 	}
 
+	// LintConfig holds the rules checked by 'fission spec lint'. It can be
+	// kept in the spec directory or in a separate file shared by projects.
+	LintConfig struct {
+		// TypeMeta describes the type of this object. It is inlined. The Kind
+		// field should always be "LintConfig".
+		TypeMeta `json:",inline"`
+
+		// NamePattern is a regular expression the names of all resources
+		// must match, e.g. "^team-[a-z0-9-]+$".
+		NamePattern string `json:"namePattern,omitempty"`
+
+		// RequiredLabels are the label keys all resources must have.
+		RequiredLabels []string `json:"requiredLabels,omitempty"`
+
+		// RequiredAnnotations are the annotation keys all resources must have.
+		RequiredAnnotations []string `json:"requiredAnnotations,omitempty"`
+
+		// ForbiddenExecutorTypes are the executor types functions must not use.
+		ForbiddenExecutorTypes []fv1.ExecutorType `json:"forbiddenExecutorTypes,omitempty"`
+
+		// RequireResourceLimits requires CPU and memory limits on environments
+		// and on functions with their own deployment (newdeploy), poolmgr
+		// functions run with the limits of their environment.
+		RequireResourceLimits bool `json:"requireResourceLimits,omitempty"`
+	}
+
 	// TypeMeta is the same as Kubernetes' TypeMeta, and allows us to version and
 	// unmarshal local-only objects (like ArchiveUploadSpec) the same way that
 	// Kubernetes does.
@@ -154,6 +194,7 @@ type (
 		TimeTriggers            []fv1.TimeTrigger
 		MessageQueueTriggers    []fv1.MessageQueueTrigger
 		ArchiveUploadSpecs      []ArchiveUploadSpec
+		LintConfig              *LintConfig
 
 		SourceMap SourceMap
 	}
@@ -218,8 +259,11 @@ func Save(data []byte, specDir string, specFile string) error {
 
 // called from `fission * create --spec`
 func SpecSave(resource interface{}, specFile string) error {
-	specDir := "specs"
+	return SpecSaveInDir(resource, "specs", specFile)
+}
 
+// SpecSaveInDir is SpecSave for a spec directory other than the default one.
+func SpecSaveInDir(resource interface{}, specDir string, specFile string) error {
 	// make sure we're writing a known type
 	var data []byte
 	var err error
@@ -317,9 +361,10 @@ func (fr *FissionResources) Validate(c *cli.Context) error {
 	for _, p := range fr.Packages {
 		packages[MapKey(&p.Metadata)] = false
 
-		// check archive refs from package
+		// check archive refs from package, archives with a remote URL
+		// aren't part of the specs
 		aname := strings.TrimPrefix(p.Spec.Source.URL, ARCHIVE_URL_PREFIX)
-		if len(aname) > 0 {
+		if strings.HasPrefix(p.Spec.Source.URL, ARCHIVE_URL_PREFIX) && len(aname) > 0 && p.Spec.Source.Type != fv1.ArchiveTypeGit {
 			if _, ok := archives[aname]; !ok {
 				result = multierror.Append(result, fmt.Errorf(
 					"%v: package '%v' references unknown source archive %v%v",
@@ -333,7 +378,7 @@ func (fr *FissionResources) Validate(c *cli.Context) error {
 		}
 
 		aname = strings.TrimPrefix(p.Spec.Deployment.URL, ARCHIVE_URL_PREFIX)
-		if len(aname) > 0 {
+		if strings.HasPrefix(p.Spec.Deployment.URL, ARCHIVE_URL_PREFIX) && len(aname) > 0 {
 			if _, ok := archives[aname]; !ok {
 				result = multierror.Append(result, fmt.Errorf(
 					"%v: package '%v' references unknown deployment archive %v%v",
@@ -364,6 +409,11 @@ func (fr *FissionResources) Validate(c *cli.Context) error {
 	for _, f := range fr.Functions {
 		functions[MapKey(&f.Metadata)] = false
 
+		// the code of functions with a prebuilt image is in the image
+		if f.Spec.PrebuiltImage != nil {
+			continue
+		}
+
 		pkgMeta := &metav1.ObjectMeta{
 			Name:      f.Spec.Package.PackageRef.Name,
 			Namespace: f.Spec.Package.PackageRef.Namespace,
@@ -441,6 +491,17 @@ func (fr *FissionResources) Validate(c *cli.Context) error {
 		if err != nil {
 			result = multierror.Append(result, err)
 		}
+		if t.Spec.APIVersioning != nil {
+			for _, fnName := range t.Spec.APIVersioning.Functions {
+				err := fr.validateFunctionReference(functions, t.Kind, &t.Metadata, fv1.FunctionReference{
+					Type: fv1.FunctionReferenceTypeFunctionName,
+					Name: fnName,
+				})
+				if err != nil {
+					result = multierror.Append(result, err)
+				}
+			}
+		}
 
 		if len(t.Spec.Host) > 0 {
 			log.Warn(fmt.Sprintf("Host in HTTPTrigger spec.Host is now marked as deprecated, see 'help' for details"))
@@ -571,7 +632,7 @@ func (fr *FissionResources) ParseYaml(b []byte, loc *Location) error {
 		}
 
 		// TODO move to validator
-		if !strings.HasPrefix(v.Spec.RelativeURL, "/") {
+		if len(v.Spec.Prefix) == 0 && !strings.HasPrefix(v.Spec.RelativeURL, "/") {
 			v.Spec.RelativeURL = fmt.Sprintf("/%s", v.Spec.RelativeURL)
 		}
 
@@ -611,6 +672,13 @@ func (fr *FissionResources) ParseYaml(b []byte, loc *Location) error {
 			return errors.Wrap(err, fmt.Sprintf("Failed to parse %v in %v", tm.Kind, loc))
 		}
 		fr.DeploymentConfig = v
+	case "LintConfig":
+		var v LintConfig
+		err = yaml.Unmarshal(b, &v)
+		if err != nil {
+			return errors.Wrap(err, fmt.Sprintf("Failed to parse %v in %v", tm.Kind, loc))
+		}
+		fr.LintConfig = &v
 	case "ArchiveUploadSpec":
 		var v ArchiveUploadSpec
 		err = yaml.Unmarshal(b, &v)
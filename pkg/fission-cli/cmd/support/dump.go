@@ -20,10 +20,13 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"sort"
+	"strings"
 	"sync"
 	"time"
 
 	"github.com/pkg/errors"
+	"k8s.io/client-go/kubernetes"
 
 	"github.com/fission/fission/pkg/controller/client"
 	"github.com/fission/fission/pkg/fission-cli/cliwrapper/cli"
@@ -38,6 +41,118 @@ const (
 	DEFAULT_OUTPUT_DIR  = "fission-dump"
 )
 
+// Selectors of --only besides the names of the components.
+const (
+	SELECTOR_KUBERNETES = "kubernetes"
+	SELECTOR_VERSION    = "version"
+	SELECTOR_BUILDERS   = "builders"
+	SELECTOR_FUNCTIONS  = "functions"
+	SELECTOR_TRIGGERS   = "triggers"
+)
+
+// components are the fission components whose specs and logs are dumped.
+var components = []string{"buildermgr", "controller", "executor", "influxdb", "kubewatcher", "logger",
+	"mqtrigger", "nats-streaming", "redis", "router", "storagesvc", "timer"}
+
+// parseSelectors returns the resources picked with --only, given comma
+// separated or repeated, or nil if all of them are dumped.
+func parseSelectors(only []string) (map[string]bool, error) {
+	known := map[string]bool{
+		SELECTOR_KUBERNETES: true,
+		SELECTOR_VERSION:    true,
+		SELECTOR_BUILDERS:   true,
+		SELECTOR_FUNCTIONS:  true,
+		SELECTOR_TRIGGERS:   true,
+	}
+	for _, c := range components {
+		known[c] = true
+	}
+
+	var selectors map[string]bool
+	for _, o := range only {
+		for _, s := range strings.Split(o, ",") {
+			s = strings.TrimSpace(s)
+			if len(s) == 0 {
+				continue
+			}
+			if !known[s] {
+				names := make([]string, 0, len(known))
+				for k := range known {
+					names = append(names, k)
+				}
+				sort.Strings(names)
+				return nil, fmt.Errorf("unknown resource %q to dump, must be one of %v", s, strings.Join(names, ", "))
+			}
+			if selectors == nil {
+				selectors = make(map[string]bool)
+			}
+			selectors[s] = true
+		}
+	}
+	return selectors, nil
+}
+
+// dumpResources returns the resources to dump by the name of their
+// directory in the dump.
+func (opts *DumpSubCommand) dumpResources(k8sClient *kubernetes.Clientset, selectors map[string]bool) map[string]resources.Resource {
+	selected := func(s string) bool {
+		return selectors == nil || selectors[s]
+	}
+	ress := make(map[string]resources.Resource)
+
+	if selected(SELECTOR_KUBERNETES) {
+		ress["kubernetes-version"] = resources.NewKubernetesVersion(k8sClient)
+		ress["kubernetes-nodes"] = resources.NewKubernetesObjectDumper(k8sClient, resources.KubernetesNode, "")
+	}
+
+	if selected(SELECTOR_VERSION) {
+		ress["fission-version"] = resources.NewFissionVersion(opts.client)
+	}
+
+	// fission component logs & spec
+	var svcs []string
+	for _, c := range components {
+		if selected(c) {
+			svcs = append(svcs, c)
+		}
+	}
+	if len(svcs) > 0 {
+		selector := fmt.Sprintf("svc in (%v)", strings.Join(svcs, ", "))
+		ress["fission-components-svc-spec"] = resources.NewKubernetesObjectDumper(k8sClient, resources.KubernetesService, selector)
+		ress["fission-components-deployment-spec"] = resources.NewKubernetesObjectDumper(k8sClient, resources.KubernetesDeployment, selector)
+		ress["fission-components-daemonset-spec"] = resources.NewKubernetesObjectDumper(k8sClient, resources.KubernetesDaemonSet, selector)
+		ress["fission-components-pod-spec"] = resources.NewKubernetesObjectDumper(k8sClient, resources.KubernetesPod, selector)
+		ress["fission-components-pod-log"] = resources.NewKubernetesPodLogDumper(k8sClient, selector)
+	}
+
+	if selected(SELECTOR_BUILDERS) {
+		ress["fission-builder-svc-spec"] = resources.NewKubernetesObjectDumper(k8sClient, resources.KubernetesService, "owner=buildermgr")
+		ress["fission-builder-deployment-spec"] = resources.NewKubernetesObjectDumper(k8sClient, resources.KubernetesDeployment, "owner=buildermgr")
+		ress["fission-builder-pod-spec"] = resources.NewKubernetesObjectDumper(k8sClient, resources.KubernetesPod, "owner=buildermgr")
+		ress["fission-builder-pod-log"] = resources.NewKubernetesPodLogDumper(k8sClient, "owner=buildermgr")
+	}
+
+	if selected(SELECTOR_FUNCTIONS) {
+		ress["fission-function-svc-spec"] = resources.NewKubernetesObjectDumper(k8sClient, resources.KubernetesService, "executorType=newdeploy")
+		ress["fission-function-deployment-spec"] = resources.NewKubernetesObjectDumper(k8sClient, resources.KubernetesDeployment, "executorType in (poolmgr, newdeploy)")
+		ress["fission-function-pod-spec"] = resources.NewKubernetesObjectDumper(k8sClient, resources.KubernetesPod, "executorType in (poolmgr, newdeploy)")
+		ress["fission-function-pod-log"] = resources.NewKubernetesPodLogDumper(k8sClient, "executorType in (poolmgr, newdeploy)")
+
+		ress["fission-crd-packages"] = resources.NewCrdDumper(opts.client, resources.CrdPackage)
+		ress["fission-crd-environments"] = resources.NewCrdDumper(opts.client, resources.CrdEnvironment)
+		ress["fission-crd-functions"] = resources.NewCrdDumper(opts.client, resources.CrdFunction)
+	}
+
+	if selected(SELECTOR_TRIGGERS) {
+		ress["fission-crd-httptriggers"] = resources.NewCrdDumper(opts.client, resources.CrdHttpTrigger)
+		ress["fission-crd-kubewatchers"] = resources.NewCrdDumper(opts.client, resources.CrdKubeWatcher)
+		ress["fission-crd-mqtriggers"] = resources.NewCrdDumper(opts.client, resources.CrdMessageQueueTrigger)
+		ress["fission-crd-timetriggers"] = resources.NewCrdDumper(opts.client, resources.CrdTimeTrigger)
+	}
+
+	return ress
+}
+
 type DumpSubCommand struct {
 	client *client.Client
 }
@@ -54,9 +169,13 @@ func (opts *DumpSubCommand) do(flags cli.Input) error {
 
 	nozip := flags.Bool("nozip")
 	outputDir := flags.String("output")
+	selectors, err := parseSelectors(flags.StringSlice("only"))
+	if err != nil {
+		return err
+	}
 
 	// check whether the dump directory exists.
-	_, err := os.Stat(outputDir)
+	_, err = os.Stat(outputDir)
 	if err != nil && os.IsNotExist(err) {
 		err = os.Mkdir(outputDir, 0755)
 		if err != nil {
@@ -73,47 +192,7 @@ func (opts *DumpSubCommand) do(flags cli.Input) error {
 
 	_, k8sClient := util.GetKubernetesClient()
 
-	ress := map[string]resources.Resource{
-		// kubernetes info
-		"kubernetes-version": resources.NewKubernetesVersion(k8sClient),
-		"kubernetes-nodes":   resources.NewKubernetesObjectDumper(k8sClient, resources.KubernetesNode, ""),
-
-		// fission info
-		"fission-version": resources.NewFissionVersion(opts.client),
-
-		// fission component logs & spec
-		"fission-components-svc-spec": resources.NewKubernetesObjectDumper(k8sClient, resources.KubernetesService,
-			"svc in (buildermgr, controller, executor, influxdb, kubewatcher, logger, mqtrigger, nats-streaming, redis, router, storagesvc, timer)"),
-		"fission-components-deployment-spec": resources.NewKubernetesObjectDumper(k8sClient, resources.KubernetesDeployment,
-			"svc in (buildermgr, controller, executor, influxdb, kubewatcher, logger, mqtrigger, nats-streaming, redis, router, storagesvc, timer)"),
-		"fission-components-daemonset-spec": resources.NewKubernetesObjectDumper(k8sClient, resources.KubernetesDaemonSet,
-			"svc in (buildermgr, controller, executor, influxdb, kubewatcher, logger, mqtrigger, nats-streaming, redis, router, storagesvc, timer)"),
-		"fission-components-pod-spec": resources.NewKubernetesObjectDumper(k8sClient, resources.KubernetesPod,
-			"svc in (buildermgr, controller, executor, influxdb, kubewatcher, logger, mqtrigger, nats-streaming, redis, router, storagesvc, timer)"),
-		"fission-components-pod-log": resources.NewKubernetesPodLogDumper(k8sClient,
-			"svc in (buildermgr, controller, executor, influxdb, kubewatcher, logger, mqtrigger, nats-streaming, redis, router, storagesvc, timer)"),
-
-		// fission builder logs & spec
-		"fission-builder-svc-spec":        resources.NewKubernetesObjectDumper(k8sClient, resources.KubernetesService, "owner=buildermgr"),
-		"fission-builder-deployment-spec": resources.NewKubernetesObjectDumper(k8sClient, resources.KubernetesDeployment, "owner=buildermgr"),
-		"fission-builder-pod-spec":        resources.NewKubernetesObjectDumper(k8sClient, resources.KubernetesPod, "owner=buildermgr"),
-		"fission-builder-pod-log":         resources.NewKubernetesPodLogDumper(k8sClient, "owner=buildermgr"),
-
-		// fission function logs & spec
-		"fission-function-svc-spec":        resources.NewKubernetesObjectDumper(k8sClient, resources.KubernetesService, "executorType=newdeploy"),
-		"fission-function-deployment-spec": resources.NewKubernetesObjectDumper(k8sClient, resources.KubernetesDeployment, "executorType in (poolmgr, newdeploy)"),
-		"fission-function-pod-spec":        resources.NewKubernetesObjectDumper(k8sClient, resources.KubernetesPod, "executorType in (poolmgr, newdeploy)"),
-		"fission-function-pod-log":         resources.NewKubernetesPodLogDumper(k8sClient, "executorType in (poolmgr, newdeploy)"),
-
-		// CRD resources
-		"fission-crd-packages":     resources.NewCrdDumper(opts.client, resources.CrdPackage),
-		"fission-crd-environments": resources.NewCrdDumper(opts.client, resources.CrdEnvironment),
-		"fission-crd-functions":    resources.NewCrdDumper(opts.client, resources.CrdFunction),
-		"fission-crd-httptriggers": resources.NewCrdDumper(opts.client, resources.CrdHttpTrigger),
-		"fission-crd-kubewatchers": resources.NewCrdDumper(opts.client, resources.CrdKubeWatcher),
-		"fission-crd-mqtriggers":   resources.NewCrdDumper(opts.client, resources.CrdMessageQueueTrigger),
-		"fission-crd-timetriggers": resources.NewCrdDumper(opts.client, resources.CrdTimeTrigger),
-	}
+	ress := opts.dumpResources(k8sClient, selectors)
 
 	dumpName := fmt.Sprintf("%v_%v", DUMP_ARCHIVE_PREFIX, time.Now().Unix())
 	dumpDir := filepath.Join(outputDir, dumpName)
@@ -0,0 +1,98 @@
+/*
+Copyright 2019 The Fission Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package resources
+
+import (
+	"encoding/json"
+	"regexp"
+	"strings"
+)
+
+// REDACTED replaces the sensitive values in the dump, so that it can be
+// shared outside the cluster.
+const REDACTED = "<redacted>"
+
+var (
+	// sensitiveName matches the names of environment variables, fields
+	// and parameters holding credentials.
+	sensitiveName = regexp.MustCompile(`(?i)(password|passwd|secret|token|api_?key|access_?key|private_?key|credential)`)
+
+	// urlUserinfo matches the user and password of URLs, e.g. the token
+	// of nats://<token>@nats-streaming:4222.
+	urlUserinfo = regexp.MustCompile(`([a-zA-Z][a-zA-Z0-9+.-]*://)[^/@\s]+@`)
+
+	// sensitiveText matches credentials in logs, e.g. token=<value>,
+	// "password": "<value>" or Authorization: Bearer <value>.
+	sensitiveText = regexp.MustCompile(`(?i)((?:password|passwd|secret|token|api_?key|access_?key|authorization)["']?\s*[:=]\s*["']?)(?:(?:bearer|basic)\s+)?[^\s"',;&]+`)
+)
+
+// redactText removes the credentials from text like logs.
+func redactText(text string) string {
+	text = urlUserinfo.ReplaceAllString(text, "${1}"+REDACTED+"@")
+	return sensitiveText.ReplaceAllString(text, "${1}"+REDACTED)
+}
+
+// redactObject returns the object as decoded from JSON with the values of
+// sensitive environment variables and fields, the archive literals and the
+// credentials in URLs replaced with REDACTED.
+func redactObject(obj interface{}) (interface{}, error) {
+	bs, err := json.Marshal(obj)
+	if err != nil {
+		return nil, err
+	}
+	var v interface{}
+	err = json.Unmarshal(bs, &v)
+	if err != nil {
+		return nil, err
+	}
+	return redactValue(v), nil
+}
+
+func isSensitiveName(name string) bool {
+	// names of the secrets and the references to them aren't sensitive
+	return sensitiveName.MatchString(name) &&
+		!strings.HasSuffix(name, "Name") && !strings.HasSuffix(name, "Ref")
+}
+
+func redactValue(v interface{}) interface{} {
+	switch val := v.(type) {
+	case map[string]interface{}:
+		// environment variables and name/value pairs like them
+		if name, ok := val["name"].(string); ok && isSensitiveName(name) {
+			if _, ok := val["value"].(string); ok {
+				val["value"] = REDACTED
+			}
+		}
+		for k, field := range val {
+			if _, ok := field.(string); ok && (k == "literal" || isSensitiveName(k)) {
+				val[k] = REDACTED
+				continue
+			}
+			val[k] = redactValue(field)
+		}
+		return val
+	case []interface{}:
+		for i := range val {
+			val[i] = redactValue(val[i])
+		}
+		return val
+	case string:
+		return urlUserinfo.ReplaceAllString(val, "${1}"+REDACTED+"@")
+	default:
+		return v
+	}
+}
@@ -0,0 +1,56 @@
+package resources
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+)
+
+func TestRedactText(t *testing.T) {
+	for _, c := range []struct {
+		text     string
+		expected string
+	}{
+		{"connecting to nats://s3cr3t@nats-streaming:4222", "connecting to nats://<redacted>@nats-streaming:4222"},
+		{`{"password": "hunter2", "user": "admin"}`, `{"password": "<redacted>", "user": "admin"}`},
+		{"Authorization: Bearer abc.def", "Authorization: <redacted>"},
+		{"GET /v2/functions?token=abc&name=hello", "GET /v2/functions?token=<redacted>&name=hello"},
+		{"nothing to hide", "nothing to hide"},
+	} {
+		assert.Equal(t, c.expected, redactText(c.text))
+	}
+}
+
+func TestRedactObject(t *testing.T) {
+	pod := corev1.Pod{
+		Spec: corev1.PodSpec{
+			ServiceAccountName: "fission-svc",
+			Containers: []corev1.Container{{
+				Name: "mqtrigger",
+				Env: []corev1.EnvVar{
+					{Name: "MESSAGE_QUEUE_URL", Value: "nats://s3cr3t@nats-streaming:4222"},
+					{Name: "INFLUXDB_PASSWORD", Value: "hunter2"},
+					{Name: "AZURE_STORAGE_ACCOUNT_KEY", ValueFrom: &corev1.EnvVarSource{
+						SecretKeyRef: &corev1.SecretKeySelector{
+							LocalObjectReference: corev1.LocalObjectReference{Name: "azure-secret"},
+							Key:                  "key",
+						},
+					}},
+					{Name: "DEBUG_ENV", Value: "false"},
+				},
+			}},
+		},
+	}
+
+	obj, err := redactObject(pod)
+	assert.NoError(t, err)
+	spec := obj.(map[string]interface{})["spec"].(map[string]interface{})
+	assert.Equal(t, "fission-svc", spec["serviceAccountName"])
+	env := spec["containers"].([]interface{})[0].(map[string]interface{})["env"].([]interface{})
+	assert.Equal(t, "nats://<redacted>@nats-streaming:4222", env[0].(map[string]interface{})["value"])
+	assert.Equal(t, REDACTED, env[1].(map[string]interface{})["value"])
+	ref := env[2].(map[string]interface{})["valueFrom"].(map[string]interface{})["secretKeyRef"].(map[string]interface{})
+	assert.Equal(t, "azure-secret", ref["name"])
+	assert.Equal(t, "false", env[3].(map[string]interface{})["value"])
+}
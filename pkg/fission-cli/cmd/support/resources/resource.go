@@ -42,7 +42,20 @@ func getPodFileName(dumpdir string, pod metav1.ObjectMeta, containerName string)
 	return filepath.Clean(f)
 }
 
+// writeToFile writes the object to the file as YAML, with the credentials
+// redacted.
 func writeToFile(file string, obj interface{}) {
+	if text, ok := obj.(string); ok {
+		obj = redactText(text)
+	} else {
+		redacted, err := redactObject(obj)
+		if err != nil {
+			log.Info(fmt.Sprintf("Error redacting object: %v", err))
+			return
+		}
+		obj = redacted
+	}
+
 	bs, err := yaml.Marshal(obj)
 	if err != nil {
 		log.Info(fmt.Sprintf("Error encoding object: %v", err))
@@ -19,12 +19,14 @@ package cmd
 import (
 	"fmt"
 	"strconv"
+	"strings"
 
 	"github.com/hashicorp/go-multierror"
 	"github.com/pkg/errors"
 	"k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/validation"
 
 	"github.com/fission/fission/pkg/controller/client"
 	"github.com/fission/fission/pkg/fission-cli/cliwrapper/cli"
@@ -35,7 +37,33 @@ func GetServer(flags cli.Input) *client.Client {
 	return util.GetApiClient(flags.GlobalString(FISSION_SERVER))
 }
 
+// resourceFlags are the names of the flags of the requests and limits of
+// a container.
+type resourceFlags struct {
+	minCPU, maxCPU, minMemory, maxMemory string
+}
+
 func GetResourceReqs(flags cli.Input, resReqs *v1.ResourceRequirements) (*v1.ResourceRequirements, error) {
+	return getResourceReqs(flags, resReqs, resourceFlags{
+		minCPU:    RUNTIME_MINCPU,
+		maxCPU:    RUNTIME_MAXCPU,
+		minMemory: RUNTIME_MINMEMORY,
+		maxMemory: RUNTIME_MAXMEMORY,
+	})
+}
+
+// GetBuilderResourceReqs returns the resource requirements of the builder
+// container of an environment, set by the builder resource flags.
+func GetBuilderResourceReqs(flags cli.Input, resReqs *v1.ResourceRequirements) (*v1.ResourceRequirements, error) {
+	return getResourceReqs(flags, resReqs, resourceFlags{
+		minCPU:    BUILDER_MINCPU,
+		maxCPU:    BUILDER_MAXCPU,
+		minMemory: BUILDER_MINMEMORY,
+		maxMemory: BUILDER_MAXMEMORY,
+	})
+}
+
+func getResourceReqs(flags cli.Input, resReqs *v1.ResourceRequirements, names resourceFlags) (*v1.ResourceRequirements, error) {
 	r := &v1.ResourceRequirements{}
 
 	if resReqs != nil {
@@ -53,38 +81,38 @@ func GetResourceReqs(flags cli.Input, resReqs *v1.ResourceRequirements) (*v1.Res
 
 	e := &multierror.Error{}
 
-	if flags.IsSet(RUNTIME_MINCPU) {
-		mincpu := flags.Int(RUNTIME_MINCPU)
+	if flags.IsSet(names.minCPU) {
+		mincpu := flags.Int(names.minCPU)
 		cpuRequest, err := resource.ParseQuantity(strconv.Itoa(mincpu) + "m")
 		if err != nil {
-			e = multierror.Append(e, errors.Wrap(err, "Failed to parse mincpu"))
+			e = multierror.Append(e, errors.Wrapf(err, "Failed to parse %v", names.minCPU))
 		}
 		r.Requests[v1.ResourceCPU] = cpuRequest
 	}
 
-	if flags.IsSet(RUNTIME_MINMEMORY) {
-		minmem := flags.Int(RUNTIME_MINMEMORY)
+	if flags.IsSet(names.minMemory) {
+		minmem := flags.Int(names.minMemory)
 		memRequest, err := resource.ParseQuantity(strconv.Itoa(minmem) + "Mi")
 		if err != nil {
-			e = multierror.Append(e, errors.Wrap(err, "Failed to parse minmemory"))
+			e = multierror.Append(e, errors.Wrapf(err, "Failed to parse %v", names.minMemory))
 		}
 		r.Requests[v1.ResourceMemory] = memRequest
 	}
 
-	if flags.IsSet(RUNTIME_MAXCPU) {
-		maxcpu := flags.Int(RUNTIME_MAXCPU)
+	if flags.IsSet(names.maxCPU) {
+		maxcpu := flags.Int(names.maxCPU)
 		cpuLimit, err := resource.ParseQuantity(strconv.Itoa(maxcpu) + "m")
 		if err != nil {
-			e = multierror.Append(e, errors.Wrap(err, "Failed to parse maxcpu"))
+			e = multierror.Append(e, errors.Wrapf(err, "Failed to parse %v", names.maxCPU))
 		}
 		r.Limits[v1.ResourceCPU] = cpuLimit
 	}
 
-	if flags.IsSet(RUNTIME_MAXMEMORY) {
-		maxmem := flags.Int(RUNTIME_MAXMEMORY)
+	if flags.IsSet(names.maxMemory) {
+		maxmem := flags.Int(names.maxMemory)
 		memLimit, err := resource.ParseQuantity(strconv.Itoa(maxmem) + "Mi")
 		if err != nil {
-			e = multierror.Append(e, errors.Wrap(err, "Failed to parse maxmemory"))
+			e = multierror.Append(e, errors.Wrapf(err, "Failed to parse %v", names.maxMemory))
 		}
 		r.Limits[v1.ResourceMemory] = memLimit
 	}
@@ -141,3 +169,177 @@ func GetMetadata(flags cli.Input) (*metav1.ObjectMeta, error) {
 
 	return m, nil
 }
+
+// IsMetadataSet returns whether the labels or the annotations of a
+// resource are set on the command line.
+func IsMetadataSet(flags cli.Input) bool {
+	return flags.IsSet(RESOURCE_LABEL) || flags.IsSet(RESOURCE_ANNOTATION)
+}
+
+// UpdateMetadataWithCmd updates the labels and the annotations of a
+// resource with the --label and --annotation flags.
+func UpdateMetadataWithCmd(flags cli.Input, m *metav1.ObjectMeta) error {
+	e := &multierror.Error{}
+
+	labels, err := UpdateStringMap(m.Labels, flags.StringSlice(RESOURCE_LABEL), true)
+	if err != nil {
+		e = multierror.Append(e, errors.Wrap(err, "invalid label"))
+	} else {
+		m.Labels = labels
+	}
+
+	annotations, err := UpdateStringMap(m.Annotations, flags.StringSlice(RESOURCE_ANNOTATION), false)
+	if err != nil {
+		e = multierror.Append(e, errors.Wrap(err, "invalid annotation"))
+	} else {
+		m.Annotations = annotations
+	}
+
+	return e.ErrorOrNil()
+}
+
+// IsSchedulingSet returns whether the node selector or the tolerations
+// of the pods are set on the command line.
+func IsSchedulingSet(flags cli.Input) bool {
+	return flags.IsSet(RUNTIME_NODESELECTOR) || flags.IsSet(RUNTIME_TOLERATION)
+}
+
+// UpdateSchedulingWithCmd updates a node selector and tolerations with
+// the --nodeselector and --toleration flags.
+func UpdateSchedulingWithCmd(flags cli.Input, nodeSelector map[string]string, tolerations []v1.Toleration) (map[string]string, []v1.Toleration, error) {
+	e := &multierror.Error{}
+
+	nodeSelector, err := UpdateStringMap(nodeSelector, flags.StringSlice(RUNTIME_NODESELECTOR), true)
+	if err != nil {
+		e = multierror.Append(e, errors.Wrap(err, "invalid node selector"))
+	}
+
+	tolerations, err = UpdateTolerations(tolerations, flags.StringSlice(RUNTIME_TOLERATION))
+	if err != nil {
+		e = multierror.Append(e, errors.Wrap(err, "invalid toleration"))
+	}
+
+	return nodeSelector, tolerations, e.ErrorOrNil()
+}
+
+// UpdateStringMap returns a copy of m updated with values of the form
+// "key=value", which set a key, or "key-", which removes it. With
+// labelValues the values must be valid label values.
+func UpdateStringMap(m map[string]string, values []string, labelValues bool) (map[string]string, error) {
+	if len(values) == 0 {
+		return m, nil
+	}
+
+	result := make(map[string]string)
+	for k, v := range m {
+		result[k] = v
+	}
+
+	for _, value := range values {
+		if strings.HasSuffix(value, "-") && !strings.Contains(value, "=") {
+			delete(result, strings.TrimSuffix(value, "-"))
+			continue
+		}
+
+		kv := strings.SplitN(value, "=", 2)
+		if len(kv) != 2 {
+			return nil, errors.Errorf("'%v' is not of the form key=value or key-", value)
+		}
+		if errs := validation.IsQualifiedName(kv[0]); len(errs) > 0 {
+			return nil, errors.Errorf("key '%v': %v", kv[0], strings.Join(errs, "; "))
+		}
+		if labelValues {
+			if errs := validation.IsValidLabelValue(kv[1]); len(errs) > 0 {
+				return nil, errors.Errorf("value '%v': %v", kv[1], strings.Join(errs, "; "))
+			}
+		}
+		result[kv[0]] = kv[1]
+	}
+
+	if len(result) == 0 {
+		return nil, nil
+	}
+	return result, nil
+}
+
+// UpdateTolerations returns a copy of the tolerations updated with values
+// of the form "key[=value][:effect]", which tolerate the taints with the
+// key, and value if one is given, and effect, or "key-", which removes
+// the tolerations of the key. A toleration replaces the one with the same
+// key and effect.
+func UpdateTolerations(tolerations []v1.Toleration, values []string) ([]v1.Toleration, error) {
+	if len(values) == 0 {
+		return tolerations, nil
+	}
+
+	result := make([]v1.Toleration, len(tolerations))
+	copy(result, tolerations)
+
+	for _, value := range values {
+		if strings.HasSuffix(value, "-") && !strings.ContainsAny(value, "=:") {
+			key := strings.TrimSuffix(value, "-")
+			kept := result[:0]
+			for _, t := range result {
+				if t.Key != key {
+					kept = append(kept, t)
+				}
+			}
+			result = kept
+			continue
+		}
+
+		t, err := parseToleration(value)
+		if err != nil {
+			return nil, err
+		}
+
+		replaced := false
+		for i := range result {
+			if result[i].Key == t.Key && result[i].Effect == t.Effect {
+				result[i] = *t
+				replaced = true
+			}
+		}
+		if !replaced {
+			result = append(result, *t)
+		}
+	}
+
+	if len(result) == 0 {
+		return nil, nil
+	}
+	return result, nil
+}
+
+func parseToleration(value string) (*v1.Toleration, error) {
+	t := &v1.Toleration{
+		Operator: v1.TolerationOpExists,
+	}
+
+	keyValue := value
+	if i := strings.LastIndex(value, ":"); i >= 0 {
+		keyValue = value[:i]
+		t.Effect = v1.TaintEffect(value[i+1:])
+		switch t.Effect {
+		case v1.TaintEffectNoSchedule, v1.TaintEffectPreferNoSchedule, v1.TaintEffectNoExecute:
+		default:
+			return nil, errors.Errorf("'%v': effect must be one of %v, %v or %v", value,
+				v1.TaintEffectNoSchedule, v1.TaintEffectPreferNoSchedule, v1.TaintEffectNoExecute)
+		}
+	}
+
+	kv := strings.SplitN(keyValue, "=", 2)
+	t.Key = kv[0]
+	if len(kv) == 2 {
+		t.Operator = v1.TolerationOpEqual
+		t.Value = kv[1]
+	}
+
+	if len(t.Key) == 0 {
+		return nil, errors.Errorf("'%v' has no key", value)
+	}
+	if errs := validation.IsQualifiedName(t.Key); len(errs) > 0 {
+		return nil, errors.Errorf("key '%v': %v", t.Key, strings.Join(errs, "; "))
+	}
+	return t, nil
+}
@@ -0,0 +1,84 @@
+package cmd
+
+import (
+	"reflect"
+	"testing"
+
+	"k8s.io/api/core/v1"
+)
+
+func TestUpdateStringMap(t *testing.T) {
+	existing := map[string]string{"team": "a", "tier": "web"}
+
+	m, err := UpdateStringMap(existing, []string{"tier=api", "team-", "example.com/owner=me"}, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := map[string]string{"tier": "api", "example.com/owner": "me"}
+	if !reflect.DeepEqual(m, expected) {
+		t.Errorf("expected %v, got %v", expected, m)
+	}
+	if existing["team"] != "a" {
+		t.Error("the existing map was modified")
+	}
+
+	m, err = UpdateStringMap(existing, []string{"team-", "tier-"}, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if m != nil {
+		t.Errorf("expected no entries left, got %v", m)
+	}
+
+	for _, value := range []string{"team", "=a", "bad key=a"} {
+		_, err = UpdateStringMap(nil, []string{value}, true)
+		if err == nil {
+			t.Errorf("expected an error for '%v'", value)
+		}
+	}
+
+	// annotation values aren't restricted like label values
+	_, err = UpdateStringMap(nil, []string{"note=a value with spaces"}, true)
+	if err == nil {
+		t.Error("expected an error for an invalid label value")
+	}
+	_, err = UpdateStringMap(nil, []string{"note=a value with spaces"}, false)
+	if err != nil {
+		t.Errorf("unexpected error for an annotation value: %v", err)
+	}
+}
+
+func TestUpdateTolerations(t *testing.T) {
+	tolerations, err := UpdateTolerations(nil, []string{"dedicated=fission:NoSchedule", "gpu", "spot:NoExecute"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := []v1.Toleration{
+		{Key: "dedicated", Operator: v1.TolerationOpEqual, Value: "fission", Effect: v1.TaintEffectNoSchedule},
+		{Key: "gpu", Operator: v1.TolerationOpExists},
+		{Key: "spot", Operator: v1.TolerationOpExists, Effect: v1.TaintEffectNoExecute},
+	}
+	if !reflect.DeepEqual(tolerations, expected) {
+		t.Errorf("expected %v, got %v", expected, tolerations)
+	}
+
+	// same key and effect replaces, a key with a trailing dash removes
+	tolerations, err = UpdateTolerations(tolerations, []string{"dedicated=functions:NoSchedule", "gpu-"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected = []v1.Toleration{
+		{Key: "dedicated", Operator: v1.TolerationOpEqual, Value: "functions", Effect: v1.TaintEffectNoSchedule},
+		{Key: "spot", Operator: v1.TolerationOpExists, Effect: v1.TaintEffectNoExecute},
+	}
+	if !reflect.DeepEqual(tolerations, expected) {
+		t.Errorf("expected %v, got %v", expected, tolerations)
+	}
+
+	for _, value := range []string{"gpu:Never", ":NoSchedule", "=a"} {
+		_, err = UpdateTolerations(nil, []string{value})
+		if err == nil {
+			t.Errorf("expected an error for '%v'", value)
+		}
+	}
+}
@@ -0,0 +1,89 @@
+/*
+Copyright 2019 The Fission Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package fission_cli
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/pkg/errors"
+
+	"github.com/fission/fission/pkg/controller/client"
+	"github.com/fission/fission/pkg/types"
+)
+
+// mqChartValues are the chart values enabling the trigger of each message
+// queue type.
+var mqChartValues = map[string]string{
+	types.MessageQueueTypeNats:            "nats",
+	types.MessageQueueTypeASQ:             "azureStorageQueue",
+	types.MessageQueueTypeKafka:           "kafka",
+	types.MessageQueueTypeRabbitMQ:        "rabbitmq",
+	types.MessageQueueTypeGCPPubSub:       "gcpPubSub",
+	types.MessageQueueTypeAzureServiceBus: "azureServiceBus",
+	types.MessageQueueTypeNatsJetStream:   "natsJetStream",
+}
+
+// getServerFeatures returns the features advertised by the controller, or
+// nil if they can't be read, e.g. from controllers older than the features
+// endpoint. Nothing is checked against nil features, the server decides.
+func getServerFeatures(client *client.Client) *types.FeatureInfo {
+	features, err := client.FeatureGet()
+	if err != nil {
+		return nil
+	}
+	return features
+}
+
+// checkMessageQueueType returns an error if no trigger of the message
+// queue type is deployed.
+func checkMessageQueueType(features *types.FeatureInfo, mqType string) error {
+	if features == nil || features.MessageQueueTypes == nil {
+		return nil
+	}
+	for _, t := range features.MessageQueueTypes {
+		if t == mqType {
+			return nil
+		}
+	}
+	deployed := "none"
+	if len(features.MessageQueueTypes) > 0 {
+		deployed = strings.Join(features.MessageQueueTypes, ", ")
+	}
+	msg := fmt.Sprintf("message queue type %q is not deployed on the server (deployed: %v)", mqType, deployed)
+	if value, ok := mqChartValues[mqType]; ok {
+		msg = fmt.Sprintf("%v, enable it with the Helm value %v.enabled=true", msg, value)
+	}
+	return errors.New(msg)
+}
+
+// checkCanaryEnabled returns an error if the canary feature is not enabled
+// on the server.
+func checkCanaryEnabled(features *types.FeatureInfo) error {
+	if features == nil || features.Canary {
+		return nil
+	}
+	return fmt.Errorf("canary deployments are not enabled on the server, enable them with the Helm value canaryDeployment.enabled=true")
+}
+
+// checkRecorderEnabled returns an error if the recorder is not installed.
+func checkRecorderEnabled(features *types.FeatureInfo) error {
+	if features == nil || features.Recorder {
+		return nil
+	}
+	return fmt.Errorf("the recorder is not installed on the server, it comes with the fission-all chart")
+}
@@ -0,0 +1,40 @@
+package fission_cli
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/fission/fission/pkg/types"
+)
+
+func TestCheckFeatures(t *testing.T) {
+	// nothing is known of controllers without the features endpoint
+	assert.NoError(t, checkMessageQueueType(nil, types.MessageQueueTypeKafka))
+	assert.NoError(t, checkCanaryEnabled(nil))
+	assert.NoError(t, checkRecorderEnabled(nil))
+
+	features := &types.FeatureInfo{}
+	assert.NoError(t, checkMessageQueueType(features, types.MessageQueueTypeKafka))
+
+	features.MessageQueueTypes = []string{types.MessageQueueTypeNats}
+	assert.NoError(t, checkMessageQueueType(features, types.MessageQueueTypeNats))
+	err := checkMessageQueueType(features, types.MessageQueueTypeKafka)
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), "kafka.enabled=true")
+		assert.Contains(t, err.Error(), "deployed: nats-streaming")
+	}
+
+	features.MessageQueueTypes = []string{}
+	err = checkMessageQueueType(features, types.MessageQueueTypeAzureServiceBus)
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), "deployed: none")
+	}
+
+	assert.Error(t, checkCanaryEnabled(features))
+	assert.Error(t, checkRecorderEnabled(features))
+	features.Canary = true
+	features.Recorder = true
+	assert.NoError(t, checkCanaryEnabled(features))
+	assert.NoError(t, checkRecorderEnabled(features))
+}
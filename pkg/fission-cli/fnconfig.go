@@ -0,0 +1,174 @@
+/*
+Copyright 2019 The Fission Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package fission_cli
+
+import (
+	"fmt"
+
+	"github.com/urfave/cli"
+	apiv1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	fv1 "github.com/fission/fission/pkg/apis/fission.io/v1"
+	"github.com/fission/fission/pkg/controller/client"
+	"github.com/fission/fission/pkg/fission-cli/log"
+	"github.com/fission/fission/pkg/fission-cli/util"
+	"github.com/fission/fission/pkg/types"
+)
+
+// fnConfigCopy copies the secret and configmap references, environment
+// variables and resource settings of one function to another.
+func fnConfigCopy(c *cli.Context) error {
+	client := util.GetApiClient(c.GlobalString("server"))
+
+	fromName := c.String("from")
+	toName := c.String("to")
+	if len(fromName) == 0 || len(toName) == 0 {
+		log.Fatal("Need --from and --to arguments.")
+	}
+	if fromName == toName {
+		log.Fatal("--from and --to must be different functions.")
+	}
+	fnNamespace := c.String("fnNamespace")
+
+	from, err := client.FunctionGet(&metav1.ObjectMeta{
+		Name:      fromName,
+		Namespace: fnNamespace,
+	})
+	util.CheckErr(err, fmt.Sprintf("read function '%v'", fromName))
+	to, err := client.FunctionGet(&metav1.ObjectMeta{
+		Name:      toName,
+		Namespace: fnNamespace,
+	})
+	util.CheckErr(err, fmt.Sprintf("read function '%v'", toName))
+
+	if !copyFunctionConfig(from, to) {
+		log.Warn(fmt.Sprintf("Environment variables are only copied between container functions, the ones of '%v' are not copied", fromName))
+	}
+	to.Spec.Resources = *from.Spec.Resources.DeepCopy()
+
+	_, err = client.FunctionUpdate(to)
+	util.CheckErr(err, "update function")
+
+	fmt.Printf("configuration of function '%v' copied to '%v'\n", fromName, toName)
+	return nil
+}
+
+// copyFunctionConfig adds the secret and configmap references of from to
+// the ones of to, and copies the environment variables when both functions
+// run a container image. References are made in the namespace of to, and
+// environment variables replace those of the same name. It returns false
+// if from has environment variables that could not be copied.
+func copyFunctionConfig(from, to *fv1.Function) bool {
+	for _, s := range from.Spec.Secrets {
+		ref := fv1.SecretReference{Name: s.Name, Namespace: to.Metadata.Namespace}
+		if !hasSecretReference(to.Spec.Secrets, ref) {
+			to.Spec.Secrets = append(to.Spec.Secrets, ref)
+		}
+	}
+	for _, cm := range from.Spec.ConfigMaps {
+		ref := fv1.ConfigMapReference{Name: cm.Name, Namespace: to.Metadata.Namespace}
+		if !hasConfigMapReference(to.Spec.ConfigMaps, ref) {
+			to.Spec.ConfigMaps = append(to.Spec.ConfigMaps, ref)
+		}
+	}
+
+	src := functionContainer(from)
+	if src == nil || (len(src.Env) == 0 && len(src.EnvFrom) == 0) {
+		return true
+	}
+	dst := functionContainer(to)
+	if dst == nil {
+		return false
+	}
+	for _, env := range src.Env {
+		replaced := false
+		for i := range dst.Env {
+			if dst.Env[i].Name == env.Name {
+				dst.Env[i] = *env.DeepCopy()
+				replaced = true
+			}
+		}
+		if !replaced {
+			dst.Env = append(dst.Env, *env.DeepCopy())
+		}
+	}
+	for _, envFrom := range src.EnvFrom {
+		dst.EnvFrom = append(dst.EnvFrom, *envFrom.DeepCopy())
+	}
+	return true
+}
+
+// functionContainer returns the container running the image of a container
+// function, or nil for functions of the other executor types.
+func functionContainer(fn *fv1.Function) *apiv1.Container {
+	if fn.Spec.InvokeStrategy.ExecutionStrategy.ExecutorType != types.ExecutorTypeContainer ||
+		fn.Spec.PodSpec == nil || len(fn.Spec.PodSpec.Containers) == 0 {
+		return nil
+	}
+	for i := range fn.Spec.PodSpec.Containers {
+		if fn.Spec.PodSpec.Containers[i].Name == fn.Metadata.Name {
+			return &fn.Spec.PodSpec.Containers[i]
+		}
+	}
+	return &fn.Spec.PodSpec.Containers[0]
+}
+
+func hasSecretReference(refs []fv1.SecretReference, ref fv1.SecretReference) bool {
+	for _, r := range refs {
+		if r == ref {
+			return true
+		}
+	}
+	return false
+}
+
+func hasConfigMapReference(refs []fv1.ConfigMapReference, ref fv1.ConfigMapReference) bool {
+	for _, r := range refs {
+		if r == ref {
+			return true
+		}
+	}
+	return false
+}
+
+// getInheritedFunction returns the function named by --inherit-from, read
+// from the spec directory when writing specs.
+func getInheritedFunction(c *cli.Context, fclient *client.Client, specDir string, toSpec bool) *fv1.Function {
+	name := c.String("inherit-from")
+	if len(name) == 0 {
+		return nil
+	}
+	fnNamespace := c.String("fnNamespace")
+	if toSpec {
+		fr, err := readSpecs(specDir)
+		util.CheckErr(err, "read specs")
+		for i := range fr.Functions {
+			fn := &fr.Functions[i]
+			if fn.Metadata.Name == name && fn.Metadata.Namespace == fnNamespace {
+				return fn
+			}
+		}
+		log.Fatal(fmt.Sprintf("Function '%v' to inherit from is not in the spec directory", name))
+	}
+	fn, err := fclient.FunctionGet(&metav1.ObjectMeta{
+		Name:      name,
+		Namespace: fnNamespace,
+	})
+	util.CheckErr(err, fmt.Sprintf("read function '%v' to inherit from", name))
+	return fn
+}
@@ -0,0 +1,54 @@
+package fission_cli
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	apiv1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	fv1 "github.com/fission/fission/pkg/apis/fission.io/v1"
+	"github.com/fission/fission/pkg/types"
+)
+
+func containerFunction(name string, env ...apiv1.EnvVar) *fv1.Function {
+	fn := &fv1.Function{
+		Metadata: metav1.ObjectMeta{Name: name, Namespace: "prod"},
+		Spec: fv1.FunctionSpec{
+			PodSpec: &apiv1.PodSpec{
+				Containers: []apiv1.Container{{Name: name, Image: "example/" + name, Env: env}},
+			},
+		},
+	}
+	fn.Spec.InvokeStrategy.ExecutionStrategy.ExecutorType = types.ExecutorTypeContainer
+	return fn
+}
+
+func TestCopyFunctionConfig(t *testing.T) {
+	from := containerFunction("a",
+		apiv1.EnvVar{Name: "LEVEL", Value: "debug"},
+		apiv1.EnvVar{Name: "REGION", Value: "eu"})
+	from.Metadata.Namespace = "staging"
+	from.Spec.Secrets = []fv1.SecretReference{{Name: "db", Namespace: "staging"}, {Name: "api", Namespace: "staging"}}
+	from.Spec.ConfigMaps = []fv1.ConfigMapReference{{Name: "settings", Namespace: "staging"}}
+
+	to := containerFunction("b", apiv1.EnvVar{Name: "LEVEL", Value: "info"})
+	to.Spec.Secrets = []fv1.SecretReference{{Name: "db", Namespace: "prod"}}
+
+	assert.True(t, copyFunctionConfig(from, to))
+	assert.Equal(t, []fv1.SecretReference{{Name: "db", Namespace: "prod"}, {Name: "api", Namespace: "prod"}}, to.Spec.Secrets)
+	assert.Equal(t, []fv1.ConfigMapReference{{Name: "settings", Namespace: "prod"}}, to.Spec.ConfigMaps)
+	assert.Equal(t, []apiv1.EnvVar{{Name: "LEVEL", Value: "debug"}, {Name: "REGION", Value: "eu"}}, to.Spec.PodSpec.Containers[0].Env)
+
+	// the source is left untouched
+	assert.Equal(t, "staging", from.Spec.Secrets[0].Namespace)
+
+	// environment variables can't be copied to functions of other executors
+	poolmgr := &fv1.Function{Metadata: metav1.ObjectMeta{Name: "c", Namespace: "prod"}}
+	poolmgr.Spec.InvokeStrategy.ExecutionStrategy.ExecutorType = types.ExecutorTypePoolmgr
+	assert.False(t, copyFunctionConfig(from, poolmgr))
+	assert.Len(t, poolmgr.Spec.Secrets, 2)
+
+	// nor do they come from them
+	assert.True(t, copyFunctionConfig(poolmgr, containerFunction("d")))
+}
@@ -0,0 +1,349 @@
+/*
+Copyright 2019 The Fission Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package fission_cli
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/urfave/cli"
+	apiv1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/client-go/kubernetes"
+
+	fv1 "github.com/fission/fission/pkg/apis/fission.io/v1"
+	"github.com/fission/fission/pkg/controller/client"
+	"github.com/fission/fission/pkg/fission-cli/log"
+	"github.com/fission/fission/pkg/fission-cli/util"
+)
+
+const (
+	// number of warning events shown for each pod
+	describeMaxPodEvents = 5
+)
+
+// fnDescribe prints the function spec, package build status, triggers
+// and the runtime status of the function on the cluster in one view.
+func fnDescribe(c *cli.Context) error {
+	client := util.GetApiClient(c.GlobalString("server"))
+
+	fnName := c.String("name")
+	if len(fnName) == 0 {
+		log.Fatal("Need name of function, use --name")
+	}
+	fnNamespace := c.String("fnNamespace")
+
+	fn, err := client.FunctionGet(&metav1.ObjectMeta{
+		Name:      fnName,
+		Namespace: fnNamespace,
+	})
+	util.CheckErr(err, fmt.Sprintf("get function %v", fnName))
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 1, ' ', 0)
+	describeFunctionSpec(w, fn)
+	describeFunctionPackage(w, client, fn)
+	describeFunctionTriggers(w, client, fn)
+
+	// The runtime status comes from the cluster directly, it's optional
+	// since users may not have access to the function namespace.
+	_, kubeClient := util.GetKubernetesClient()
+	err = describeFunctionRuntime(w, kubeClient, fn)
+	if err != nil {
+		fmt.Fprintf(w, "\t%v\n", fmt.Sprintf("Unavailable: %v", err))
+	}
+	w.Flush()
+
+	return nil
+}
+
+func describeFunctionSpec(w io.Writer, fn *fv1.Function) {
+	es := fn.Spec.InvokeStrategy.ExecutionStrategy
+
+	fmt.Fprintf(w, "%v\t%v\n", "Name:", fn.Metadata.Name)
+	fmt.Fprintf(w, "%v\t%v\n", "Namespace:", fn.Metadata.Namespace)
+	fmt.Fprintf(w, "%v\t%v\n", "UID:", fn.Metadata.UID)
+	fmt.Fprintf(w, "%v\t%v\n", "Created:", fn.Metadata.CreationTimestamp.Format(time.RFC3339))
+	if es.ExecutorType == fv1.ExecutorTypeContainer && fn.Spec.PodSpec != nil && len(fn.Spec.PodSpec.Containers) > 0 {
+		fmt.Fprintf(w, "%v\t%v\n", "Image:", fn.Spec.PodSpec.Containers[0].Image)
+	} else {
+		fmt.Fprintf(w, "%v\t%v\n", "Environment:", fn.Spec.Environment.Name)
+	}
+	if fn.Spec.PrebuiltImage != nil {
+		fmt.Fprintf(w, "%v\t%v\n", "Prebuilt Image:", fn.Spec.PrebuiltImage.Image)
+		if len(fn.Spec.PrebuiltImage.Path) > 0 {
+			fmt.Fprintf(w, "%v\t%v\n", "Code Path:", fn.Spec.PrebuiltImage.Path)
+		}
+	}
+	fmt.Fprintf(w, "%v\t%v\n", "Executor:", es.ExecutorType)
+	if es.ExecutorType == fv1.ExecutorTypeNewdeploy || es.ExecutorType == fv1.ExecutorTypeContainer {
+		fmt.Fprintf(w, "%v\t%v\n", "Scale:", fmt.Sprintf("min %v, max %v, target CPU %v%%", es.MinScale, es.MaxScale, es.TargetCPUPercent))
+	}
+	if es.ExecutorType == fv1.ExecutorTypePoolmgr {
+		fmt.Fprintf(w, "%v\t%v\n", "Multiplexed:", es.Multiplex)
+		if es.Isolation == fv1.IsolationModeInvocation {
+			fmt.Fprintf(w, "%v\t%v\n", "Isolation:", fmt.Sprintf("%v, %v prespecialized pods", es.Isolation, es.PrespecializedPods))
+		}
+	}
+	fmt.Fprintf(w, "%v\t%v\n", "Specialization Timeout:", fmt.Sprintf("%vs", es.SpecializationTimeout))
+	fmt.Fprintf(w, "%v\t%v\n", "Function Timeout:", fmt.Sprintf("%vs", fn.Spec.FunctionTimeout))
+	if fn.Spec.Concurrency > 0 {
+		fmt.Fprintf(w, "%v\t%v\n", "Concurrency:", fmt.Sprintf("%v per router, queue length %v", fn.Spec.Concurrency, fn.Spec.RequestQueueLength))
+	}
+	fmt.Fprintf(w, "%v\t%v\n", "Resources:", fmt.Sprintf("CPU %v-%v, memory %v-%v",
+		fn.Spec.Resources.Requests.Cpu(), fn.Spec.Resources.Limits.Cpu(),
+		fn.Spec.Resources.Requests.Memory(), fn.Spec.Resources.Limits.Memory()))
+
+	var secrets, cfgmaps []string
+	for _, s := range fn.Spec.Secrets {
+		secrets = append(secrets, s.Name)
+	}
+	for _, cm := range fn.Spec.ConfigMaps {
+		cfgmaps = append(cfgmaps, cm.Name)
+	}
+	fmt.Fprintf(w, "%v\t%v\n", "Secrets:", describeList(secrets))
+	fmt.Fprintf(w, "%v\t%v\n", "ConfigMaps:", describeList(cfgmaps))
+
+	var volumes []string
+	for _, v := range fn.Spec.Volumes {
+		volume := fmt.Sprintf("%v:%v:%v", v.Type, v.Name, v.MountPath)
+		if v.ReadOnly {
+			volume += ":ro"
+		}
+		if v.SizeLimit != nil {
+			volume += fmt.Sprintf(" (%v)", v.SizeLimit)
+		}
+		volumes = append(volumes, volume)
+	}
+	if len(volumes) > 0 {
+		fmt.Fprintf(w, "%v\t%v\n", "Volumes:", describeList(volumes))
+	}
+}
+
+func describeFunctionPackage(w io.Writer, client *client.Client, fn *fv1.Function) {
+	pkgRef := fn.Spec.Package.PackageRef
+	if len(pkgRef.Name) == 0 {
+		// container functions and functions with a prebuilt image have no package
+		return
+	}
+
+	fmt.Fprintf(w, "%v\n", "Package:")
+	fmt.Fprintf(w, "\t%v\t%v\n", "Name:", pkgRef.Name)
+	fmt.Fprintf(w, "\t%v\t%v\n", "Entrypoint:", fn.Spec.Package.FunctionName)
+
+	pkg, err := client.PackageGet(&metav1.ObjectMeta{
+		Name:      pkgRef.Name,
+		Namespace: pkgRef.Namespace,
+	})
+	if err != nil {
+		fmt.Fprintf(w, "\t%v\t%v\n", "Status:", fmt.Sprintf("Unavailable: %v", err))
+		return
+	}
+
+	fmt.Fprintf(w, "\t%v\t%v\n", "Status:", pkg.Status.BuildStatus)
+	if !pkg.Status.LastUpdateTimestamp.IsZero() {
+		fmt.Fprintf(w, "\t%v\t%v\n", "Last Update:", pkg.Status.LastUpdateTimestamp.Format(time.RFC3339))
+	}
+	if len(pkg.Status.BuildAttempts) > 1 {
+		fmt.Fprintf(w, "\t%v\t%v\n", "Build Attempts:", len(pkg.Status.BuildAttempts))
+	}
+	// the build log of a successful build is only noise here
+	if pkg.Status.BuildStatus == fv1.BuildStatusFailed && len(pkg.Status.BuildLog) > 0 {
+		fmt.Fprintf(w, "\t%v\n", "Build Log:")
+		for _, line := range strings.Split(strings.TrimSpace(pkg.Status.BuildLog), "\n") {
+			fmt.Fprintf(w, "\t  %v\n", line)
+		}
+	}
+}
+
+func describeFunctionTriggers(w io.Writer, client *client.Client, fn *fv1.Function) {
+	fmt.Fprintf(w, "%v\n", "Triggers:")
+
+	ns := fn.Metadata.Namespace
+	found := false
+
+	hts, err := client.HTTPTriggerList(ns)
+	if err != nil {
+		fmt.Fprintf(w, "\t%v\t%v\n", "HTTP:", fmt.Sprintf("Unavailable: %v", err))
+	}
+	for _, ht := range hts {
+		ref := ht.Spec.FunctionReference
+		weight, ok := ref.FunctionWeights[fn.Metadata.Name]
+		if ref.Name != fn.Metadata.Name && !ok {
+			continue
+		}
+		desc := fmt.Sprintf("%v %v", strings.Join(ht.Spec.GetMethods(), ","), triggerURL(&ht.Spec))
+		if ok {
+			desc = fmt.Sprintf("%v (weight %v%%)", desc, weight)
+		}
+		if ht.Spec.CreateIngress {
+			desc = fmt.Sprintf("%v, ingress %v%v", desc, ht.Spec.IngressConfig.Host, ht.Spec.IngressConfig.Path)
+		}
+		fmt.Fprintf(w, "\t%v\t%v\t%v\n", "HTTP:", ht.Metadata.Name, desc)
+		found = true
+	}
+
+	tts, err := client.TimeTriggerList(ns)
+	if err != nil {
+		fmt.Fprintf(w, "\t%v\t%v\n", "Time:", fmt.Sprintf("Unavailable: %v", err))
+	}
+	for _, tt := range tts {
+		if tt.Spec.FunctionReference.Name != fn.Metadata.Name {
+			continue
+		}
+		fmt.Fprintf(w, "\t%v\t%v\t%v\n", "Time:", tt.Metadata.Name, tt.Spec.Cron)
+		found = true
+	}
+
+	mqts, err := client.MessageQueueTriggerList("", ns)
+	if err != nil {
+		fmt.Fprintf(w, "\t%v\t%v\n", "Message Queue:", fmt.Sprintf("Unavailable: %v", err))
+	}
+	for _, mqt := range mqts {
+		if mqt.Spec.FunctionReference.Name != fn.Metadata.Name {
+			continue
+		}
+		fmt.Fprintf(w, "\t%v\t%v\t%v\n", "Message Queue:", mqt.Metadata.Name,
+			fmt.Sprintf("%v topic %v", mqt.Spec.MessageQueueType, mqt.Spec.Topic))
+		found = true
+	}
+
+	watches, err := client.WatchList(ns)
+	if err != nil {
+		fmt.Fprintf(w, "\t%v\t%v\n", "Watch:", fmt.Sprintf("Unavailable: %v", err))
+	}
+	for _, watch := range watches {
+		if watch.Spec.FunctionReference.Name != fn.Metadata.Name {
+			continue
+		}
+		fmt.Fprintf(w, "\t%v\t%v\t%v\n", "Watch:", watch.Metadata.Name,
+			fmt.Sprintf("%v in %v", watch.Spec.Type, watch.Spec.Namespace))
+		found = true
+	}
+
+	if !found {
+		fmt.Fprintf(w, "\t%v\n", "<none>")
+	}
+}
+
+// describeFunctionRuntime prints the deployments, autoscalers and pods the
+// executors created for the function, found by the function labels.
+func describeFunctionRuntime(w io.Writer, kubeClient *kubernetes.Clientset, fn *fv1.Function) error {
+	fmt.Fprintf(w, "%v\n", "Runtime:")
+
+	listOpts := metav1.ListOptions{LabelSelector: functionPodSelector(fn)}
+
+	deployments, err := kubeClient.AppsV1().Deployments(metav1.NamespaceAll).List(listOpts)
+	if err != nil {
+		return err
+	}
+	for _, d := range deployments.Items {
+		fmt.Fprintf(w, "\t%v\t%v\t%v\n", "Deployment:", d.Name,
+			fmt.Sprintf("%v/%v ready, %v updated", d.Status.ReadyReplicas, d.Status.Replicas, d.Status.UpdatedReplicas))
+	}
+
+	hpas, err := kubeClient.AutoscalingV1().HorizontalPodAutoscalers(metav1.NamespaceAll).List(listOpts)
+	if err != nil {
+		return err
+	}
+	for _, hpa := range hpas.Items {
+		cpu := "<unknown>"
+		if hpa.Status.CurrentCPUUtilizationPercentage != nil {
+			cpu = fmt.Sprintf("%v%%", *hpa.Status.CurrentCPUUtilizationPercentage)
+		}
+		minReplicas := int32(1)
+		if hpa.Spec.MinReplicas != nil {
+			minReplicas = *hpa.Spec.MinReplicas
+		}
+		fmt.Fprintf(w, "\t%v\t%v\t%v\n", "Autoscaler:", hpa.Name,
+			fmt.Sprintf("%v replicas (min %v, max %v), CPU %v", hpa.Status.CurrentReplicas, minReplicas, hpa.Spec.MaxReplicas, cpu))
+	}
+
+	pods, err := kubeClient.CoreV1().Pods(metav1.NamespaceAll).List(listOpts)
+	if err != nil {
+		return err
+	}
+	if len(deployments.Items) == 0 && len(pods.Items) == 0 {
+		fmt.Fprintf(w, "\t%v\n", "No pods running, the function is not specialized or has been scaled down")
+		return nil
+	}
+	for _, pod := range pods.Items {
+		var restarts int32
+		for _, cs := range pod.Status.ContainerStatuses {
+			restarts += cs.RestartCount
+		}
+		fmt.Fprintf(w, "\t%v\t%v\t%v\n", "Pod:", pod.Name,
+			fmt.Sprintf("%v, %v restarts, node %v", pod.Status.Phase, restarts, pod.Spec.NodeName))
+		for _, e := range describePodErrors(kubeClient, &pod) {
+			fmt.Fprintf(w, "\t\t%v\n", e)
+		}
+	}
+
+	return nil
+}
+
+// describePodErrors returns the container failures and the recent warning
+// events of the pod.
+func describePodErrors(kubeClient *kubernetes.Clientset, pod *apiv1.Pod) []string {
+	var errs []string
+	for _, cs := range pod.Status.ContainerStatuses {
+		if cs.State.Waiting != nil && len(cs.State.Waiting.Reason) > 0 {
+			errs = append(errs, fmt.Sprintf("container %v waiting: %v %v", cs.Name, cs.State.Waiting.Reason, cs.State.Waiting.Message))
+		}
+		if t := cs.LastTerminationState.Terminated; t != nil && t.ExitCode != 0 {
+			errs = append(errs, fmt.Sprintf("container %v last terminated at %v: %v, exit code %v",
+				cs.Name, t.FinishedAt.Format(time.RFC3339), t.Reason, t.ExitCode))
+		}
+	}
+
+	events, err := kubeClient.CoreV1().Events(pod.Namespace).List(metav1.ListOptions{
+		FieldSelector: fields.OneTermEqualSelector("involvedObject.name", pod.Name).String(),
+	})
+	if err != nil {
+		return append(errs, fmt.Sprintf("error listing events: %v", err))
+	}
+
+	var warnings []apiv1.Event
+	for _, e := range events.Items {
+		if e.Type == apiv1.EventTypeWarning {
+			warnings = append(warnings, e)
+		}
+	}
+	sort.Slice(warnings, func(i, j int) bool {
+		return warnings[i].LastTimestamp.Before(&warnings[j].LastTimestamp)
+	})
+	if len(warnings) > describeMaxPodEvents {
+		warnings = warnings[len(warnings)-describeMaxPodEvents:]
+	}
+	for _, e := range warnings {
+		errs = append(errs, fmt.Sprintf("%v %v: %v", e.LastTimestamp.Format(time.RFC3339), e.Reason, strings.TrimSpace(e.Message)))
+	}
+
+	return errs
+}
+
+func describeList(items []string) string {
+	if len(items) == 0 {
+		return "<none>"
+	}
+	return strings.Join(items, ", ")
+}
@@ -0,0 +1,197 @@
+/*
+Copyright 2019 The Fission Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package fission_cli
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"strings"
+
+	"github.com/ghodss/yaml"
+	"github.com/pkg/errors"
+	"github.com/urfave/cli"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	fv1 "github.com/fission/fission/pkg/apis/fission.io/v1"
+	"github.com/fission/fission/pkg/controller/client"
+	"github.com/fission/fission/pkg/fission-cli/log"
+	"github.com/fission/fission/pkg/fission-cli/util"
+)
+
+const fnEditHeader = `# Please edit the function below. Lines beginning with a '#' will be ignored,
+# and an empty file will abort the edit. If an error occurs while saving this
+# file will be reopened with the relevant failures.
+#
+`
+
+// fnEdit opens the function in an editor as YAML, like kubectl edit, and
+// updates it once the edited function passes validation. Validation errors
+// are shown at the top of the file, which is reopened until it's valid or
+// left unchanged.
+func fnEdit(c *cli.Context) error {
+	client := util.GetApiClient(c.GlobalString("server"))
+
+	fnName := c.String("name")
+	if len(fnName) == 0 {
+		log.Fatal("Need name of function, use --name")
+	}
+	fnNamespace := c.String("fnNamespace")
+
+	fn, err := client.FunctionGet(&metav1.ObjectMeta{
+		Name:      fnName,
+		Namespace: fnNamespace,
+	})
+	util.CheckErr(err, fmt.Sprintf("read function '%v'", fnName))
+
+	fn.TypeMeta.APIVersion = fv1.CRD_VERSION
+	fn.TypeMeta.Kind = "Function"
+	original, err := yaml.Marshal(fn)
+	util.CheckErr(err, "marshal function")
+
+	f, err := ioutil.TempFile("", fmt.Sprintf("fission-fn-%v-*.yaml", fnName))
+	util.CheckErr(err, "create temporary file")
+	f.Close()
+	defer os.Remove(f.Name())
+
+	content := original
+	header := fnEditHeader
+	for {
+		err = ioutil.WriteFile(f.Name(), append([]byte(header), content...), 0600)
+		util.CheckErr(err, "write temporary file")
+
+		err = runEditor(f.Name())
+		util.CheckErr(err, "run editor")
+
+		edited, err := ioutil.ReadFile(f.Name())
+		util.CheckErr(err, "read edited function")
+		edited = stripComments(edited)
+
+		if len(bytes.TrimSpace(edited)) == 0 {
+			fmt.Println("Edit cancelled, empty file.")
+			return nil
+		}
+		if bytes.Equal(bytes.TrimSpace(edited), bytes.TrimSpace(original)) {
+			fmt.Println("Edit cancelled, no changes made.")
+			return nil
+		}
+		if header != fnEditHeader && bytes.Equal(edited, content) {
+			// the user gave up fixing the errors, the file is kept as
+			// log.Fatal doesn't run the deferred removal
+			log.Fatal(fmt.Sprintf("Edit cancelled, the function was not updated. Your changes have been kept in %v", f.Name()))
+		}
+		content = edited
+
+		newFn, err := validateEditedFunction(client, fn, edited)
+		if err != nil {
+			header = fnEditHeader + fmt.Sprintf("# function %q was not valid:\n", fnName)
+			for _, line := range strings.Split(err.Error(), "\n") {
+				header += "# " + line + "\n"
+			}
+			header += "#\n"
+			continue
+		}
+
+		_, err = client.FunctionUpdate(newFn)
+		util.CheckErr(err, "update function")
+		fmt.Printf("function '%v' updated\n", fnName)
+		return nil
+	}
+}
+
+// validateEditedFunction parses the edited function and checks it the same
+// way the CLI checks new functions, including the package and environment
+// references.
+func validateEditedFunction(client *client.Client, fn *fv1.Function, edited []byte) (*fv1.Function, error) {
+	var newFn fv1.Function
+	err := yaml.Unmarshal(edited, &newFn)
+	if err != nil {
+		return nil, errors.Wrap(err, "error parsing function")
+	}
+
+	if newFn.Metadata.Name != fn.Metadata.Name || newFn.Metadata.Namespace != fn.Metadata.Namespace {
+		return nil, errors.New("the name and namespace of the function can't be changed")
+	}
+
+	err = newFn.Validate()
+	if err != nil {
+		return nil, err
+	}
+
+	// functions with a prebuilt image have no package
+	if newFn.Spec.PrebuiltImage == nil {
+		pkgRef := newFn.Spec.Package.PackageRef
+		if pkgRef.Namespace != newFn.Metadata.Namespace {
+			return nil, errors.Errorf("package %v/%v needs to be in the same namespace as the function", pkgRef.Namespace, pkgRef.Name)
+		}
+		pkg, err := client.PackageGet(&metav1.ObjectMeta{
+			Name:      pkgRef.Name,
+			Namespace: pkgRef.Namespace,
+		})
+		if err != nil {
+			return nil, errors.Wrapf(err, "error getting package %v/%v", pkgRef.Namespace, pkgRef.Name)
+		}
+		if pkg.Spec.Environment.Name != newFn.Spec.Environment.Name ||
+			pkg.Spec.Environment.Namespace != newFn.Spec.Environment.Namespace {
+			return nil, errors.Errorf("environment %v/%v of the function is different from environment %v/%v of package %v",
+				newFn.Spec.Environment.Namespace, newFn.Spec.Environment.Name,
+				pkg.Spec.Environment.Namespace, pkg.Spec.Environment.Name, pkgRef.Name)
+		}
+		// the package may have been rebuilt since the function was fetched
+		if pkgRef.Name != fn.Spec.Package.PackageRef.Name || len(pkgRef.ResourceVersion) == 0 {
+			newFn.Spec.Package.PackageRef.ResourceVersion = pkg.Metadata.ResourceVersion
+		}
+	}
+
+	_, err = client.EnvironmentGet(&metav1.ObjectMeta{
+		Name:      newFn.Spec.Environment.Name,
+		Namespace: newFn.Spec.Environment.Namespace,
+	})
+	if err != nil {
+		return nil, errors.Wrapf(err, "error getting environment %v/%v",
+			newFn.Spec.Environment.Namespace, newFn.Spec.Environment.Name)
+	}
+
+	return &newFn, nil
+}
+
+// runEditor opens the file in the editor of $EDITOR, vi by default.
+func runEditor(path string) error {
+	editor := strings.Fields(os.Getenv("EDITOR"))
+	if len(editor) == 0 {
+		editor = []string{"vi"}
+	}
+	cmd := exec.Command(editor[0], append(editor[1:], path)...)
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	return cmd.Run()
+}
+
+// stripComments removes the comment lines added to the edited file.
+func stripComments(b []byte) []byte {
+	var out bytes.Buffer
+	for _, line := range bytes.SplitAfter(b, []byte("\n")) {
+		if bytes.HasPrefix(bytes.TrimSpace(line), []byte("#")) {
+			continue
+		}
+		out.Write(line)
+	}
+	return out.Bytes()
+}
@@ -0,0 +1,134 @@
+/*
+Copyright 2019 The Fission Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package fission_cli
+
+import (
+	"fmt"
+	"math"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	"github.com/urfave/cli"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/fission/fission/pkg/fission-cli/log"
+	"github.com/fission/fission/pkg/fission-cli/util"
+)
+
+var sparklineTicks = []rune("▁▂▃▄▅▆▇█")
+
+func fnMetrics(c *cli.Context) error {
+	client := util.GetApiClient(c.GlobalString("server"))
+
+	fnName := c.String("name")
+	if len(fnName) == 0 {
+		log.Fatal("Need name of function, use --name")
+	}
+	fnNamespace := c.String("fnNamespace")
+
+	since := c.Duration("since")
+	m, err := client.FunctionMetrics(&metav1.ObjectMeta{
+		Name:      fnName,
+		Namespace: fnNamespace,
+	}, since)
+	util.CheckErr(err, fmt.Sprintf("get metrics of function %v", fnName))
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(w, "%v\t%v/%v, last %v\n", "FUNCTION", fnNamespace, fnName, since)
+	fmt.Fprintf(w, "%v\t%v\t%v\n", "INVOCATIONS", math.Round(m.Invocations), sparkline(m.InvocationSeries))
+	fmt.Fprintf(w, "%v\t%v (%v errors)\n", "ERROR RATE", formatRatio(m.Errors, m.Invocations), math.Round(m.Errors))
+	fmt.Fprintf(w, "%v\tp50 %v  p90 %v  p95 %v  p99 %v\n", "LATENCY",
+		formatLatency(m.LatencyP50), formatLatency(m.LatencyP90), formatLatency(m.LatencyP95), formatLatency(m.LatencyP99))
+	fmt.Fprintf(w, "%v\t%v (%v cold starts)\n", "COLD START RATIO", formatRatio(m.ColdStarts, m.Invocations), math.Round(m.ColdStarts))
+	w.Flush()
+
+	return nil
+}
+
+// fnStats shows the stats the routers record, unlike fnMetrics it works
+// without Prometheus.
+func fnStats(c *cli.Context) error {
+	client := util.GetApiClient(c.GlobalString("server"))
+
+	fnName := c.String("name")
+	if len(fnName) == 0 {
+		log.Fatal("Need name of function, use --name")
+	}
+	fnNamespace := c.String("fnNamespace")
+
+	since := c.Duration("since")
+	s, err := client.FunctionStats(&metav1.ObjectMeta{
+		Name:      fnName,
+		Namespace: fnNamespace,
+	}, since)
+	util.CheckErr(err, fmt.Sprintf("get stats of function %v", fnName))
+
+	if s.Unreachable > 0 {
+		log.Warn(fmt.Sprintf("%v of %v routers couldn't be queried, the stats are incomplete", s.Unreachable, s.Routers+s.Unreachable))
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(w, "%v\t%v/%v, last %v\n", "FUNCTION", fnNamespace, fnName, since)
+	fmt.Fprintf(w, "%v\t%v\n", "INVOCATIONS", s.Invocations)
+	fmt.Fprintf(w, "%v\t%v (%v errors)\n", "ERROR RATE", formatRatio(float64(s.Errors), float64(s.Invocations)), s.Errors)
+	fmt.Fprintf(w, "%v\t%v\n", "COLD STARTS", s.ColdStarts)
+	fmt.Fprintf(w, "%v\tp50 %v  p95 %v  p99 %v\n", "LATENCY",
+		formatLatency(s.LatencyP50), formatLatency(s.LatencyP95), formatLatency(s.LatencyP99))
+	w.Flush()
+
+	return nil
+}
+
+// sparkline renders values as a line of block characters scaled between
+// the lowest and the highest value.
+func sparkline(values []float64) string {
+	if len(values) == 0 {
+		return ""
+	}
+	min, max := values[0], values[0]
+	for _, v := range values {
+		min = math.Min(min, v)
+		max = math.Max(max, v)
+	}
+
+	line := make([]rune, len(values))
+	for i, v := range values {
+		tick := 0
+		if max > min {
+			tick = int((v - min) / (max - min) * float64(len(sparklineTicks)-1))
+		}
+		line[i] = sparklineTicks[tick]
+	}
+	return string(line)
+}
+
+// formatRatio formats part/total as a percentage, or "-" if total is 0.
+func formatRatio(part float64, total float64) string {
+	if total <= 0 {
+		return "-"
+	}
+	return fmt.Sprintf("%.1f%%", part/total*100)
+}
+
+// formatLatency formats a latency in seconds, or "-" if there's no data.
+func formatLatency(seconds float64) string {
+	if seconds <= 0 {
+		return "-"
+	}
+	return time.Duration(seconds * float64(time.Second)).Round(time.Millisecond / 10).String()
+}
@@ -0,0 +1,18 @@
+package fission_cli
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSparkline(t *testing.T) {
+	assert.Equal(t, "", sparkline(nil))
+	assert.Equal(t, "▁▁▁", sparkline([]float64{3, 3, 3}))
+	assert.Equal(t, "▁▄█", sparkline([]float64{0, 5, 10}))
+}
+
+func TestFormatRatio(t *testing.T) {
+	assert.Equal(t, "-", formatRatio(1, 0))
+	assert.Equal(t, "2.5%", formatRatio(1, 40))
+}
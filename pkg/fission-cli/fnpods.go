@@ -0,0 +1,208 @@
+/*
+Copyright 2019 The Fission Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package fission_cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"text/tabwriter"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/urfave/cli"
+	apiv1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/util/duration"
+	"k8s.io/client-go/kubernetes"
+
+	fv1 "github.com/fission/fission/pkg/apis/fission.io/v1"
+	"github.com/fission/fission/pkg/fission-cli/log"
+	"github.com/fission/fission/pkg/fission-cli/util"
+	"github.com/fission/fission/pkg/types"
+)
+
+type (
+	// podMetrics is the PodMetrics resource of metrics-server, only the
+	// fields used here.
+	podMetrics struct {
+		Metadata   metav1.ObjectMeta  `json:"metadata"`
+		Window     metav1.Duration    `json:"window"`
+		Containers []containerMetrics `json:"containers"`
+	}
+
+	containerMetrics struct {
+		Name  string             `json:"name"`
+		Usage apiv1.ResourceList `json:"usage"`
+	}
+)
+
+// fnPods lists the pods currently serving the function.
+func fnPods(c *cli.Context) error {
+	fn := getFunctionForPods(c)
+
+	_, kubeClient := util.GetKubernetesClient()
+	pods, err := getFunctionPods(kubeClient, fn)
+	util.CheckErr(err, fmt.Sprintf("list pods of function %v", fn.Metadata.Name))
+	if len(pods) == 0 {
+		fmt.Println("No pods running, the function is not specialized or has been scaled down")
+		return nil
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(w, "%v\t%v\t%v\t%v\t%v\t%v\t%v\t%v\n", "NAME", "NAMESPACE", "EXECUTOR", "STATUS", "RESTARTS", "AGE", "NODE", "IP")
+	for _, pod := range pods {
+		var restarts int32
+		for _, cs := range pod.Status.ContainerStatuses {
+			restarts += cs.RestartCount
+		}
+		executor := pod.Labels[types.EXECUTOR_TYPE]
+		if len(executor) == 0 {
+			executor = string(fn.Spec.InvokeStrategy.ExecutionStrategy.ExecutorType)
+		}
+		fmt.Fprintf(w, "%v\t%v\t%v\t%v\t%v\t%v\t%v\t%v\n", pod.Name, pod.Namespace, executor, pod.Status.Phase,
+			restarts, podAge(&pod, time.Now()), pod.Spec.NodeName, pod.Status.PodIP)
+	}
+	w.Flush()
+
+	return nil
+}
+
+// fnTop shows the CPU and memory usage of the pods of the function,
+// as reported by metrics-server.
+func fnTop(c *cli.Context) error {
+	fn := getFunctionForPods(c)
+
+	_, kubeClient := util.GetKubernetesClient()
+	pods, err := getFunctionPods(kubeClient, fn)
+	util.CheckErr(err, fmt.Sprintf("list pods of function %v", fn.Metadata.Name))
+	if len(pods) == 0 {
+		fmt.Println("No pods running, the function is not specialized or has been scaled down")
+		return nil
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(w, "%v\t%v\t%v\n", "NAME", "CPU(cores)", "MEMORY(bytes)")
+	var totalCPU, totalMemory resource.Quantity
+	for _, pod := range pods {
+		m, err := getPodMetrics(kubeClient, pod.Namespace, pod.Name)
+		if err != nil {
+			fmt.Fprintf(w, "%v\t%v\t%v\n", pod.Name, "<unknown>", "<unknown>")
+			log.Verbose(2, "Error getting metrics of pod %v: %v", pod.Name, err)
+			continue
+		}
+		cpu, memory := m.usage()
+		totalCPU.Add(cpu)
+		totalMemory.Add(memory)
+		fmt.Fprintf(w, "%v\t%v\t%v\n", pod.Name, formatCPU(cpu), formatMemory(memory))
+	}
+	if len(pods) > 1 {
+		fmt.Fprintf(w, "%v\t%v\t%v\n", "TOTAL", formatCPU(totalCPU), formatMemory(totalMemory))
+	}
+	w.Flush()
+
+	return nil
+}
+
+func getFunctionForPods(c *cli.Context) *fv1.Function {
+	client := util.GetApiClient(c.GlobalString("server"))
+
+	fnName := c.String("name")
+	if len(fnName) == 0 {
+		log.Fatal("Need name of function, use --name")
+	}
+
+	fn, err := client.FunctionGet(&metav1.ObjectMeta{
+		Name:      fnName,
+		Namespace: c.String("fnNamespace"),
+	})
+	util.CheckErr(err, fmt.Sprintf("get function %v", fnName))
+	return fn
+}
+
+// functionPodSelector selects the pods the executors run the function on,
+// for all executor types.
+func functionPodSelector(fn *fv1.Function) string {
+	return labels.Set(map[string]string{
+		types.FUNCTION_NAME:      fn.Metadata.Name,
+		types.FUNCTION_NAMESPACE: fn.Metadata.Namespace,
+	}).AsSelector().String()
+}
+
+// getFunctionPods returns the pods of the function, oldest first.
+func getFunctionPods(kubeClient *kubernetes.Clientset, fn *fv1.Function) ([]apiv1.Pod, error) {
+	pods, err := kubeClient.CoreV1().Pods(metav1.NamespaceAll).List(metav1.ListOptions{
+		LabelSelector: functionPodSelector(fn),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	items := pods.Items
+	sort.Slice(items, func(i, j int) bool {
+		return items[i].CreationTimestamp.Before(&items[j].CreationTimestamp)
+	})
+	return items, nil
+}
+
+// getPodMetrics gets the metrics of a pod from the metrics API.
+func getPodMetrics(kubeClient *kubernetes.Clientset, namespace string, name string) (*podMetrics, error) {
+	body, err := kubeClient.CoreV1().RESTClient().Get().
+		AbsPath("/apis/metrics.k8s.io/v1beta1/namespaces", namespace, "pods", name).
+		DoRaw()
+	if err != nil {
+		return nil, errors.Wrap(err, "error getting pod metrics, is metrics-server installed?")
+	}
+
+	var m podMetrics
+	err = json.Unmarshal(body, &m)
+	if err != nil {
+		return nil, errors.Wrap(err, "error decoding pod metrics")
+	}
+	return &m, nil
+}
+
+// usage returns the total CPU and memory usage of the containers of the pod.
+func (m *podMetrics) usage() (cpu resource.Quantity, memory resource.Quantity) {
+	for _, c := range m.Containers {
+		if q, ok := c.Usage[apiv1.ResourceCPU]; ok {
+			cpu.Add(q)
+		}
+		if q, ok := c.Usage[apiv1.ResourceMemory]; ok {
+			memory.Add(q)
+		}
+	}
+	return cpu, memory
+}
+
+func formatCPU(q resource.Quantity) string {
+	return fmt.Sprintf("%vm", q.MilliValue())
+}
+
+func formatMemory(q resource.Quantity) string {
+	return fmt.Sprintf("%vMi", q.Value()/(1024*1024))
+}
+
+func podAge(pod *apiv1.Pod, now time.Time) string {
+	if pod.CreationTimestamp.IsZero() {
+		return "<unknown>"
+	}
+	return duration.HumanDuration(now.Sub(pod.CreationTimestamp.Time))
+}
@@ -0,0 +1,41 @@
+package fission_cli
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	apiv1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestPodMetricsUsage(t *testing.T) {
+	body := `{
+		"kind": "PodMetrics",
+		"apiVersion": "metrics.k8s.io/v1beta1",
+		"metadata": {"name": "poolmgr-nodejs-abc", "namespace": "fission-function"},
+		"window": "30s",
+		"containers": [
+			{"name": "nodejs", "usage": {"cpu": "12345678n", "memory": "30Mi"}},
+			{"name": "fetcher", "usage": {"cpu": "1m", "memory": "10240Ki"}}
+		]
+	}`
+
+	var m podMetrics
+	assert.NoError(t, json.Unmarshal([]byte(body), &m))
+	assert.Equal(t, "poolmgr-nodejs-abc", m.Metadata.Name)
+
+	cpu, memory := m.usage()
+	assert.Equal(t, "14m", formatCPU(cpu))
+	assert.Equal(t, "40Mi", formatMemory(memory))
+}
+
+func TestPodAge(t *testing.T) {
+	now := time.Now()
+	pod := &apiv1.Pod{}
+	assert.Equal(t, "<unknown>", podAge(pod, now))
+
+	pod.CreationTimestamp = metav1.NewTime(now.Add(-30 * time.Minute))
+	assert.Equal(t, "30m", podAge(pod, now))
+}
@@ -0,0 +1,95 @@
+/*
+Copyright 2019 The Fission Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package fission_cli
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/urfave/cli"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/fission/fission/pkg/fission-cli/log"
+	"github.com/fission/fission/pkg/fission-cli/util"
+	"github.com/fission/fission/pkg/types"
+)
+
+func fnProfile(c *cli.Context) error {
+	client := util.GetApiClient(c.GlobalString("server"))
+
+	fnName := c.String("name")
+	if len(fnName) == 0 {
+		log.Fatal("Need name of function, use --name")
+	}
+	fnNamespace := c.String("fnNamespace")
+
+	duration := c.Duration("duration")
+	if duration < time.Second || duration > types.MaxProfileSeconds*time.Second {
+		log.Fatal(fmt.Sprintf("Profile duration must be between 1s and %v", types.MaxProfileSeconds*time.Second))
+	}
+	profileType := c.String("type")
+
+	outDir := c.String("output")
+	if len(outDir) == 0 {
+		outDir = "."
+	}
+	err := os.MkdirAll(outDir, 0755)
+	util.CheckErr(err, fmt.Sprintf("create output directory %v", outDir))
+
+	fmt.Printf("Capturing %v profile of function '%v' for %v...\n", profileType, fnName, duration)
+
+	resp, err := client.FunctionProfile(&metav1.ObjectMeta{
+		Name:      fnName,
+		Namespace: fnNamespace,
+	}, profileType, int(duration/time.Second))
+	util.CheckErr(err, fmt.Sprintf("profile function %v", fnName))
+
+	var saved []string
+	for _, p := range resp.Profiles {
+		if len(p.Error) > 0 {
+			fmt.Printf("Pod %v: %v\n", p.Pod, p.Error)
+			continue
+		}
+		file := filepath.Join(outDir, fmt.Sprintf("%v-%v-%v%v", fnName, p.Pod, profileType, profileFileExt(p.ContentType)))
+		err = ioutil.WriteFile(file, p.Data, 0644)
+		util.CheckErr(err, fmt.Sprintf("write profile %v", file))
+		fmt.Printf("Pod %v: profile saved to %v\n", p.Pod, file)
+		saved = append(saved, file)
+	}
+
+	if len(saved) == 0 {
+		log.Fatal("No profile was captured")
+	}
+	if profileFileExt(resp.Profiles[0].ContentType) == ".pb.gz" {
+		fmt.Printf("View the flame graph with: go tool pprof -http=: %v\n", saved[0])
+	}
+	return nil
+}
+
+// profileFileExt returns the file extension of a profile, environments
+// serve either pprof protobufs or collapsed stacks as text, which can be
+// rendered by flamegraph.pl.
+func profileFileExt(contentType string) string {
+	if strings.HasPrefix(contentType, "text/") {
+		return ".txt"
+	}
+	return ".pb.gz"
+}
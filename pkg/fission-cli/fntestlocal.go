@@ -0,0 +1,342 @@
+/*
+Copyright 2019 The Fission Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package fission_cli
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/dchest/uniuri"
+	"github.com/mholt/archiver"
+	"github.com/urfave/cli"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/fission/fission/pkg/fission-cli/log"
+	"github.com/fission/fission/pkg/fission-cli/util"
+	"github.com/fission/fission/pkg/types"
+)
+
+const (
+	// same as the shared volume path of function pods
+	localRunUserfuncPath = "/userfunc"
+
+	// port environment containers serve functions on
+	localRunEnvPort = 8888
+
+	// max number of attempts to specialize the environment container,
+	// it may still be starting
+	localRunSpecializeRetries = 30
+)
+
+// localRunConfig is what fn test --local needs to run a function.
+type localRunConfig struct {
+	runtimeImage string
+	builderImage string
+	buildCmd     string
+	entrypoint   string
+	envVersion   int
+}
+
+// fnTestLocal runs the function in the runtime image of its environment
+// with Docker and sends the test request to it on localhost, without
+// creating anything on the cluster. The deployment archive is built locally
+// with the builder image if a source archive is given.
+func fnTestLocal(c *cli.Context) error {
+	var n int
+	if len(c.String("code")) > 0 {
+		n++
+	}
+	for _, flag := range []string{"src", "deploy"} {
+		if len(c.StringSlice(flag)) > 0 {
+			n++
+		}
+	}
+	if n != 1 {
+		log.Fatal("Need exactly one of --code, --src or --deploy to run the function locally.")
+	}
+
+	config := getLocalRunConfig(c)
+
+	dockerPath, err := exec.LookPath("docker")
+	util.CheckErr(err, "find docker, local runs require Docker")
+
+	workDir, err := ioutil.TempDir("", "fission-local-run-")
+	util.CheckErr(err, "create run directory")
+	defer os.RemoveAll(workDir)
+
+	var archive string
+	if srcArchiveFiles := c.StringSlice("src"); len(srcArchiveFiles) > 0 {
+		if len(config.builderImage) == 0 {
+			log.Fatal("Need a builder image to build the source archive, use --builder-image or an environment with a builder.")
+		}
+		archive = filepath.Join(workDir, "deploy.zip")
+		err = buildPackageLocally(config.builderImage, config.buildCmd, srcArchiveFiles, archive)
+		util.CheckErr(err, "build package")
+	} else if code := c.String("code"); len(code) > 0 {
+		archive = localArchiveFile([]string{code}, true)
+	} else {
+		archive = localArchiveFile(c.StringSlice("deploy"), false)
+	}
+
+	// Same as the fetcher, zip archives are extracted and other files are
+	// copied as is.
+	userfuncDir := filepath.Join(workDir, "userfunc")
+	err = os.Mkdir(userfuncDir, 0755)
+	util.CheckErr(err, "create function directory")
+	deployName := "deploy"
+	if config.envVersion == 1 {
+		// v1 environments load the function from a fixed path
+		deployName = "user"
+	}
+	err = extractLocalArchive(archive, filepath.Join(userfuncDir, deployName))
+	util.CheckErr(err, fmt.Sprintf("extract deployment archive %v", archive))
+
+	port, err := findFreePort()
+	util.CheckErr(err, "find a free local port")
+
+	container := fmt.Sprintf("fission-local-%v", strings.ToLower(uniuri.NewLen(6)))
+	args := []string{"run", "--detach", "--rm",
+		"--name", container,
+		"-p", fmt.Sprintf("127.0.0.1:%v:%v", port, localRunEnvPort),
+		"-v", fmt.Sprintf("%v:%v", userfuncDir, localRunUserfuncPath),
+		config.runtimeImage,
+	}
+	fmt.Printf("Running function with image %v\n", config.runtimeImage)
+	out, err := exec.Command(dockerPath, args...).CombinedOutput()
+	if err != nil {
+		log.Fatal(fmt.Sprintf("Error running image %v: %v\n%s", config.runtimeImage, err, out))
+	}
+
+	// log.Fatal skips deferred calls, remove the container first
+	err = runLocalTest(c, dockerPath, container, port, path.Join(localRunUserfuncPath, deployName), config)
+	exec.Command(dockerPath, "rm", "--force", container).Run()
+	os.RemoveAll(workDir)
+	util.CheckErr(err, "test function locally")
+	return nil
+}
+
+// runLocalTest specializes the environment container listening on port and
+// sends the test request to it.
+func runLocalTest(c *cli.Context, dockerPath string, container string, port int, filePath string, config *localRunConfig) error {
+	baseUrl := fmt.Sprintf("http://127.0.0.1:%v", port)
+	err := specializeLocally(baseUrl, filePath, config)
+	if err != nil {
+		printContainerLogs(dockerPath, container)
+		return fmt.Errorf("error specializing function: %v", err)
+	}
+
+	functionUrl, err := url.Parse(baseUrl + "/")
+	if err != nil {
+		return err
+	}
+
+	ctx := context.Background()
+	if deadline := c.Duration("timeout"); deadline > 0 {
+		var closeCtx func()
+		ctx, closeCtx = context.WithTimeout(ctx, deadline)
+		defer closeCtx()
+	}
+
+	resp := doTestRequest(ctx, c, functionUrl)
+	defer resp.Body.Close()
+	respBody, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode < 400 {
+		fmt.Print(string(respBody))
+		return nil
+	}
+
+	fmt.Printf("Error calling function: %d; Please try again or fix the error: %s\n", resp.StatusCode, string(respBody))
+	printContainerLogs(dockerPath, container)
+	return nil
+}
+
+// getLocalRunConfig returns the images, build command and entrypoint of the
+// flags, the missing ones are taken from the environment, and from the
+// function if --name is given.
+func getLocalRunConfig(c *cli.Context) *localRunConfig {
+	config := &localRunConfig{
+		runtimeImage: c.String("runtime-image"),
+		builderImage: c.String("builder-image"),
+		buildCmd:     c.String("buildcmd"),
+		entrypoint:   c.String("entrypoint"),
+		envVersion:   2,
+	}
+
+	needBuilder := len(c.StringSlice("src")) > 0 && len(config.builderImage) == 0
+	fnName := c.String("name")
+	if len(config.runtimeImage) > 0 && !needBuilder && (len(config.entrypoint) > 0 || len(fnName) == 0) {
+		// no cluster access is needed
+		if len(config.buildCmd) == 0 {
+			config.buildCmd = defaultBuildCommand
+		}
+		return config
+	}
+
+	client := util.GetApiClient(c.GlobalString("server"))
+
+	envName, envNamespace := c.String("env"), c.String("envNamespace")
+	if len(fnName) > 0 {
+		fn, err := client.FunctionGet(&metav1.ObjectMeta{
+			Name:      fnName,
+			Namespace: c.String("fnNamespace"),
+		})
+		util.CheckErr(err, fmt.Sprintf("get function %v", fnName))
+		if len(envName) == 0 {
+			envName, envNamespace = fn.Spec.Environment.Name, fn.Spec.Environment.Namespace
+		}
+		if len(config.entrypoint) == 0 {
+			config.entrypoint = fn.Spec.Package.FunctionName
+		}
+	}
+
+	if len(config.runtimeImage) == 0 || needBuilder {
+		if len(envName) == 0 {
+			log.Fatal("Need --env, --name or --runtime-image argument.")
+		}
+		env, err := client.EnvironmentGet(&metav1.ObjectMeta{
+			Name:      envName,
+			Namespace: envNamespace,
+		})
+		util.CheckErr(err, fmt.Sprintf("get environment %v", envName))
+		if len(config.runtimeImage) == 0 {
+			config.runtimeImage = env.Spec.Runtime.Image
+		}
+		if len(config.builderImage) == 0 {
+			config.builderImage = env.Spec.Builder.Image
+		}
+		if len(config.buildCmd) == 0 {
+			config.buildCmd = env.Spec.Builder.Command
+		}
+		config.envVersion = env.Spec.Version
+	}
+	if len(config.buildCmd) == 0 {
+		config.buildCmd = defaultBuildCommand
+	}
+	return config
+}
+
+// localArchiveFile returns the local path of the archive made of the files,
+// an archive given as URL is downloaded first.
+func localArchiveFile(files []string, noZip bool) string {
+	archive := makeArchiveFileIfNeeded("", files, noZip)
+	if isHTTPURL(archive) {
+		archive = downloadToTempFile(archive)
+	}
+	return archive
+}
+
+// extractLocalArchive extracts a zip archive to dst, or copies any other
+// file to dst.
+func extractLocalArchive(archive string, dst string) error {
+	if archiver.Zip.Match(archive) {
+		return archiver.Zip.Open(archive, dst)
+	}
+
+	src, err := os.Open(archive)
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+
+	f, err := os.Create(dst)
+	if err != nil {
+		return err
+	}
+	_, err = io.Copy(f, src)
+	if err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// findFreePort returns a port nothing listens on at the moment.
+func findFreePort() (int, error) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return 0, err
+	}
+	defer listener.Close()
+	return listener.Addr().(*net.TCPAddr).Port, nil
+}
+
+// specializeLocally loads the function into the environment container the
+// same way the fetcher does, retrying while the container is starting.
+func specializeLocally(baseUrl string, filePath string, config *localRunConfig) error {
+	specializeUrl := baseUrl + "/specialize"
+	contentType := "text/plain"
+	var payload []byte
+	if config.envVersion >= 2 {
+		loadReq := types.FunctionLoadRequest{
+			FilePath:     filePath,
+			FunctionName: config.entrypoint,
+			URL:          "/",
+			EnvVersion:   config.envVersion,
+		}
+		var err error
+		payload, err = json.Marshal(loadReq)
+		if err != nil {
+			return err
+		}
+		specializeUrl = baseUrl + "/v2/specialize"
+		contentType = "application/json"
+	}
+
+	var err error
+	for i := 0; i < localRunSpecializeRetries; i++ {
+		var resp *http.Response
+		resp, err = http.Post(specializeUrl, contentType, bytes.NewReader(payload))
+		if err != nil {
+			// the environment container is not listening yet
+			time.Sleep(500 * time.Millisecond)
+			continue
+		}
+		body, _ := ioutil.ReadAll(resp.Body)
+		resp.Body.Close()
+		if resp.StatusCode >= 300 {
+			return fmt.Errorf("%v %v", resp.StatusCode, strings.TrimSpace(string(body)))
+		}
+		return nil
+	}
+	return fmt.Errorf("environment container didn't start: %v", err)
+}
+
+// printContainerLogs prints the output of the container to help find why
+// the function failed.
+func printContainerLogs(dockerPath string, container string) {
+	fmt.Printf("\n=== Container logs ===\n")
+	cmd := exec.Command(dockerPath, "logs", container)
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stdout
+	cmd.Run()
+}
@@ -0,0 +1,36 @@
+package fission_cli
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/mholt/archiver"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestExtractLocalArchive(t *testing.T) {
+	dir, err := ioutil.TempDir("", "fission-local-run")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	code := filepath.Join(dir, "hello.js")
+	assert.NoError(t, ioutil.WriteFile(code, []byte("module.exports = 1"), 0644))
+
+	// other files are copied as is
+	dst := filepath.Join(dir, "user")
+	assert.NoError(t, extractLocalArchive(code, dst))
+	contents, err := ioutil.ReadFile(dst)
+	assert.NoError(t, err)
+	assert.Equal(t, "module.exports = 1", string(contents))
+
+	// zip archives are extracted to a directory
+	archive := filepath.Join(dir, "deploy.zip")
+	assert.NoError(t, archiver.Zip.Make(archive, []string{code}))
+	dst = filepath.Join(dir, "deploy")
+	assert.NoError(t, extractLocalArchive(archive, dst))
+	contents, err = ioutil.ReadFile(filepath.Join(dst, "hello.js"))
+	assert.NoError(t, err)
+	assert.Equal(t, "module.exports = 1", string(contents))
+}
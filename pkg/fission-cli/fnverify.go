@@ -0,0 +1,367 @@
+/*
+Copyright 2019 The Fission Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package fission_cli
+
+import (
+	"context"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/ghodss/yaml"
+	"github.com/urfave/cli"
+	"k8s.io/client-go/util/jsonpath"
+
+	"github.com/fission/fission/pkg/fission-cli/log"
+	"github.com/fission/fission/pkg/fission-cli/util"
+	"github.com/fission/fission/pkg/utils"
+)
+
+type (
+	// VerifyCases is the file of request and expected response cases of
+	// fn verify.
+	VerifyCases struct {
+		Cases []VerifyCase `json:"cases"`
+	}
+
+	VerifyCase struct {
+		Name   string `json:"name"`
+		Method string `json:"method,omitempty"`
+		// Query parameters and headers of the request
+		Query   map[string]string `json:"query,omitempty"`
+		Headers map[string]string `json:"headers,omitempty"`
+		// Body is sent as is, BodyFile is read from a file relative to
+		// the cases file and its content type guessed unless set in
+		// Headers.
+		Body     string         `json:"body,omitempty"`
+		BodyFile string         `json:"bodyFile,omitempty"`
+		Expect   VerifyExpected `json:"expect"`
+	}
+
+	VerifyExpected struct {
+		// Status defaults to 200
+		Status int `json:"status,omitempty"`
+		// Headers must have the given values
+		Headers      map[string]string `json:"headers,omitempty"`
+		BodyContains string            `json:"bodyContains,omitempty"`
+		// JSON assertions on the response body
+		JSON []VerifyJSONAssertion `json:"json,omitempty"`
+	}
+
+	// VerifyJSONAssertion checks the value at a JSON path of the response
+	// body, in the kubectl syntax like {.items[0].name} or .items[0].name.
+	VerifyJSONAssertion struct {
+		Path   string      `json:"path"`
+		Equals interface{} `json:"equals,omitempty"`
+		// Exists checks only whether the path is there or not
+		Exists *bool `json:"exists,omitempty"`
+	}
+
+	verifyResult struct {
+		name     string
+		duration time.Duration
+		failures []string
+	}
+
+	junitTestSuites struct {
+		XMLName xml.Name         `xml:"testsuites"`
+		Suites  []junitTestSuite `xml:"testsuite"`
+	}
+
+	junitTestSuite struct {
+		Name     string          `xml:"name,attr"`
+		Tests    int             `xml:"tests,attr"`
+		Failures int             `xml:"failures,attr"`
+		Time     string          `xml:"time,attr"`
+		Cases    []junitTestCase `xml:"testcase"`
+	}
+
+	junitTestCase struct {
+		Name      string        `xml:"name,attr"`
+		ClassName string        `xml:"classname,attr"`
+		Time      string        `xml:"time,attr"`
+		Failure   *junitFailure `xml:"failure,omitempty"`
+	}
+
+	junitFailure struct {
+		Message string `xml:"message,attr"`
+		Text    string `xml:",chardata"`
+	}
+)
+
+// fnVerify runs the request and expected response cases of a file against
+// a function and reports them, optionally as JUnit XML for CI.
+func fnVerify(c *cli.Context) error {
+	fnName := c.String("name")
+	if len(fnName) == 0 {
+		log.Fatal("Need name of function, use --name")
+	}
+	ns := c.String("fnNamespace")
+	casesFile := c.String("cases")
+	if len(casesFile) == 0 {
+		log.Fatal("Need a file of test cases, use --cases")
+	}
+
+	cases, err := readVerifyCases(casesFile)
+	util.CheckErr(err, "read test cases")
+
+	functionURL := fmt.Sprintf("http://%s%s", getRouterURL(), utils.UrlForFunction(fnName, ns))
+	timeout := c.Duration("timeout")
+
+	start := time.Now()
+	results := make([]verifyResult, 0, len(cases.Cases))
+	failed := 0
+	for i, vc := range cases.Cases {
+		if len(vc.Name) == 0 {
+			vc.Name = fmt.Sprintf("case-%d", i+1)
+		}
+		result := runVerifyCase(functionURL, filepath.Dir(casesFile), vc, timeout)
+		if len(result.failures) > 0 {
+			failed++
+			fmt.Printf("FAIL %v (%v)\n", result.name, result.duration.Round(time.Millisecond))
+			for _, f := range result.failures {
+				fmt.Printf("    %v\n", f)
+			}
+		} else {
+			fmt.Printf("PASS %v (%v)\n", result.name, result.duration.Round(time.Millisecond))
+		}
+		results = append(results, result)
+	}
+	fmt.Printf("%v passed, %v failed\n", len(results)-failed, failed)
+
+	if junitFile := c.String("junit"); len(junitFile) > 0 {
+		report, err := junitReport(fmt.Sprintf("%v/%v", ns, fnName), results, time.Since(start))
+		util.CheckErr(err, "generate JUnit report")
+		err = ioutil.WriteFile(junitFile, report, 0644)
+		util.CheckErr(err, "write JUnit report")
+	}
+
+	if failed > 0 {
+		return fmt.Errorf("%v of %v cases failed", failed, len(results))
+	}
+	return nil
+}
+
+func readVerifyCases(file string) (*VerifyCases, error) {
+	data, err := ioutil.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+	var cases VerifyCases
+	err = yaml.Unmarshal(data, &cases)
+	if err != nil {
+		return nil, err
+	}
+	if len(cases.Cases) == 0 {
+		return nil, fmt.Errorf("no cases in %v", file)
+	}
+	return &cases, nil
+}
+
+// runVerifyCase sends the request of the case to the function and checks
+// the response.
+func runVerifyCase(functionURL string, baseDir string, vc VerifyCase, timeout time.Duration) verifyResult {
+	result := verifyResult{name: vc.Name}
+	fail := func(format string, args ...interface{}) verifyResult {
+		result.failures = append(result.failures, fmt.Sprintf(format, args...))
+		return result
+	}
+
+	u, err := url.Parse(functionURL)
+	if err != nil {
+		return fail("invalid function URL: %v", err)
+	}
+	if len(vc.Query) > 0 {
+		q := url.Values{}
+		for k, v := range vc.Query {
+			q.Set(k, v)
+		}
+		u.RawQuery = q.Encode()
+	}
+
+	var body io.ReadCloser = http.NoBody
+	var contentType string
+	if len(vc.BodyFile) > 0 {
+		path := vc.BodyFile
+		if !filepath.IsAbs(path) {
+			path = filepath.Join(baseDir, path)
+		}
+		body, _, contentType, err = getRequestBody("@"+path, true)
+		if err != nil {
+			return fail("read body file: %v", err)
+		}
+	} else if len(vc.Body) > 0 {
+		body = ioutil.NopCloser(strings.NewReader(vc.Body))
+	}
+	defer body.Close()
+
+	method := vc.Method
+	if len(method) == 0 {
+		method = http.MethodGet
+		if body != http.NoBody {
+			method = http.MethodPost
+		}
+	}
+
+	ctx := context.Background()
+	if timeout > 0 {
+		var cancel func()
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+	req, err := http.NewRequest(strings.ToUpper(method), u.String(), body)
+	if err != nil {
+		return fail("create request: %v", err)
+	}
+	if len(contentType) > 0 {
+		req.Header.Set("Content-Type", contentType)
+	}
+	for k, v := range vc.Headers {
+		req.Header.Set(k, v)
+	}
+
+	start := time.Now()
+	resp, err := http.DefaultClient.Do(req.WithContext(ctx))
+	if err != nil {
+		result.duration = time.Since(start)
+		return fail("request failed: %v", err)
+	}
+	defer resp.Body.Close()
+	respBody, err := ioutil.ReadAll(resp.Body)
+	result.duration = time.Since(start)
+	if err != nil {
+		return fail("read response: %v", err)
+	}
+
+	result.failures = checkVerifyResponse(vc.Expect, resp.StatusCode, resp.Header, respBody)
+	return result
+}
+
+// checkVerifyResponse returns the failed expectations of the response.
+func checkVerifyResponse(expected VerifyExpected, status int, header http.Header, body []byte) []string {
+	var failures []string
+
+	expectedStatus := expected.Status
+	if expectedStatus == 0 {
+		expectedStatus = http.StatusOK
+	}
+	if status != expectedStatus {
+		failures = append(failures, fmt.Sprintf("status: expected %v, got %v: %v", expectedStatus, status, truncate(string(body), 200)))
+	}
+
+	for k, v := range expected.Headers {
+		if got := header.Get(k); got != v {
+			failures = append(failures, fmt.Sprintf("header %v: expected %q, got %q", k, v, got))
+		}
+	}
+
+	if len(expected.BodyContains) > 0 && !strings.Contains(string(body), expected.BodyContains) {
+		failures = append(failures, fmt.Sprintf("body: expected to contain %q", expected.BodyContains))
+	}
+
+	if len(expected.JSON) > 0 {
+		var doc interface{}
+		err := json.Unmarshal(body, &doc)
+		if err != nil {
+			return append(failures, fmt.Sprintf("body: not JSON: %v", err))
+		}
+		for _, a := range expected.JSON {
+			if msg := checkJSONAssertion(a, doc); len(msg) > 0 {
+				failures = append(failures, fmt.Sprintf("json %v: %v", a.Path, msg))
+			}
+		}
+	}
+	return failures
+}
+
+// checkJSONAssertion returns why the assertion failed on the decoded JSON
+// document, or an empty string if it passed.
+func checkJSONAssertion(a VerifyJSONAssertion, doc interface{}) string {
+	path := a.Path
+	if !strings.HasPrefix(path, "{") {
+		path = "{" + path + "}"
+	}
+	jp := jsonpath.New(a.Path)
+	err := jp.Parse(path)
+	if err != nil {
+		return fmt.Sprintf("invalid path: %v", err)
+	}
+	results, err := jp.FindResults(doc)
+	found := err == nil && len(results) > 0 && len(results[0]) > 0
+
+	if a.Exists != nil {
+		if found != *a.Exists {
+			return fmt.Sprintf("expected exists %v, got %v", *a.Exists, found)
+		}
+		return ""
+	}
+	if !found {
+		return "not found"
+	}
+
+	got := results[0][0].Interface()
+	if !reflect.DeepEqual(got, a.Equals) {
+		gotJSON, _ := json.Marshal(got)
+		expectedJSON, _ := json.Marshal(a.Equals)
+		return fmt.Sprintf("expected %s, got %s", expectedJSON, gotJSON)
+	}
+	return ""
+}
+
+func junitReport(suite string, results []verifyResult, duration time.Duration) ([]byte, error) {
+	ts := junitTestSuite{
+		Name:  suite,
+		Tests: len(results),
+		Time:  fmt.Sprintf("%.3f", duration.Seconds()),
+	}
+	for _, r := range results {
+		tc := junitTestCase{
+			Name:      r.name,
+			ClassName: suite,
+			Time:      fmt.Sprintf("%.3f", r.duration.Seconds()),
+		}
+		if len(r.failures) > 0 {
+			ts.Failures++
+			tc.Failure = &junitFailure{
+				Message: r.failures[0],
+				Text:    strings.Join(r.failures, "\n"),
+			}
+		}
+		ts.Cases = append(ts.Cases, tc)
+	}
+
+	out, err := xml.MarshalIndent(junitTestSuites{Suites: []junitTestSuite{ts}}, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append([]byte(xml.Header), append(out, '\n')...), nil
+}
+
+func truncate(s string, n int) string {
+	if len(s) <= n {
+		return s
+	}
+	return s[:n] + "..."
+}
@@ -0,0 +1,54 @@
+package fission_cli
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ghodss/yaml"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCheckVerifyResponse(t *testing.T) {
+	var expected VerifyExpected
+	err := yaml.Unmarshal([]byte(`
+status: 201
+headers:
+  Content-Type: application/json
+bodyContains: hello
+json:
+- path: .message
+  equals: hello world
+- path: "{.items[1].count}"
+  equals: 2
+- path: .missing
+  exists: false
+`), &expected)
+	assert.NoError(t, err)
+
+	header := http.Header{}
+	header.Set("Content-Type", "application/json")
+	body := []byte(`{"message": "hello world", "items": [{"count": 1}, {"count": 2}]}`)
+	assert.Empty(t, checkVerifyResponse(expected, 201, header, body))
+
+	body = []byte(`{"message": "hello there", "items": [{"count": 1}], "missing": true}`)
+	failures := checkVerifyResponse(expected, 200, http.Header{}, body)
+	assert.Len(t, failures, 5)
+	assert.True(t, strings.HasPrefix(failures[0], "status: expected 201, got 200"))
+
+	failures = checkVerifyResponse(VerifyExpected{JSON: expected.JSON}, 200, header, []byte("hello"))
+	assert.Len(t, failures, 1)
+}
+
+func TestJunitReport(t *testing.T) {
+	report, err := junitReport("default/hello", []verifyResult{
+		{name: "ok", duration: time.Second},
+		{name: "broken", failures: []string{"status: expected 200, got 500", "body: expected to contain \"x\""}},
+	}, 2*time.Second)
+	assert.NoError(t, err)
+	s := string(report)
+	assert.Contains(t, s, `<testsuite name="default/hello" tests="2" failures="1" time="2.000">`)
+	assert.Contains(t, s, `<testcase name="ok" classname="default/hello" time="1.000"></testcase>`)
+	assert.Contains(t, s, `<failure message="status: expected 200, got 500">`)
+}
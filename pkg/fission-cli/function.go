@@ -20,37 +20,50 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"io"
 	"io/ioutil"
+	"mime"
 	"net/http"
 	"net/url"
 	"os"
+	"path/filepath"
 	"strings"
 	"text/tabwriter"
 	"time"
 
-	uuid "github.com/satori/go.uuid"
 	"github.com/urfave/cli"
 	apiv1 "k8s.io/api/core/v1"
 	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 
 	fv1 "github.com/fission/fission/pkg/apis/fission.io/v1"
+	"github.com/fission/fission/pkg/controller/client"
 	ferror "github.com/fission/fission/pkg/error"
 	"github.com/fission/fission/pkg/fission-cli/cliwrapper/driver/urfavecli"
 	"github.com/fission/fission/pkg/fission-cli/cmd"
 	cmdutils "github.com/fission/fission/pkg/fission-cli/cmd"
+	"github.com/fission/fission/pkg/fission-cli/cmd/httptrigger"
 	"github.com/fission/fission/pkg/fission-cli/cmd/spec"
 	"github.com/fission/fission/pkg/fission-cli/log"
 	"github.com/fission/fission/pkg/fission-cli/logdb"
 	"github.com/fission/fission/pkg/fission-cli/util"
 	"github.com/fission/fission/pkg/types"
+	"github.com/fission/fission/pkg/utils"
 )
 
 const (
 	DEFAULT_MIN_SCALE             = 1
 	DEFAULT_TARGET_CPU_PERCENTAGE = 80
+
+	// the emptydir volume mounted by --scratch-size alone
+	defaultScratchVolume = "scratch"
+	defaultScratchPath   = "/scratch"
 )
 
+// printPodLogs prints the logs of the function pods read from Kubernetes
+// instead of the log database, of the previous containers of the pods
+// with --previous.
 func printPodLogs(c *cli.Context) error {
 	fnName := c.String("name")
 	if len(fnName) == 0 {
@@ -60,6 +73,15 @@ func printPodLogs(c *cli.Context) error {
 	queryURL, err := url.Parse(util.GetServerUrl())
 	util.CheckErr(err, "parse the base URL")
 	queryURL.Path = fmt.Sprintf("/proxy/logs/%s", fnName)
+	q := url.Values{}
+	q.Set("namespace", c.String("fnNamespace"))
+	if len(c.String("pod")) > 0 {
+		q.Set("pod", c.String("pod"))
+	}
+	if c.Bool("previous") {
+		q.Set("previous", "true")
+	}
+	queryURL.RawQuery = q.Encode()
 
 	req, err := http.NewRequest("POST", queryURL.String(), nil)
 	util.CheckErr(err, "create logs request")
@@ -70,7 +92,7 @@ func printPodLogs(c *cli.Context) error {
 
 	defer resp.Body.Close()
 	if resp.StatusCode != http.StatusOK {
-		return errors.New("get logs from pod directly")
+		return ferror.MakeErrorFromHTTP(resp)
 	}
 
 	body, err := ioutil.ReadAll(resp.Body)
@@ -83,15 +105,27 @@ func getInvokeStrategy(c *cli.Context, existingInvokeStrategy *fv1.InvokeStrateg
 
 	var fnExecutor, newFnExecutor fv1.ExecutorType
 
-	switch c.String("executortype") {
+	executorType := c.String("executortype")
+	// functions given by an image run without environment
+	if !c.IsSet("executortype") && len(c.String("image")) > 0 {
+		executorType = types.ExecutorTypeContainer
+	}
+	// prebuilt images are only run by newdeploy
+	if !c.IsSet("executortype") && len(c.String("from-image")) > 0 {
+		executorType = types.ExecutorTypeNewdeploy
+	}
+
+	switch executorType {
 	case "":
 		fallthrough
 	case types.ExecutorTypePoolmgr:
 		newFnExecutor = types.ExecutorTypePoolmgr
 	case types.ExecutorTypeNewdeploy:
 		newFnExecutor = types.ExecutorTypeNewdeploy
+	case types.ExecutorTypeContainer:
+		newFnExecutor = types.ExecutorTypeContainer
 	default:
-		return nil, errors.New("executor type must be one of 'poolmgr' or 'newdeploy', defaults to 'poolmgr'")
+		return nil, errors.New("executor type must be one of 'poolmgr', 'newdeploy' or 'container', defaults to 'poolmgr'")
 	}
 
 	if existingInvokeStrategy != nil {
@@ -109,6 +143,14 @@ func getInvokeStrategy(c *cli.Context, existingInvokeStrategy *fv1.InvokeStrateg
 		return nil, errors.New("specializationtimeout flag is only applicable for newdeploy type of executor")
 	}
 
+	if c.Bool("multiplex") && fnExecutor != types.ExecutorTypePoolmgr {
+		return nil, errors.New("multiplex flag is only applicable for poolmgr type of executor")
+	}
+
+	if (c.IsSet("isolation") || c.IsSet("prespecialized")) && fnExecutor != types.ExecutorTypePoolmgr {
+		return nil, errors.New("isolation and prespecialized flags are only applicable for poolmgr type of executor")
+	}
+
 	if fnExecutor == types.ExecutorTypePoolmgr {
 		if c.IsSet("targetcpu") || c.IsSet("minscale") || c.IsSet("maxscale") {
 			log.Fatal("To set target CPU or min/max scale for function, please specify \"--executortype newdeploy\"")
@@ -117,10 +159,51 @@ func getInvokeStrategy(c *cli.Context, existingInvokeStrategy *fv1.InvokeStrateg
 		if c.IsSet("mincpu") || c.IsSet("maxcpu") || c.IsSet("minmemory") || c.IsSet("maxmemory") {
 			log.Warn("To limit CPU/Memory for function with executor type \"poolmgr\", please specify resources limits when creating environment")
 		}
+
+		multiplex := false
+		if existingInvokeStrategy != nil && existingInvokeStrategy.ExecutionStrategy.ExecutorType == types.ExecutorTypePoolmgr {
+			multiplex = existingInvokeStrategy.ExecutionStrategy.Multiplex
+		}
+		if c.IsSet("multiplex") {
+			multiplex = c.Bool("multiplex")
+		}
+
+		var isolation fv1.IsolationMode
+		var prespecialized int
+		if existingInvokeStrategy != nil && existingInvokeStrategy.ExecutionStrategy.ExecutorType == types.ExecutorTypePoolmgr {
+			isolation = existingInvokeStrategy.ExecutionStrategy.Isolation
+			prespecialized = existingInvokeStrategy.ExecutionStrategy.PrespecializedPods
+		}
+		if c.IsSet("isolation") {
+			isolation = fv1.IsolationMode(c.String("isolation"))
+			if isolation != fv1.IsolationModeShared && isolation != fv1.IsolationModeInvocation {
+				return nil, errors.New("isolation must be one of 'shared' or 'invocation'")
+			}
+			if isolation == fv1.IsolationModeShared {
+				isolation = ""
+				prespecialized = 0
+			}
+		}
+		if c.IsSet("prespecialized") {
+			prespecialized = c.Int("prespecialized")
+			if prespecialized < 0 {
+				return nil, errors.New("prespecialized must be greater or equal to 0")
+			}
+		}
+		if prespecialized > 0 && isolation != fv1.IsolationModeInvocation {
+			return nil, errors.New("prespecialized flag is only applicable for functions with --isolation invocation")
+		}
+		if multiplex && isolation == fv1.IsolationModeInvocation {
+			return nil, errors.New("a function isolated per invocation cannot be multiplexed")
+		}
+
 		strategy = &fv1.InvokeStrategy{
 			StrategyType: fv1.StrategyTypeExecution,
 			ExecutionStrategy: fv1.ExecutionStrategy{
-				ExecutorType: types.ExecutorTypePoolmgr,
+				ExecutorType:       types.ExecutorTypePoolmgr,
+				Multiplex:          multiplex,
+				Isolation:          isolation,
+				PrespecializedPods: prespecialized,
 			},
 		}
 	} else {
@@ -130,7 +213,7 @@ func getInvokeStrategy(c *cli.Context, existingInvokeStrategy *fv1.InvokeStrateg
 		maxScale := minScale
 		specializationTimeout := fv1.DefaultSpecializationTimeOut
 
-		if existingInvokeStrategy != nil && existingInvokeStrategy.ExecutionStrategy.ExecutorType == types.ExecutorTypeNewdeploy {
+		if existingInvokeStrategy != nil && existingInvokeStrategy.ExecutionStrategy.ExecutorType != types.ExecutorTypePoolmgr {
 			minScale = existingInvokeStrategy.ExecutionStrategy.MinScale
 			maxScale = existingInvokeStrategy.ExecutionStrategy.MaxScale
 			targetCPU = existingInvokeStrategy.ExecutionStrategy.TargetCPUPercent
@@ -180,6 +263,31 @@ func getInvokeStrategy(c *cli.Context, existingInvokeStrategy *fv1.InvokeStrateg
 	return strategy, nil
 }
 
+// makeContainerPodSpec returns the pod spec of a function running the given
+// image, serving requests on the given port.
+func makeContainerPodSpec(fnName string, image string, port int) (*apiv1.PodSpec, error) {
+	if len(image) == 0 {
+		return nil, errors.New("need --image argument")
+	}
+	if port <= 0 || port > 65535 {
+		return nil, fmt.Errorf("port %v is not valid", port)
+	}
+	return &apiv1.PodSpec{
+		Containers: []apiv1.Container{
+			{
+				Name:  fnName,
+				Image: image,
+				Ports: []apiv1.ContainerPort{
+					{
+						Name:          "http-env",
+						ContainerPort: int32(port),
+					},
+				},
+			},
+		},
+	}, nil
+}
+
 func getTargetCPU(c *cli.Context) int {
 	var targetCPU int
 	if c.IsSet("targetcpu") {
@@ -193,6 +301,59 @@ func getTargetCPU(c *cli.Context) int {
 	return targetCPU
 }
 
+// getCodeFile returns the path of the function code given with --code or
+// --code-literal. Inline code and code read from stdin (--code -) are
+// written to a temporary file named --code-name, or after the function, so
+// they can be uploaded like any other code file. The returned func removes
+// the temporary file once it's uploaded.
+func getCodeFile(c *cli.Context, fnName string, toSpec bool) (string, func()) {
+	code := c.String("code")
+	literal := c.String("code-literal")
+	if len(code) > 0 && len(literal) > 0 {
+		log.Fatal("--code and --code-literal can't be used together")
+	}
+
+	var content []byte
+	switch {
+	case len(literal) > 0:
+		content = []byte(literal)
+	case code == "-":
+		var err error
+		content, err = ioutil.ReadAll(os.Stdin)
+		util.CheckErr(err, "read code from stdin")
+		if len(content) == 0 {
+			log.Fatal("No code read from stdin")
+		}
+	default:
+		return code, func() {}
+	}
+
+	if toSpec {
+		log.Fatal("--code-literal and --code - can't be used with --spec, specs need a code file in the project")
+	}
+
+	// the runtimes may load the code by its file name, e.g. by extension
+	codeName := c.String("code-name")
+	if len(codeName) == 0 {
+		codeName = fnName
+	} else if filepath.Base(codeName) != codeName {
+		log.Fatal("--code-name must be a file name, not a path")
+	}
+
+	dir, err := ioutil.TempDir("", "fission-code-")
+	util.CheckErr(err, "create temporary directory for code")
+	cleanup := func() {
+		os.RemoveAll(dir)
+	}
+	codeFile := filepath.Join(dir, codeName)
+	err = ioutil.WriteFile(codeFile, content, 0644)
+	if err != nil {
+		cleanup()
+		util.CheckErr(err, "write code to temporary file")
+	}
+	return codeFile, cleanup
+}
+
 // From this change onwards, we mandate that a function should reference a secret, config map and package in its own ns
 func fnCreate(c *cli.Context) error {
 	client := util.GetApiClient(c.GlobalString("server"))
@@ -212,15 +373,62 @@ func fnCreate(c *cli.Context) error {
 		toSpec = true
 		specFile = fmt.Sprintf("function-%v.yaml", fnName)
 	}
+	if toSpec && c.Bool("update-if-exists") {
+		log.Fatal("--update-if-exists can't be used with --spec, use 'fission spec apply' to update specs.")
+	}
 	specDir := cmdutils.GetSpecDir(urfavecli.Parse(c))
 
-	// check for unique function names within a namespace
-	fnList, err := client.FunctionList(fnNamespace)
-	util.CheckErr(err, "get function list")
+	// check for unique function names within a namespace, specs are
+	// checked against the spec directory instead of the cluster
+	var fnList []fv1.Function
+	if toSpec {
+		fr, err := readSpecs(specDir)
+		util.CheckErr(err, "read specs")
+		fnList = fr.Functions
+	} else {
+		var err error
+		fnList, err = client.FunctionList(fnNamespace)
+		util.CheckErr(err, "get function list")
+	}
 	// check function existence before creating package
 	for _, fn := range fnList {
-		if fn.Metadata.Name == fnName {
-			log.Fatal("A function with the same name already exists.")
+		if fn.Metadata.Name == fnName && fn.Metadata.Namespace == fnNamespace {
+			if !c.Bool("update-if-exists") {
+				log.Fatal("A function with the same name already exists.")
+			}
+			// routes are only created along with new functions
+			if len(c.String("url")) > 0 {
+				log.Warn("Function exists, --url is ignored; use 'fission httptrigger' to manage its routes.")
+			}
+			return fnUpdate(c)
+		}
+	}
+
+	// the configuration of the inherited function is used as the base,
+	// flags given on the command line take precedence
+	inherited := getInheritedFunction(c, client, specDir, toSpec)
+
+	// Allow the user to specify HTTP triggers while creating a function,
+	// check their names before creating anything.
+	routes, err := makeFunctionRoutes(c, fnName, fnNamespace)
+	util.CheckErr(err, "parse route")
+	for _, ht := range routes {
+		var exists bool
+		if toSpec {
+			fr, err := readSpecs(specDir)
+			util.CheckErr(err, "read specs")
+			for _, t := range fr.HttpTriggers {
+				exists = exists || (t.Metadata.Name == ht.Metadata.Name && t.Metadata.Namespace == ht.Metadata.Namespace)
+			}
+		} else {
+			_, err := client.HTTPTriggerGet(&ht.Metadata)
+			if err != nil && !ferror.IsNotFound(err) {
+				util.CheckErr(err, "get HTTP trigger")
+			}
+			exists = err == nil
+		}
+		if exists {
+			log.Fatal(fmt.Sprintf("An HTTP trigger named '%v' already exists, choose a different name with --route-name.", ht.Metadata.Name))
 		}
 	}
 	entrypoint := c.String("entrypoint")
@@ -230,6 +438,19 @@ func fnCreate(c *cli.Context) error {
 		log.Fatal("fntimeout must be greater than 0")
 	}
 
+	concurrency := c.Int("concurrency")
+	if concurrency < 0 {
+		log.Fatal("concurrency must not be negative")
+	}
+	queueLength := c.Int("queuelength")
+	if queueLength < 0 {
+		log.Fatal("queuelength must not be negative")
+	}
+	idleTimeout := c.Int("idletimeout")
+	if idleTimeout < 0 {
+		log.Fatal("idletimeout must not be negative")
+	}
+
 	pkgName := c.String("pkg")
 
 	secretNames := c.StringSlice("secret")
@@ -239,20 +460,76 @@ func fnCreate(c *cli.Context) error {
 	if err != nil {
 		log.Fatal(err)
 	}
-	resourceReq, err := cmd.GetResourceReqs(urfavecli.Parse(c), &apiv1.ResourceRequirements{})
+	baseResources := &apiv1.ResourceRequirements{}
+	if inherited != nil {
+		baseResources = inherited.Spec.Resources.DeepCopy()
+	}
+	resourceReq, err := cmd.GetResourceReqs(urfavecli.Parse(c), baseResources)
 	if err != nil {
 		log.Fatal(err)
 	}
 
-	var pkgMetadata *metav1.ObjectMeta
+	image := c.String("image")
+	isContainer := invokeStrategy.ExecutionStrategy.ExecutorType == types.ExecutorTypeContainer
+	if isContainer != (len(image) > 0) {
+		log.Fatal("--image must be given for, and only for, the 'container' executor type")
+	}
+
+	fromImage := c.String("from-image")
+	if len(fromImage) > 0 && isContainer {
+		log.Fatal("--image and --from-image can not be used together")
+	}
+	if len(c.String("image-code-path")) > 0 && len(fromImage) == 0 {
+		log.Fatal("--image-code-path can only be used with --from-image")
+	}
+
+	var podSpec *apiv1.PodSpec
+	var prebuiltImage *fv1.PrebuiltImage
+	pkgMetadata := &metav1.ObjectMeta{}
 	var envName string
-	if len(pkgName) > 0 {
+	if isContainer {
+		// the image is run as it is, without environment or package
+		if len(pkgName) > 0 || len(c.String("env")) > 0 || len(c.StringSlice("src")) > 0 ||
+			len(c.StringSlice("deploy")) > 0 || len(c.String("code")) > 0 || len(c.String("code-literal")) > 0 {
+			log.Fatal("--env, --pkg, --code, --src and --deploy can not be used with --image")
+		}
+		podSpec, err = makeContainerPodSpec(fnName, image, c.Int("port"))
+		if err != nil {
+			log.Fatal(err)
+		}
+		envNamespace = ""
+	} else if len(fromImage) > 0 {
+		// the code is baked into the image, there's no package
+		if len(pkgName) > 0 || len(c.StringSlice("src")) > 0 || len(c.StringSlice("deploy")) > 0 ||
+			len(c.String("code")) > 0 || len(c.String("code-literal")) > 0 || len(c.String("buildcmd")) > 0 {
+			log.Fatal("--pkg, --code, --src, --deploy and --buildcmd can not be used with --from-image")
+		}
+		if invokeStrategy.ExecutionStrategy.ExecutorType != types.ExecutorTypeNewdeploy {
+			log.Fatal("--from-image needs the 'newdeploy' executor type")
+		}
+		envName = c.String("env")
+		if len(envName) == 0 {
+			log.Fatal("Need --env argument.")
+		}
+		if !toSpec {
+			checkEnvironmentExists(client, envName, envNamespace)
+		}
+		prebuiltImage = &fv1.PrebuiltImage{
+			Image: fromImage,
+			Path:  c.String("image-code-path"),
+		}
+	} else if len(pkgName) > 0 {
 		// use existing package
-		pkg, err := client.PackageGet(&metav1.ObjectMeta{
-			Namespace: fnNamespace,
-			Name:      pkgName,
-		})
-		util.CheckErr(err, fmt.Sprintf("read package in '%v' in Namespace: %s. Package needs to be present in the same namespace as function", pkgName, fnNamespace))
+		var pkg *fv1.Package
+		if toSpec {
+			pkg = getSpecPackage(specDir, fnNamespace, pkgName)
+		} else {
+			pkg, err = client.PackageGet(&metav1.ObjectMeta{
+				Namespace: fnNamespace,
+				Name:      pkgName,
+			})
+			util.CheckErr(err, fmt.Sprintf("read package in '%v' in Namespace: %s. Package needs to be present in the same namespace as function", pkgName, fnNamespace))
+		}
 		pkgMetadata = &pkg.Metadata
 		envName = pkg.Spec.Environment.Name
 		if envName != c.String("env") {
@@ -268,27 +545,18 @@ func fnCreate(c *cli.Context) error {
 
 		// examine existence of given environment. If specs - then spec validate will do it, don't check here.
 		if !toSpec {
-			_, err := client.EnvironmentGet(&metav1.ObjectMeta{
-				Namespace: envNamespace,
-				Name:      envName,
-			})
-			if err != nil {
-				if e, ok := err.(ferror.Error); ok && e.Code == ferror.ErrorNotFound {
-					log.Warn(fmt.Sprintf("Environment \"%v\" does not exist. Please create the environment before executing the function. \nFor example: `fission env create --name %v --envns %v --image <image>`\n", envName, envName, envNamespace))
-				} else {
-					util.CheckErr(err, "retrieve environment information")
-				}
-			}
+			checkEnvironmentExists(client, envName, envNamespace)
 		}
 
 		srcArchiveFiles := c.StringSlice("src")
 		var deployArchiveFiles []string
 		noZip := false
-		code := c.String("code")
+		code, cleanup := getCodeFile(c, fnName, toSpec)
+		defer cleanup()
 		if len(code) == 0 {
 			deployArchiveFiles = c.StringSlice("deploy")
 		} else {
-			deployArchiveFiles = append(deployArchiveFiles, c.String("code"))
+			deployArchiveFiles = append(deployArchiveFiles, code)
 			noZip = true
 		}
 		// fatal when both src & deploy archive are empty
@@ -307,13 +575,16 @@ func fnCreate(c *cli.Context) error {
 
 	if len(secretNames) > 0 {
 		// check the referenced secret is in the same ns as the function, if not give a warning.
-		for _, secretName := range secretNames {
-			_, err := client.SecretGet(&metav1.ObjectMeta{
-				Namespace: fnNamespace,
-				Name:      secretName,
-			})
-			if k8serrors.IsNotFound(err) {
-				log.Warn(fmt.Sprintf("Secret %s not found in Namespace: %s. Secret needs to be present in the same namespace as function", secretName, fnNamespace))
+		// If specs - then spec validate will do it, don't check here.
+		if !toSpec {
+			for _, secretName := range secretNames {
+				_, err := client.SecretGet(&metav1.ObjectMeta{
+					Namespace: fnNamespace,
+					Name:      secretName,
+				})
+				if k8serrors.IsNotFound(err) {
+					log.Warn(fmt.Sprintf("Secret %s not found in Namespace: %s. Secret needs to be present in the same namespace as function", secretName, fnNamespace))
+				}
 			}
 		}
 		for _, secretName := range secretNames {
@@ -327,13 +598,16 @@ func fnCreate(c *cli.Context) error {
 
 	if len(cfgMapNames) > 0 {
 		// check the referenced cfgmap is in the same ns as the function, if not give a warning.
-		for _, cfgMapName := range cfgMapNames {
-			_, err := client.ConfigMapGet(&metav1.ObjectMeta{
-				Namespace: fnNamespace,
-				Name:      cfgMapName,
-			})
-			if k8serrors.IsNotFound(err) {
-				log.Warn(fmt.Sprintf("ConfigMap %s not found in Namespace: %s. ConfigMap needs to be present in the same namespace as function", cfgMapName, fnNamespace))
+		// If specs - then spec validate will do it, don't check here.
+		if !toSpec {
+			for _, cfgMapName := range cfgMapNames {
+				_, err := client.ConfigMapGet(&metav1.ObjectMeta{
+					Namespace: fnNamespace,
+					Name:      cfgMapName,
+				})
+				if k8serrors.IsNotFound(err) {
+					log.Warn(fmt.Sprintf("ConfigMap %s not found in Namespace: %s. ConfigMap needs to be present in the same namespace as function", cfgMapName, fnNamespace))
+				}
 			}
 		}
 		for _, cfgMapName := range cfgMapNames {
@@ -363,60 +637,147 @@ func fnCreate(c *cli.Context) error {
 					ResourceVersion: pkgMetadata.ResourceVersion,
 				},
 			},
-			Secrets:         secrets,
-			ConfigMaps:      cfgmaps,
-			Resources:       *resourceReq,
-			InvokeStrategy:  *invokeStrategy,
-			FunctionTimeout: fnTimeout,
+			PodSpec:            podSpec,
+			PrebuiltImage:      prebuiltImage,
+			Secrets:            secrets,
+			ConfigMaps:         cfgmaps,
+			Resources:          *resourceReq,
+			InvokeStrategy:     *invokeStrategy,
+			FunctionTimeout:    fnTimeout,
+			Concurrency:        concurrency,
+			RequestQueueLength: queueLength,
+			IdleTimeout:        idleTimeout,
 		},
 	}
 
-	// if we're writing a spec, don't create the function
+	if inherited != nil && !copyFunctionConfig(inherited, function) {
+		log.Warn(fmt.Sprintf("Environment variables are only copied between container functions, the ones of '%v' are not inherited", inherited.Metadata.Name))
+	}
+
+	err = updateFunctionPodsWithCmd(c, function)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	// if we're writing a spec, don't create the function or the triggers
 	if toSpec {
 		err = spec.SpecSave(*function, specFile)
 		util.CheckErr(err, "create function spec")
-		return nil
+		for _, ht := range routes {
+			err = spec.SpecSave(ht, specFile)
+			util.CheckErr(err, "create HTTP trigger spec")
+		}
 
+		// make sure the spec directory can be applied as it is
+		fr, err := readSpecs(specDir)
+		util.CheckErr(err, "read specs")
+		err = fr.Validate(c)
+		util.CheckErr(err, "validate specs")
+		return nil
 	}
 
-	_, err = client.FunctionCreate(function)
+	fnMeta, err := client.FunctionCreate(function)
 	util.CheckErr(err, "create function")
 
-	fmt.Printf("function '%v' created\n", fnName)
+	fmt.Printf("function '%v' created (uid %v)\n", fnName, fnMeta.UID)
+
+	for i := range routes {
+		ht := &routes[i]
+		htMeta, err := client.HTTPTriggerCreate(ht)
+		util.CheckErr(err, "create HTTP trigger")
+		fmt.Printf("route '%v' created (uid %v): %v %v -> %v\n",
+			ht.Metadata.Name, htMeta.UID, strings.Join(ht.Spec.GetMethods(), ","), ht.Spec.RelativeURL, fnName)
+	}
+
+	return nil
+}
+
+// checkEnvironmentExists warns if the environment of a new function
+// doesn't exist yet.
+func checkEnvironmentExists(client *client.Client, envName string, envNamespace string) {
+	_, err := client.EnvironmentGet(&metav1.ObjectMeta{
+		Namespace: envNamespace,
+		Name:      envName,
+	})
+	if err != nil {
+		if e, ok := err.(ferror.Error); ok && e.Code == ferror.ErrorNotFound {
+			log.Warn(fmt.Sprintf("Environment \"%v\" does not exist. Please create the environment before executing the function. \nFor example: `fission env create --name %v --envns %v --image <image>`\n", envName, envName, envNamespace))
+		} else {
+			util.CheckErr(err, "retrieve environment information")
+		}
+	}
+}
+
+// fnRunContainer creates a function running the given container image,
+// without environment or package.
+func fnRunContainer(c *cli.Context) error {
+	if len(c.String("image")) == 0 {
+		log.Fatal("Need --image argument.")
+	}
+	return fnCreate(c)
+}
 
-	// Allow the user to specify an HTTP trigger while creating a function.
+// makeFunctionRoutes returns the HTTP trigger to create along with a
+// function, serving the methods given with --method. The trigger is named
+// by --route-name, or after the function.
+func makeFunctionRoutes(c *cli.Context, fnName string, fnNamespace string) ([]fv1.HTTPTrigger, error) {
 	triggerUrl := c.String("url")
 	if len(triggerUrl) == 0 {
-		return nil
+		// --method alone is allowed, it used to be ignored without --url
+		for _, flag := range []string{"route-name", "createingress", "ingressrule", "ingressannotation", "ingresstls"} {
+			if c.IsSet(flag) {
+				return nil, fmt.Errorf("--%v requires a route, use --url", flag)
+			}
+		}
+		return nil, nil
 	}
 	if !strings.HasPrefix(triggerUrl, "/") {
 		triggerUrl = fmt.Sprintf("/%s", triggerUrl)
 	}
 
-	method := c.String("method")
-	if len(method) == 0 {
-		method = http.MethodGet
+	routeName := c.String("route-name")
+	if len(routeName) == 0 {
+		routeName = fnName
 	}
-	triggerName := uuid.NewV4().String()
-	ht := &fv1.HTTPTrigger{
+
+	ingressConfig, err := httptrigger.GetIngressConfig(
+		c.StringSlice("ingressannotation"), c.String("ingressrule"),
+		c.String("ingresstls"), triggerUrl, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	route := fv1.HTTPTrigger{
 		Metadata: metav1.ObjectMeta{
-			Name:      triggerName,
+			Name:      routeName,
 			Namespace: fnNamespace,
 		},
 		Spec: fv1.HTTPTriggerSpec{
 			RelativeURL: triggerUrl,
-			Method:      getMethod(method),
 			FunctionReference: fv1.FunctionReference{
 				Type: fv1.FunctionReferenceTypeFunctionName,
 				Name: fnName,
 			},
+			CreateIngress: c.Bool("createingress"),
+			IngressConfig: *ingressConfig,
 		},
 	}
-	_, err = client.HTTPTriggerCreate(ht)
-	util.CheckErr(err, "create HTTP trigger")
-	fmt.Printf("route created: %v %v -> %v\n", method, triggerUrl, fnName)
+	setMethods(&route.Spec, getMethods(c))
+	return []fv1.HTTPTrigger{route}, nil
+}
 
-	return err
+// getSpecPackage returns the package with the given name from the specs, a
+// function spec can't reference a package that is only on the cluster.
+func getSpecPackage(specDir string, namespace string, name string) *fv1.Package {
+	fr, err := readSpecs(specDir)
+	util.CheckErr(err, "read specs")
+	for i := range fr.Packages {
+		if fr.Packages[i].Metadata.Name == name && fr.Packages[i].Metadata.Namespace == namespace {
+			return &fr.Packages[i]
+		}
+	}
+	log.Fatal(fmt.Sprintf("Package %v not found in the specs in %v, a function spec needs a package in the same spec directory", name, specDir))
+	return nil
 }
 
 func fnGet(c *cli.Context) error {
@@ -434,6 +795,10 @@ func fnGet(c *cli.Context) error {
 	fn, err := client.FunctionGet(m)
 	util.CheckErr(err, "get function")
 
+	if fn.Spec.PrebuiltImage != nil {
+		log.Fatal(fmt.Sprintf("function '%v' has no package, its code is in the image %v", fnName, fn.Spec.PrebuiltImage.Image))
+	}
+
 	pkg, err := client.PackageGet(&metav1.ObjectMeta{
 		Name:      fn.Spec.Package.PackageRef.Name,
 		Namespace: fn.Spec.Package.PackageRef.Namespace,
@@ -507,11 +872,12 @@ func fnUpdate(c *cli.Context) error {
 
 	var deployArchiveFiles []string
 	codeFlag := false
-	code := c.String("code")
+	code, cleanup := getCodeFile(c, fnName, false)
+	defer cleanup()
 	if len(code) == 0 {
 		deployArchiveFiles = c.StringSlice("deploy")
 	} else {
-		deployArchiveFiles = append(deployArchiveFiles, c.String("code"))
+		deployArchiveFiles = append(deployArchiveFiles, code)
 		codeFlag = true
 	}
 
@@ -519,6 +885,7 @@ func fnUpdate(c *cli.Context) error {
 	pkgName := c.String("pkg")
 	entrypoint := c.String("entrypoint")
 	buildcmd := c.String("buildcmd")
+	gitSecret := c.String("git-secret")
 	force := c.Bool("force")
 
 	secretName := c.String("secret")
@@ -591,6 +958,30 @@ func fnUpdate(c *cli.Context) error {
 		function.Spec.FunctionTimeout = fnTimeout
 	}
 
+	if c.IsSet("concurrency") {
+		concurrency := c.Int("concurrency")
+		if concurrency < 0 {
+			log.Fatal("concurrency must not be negative")
+		}
+		function.Spec.Concurrency = concurrency
+	}
+
+	if c.IsSet("queuelength") {
+		queueLength := c.Int("queuelength")
+		if queueLength < 0 {
+			log.Fatal("queuelength must not be negative")
+		}
+		function.Spec.RequestQueueLength = queueLength
+	}
+
+	if c.IsSet("idletimeout") {
+		idleTimeout := c.Int("idletimeout")
+		if idleTimeout < 0 {
+			log.Fatal("idletimeout must not be negative")
+		}
+		function.Spec.IdleTimeout = idleTimeout
+	}
+
 	if len(pkgName) == 0 {
 		pkgName = function.Spec.Package.PackageRef.Name
 	}
@@ -620,6 +1011,66 @@ func fnUpdate(c *cli.Context) error {
 
 	function.Spec.Resources = *resReqs
 
+	err = updateFunctionPodsWithCmd(c, function)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	if function.Spec.InvokeStrategy.ExecutionStrategy.ExecutorType == types.ExecutorTypeContainer {
+		if len(pkgName) > 0 && pkgName != function.Spec.Package.PackageRef.Name || len(envName) > 0 ||
+			len(deployArchiveFiles) > 0 || len(srcArchiveFiles) > 0 || len(buildcmd) > 0 {
+			log.Fatal("--env, --pkg, --code, --src, --deploy and --buildcmd can not be used with functions of the 'container' executor type")
+		}
+		if function.Spec.PodSpec == nil || len(function.Spec.PodSpec.Containers) == 0 {
+			function.Spec.PodSpec = &apiv1.PodSpec{Containers: []apiv1.Container{{Name: fnName}}}
+		}
+		container := &function.Spec.PodSpec.Containers[0]
+		if c.IsSet("image") {
+			container.Image = c.String("image")
+		}
+		if c.IsSet("port") {
+			spec, err := makeContainerPodSpec(fnName, container.Image, c.Int("port"))
+			if err != nil {
+				log.Fatal(err)
+			}
+			container.Ports = spec.Containers[0].Ports
+		}
+
+		_, err = client.FunctionUpdate(function)
+		util.CheckErr(err, "update function")
+
+		fmt.Printf("function '%v' updated\n", fnName)
+		return err
+	}
+
+	if function.Spec.PrebuiltImage != nil {
+		if len(pkgName) > 0 && pkgName != function.Spec.Package.PackageRef.Name ||
+			len(deployArchiveFiles) > 0 || len(srcArchiveFiles) > 0 || len(buildcmd) > 0 {
+			log.Fatal("--pkg, --code, --src, --deploy and --buildcmd can not be used with functions with a prebuilt image")
+		}
+		if len(envName) > 0 {
+			function.Spec.Environment.Name = envName
+		}
+		if len(envNamespace) > 0 {
+			function.Spec.Environment.Namespace = envNamespace
+		}
+		if c.IsSet("from-image") {
+			function.Spec.PrebuiltImage.Image = c.String("from-image")
+		}
+		if c.IsSet("image-code-path") {
+			function.Spec.PrebuiltImage.Path = c.String("image-code-path")
+		}
+
+		_, err = client.FunctionUpdate(function)
+		util.CheckErr(err, "update function")
+
+		fmt.Printf("function '%v' updated\n", fnName)
+		return err
+	}
+	if c.IsSet("from-image") || c.IsSet("image-code-path") {
+		log.Fatal("--from-image and --image-code-path can only be used with functions with a prebuilt image")
+	}
+
 	pkg, err := client.PackageGet(&metav1.ObjectMeta{
 		Namespace: fnNamespace,
 		Name:      pkgName,
@@ -628,7 +1079,7 @@ func fnUpdate(c *cli.Context) error {
 
 	pkgMetadata := &pkg.Metadata
 
-	if len(deployArchiveFiles) != 0 || len(srcArchiveFiles) != 0 || len(buildcmd) != 0 || len(envName) != 0 || len(envNamespace) != 0 {
+	if len(deployArchiveFiles) != 0 || len(srcArchiveFiles) != 0 || len(buildcmd) != 0 || len(envName) != 0 || len(envNamespace) != 0 || len(gitSecret) != 0 {
 		fnList, err := getFunctionsByPackage(client, pkg.Metadata.Name, pkg.Metadata.Namespace)
 		util.CheckErr(err, "get function list")
 
@@ -636,7 +1087,7 @@ func fnUpdate(c *cli.Context) error {
 			log.Fatal("Package is used by multiple functions, use --force to force update")
 		}
 
-		pkgMetadata, err = updatePackage(client, pkg, envName, envNamespace, srcArchiveFiles, deployArchiveFiles, buildcmd, false, codeFlag)
+		pkgMetadata, err = updatePackage(client, pkg, envName, envNamespace, srcArchiveFiles, deployArchiveFiles, buildcmd, gitSecret, false, codeFlag)
 		util.CheckErr(err, fmt.Sprintf("update package '%v'", pkgName))
 
 		fmt.Printf("package '%v' updated\n", pkgMetadata.GetName())
@@ -675,6 +1126,186 @@ func fnUpdate(c *cli.Context) error {
 	return err
 }
 
+// updateFunctionPodsWithCmd sets the labels, annotations, node selector,
+// tolerations, spread and volumes of the flags on the function, which the
+// executors apply to the pods of the function.
+func updateFunctionPodsWithCmd(c *cli.Context, function *fv1.Function) error {
+	flags := urfavecli.Parse(c)
+
+	err := cmd.UpdateMetadataWithCmd(flags, &function.Metadata)
+	if err != nil {
+		return err
+	}
+
+	if cmd.IsSchedulingSet(flags) {
+		executorType := function.Spec.InvokeStrategy.ExecutionStrategy.ExecutorType
+		if executorType != types.ExecutorTypeNewdeploy && executorType != types.ExecutorTypeContainer {
+			return errors.New("--nodeselector and --toleration are only applicable for the newdeploy and container executor types, use them on the environment of poolmgr functions")
+		}
+		function.Spec.NodeSelector, function.Spec.Tolerations, err = cmd.UpdateSchedulingWithCmd(
+			flags, function.Spec.NodeSelector, function.Spec.Tolerations)
+		if err != nil {
+			return err
+		}
+	}
+
+	if c.IsSet("spread") || c.IsSet("spread-required") {
+		spread, err := getSpread(c, function.Spec.Spread)
+		if err != nil {
+			return err
+		}
+		function.Spec.Spread = spread
+	}
+
+	if c.IsSet("volume") || c.IsSet("scratch-size") {
+		executorType := function.Spec.InvokeStrategy.ExecutionStrategy.ExecutorType
+		if executorType != types.ExecutorTypeNewdeploy && executorType != types.ExecutorTypeContainer {
+			return errors.New("--volume and --scratch-size are only applicable for the newdeploy and container executor types")
+		}
+		function.Spec.Volumes, err = updateVolumes(function.Spec.Volumes, c.StringSlice("volume"), c.String("scratch-size"))
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// updateVolumes returns a copy of the volumes updated with values of the
+// form "pvc:claim:/path[:ro]" or "emptydir:name:/path", which mount a
+// volume at the path, replacing the volume mounted there, or "name-",
+// which removes the volumes of the name. A scratch size sets the size of
+// the emptydir volumes given, or of a "scratch" emptydir volume mounted at
+// /scratch if there's none.
+func updateVolumes(volumes []fv1.FunctionVolume, values []string, scratchSize string) ([]fv1.FunctionVolume, error) {
+	result := make([]fv1.FunctionVolume, 0, len(volumes))
+	for _, v := range volumes {
+		result = append(result, *v.DeepCopy())
+	}
+
+	var scratchPaths []string
+	for _, value := range values {
+		if strings.HasSuffix(value, "-") && !strings.Contains(value, ":") {
+			name := strings.TrimSuffix(value, "-")
+			kept := result[:0]
+			for _, v := range result {
+				if v.Name != name {
+					kept = append(kept, v)
+				}
+			}
+			result = kept
+			continue
+		}
+
+		v, err := parseVolume(value)
+		if err != nil {
+			return nil, err
+		}
+
+		i := 0
+		for i < len(result) && result[i].MountPath != v.MountPath {
+			i++
+		}
+		if i == len(result) {
+			result = append(result, *v)
+		} else {
+			result[i] = *v
+		}
+		if v.Type == fv1.FunctionVolumeTypeEmptyDir {
+			scratchPaths = append(scratchPaths, v.MountPath)
+		}
+	}
+
+	if len(scratchSize) > 0 {
+		sizeLimit, err := resource.ParseQuantity(scratchSize)
+		if err != nil {
+			return nil, fmt.Errorf("invalid scratch size '%v': %v", scratchSize, err)
+		}
+		if len(scratchPaths) == 0 {
+			scratchPaths = append(scratchPaths, defaultScratchPath)
+			i := 0
+			for i < len(result) && result[i].MountPath != defaultScratchPath {
+				i++
+			}
+			if i == len(result) {
+				result = append(result, fv1.FunctionVolume{
+					Type:      fv1.FunctionVolumeTypeEmptyDir,
+					Name:      defaultScratchVolume,
+					MountPath: defaultScratchPath,
+				})
+			}
+		}
+		for i := range result {
+			for _, p := range scratchPaths {
+				if result[i].MountPath == p && result[i].Type == fv1.FunctionVolumeTypeEmptyDir {
+					result[i].SizeLimit = &sizeLimit
+				}
+			}
+		}
+	}
+
+	for _, v := range result {
+		err := v.Validate()
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if len(result) == 0 {
+		return nil, nil
+	}
+	return result, nil
+}
+
+func parseVolume(value string) (*fv1.FunctionVolume, error) {
+	parts := strings.Split(value, ":")
+	if len(parts) < 3 || len(parts) > 4 || (len(parts) == 4 && parts[3] != "ro") {
+		return nil, fmt.Errorf("'%v' is not of the form pvc:claim:/path[:ro], emptydir:name:/path or name-", value)
+	}
+
+	v := &fv1.FunctionVolume{
+		Type:      fv1.FunctionVolumeType(parts[0]),
+		Name:      parts[1],
+		MountPath: parts[2],
+		ReadOnly:  len(parts) == 4,
+	}
+	err := v.Validate()
+	if err != nil {
+		return nil, err
+	}
+	return v, nil
+}
+
+// getSpread returns the spread of the --spread and --spread-required
+// flags merged into the existing one, or nil if it's removed with
+// --spread none.
+func getSpread(c *cli.Context, existing *fv1.Spread) (*fv1.Spread, error) {
+	spread := &fv1.Spread{}
+	if existing != nil {
+		spread = existing.DeepCopy()
+	}
+
+	if c.IsSet("spread") {
+		topology := c.String("spread")
+		if topology == "none" {
+			return nil, nil
+		}
+		spread.Topology = fv1.SpreadTopology(topology)
+	} else if existing == nil {
+		return nil, errors.New("--spread-required needs --spread to set the topology, node or zone")
+	}
+
+	if c.IsSet("spread-required") {
+		spread.Required = c.Bool("spread-required")
+	}
+
+	err := spread.Validate()
+	if err != nil {
+		return nil, err
+	}
+	return spread, nil
+}
+
 func fnDelete(c *cli.Context) error {
 	client := util.GetApiClient(c.GlobalString("server"))
 
@@ -700,7 +1331,7 @@ func fnList(c *cli.Context) error {
 	client := util.GetApiClient(c.GlobalString("server"))
 	ns := c.String("fnNamespace")
 
-	fns, err := client.FunctionList(ns)
+	fns, err := client.FunctionListWithOptions(ns, listOptions(c))
 	util.CheckErr(err, "list functions")
 
 	w := tabwriter.NewWriter(os.Stdout, 0, 0, 1, ' ', 0)
@@ -735,9 +1366,32 @@ func fnLogs(c *cli.Context) error {
 	}
 	fnNamespace := c.String("fnNamespace")
 
+	// the logs of the previous containers of crash looping pods are read
+	// from the pods, they may never reach the log database
+	if c.Bool("previous") {
+		if c.Bool("f") {
+			log.Fatal("--previous can't be used with --follow")
+		}
+		err := printPodLogs(c)
+		util.CheckErr(err, "get logs of the previous containers")
+		return nil
+	}
+
+	// controllers older than the log database discovery only proxy influxdb
+	dbInfo, err := client.LogDBList()
+	if err != nil {
+		dbInfo = &types.LogDBInfo{Default: logdb.INFLUXDB, Types: []string{logdb.INFLUXDB}}
+	}
 	dbType := c.String("dbtype")
 	if len(dbType) == 0 {
-		dbType = logdb.INFLUXDB
+		dbType = dbInfo.Default
+	}
+	supported := false
+	for _, t := range dbInfo.Types {
+		supported = supported || t == dbType
+	}
+	if !supported {
+		log.Fatal(fmt.Sprintf("Log database type %q is not available, the server supports %v", dbType, strings.Join(dbInfo.Types, ", ")))
 	}
 
 	fnPod := c.String("pod")
@@ -753,14 +1407,25 @@ func fnLogs(c *cli.Context) error {
 		recordLimit = 1000
 	}
 
+	output := c.String("output")
+	if c.Bool("d") {
+		output = logOutputDetail
+	}
+	err = checkLogOutput(output)
+	util.CheckErr(err, "check output format")
+
+	fieldFilters, err := parseLogFieldFilters(c.StringSlice("field"))
+	util.CheckErr(err, "parse field filters")
+
+	patterns, err := getLogPatterns(c.String("reqid"), c.String("grep"), c.String("regex"), fieldFilters)
+	util.CheckErr(err, "parse log filters")
+
 	f, err := client.FunctionGet(m)
 	util.CheckErr(err, "get function")
 
 	// request the controller to establish a proxy server to the database.
 	logDB, err := logdb.GetLogDB(dbType, util.GetServerUrl())
-	if err != nil {
-		log.Fatal("failed to connect log database")
-	}
+	util.CheckErr(err, "connect log database")
 
 	requestChan := make(chan struct{})
 	responseChan := make(chan struct{})
@@ -775,22 +1440,30 @@ func fnLogs(c *cli.Context) error {
 					Pod:         fnPod,
 					Function:    f.Metadata.Name,
 					FuncUid:     string(f.Metadata.UID),
+					Namespace:   f.Metadata.Namespace,
 					Since:       t,
 					Reverse:     logReverseQuery,
 					RecordLimit: recordLimit,
+					Patterns:    patterns,
 				}
 				logEntries, err := logDB.GetLogs(logFilter)
 				if err != nil {
 					log.Fatal(fmt.Sprintf("Error querying logs: %v", err))
 				}
 				for _, logEntry := range logEntries {
-					if c.Bool("d") {
-						fmt.Printf("Timestamp: %s\nNamespace: %s\nFunction Name: %s\nFunction ID: %s\nPod: %s\nContainer: %s\nStream: %s\nLog: %s\n---\n",
-							logEntry.Timestamp, logEntry.Namespace, logEntry.FuncName, logEntry.FuncUid, logEntry.Pod, logEntry.Container, logEntry.Stream, logEntry.Message)
-					} else {
-						fmt.Printf("[%s] %s\n", logEntry.Timestamp, logEntry.Message)
-					}
 					t = logEntry.Timestamp
+
+					var fields map[string]interface{}
+					if len(fieldFilters) > 0 || output == logOutputJSON {
+						fields = parseLogMessage(logEntry.Message)
+					}
+					if len(fieldFilters) > 0 && !matchLogFields(fields, fieldFilters) {
+						continue
+					}
+					err = printLogEntry(os.Stdout, output, logEntry, fields)
+					if err != nil {
+						log.Fatal(fmt.Sprintf("Error printing logs: %v", err))
+					}
 				}
 				responseChan <- struct{}{}
 			case <-ctx.Done():
@@ -811,31 +1484,69 @@ func fnLogs(c *cli.Context) error {
 }
 
 func fnTest(c *cli.Context) error {
+	if c.Bool("local") {
+		return fnTestLocal(c)
+	}
+
 	fnName := c.String("name")
-	if len(fnName) == 0 {
-		log.Fatal("Need function name to be specified with --name")
+	aliasName := c.String("alias")
+	if len(fnName) == 0 && len(aliasName) == 0 {
+		log.Fatal("Need function name to be specified with --name, or a function alias with --alias")
+	}
+	if len(fnName) > 0 && len(aliasName) > 0 {
+		log.Fatal("--name and --alias can't be used together")
 	}
 	ns := c.String("fnNamespace")
 
-	routerURL := os.Getenv("FISSION_ROUTER")
-	if len(routerURL) == 0 {
-		// Portforward to the fission router
-		localRouterPort := util.SetupPortForward(util.GetFissionNamespace(),
-			"application=fission-router")
-		routerURL = "127.0.0.1:" + localRouterPort
-	} else {
-		routerURL = strings.TrimPrefix(routerURL, "http://")
-	}
+	routerURL := getRouterURL()
 
-	fnUri := fnName
-	if ns != metav1.NamespaceDefault {
-		fnUri = fmt.Sprintf("%v/%v", ns, fnName)
+	// the router resolves an alias to the functions it points to
+	fnPath := utils.UrlForFunction(fnName, ns)
+	if len(aliasName) > 0 {
+		fnPath = utils.UrlForFunctionAlias(aliasName, ns)
 	}
 
-	functionUrl, err := url.Parse(fmt.Sprintf("http://%s/fission-function/%s", routerURL, fnUri))
+	functionUrl, err := url.Parse(fmt.Sprintf("http://%s%s", routerURL, fnPath))
 	if err != nil {
 		log.Fatal(err)
 	}
+
+	ctx := context.Background()
+	if deadline := c.Duration("timeout"); deadline > 0 {
+		var closeCtx func()
+		ctx, closeCtx = context.WithTimeout(ctx, deadline)
+		defer closeCtx()
+	}
+
+	resp := doTestRequest(ctx, c, functionUrl)
+	if resp.StatusCode < 400 {
+		respBody, err := ioutil.ReadAll(resp.Body)
+		util.CheckErr(err, "Function test")
+		fmt.Print(string(respBody))
+		defer resp.Body.Close()
+		return nil
+	}
+
+	respBody, err := ioutil.ReadAll(resp.Body)
+	util.CheckErr(err, "read log response from pod")
+	defer resp.Body.Close()
+	if len(aliasName) > 0 {
+		// the alias may point to several functions, see their logs with fn logs
+		fmt.Printf("Error calling function alias %s: %d; Please try again or fix the error: %s", aliasName, resp.StatusCode, string(respBody))
+		return nil
+	}
+	fmt.Printf("Error calling function %s: %d; Please try again or fix the error: %s", fnName, resp.StatusCode, string(respBody))
+	err = printPodLogs(c)
+	if err != nil {
+		fnLogs(c)
+	}
+
+	return nil
+}
+
+// doTestRequest sends the request described by the flags of fn test to the
+// function URL.
+func doTestRequest(ctx context.Context, c *cli.Context, functionUrl *url.URL) *http.Response {
 	queryParams := c.StringSlice("query")
 	if len(queryParams) > 0 {
 		query := url.Values{}
@@ -856,37 +1567,109 @@ func fnTest(c *cli.Context) error {
 		functionUrl.RawQuery = query.Encode()
 	}
 
-	ctx := context.Background()
-	if deadline := c.Duration("timeout"); deadline > 0 {
-		var closeCtx func()
-		ctx, closeCtx = context.WithTimeout(ctx, deadline)
-		defer closeCtx()
+	bodyArg, binary := c.String("body"), false
+	if c.IsSet("body-binary") {
+		if c.IsSet("body") {
+			log.Fatal("--body and --body-binary can't be used together")
+		}
+		bodyArg, binary = c.String("body-binary"), true
 	}
+	body, bodySize, bodyType, err := getRequestBody(bodyArg, binary)
+	util.CheckErr(err, "read request body")
+	defer body.Close()
 
 	headers := c.StringSlice("header")
+	if contentType := c.String("content-type"); len(contentType) > 0 {
+		headers = append(headers, fmt.Sprintf("Content-Type:%v", contentType))
+	} else if len(bodyType) > 0 && !hasHeader(headers, "Content-Type") {
+		headers = append(headers, fmt.Sprintf("Content-Type:%v", bodyType))
+	}
 
-	resp := doHTTPRequest(ctx, c.String("method"), functionUrl.String(), c.String("body"), headers)
-	if resp.StatusCode < 400 {
-		body, err := ioutil.ReadAll(resp.Body)
-		util.CheckErr(err, "Function test")
-		fmt.Print(string(body))
-		defer resp.Body.Close()
-		return nil
+	return doHTTPRequest(ctx, c.String("method"), functionUrl.String(), body, bodySize, headers)
+}
+
+// getRouterURL returns the host and port of the router, $FISSION_ROUTER or
+// a port forward to it.
+func getRouterURL() string {
+	routerURL := os.Getenv("FISSION_ROUTER")
+	if len(routerURL) == 0 {
+		// Portforward to the fission router
+		localRouterPort := util.SetupPortForward(util.GetFissionNamespace(),
+			"application=fission-router")
+		return "127.0.0.1:" + localRouterPort
 	}
+	return strings.TrimPrefix(routerURL, "http://")
+}
 
-	body, err := ioutil.ReadAll(resp.Body)
-	util.CheckErr(err, "read log response from pod")
-	fmt.Printf("Error calling function %s: %d; Please try again or fix the error: %s", fnName, resp.StatusCode, string(body))
-	defer resp.Body.Close()
-	err = printPodLogs(c)
+// getRequestBody returns the reader of the request body specified with --body
+// or --body-binary, its size, or -1 if the size is unknown, and its content
+// type if it can be told. Like curl, "@path" reads the body from a file and
+// "-" reads it from stdin, both are streamed as is instead of loaded into
+// memory. The content type of a file is guessed from its extension, binary
+// bodies are sniffed otherwise and default to application/octet-stream.
+func getRequestBody(body string, binary bool) (io.ReadCloser, int64, string, error) {
+	switch {
+	case body == "-":
+		var contentType string
+		if binary {
+			contentType = "application/octet-stream"
+		}
+		return ioutil.NopCloser(os.Stdin), -1, contentType, nil
+	case strings.HasPrefix(body, "@"):
+		f, err := os.Open(body[1:])
+		if err != nil {
+			return nil, 0, "", err
+		}
+		fi, err := f.Stat()
+		if err != nil {
+			f.Close()
+			return nil, 0, "", err
+		}
+		contentType := mime.TypeByExtension(filepath.Ext(body))
+		if len(contentType) == 0 && binary {
+			contentType, err = sniffContentType(f)
+			if err != nil {
+				f.Close()
+				return nil, 0, "", err
+			}
+		}
+		return f, fi.Size(), contentType, nil
+	default:
+		var contentType string
+		if binary {
+			contentType = http.DetectContentType([]byte(body))
+		}
+		return ioutil.NopCloser(strings.NewReader(body)), int64(len(body)), contentType, nil
+	}
+}
+
+// sniffContentType detects the content type of the file from its first
+// bytes and rewinds it.
+func sniffContentType(f *os.File) (string, error) {
+	buf := make([]byte, 512)
+	n, err := io.ReadFull(f, buf)
+	if err != nil && err != io.EOF && err != io.ErrUnexpectedEOF {
+		return "", err
+	}
+	_, err = f.Seek(0, io.SeekStart)
 	if err != nil {
-		fnLogs(c)
+		return "", err
 	}
+	return http.DetectContentType(buf[:n]), nil
+}
 
-	return nil
+// hasHeader returns whether the "key:value" headers of --header contain
+// the key.
+func hasHeader(headers []string, key string) bool {
+	for _, header := range headers {
+		if strings.EqualFold(strings.TrimSpace(strings.SplitN(header, ":", 2)[0]), key) {
+			return true
+		}
+	}
+	return false
 }
 
-func doHTTPRequest(ctx context.Context, method, url, body string, headers []string) *http.Response {
+func doHTTPRequest(ctx context.Context, method, url string, body io.Reader, bodySize int64, headers []string) *http.Response {
 	if method == "" {
 		method = http.MethodGet
 	}
@@ -899,8 +1682,14 @@ func doHTTPRequest(ctx context.Context, method, url, body string, headers []stri
 		log.Fatal(fmt.Sprintf("Invalid HTTP method '%s'.", method))
 	}
 
-	req, err := http.NewRequest(method, url, strings.NewReader(body))
+	req, err := http.NewRequest(method, url, body)
 	util.CheckErr(err, "create HTTP request")
+	if bodySize >= 0 {
+		req.ContentLength = bodySize
+	}
+	if bodySize == 0 {
+		req.Body = http.NoBody
+	}
 
 	for _, header := range headers {
 		headerKeyValue := strings.SplitN(header, ":", 2)
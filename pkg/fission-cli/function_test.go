@@ -3,10 +3,14 @@ package fission_cli
 import (
 	"flag"
 	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/urfave/cli"
+	"k8s.io/apimachinery/pkg/api/resource"
 
 	fv1 "github.com/fission/fission/pkg/apis/fission.io/v1"
 )
@@ -319,6 +323,111 @@ func TestGetInvokeStrategy(t *testing.T) {
 			expectedResult:         nil,
 			expectError:            true,
 		},
+		{
+			// case: multiplex poolmgr function
+			testArgs:               map[string]string{"multiplex": "true"},
+			existingInvokeStrategy: nil,
+			expectedResult: &fv1.InvokeStrategy{
+				StrategyType: fv1.StrategyTypeExecution,
+				ExecutionStrategy: fv1.ExecutionStrategy{
+					ExecutorType: fv1.ExecutorTypePoolmgr,
+					Multiplex:    true,
+				},
+			},
+			expectError: false,
+		},
+		{
+			// case: multiplex is kept on update
+			testArgs: map[string]string{},
+			existingInvokeStrategy: &fv1.InvokeStrategy{
+				StrategyType: fv1.StrategyTypeExecution,
+				ExecutionStrategy: fv1.ExecutionStrategy{
+					ExecutorType: fv1.ExecutorTypePoolmgr,
+					Multiplex:    true,
+				},
+			},
+			expectedResult: &fv1.InvokeStrategy{
+				StrategyType: fv1.StrategyTypeExecution,
+				ExecutionStrategy: fv1.ExecutionStrategy{
+					ExecutorType: fv1.ExecutorTypePoolmgr,
+					Multiplex:    true,
+				},
+			},
+			expectError: false,
+		},
+		{
+			// case: multiplex should not work for newdeploy
+			testArgs: map[string]string{
+				"executortype": fv1.ExecutorTypeNewdeploy,
+				"multiplex":    "true",
+			},
+			existingInvokeStrategy: nil,
+			expectedResult:         nil,
+			expectError:            true,
+		},
+		{
+			// case: poolmgr function isolated per invocation
+			testArgs: map[string]string{
+				"isolation":      fv1.IsolationModeInvocation,
+				"prespecialized": "2",
+			},
+			existingInvokeStrategy: nil,
+			expectedResult: &fv1.InvokeStrategy{
+				StrategyType: fv1.StrategyTypeExecution,
+				ExecutionStrategy: fv1.ExecutionStrategy{
+					ExecutorType:       fv1.ExecutorTypePoolmgr,
+					Isolation:          fv1.IsolationModeInvocation,
+					PrespecializedPods: 2,
+				},
+			},
+			expectError: false,
+		},
+		{
+			// case: isolation turned off on update drops prespecialized pods
+			testArgs: map[string]string{"isolation": fv1.IsolationModeShared},
+			existingInvokeStrategy: &fv1.InvokeStrategy{
+				StrategyType: fv1.StrategyTypeExecution,
+				ExecutionStrategy: fv1.ExecutionStrategy{
+					ExecutorType:       fv1.ExecutorTypePoolmgr,
+					Isolation:          fv1.IsolationModeInvocation,
+					PrespecializedPods: 2,
+				},
+			},
+			expectedResult: &fv1.InvokeStrategy{
+				StrategyType: fv1.StrategyTypeExecution,
+				ExecutionStrategy: fv1.ExecutionStrategy{
+					ExecutorType: fv1.ExecutorTypePoolmgr,
+				},
+			},
+			expectError: false,
+		},
+		{
+			// case: prespecialized pods need isolation per invocation
+			testArgs:               map[string]string{"prespecialized": "2"},
+			existingInvokeStrategy: nil,
+			expectedResult:         nil,
+			expectError:            true,
+		},
+		{
+			// case: isolated function cannot be multiplexed
+			testArgs: map[string]string{
+				"isolation": fv1.IsolationModeInvocation,
+				"multiplex": "true",
+			},
+			existingInvokeStrategy: nil,
+			expectedResult:         nil,
+			expectError:            true,
+		},
+		{
+			// case: isolation should not work for newdeploy
+			testArgs: map[string]string{
+				"executortype": fv1.ExecutorTypeNewdeploy,
+				"isolation":    fv1.IsolationModeInvocation,
+			},
+			existingInvokeStrategy: nil,
+			expectedResult:         nil,
+			expectError:            true,
+		},
 	}
 
 	for i, c := range cases {
@@ -346,3 +455,172 @@ func TestGetInvokeStrategy(t *testing.T) {
 		}
 	}
 }
+
+func TestGetRequestBody(t *testing.T) {
+	dir, err := ioutil.TempDir("", "fission-fn-test")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	jsonFile := filepath.Join(dir, "body.json")
+	assert.NoError(t, ioutil.WriteFile(jsonFile, []byte(`{"a": 1}`), 0644))
+	pngData := []byte("\x89PNG\r\n\x1a\n\x00\x00\x00\rIHDR")
+	pngFile := filepath.Join(dir, "image")
+	assert.NoError(t, ioutil.WriteFile(pngFile, pngData, 0644))
+
+	cases := []struct {
+		body        string
+		binary      bool
+		size        int64
+		contentType string
+		data        []byte
+	}{
+		{"hello", false, 5, "", []byte("hello")},
+		{"@" + jsonFile, false, 8, "application/json", []byte(`{"a": 1}`)},
+		{"@" + jsonFile, true, 8, "application/json", []byte(`{"a": 1}`)},
+		{"@" + pngFile, false, int64(len(pngData)), "", pngData},
+		{"@" + pngFile, true, int64(len(pngData)), "image/png", pngData},
+	}
+	for _, c := range cases {
+		body, size, contentType, err := getRequestBody(c.body, c.binary)
+		assert.NoError(t, err)
+		data, err := ioutil.ReadAll(body)
+		body.Close()
+		assert.NoError(t, err)
+		assert.Equal(t, c.size, size)
+		assert.Equal(t, c.contentType, contentType)
+		assert.Equal(t, c.data, data)
+	}
+
+	assert.True(t, hasHeader([]string{"content-type: text/plain"}, "Content-Type"))
+	assert.False(t, hasHeader([]string{"X-Foo:bar"}, "Content-Type"))
+}
+
+func TestUpdateVolumes(t *testing.T) {
+	sizeLimit := resource.MustParse("2Gi")
+	models := fv1.FunctionVolume{Type: fv1.FunctionVolumeTypePVC, Name: "models", MountPath: "/mnt/models", ReadOnly: true}
+	scratch := fv1.FunctionVolume{Type: fv1.FunctionVolumeTypeEmptyDir, Name: "scratch", MountPath: "/scratch", SizeLimit: &sizeLimit}
+
+	cases := []struct {
+		name        string
+		values      []string
+		scratchSize string
+		existing    []fv1.FunctionVolume
+		expected    []fv1.FunctionVolume
+		err         bool
+	}{
+		{
+			name:     "pvc volume",
+			values:   []string{"pvc:models:/mnt/models:ro"},
+			expected: []fv1.FunctionVolume{models},
+		},
+		{
+			name:        "sized emptydir volume",
+			values:      []string{"emptydir:scratch:/scratch"},
+			scratchSize: "2Gi",
+			existing:    []fv1.FunctionVolume{models},
+			expected:    []fv1.FunctionVolume{models, scratch},
+		},
+		{
+			name:        "scratch size alone",
+			scratchSize: "2Gi",
+			expected:    []fv1.FunctionVolume{scratch},
+		},
+		{
+			name:     "replace the volume of a path",
+			values:   []string{"pvc:models-v2:/mnt/models"},
+			existing: []fv1.FunctionVolume{models},
+			expected: []fv1.FunctionVolume{{Type: fv1.FunctionVolumeTypePVC, Name: "models-v2", MountPath: "/mnt/models"}},
+		},
+		{
+			name:     "remove volume",
+			values:   []string{"models-"},
+			existing: []fv1.FunctionVolume{models, scratch},
+			expected: []fv1.FunctionVolume{scratch},
+		},
+		{
+			name:   "unknown type",
+			values: []string{"hostpath:data:/data"},
+			err:    true,
+		},
+		{
+			name:   "relative path",
+			values: []string{"pvc:models:models"},
+			err:    true,
+		},
+		{
+			name:        "invalid size",
+			scratchSize: "lots",
+			err:         true,
+		},
+	}
+
+	for _, c := range cases {
+		volumes, err := updateVolumes(c.existing, c.values, c.scratchSize)
+		if c.err {
+			assert.Error(t, err, c.name)
+			continue
+		}
+		assert.NoError(t, err, c.name)
+		assert.Equal(t, c.expected, volumes, c.name)
+	}
+}
+
+func TestGetSpread(t *testing.T) {
+	cases := []struct {
+		name     string
+		args     map[string]string
+		existing *fv1.Spread
+		expected *fv1.Spread
+		err      bool
+	}{
+		{
+			name:     "new spread",
+			args:     map[string]string{"spread": "zone"},
+			expected: &fv1.Spread{Topology: fv1.SpreadTopologyZone},
+		},
+		{
+			name:     "required spread",
+			args:     map[string]string{"spread": "node", "spread-required": "true"},
+			expected: &fv1.Spread{Topology: fv1.SpreadTopologyNode, Required: true},
+		},
+		{
+			name:     "update keeps the topology",
+			args:     map[string]string{"spread-required": "false"},
+			existing: &fv1.Spread{Topology: fv1.SpreadTopologyZone, Required: true},
+			expected: &fv1.Spread{Topology: fv1.SpreadTopologyZone},
+		},
+		{
+			name:     "remove spread",
+			args:     map[string]string{"spread": "none"},
+			existing: &fv1.Spread{Topology: fv1.SpreadTopologyNode},
+		},
+		{
+			name: "required without topology",
+			args: map[string]string{"spread-required": "true"},
+			err:  true,
+		},
+		{
+			name: "unknown topology",
+			args: map[string]string{"spread": "rack"},
+			err:  true,
+		},
+	}
+
+	for _, c := range cases {
+		app := NewCliApp()
+		set := flag.NewFlagSet("test-cmd", 0)
+		ctx := cli.NewContext(app, set, nil)
+		for k, v := range c.args {
+			set.String(k, v, "")
+			ctx.Set(k, v)
+		}
+
+		spread, err := getSpread(ctx, c.existing)
+		if c.err {
+			assert.Error(t, err, c.name)
+			continue
+		}
+		assert.NoError(t, err, c.name)
+		assert.Equal(t, c.expected, spread, c.name)
+	}
+}
@@ -22,8 +22,10 @@ import (
 	"os"
 	"strings"
 	"text/tabwriter"
+	"time"
 
 	"github.com/hashicorp/go-multierror"
+	"github.com/pkg/errors"
 	"github.com/satori/go.uuid"
 	"github.com/urfave/cli"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -62,6 +64,46 @@ func getMethod(method string) string {
 	return ""
 }
 
+// getMethods returns the HTTP methods given with --method, repeated or
+// separated with commas, '*' for any method. It defaults to GET.
+func getMethods(c *cli.Context) []string {
+	var methods []string
+	for _, m := range c.StringSlice("method") {
+		for _, method := range strings.Split(m, ",") {
+			method = strings.TrimSpace(method)
+			if method == fv1.HTTPMethodAny {
+				methods = append(methods, method)
+			} else if len(method) > 0 {
+				methods = append(methods, getMethod(method))
+			}
+		}
+	}
+	if len(methods) == 0 {
+		methods = []string{http.MethodGet}
+	}
+	return methods
+}
+
+// setMethods sets the HTTP methods of a trigger, a single method is kept
+// in Method for the routers which don't know Methods yet.
+func setMethods(spec *fv1.HTTPTriggerSpec, methods []string) {
+	if len(methods) == 1 && methods[0] != fv1.HTTPMethodAny {
+		spec.Method = methods[0]
+		spec.Methods = nil
+		return
+	}
+	spec.Method = ""
+	spec.Methods = methods
+}
+
+// setGRPCMethod makes a gRPC trigger serve POST requests, as gRPC calls
+// are, unless the methods are given.
+func setGRPCMethod(c *cli.Context, spec *fv1.HTTPTriggerSpec) {
+	if spec.Protocol == fv1.HTTPTriggerProtocolGRPC && !c.IsSet("method") {
+		setMethods(spec, []string{http.MethodPost})
+	}
+}
+
 func setHtFunctionRef(functionList []string, functionWeightsList []int) (*fv1.FunctionReference, error) {
 	if len(functionList) == 1 {
 		return &fv1.FunctionReference{
@@ -92,19 +134,225 @@ func setHtFunctionRef(functionList []string, functionWeightsList []int) (*fv1.Fu
 	return nil, fmt.Errorf("the number of functions in a trigger can be 1 or 2(for canary feature along with their weights)")
 }
 
+// isFaultInjectionSet returns true if any of the fault injection flags is set.
+func isFaultInjectionSet(c *cli.Context) bool {
+	return c.IsSet("fault-delay") || c.IsSet("fault-delay-percent") ||
+		c.IsSet("fault-abort-status") || c.IsSet("fault-abort-percent")
+}
+
+// updateFaultInjection applies the fault injection flags to the given
+// config, a nil config is created when any of the flags is set.
+func updateFaultInjection(c *cli.Context, fault *fv1.FaultInjection) *fv1.FaultInjection {
+	if !isFaultInjectionSet(c) {
+		return fault
+	}
+	if fault == nil {
+		fault = &fv1.FaultInjection{}
+	}
+	if c.IsSet("fault-delay") {
+		fault.Delay = int(c.Duration("fault-delay") / time.Millisecond)
+	}
+	if c.IsSet("fault-delay-percent") {
+		fault.DelayPercentage = c.Int("fault-delay-percent")
+	}
+	if c.IsSet("fault-abort-status") {
+		fault.AbortStatus = c.Int("fault-abort-status")
+	}
+	if c.IsSet("fault-abort-percent") {
+		fault.AbortPercentage = c.Int("fault-abort-percent")
+	}
+	return fault
+}
+
+// updateUpstreamTimeouts applies the timeout flags to the given config, a
+// nil config is created when any of the flags is set. A timeout set to 0
+// falls back to the function timeout or the router default.
+func updateUpstreamTimeouts(c *cli.Context, timeouts *fv1.UpstreamTimeouts) *fv1.UpstreamTimeouts {
+	if !c.IsSet("connect-timeout") && !c.IsSet("response-header-timeout") && !c.IsSet("total-timeout") {
+		return timeouts
+	}
+	if timeouts == nil {
+		timeouts = &fv1.UpstreamTimeouts{}
+	}
+	if c.IsSet("connect-timeout") {
+		timeouts.ConnectTimeout = int(c.Duration("connect-timeout") / time.Millisecond)
+	}
+	if c.IsSet("response-header-timeout") {
+		timeouts.ResponseHeaderTimeout = int(c.Duration("response-header-timeout") / time.Millisecond)
+	}
+	if c.IsSet("total-timeout") {
+		timeouts.TotalTimeout = int(c.Duration("total-timeout") / time.Millisecond)
+	}
+	if *timeouts == (fv1.UpstreamTimeouts{}) {
+		return nil
+	}
+	return timeouts
+}
+
+// triggerURL returns the URL of the trigger to show, with a wildcard for
+// the triggers routing a path prefix.
+func triggerURL(spec *fv1.HTTPTriggerSpec) string {
+	if len(spec.Prefix) > 0 {
+		return strings.TrimSuffix(spec.Prefix, "/") + "/*"
+	}
+	return spec.RelativeURL
+}
+
+// updateRateLimit applies the rate limit flags to the given config, a nil
+// config is created when --ratelimit-rps is set. A rate of 0 removes the
+// rate limit of the trigger.
+func updateRateLimit(c *cli.Context, rateLimit *fv1.RateLimit) *fv1.RateLimit {
+	if !c.IsSet("ratelimit-rps") && !c.IsSet("ratelimit-burst") && !c.IsSet("ratelimit-per-client-ip") {
+		return rateLimit
+	}
+	if rateLimit == nil {
+		if !c.IsSet("ratelimit-rps") {
+			log.Fatal("Need a rate to rate limit the trigger, use --ratelimit-rps")
+		}
+		rateLimit = &fv1.RateLimit{}
+	}
+	if c.IsSet("ratelimit-rps") {
+		rateLimit.RequestsPerSecond = c.Int("ratelimit-rps")
+		if rateLimit.RequestsPerSecond == 0 {
+			return nil
+		}
+	}
+	if c.IsSet("ratelimit-burst") {
+		rateLimit.Burst = c.Int("ratelimit-burst")
+	}
+	if c.IsSet("ratelimit-per-client-ip") {
+		rateLimit.PerClientIP = c.Bool("ratelimit-per-client-ip")
+	}
+	return rateLimit
+}
+
+// updateAuth applies the auth flags to the given config, a nil config is
+// created when --auth is set. --auth none removes the auth of the trigger.
+func updateAuth(c *cli.Context, auth *fv1.HTTPTriggerAuth) *fv1.HTTPTriggerAuth {
+	if !c.IsSet("auth") && !c.IsSet("auth-secret") && !c.IsSet("auth-issuer") && !c.IsSet("auth-audience") {
+		return auth
+	}
+	if c.String("auth") == "none" {
+		return nil
+	}
+	if auth == nil {
+		if !c.IsSet("auth") {
+			log.Fatal("Need an auth type to authenticate the requests of the trigger, use --auth jwt or --auth apikey")
+		}
+		auth = &fv1.HTTPTriggerAuth{}
+	}
+	if c.IsSet("auth") {
+		auth.Type = fv1.HTTPTriggerAuthType(c.String("auth"))
+	}
+	if c.IsSet("auth-secret") {
+		auth.Secret = c.String("auth-secret")
+	}
+	if c.IsSet("auth-issuer") {
+		auth.Issuer = c.String("auth-issuer")
+	}
+	if c.IsSet("auth-audience") {
+		auth.Audience = c.String("auth-audience")
+	}
+
+	err := auth.Validate()
+	if err != nil {
+		log.Fatal(err.Error())
+	}
+	return auth
+}
+
+// updateRetryPolicy applies the retry flags to the given policy, a nil
+// policy is created when any of the flags is set. A new policy retries
+// connect failures like the router default, unless --retry-on is set.
+func updateRetryPolicy(c *cli.Context, policy *fv1.RetryPolicy) *fv1.RetryPolicy {
+	if !c.IsSet("retries") && !c.IsSet("retry-on") {
+		return policy
+	}
+	if policy == nil {
+		policy = &fv1.RetryPolicy{
+			RetryOn: []string{fv1.RetryOnConnectFailure},
+		}
+	}
+	if c.IsSet("retries") {
+		policy.Retries = c.Int("retries")
+	}
+	if c.IsSet("retry-on") {
+		policy.RetryOn = c.StringSlice("retry-on")
+	}
+	return policy
+}
+
+// updateAPIVersioning applies the API versioning flags to the given config,
+// a nil config is created when --api-version is set. "--api-version 2=fn"
+// routes version 2 to fn, "--api-version 2-" removes version 2, and the
+// config is removed along with its last version.
+func updateAPIVersioning(c *cli.Context, versioning *fv1.APIVersioning) (*fv1.APIVersioning, error) {
+	if !c.IsSet("api-version") && !c.IsSet("api-version-header") && !c.IsSet("api-version-accept") {
+		return versioning, nil
+	}
+	if versioning == nil {
+		if !c.IsSet("api-version") {
+			return nil, errors.New("need the functions of the API versions, use --api-version VERSION=FUNCTION")
+		}
+		versioning = &fv1.APIVersioning{}
+	}
+	if versioning.Functions == nil {
+		versioning.Functions = make(map[string]string)
+	}
+	for _, value := range c.StringSlice("api-version") {
+		if strings.HasSuffix(value, "-") && !strings.Contains(value, "=") {
+			delete(versioning.Functions, strings.TrimSuffix(value, "-"))
+			continue
+		}
+		kv := strings.SplitN(value, "=", 2)
+		if len(kv) != 2 || len(kv[0]) == 0 || len(kv[1]) == 0 {
+			return nil, fmt.Errorf("invalid API version %q, use VERSION=FUNCTION, or VERSION- to remove it", value)
+		}
+		versioning.Functions[kv[0]] = kv[1]
+	}
+	if len(versioning.Functions) == 0 {
+		return nil, nil
+	}
+	if c.IsSet("api-version-header") {
+		versioning.Header = c.String("api-version-header")
+	}
+	if c.IsSet("api-version-accept") {
+		versioning.Accept = c.Bool("api-version-accept")
+	}
+
+	err := versioning.Validate()
+	if err != nil {
+		return nil, err
+	}
+	return versioning, nil
+}
+
 func htCreate(c *cli.Context) error {
 	client := util.GetApiClient(c.GlobalString("server"))
 
 	functionList := c.StringSlice("function")
 	functionWeightsList := c.IntSlice("weight")
+	aliasName := c.String("alias")
 
-	if len(functionList) == 0 {
-		log.Fatal("Need a function name to create a trigger, use --function")
+	if len(functionList) == 0 && len(aliasName) == 0 {
+		log.Fatal("Need a function name to create a trigger, use --function or --alias")
+	}
+	if len(functionList) > 0 && len(aliasName) > 0 {
+		log.Fatal("--function and --alias can't be used together")
 	}
 
-	functionRef, err := setHtFunctionRef(functionList, functionWeightsList)
-	if err != nil {
-		log.Fatal(err.Error())
+	var functionRef *fv1.FunctionReference
+	var err error
+	if len(aliasName) > 0 {
+		functionRef = &fv1.FunctionReference{
+			Type: fv1.FunctionReferenceTypeFunctionAlias,
+			Name: aliasName,
+		}
+	} else {
+		functionRef, err = setHtFunctionRef(functionList, functionWeightsList)
+		if err != nil {
+			log.Fatal(err.Error())
+		}
 	}
 
 	triggerName := c.String("name")
@@ -125,20 +373,25 @@ func htCreate(c *cli.Context) error {
 	}
 
 	triggerUrl := c.String("url")
-	if len(triggerUrl) == 0 {
-		log.Fatal("Need a trigger URL, use --url")
+	prefix := c.String("prefix")
+	if len(triggerUrl) > 0 && len(prefix) > 0 {
+		log.Fatal("--url and --prefix can't be used together")
+	}
+	if len(triggerUrl) == 0 && len(prefix) == 0 {
+		log.Fatal("Need a trigger URL, use --url, or a path prefix, use --prefix")
 	}
-	if !strings.HasPrefix(triggerUrl, "/") {
+	if len(triggerUrl) > 0 && !strings.HasPrefix(triggerUrl, "/") {
 		triggerUrl = fmt.Sprintf("/%s", triggerUrl)
 	}
-
-	method := c.String("method")
-	if len(method) == 0 {
-		method = "GET"
+	if len(prefix) > 0 && !strings.HasPrefix(prefix, "/") {
+		prefix = fmt.Sprintf("/%s", prefix)
+	}
+	if c.Bool("keep-prefix") && len(prefix) == 0 {
+		log.Fatal("--keep-prefix can only be used with --prefix")
 	}
 
 	// For Specs, the spec validate checks for function reference
-	if !toSpec {
+	if !toSpec && len(functionList) > 0 {
 		err = util.CheckFunctionExistence(client, functionList, fnNamespace)
 		if err != nil {
 			log.Warn(err.Error())
@@ -148,7 +401,7 @@ func htCreate(c *cli.Context) error {
 	createIngress := c.Bool("createingress")
 	ingressConfig, err := httptrigger.GetIngressConfig(
 		c.StringSlice("ingressannotation"), c.String("ingressrule"),
-		c.String("ingresstls"), triggerUrl, nil)
+		c.String("ingresstls"), triggerUrl+prefix, nil)
 	util.CheckErr(err, "parse ingress configuration")
 
 	host := c.String("host")
@@ -167,14 +420,27 @@ func htCreate(c *cli.Context) error {
 			Namespace: fnNamespace,
 		},
 		Spec: fv1.HTTPTriggerSpec{
-			Host:              host,
-			RelativeURL:       triggerUrl,
-			Method:            getMethod(method),
-			FunctionReference: *functionRef,
-			CreateIngress:     createIngress,
-			IngressConfig:     *ingressConfig,
+			Host:                  host,
+			RelativeURL:           triggerUrl,
+			Prefix:                prefix,
+			KeepPrefix:            c.Bool("keep-prefix"),
+			FunctionReference:     *functionRef,
+			CreateIngress:         createIngress,
+			IngressConfig:         *ingressConfig,
+			AllowWebsocket:        c.Bool("allow-websocket"),
+			Protocol:              c.String("protocol"),
+			FaultInjection:        updateFaultInjection(c, nil),
+			Timeouts:              updateUpstreamTimeouts(c, nil),
+			RateLimit:             updateRateLimit(c, nil),
+			MaxConcurrentRequests: c.Int("max-concurrency"),
+			RetryPolicy:           updateRetryPolicy(c, nil),
+			Auth:                  updateAuth(c, nil),
 		},
 	}
+	setMethods(&ht.Spec, getMethods(c))
+	setGRPCMethod(c, &ht.Spec)
+	ht.Spec.APIVersioning, err = updateAPIVersioning(c, nil)
+	util.CheckErr(err, "parse API versions")
 
 	// if we're writing a spec, don't call the API
 	if toSpec {
@@ -226,6 +492,17 @@ func htUpdate(c *cli.Context) error {
 	})
 	util.CheckErr(err, "get HTTP trigger")
 
+	if c.IsSet("function") && c.IsSet("alias") {
+		log.Fatal("--function and --alias can't be used together")
+	}
+
+	if c.IsSet("alias") {
+		ht.Spec.FunctionReference = fv1.FunctionReference{
+			Type: fv1.FunctionReferenceTypeFunctionAlias,
+			Name: c.String("alias"),
+		}
+	}
+
 	if c.IsSet("function") {
 		// get the functions and their weights if specified
 		functionList := c.StringSlice("function")
@@ -250,19 +527,64 @@ func htUpdate(c *cli.Context) error {
 		ht.Spec.FunctionReference = *functionRef
 	}
 
+	if c.IsSet("method") {
+		setMethods(&ht.Spec, getMethods(c))
+	}
+
 	if c.IsSet("createingress") {
 		ht.Spec.CreateIngress = c.Bool("createingress")
 	}
 
+	if c.IsSet("allow-websocket") {
+		ht.Spec.AllowWebsocket = c.Bool("allow-websocket")
+	}
+
+	if c.IsSet("protocol") {
+		ht.Spec.Protocol = c.String("protocol")
+		setGRPCMethod(c, &ht.Spec)
+	}
+
 	if c.IsSet("host") {
 		ht.Spec.Host = c.String("host")
 		log.Warn(fmt.Sprintf("--host is now marked as deprecated, see 'help' for details"))
 	}
 
+	if c.Bool("fault-disable") {
+		if isFaultInjectionSet(c) {
+			log.Fatal("--fault-disable can't be used with other fault injection flags")
+		}
+		ht.Spec.FaultInjection = nil
+	} else {
+		ht.Spec.FaultInjection = updateFaultInjection(c, ht.Spec.FaultInjection)
+	}
+
+	ht.Spec.Timeouts = updateUpstreamTimeouts(c, ht.Spec.Timeouts)
+	if c.IsSet("prefix") {
+		if len(ht.Spec.Prefix) == 0 {
+			log.Fatal("--prefix can only be used with triggers created with --prefix")
+		}
+		ht.Spec.Prefix = c.String("prefix")
+		if !strings.HasPrefix(ht.Spec.Prefix, "/") {
+			ht.Spec.Prefix = fmt.Sprintf("/%s", ht.Spec.Prefix)
+		}
+	}
+	if c.IsSet("keep-prefix") {
+		ht.Spec.KeepPrefix = c.Bool("keep-prefix")
+	}
+
+	ht.Spec.RateLimit = updateRateLimit(c, ht.Spec.RateLimit)
+	if c.IsSet("max-concurrency") {
+		ht.Spec.MaxConcurrentRequests = c.Int("max-concurrency")
+	}
+	ht.Spec.RetryPolicy = updateRetryPolicy(c, ht.Spec.RetryPolicy)
+	ht.Spec.Auth = updateAuth(c, ht.Spec.Auth)
+	ht.Spec.APIVersioning, err = updateAPIVersioning(c, ht.Spec.APIVersioning)
+	util.CheckErr(err, "parse API versions")
+
 	if c.IsSet("ingressrule") || c.IsSet("ingressannotation") || c.IsSet("ingresstls") {
 		_, err = httptrigger.GetIngressConfig(
 			c.StringSlice("ingressannotation"), c.String("ingressrule"),
-			c.String("ingresstls"), ht.Spec.RelativeURL, &ht.Spec.IngressConfig)
+			c.String("ingresstls"), ht.Spec.GetPath(), &ht.Spec.IngressConfig)
 		util.CheckErr(err, "parse ingress configuration")
 	}
 
@@ -326,7 +648,7 @@ func htList(c *cli.Context) error {
 	triggerNamespace := c.String("triggerNamespace")
 	fnName := c.String("function")
 
-	hts, err := client.HTTPTriggerList(triggerNamespace)
+	hts, err := client.HTTPTriggerListWithOptions(triggerNamespace, listOptions(c))
 	util.CheckErr(err, "list HTTP triggers")
 
 	var triggers []fv1.HTTPTrigger
@@ -348,6 +670,8 @@ func printHtSummary(triggers []fv1.HTTPTrigger) {
 		function := ""
 		if trigger.Spec.FunctionReference.Type == fv1.FunctionReferenceTypeFunctionName {
 			function = trigger.Spec.FunctionReference.Name
+		} else if trigger.Spec.FunctionReference.Type == fv1.FunctionReferenceTypeFunctionAlias {
+			function = fmt.Sprintf("alias:%s", trigger.Spec.FunctionReference.Name)
 		} else {
 			for k, v := range trigger.Spec.FunctionReference.FunctionWeights {
 				function += fmt.Sprintf("%s:%v ", k, v)
@@ -358,7 +682,7 @@ func printHtSummary(triggers []fv1.HTTPTrigger) {
 		if len(trigger.Spec.IngressConfig.Host) > 0 {
 			host = trigger.Spec.IngressConfig.Host
 		}
-		path := trigger.Spec.RelativeURL
+		path := trigger.Spec.GetPath()
 		if len(trigger.Spec.IngressConfig.Path) > 0 {
 			path = trigger.Spec.IngressConfig.Path
 		}
@@ -370,7 +694,7 @@ func printHtSummary(triggers []fv1.HTTPTrigger) {
 		ann := strings.Join(msg, ", ")
 
 		fmt.Fprintf(w, "%v\t%v\t%v\t%v\t%v\t%v\t%v\t%v\t%v\n",
-			trigger.Metadata.Name, trigger.Spec.Method, trigger.Spec.RelativeURL, function, trigger.Spec.CreateIngress, host, path, trigger.Spec.IngressConfig.TLS, ann)
+			trigger.Metadata.Name, strings.Join(trigger.Spec.GetMethods(), ","), triggerURL(&trigger.Spec), function, trigger.Spec.CreateIngress, host, path, trigger.Spec.IngressConfig.TLS, ann)
 	}
 	w.Flush()
 }
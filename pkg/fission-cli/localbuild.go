@@ -0,0 +1,178 @@
+/*
+Copyright 2019 The Fission Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package fission_cli
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/mholt/archiver"
+	"github.com/urfave/cli"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/fission/fission/pkg/fission-cli/log"
+	"github.com/fission/fission/pkg/fission-cli/util"
+)
+
+const (
+	// same as the shared volume path of the builder pod
+	localBuildPackagesPath = "/packages"
+
+	// default build command of builder images
+	defaultBuildCommand = "/build"
+)
+
+// pkgBuild builds a source package on the local machine by running the
+// builder image of the environment with Docker, the same way the builder
+// pod does on the cluster, and saves the deployment archive to a local file.
+func pkgBuild(c *cli.Context) error {
+	if !c.Bool("local") {
+		log.Fatal("Only local builds are supported, use --local to build with Docker or 'fission pkg rebuild' to rebuild a package on the cluster.")
+	}
+
+	srcArchiveFiles := c.StringSlice("src")
+	if len(srcArchiveFiles) == 0 {
+		log.Fatal("Need --src to specify source archive.")
+	}
+	if strings.HasPrefix(srcArchiveFiles[0], gitSourcePrefix) {
+		log.Fatal("Local builds of Git sources are not supported, clone the repository and use its directory as --src.")
+	}
+
+	// The builder image and the default build command come from the
+	// environment, unless the image is given, which allows offline builds.
+	image := c.String("builder-image")
+	buildCmd := c.String("buildcmd")
+	if len(image) == 0 {
+		envName := c.String("env")
+		if len(envName) == 0 {
+			log.Fatal("Need --env or --builder-image argument.")
+		}
+		client := util.GetApiClient(c.GlobalString("server"))
+		env, err := client.EnvironmentGet(&metav1.ObjectMeta{
+			Namespace: c.String("envNamespace"),
+			Name:      envName,
+		})
+		util.CheckErr(err, fmt.Sprintf("get environment %v", envName))
+		if len(env.Spec.Builder.Image) == 0 {
+			log.Fatal(fmt.Sprintf("Environment %v has no builder image.", envName))
+		}
+		image = env.Spec.Builder.Image
+		if len(buildCmd) == 0 {
+			buildCmd = env.Spec.Builder.Command
+		}
+	}
+	if len(buildCmd) == 0 {
+		buildCmd = defaultBuildCommand
+	}
+
+	output := c.String("output")
+	if len(output) == 0 {
+		name := util.KubifyName(path.Base(srcArchiveFiles[0]))
+		if len(name) == 0 {
+			name = "package"
+		}
+		output = fmt.Sprintf("%v-deploy.zip", name)
+	}
+
+	err := buildPackageLocally(image, buildCmd, srcArchiveFiles, output)
+	util.CheckErr(err, "build package")
+
+	fmt.Printf("Deployment archive saved to %v, create a package with it using 'fission pkg create --deploy %v'\n", output, output)
+	return nil
+}
+
+// buildPackageLocally runs the build command in the builder image with
+// Docker on the source archive files, and zips the deployment package
+// to output.
+func buildPackageLocally(image string, buildCmd string, srcArchiveFiles []string, output string) error {
+	dockerPath, err := exec.LookPath("docker")
+	if err != nil {
+		return fmt.Errorf("error finding docker, local builds require Docker: %v", err)
+	}
+
+	workDir, err := ioutil.TempDir("", "fission-local-build-")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(workDir)
+
+	// Same as the fetcher, the source archive is extracted to a directory
+	// and the build command runs in it.
+	srcPkgFilename := "src"
+	srcArchive := makeArchiveFileIfNeeded("", srcArchiveFiles, false)
+	err = archiver.Zip.Open(srcArchive, filepath.Join(workDir, srcPkgFilename))
+	if err != nil {
+		return fmt.Errorf("error extracting source archive %v: %v", srcArchive, err)
+	}
+
+	deployPkgFilename := "deploy"
+	srcPkgPath := path.Join(localBuildPackagesPath, srcPkgFilename)
+	deployPkgPath := path.Join(localBuildPackagesPath, deployPkgFilename)
+
+	args := []string{"run", "--rm",
+		"-v", fmt.Sprintf("%v:%v", workDir, localBuildPackagesPath),
+		"-w", srcPkgPath,
+		"-e", fmt.Sprintf("SRC_PKG=%v", srcPkgPath),
+		"-e", fmt.Sprintf("DEPLOY_PKG=%v", deployPkgPath),
+		"--entrypoint", buildCmd,
+		image,
+	}
+	fmt.Printf("Building with image %v and command %v\n", image, buildCmd)
+
+	cmd := exec.Command(dockerPath, args...)
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	err = cmd.Run()
+	if err != nil {
+		return err
+	}
+
+	err = archiveDeployPackage(filepath.Join(workDir, deployPkgFilename), output)
+	if err != nil {
+		return fmt.Errorf("error creating deployment archive: %v", err)
+	}
+	return nil
+}
+
+// archiveDeployPackage zips the build output into dst, the content of the
+// directory is zipped if the output is a directory.
+func archiveDeployPackage(src string, dst string) error {
+	fi, err := os.Stat(src)
+	if err != nil {
+		return fmt.Errorf("build command didn't create the deployment package: %v", err)
+	}
+
+	var files []string
+	if fi.IsDir() {
+		fs, err := ioutil.ReadDir(src)
+		if err != nil {
+			return err
+		}
+		for _, f := range fs {
+			files = append(files, filepath.Join(src, f.Name()))
+		}
+	} else {
+		files = append(files, src)
+	}
+
+	return archiver.Zip.Make(dst, files)
+}
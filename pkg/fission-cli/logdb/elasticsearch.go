@@ -0,0 +1,160 @@
+/*
+Copyright 2019 The Fission Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package logdb
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	ferror "github.com/fission/fission/pkg/error"
+)
+
+const (
+	ELASTICSEARCH     = "elasticsearch"
+	ELASTICSEARCH_URL = "http://elasticsearch:9200/fluent-bit/_search"
+)
+
+func init() {
+	Register(ELASTICSEARCH, Backend{
+		New: func(serverURL string) (LogDatabase, error) {
+			return NewElasticsearch(serverURL)
+		},
+		DefaultURL: ELASTICSEARCH_URL,
+	})
+}
+
+func NewElasticsearch(serverURL string) (Elasticsearch, error) {
+	return Elasticsearch{endpoint: serverURL}, nil
+}
+
+// Elasticsearch queries the function logs written by the es output of
+// fluent-bit with the kubernetes filter.
+type Elasticsearch struct {
+	endpoint string
+}
+
+type esLogDocument struct {
+	Timestamp  time.Time `json:"@timestamp"`
+	Log        string    `json:"log"`
+	Stream     string    `json:"stream"`
+	Kubernetes struct {
+		PodName       string            `json:"pod_name"`
+		NamespaceName string            `json:"namespace_name"`
+		DockerID      string            `json:"docker_id"`
+		Labels        map[string]string `json:"labels"`
+	} `json:"kubernetes"`
+}
+
+type esResponse struct {
+	Hits struct {
+		Hits []struct {
+			Source esLogDocument `json:"_source"`
+		} `json:"hits"`
+	} `json:"hits"`
+}
+
+// esQuery returns the search request of the logs matching the filter but
+// the patterns, the regular expressions of elasticsearch aren't RE2 and
+// only match whole terms.
+func esQuery(filter LogFilter) map[string]interface{} {
+	conditions := []interface{}{
+		map[string]interface{}{
+			"match_phrase": map[string]interface{}{"kubernetes.labels.functionUid": filter.FuncUid},
+		},
+		map[string]interface{}{
+			"range": map[string]interface{}{
+				"@timestamp": map[string]interface{}{
+					"gt":     filter.Since.UnixNano() / int64(time.Millisecond),
+					"format": "epoch_millis",
+				},
+			},
+		},
+	}
+	if len(filter.Pod) > 0 {
+		conditions = append(conditions, map[string]interface{}{
+			"match_phrase": map[string]interface{}{"kubernetes.pod_name": filter.Pod},
+		})
+	}
+
+	order := "asc"
+	if filter.Reverse {
+		order = "desc"
+	}
+	return map[string]interface{}{
+		"query": map[string]interface{}{
+			"bool": map[string]interface{}{"filter": conditions},
+		},
+		"sort": []interface{}{
+			map[string]interface{}{"@timestamp": map[string]interface{}{"order": order}},
+		},
+		"size": filter.RecordLimit,
+	}
+}
+
+func (es Elasticsearch) GetLogs(filter LogFilter) ([]LogEntry, error) {
+	queryURL, err := proxyURL(es.endpoint, ELASTICSEARCH)
+	if err != nil {
+		return nil, err
+	}
+	body, err := json.Marshal(esQuery(filter))
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequest(http.MethodPost, queryURL.String(), bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	httpClient := http.Client{Timeout: 5 * time.Second}
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, ferror.MakeErrorFromHTTP(resp)
+	}
+
+	response := esResponse{}
+	err = json.NewDecoder(resp.Body).Decode(&response)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode elasticsearch response: %v", err)
+	}
+
+	entries := make([]LogEntry, 0, len(response.Hits.Hits))
+	for _, hit := range response.Hits.Hits {
+		doc := hit.Source
+		entries = append(entries, LogEntry{
+			Timestamp: doc.Timestamp,
+			Message:   strings.TrimSuffix(doc.Log, "\n"),
+			Stream:    doc.Stream,
+			Container: doc.Kubernetes.DockerID,
+			Namespace: doc.Kubernetes.NamespaceName,
+			FuncName:  doc.Kubernetes.Labels["functionName"],
+			FuncUid:   doc.Kubernetes.Labels["functionUid"],
+			Pod:       doc.Kubernetes.PodName,
+		})
+	}
+	return filterLogEntries(entries, filter)
+}
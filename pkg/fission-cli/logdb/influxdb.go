@@ -20,8 +20,6 @@ import (
 	"encoding/json"
 	"fmt"
 	"net/http"
-	"net/url"
-	"path"
 	"strconv"
 	"strings"
 	"time"
@@ -37,6 +35,15 @@ const (
 	INFLUXDB_URL      = "http://influxdb:8086/query"
 )
 
+func init() {
+	Register(INFLUXDB, Backend{
+		New: func(serverURL string) (LogDatabase, error) {
+			return NewInfluxDB(serverURL)
+		},
+		DefaultURL: INFLUXDB_URL,
+	})
+}
+
 func NewInfluxDB(serverURL string) (InfluxDB, error) {
 	return InfluxDB{endpoint: serverURL}, nil
 }
@@ -65,6 +72,12 @@ func (influx InfluxDB) GetLogs(filter LogFilter) ([]LogEntry, error) {
 	parameters["time"] = timestamp
 	//the parameters above are only for the where clause and do not work with LIMIT
 
+	// filter log messages in the database instead of transferring all of them
+	var patternCondition string
+	for _, pattern := range filter.Patterns {
+		patternCondition += " AND \"log\" =~ /" + escapeRegexLiteral(pattern) + "/"
+	}
+
 	orderCondition := " order by \"time\" asc"
 	if filter.Reverse {
 		orderCondition = " order by \"time\" desc"
@@ -72,11 +85,11 @@ func (influx InfluxDB) GetLogs(filter LogFilter) ([]LogEntry, error) {
 
 	if filter.Pod != "" {
 		// wait for bug fix for fluent-bit influxdb plugin
-		queryCmd = "select * from /^log*/ where (\"funcuid\" = $funcuid OR \"kubernetes_labels_functionUid\" = $funcuid) AND \"pod\" = $pod AND \"time\" > $time " + orderCondition + " LIMIT " + strconv.Itoa(filter.RecordLimit)
+		queryCmd = "select * from /^log*/ where (\"funcuid\" = $funcuid OR \"kubernetes_labels_functionUid\" = $funcuid) AND (\"pod\" = $pod OR \"kubernetes_pod_name\" = $pod) AND \"time\" > $time" + patternCondition + orderCondition + " LIMIT " + strconv.Itoa(filter.RecordLimit)
 		parameters["pod"] = filter.Pod
 	} else {
 		// wait for bug fix for fluent-bit influxdb plugin
-		queryCmd = "select * from /^log*/ where (\"funcuid\" = $funcuid  OR \"kubernetes_labels_functionUid\" = $funcuid) AND \"time\" > $time " + orderCondition + " LIMIT " + strconv.Itoa(filter.RecordLimit)
+		queryCmd = "select * from /^log*/ where (\"funcuid\" = $funcuid  OR \"kubernetes_labels_functionUid\" = $funcuid) AND \"time\" > $time" + patternCondition + orderCondition + " LIMIT " + strconv.Itoa(filter.RecordLimit)
 	}
 
 	query := influxdbClient.NewQueryWithParameters(queryCmd, INFLUXDB_DATABASE, "", parameters)
@@ -134,13 +147,12 @@ func (influx InfluxDB) GetLogs(filter LogFilter) ([]LogEntry, error) {
 }
 
 func (influx InfluxDB) query(query influxdbClient.Query) (*influxdbClient.Response, error) {
-	queryURL, err := url.Parse(influx.endpoint)
+	// connect to controller first, then controller will redirect our query command
+	// to influxdb and proxy back the db response.
+	queryURL, err := proxyURL(influx.endpoint, INFLUXDB)
 	if err != nil {
 		return nil, err
 	}
-	// connect to controller first, then controller will redirect our query command
-	// to influxdb and proxy back the db response.
-	queryURL.Path = path.Clean(fmt.Sprintf("%s/proxy/%s", queryURL.Path, INFLUXDB))
 	req, err := http.NewRequest(http.MethodPost, queryURL.String(), nil)
 	if err != nil {
 		return nil, err
@@ -196,3 +208,9 @@ func getEntryValue(list []interface{}, index int, fallbackIndex ...int) string {
 
 	return ""
 }
+
+// escapeRegexLiteral escapes the slashes of a regular expression so that
+// it can be used as an InfluxQL regular expression literal.
+func escapeRegexLiteral(pattern string) string {
+	return strings.Replace(pattern, "/", "\\/", -1)
+}
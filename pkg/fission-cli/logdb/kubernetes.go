@@ -0,0 +1,135 @@
+/*
+Copyright 2019 The Fission Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package logdb
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"path"
+	"strings"
+	"time"
+
+	ferror "github.com/fission/fission/pkg/error"
+)
+
+const (
+	KUBERNETES = "kubernetes"
+)
+
+func init() {
+	// the controller reads the logs from the function pods itself, there's
+	// no database to proxy to
+	Register(KUBERNETES, Backend{
+		New: func(serverURL string) (LogDatabase, error) {
+			return NewKubernetes(serverURL)
+		},
+	})
+}
+
+func NewKubernetes(serverURL string) (Kubernetes, error) {
+	return Kubernetes{endpoint: serverURL}, nil
+}
+
+// Kubernetes reads the logs of the running function pods through the
+// controller. It needs no log database, but only has the logs of the
+// pods still around.
+type Kubernetes struct {
+	endpoint string
+}
+
+func (k Kubernetes) GetLogs(filter LogFilter) ([]LogEntry, error) {
+	queryURL, err := url.Parse(k.endpoint)
+	if err != nil {
+		return nil, err
+	}
+	queryURL.Path = path.Clean(fmt.Sprintf("%s/proxy/logs/%s", queryURL.Path, filter.Function))
+	q := url.Values{}
+	q.Set("namespace", filter.Namespace)
+	q.Set("timestamps", "true")
+	if len(filter.Pod) > 0 {
+		q.Set("pod", filter.Pod)
+	}
+	if filter.Since.After(time.Unix(0, 0)) {
+		q.Set("since", filter.Since.UTC().Format(time.RFC3339Nano))
+	}
+	queryURL.RawQuery = q.Encode()
+
+	req, err := http.NewRequest(http.MethodPost, queryURL.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+	httpClient := http.Client{Timeout: 30 * time.Second}
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	// no pods of the function are running, e.g. it wasn't invoked yet
+	if resp.StatusCode == http.StatusNotFound {
+		return []LogEntry{}, nil
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, ferror.MakeErrorFromHTTP(resp)
+	}
+
+	entries, err := parsePodLogs(resp.Body, filter)
+	if err != nil {
+		return nil, err
+	}
+	return filterLogEntries(entries, filter)
+}
+
+// parsePodLogs parses the timestamped pod logs returned by the controller,
+// the logs of each pod follow a "=== Pod <name> ===" line if there are
+// several pods. Lines without a timestamp are error messages of the
+// controller and are skipped.
+func parsePodLogs(r io.Reader, filter LogFilter) ([]LogEntry, error) {
+	entries := make([]LogEntry, 0)
+	pod := filter.Pod
+
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if strings.HasPrefix(line, "=== Pod ") && strings.HasSuffix(line, " ===") {
+			pod = strings.TrimSuffix(strings.TrimPrefix(line, "=== Pod "), " ===")
+			continue
+		}
+
+		fields := strings.SplitN(line, " ", 2)
+		t, err := time.Parse(time.RFC3339Nano, fields[0])
+		if err != nil {
+			continue
+		}
+		var message string
+		if len(fields) > 1 {
+			message = fields[1]
+		}
+		entries = append(entries, LogEntry{
+			Timestamp: t,
+			Message:   message,
+			FuncName:  filter.Function,
+			FuncUid:   filter.FuncUid,
+			Pod:       pod,
+		})
+	}
+	return entries, scanner.Err()
+}
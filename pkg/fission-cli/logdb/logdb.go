@@ -18,6 +18,12 @@ package logdb
 
 import (
 	"fmt"
+	"net/url"
+	"path"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
 	"time"
 )
 
@@ -29,13 +35,66 @@ type LogDatabase interface {
 	GetLogs(LogFilter) ([]LogEntry, error)
 }
 
+// Backend is a type of log database. Queries of the CLI go through the
+// controller, which proxies them to the database or serves them itself.
+type Backend struct {
+	// New makes a client of the database querying through the controller
+	// at serverURL.
+	New func(serverURL string) (LogDatabase, error)
+
+	// DefaultURL is the URL the controller proxies the queries to if
+	// <TYPE>_URL isn't set, empty if the controller serves the queries
+	// itself or the database has to be configured.
+	DefaultURL string
+}
+
+var (
+	backendsLock sync.RWMutex
+	backends     = make(map[string]Backend)
+)
+
+// Register registers a type of log database, the backends register
+// themselves in init.
+func Register(dbType string, backend Backend) {
+	backendsLock.Lock()
+	defer backendsLock.Unlock()
+	if _, ok := backends[dbType]; ok {
+		panic(fmt.Sprintf("log database type %q is registered twice", dbType))
+	}
+	backends[dbType] = backend
+}
+
+// GetBackend returns the registered log database of the type.
+func GetBackend(dbType string) (Backend, bool) {
+	backendsLock.RLock()
+	defer backendsLock.RUnlock()
+	backend, ok := backends[dbType]
+	return backend, ok
+}
+
+// Types returns the registered types of log databases, sorted.
+func Types() []string {
+	backendsLock.RLock()
+	defer backendsLock.RUnlock()
+	types := make([]string, 0, len(backends))
+	for dbType := range backends {
+		types = append(types, dbType)
+	}
+	sort.Strings(types)
+	return types
+}
+
 type LogFilter struct {
 	Pod         string
 	Function    string
 	FuncUid     string
+	Namespace   string
 	Since       time.Time
 	Reverse     bool
 	RecordLimit int
+	// Patterns are regular expressions in RE2 syntax, only the log
+	// messages matching all of them are returned.
+	Patterns []string
 }
 
 type LogEntry struct {
@@ -51,9 +110,63 @@ type LogEntry struct {
 }
 
 func GetLogDB(dbType string, serverURL string) (LogDatabase, error) {
-	switch dbType {
-	case INFLUXDB:
-		return NewInfluxDB(serverURL)
+	backend, ok := GetBackend(dbType)
+	if !ok {
+		return nil, fmt.Errorf("log database type %q is not supported, supported types are %v", dbType, strings.Join(Types(), ", "))
+	}
+	return backend.New(serverURL)
+}
+
+// proxyURL returns the URL of the controller proxying the queries to the
+// log database of the type.
+func proxyURL(serverURL string, dbType string) (*url.URL, error) {
+	queryURL, err := url.Parse(serverURL)
+	if err != nil {
+		return nil, err
+	}
+	queryURL.Path = path.Clean(fmt.Sprintf("%s/proxy/%s", queryURL.Path, dbType))
+	return queryURL, nil
+}
+
+// filterLogEntries is for the databases that can't filter the log
+// messages themselves. It drops the entries not newer than filter.Since or
+// not matching the patterns, and returns the rest ordered by time and cut
+// to filter.RecordLimit.
+func filterLogEntries(entries []LogEntry, filter LogFilter) ([]LogEntry, error) {
+	regexps := make([]*regexp.Regexp, 0, len(filter.Patterns))
+	for _, pattern := range filter.Patterns {
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			return nil, err
+		}
+		regexps = append(regexps, re)
+	}
+
+	filtered := make([]LogEntry, 0, len(entries))
+	for _, entry := range entries {
+		if !entry.Timestamp.After(filter.Since) || !matchAll(regexps, entry.Message) {
+			continue
+		}
+		filtered = append(filtered, entry)
+	}
+
+	sort.SliceStable(filtered, func(i, j int) bool {
+		if filter.Reverse {
+			return filtered[i].Timestamp.After(filtered[j].Timestamp)
+		}
+		return filtered[i].Timestamp.Before(filtered[j].Timestamp)
+	})
+	if filter.RecordLimit > 0 && len(filtered) > filter.RecordLimit {
+		filtered = filtered[:filter.RecordLimit]
+	}
+	return filtered, nil
+}
+
+func matchAll(regexps []*regexp.Regexp, message string) bool {
+	for _, re := range regexps {
+		if !re.MatchString(message) {
+			return false
+		}
 	}
-	return nil, fmt.Errorf("log database type is incorrect, now only support %s", INFLUXDB)
+	return true
 }
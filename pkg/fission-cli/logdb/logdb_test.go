@@ -0,0 +1,54 @@
+package logdb
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTypes(t *testing.T) {
+	assert.Equal(t, []string{ELASTICSEARCH, INFLUXDB, KUBERNETES, LOKI}, Types())
+
+	_, err := GetLogDB("nosuchdb", "http://controller")
+	assert.Error(t, err)
+
+	backend, ok := GetBackend(LOKI)
+	assert.True(t, ok)
+	assert.Equal(t, LOKI_URL, backend.DefaultURL)
+}
+
+func TestParsePodLogs(t *testing.T) {
+	body := `=== Pod hello-abc ===
+2019-07-01T10:00:01.000000002Z second
+2019-07-01T10:00:00.000000001Z first line
+error getting logs: container not found
+=== Pod hello-def ===
+2019-07-01T10:00:03Z third
+`
+	filter := LogFilter{Function: "hello", FuncUid: "uid", Since: time.Unix(0, 0)}
+	entries, err := parsePodLogs(strings.NewReader(body), filter)
+	assert.NoError(t, err)
+	assert.Len(t, entries, 3)
+	assert.Equal(t, "hello-abc", entries[0].Pod)
+	assert.Equal(t, "hello-def", entries[2].Pod)
+
+	filter.Since = entries[1].Timestamp
+	filter.Reverse = true
+	entries, err = filterLogEntries(entries, filter)
+	assert.NoError(t, err)
+	assert.Len(t, entries, 2)
+	assert.Equal(t, "third", entries[0].Message)
+	assert.Equal(t, "second", entries[1].Message)
+
+	filter.Patterns = []string{"^th"}
+	entries, err = filterLogEntries(entries, filter)
+	assert.NoError(t, err)
+	assert.Len(t, entries, 1)
+}
+
+func TestLokiQuery(t *testing.T) {
+	query := lokiQuery(LogFilter{FuncUid: "uid", Pod: "hello-abc", Patterns: []string{`"reqid":"1"`}})
+	assert.Equal(t, `{functionUid="uid", pod="hello-abc"} |~ "\"reqid\":\"1\""`, query)
+}
@@ -0,0 +1,138 @@
+/*
+Copyright 2019 The Fission Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package logdb
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	ferror "github.com/fission/fission/pkg/error"
+)
+
+const (
+	LOKI     = "loki"
+	LOKI_URL = "http://loki:3100/loki/api/v1/query_range"
+)
+
+func init() {
+	Register(LOKI, Backend{
+		New: func(serverURL string) (LogDatabase, error) {
+			return NewLoki(serverURL)
+		},
+		DefaultURL: LOKI_URL,
+	})
+}
+
+func NewLoki(serverURL string) (Loki, error) {
+	return Loki{endpoint: serverURL}, nil
+}
+
+// Loki queries the function logs collected by promtail with the pod
+// labels kept as stream labels.
+type Loki struct {
+	endpoint string
+}
+
+type lokiResponse struct {
+	Status string `json:"status"`
+	Data   struct {
+		Result []struct {
+			Stream map[string]string `json:"stream"`
+			// Values are pairs of a timestamp in nanoseconds and a line
+			Values [][2]string `json:"values"`
+		} `json:"result"`
+	} `json:"data"`
+}
+
+// lokiQuery returns the LogQL query of the logs matching the filter, the
+// patterns are RE2 like the line filters of LogQL.
+func lokiQuery(filter LogFilter) string {
+	selector := []string{"functionUid=" + strconv.Quote(filter.FuncUid)}
+	if len(filter.Pod) > 0 {
+		selector = append(selector, "pod="+strconv.Quote(filter.Pod))
+	}
+	query := "{" + strings.Join(selector, ", ") + "}"
+	for _, pattern := range filter.Patterns {
+		query += " |~ " + strconv.Quote(pattern)
+	}
+	return query
+}
+
+func (loki Loki) GetLogs(filter LogFilter) ([]LogEntry, error) {
+	queryURL, err := proxyURL(loki.endpoint, LOKI)
+	if err != nil {
+		return nil, err
+	}
+	direction := "forward"
+	if filter.Reverse {
+		direction = "backward"
+	}
+	q := queryURL.Query()
+	q.Set("query", lokiQuery(filter))
+	// start is inclusive, the entries at Since were returned before
+	q.Set("start", strconv.FormatInt(filter.Since.UnixNano()+1, 10))
+	q.Set("end", strconv.FormatInt(time.Now().UnixNano(), 10))
+	q.Set("limit", strconv.Itoa(filter.RecordLimit))
+	q.Set("direction", direction)
+	queryURL.RawQuery = q.Encode()
+
+	httpClient := http.Client{Timeout: 5 * time.Second}
+	resp, err := httpClient.Get(queryURL.String())
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, ferror.MakeErrorFromHTTP(resp)
+	}
+
+	response := lokiResponse{}
+	err = json.NewDecoder(resp.Body).Decode(&response)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode loki response: %v", err)
+	}
+
+	entries := make([]LogEntry, 0)
+	for _, stream := range response.Data.Result {
+		for _, value := range stream.Values {
+			ns, err := strconv.ParseInt(value[0], 10, 64)
+			if err != nil {
+				return nil, fmt.Errorf("invalid loki timestamp %q: %v", value[0], err)
+			}
+			entries = append(entries, LogEntry{
+				Timestamp: time.Unix(0, ns),
+				Message:   strings.TrimSuffix(value[1], "\n"),
+				Stream:    stream.Stream["stream"],
+				Container: stream.Stream["container"],
+				Namespace: stream.Stream["namespace"],
+				FuncName:  stream.Stream["functionName"],
+				FuncUid:   stream.Stream["functionUid"],
+				Pod:       stream.Stream["pod"],
+			})
+		}
+	}
+
+	// the streams are returned one by one, merge them, the patterns are
+	// matched by loki already
+	filter.Patterns = nil
+	return filterLogEntries(entries, filter)
+}
@@ -0,0 +1,167 @@
+/*
+Copyright 2019 The Fission Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package fission_cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/fission/fission/pkg/fission-cli/logdb"
+)
+
+const (
+	logOutputText   = "text"
+	logOutputDetail = "detail"
+	logOutputRaw    = "raw"
+	logOutputJSON   = "json"
+)
+
+type (
+	// logFieldFilter matches structured (JSON) log messages with the
+	// value of a field, nested fields are separated by dots.
+	logFieldFilter struct {
+		path  []string
+		value string
+	}
+)
+
+// parseLogFieldFilters parses field filters in the form of key=value.
+func parseLogFieldFilters(fields []string) ([]logFieldFilter, error) {
+	var filters []logFieldFilter
+	for _, f := range fields {
+		kv := strings.SplitN(f, "=", 2)
+		if len(kv) != 2 || len(kv[0]) == 0 {
+			return nil, fmt.Errorf("invalid field filter '%v', use key=value", f)
+		}
+		filters = append(filters, logFieldFilter{
+			path:  strings.Split(kv[0], "."),
+			value: kv[1],
+		})
+	}
+	return filters, nil
+}
+
+// getLogPatterns returns the regular expressions to filter log messages
+// with in the log database. Field filters can't be evaluated by the
+// database, a pattern matching the key and the value is used to narrow
+// down the messages before they are parsed.
+func getLogPatterns(reqID string, grep string, regex string, fieldFilters []logFieldFilter) ([]string, error) {
+	var patterns []string
+	// environments tag the log lines of an invocation with the request id
+	if len(reqID) > 0 {
+		patterns = append(patterns, regexp.QuoteMeta(reqID))
+	}
+	if len(grep) > 0 {
+		patterns = append(patterns, regexp.QuoteMeta(grep))
+	}
+	if len(regex) > 0 {
+		_, err := regexp.Compile(regex)
+		if err != nil {
+			return nil, fmt.Errorf("invalid regular expression '%v': %v", regex, err)
+		}
+		patterns = append(patterns, regex)
+	}
+	for _, f := range fieldFilters {
+		key := f.path[len(f.path)-1]
+		patterns = append(patterns, fmt.Sprintf(`"%v"\s*:\s*"?%v`, regexp.QuoteMeta(key), regexp.QuoteMeta(f.value)))
+	}
+	return patterns, nil
+}
+
+// parseLogMessage returns the fields of a JSON log message, or nil if the
+// message is not a JSON object.
+func parseLogMessage(msg string) map[string]interface{} {
+	msg = strings.TrimSpace(msg)
+	if !strings.HasPrefix(msg, "{") {
+		return nil
+	}
+	var fields map[string]interface{}
+	if json.Unmarshal([]byte(msg), &fields) != nil {
+		return nil
+	}
+	return fields
+}
+
+// matchLogFields checks whether the fields of a log message have the
+// values of all field filters.
+func matchLogFields(fields map[string]interface{}, filters []logFieldFilter) bool {
+	for _, f := range filters {
+		var val interface{} = fields
+		for _, key := range f.path {
+			m, ok := val.(map[string]interface{})
+			if !ok {
+				return false
+			}
+			val, ok = m[key]
+			if !ok {
+				return false
+			}
+		}
+		if fmt.Sprintf("%v", val) != f.value {
+			return false
+		}
+	}
+	return true
+}
+
+// checkLogOutput returns an error if the log output format is unknown.
+func checkLogOutput(format string) error {
+	switch format {
+	case logOutputText, logOutputDetail, logOutputRaw, logOutputJSON:
+		return nil
+	}
+	return fmt.Errorf("unknown output format '%v', use one of %v, %v, %v or %v",
+		format, logOutputText, logOutputDetail, logOutputRaw, logOutputJSON)
+}
+
+// printLogEntry writes a log entry in the output format.
+func printLogEntry(w io.Writer, format string, entry logdb.LogEntry, fields map[string]interface{}) error {
+	switch format {
+	case logOutputText:
+		fmt.Fprintf(w, "[%s] %s\n", entry.Timestamp, entry.Message)
+	case logOutputDetail:
+		fmt.Fprintf(w, "Timestamp: %s\nNamespace: %s\nFunction Name: %s\nFunction ID: %s\nPod: %s\nContainer: %s\nStream: %s\nLog: %s\n---\n",
+			entry.Timestamp, entry.Namespace, entry.FuncName, entry.FuncUid, entry.Pod, entry.Container, entry.Stream, entry.Message)
+	case logOutputRaw:
+		fmt.Fprintln(w, entry.Message)
+	case logOutputJSON:
+		obj := map[string]interface{}{
+			"timestamp": entry.Timestamp.Format(time.RFC3339Nano),
+			"namespace": entry.Namespace,
+			"function":  entry.FuncName,
+			"pod":       entry.Pod,
+			"container": entry.Container,
+			"stream":    entry.Stream,
+			"message":   entry.Message,
+		}
+		if fields != nil {
+			obj["fields"] = fields
+		}
+		data, err := json.Marshal(obj)
+		if err != nil {
+			return err
+		}
+		fmt.Fprintln(w, string(data))
+	default:
+		return checkLogOutput(format)
+	}
+	return nil
+}
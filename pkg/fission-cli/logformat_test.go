@@ -0,0 +1,36 @@
+package fission_cli
+
+import (
+	"regexp"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLogFieldFilters(t *testing.T) {
+	_, err := parseLogFieldFilters([]string{"level"})
+	assert.NotNil(t, err)
+
+	filters, err := parseLogFieldFilters([]string{"level=error", "http.status=500"})
+	assert.Nil(t, err)
+	assert.Equal(t, []logFieldFilter{
+		{path: []string{"level"}, value: "error"},
+		{path: []string{"http", "status"}, value: "500"},
+	}, filters)
+
+	msg := `{"level": "error", "http": {"status": 500}, "msg": "failed"}`
+	assert.True(t, matchLogFields(parseLogMessage(msg), filters))
+	assert.False(t, matchLogFields(parseLogMessage(`{"level": "info", "http": {"status": 500}}`), filters))
+	assert.False(t, matchLogFields(parseLogMessage(`{"level": "error"}`), filters))
+	assert.False(t, matchLogFields(parseLogMessage("level=error"), filters))
+
+	// the patterns for the log database match the same messages
+	patterns, err := getLogPatterns("", "fail", "", filters)
+	assert.Nil(t, err)
+	for _, p := range patterns {
+		assert.Regexp(t, regexp.MustCompile(p), msg)
+	}
+
+	_, err = getLogPatterns("", "", "(", nil)
+	assert.NotNil(t, err)
+}
@@ -28,6 +28,7 @@ import (
 	"github.com/urfave/cli"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 
+	"github.com/fission/fission/pkg/controller/client"
 	"github.com/fission/fission/pkg/fission-cli/cliwrapper/driver/urfavecli"
 	"github.com/fission/fission/pkg/fission-cli/cmd"
 	"github.com/fission/fission/pkg/fission-cli/cmd/environment"
@@ -85,10 +86,18 @@ func NewCliApp() *cli.App {
 	triggerNamespaceFlag := cli.StringFlag{Name: "triggerNamespace, triggerns", Value: metav1.NamespaceDefault, Usage: "Namespace for trigger object"}
 	recorderNamespaceFlag := cli.StringFlag{Name: "recorderNamespace, recorderns", Value: metav1.NamespaceDefault, Usage: "Namespace for recorder object"}
 	canaryNamespaceFlag := cli.StringFlag{Name: "canaryNamespace, canaryns", Value: metav1.NamespaceDefault, Usage: "Namespace for canary config object"}
+	aliasNamespaceFlag := cli.StringFlag{Name: "aliasNamespace, aliasns", Value: metav1.NamespaceDefault, Usage: "Namespace for function alias object"}
 
-	// trigger method and url flags (used in function and route CLIs)
+	// trigger method, url and ingress flags (used in function and route CLIs)
 	htMethodFlag := cli.StringFlag{Name: "method", Value: "GET", Usage: "HTTP Method: GET|POST|PUT|DELETE|HEAD"}
+	htMethodsFlag := cli.StringSliceFlag{Name: "method", Usage: "HTTP method: GET|POST|PUT|DELETE|HEAD, repeat or separate with commas for several methods, '*' for any method (default GET)"}
 	htUrlFlag := cli.StringFlag{Name: "url", Usage: "URL pattern (See gorilla/mux supported patterns)"}
+	htPrefixFlag := cli.StringFlag{Name: "prefix", Usage: "Path prefix routing all the paths under it to the function instead of --url, e.g. /api/v1/; the path under the prefix is in the X-Fission-Path-Info header"}
+	htKeepPrefixFlag := cli.BoolFlag{Name: "keep-prefix", Usage: "Forward the full request path of a --prefix trigger to the function instead of stripping the prefix"}
+	htIngressFlag := cli.BoolFlag{Name: "createingress", Usage: "Creates ingress with same URL, defaults to false"}
+	htIngressRuleFlag := cli.StringFlag{Name: "ingressrule", Usage: "Host for Ingress rule: --ingressrule host=path (the format of host/path depends on what ingress controller you used)"}
+	htIngressAnnotationFlag := cli.StringSliceFlag{Name: "ingressannotation", Usage: "Annotation for Ingress: --ingressannotation key=value (the format of annotation depends on what ingress controller you used)"}
+	htIngressTLSFlag := cli.StringFlag{Name: "ingresstls", Usage: "Name of the Secret contains TLS key and crt for Ingress (the usability of TLS features depends on what ingress controller you used)"}
 
 	// Resource & scale related flags (Used in env and function)
 	minCpu := cli.IntFlag{Name: cmd.RUNTIME_MINCPU, Usage: "Minimum CPU to be assigned to pod (In millicore, minimum 1)"}
@@ -98,96 +107,199 @@ func NewCliApp() *cli.App {
 	minScale := cli.IntFlag{Name: cmd.RUNTIME_MINSCALE, Usage: "Minimum number of pods (Uses resource inputs to configure HPA)"}
 	maxScale := cli.IntFlag{Name: cmd.RUNTIME_MAXSCALE, Usage: "Maximum number of pods (Uses resource inputs to configure HPA)"}
 	targetcpu := cli.IntFlag{Name: cmd.RUNTIME_TARGETCPU, Usage: "Target average CPU usage percentage across pods for scaling"}
+	selectorFlag := cli.StringFlag{Name: cmd.GetCliFlagName(cmd.RESOURCE_SELECTOR, cmd.RESOURCE_SELECTOR_ALIAS), Usage: "Label selector to filter the listed objects, e.g. -l 'team=payments,tier!=canary'"}
+	labelFlag := cli.StringSliceFlag{Name: cmd.RESOURCE_LABEL, Usage: "Label of the resource and its pods: --label key=value, or --label key- to remove it on update; can be repeated"}
+	annotationFlag := cli.StringSliceFlag{Name: cmd.RESOURCE_ANNOTATION, Usage: "Annotation of the resource and its pods: --annotation key=value, or --annotation key- to remove it on update; can be repeated"}
+	nodeSelectorFlag := cli.StringSliceFlag{Name: cmd.RUNTIME_NODESELECTOR, Usage: "Node label the pods must be scheduled on: --nodeselector key=value, or --nodeselector key- to remove it on update; can be repeated (newdeploy and container functions, or environments)"}
+	tolerationFlag := cli.StringSliceFlag{Name: cmd.RUNTIME_TOLERATION, Usage: "Taint the pods tolerate: --toleration key[=value][:NoSchedule|PreferNoSchedule|NoExecute], or --toleration key- to remove it on update; can be repeated (newdeploy and container functions, or environments)"}
 	specializationTimeoutFlag := cli.IntFlag{Name: "specializationtimeout, st", Value: 120, Usage: "Timeout for newdeploy to wait for function pod creation"}
+	fnIsolationFlag := cli.StringFlag{Name: "isolation", Usage: "Whether invocations share pods: 'shared', or 'invocation' to run each invocation in a fresh pod that is deleted afterwards (poolmgr only)"}
+	fnPrespecializedFlag := cli.IntFlag{Name: "prespecialized", Usage: "Number of pods kept specialized ahead of invocations of a function with --isolation invocation"}
+	fnMultiplexFlag := cli.BoolFlag{Name: "multiplex", Usage: "Serve the function from a pod already specialized for another multiplexed function with the same package (poolmgr only); --multiplex=false to turn it off"}
 
 	// functions
 	fnNameFlag := cli.StringFlag{Name: "name", Usage: "function name"}
 	fnEnvNameFlag := cli.StringFlag{Name: "env", Usage: "environment name for function"}
-	fnCodeFlag := cli.StringFlag{Name: "code", Usage: "local path or URL for source code"}
+	fnCodeFlag := cli.StringFlag{Name: "code", Usage: "local path or URL for source code, use - to read the code from stdin"}
+	fnCodeLiteralFlag := cli.StringFlag{Name: "code-literal", Usage: "inline source code of the function, e.g. from a shell heredoc"}
+	fnCodeNameFlag := cli.StringFlag{Name: "code-name", Usage: "file name of the code given with --code-literal or --code -, e.g. hello.py (defaults to the function name)"}
 	fnDeployArchiveFlag := cli.StringSliceFlag{Name: "deployarchive, deploy", Usage: "local path or URL for deployment archive"}
-	fnSrcArchiveFlag := cli.StringSliceFlag{Name: "sourcearchive, src, source", Usage: "local path or URL for source archive"}
+	fnSrcArchiveFlag := cli.StringSliceFlag{Name: "sourcearchive, src, source", Usage: "local path or URL for source archive, or git+<repository URL>#<ref> for a Git repository"}
 	fnPkgNameFlag := cli.StringFlag{Name: "pkgname, pkg", Usage: "Name of the existing package (--deploy and --src and --env will be ignored), should be in the same namespace as the function"}
-	fnPodFlag := cli.StringFlag{Name: "pod", Usage: "function pod name, optional (use latest if unspecified)"}
+	fnPodFlag := cli.StringFlag{Name: "pod", Usage: "function pod name, optional (all the pods of the function if unspecified)"}
+	fnLogPreviousFlag := cli.BoolFlag{Name: "previous, p", Usage: "show the logs of the previous, terminated containers of the function pods, e.g. of crash looping pods"}
+	fnRouteNameFlag := cli.StringFlag{Name: "route-name", Usage: "name of the HTTP trigger created with --url, defaults to the function name"}
+	fnRouteMethodFlag := cli.StringSliceFlag{Name: "method", Usage: "HTTP method of the trigger created with --url, repeat or separate with commas for several methods, '*' for any method (default GET)"}
+	fnTestAliasFlag := cli.StringFlag{Name: "alias", Usage: "function alias to test instead of --name, the request goes to the functions the alias points to"}
+	fnTestLocalFlag := cli.BoolFlag{Name: "local", Usage: "Run the function from --code, --src or --deploy in the runtime image of the environment with Docker and test it on localhost"}
+	fnTestRuntimeImageFlag := cli.StringFlag{Name: "runtime-image", Usage: "Runtime image to run the function with locally (optional, default to the runtime image of the environment)"}
+	fnTestBuilderImageFlag := cli.StringFlag{Name: "builder-image", Usage: "Builder image to build --src with locally (optional, default to the builder image of the environment)"}
+	fnMetricsSinceFlag := cli.DurationFlag{Name: "since", Value: time.Hour, Usage: "time window of the metrics summary, e.g. 30m, 1h, 24h"}
+	fnStatsSinceFlag := cli.DurationFlag{Name: "since", Value: time.Hour, Usage: "time window of the stats, e.g. 30m, 1h, up to 24h"}
 	fnFollowFlag := cli.BoolFlag{Name: "follow, f", Usage: "specify if the logs should be streamed"}
 	fnDetailFlag := cli.BoolFlag{Name: "detail, d", Usage: "display detailed information"}
-	fnLogDBTypeFlag := cli.StringFlag{Name: "dbtype", Usage: "log database type, e.g. influxdb (currently only influxdb is supported)"}
-	fnBodyFlag := cli.StringFlag{Name: "body, b", Usage: "request body"}
+	fnLogDBTypeFlag := cli.StringFlag{Name: "dbtype", Usage: "log database type, one of the types the server supports, e.g. influxdb, kubernetes, loki or elasticsearch (default: the server default)"}
+	fnBodyFlag := cli.StringFlag{Name: "body, b", Usage: "request body, use @file to read it from a file or - to read it from stdin; the content type of a file is guessed from its extension"}
+	fnBodyBinaryFlag := cli.StringFlag{Name: "body-binary", Usage: "binary request body sent as is, e.g. @image.png, use @file to read it from a file or - to read it from stdin; the content type is guessed from the extension or the content unless --content-type is given"}
+	fnContentTypeFlag := cli.StringFlag{Name: "content-type", Usage: "content type of the request body, e.g. application/json"}
 	fnHeaderFlag := cli.StringSliceFlag{Name: "header, H", Usage: "request headers"}
 	fnQueryFlag := cli.StringSliceFlag{Name: "query, q", Usage: "request query parameters: -q key1=value1 -q key2=value2"}
 	fnEntryPointFlag := cli.StringFlag{Name: "entrypoint", Usage: "entry point for environment v2 to load with"}
 	fnBuildCmdFlag := cli.StringFlag{Name: "buildcmd", Usage: "build command for builder to run with"}
+	fnBuildEnvFlag := cli.StringSliceFlag{Name: "build-env", Usage: "Environment variable of the builds of the package in the form of KEY=VALUE, set in the builder container for these builds only"}
+	fnBuildSecretFlag := cli.StringSliceFlag{Name: "build-secret", Usage: "Secret in the namespace of the function whose entries are set as environment variables of the builds, e.g. private registry tokens"}
+	fnGitSecretFlag := cli.StringFlag{Name: "git-secret", Usage: "secret with the credentials of the Git source repository, username and password for https or ssh-privatekey and known_hosts for ssh (optional)"}
 	fnSecretFlag := cli.StringSliceFlag{Name: "secret", Usage: "function access to secret, should be present in the same namespace as the function. You can provide multiple secrets using multiple --secrets flags."}
 	fnCfgMapFlag := cli.StringSliceFlag{Name: "configmap", Usage: "function access to configmap, should be present in the same namespace as the function. You can provide multiple configmaps using multiple --configmap flags."}
 	fnLogReverseQueryFlag := cli.BoolFlag{Name: "reverse, r", Usage: "specify the log reverse query base on time, it will be invalid if the 'follow' flag is specified"}
 	fnLogCountFlag := cli.StringFlag{Name: "recordcount", Usage: "the n most recent log records"}
+	fnLogGrepFlag := cli.StringFlag{Name: "grep", Usage: "only show log messages containing the text, filtered by the log database"}
+	fnLogRegexFlag := cli.StringFlag{Name: "regex", Usage: "only show log messages matching the regular expression (RE2 syntax), filtered by the log database"}
+	fnLogFieldFlag := cli.StringSliceFlag{Name: "field", Usage: "only show JSON log messages with the field value, e.g. --field level=error; nested fields are separated by dots, can be repeated"}
+	fnLogReqIDFlag := cli.StringFlag{Name: "reqid", Usage: "only show logs of the invocation with the request ID, returned by the router in the X-Fission-Request-Id response header"}
+	fnLogOutputFlag := cli.StringFlag{Name: "output, o", Value: logOutputText, Usage: "log output format: text, detail, raw (message only) or json"}
 	fnForceFlag := cli.BoolFlag{Name: "force", Usage: "Force update a package even if it is used by one or more functions"}
-	fnExecutorTypeFlag := cli.StringFlag{Name: "executortype", Value: types.ExecutorTypePoolmgr, Usage: "Executor type for execution; one of 'poolmgr', 'newdeploy' defaults to 'poolmgr'"}
+	fnExecutorTypeFlag := cli.StringFlag{Name: "executortype", Value: types.ExecutorTypePoolmgr, Usage: "Executor type for execution; one of 'poolmgr', 'newdeploy' or 'container' defaults to 'poolmgr' ('container' if --image is given)"}
+	fnImageFlag := cli.StringFlag{Name: "image", Usage: "Container image serving the function over HTTP, run without environment or package"}
+	fnPortFlag := cli.IntFlag{Name: "port", Value: 8888, Usage: "Port the container image of the function listens on"}
+	fnFromImageFlag := cli.StringFlag{Name: "from-image", Usage: "Image of the environment runtime with the function code baked in, run by the newdeploy executor without package"}
+	fnImageCodePathFlag := cli.StringFlag{Name: "image-code-path", Usage: "Absolute path of the function code in the --from-image image, loaded on specialization; if empty the image isn't specialized"}
 	fnExecutionTimeoutFlag := cli.IntFlag{Name: "fntimeout, ft", Value: 60, Usage: "Time duration to wait for the response while executing the function. If the flag is not provided, by default it will wait of 60s for the response."}
-
+	fnConcurrencyFlag := cli.IntFlag{Name: "concurrency", Usage: "Maximum number of requests each router instance sends to the function at the same time; defaults to 0 (unlimited)"}
+	fnSpreadFlag := cli.StringFlag{Name: "spread", Usage: "Spread the pods of the function across failure domains: node, zone, or none to stop spreading them on update"}
+	fnSpreadRequiredFlag := cli.BoolFlag{Name: "spread-required", Usage: "Make --spread a hard constraint of newdeploy and container functions, pods that would share a node or zone with another pod of the function stay pending"}
+	fnVolumeFlag := cli.StringSliceFlag{Name: "volume", Usage: "Mount a volume into newdeploy and container function pods: pvc:claim:/path[:ro] or emptydir:name:/path, or name- to remove it on update; can be repeated"}
+	fnScratchSizeFlag := cli.StringFlag{Name: "scratch-size", Usage: "Size limit of the emptydir volumes of --volume, e.g. 2Gi; mounts an emptydir volume at /scratch if none is given"}
+	fnInheritFromFlag := cli.StringFlag{Name: "inherit-from", Usage: "Function whose secrets, configmaps, environment variables and resource settings are used as the defaults of the new function"}
+	fnConfigFromFlag := cli.StringFlag{Name: "from", Usage: "Function to copy the configuration from"}
+	fnConfigToFlag := cli.StringFlag{Name: "to", Usage: "Function to copy the configuration to"}
+	fnIdleTimeoutFlag := cli.IntFlag{Name: "idletimeout", Usage: "Seconds without requests after which a newdeploy function is scaled down to --minscale, down to zero pods with --minscale 0; defaults to the executor setting"}
+	fnQueueLengthFlag := cli.IntFlag{Name: "queuelength", Usage: "Number of requests queued when the function reaches --concurrency, excess requests are rejected with 429; defaults to 0"}
+
+	fnVerifyCasesFlag := cli.StringFlag{Name: "cases", Usage: "YAML file of the cases, each with a request and the expected status, headers, body and JSON path values of the response"}
+	fnVerifyJUnitFlag := cli.StringFlag{Name: "junit", Usage: "write the results as JUnit XML to the file"}
 	fnTimeoutFlag := cli.DurationFlag{Name: "timeout, t", Value: 30 * time.Second, Usage: "The length of time to wait for the response. If set to zero or negative number, no timeout is set."}
+	fnProfileDurationFlag := cli.DurationFlag{Name: "duration", Value: 30 * time.Second, Usage: "Duration of the profile capture, at most 5m"}
+	fnProfileTypeFlag := cli.StringFlag{Name: "type", Value: types.ProfileTypeCPU, Usage: "Profile type, e.g. cpu or heap; the supported types depend on the environment"}
+	fnProfileOutputFlag := cli.StringFlag{Name: "output, o", Value: ".", Usage: "Directory to save the profiles of function pods to"}
+
+	fnUpdateIfExistsFlag := cli.BoolFlag{Name: "update-if-exists", Usage: "Update the function if it exists instead of failing"}
 
 	fnSubcommands := []cli.Command{
-		{Name: "create", Usage: "Create new function (and optionally, an HTTP route to it)", Flags: []cli.Flag{fnNameFlag, fnNamespaceFlag, fnEnvNameFlag, envNamespaceFlag, specSaveFlag, fnCodeFlag, fnSrcArchiveFlag, fnDeployArchiveFlag, fnEntryPointFlag, fnBuildCmdFlag, fnPkgNameFlag, htUrlFlag, htMethodFlag, minCpu, maxCpu, minMem, maxMem, minScale, maxScale, fnExecutorTypeFlag, targetcpu, fnCfgMapFlag, fnSecretFlag, specializationTimeoutFlag, fnExecutionTimeoutFlag}, Action: fnCreate},
+		{Name: "create", Usage: "Create new function (and optionally, an HTTP route to it)", Flags: []cli.Flag{fnNameFlag, fnNamespaceFlag, fnEnvNameFlag, envNamespaceFlag, specSaveFlag, fnUpdateIfExistsFlag, fnCodeFlag, fnCodeLiteralFlag, fnCodeNameFlag, fnSrcArchiveFlag, fnDeployArchiveFlag, fnEntryPointFlag, fnBuildCmdFlag, fnBuildEnvFlag, fnBuildSecretFlag, fnGitSecretFlag, fnPkgNameFlag, htUrlFlag, fnRouteMethodFlag, fnRouteNameFlag, htIngressFlag, htIngressRuleFlag, htIngressAnnotationFlag, htIngressTLSFlag, minCpu, maxCpu, minMem, maxMem, minScale, maxScale, fnExecutorTypeFlag, targetcpu, fnCfgMapFlag, fnSecretFlag, specializationTimeoutFlag, fnMultiplexFlag, fnIsolationFlag, fnPrespecializedFlag, fnExecutionTimeoutFlag, fnConcurrencyFlag, fnQueueLengthFlag, fnIdleTimeoutFlag, fnImageFlag, fnPortFlag, fnFromImageFlag, fnImageCodePathFlag, labelFlag, annotationFlag, nodeSelectorFlag, tolerationFlag, fnSpreadFlag, fnSpreadRequiredFlag, fnVolumeFlag, fnScratchSizeFlag, fnInheritFromFlag}, Action: fnCreate},
+		{Name: "run-container", Usage: "Create a function running a container image, without environment or package", Flags: []cli.Flag{fnNameFlag, fnNamespaceFlag, fnImageFlag, fnPortFlag, specSaveFlag, htUrlFlag, fnRouteMethodFlag, fnRouteNameFlag, htIngressFlag, htIngressRuleFlag, htIngressAnnotationFlag, htIngressTLSFlag, minCpu, maxCpu, minMem, maxMem, minScale, maxScale, targetcpu, fnCfgMapFlag, fnSecretFlag, fnExecutionTimeoutFlag, fnConcurrencyFlag, fnQueueLengthFlag, fnIdleTimeoutFlag, labelFlag, annotationFlag, nodeSelectorFlag, tolerationFlag, fnSpreadFlag, fnSpreadRequiredFlag}, Action: fnRunContainer},
 		{Name: "get", Usage: "Get function source code", Flags: []cli.Flag{fnNameFlag, fnNamespaceFlag}, Action: fnGet},
 		{Name: "getmeta", Usage: "Get function metadata", Flags: []cli.Flag{fnNameFlag, fnNamespaceFlag}, Action: fnGetMeta},
-		{Name: "update", Usage: "Update function source code", Flags: []cli.Flag{fnNameFlag, fnNamespaceFlag, fnEnvNameFlag, envNamespaceFlag, fnCodeFlag, fnSrcArchiveFlag, fnDeployArchiveFlag, fnEntryPointFlag, fnPkgNameFlag, pkgNamespaceFlag, fnBuildCmdFlag, fnForceFlag, minCpu, maxCpu, minMem, maxMem, minScale, maxScale, fnExecutorTypeFlag, targetcpu, specializationTimeoutFlag, fnExecutionTimeoutFlag}, Action: fnUpdate},
+		{Name: "describe", Usage: "Show details of a function, its package, triggers and runtime status", Flags: []cli.Flag{fnNameFlag, fnNamespaceFlag}, Action: fnDescribe},
+		{Name: "edit", Usage: "Edit a function as YAML in $EDITOR, and update it after validating the package and environment references", Flags: []cli.Flag{fnNameFlag, fnNamespaceFlag}, Action: fnEdit},
+		{Name: "update", Usage: "Update function source code", Flags: []cli.Flag{fnNameFlag, fnNamespaceFlag, fnEnvNameFlag, envNamespaceFlag, fnCodeFlag, fnCodeLiteralFlag, fnCodeNameFlag, fnSrcArchiveFlag, fnDeployArchiveFlag, fnEntryPointFlag, fnPkgNameFlag, pkgNamespaceFlag, fnBuildCmdFlag, fnGitSecretFlag, fnForceFlag, minCpu, maxCpu, minMem, maxMem, minScale, maxScale, fnExecutorTypeFlag, targetcpu, specializationTimeoutFlag, fnMultiplexFlag, fnIsolationFlag, fnPrespecializedFlag, fnExecutionTimeoutFlag, fnConcurrencyFlag, fnQueueLengthFlag, fnIdleTimeoutFlag, fnImageFlag, fnPortFlag, fnFromImageFlag, fnImageCodePathFlag, labelFlag, annotationFlag, nodeSelectorFlag, tolerationFlag, fnSpreadFlag, fnSpreadRequiredFlag, fnVolumeFlag, fnScratchSizeFlag}, Action: fnUpdate},
 		{Name: "delete", Usage: "Delete function", Flags: []cli.Flag{fnNameFlag, fnNamespaceFlag}, Action: fnDelete},
+		{Name: "config", Usage: "Manage the configuration of functions", Subcommands: []cli.Command{
+			{Name: "copy", Usage: "Copy secrets, configmaps, environment variables and resource settings from one function to another", Flags: []cli.Flag{fnConfigFromFlag, fnConfigToFlag, fnNamespaceFlag}, Action: fnConfigCopy},
+		}},
 		// TODO : for fnList, i feel like it's nice to allow --fns all, to list functions across all namespaces for cluster admins, although, this is against ns isolation.
 		// so, in the future, if we end up using kubeconfig in fission cli and enforcing rolebindings to be created for users by admins etc, we can add this option at the time.
-		{Name: "list", Usage: "List all functions in a namespace if specified, else, list functions across all namespaces", Flags: []cli.Flag{fnNamespaceFlag}, Action: fnList},
-		{Name: "logs", Usage: "Display function logs", Flags: []cli.Flag{fnNameFlag, fnNamespaceFlag, fnPodFlag, fnFollowFlag, fnDetailFlag, fnLogDBTypeFlag, fnLogReverseQueryFlag, fnLogCountFlag}, Action: fnLogs},
+		{Name: "list", Usage: "List all functions in a namespace if specified, else, list functions across all namespaces", Flags: []cli.Flag{fnNamespaceFlag, selectorFlag}, Action: fnList},
+		{Name: "logs", Usage: "Display function logs", Flags: []cli.Flag{fnNameFlag, fnNamespaceFlag, fnPodFlag, fnFollowFlag, fnDetailFlag, fnLogDBTypeFlag, fnLogReverseQueryFlag, fnLogCountFlag, fnLogGrepFlag, fnLogRegexFlag, fnLogFieldFlag, fnLogReqIDFlag, fnLogOutputFlag, fnLogPreviousFlag}, Action: fnLogs},
+		{Name: "verify", Usage: "Run the request and expected response cases of a file against a function, e.g. in CI", Flags: []cli.Flag{fnNameFlag, fnNamespaceFlag, fnVerifyCasesFlag, fnVerifyJUnitFlag, fnTimeoutFlag}, Action: fnVerify},
+		{Name: "metrics", Usage: "Summarize invocations, errors, latency and cold starts of a function from Prometheus", Flags: []cli.Flag{fnNameFlag, fnNamespaceFlag, fnMetricsSinceFlag}, Action: fnMetrics},
+		{Name: "stats", Usage: "Show invocations, errors, cold starts and latency of a function as recorded by the routers", Flags: []cli.Flag{fnNameFlag, fnNamespaceFlag, fnStatsSinceFlag}, Action: fnStats},
+		{Name: "pods", Usage: "List the pods currently serving a function", Flags: []cli.Flag{fnNameFlag, fnNamespaceFlag}, Action: fnPods},
+		{Name: "top", Usage: "Show the CPU and memory usage of the pods of a function, requires metrics-server", Flags: []cli.Flag{fnNameFlag, fnNamespaceFlag}, Action: fnTop},
 		{Name: "test", Usage: "Test a function", Flags: []cli.Flag{fnNameFlag, fnNamespaceFlag, fnEnvNameFlag,
-			fnCodeFlag, fnSrcArchiveFlag, htMethodFlag, fnBodyFlag, fnHeaderFlag, fnQueryFlag, fnTimeoutFlag},
+			fnCodeFlag, fnSrcArchiveFlag, htMethodFlag, fnBodyFlag, fnBodyBinaryFlag, fnContentTypeFlag, fnHeaderFlag, fnQueryFlag, fnTimeoutFlag, fnTestAliasFlag,
+			fnTestLocalFlag, envNamespaceFlag, fnDeployArchiveFlag, fnEntryPointFlag, fnBuildCmdFlag, fnTestRuntimeImageFlag, fnTestBuilderImageFlag},
 			Action: fnTest},
+		{Name: "profile", Usage: "Capture a profile of the running pods of a function", Flags: []cli.Flag{fnNameFlag, fnNamespaceFlag, fnProfileDurationFlag, fnProfileTypeFlag, fnProfileOutputFlag}, Action: fnProfile},
 	}
 
 	// httptriggers
 	htNameFlag := cli.StringFlag{Name: "name", Usage: "HTTP Trigger name"}
 	htHostFlag := cli.StringFlag{Name: "host", Usage: "(DEPRECATED) Use --ingressrule instead"}
-	htIngressFlag := cli.BoolFlag{Name: "createingress", Usage: "Creates ingress with same URL, defaults to false"}
-	htIngressRuleFlag := cli.StringFlag{Name: "ingressrule", Usage: "Host for Ingress rule: --ingressrule host=path (the format of host/path depends on what ingress controller you used)"}
-	htIngressAnnotationFlag := cli.StringSliceFlag{Name: "ingressannotation", Usage: "Annotation for Ingress: --ingressannotation key=value (the format of annotation depends on what ingress controller you used)"}
-	htIngressTLSFlag := cli.StringFlag{Name: "ingresstls", Usage: "Name of the Secret contains TLS key and crt for Ingress (the usability of TLS features depends on what ingress controller you used)"}
+	htWebsocketFlag := cli.BoolFlag{Name: "allow-websocket", Usage: "Allow websocket connections to the function, the method must be GET; defaults to false"}
 	htFnNameFlag := cli.StringSliceFlag{Name: "function", Usage: "Name(s) of the function for this trigger. (If 2 functions are supplied with this flag, traffic gets routed to them based on weights supplied with --weight flag.)"}
 	htFnWeightFlag := cli.IntSliceFlag{Name: "weight", Usage: "Weight for each function supplied with --function flag, in the same order. Used for canary deployment"}
+	htAliasFlag := cli.StringFlag{Name: "alias", Usage: "Name of the function alias for this trigger, instead of --function; traffic follows the alias when it's updated"}
 	htFnFilterFlag := cli.StringFlag{Name: "function", Usage: "Name of the function for trigger(s)"}
+	htFaultDelayFlag := cli.DurationFlag{Name: "fault-delay", Usage: "Fault injection: delay added to the requests chosen by --fault-delay-percent, e.g. 2s"}
+	htFaultDelayPercentFlag := cli.IntFlag{Name: "fault-delay-percent", Usage: "Fault injection: percentage (0-100) of requests to delay"}
+	htFaultAbortStatusFlag := cli.IntFlag{Name: "fault-abort-status", Usage: "Fault injection: HTTP status code returned for aborted requests; defaults to 503"}
+	htFaultAbortPercentFlag := cli.IntFlag{Name: "fault-abort-percent", Usage: "Fault injection: percentage (0-100) of requests to abort without calling the function"}
+	htFaultDisableFlag := cli.BoolFlag{Name: "fault-disable", Usage: "Remove the fault injection of the trigger"}
+	htConnectTimeoutFlag := cli.DurationFlag{Name: "connect-timeout", Usage: "Timeout to connect to a function pod, e.g. 1s; defaults to the router setting"}
+	htResponseHeaderTimeoutFlag := cli.DurationFlag{Name: "response-header-timeout", Usage: "Timeout to wait for the response headers of the function once the request is sent, e.g. 30s; defaults to the router setting"}
+	htTotalTimeoutFlag := cli.DurationFlag{Name: "total-timeout, timeout", Usage: "Timeout of a function call, e.g. 2m; defaults to the function timeout"}
+	htRateLimitRPSFlag := cli.IntFlag{Name: "ratelimit-rps", Usage: "Requests per second allowed through the trigger, requests above the rate get 429; 0 removes the rate limit"}
+	htRateLimitBurstFlag := cli.IntFlag{Name: "ratelimit-burst", Usage: "Requests allowed at once above --ratelimit-rps; defaults to the rate"}
+	htRateLimitPerClientIPFlag := cli.BoolFlag{Name: "ratelimit-per-client-ip", Usage: "Apply the rate limit to each client IP address instead of all requests of the trigger"}
+	htMaxConcurrencyFlag := cli.IntFlag{Name: "max-concurrency", Usage: "Requests of the trigger in flight at once per router, requests above the cap get 503; 0 removes the cap"}
+	htRetriesFlag := cli.IntFlag{Name: "retries", Usage: "Max number of retries of a failed function call, with backoff; defaults to the router setting"}
+	htAuthFlag := cli.StringFlag{Name: "auth", Usage: "Authenticate the requests of the trigger: jwt for HMAC signed tokens in the Authorization header, apikey for keys in the X-Fission-Api-Key header, or none to remove the auth"}
+	htAuthSecretFlag := cli.StringFlag{Name: "auth-secret", Usage: "Secret with the keys of --auth in the namespace of the trigger: its 'key' entry signs the tokens of jwt, every entry is a key for apikey"}
+	htAuthIssuerFlag := cli.StringFlag{Name: "auth-issuer", Usage: "Issuer (iss claim) the tokens of --auth jwt must have (optional)"}
+	htAuthAudienceFlag := cli.StringFlag{Name: "auth-audience", Usage: "Audience (aud claim) the tokens of --auth jwt must have (optional)"}
+	htRetryOnFlag := cli.StringSliceFlag{Name: "retry-on", Usage: "Failures to retry: 5xx, connect-failure; use it multiple times for both, defaults to connect-failure"}
+	htAPIVersionFlag := cli.StringSliceFlag{Name: "api-version", Usage: "Route the requests of an API version to a function, VERSION=FUNCTION; VERSION- removes the version, use it multiple times for more versions"}
+	htAPIVersionHeaderFlag := cli.StringFlag{Name: "api-version-header", Usage: "Request header with the API version of --api-version; defaults to X-API-Version"}
+	htAPIVersionAcceptFlag := cli.BoolFlag{Name: "api-version-accept", Usage: "Also read the API version of --api-version from the Accept header, e.g. application/vnd.example.v2+json or version=2"}
+	htProtocolFlag := cli.StringFlag{Name: "protocol", Usage: "Protocol to the function: http or grpc, grpc proxies HTTP/2 and keeps gRPC streams open; the router must serve HTTP/2 for grpc. Defaults to http"}
 	htSubcommands := []cli.Command{
-		{Name: "create", Aliases: []string{"add"}, Usage: "Create HTTP trigger", Flags: []cli.Flag{htNameFlag, htMethodFlag, htUrlFlag, htFnNameFlag, htIngressRuleFlag, htIngressAnnotationFlag, htIngressTLSFlag, htIngressFlag, fnNamespaceFlag, specSaveFlag, htFnWeightFlag, htHostFlag}, Action: htCreate},
+		{Name: "create", Aliases: []string{"add"}, Usage: "Create HTTP trigger", Flags: []cli.Flag{htNameFlag, htMethodsFlag, htUrlFlag, htPrefixFlag, htKeepPrefixFlag, htFnNameFlag, htIngressRuleFlag, htIngressAnnotationFlag, htIngressTLSFlag, htIngressFlag, htWebsocketFlag, fnNamespaceFlag, specSaveFlag, htFnWeightFlag, htHostFlag, htFaultDelayFlag, htFaultDelayPercentFlag, htFaultAbortStatusFlag, htFaultAbortPercentFlag, htConnectTimeoutFlag, htResponseHeaderTimeoutFlag, htTotalTimeoutFlag, htRateLimitRPSFlag, htRateLimitBurstFlag, htRateLimitPerClientIPFlag, htMaxConcurrencyFlag, htRetriesFlag, htRetryOnFlag, htAliasFlag, htAuthFlag, htAuthSecretFlag, htAuthIssuerFlag, htAuthAudienceFlag, htAPIVersionFlag, htAPIVersionHeaderFlag, htAPIVersionAcceptFlag, htProtocolFlag}, Action: htCreate},
 		{Name: "get", Usage: "Get HTTP trigger", Flags: []cli.Flag{htNameFlag}, Action: htGet},
-		{Name: "update", Usage: "Update HTTP trigger", Flags: []cli.Flag{htNameFlag, triggerNamespaceFlag, htFnNameFlag, htIngressRuleFlag, htIngressAnnotationFlag, htIngressTLSFlag, htIngressFlag, htFnWeightFlag, htHostFlag}, Action: htUpdate},
+		{Name: "update", Usage: "Update HTTP trigger", Flags: []cli.Flag{htNameFlag, triggerNamespaceFlag, htMethodsFlag, htPrefixFlag, htKeepPrefixFlag, htFnNameFlag, htIngressRuleFlag, htIngressAnnotationFlag, htIngressTLSFlag, htIngressFlag, htWebsocketFlag, htFnWeightFlag, htHostFlag, htFaultDelayFlag, htFaultDelayPercentFlag, htFaultAbortStatusFlag, htFaultAbortPercentFlag, htFaultDisableFlag, htConnectTimeoutFlag, htResponseHeaderTimeoutFlag, htTotalTimeoutFlag, htRateLimitRPSFlag, htRateLimitBurstFlag, htRateLimitPerClientIPFlag, htMaxConcurrencyFlag, htRetriesFlag, htRetryOnFlag, htAliasFlag, htAuthFlag, htAuthSecretFlag, htAuthIssuerFlag, htAuthAudienceFlag, htAPIVersionFlag, htAPIVersionHeaderFlag, htAPIVersionAcceptFlag, htProtocolFlag}, Action: htUpdate},
 		{Name: "delete", Usage: "Delete HTTP trigger", Flags: []cli.Flag{htNameFlag, triggerNamespaceFlag, htFnFilterFlag}, Action: htDelete},
-		{Name: "list", Usage: "List HTTP triggers", Flags: []cli.Flag{triggerNamespaceFlag, htFnFilterFlag}, Action: htList},
+		{Name: "list", Usage: "List HTTP triggers", Flags: []cli.Flag{triggerNamespaceFlag, htFnFilterFlag, selectorFlag}, Action: htList},
 	}
 
 	// timetriggers
 	ttNameFlag := cli.StringFlag{Name: "name", Usage: "Time Trigger name"}
 	ttCronFlag := cli.StringFlag{Name: "cron", Usage: "Time trigger cron spec with each asterisk representing respectively second, minute, hour, the day of the month, month and day of the week. Also supports readable formats like '@every 5m', '@hourly'"}
 	ttFnNameFlag := cli.StringFlag{Name: "function", Usage: "Function name"}
+	ttTimezoneFlag := cli.StringFlag{Name: "timezone", Usage: "IANA time zone name in which the cron spec is evaluated, e.g. 'America/New_York'; defaults to UTC"}
 	ttRoundFlag := cli.IntFlag{Name: "round", Value: 1, Usage: "Get next N rounds of invocation time"}
 	ttSubcommands := []cli.Command{
-		{Name: "create", Aliases: []string{"add"}, Usage: "Create time trigger", Flags: []cli.Flag{ttNameFlag, ttFnNameFlag, fnNamespaceFlag, ttCronFlag, specSaveFlag}, Action: ttCreate},
+		{Name: "create", Aliases: []string{"add"}, Usage: "Create time trigger", Flags: []cli.Flag{ttNameFlag, ttFnNameFlag, fnNamespaceFlag, ttCronFlag, ttTimezoneFlag, specSaveFlag}, Action: ttCreate},
 		{Name: "get", Usage: "Get time trigger", Flags: []cli.Flag{triggerNamespaceFlag}, Action: ttGet},
-		{Name: "update", Usage: "Update time trigger", Flags: []cli.Flag{ttNameFlag, triggerNamespaceFlag, ttCronFlag, ttFnNameFlag}, Action: ttUpdate},
+		{Name: "update", Usage: "Update time trigger", Flags: []cli.Flag{ttNameFlag, triggerNamespaceFlag, ttCronFlag, ttTimezoneFlag, ttFnNameFlag}, Action: ttUpdate},
 		{Name: "delete", Usage: "Delete time trigger", Flags: []cli.Flag{ttNameFlag, triggerNamespaceFlag}, Action: ttDelete},
-		{Name: "list", Usage: "List time triggers", Flags: []cli.Flag{triggerNamespaceFlag}, Action: ttList},
-		{Name: "showschedule", Aliases: []string{"show"}, Usage: "Show schedule for cron spec", Flags: []cli.Flag{ttCronFlag, ttRoundFlag}, Action: ttTest},
+		{Name: "list", Usage: "List time triggers", Flags: []cli.Flag{triggerNamespaceFlag, selectorFlag}, Action: ttList},
+		{Name: "showschedule", Aliases: []string{"show"}, Usage: "Show schedule for cron spec", Flags: []cli.Flag{ttCronFlag, ttTimezoneFlag, ttRoundFlag}, Action: ttTest},
 	}
 
 	// Message queue trigger
 	mqtNameFlag := cli.StringFlag{Name: "name", Usage: "Message queue Trigger name"}
 	mqtFnNameFlag := cli.StringFlag{Name: "function", Usage: "Function name"}
-	mqtMQTypeFlag := cli.StringFlag{Name: "mqtype", Value: "nats-streaming", Usage: "Message queue type, e.g. nats-streaming, azure-storage-queue (optional)"}
+	mqtAliasFlag := cli.StringFlag{Name: "alias", Usage: "Function alias name, instead of --function"}
+	mqtMQTypeFlag := cli.StringFlag{Name: "mqtype", Value: "nats-streaming", Usage: "Message queue type, e.g. nats-streaming (deprecated), azure-storage-queue, kafka, rabbitmq, gcp-pubsub, azure-servicebus, nats-jetstream (optional)"}
 	mqtTopicFlag := cli.StringFlag{Name: "topic", Usage: "Message queue Topic the trigger listens on"}
 	mqtRespTopicFlag := cli.StringFlag{Name: "resptopic", Usage: "Topic that the function response is sent on (optional; response discarded if unspecified)"}
 	mqtErrorTopicFlag := cli.StringFlag{Name: "errortopic", Usage: "Topic that the function error messages are sent to (optional; errors discarded if unspecified"}
 	mqtMaxRetries := cli.IntFlag{Name: "maxretries", Value: 0, Usage: "Maximum number of times the function will be retried upon failure (optional; default is 0)"}
 	mqtMsgContentType := cli.StringFlag{Name: "contenttype, c", Value: "application/json", Usage: "Content type of messages that publish to the topic (optional)"}
+	mqtKafkaBrokersFlag := cli.StringSliceFlag{Name: "kafkabrokers", Usage: "Kafka broker address, e.g. broker-1:9092; can be specified multiple times (optional; kafka only, default to the brokers of mqtrigger deployment)"}
+	mqtKafkaGroupFlag := cli.StringFlag{Name: "kafkagroup", Usage: "Kafka consumer group of the trigger (optional; kafka only, default to the trigger UID)"}
+	mqtKafkaTLSFlag := cli.BoolFlag{Name: "kafkatls", Usage: "Use TLS to connect to Kafka brokers, --kafkatls=false turns it off on update (optional; kafka only)"}
+	mqtKafkaTLSInsecureFlag := cli.BoolFlag{Name: "kafkatlsinsecure", Usage: "Skip the verification of Kafka broker certificates (optional; kafka only)"}
+	mqtRabbitMQQueueFlag := cli.StringFlag{Name: "queue", Usage: "Queue the trigger consumes from, same as --topic (rabbitmq only)"}
+	mqtRabbitMQExchangeFlag := cli.StringFlag{Name: "exchange", Usage: "Exchange the queue is bound to and responses are published to (optional; rabbitmq only, default to the default exchange)"}
+	mqtRabbitMQRoutingKeyFlag := cli.StringFlag{Name: "routingkey", Usage: "Routing key the queue is bound to the exchange with (optional; rabbitmq only, default to the queue name)"}
+	mqtRabbitMQPrefetchFlag := cli.IntFlag{Name: "prefetch", Usage: "Number of unacknowledged messages delivered to the function at a time (optional; rabbitmq only, default is 1)"}
+	mqtRabbitMQSecretFlag := cli.StringFlag{Name: "credentialsecret", Usage: "(DEPRECATED) Use --secret instead"}
+	mqtGCPProjectFlag := cli.StringFlag{Name: "project", Usage: "GCP project of the topics and the subscription (optional; gcp-pubsub only, default to the project of mqtrigger deployment)"}
+	mqtGCPSubscriptionFlag := cli.StringFlag{Name: "subscription", Usage: "Pub/Sub subscription the messages are pulled from, created if it doesn't exist (optional; gcp-pubsub, default to fission-<trigger UID>), or subscription of the Service Bus topic (azure-servicebus, --topic is a queue without it)"}
+	mqtGCPAckDeadlineFlag := cli.DurationFlag{Name: "ackdeadline", Usage: "Ack deadline of the subscription created for the trigger, between 10s and 10m (optional; gcp-pubsub only, default is 10s)"}
+	mqtASBSessionsFlag := cli.BoolFlag{Name: "sessions", Usage: "Receive the messages of a session-enabled queue or subscription one session at a time, in order (optional; azure-servicebus only)"}
+	mqtNJSStreamFlag := cli.StringFlag{Name: "stream", Usage: "JetStream stream the topic is a subject of (nats-jetstream only, required)"}
+	mqtNJSDurableFlag := cli.StringFlag{Name: "durable", Usage: "Durable consumer of the stream the messages are received from, created if it doesn't exist (optional; nats-jetstream only, default to fission-<trigger UID>)"}
+	mqtNJSAckWaitFlag := cli.DurationFlag{Name: "ackwait", Usage: "Time JetStream waits for a message to be acknowledged before redelivering it, used when the consumer is created (optional; nats-jetstream only, default is 30s)"}
+	mqtNJSMaxDeliverFlag := cli.IntFlag{Name: "maxdeliver", Usage: "Number of times a message the function fails on is delivered before it's sent to the error topic and dropped (optional; nats-jetstream only, default is 1)"}
+	mqtSecretFlag := cli.StringFlag{Name: "secret", Usage: "Secret in the trigger namespace with the credentials to connect to the message queue: username and password keys for authentication (SASL/PLAIN for kafka), ca.crt, tls.crt and tls.key keys for TLS, credentials.json key with a service account key for gcp-pubsub, connectionString key for azure-servicebus (optional; kafka, rabbitmq, gcp-pubsub, azure-servicebus and nats-jetstream only)"}
 	mqtSubcommands := []cli.Command{
-		{Name: "create", Aliases: []string{"add"}, Usage: "Create Message queue trigger", Flags: []cli.Flag{mqtNameFlag, mqtFnNameFlag, fnNamespaceFlag, mqtMQTypeFlag, mqtTopicFlag, mqtRespTopicFlag, mqtErrorTopicFlag, mqtMaxRetries, mqtMsgContentType, specSaveFlag}, Action: mqtCreate},
+		{Name: "create", Aliases: []string{"add"}, Usage: "Create Message queue trigger", Flags: []cli.Flag{mqtNameFlag, mqtFnNameFlag, mqtAliasFlag, fnNamespaceFlag, mqtMQTypeFlag, mqtTopicFlag, mqtRespTopicFlag, mqtErrorTopicFlag, mqtMaxRetries, mqtMsgContentType, mqtKafkaBrokersFlag, mqtKafkaGroupFlag, mqtKafkaTLSFlag, mqtKafkaTLSInsecureFlag, mqtRabbitMQQueueFlag, mqtRabbitMQExchangeFlag, mqtRabbitMQRoutingKeyFlag, mqtRabbitMQPrefetchFlag, mqtRabbitMQSecretFlag, mqtGCPProjectFlag, mqtGCPSubscriptionFlag, mqtGCPAckDeadlineFlag, mqtASBSessionsFlag, mqtNJSStreamFlag, mqtNJSDurableFlag, mqtNJSAckWaitFlag, mqtNJSMaxDeliverFlag, mqtSecretFlag, specSaveFlag}, Action: mqtCreate},
 		{Name: "get", Usage: "Get message queue trigger", Flags: []cli.Flag{triggerNamespaceFlag}, Action: mqtGet},
-		{Name: "update", Usage: "Update message queue trigger", Flags: []cli.Flag{mqtNameFlag, triggerNamespaceFlag, mqtTopicFlag, mqtRespTopicFlag, mqtErrorTopicFlag, mqtMaxRetries, mqtFnNameFlag, mqtMsgContentType}, Action: mqtUpdate},
+		{Name: "update", Usage: "Update message queue trigger", Flags: []cli.Flag{mqtNameFlag, triggerNamespaceFlag, mqtTopicFlag, mqtRespTopicFlag, mqtErrorTopicFlag, mqtMaxRetries, mqtFnNameFlag, mqtAliasFlag, mqtMsgContentType, mqtKafkaBrokersFlag, mqtKafkaGroupFlag, mqtKafkaTLSFlag, mqtKafkaTLSInsecureFlag, mqtRabbitMQQueueFlag, mqtRabbitMQExchangeFlag, mqtRabbitMQRoutingKeyFlag, mqtRabbitMQPrefetchFlag, mqtRabbitMQSecretFlag, mqtGCPProjectFlag, mqtGCPSubscriptionFlag, mqtGCPAckDeadlineFlag, mqtASBSessionsFlag, mqtNJSStreamFlag, mqtNJSDurableFlag, mqtNJSAckWaitFlag, mqtNJSMaxDeliverFlag, mqtSecretFlag}, Action: mqtUpdate},
 		{Name: "delete", Usage: "Delete message queue trigger", Flags: []cli.Flag{mqtNameFlag, triggerNamespaceFlag}, Action: mqtDelete},
-		{Name: "list", Usage: "List message queue triggers", Flags: []cli.Flag{mqtMQTypeFlag, triggerNamespaceFlag}, Action: mqtList},
+		{Name: "list", Usage: "List message queue triggers", Flags: []cli.Flag{mqtMQTypeFlag, triggerNamespaceFlag, selectorFlag}, Action: mqtList},
 	}
 
 	// Recorders
@@ -213,12 +325,19 @@ func NewCliApp() *cli.App {
 	filterTrigger := cli.StringFlag{Name: "trigger", Usage: "Filter records by trigger"}
 	verbosityFlag := cli.BoolFlag{Name: "v", Usage: "Toggle verbosity -- view more detailed requests/responses"}
 	vvFlag := cli.BoolFlag{Name: "vv", Usage: "Toggle verbosity -- view raw requests/responses"}
+	recordsExportToFlag := cli.StringFlag{Name: "to", Usage: "File to export records to, defaults to stdout"}
+	recordsImportFromFlag := cli.StringFlag{Name: "from", Usage: "File of exported records to import"}
+	recordsImportRecorderFlag := cli.StringFlag{Name: "recorder", Usage: "Recorder to add the imported records to, so they can be viewed with filters"}
 	recViewSubcommands := []cli.Command{
 		{Name: "view", Usage: "View existing records", Flags: []cli.Flag{filterTimeTo, filterTimeFrom, filterFunction, filterTrigger, verbosityFlag, vvFlag}, Action: recordsView},
+		{Name: "export", Usage: "Export all records as newline-delimited JSON", Flags: []cli.Flag{recordsExportToFlag}, Action: recordsExport},
+		{Name: "import", Usage: "Import records exported from another cluster", Flags: []cli.Flag{recordsImportFromFlag, recordsImportRecorderFlag}, Action: recordsImport},
 	}
 
 	// Replay records
 	reqIDFlag := cli.StringFlag{Name: "reqUID", Usage: "Replay a particular request by providing the reqUID (to view reqUIDs, do 'fission records view')"}
+	replayTargetFlag := cli.StringFlag{Name: "target", Usage: "Replay the request against this function instead of its recorded path"}
+	replayTargetNamespaceFlag := cli.StringFlag{Name: "targetNamespace", Value: metav1.NamespaceDefault, Usage: "Namespace of the target function"}
 
 	// environments
 	envNameFlag := cli.StringFlag{Name: cmd.RESOURCE_NAME, Usage: "Environment name"}
@@ -230,12 +349,38 @@ func NewCliApp() *cli.App {
 	envExternalNetworkFlag := cli.BoolFlag{Name: cmd.ENVIRONMENT_EXTERNAL_NETWORK, Usage: "Allow environment access external network when istio feature enabled (optional, defaults to false)"}
 	envTerminationGracePeriodFlag := cli.Int64Flag{Name: cmd.GetCliFlagName(cmd.ENVIRONMENT_GRACE_PERIOD, cmd.ENVIRONMENT_GRACE_PERIOD_ALIAS), Value: 360, Usage: "The grace time (in seconds) for pod to perform connection draining before termination (optional)"}
 	envVersionFlag := cli.IntFlag{Name: cmd.ENVIRONMENT_VERSION, Value: 1, Usage: "Environment API version (1 means v1 interface)"}
+	envRuntimeClassFlag := cli.StringFlag{Name: cmd.ENVIRONMENT_RUNTIME_CLASS, Usage: "Kubernetes RuntimeClass of function and builder pods, e.g. gvisor to sandbox untrusted code (optional)"}
+	envBenchmarkNameFlag := cli.StringSliceFlag{Name: cmd.RESOURCE_NAME, Usage: "Name of the environment to benchmark, can be repeated to compare environments"}
+	envBenchmarkCodeFlag := cli.StringFlag{Name: cmd.BENCHMARK_CODE, Usage: "File of the function to benchmark (optional, defaults to a hello world function for python, nodejs, ruby and php environments)"}
+	envBenchmarkRequestsFlag := cli.IntFlag{Name: cmd.BENCHMARK_REQUESTS, Value: 100, Usage: "Number of sequential requests to measure the warm latency with"}
+	envBenchmarkDurationFlag := cli.IntFlag{Name: cmd.BENCHMARK_DURATION, Value: 10, Usage: "Duration in seconds of the load to measure the max RPS with"}
+	envBenchmarkConcurrencyFlag := cli.IntFlag{Name: cmd.BENCHMARK_CONCURRENCY, Value: 10, Usage: "Number of concurrent clients to measure the max RPS with"}
+	envImagePullSecretFlag := cli.StringFlag{Name: cmd.ENVIRONMENT_IMAGE_PULL_SECRET, Usage: "Secret to pull the runtime and builder images from a private registry, must exist in the namespace of the function and builder pods (optional)"}
+	envBuilderPoolsizeFlag := cli.IntFlag{Name: cmd.ENVIRONMENT_BUILDER_POOLSIZE, Usage: "Number of builder pods, so that builds don't queue behind a single builder (optional, defaults to 1)"}
+	envBuilderMinCpuFlag := cli.IntFlag{Name: cmd.BUILDER_MINCPU, Usage: "Minimum CPU to be assigned to the builder pods (In millicore, minimum 1) (optional)"}
+	envBuilderMaxCpuFlag := cli.IntFlag{Name: cmd.BUILDER_MAXCPU, Usage: "Maximum CPU to be assigned to the builder pods (In millicore, minimum 1) (optional)"}
+	envBuilderMinMemFlag := cli.IntFlag{Name: cmd.BUILDER_MINMEMORY, Usage: "Minimum memory to be assigned to the builder pods (In megabyte) (optional)"}
+	envBuilderMaxMemFlag := cli.IntFlag{Name: cmd.BUILDER_MAXMEMORY, Usage: "Maximum memory to be assigned to the builder pods (In megabyte) (optional)"}
+	envBuildCacheFlag := cli.StringFlag{Name: cmd.ENVIRONMENT_BUILD_CACHE, Usage: "Size of the volume caching downloaded dependencies between builds, e.g. 2Gi; 0 disables the cache (optional)"}
+	envBuildCacheClassFlag := cli.StringFlag{Name: cmd.ENVIRONMENT_BUILD_CACHE_CLASS, Usage: "Storage class of the build cache volume, must support ReadWriteMany if builder pool size > 1 (optional)"}
+	envForceFlag := cli.BoolFlag{Name: cmd.ENVIRONMENT_FORCE, Usage: "Also delete the functions and packages of the environment, otherwise an environment in use isn't deleted"}
+	envConformanceNameFlag := cli.StringFlag{Name: cmd.RESOURCE_NAME, Usage: "Environment whose runtime image and version to check, instead of --image (optional)"}
+	envConformanceImageFlag := cli.StringFlag{Name: cmd.ENVIRONMENT_IMAGE, Usage: "Environment image to run locally with Docker and check"}
+	envConformanceVersionFlag := cli.IntFlag{Name: cmd.ENVIRONMENT_VERSION, Value: 2, Usage: "Environment API version the image implements (1 means v1 interface)"}
+	envConformanceURLFlag := cli.StringFlag{Name: cmd.CONFORMANCE_URL, Usage: "URL of an environment container that is already running, e.g. a pod forwarded with kubectl port-forward, instead of --image; the function must be in the container at --filepath"}
+	envConformanceCodeFlag := cli.StringFlag{Name: cmd.CONFORMANCE_CODE, Usage: "File of the function to check with (optional, defaults to a hello world function for python, nodejs, ruby and php environments)"}
+	envConformanceFilePathFlag := cli.StringFlag{Name: cmd.CONFORMANCE_FILEPATH, Usage: "Path of the function in the container (optional, defaults to /userfunc/deploy, or /userfunc/user for v1)"}
+	envConformanceEntrypointFlag := cli.StringFlag{Name: cmd.CONFORMANCE_ENTRYPOINT, Usage: "Entry point of the function to load (optional)"}
+	envConformanceConcurrencyFlag := cli.IntFlag{Name: cmd.CONFORMANCE_CONCURRENCY, Value: 10, Usage: "Number of concurrent clients calling the function"}
+	envConformanceTimeoutFlag := cli.IntFlag{Name: cmd.CONFORMANCE_TIMEOUT, Value: 30, Usage: "Timeout in seconds of each request to the container, and of its start"}
 	envSubcommands := []cli.Command{
-		{Name: "create", Aliases: []string{"add"}, Usage: "Add an environment", Flags: []cli.Flag{envNameFlag, envNamespaceFlag, envPoolsizeFlag, envImageFlag, envBuilderImageFlag, envBuildCmdFlag, envKeepArchiveFlag, minCpu, maxCpu, minMem, maxMem, envVersionFlag, envExternalNetworkFlag, envTerminationGracePeriodFlag, specSaveFlag}, Action: urfavecli.Wrapper(environment.Create)},
+		{Name: "create", Aliases: []string{"add"}, Usage: "Add an environment", Flags: []cli.Flag{envNameFlag, envNamespaceFlag, envPoolsizeFlag, envImageFlag, envBuilderImageFlag, envBuildCmdFlag, envKeepArchiveFlag, minCpu, maxCpu, minMem, maxMem, envVersionFlag, envExternalNetworkFlag, envTerminationGracePeriodFlag, envRuntimeClassFlag, envImagePullSecretFlag, envBuilderPoolsizeFlag, envBuilderMinCpuFlag, envBuilderMaxCpuFlag, envBuilderMinMemFlag, envBuilderMaxMemFlag, envBuildCacheFlag, envBuildCacheClassFlag, labelFlag, annotationFlag, nodeSelectorFlag, tolerationFlag, specSaveFlag}, Action: urfavecli.Wrapper(environment.Create)},
 		{Name: "get", Usage: "Get environment details", Flags: []cli.Flag{envNameFlag, envNamespaceFlag}, Action: urfavecli.Wrapper(environment.Get)},
-		{Name: "update", Usage: "Update environment", Flags: []cli.Flag{envNameFlag, envNamespaceFlag, envPoolsizeFlag, envImageFlag, envBuilderImageFlag, envBuildCmdFlag, envKeepArchiveFlag, minCpu, maxCpu, minMem, maxMem, envExternalNetworkFlag, envTerminationGracePeriodFlag}, Action: urfavecli.Wrapper(environment.Update)},
-		{Name: "delete", Usage: "Delete environment", Flags: []cli.Flag{envNameFlag, envNamespaceFlag}, Action: urfavecli.Wrapper(environment.Delete)},
-		{Name: "list", Usage: "List all environments", Flags: []cli.Flag{envNamespaceFlag}, Action: urfavecli.Wrapper(environment.List)},
+		{Name: "update", Usage: "Update environment", Flags: []cli.Flag{envNameFlag, envNamespaceFlag, envPoolsizeFlag, envImageFlag, envBuilderImageFlag, envBuildCmdFlag, envKeepArchiveFlag, minCpu, maxCpu, minMem, maxMem, envExternalNetworkFlag, envTerminationGracePeriodFlag, envRuntimeClassFlag, envImagePullSecretFlag, envBuilderPoolsizeFlag, envBuilderMinCpuFlag, envBuilderMaxCpuFlag, envBuilderMinMemFlag, envBuilderMaxMemFlag, envBuildCacheFlag, envBuildCacheClassFlag, labelFlag, annotationFlag, nodeSelectorFlag, tolerationFlag}, Action: urfavecli.Wrapper(environment.Update)},
+		{Name: "delete", Usage: "Delete environment", Flags: []cli.Flag{envNameFlag, envNamespaceFlag, envForceFlag}, Action: urfavecli.Wrapper(environment.Delete)},
+		{Name: "list", Usage: "List all environments", Flags: []cli.Flag{envNamespaceFlag, selectorFlag}, Action: urfavecli.Wrapper(environment.List)},
+		{Name: "benchmark", Usage: "Measure the cold start, warm latency and max RPS of environments on the cluster with a hello world function", Flags: []cli.Flag{envBenchmarkNameFlag, envNamespaceFlag, envBenchmarkCodeFlag, envBenchmarkRequestsFlag, envBenchmarkDurationFlag, envBenchmarkConcurrencyFlag}, Action: urfavecli.Wrapper(environment.Benchmark)},
+		{Name: "conformance", Usage: "Check that an environment image implements the specialization protocol, with Docker or a running container, and report pass/fail per check", Flags: []cli.Flag{envConformanceImageFlag, envConformanceNameFlag, envNamespaceFlag, envConformanceVersionFlag, envConformanceURLFlag, envConformanceCodeFlag, envConformanceFilePathFlag, envConformanceEntrypointFlag, envConformanceConcurrencyFlag, envConformanceTimeoutFlag}, Action: urfavecli.Wrapper(environment.Conformance)},
 	}
 
 	// watches
@@ -249,26 +394,38 @@ func NewCliApp() *cli.App {
 		{Name: "get", Usage: "Get details about a watch", Flags: []cli.Flag{wNameFlag, triggerNamespaceFlag}, Action: wGet},
 		// TODO add update flag when supported
 		{Name: "delete", Usage: "Delete watch", Flags: []cli.Flag{wNameFlag, triggerNamespaceFlag}, Action: wDelete},
-		{Name: "list", Usage: "List all watches", Flags: []cli.Flag{triggerNamespaceFlag}, Action: wList},
+		{Name: "list", Usage: "List all watches", Flags: []cli.Flag{triggerNamespaceFlag, selectorFlag}, Action: wList},
 	}
 
 	// packages
 	pkgNameFlag := cli.StringFlag{Name: "name", Usage: "Package name"}
 	pkgForceFlag := cli.BoolFlag{Name: "force, f", Usage: "Force update a package even if it is used by one or more functions"}
 	pkgEnvironmentFlag := cli.StringFlag{Name: "env", Usage: "Environment name"}
-	pkgSrcArchiveFlag := cli.StringSliceFlag{Name: "sourcearchive, src", Usage: "Local path or URL for source archive"}
+	pkgSrcArchiveFlag := cli.StringSliceFlag{Name: "sourcearchive, src", Usage: "Local path or URL for source archive, or git+<repository URL>#<ref> for a Git repository"}
 	pkgDeployArchiveFlag := cli.StringSliceFlag{Name: "deployarchive, deploy", Usage: "Local path or URL for binary archive"}
 	pkgBuildCmdFlag := cli.StringFlag{Name: "buildcmd", Usage: "Build command for builder to run with"}
+	pkgGitSecretFlag := cli.StringFlag{Name: "git-secret", Usage: "Secret with the credentials of the Git source repository, username and password for https or ssh-privatekey and known_hosts for ssh (optional)"}
+	pkgBuildEnvFlag := cli.StringSliceFlag{Name: "build-env", Usage: "Environment variable of the builds in the form of KEY=VALUE, set in the builder container for the builds of this package only; replaces the old ones on update"}
+	pkgBuildSecretFlag := cli.StringSliceFlag{Name: "build-secret", Usage: "Secret in the namespace of the package whose entries are set as environment variables of the builds, e.g. private registry tokens; replaces the old ones on update"}
 	pkgOutputFlag := cli.StringFlag{Name: "output, o", Usage: "Output filename to save archive content"}
 	pkgOrphanFlag := cli.BoolFlag{Name: "orphan", Usage: "orphan packages that are not referenced by any function"}
+	pkgBuildLogsFlag := cli.StringFlag{Name: "build-logs", Value: "summary", Usage: "Build log to show, summary or full (optional)"}
+	pkgBuildStepFlag := cli.StringFlag{Name: "step", Usage: "Only show the log of a build step, e.g. fetch, build, upload, or build/<step name> for a multi-step build (optional)"}
+	pkgBuildStepsFlag := cli.StringSliceFlag{Name: "buildstep", Usage: "Build step in the form of name=command or name@image=command, repeat to run several steps in order instead of --buildcmd"}
+	pkgFollowFlag := cli.BoolFlag{Name: "follow, f", Usage: "Follow the logs of a running build until it's over"}
+	pkgWaitFlag := cli.BoolFlag{Name: "wait", Usage: "Wait for the build, exits with status 1 if it fails"}
+	pkgLocalBuildFlag := cli.BoolFlag{Name: "local", Usage: "Build the package on the local machine with Docker"}
+	pkgBuilderImageFlag := cli.StringFlag{Name: "builder-image", Usage: "Builder image to build with, no cluster access is needed if specified (optional, default to the builder image of the environment)"}
 	pkgSubCommands := []cli.Command{
-		{Name: "create", Usage: "Create new package", Flags: []cli.Flag{pkgNamespaceFlag, pkgEnvironmentFlag, envNamespaceFlag, pkgSrcArchiveFlag, pkgDeployArchiveFlag, pkgBuildCmdFlag}, Action: pkgCreate},
-		{Name: "update", Usage: "Update package", Flags: []cli.Flag{pkgNameFlag, pkgNamespaceFlag, pkgEnvironmentFlag, envNamespaceFlag, pkgSrcArchiveFlag, pkgDeployArchiveFlag, pkgBuildCmdFlag, pkgForceFlag}, Action: pkgUpdate},
-		{Name: "rebuild", Usage: "Rebuild a failed package", Flags: []cli.Flag{pkgNameFlag, pkgNamespaceFlag}, Action: pkgRebuild},
+		{Name: "create", Usage: "Create new package", Flags: []cli.Flag{pkgNamespaceFlag, pkgEnvironmentFlag, envNamespaceFlag, pkgSrcArchiveFlag, pkgDeployArchiveFlag, pkgBuildCmdFlag, pkgBuildStepsFlag, pkgBuildEnvFlag, pkgBuildSecretFlag, pkgGitSecretFlag}, Action: pkgCreate},
+		{Name: "update", Usage: "Update package", Flags: []cli.Flag{pkgNameFlag, pkgNamespaceFlag, pkgEnvironmentFlag, envNamespaceFlag, pkgSrcArchiveFlag, pkgDeployArchiveFlag, pkgBuildCmdFlag, pkgBuildStepsFlag, pkgBuildEnvFlag, pkgBuildSecretFlag, pkgGitSecretFlag, pkgForceFlag}, Action: pkgUpdate},
+		{Name: "rebuild", Usage: "Rebuild a failed package", Flags: []cli.Flag{pkgNameFlag, pkgNamespaceFlag, pkgWaitFlag}, Action: pkgRebuild},
+		{Name: "build", Usage: "Build a source package locally with the builder image of the environment", Flags: []cli.Flag{pkgLocalBuildFlag, pkgEnvironmentFlag, envNamespaceFlag, pkgBuilderImageFlag, pkgSrcArchiveFlag, pkgBuildCmdFlag, pkgOutputFlag}, Action: pkgBuild},
 		{Name: "getsrc", Usage: "Get source archive content", Flags: []cli.Flag{pkgNameFlag, pkgNamespaceFlag, pkgOutputFlag}, Action: pkgSourceGet},
 		{Name: "getdeploy", Usage: "Get deployment archive content", Flags: []cli.Flag{pkgNameFlag, pkgNamespaceFlag, pkgOutputFlag}, Action: pkgDeployGet},
-		{Name: "info", Usage: "Show package information", Flags: []cli.Flag{pkgNameFlag, pkgNamespaceFlag}, Action: pkgInfo},
-		{Name: "list", Usage: "List all packages", Flags: []cli.Flag{pkgOrphanFlag, pkgNamespaceFlag}, Action: pkgList},
+		{Name: "info", Usage: "Show package information", Flags: []cli.Flag{pkgNameFlag, pkgNamespaceFlag, pkgBuildLogsFlag}, Action: pkgInfo},
+		{Name: "logs", Aliases: []string{"build-logs"}, Usage: "Show full build log of package", Flags: []cli.Flag{pkgNameFlag, pkgNamespaceFlag, pkgBuildStepFlag, pkgFollowFlag}, Action: pkgLogs},
+		{Name: "list", Usage: "List all packages", Flags: []cli.Flag{pkgOrphanFlag, pkgNamespaceFlag, selectorFlag}, Action: pkgList},
 		{Name: "delete", Usage: "Delete package", Flags: []cli.Flag{pkgNameFlag, pkgNamespaceFlag, pkgForceFlag, pkgOrphanFlag}, Action: pkgDelete},
 	}
 
@@ -276,22 +433,39 @@ func NewCliApp() *cli.App {
 	specDirFlag := cli.StringFlag{Name: "specdir", Usage: "Directory to store specs, defaults to ./specs"}
 	specNameFlag := cli.StringFlag{Name: "name", Usage: "(optional) Name for the app, applied to resources as a Kubernetes annotation"}
 	specDeployIDFlag := cli.StringFlag{Name: "deployid, id", Usage: "(optional) Deployment ID for the spec deployment config"}
-	specWaitFlag := cli.BoolFlag{Name: "wait", Usage: "Wait for package builds"}
+	specWaitFlag := cli.BoolFlag{Name: "wait", Usage: "Wait for package builds, exits with status 1 if any build fails"}
+	specFailFastFlag := cli.BoolTFlag{Name: "fail-fast", Usage: "With --wait, stop waiting for the other builds once a build fails; --fail-fast=false waits for all of them"}
+	specBuildLogLinesFlag := cli.IntFlag{Name: "build-log-lines", Value: 20, Usage: "Number of lines printed from the end of the log of a failed build, 0 prints all of it"}
+	specBuildReportFlag := cli.StringFlag{Name: "build-report", Usage: "With --wait, write the succeeded, failed and pending packages as JSON to the file"}
 	specWatchFlag := cli.BoolFlag{Name: "watch", Usage: "Watch local files for change, and re-apply specs as necessary"}
 	specDeleteFlag := cli.BoolFlag{Name: "delete", Usage: "Allow apply to delete resources that no longer exist in the specification"}
+	specDryRunFlag := cli.BoolFlag{Name: "dry-run", Usage: "Print the changes apply would make to the cluster, without applying them"}
+	specRenderFlag := cli.BoolFlag{Name: "render", Usage: "Print all resources as they would be created, without contacting the cluster"}
+	specImportNamespaceFlag := cli.StringFlag{Name: "namespace", Value: metav1.NamespaceDefault, Usage: "Namespace of the resources to import"}
+	specAdoptFlag := cli.BoolFlag{Name: "adopt", Usage: "Annotate the imported resources on the cluster with the deployment config, so that spec apply updates them instead of creating them"}
+	specLintRulesFlag := cli.StringFlag{Name: "rules", Usage: "File with the LintConfig to check the specs against, defaults to the LintConfig in the spec directory"}
+	specEnvFileFlag := cli.StringFlag{Name: "env-file", Usage: "File with KEY=value lines substituted for the ${KEY} placeholders in the specs; variables not in the file are taken from the environment"}
+	specOverlayFlag := cli.StringFlag{Name: "overlay", Usage: "Name of the overlay in <specdir>/overlays whose specs patch the base specs, e.g. prod"}
 	specSubCommands := []cli.Command{
 		{Name: "init", Usage: "Create an initial declarative app specification", Flags: []cli.Flag{specDirFlag, specNameFlag, specDeployIDFlag}, Action: specInit},
-		{Name: "validate", Usage: "Validate Fission app specification", Flags: []cli.Flag{specDirFlag}, Action: specValidate},
-		{Name: "apply", Usage: "Create, update, or delete Fission resources from app specification", Flags: []cli.Flag{specDirFlag, specDeleteFlag, specWaitFlag, specWatchFlag}, Action: specApply},
+		{Name: "validate", Usage: "Validate Fission app specification", Flags: []cli.Flag{specDirFlag, specEnvFileFlag, specOverlayFlag}, Action: specValidate},
+		{Name: "apply", Usage: "Create, update, or delete Fission resources from app specification", Flags: []cli.Flag{specDirFlag, specEnvFileFlag, specOverlayFlag, specDeleteFlag, specWaitFlag, specFailFastFlag, specBuildLogLinesFlag, specBuildReportFlag, specWatchFlag, specDryRunFlag, specRenderFlag}, Action: specApply},
+		{Name: "list", Usage: "List the resources in the app specification and their deployment status", Flags: []cli.Flag{specDirFlag, specEnvFileFlag, specOverlayFlag}, Action: specList},
+		{Name: "diff", Usage: "Show the differences between the app specification and the resources on the cluster, including archive checksums; exits with status 1 if there are differences", Flags: []cli.Flag{specDirFlag, specEnvFileFlag, specOverlayFlag}, Action: specDiff},
+		{Name: "drift", Usage: "Report resources changed on the cluster since the app specification was last applied, e.g. with kubectl edit; exits with status 1 if any resource drifted", Flags: []cli.Flag{specDirFlag, specEnvFileFlag, specOverlayFlag}, Action: specDrift},
+		{Name: "lint", Usage: "Check the app specification against naming, label and resource rules; exits with status 1 if any rule is broken", Flags: []cli.Flag{specDirFlag, specEnvFileFlag, specOverlayFlag, specLintRulesFlag}, Action: specLint},
 		{Name: "destroy", Usage: "Delete all Fission resources in the app specification", Flags: []cli.Flag{specDirFlag}, Action: specDestroy},
+		{Name: "bulk-import", Usage: "Generate the package, function and HTTP trigger specs of the functions listed in a YAML or CSV manifest, e.g. \"fission spec bulk-import functions.yaml\"", ArgsUsage: "MANIFEST", Flags: []cli.Flag{specDirFlag, fnNamespaceFlag}, Action: specBulkImport},
+		{Name: "import", Usage: "Generate the specs of the environments, packages, functions and triggers of a namespace on the cluster, downloading their archives", Flags: []cli.Flag{specDirFlag, specImportNamespaceFlag, specAdoptFlag}, Action: specImport},
 		{Name: "helm", Usage: "Create a helm chart from the app specification", Flags: []cli.Flag{specDirFlag}, Action: specHelm, Hidden: true},
 	}
 
 	// support
 	supportOutputFlag := cli.StringFlag{Name: "output, o", Value: support.DEFAULT_OUTPUT_DIR, Usage: "Output directory to save dump archive/files"}
 	supportNoZipFlag := cli.BoolFlag{Name: "nozip", Usage: "Save dump information into multiple files instead of single zip file"}
+	supportOnlyFlag := cli.StringSliceFlag{Name: "only", Usage: "Only dump the given resources, comma separated or repeated: kubernetes, version, functions, builders, triggers or fission components like router or executor (default: all)"}
 	supportSubCommands := []cli.Command{
-		{Name: "dump", Usage: "Collect & dump all necessary for troubleshooting", Flags: []cli.Flag{supportOutputFlag, supportNoZipFlag}, Action: urfavecli.Wrapper(support.Dump)},
+		{Name: "dump", Usage: "Collect & dump all necessary for troubleshooting, with credentials redacted", Flags: []cli.Flag{supportOutputFlag, supportNoZipFlag, supportOnlyFlag}, Action: urfavecli.Wrapper(support.Dump)},
 	}
 
 	// canary configs
@@ -302,14 +476,62 @@ func NewCliApp() *cli.App {
 	weightIncrementFlag := cli.IntFlag{Name: "increment-step", Value: 20, Usage: "Weight increment step for function"}
 	incrementIntervalFlag := cli.StringFlag{Name: "increment-interval", Value: "2m", Usage: "Weight increment interval, string representation of time.Duration, ex : 1m, 2h, 2d"}
 	failureThresholdFlag := cli.IntFlag{Name: "failure-threshold", Value: 10, Usage: "Threshold in percentage beyond which the new version of the function is considered unstable"}
+	latencyThresholdFlag := cli.DurationFlag{Name: "latency-threshold", Usage: "99th percentile latency beyond which the new version of the function is considered unstable, e.g. 500ms; defaults to 0 (disabled)"}
+	successQueryFlag := cli.StringSliceFlag{Name: "success-query", Usage: "PromQL expression which must evaluate to non-zero for the new version to be considered stable; can be specified multiple times. Templates {{.Function}}, {{.OldFunction}}, {{.Namespace}}, {{.Path}}, {{.Method}} and {{.Window}} are replaced"}
+	failureQueryFlag := cli.StringSliceFlag{Name: "failure-query", Usage: "PromQL expression which rolls the new version back if it evaluates to non-zero; can be specified multiple times. Same templates as --success-query"}
+	onSuccessWebhookFlag := cli.StringFlag{Name: "on-success-webhook", Usage: "URL to POST to when the new version of the function is promoted (optional)"}
+	onFailureWebhookFlag := cli.StringFlag{Name: "on-failure-webhook", Usage: "URL to POST to when the new version of the function is rolled back (optional)"}
+	webhookFormatFlag := cli.StringFlag{Name: "webhook-format", Usage: "Payload format of the webhooks, json or slack (optional, default json)"}
 	canarySubCommands := []cli.Command{
-		{Name: "create", Usage: "Create a canary config", Flags: []cli.Flag{canaryConfigNameFlag, triggerNameFlag, newFunc, oldFunc, fnNamespaceFlag, weightIncrementFlag, incrementIntervalFlag, failureThresholdFlag}, Action: canaryConfigCreate},
+		{Name: "create", Usage: "Create a canary config", Flags: []cli.Flag{canaryConfigNameFlag, triggerNameFlag, newFunc, oldFunc, fnNamespaceFlag, weightIncrementFlag, incrementIntervalFlag, failureThresholdFlag, latencyThresholdFlag, successQueryFlag, failureQueryFlag, onSuccessWebhookFlag, onFailureWebhookFlag, webhookFormatFlag}, Action: canaryConfigCreate},
 		{Name: "get", Usage: "View parameters in a canary config", Flags: []cli.Flag{canaryConfigNameFlag, canaryNamespaceFlag}, Action: canaryConfigGet},
-		{Name: "update", Usage: "Update parameters of a canary config", Flags: []cli.Flag{canaryConfigNameFlag, canaryNamespaceFlag, incrementIntervalFlag, weightIncrementFlag, failureThresholdFlag}, Action: canaryConfigUpdate},
+		{Name: "update", Usage: "Update parameters of a canary config", Flags: []cli.Flag{canaryConfigNameFlag, canaryNamespaceFlag, incrementIntervalFlag, weightIncrementFlag, failureThresholdFlag, latencyThresholdFlag, successQueryFlag, failureQueryFlag, onSuccessWebhookFlag, onFailureWebhookFlag, webhookFormatFlag}, Action: canaryConfigUpdate},
 		{Name: "delete", Usage: "Delete a canary config", Flags: []cli.Flag{canaryConfigNameFlag, canaryNamespaceFlag}, Action: canaryConfigDelete},
-		{Name: "list", Usage: "List all canary configs in a namespace", Flags: []cli.Flag{canaryNamespaceFlag}, Action: canaryConfigList},
+		{Name: "list", Usage: "List all canary configs in a namespace", Flags: []cli.Flag{canaryNamespaceFlag, selectorFlag}, Action: canaryConfigList},
+	}
+
+	// canary policies
+	canaryPolicyNameFlag := cli.StringFlag{Name: "name", Usage: "Name for the canary policy"}
+	canaryPolicySubCommands := []cli.Command{
+		{Name: "create", Usage: "Create the canary policy of a namespace, updates of functions routed to by HTTP triggers then start canary deployments from the previous versions", Flags: []cli.Flag{canaryPolicyNameFlag, canaryNamespaceFlag, weightIncrementFlag, incrementIntervalFlag, failureThresholdFlag, latencyThresholdFlag}, Action: canaryPolicyCreate},
+		{Name: "get", Usage: "View parameters of a canary policy", Flags: []cli.Flag{canaryPolicyNameFlag, canaryNamespaceFlag}, Action: canaryPolicyGet},
+		{Name: "update", Usage: "Update parameters of a canary policy", Flags: []cli.Flag{canaryPolicyNameFlag, canaryNamespaceFlag, weightIncrementFlag, incrementIntervalFlag, failureThresholdFlag, latencyThresholdFlag}, Action: canaryPolicyUpdate},
+		{Name: "delete", Usage: "Delete a canary policy", Flags: []cli.Flag{canaryPolicyNameFlag, canaryNamespaceFlag}, Action: canaryPolicyDelete},
+		{Name: "list", Usage: "List canary policies", Flags: []cli.Flag{canaryNamespaceFlag, selectorFlag}, Action: canaryPolicyList},
+	}
+
+	// function aliases
+	aliasNameFlag := cli.StringFlag{Name: "name", Usage: "Function alias name, can also be given as the first argument"}
+	aliasFnNameFlag := cli.StringSliceFlag{Name: "function", Usage: "Name(s) of the function(s) the alias points to; traffic is split based on the weights supplied with --weight if more than one"}
+	aliasFnWeightFlag := cli.IntSliceFlag{Name: "weight", Usage: "Weight for each function supplied with --function flag, in the same order; the weights add up to 100"}
+	aliasSubCommands := []cli.Command{
+		{Name: "create", Aliases: []string{"add"}, Usage: "Create a function alias", ArgsUsage: "[alias-name]", Flags: []cli.Flag{aliasNameFlag, aliasFnNameFlag, aliasFnWeightFlag, fnNamespaceFlag}, Action: aliasCreate},
+		{Name: "get", Usage: "Get a function alias", Flags: []cli.Flag{aliasNameFlag, aliasNamespaceFlag}, Action: aliasGet},
+		{Name: "update", Usage: "Point a function alias to other functions or change their weights", Flags: []cli.Flag{aliasNameFlag, aliasNamespaceFlag, aliasFnNameFlag, aliasFnWeightFlag}, Action: aliasUpdate},
+		{Name: "delete", Usage: "Delete a function alias", Flags: []cli.Flag{aliasNameFlag, aliasNamespaceFlag}, Action: aliasDelete},
+		{Name: "list", Usage: "List function aliases", Flags: []cli.Flag{aliasNamespaceFlag, selectorFlag}, Action: aliasList},
 	}
 
+	// audit
+	auditKindFlag := cli.StringFlag{Name: "kind", Usage: "Only show events of resources of this kind, e.g. HTTPTrigger"}
+	auditNameFlag := cli.StringFlag{Name: "name", Usage: "Only show events of resources with this name"}
+	auditNamespaceFlag := cli.StringFlag{Name: "namespace", Usage: "Only show events of resources in this namespace"}
+	auditIDFlag := cli.StringFlag{Name: "id", Usage: "ID of the audit event"}
+	auditSubCommands := []cli.Command{
+		{Name: "list", Usage: "List the resources created, updated and deleted through the controller, oldest first. The user is asserted by the client ($FISSION_USER or the local user) and not verified", Flags: []cli.Flag{auditKindFlag, auditNameFlag, auditNamespaceFlag}, Action: auditList},
+		{Name: "get", Usage: "Show an audit event and the changes it made to the spec of the resource", Flags: []cli.Flag{auditIDFlag}, Action: auditGet},
+	}
+
+	// token
+	tokenTriggerFlag := cli.StringFlag{Name: "httptrigger", Usage: "HTTP trigger with auth to create the credentials for"}
+	tokenSubjectFlag := cli.StringFlag{Name: "subject, sub", Usage: "Client the credentials are for: the sub claim of a token, the name of an API key"}
+	tokenTTLFlag := cli.DurationFlag{Name: "ttl", Value: 24 * time.Hour, Usage: "Time a token is valid for, 0 for no expiry; jwt only"}
+	tokenSubCommands := []cli.Command{
+		{Name: "create", Usage: "Create a token for a jwt trigger, or a new key to add to the secret of an apikey trigger", Flags: []cli.Flag{tokenTriggerFlag, triggerNamespaceFlag, tokenSubjectFlag, tokenTTLFlag}, Action: tokenCreate},
+	}
+
+	applyFileFlag := cli.StringSliceFlag{Name: "file, f", Usage: "YAML file of Fission resources, - to read from stdin; can be given multiple times"}
+
 	app.Commands = []cli.Command{
 		{Name: "function", Aliases: []string{"fn"}, Usage: "Create, update and manage functions", Subcommands: fnSubcommands},
 		{Name: "httptrigger", Aliases: []string{"ht", "route"}, Usage: "Manage HTTP triggers (routes) for functions", Subcommands: htSubcommands},
@@ -317,14 +539,20 @@ func NewCliApp() *cli.App {
 		{Name: "mqtrigger", Aliases: []string{"mqt", "messagequeue"}, Usage: "Manage message queue triggers for functions", Subcommands: mqtSubcommands},
 		{Name: "recorder", Usage: "Manage recorders for functions", Subcommands: recSubcommands, Hidden: true},
 		{Name: "records", Usage: "View records with optional filters", Subcommands: recViewSubcommands, Hidden: true},
-		{Name: "replay", Usage: "Replay records", Flags: []cli.Flag{reqIDFlag}, Action: replay},
+		{Name: "replay", Usage: "Replay records", Flags: []cli.Flag{reqIDFlag, replayTargetFlag, replayTargetNamespaceFlag}, Action: replay},
 		{Name: "environment", Aliases: []string{"env"}, Usage: "Manage environments", Subcommands: envSubcommands},
 		{Name: "watch", Aliases: []string{"w"}, Usage: "Manage watches", Subcommands: wSubCommands},
 		{Name: "package", Aliases: []string{"pkg"}, Usage: "Manage packages", Subcommands: pkgSubCommands},
 		{Name: "spec", Aliases: []string{"specs"}, Usage: "Manage a declarative app specification", Subcommands: specSubCommands},
+		{Name: "apply", Usage: "Create or update the Fission resources of YAML files, without a spec directory", Flags: []cli.Flag{applyFileFlag}, Action: resourceApply},
 		{Name: "support", Usage: "Collect an archive of diagnostic information for support", Subcommands: supportSubCommands},
+		{Name: "audit", Usage: "Inspect the history of changes to Fission resources", Subcommands: auditSubCommands},
 		cmdPlugin,
 		{Name: "canary-config", Aliases: []string{}, Usage: "Create, Update and manage Canary Configs", Subcommands: canarySubCommands},
+		{Name: "canary-policy", Usage: "Manage the canary policies of namespaces, which roll out function updates gradually", Subcommands: canaryPolicySubCommands},
+		{Name: "alias", Usage: "Manage function aliases, which triggers reference instead of functions", Subcommands: aliasSubCommands},
+		{Name: "token", Usage: "Create credentials for HTTP triggers with auth", Subcommands: tokenSubCommands},
+		{Name: "shell", Usage: "Start an interactive shell with command history, completion of resource names, and defaults for the namespace and environment", Action: shell},
 	}
 
 	app.Before = cliHook
@@ -449,6 +677,14 @@ func flagValueParser(args []string) error {
 	return nil
 }
 
+// listOptions returns the options of a list command, for the list methods
+// of the controller client.
+func listOptions(c *cli.Context) client.ListOptions {
+	return client.ListOptions{
+		LabelSelector: c.String(cmd.RESOURCE_SELECTOR),
+	}
+}
+
 var helpTemplate = `NAME:
    {{.Name}}{{if .Usage}} - {{.Usage}}{{end}}
 
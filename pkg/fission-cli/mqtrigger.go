@@ -20,6 +20,7 @@ import (
 	"fmt"
 	"os"
 	"text/tabwriter"
+	"time"
 
 	"github.com/satori/go.uuid"
 	"github.com/urfave/cli"
@@ -40,8 +41,22 @@ func mqtCreate(c *cli.Context) error {
 		mqtName = uuid.NewV4().String()
 	}
 	fnName := c.String("function")
-	if len(fnName) == 0 {
-		log.Fatal("Need a function name to create a trigger, use --function")
+	aliasName := c.String("alias")
+	if len(fnName) == 0 && len(aliasName) == 0 {
+		log.Fatal("Need a function name to create a trigger, use --function or --alias")
+	}
+	if len(fnName) > 0 && len(aliasName) > 0 {
+		log.Fatal("--function and --alias can't be used together")
+	}
+	fnRef := fv1.FunctionReference{
+		Type: types.FunctionReferenceTypeFunctionName,
+		Name: fnName,
+	}
+	if len(aliasName) > 0 {
+		fnRef = fv1.FunctionReference{
+			Type: types.FunctionReferenceTypeFunctionAlias,
+			Name: aliasName,
+		}
 	}
 	fnNamespace := c.String("fnNamespace")
 
@@ -55,14 +70,25 @@ func mqtCreate(c *cli.Context) error {
 		mqType = types.MessageQueueTypeASQ
 	case types.MessageQueueTypeKafka:
 		mqType = types.MessageQueueTypeKafka
+	case types.MessageQueueTypeRabbitMQ:
+		mqType = types.MessageQueueTypeRabbitMQ
+	case types.MessageQueueTypeGCPPubSub:
+		mqType = types.MessageQueueTypeGCPPubSub
+	case types.MessageQueueTypeAzureServiceBus:
+		mqType = types.MessageQueueTypeAzureServiceBus
+	case types.MessageQueueTypeNatsJetStream:
+		mqType = types.MessageQueueTypeNatsJetStream
 
 	default:
-		log.Fatal("Unknown message queue type, currently only \"nats-streaming, azure-storage-queue, kafka \" is supported")
+		log.Fatal("Unknown message queue type, currently only \"nats-streaming, azure-storage-queue, kafka, rabbitmq, gcp-pubsub, azure-servicebus, nats-jetstream \" is supported")
 
 	}
+	if mqType == types.MessageQueueTypeNats {
+		log.Warn("nats-streaming is deprecated, use --mqtype nats-jetstream for new triggers")
+	}
 
 	// TODO: check topic availability
-	topic := c.String("topic")
+	topic := getMQTopic(c)
 	if len(topic) == 0 {
 		log.Fatal("Topic cannot be empty")
 	}
@@ -89,22 +115,80 @@ func mqtCreate(c *cli.Context) error {
 
 	checkMQTopicAvailability(mqType, topic, respTopic)
 
+	if isKafkaConfigSet(c) && mqType != types.MessageQueueTypeKafka {
+		log.Fatal("Kafka flags can only be used with --mqtype kafka")
+	}
+	var kafkaConfig *fv1.KafkaConfig
+	if isKafkaConfigSet(c) {
+		kafkaConfig = &fv1.KafkaConfig{}
+		updateKafkaConfig(c, kafkaConfig)
+	}
+
+	if (isRabbitMQConfigSet(c) || len(c.String("queue")) > 0) && mqType != types.MessageQueueTypeRabbitMQ {
+		log.Fatal("RabbitMQ flags can only be used with --mqtype rabbitmq")
+	}
+	var rabbitMQConfig *fv1.RabbitMQConfig
+	if isRabbitMQConfigSet(c) {
+		rabbitMQConfig = &fv1.RabbitMQConfig{}
+		updateRabbitMQConfig(c, rabbitMQConfig)
+	}
+
+	if isGCPPubSubConfigSet(c, mqType) && mqType != types.MessageQueueTypeGCPPubSub {
+		log.Fatal("GCP Pub/Sub flags can only be used with --mqtype gcp-pubsub")
+	}
+	var gcpPubSubConfig *fv1.GCPPubSubConfig
+	if isGCPPubSubConfigSet(c, mqType) {
+		gcpPubSubConfig = &fv1.GCPPubSubConfig{}
+		updateGCPPubSubConfig(c, gcpPubSubConfig)
+	}
+
+	if isAzureServiceBusConfigSet(c, mqType) && mqType != types.MessageQueueTypeAzureServiceBus {
+		log.Fatal("Azure Service Bus flags can only be used with --mqtype azure-servicebus")
+	}
+	var azureServiceBusConfig *fv1.AzureServiceBusConfig
+	if isAzureServiceBusConfigSet(c, mqType) {
+		azureServiceBusConfig = &fv1.AzureServiceBusConfig{}
+		updateAzureServiceBusConfig(c, azureServiceBusConfig)
+	}
+
+	if isNatsJetStreamConfigSet(c) && mqType != types.MessageQueueTypeNatsJetStream {
+		log.Fatal("NATS JetStream flags can only be used with --mqtype nats-jetstream")
+	}
+	var natsJetStreamConfig *fv1.NatsJetStreamConfig
+	if mqType == types.MessageQueueTypeNatsJetStream {
+		if len(c.String("stream")) == 0 {
+			log.Fatal("Need the stream of the topic, use --stream")
+		}
+		natsJetStreamConfig = &fv1.NatsJetStreamConfig{}
+		updateNatsJetStreamConfig(c, natsJetStreamConfig)
+	}
+
+	secret := c.String("secret")
+	if len(secret) > 0 && mqType != types.MessageQueueTypeKafka && mqType != types.MessageQueueTypeRabbitMQ &&
+		mqType != types.MessageQueueTypeGCPPubSub && mqType != types.MessageQueueTypeAzureServiceBus &&
+		mqType != types.MessageQueueTypeNatsJetStream {
+		log.Fatal("--secret can only be used with --mqtype kafka, rabbitmq, gcp-pubsub, azure-servicebus or nats-jetstream")
+	}
+
 	mqt := &fv1.MessageQueueTrigger{
 		Metadata: metav1.ObjectMeta{
 			Name:      mqtName,
 			Namespace: fnNamespace,
 		},
 		Spec: fv1.MessageQueueTriggerSpec{
-			FunctionReference: fv1.FunctionReference{
-				Type: types.FunctionReferenceTypeFunctionName,
-				Name: fnName,
-			},
-			MessageQueueType: mqType,
-			Topic:            topic,
-			ResponseTopic:    respTopic,
-			ErrorTopic:       errorTopic,
-			MaxRetries:       maxRetries,
-			ContentType:      contentType,
+			FunctionReference: fnRef,
+			MessageQueueType:  mqType,
+			Topic:             topic,
+			ResponseTopic:     respTopic,
+			ErrorTopic:        errorTopic,
+			MaxRetries:        maxRetries,
+			ContentType:       contentType,
+			Kafka:             kafkaConfig,
+			RabbitMQ:          rabbitMQConfig,
+			GCPPubSub:         gcpPubSubConfig,
+			AzureServiceBus:   azureServiceBusConfig,
+			NatsJetStream:     natsJetStreamConfig,
+			Secret:            secret,
 		},
 	}
 
@@ -116,7 +200,10 @@ func mqtCreate(c *cli.Context) error {
 		return nil
 	}
 
-	_, err := client.MessageQueueTriggerCreate(mqt)
+	err := checkMessageQueueType(getServerFeatures(client), string(mqType))
+	util.CheckErr(err, "create message queue trigger")
+
+	_, err = client.MessageQueueTriggerCreate(mqt)
 	util.CheckErr(err, "create message queue trigger")
 
 	fmt.Printf("trigger '%s' created\n", mqtName)
@@ -135,7 +222,7 @@ func mqtUpdate(c *cli.Context) error {
 	}
 	mqtNs := c.String("triggerns")
 
-	topic := c.String("topic")
+	topic := getMQTopic(c)
 	respTopic := c.String("resptopic")
 	errorTopic := c.String("errortopic")
 	maxRetries := c.Int("maxretries")
@@ -169,17 +256,84 @@ func mqtUpdate(c *cli.Context) error {
 		mqt.Spec.MaxRetries = maxRetries
 		updated = true
 	}
+	if len(fnName) > 0 && c.IsSet("alias") {
+		log.Fatal("--function and --alias can't be used together")
+	}
 	if len(fnName) > 0 {
-		mqt.Spec.FunctionReference.Name = fnName
+		mqt.Spec.FunctionReference = fv1.FunctionReference{
+			Type: types.FunctionReferenceTypeFunctionName,
+			Name: fnName,
+		}
+		updated = true
+	}
+	if c.IsSet("alias") {
+		mqt.Spec.FunctionReference = fv1.FunctionReference{
+			Type: types.FunctionReferenceTypeFunctionAlias,
+			Name: c.String("alias"),
+		}
 		updated = true
 	}
 	if len(contentType) > 0 {
 		mqt.Spec.ContentType = contentType
 		updated = true
 	}
+	if (isRabbitMQConfigSet(c) || len(c.String("queue")) > 0) && mqt.Spec.MessageQueueType != types.MessageQueueTypeRabbitMQ {
+		log.Fatal("RabbitMQ flags can only be used with rabbitmq triggers")
+	}
+	if isKafkaConfigSet(c) && mqt.Spec.MessageQueueType != types.MessageQueueTypeKafka {
+		log.Fatal("Kafka flags can only be used with kafka triggers")
+	}
+	if isKafkaConfigSet(c) {
+		if mqt.Spec.Kafka == nil {
+			mqt.Spec.Kafka = &fv1.KafkaConfig{}
+		}
+		updateKafkaConfig(c, mqt.Spec.Kafka)
+		updated = true
+	}
+	if isRabbitMQConfigSet(c) {
+		if mqt.Spec.RabbitMQ == nil {
+			mqt.Spec.RabbitMQ = &fv1.RabbitMQConfig{}
+		}
+		updateRabbitMQConfig(c, mqt.Spec.RabbitMQ)
+		updated = true
+	}
+	if isGCPPubSubConfigSet(c, mqt.Spec.MessageQueueType) && mqt.Spec.MessageQueueType != types.MessageQueueTypeGCPPubSub {
+		log.Fatal("GCP Pub/Sub flags can only be used with gcp-pubsub triggers")
+	}
+	if isGCPPubSubConfigSet(c, mqt.Spec.MessageQueueType) {
+		if mqt.Spec.GCPPubSub == nil {
+			mqt.Spec.GCPPubSub = &fv1.GCPPubSubConfig{}
+		}
+		updateGCPPubSubConfig(c, mqt.Spec.GCPPubSub)
+		updated = true
+	}
+	if isAzureServiceBusConfigSet(c, mqt.Spec.MessageQueueType) && mqt.Spec.MessageQueueType != types.MessageQueueTypeAzureServiceBus {
+		log.Fatal("Azure Service Bus flags can only be used with azure-servicebus triggers")
+	}
+	if isAzureServiceBusConfigSet(c, mqt.Spec.MessageQueueType) {
+		if mqt.Spec.AzureServiceBus == nil {
+			mqt.Spec.AzureServiceBus = &fv1.AzureServiceBusConfig{}
+		}
+		updateAzureServiceBusConfig(c, mqt.Spec.AzureServiceBus)
+		updated = true
+	}
+	if isNatsJetStreamConfigSet(c) && mqt.Spec.MessageQueueType != types.MessageQueueTypeNatsJetStream {
+		log.Fatal("NATS JetStream flags can only be used with nats-jetstream triggers")
+	}
+	if isNatsJetStreamConfigSet(c) {
+		if mqt.Spec.NatsJetStream == nil {
+			mqt.Spec.NatsJetStream = &fv1.NatsJetStreamConfig{}
+		}
+		updateNatsJetStreamConfig(c, mqt.Spec.NatsJetStream)
+		updated = true
+	}
+	if c.IsSet("secret") {
+		mqt.Spec.Secret = c.String("secret")
+		updated = true
+	}
 
 	if !updated {
-		log.Fatal("Nothing to update. Use --topic, --resptopic, --errortopic, --maxretries or --function.")
+		log.Fatal("Nothing to update. Use --topic, --resptopic, --errortopic, --maxretries, --function, --alias, --secret or the kafka, rabbitmq, gcp pubsub, azure service bus or nats jetstream flags.")
 	}
 
 	_, err = client.MessageQueueTriggerUpdate(mqt)
@@ -211,7 +365,7 @@ func mqtList(c *cli.Context) error {
 	client := util.GetApiClient(c.GlobalString("server"))
 	mqtNs := c.String("triggerns")
 
-	mqts, err := client.MessageQueueTriggerList(c.String("mqtype"), mqtNs)
+	mqts, err := client.MessageQueueTriggerListWithOptions(c.String("mqtype"), mqtNs, listOptions(c))
 	util.CheckErr(err, "list message queue triggers")
 
 	w := tabwriter.NewWriter(os.Stdout, 0, 0, 1, ' ', 0)
@@ -219,14 +373,152 @@ func mqtList(c *cli.Context) error {
 	fmt.Fprintf(w, "%v\t%v\t%v\t%v\t%v\t%v\t%v\t%v\n",
 		"NAME", "FUNCTION_NAME", "MESSAGE_QUEUE_TYPE", "TOPIC", "RESPONSE_TOPIC", "ERROR_TOPIC", "MAX_RETRIES", "PUB_MSG_CONTENT_TYPE")
 	for _, mqt := range mqts {
+		function := mqt.Spec.FunctionReference.Name
+		if mqt.Spec.FunctionReference.Type == types.FunctionReferenceTypeFunctionAlias {
+			function = fmt.Sprintf("alias:%s", function)
+		}
 		fmt.Fprintf(w, "%v\t%v\t%v\t%v\t%v\t%v\t%v\t%v\n",
-			mqt.Metadata.Name, mqt.Spec.FunctionReference.Name, mqt.Spec.MessageQueueType, mqt.Spec.Topic, mqt.Spec.ResponseTopic, mqt.Spec.ErrorTopic, mqt.Spec.MaxRetries, mqt.Spec.ContentType)
+			mqt.Metadata.Name, function, mqt.Spec.MessageQueueType, mqt.Spec.Topic, mqt.Spec.ResponseTopic, mqt.Spec.ErrorTopic, mqt.Spec.MaxRetries, mqt.Spec.ContentType)
 	}
 	w.Flush()
 
 	return nil
 }
 
+// isKafkaConfigSet checks whether any of the Kafka settings is given by flags.
+func isKafkaConfigSet(c *cli.Context) bool {
+	return c.IsSet("kafkabrokers") || c.IsSet("kafkagroup") || c.IsSet("kafkatls") || c.IsSet("kafkatlsinsecure")
+}
+
+// updateKafkaConfig sets the Kafka settings given by flags. The SASL
+// credentials are only taken from the trigger secret, see --secret.
+func updateKafkaConfig(c *cli.Context, config *fv1.KafkaConfig) {
+	if c.IsSet("kafkabrokers") {
+		config.Brokers = c.StringSlice("kafkabrokers")
+	}
+	if c.IsSet("kafkagroup") {
+		config.ConsumerGroup = c.String("kafkagroup")
+	}
+	if c.IsSet("kafkatls") || c.IsSet("kafkatlsinsecure") {
+		tlsInsecure := c.Bool("kafkatlsinsecure")
+		if c.Bool("kafkatls") || tlsInsecure {
+			config.TLS = &fv1.KafkaTLSConfig{
+				Enabled:            true,
+				InsecureSkipVerify: tlsInsecure,
+			}
+		} else {
+			config.TLS = nil
+		}
+	}
+}
+
+// getMQTopic returns the topic of a trigger, --queue is an alias of --topic for RabbitMQ.
+func getMQTopic(c *cli.Context) string {
+	topic := c.String("topic")
+	queue := c.String("queue")
+	if len(topic) > 0 && len(queue) > 0 && topic != queue {
+		log.Fatal("--topic and --queue can't be different, use either of them")
+	}
+	if len(queue) > 0 {
+		return queue
+	}
+	return topic
+}
+
+// isRabbitMQConfigSet checks whether any of the RabbitMQ settings is given by flags.
+func isRabbitMQConfigSet(c *cli.Context) bool {
+	return c.IsSet("exchange") || c.IsSet("routingkey") || c.IsSet("prefetch") || c.IsSet("credentialsecret")
+}
+
+// updateRabbitMQConfig sets the RabbitMQ settings given by flags.
+func updateRabbitMQConfig(c *cli.Context, config *fv1.RabbitMQConfig) {
+	if c.IsSet("exchange") {
+		config.Exchange = c.String("exchange")
+	}
+	if c.IsSet("routingkey") {
+		config.RoutingKey = c.String("routingkey")
+	}
+	if c.IsSet("prefetch") {
+		prefetch := c.Int("prefetch")
+		if prefetch < 0 {
+			log.Fatal("Prefetch count must be a natural number, default is 1")
+		}
+		config.Prefetch = prefetch
+	}
+	if c.IsSet("credentialsecret") {
+		config.CredentialSecret = c.String("credentialsecret")
+	}
+}
+
+// isGCPPubSubConfigSet checks whether any of the GCP Pub/Sub settings is given by flags.
+// --subscription is shared with Azure Service Bus triggers.
+func isGCPPubSubConfigSet(c *cli.Context, mqType fv1.MessageQueueType) bool {
+	return c.IsSet("project") || c.IsSet("ackdeadline") ||
+		(c.IsSet("subscription") && mqType != types.MessageQueueTypeAzureServiceBus)
+}
+
+// updateGCPPubSubConfig sets the GCP Pub/Sub settings given by flags.
+func updateGCPPubSubConfig(c *cli.Context, config *fv1.GCPPubSubConfig) {
+	if c.IsSet("project") {
+		config.ProjectID = c.String("project")
+	}
+	if c.IsSet("subscription") {
+		config.Subscription = c.String("subscription")
+	}
+	if c.IsSet("ackdeadline") {
+		ackDeadline := c.Duration("ackdeadline")
+		if ackDeadline < 10*time.Second || ackDeadline > 600*time.Second {
+			log.Fatal("Ack deadline must be between 10s and 10m")
+		}
+		config.AckDeadline = int(ackDeadline.Seconds())
+	}
+}
+
+// isAzureServiceBusConfigSet checks whether any of the Azure Service Bus settings is given by flags.
+func isAzureServiceBusConfigSet(c *cli.Context, mqType fv1.MessageQueueType) bool {
+	return c.IsSet("sessions") ||
+		(c.IsSet("subscription") && mqType == types.MessageQueueTypeAzureServiceBus)
+}
+
+// updateAzureServiceBusConfig sets the Azure Service Bus settings given by flags.
+func updateAzureServiceBusConfig(c *cli.Context, config *fv1.AzureServiceBusConfig) {
+	if c.IsSet("subscription") {
+		config.Subscription = c.String("subscription")
+	}
+	if c.IsSet("sessions") {
+		config.Sessions = c.Bool("sessions")
+	}
+}
+
+// isNatsJetStreamConfigSet checks whether any of the NATS JetStream settings is given by flags.
+func isNatsJetStreamConfigSet(c *cli.Context) bool {
+	return c.IsSet("stream") || c.IsSet("durable") || c.IsSet("ackwait") || c.IsSet("maxdeliver")
+}
+
+// updateNatsJetStreamConfig sets the NATS JetStream settings given by flags.
+func updateNatsJetStreamConfig(c *cli.Context, config *fv1.NatsJetStreamConfig) {
+	if c.IsSet("stream") {
+		config.Stream = c.String("stream")
+	}
+	if c.IsSet("durable") {
+		config.Durable = c.String("durable")
+	}
+	if c.IsSet("ackwait") {
+		ackWait := c.Duration("ackwait")
+		if ackWait < time.Second {
+			log.Fatal("Ack wait must be at least 1s")
+		}
+		config.AckWait = int(ackWait.Seconds())
+	}
+	if c.IsSet("maxdeliver") {
+		maxDeliver := c.Int("maxdeliver")
+		if maxDeliver < 1 {
+			log.Fatal("Max deliver must be at least 1")
+		}
+		config.MaxDeliver = maxDeliver
+	}
+}
+
 func checkMQTopicAvailability(mqType fv1.MessageQueueType, topics ...string) {
 	for _, t := range topics {
 		if len(t) > 0 && !fv1.IsTopicValid(mqType, t) {
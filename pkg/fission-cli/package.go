@@ -21,6 +21,7 @@ import (
 	"context"
 	"crypto/sha256"
 	"encoding/hex"
+	"encoding/json"
 	"fmt"
 	"io"
 	"io/ioutil"
@@ -31,6 +32,7 @@ import (
 	"path/filepath"
 	"sort"
 	"strings"
+	"sync/atomic"
 	"text/tabwriter"
 	"time"
 
@@ -45,6 +47,7 @@ import (
 
 	fv1 "github.com/fission/fission/pkg/apis/fission.io/v1"
 	"github.com/fission/fission/pkg/controller/client"
+	ferror "github.com/fission/fission/pkg/error"
 	"github.com/fission/fission/pkg/fission-cli/cliwrapper/driver/urfavecli"
 	cmdutils "github.com/fission/fission/pkg/fission-cli/cmd"
 	"github.com/fission/fission/pkg/fission-cli/cmd/spec"
@@ -54,6 +57,42 @@ import (
 	"github.com/fission/fission/pkg/types"
 )
 
+// gitSourcePrefix marks a source archive as a Git repository to be cloned by
+// the builder, e.g. git+https://github.com/fission/fission.git#master
+const gitSourcePrefix = "git+"
+
+// getGitArchive returns the Git archive of the source, or nil if the source
+// isn't a Git repository.
+func getGitArchive(srcArchiveFiles []string, gitSecret string) *fv1.Archive {
+	if len(srcArchiveFiles) == 0 || !strings.HasPrefix(srcArchiveFiles[0], gitSourcePrefix) {
+		if len(gitSecret) > 0 {
+			log.Fatal("--git-secret can only be used with a Git source, e.g. --src git+https://...")
+		}
+		return nil
+	}
+	if len(srcArchiveFiles) > 1 {
+		log.Fatal("Only one Git repository can be used as source.")
+	}
+
+	repoURL := strings.TrimPrefix(srcArchiveFiles[0], gitSourcePrefix)
+	var ref string
+	if i := strings.LastIndex(repoURL, "#"); i >= 0 {
+		repoURL, ref = repoURL[:i], repoURL[i+1:]
+	}
+	if !fv1.IsValidGitURL(repoURL) {
+		log.Fatal(fmt.Sprintf("Invalid Git repository URL '%v', use https:// or ssh:// URLs or the user@host:path form", repoURL))
+	}
+
+	return &fv1.Archive{
+		Type: fv1.ArchiveTypeGit,
+		URL:  repoURL,
+		Git: &fv1.GitSource{
+			Ref:    ref,
+			Secret: gitSecret,
+		},
+	}
+}
+
 func getFunctionsByPackage(client *client.Client, pkgName, pkgNamespace string) ([]fv1.Function, error) {
 	fnList, err := client.FunctionList(pkgNamespace)
 	if err != nil {
@@ -100,6 +139,10 @@ func pkgCreate(c *cli.Context) error {
 		log.Fatal("Need --src to specify source archive, or use --deploy to specify deployment archive.")
 	}
 
+	if len(buildcmd) > 0 && len(c.StringSlice("buildstep")) > 0 {
+		log.Fatal("--buildcmd can not be used with --buildstep")
+	}
+
 	createPackage(c, client, pkgNamespace, envName, envNamespace, srcArchiveFiles, deployArchiveFiles, buildcmd, "", "", false)
 
 	return nil
@@ -120,14 +163,23 @@ func pkgUpdate(c *cli.Context) error {
 	srcArchiveFiles := c.StringSlice("src")
 	deployArchiveFiles := c.StringSlice("deploy")
 	buildcmd := c.String("buildcmd")
+	buildSteps := c.StringSlice("buildstep")
+	gitSecret := c.String("git-secret")
+	buildEnv := c.StringSlice("build-env")
+	buildSecrets := c.StringSlice("build-secret")
 
 	if len(srcArchiveFiles) > 0 && len(deployArchiveFiles) > 0 {
 		log.Fatal("Need either of --src or --deploy and not both arguments.")
 	}
 
 	if len(srcArchiveFiles) == 0 && len(deployArchiveFiles) == 0 &&
-		len(envName) == 0 && len(buildcmd) == 0 {
-		log.Fatal("Need --env or --src or --deploy or --buildcmd argument.")
+		len(envName) == 0 && len(buildcmd) == 0 && len(buildSteps) == 0 && len(gitSecret) == 0 &&
+		len(buildEnv) == 0 && len(buildSecrets) == 0 {
+		log.Fatal("Need --env or --src or --deploy or --buildcmd or --buildstep or --git-secret or --build-env or --build-secret argument.")
+	}
+
+	if len(buildcmd) > 0 && len(buildSteps) > 0 {
+		log.Fatal("--buildcmd can not be used with --buildstep")
 	}
 
 	pkg, err := client.PackageGet(&metav1.ObjectMeta{
@@ -136,6 +188,29 @@ func pkgUpdate(c *cli.Context) error {
 	})
 	util.CheckErr(err, "get package")
 
+	// the build command and the build steps replace each other
+	forceRebuild := false
+	if len(buildSteps) > 0 {
+		pkg.Spec.BuildSteps, err = parseBuildSteps(buildSteps)
+		util.CheckErr(err, "parse build steps")
+		pkg.Spec.BuildCommand = ""
+		forceRebuild = pkg.Spec.Source.Type == fv1.ArchiveTypeGit || len(pkg.Spec.Source.URL) > 0 || len(pkg.Spec.Source.Literal) > 0
+	} else if len(buildcmd) > 0 {
+		pkg.Spec.BuildSteps = nil
+	}
+
+	// the build environment given replaces the old one
+	if len(buildEnv) > 0 || len(buildSecrets) > 0 {
+		if len(buildEnv) > 0 {
+			pkg.Spec.BuildEnv, err = parseBuildEnv(buildEnv)
+			util.CheckErr(err, "parse build environment variables")
+		}
+		if len(buildSecrets) > 0 {
+			pkg.Spec.BuildSecrets = buildSecrets
+		}
+		forceRebuild = pkg.Spec.Source.Type == fv1.ArchiveTypeGit || len(pkg.Spec.Source.URL) > 0 || len(pkg.Spec.Source.Literal) > 0
+	}
+
 	// if the new env specified is the same as the old one, no need to update package
 	// same is true for all update parameters, but, for now, we dont check all of them - because, its ok to
 	// re-write the object with same old values, we just end up getting a new resource version for the object.
@@ -155,7 +230,7 @@ func pkgUpdate(c *cli.Context) error {
 	}
 
 	newPkgMeta, err := updatePackage(client, pkg,
-		envName, envNamespace, srcArchiveFiles, deployArchiveFiles, buildcmd, false, false)
+		envName, envNamespace, srcArchiveFiles, deployArchiveFiles, buildcmd, gitSecret, forceRebuild, false)
 	if err != nil {
 		util.CheckErr(err, "update package")
 	}
@@ -173,7 +248,7 @@ func pkgUpdate(c *cli.Context) error {
 }
 
 func updatePackage(client *client.Client, pkg *fv1.Package, envName, envNamespace string,
-	srcArchiveFiles []string, deployArchiveFiles []string, buildcmd string, forceRebuild bool, noZip bool) (*metav1.ObjectMeta, error) {
+	srcArchiveFiles []string, deployArchiveFiles []string, buildcmd string, gitSecret string, forceRebuild bool, noZip bool) (*metav1.ObjectMeta, error) {
 
 	var srcArchiveMetadata, deployArchiveMetadata *fv1.Archive
 	needToBuild := false
@@ -194,9 +269,19 @@ func updatePackage(client *client.Client, pkg *fv1.Package, envName, envNamespac
 	}
 
 	if len(srcArchiveFiles) > 0 {
-		srcArchiveMetadata = createArchive(client, srcArchiveFiles, false, "", "")
+		srcArchiveMetadata = getGitArchive(srcArchiveFiles, gitSecret)
+		if srcArchiveMetadata == nil {
+			srcArchiveMetadata = createArchive(client, srcArchiveFiles, false, "", "")
+		}
 		pkg.Spec.Source = *srcArchiveMetadata
 		needToBuild = true
+	} else if len(gitSecret) > 0 {
+		// only the secret of the Git source changes
+		if pkg.Spec.Source.Type != fv1.ArchiveTypeGit || pkg.Spec.Source.Git == nil {
+			log.Fatal("--git-secret can only be used with a Git source, e.g. --src git+https://...")
+		}
+		pkg.Spec.Source.Git.Secret = gitSecret
+		needToBuild = true
 	}
 
 	if len(deployArchiveFiles) > 0 {
@@ -243,7 +328,14 @@ func pkgSourceGet(c *cli.Context) error {
 
 	var reader io.Reader
 
-	if pkg.Spec.Source.Type == fv1.ArchiveTypeLiteral {
+	if pkg.Spec.Source.Type == fv1.ArchiveTypeGit {
+		// the source is cloned by the builder, there is no archive to download
+		fmt.Printf("Git repository: %v\n", pkg.Spec.Source.URL)
+		if pkg.Spec.Source.Git != nil && len(pkg.Spec.Source.Git.Ref) > 0 {
+			fmt.Printf("Ref: %v\n", pkg.Spec.Source.Git.Ref)
+		}
+		return nil
+	} else if pkg.Spec.Source.Type == fv1.ArchiveTypeLiteral {
 		reader = bytes.NewReader(pkg.Spec.Source.Literal)
 	} else if pkg.Spec.Source.Type == fv1.ArchiveTypeUrl {
 		readCloser := downloadStoragesvcURL(client, pkg.Spec.Source.URL)
@@ -317,19 +409,204 @@ func pkgInfo(c *cli.Context) error {
 	fmt.Fprintf(w, "%v\t%v\n", "Name:", pkg.Metadata.Name)
 	fmt.Fprintf(w, "%v\t%v\n", "Environment:", pkg.Spec.Environment.Name)
 	fmt.Fprintf(w, "%v\t%v\n", "Status:", pkg.Status.BuildStatus)
-	fmt.Fprintf(w, "%v\n%v", "Build Logs:", pkg.Status.BuildLog)
+	if len(pkg.Status.BuildAttempts) > 1 {
+		fmt.Fprintf(w, "%v\n", "Build Attempts:")
+		for _, attempt := range pkg.Status.BuildAttempts {
+			fmt.Fprintf(w, "\t%v\t%v\t%v\t%v\n", attempt.Attempt, attempt.Status,
+				attempt.StartTimestamp.Format(time.RFC3339), attempt.Message)
+		}
+	}
+	if len(pkg.Status.BuildSteps) > 0 {
+		fmt.Fprintf(w, "%v\n", "Build Steps:")
+		for _, step := range pkg.Status.BuildSteps {
+			var duration string
+			if !step.StartTimestamp.IsZero() && !step.FinishTimestamp.IsZero() {
+				duration = step.FinishTimestamp.Sub(step.StartTimestamp).Round(time.Second).String()
+			}
+			fmt.Fprintf(w, "\t%v\t%v\t%v\t%v\n", step.Name, step.Status, duration, step.Message)
+		}
+	}
 	w.Flush()
 
+	switch c.String("build-logs") {
+	case "", "summary":
+		fmt.Printf("%v\n%v", "Build Logs:", pkg.Status.BuildLog)
+	case "full":
+		fmt.Println("Build Logs:")
+		printPackageBuildLog(client, pkg, "")
+	default:
+		log.Fatal("Unknown build log mode, use --build-logs summary or --build-logs full")
+	}
+
+	return nil
+}
+
+func pkgLogs(c *cli.Context) error {
+	client := util.GetApiClient(c.GlobalString("server"))
+
+	pkgName := c.String("name")
+	if len(pkgName) == 0 {
+		log.Fatal("Need name of package, use --name")
+	}
+	pkgNamespace := c.String("pkgNamespace")
+
+	pkg, err := client.PackageGet(&metav1.ObjectMeta{
+		Namespace: pkgNamespace,
+		Name:      pkgName,
+	})
+	util.CheckErr(err, fmt.Sprintf("find package %s", pkgName))
+
+	if c.Bool("follow") {
+		if len(c.String("step")) > 0 {
+			log.Fatal("--step can't be used with --follow")
+		}
+		followPackageBuild(client, pkg)
+		return nil
+	}
+
+	printPackageBuildLog(client, pkg, c.String("step"))
 	return nil
 }
 
+// followPackageBuild prints the logs of the build of the package from the
+// builder pod while it runs, and the build status once it's over. The logs
+// of a package that isn't being built are printed from the package.
+func followPackageBuild(client *client.Client, pkg *fv1.Package) {
+	if pkg.Status.BuildStatus != fv1.BuildStatusPending && pkg.Status.BuildStatus != fv1.BuildStatusRunning {
+		printPackageBuildLog(client, pkg, "")
+		return
+	}
+
+	var followed fv1.BuildPod
+	for {
+		switch pkg.Status.BuildStatus {
+		case fv1.BuildStatusPending, fv1.BuildStatusRunning:
+		default:
+			fmt.Printf("--- Build %v ---\n", strings.ToUpper(string(pkg.Status.BuildStatus)))
+			return
+		}
+
+		// the logs of a build are followed once, the stream ends when the
+		// build is over or moves to another builder pod
+		buildPod := pkg.Status.BuildPod
+		if pkg.Status.BuildStatus == fv1.BuildStatusRunning && buildPod != nil && *buildPod != followed {
+			logs, err := client.PackageBuildLogs(&pkg.Metadata)
+			if err == nil {
+				followed = *buildPod
+				io.Copy(os.Stdout, logs)
+				logs.Close()
+			}
+		} else {
+			time.Sleep(time.Second)
+		}
+
+		m := pkg.Metadata
+		var err error
+		pkg, err = client.PackageGet(&m)
+		util.CheckErr(err, fmt.Sprintf("find package %s", m.Name))
+	}
+}
+
+// getPackageBuildLog returns the full build log of the package stored in
+// the storage service, or nil if the package doesn't have one.
+func getPackageBuildLog(client *client.Client, pkg *fv1.Package) *types.PackageBuildLog {
+	if len(pkg.Status.BuildLogURL) == 0 {
+		return nil
+	}
+
+	reader := downloadStoragesvcURL(client, pkg.Status.BuildLogURL)
+	if reader == nil {
+		log.Fatal(fmt.Sprintf("Invalid build log url: %v", pkg.Status.BuildLogURL))
+	}
+	defer reader.Close()
+
+	var buildLog types.PackageBuildLog
+	err := json.NewDecoder(reader).Decode(&buildLog)
+	util.CheckErr(err, "decode package build log")
+
+	return &buildLog
+}
+
+// printPackageBuildLog prints the full build log of the package. If step is
+// not empty, only the log of that build step is printed.
+// parseBuildSteps parses build steps in the form of name=command or
+// name@image=command, in the order they run.
+func parseBuildSteps(values []string) ([]fv1.BuildStep, error) {
+	steps := make([]fv1.BuildStep, 0, len(values))
+	for _, value := range values {
+		kv := strings.SplitN(value, "=", 2)
+		if len(kv) != 2 || len(strings.TrimSpace(kv[1])) == 0 {
+			return nil, fmt.Errorf("invalid build step %q, should be in the form of name=command or name@image=command", value)
+		}
+
+		step := fv1.BuildStep{
+			Name:    kv[0],
+			Command: kv[1],
+		}
+		if i := strings.Index(kv[0], "@"); i >= 0 {
+			step.Name, step.Image = kv[0][:i], kv[0][i+1:]
+		}
+
+		err := step.Validate()
+		if err != nil {
+			return nil, err
+		}
+		steps = append(steps, step)
+	}
+	return steps, nil
+}
+
+// parseBuildEnv parses build environment variables in the form of
+// KEY=VALUE, nil is returned if there is none.
+func parseBuildEnv(values []string) (map[string]string, error) {
+	if len(values) == 0 {
+		return nil, nil
+	}
+	env := make(map[string]string, len(values))
+	for _, value := range values {
+		kv := strings.SplitN(value, "=", 2)
+		if len(kv) != 2 || len(kv[0]) == 0 {
+			return nil, fmt.Errorf("invalid build environment variable %q, should be in the form of KEY=VALUE", value)
+		}
+		env[kv[0]] = kv[1]
+	}
+	return env, nil
+}
+
+func printPackageBuildLog(client *client.Client, pkg *fv1.Package, step string) {
+	buildLog := getPackageBuildLog(client, pkg)
+	if buildLog == nil {
+		// packages built by older versions only have the log in the status
+		if len(step) > 0 {
+			log.Fatal("Build log of the package isn't split into steps, try without --step")
+		}
+		fmt.Print(pkg.Status.BuildLog)
+		return
+	}
+
+	found := false
+	for _, s := range buildLog.Steps {
+		if len(step) > 0 && s.Name != step {
+			continue
+		}
+		found = true
+		fmt.Printf("--- %v ---\n%v", s.Name, s.Log)
+		if !strings.HasSuffix(s.Log, "\n") {
+			fmt.Println()
+		}
+	}
+	if len(step) > 0 && !found {
+		log.Fatal(fmt.Sprintf("No log found for build step %q", step))
+	}
+}
+
 func pkgList(c *cli.Context) error {
 	client := util.GetApiClient(c.GlobalString("server"))
 	// option for the user to list all orphan packages (not referenced by any function)
 	listOrphans := c.Bool("orphan")
 	pkgNamespace := c.String("pkgNamespace")
 
-	pkgList, err := client.PackageList(pkgNamespace)
+	pkgList, err := client.PackageListWithOptions(pkgNamespace, listOptions(c))
 	if err != nil {
 		return err
 	}
@@ -457,10 +734,27 @@ func pkgRebuild(c *cli.Context) error {
 			pkg.Metadata.Name, fv1.BuildStatusFailed))
 	}
 
-	_, err = updatePackage(client, pkg, "", "", nil, nil, "", true, false)
+	pkgMeta, err := updatePackage(client, pkg, "", "", nil, nil, "", "", true, false)
 	util.CheckErr(err, "update package")
 
-	fmt.Printf("Retrying build for pkg %v. Use \"fission pkg info --name %v\" to view status.\n", pkg.Metadata.Name, pkg.Metadata.Name)
+	if !c.Bool("wait") {
+		fmt.Printf("Retrying build for pkg %v. Use \"fission pkg info --name %v\" to view status.\n", pkg.Metadata.Name, pkg.Metadata.Name)
+		return nil
+	}
+
+	fmt.Printf("Retrying build for pkg %v, waiting for it...\n", pkg.Metadata.Name)
+	pbw := makePackageBuildWatcher(client)
+	pbw.addPackages(map[string]metav1.ObjectMeta{mapKey(pkgMeta): *pkgMeta})
+	ctx := interruptContext()
+	pbw.watch(ctx)
+
+	report := pbw.report()
+	if len(report.Failed) > 0 {
+		log.Fatal(fmt.Sprintf("Package build failed: %v", pkg.Metadata.Name))
+	}
+	if ctx.Err() != nil {
+		log.Fatal(fmt.Sprintf("Interrupted while waiting for the build of package %v, it continues in the cluster", pkg.Metadata.Name))
+	}
 
 	return nil
 }
@@ -501,7 +795,7 @@ func createArchive(client *client.Client, includeFiles []string, noZip bool, spe
 	// check files existence
 	for _, path := range includeFiles {
 		// ignore http files
-		if strings.HasPrefix(path, "http://") || strings.HasPrefix(path, "https://") {
+		if isHTTPURL(path) {
 			continue
 		}
 
@@ -521,6 +815,23 @@ func createArchive(client *client.Client, includeFiles []string, noZip bool, spe
 	}
 
 	if len(specFile) > 0 {
+		// an ArchiveUploadSpec only holds local files, a remote archive is
+		// referenced by its URL with the checksum of the current content.
+		if len(includeFiles) == 1 && isHTTPURL(includeFiles[0]) {
+			csum, err := fileChecksum(downloadToTempFile(includeFiles[0]))
+			util.CheckErr(err, fmt.Sprintf("calculate checksum for %v", includeFiles[0]))
+			return &fv1.Archive{
+				Type:     fv1.ArchiveTypeUrl,
+				URL:      includeFiles[0],
+				Checksum: *csum,
+			}
+		}
+		for _, path := range includeFiles {
+			if isHTTPURL(path) {
+				log.Fatal(fmt.Sprintf("URL %v can't be used with other files in an archive spec", path))
+			}
+		}
+
 		// create an ArchiveUploadSpec and reference it from the archive
 		aus := &spec.ArchiveUploadSpec{
 			Name:         archiveName("", includeFiles),
@@ -549,8 +860,40 @@ func createArchive(client *client.Client, includeFiles []string, noZip bool, spe
 
 	archivePath := makeArchiveFileIfNeeded("", includeFiles, noZip)
 
-	ctx := context.Background()
-	return uploadArchive(ctx, client, archivePath)
+	return uploadArchive(interruptContext(), client, archivePath)
+}
+
+func isHTTPURL(path string) bool {
+	return strings.HasPrefix(path, "http://") || strings.HasPrefix(path, "https://")
+}
+
+// archiveUploadSecret is the Secret the charts keep the archive upload token
+// of the controller in.
+const archiveUploadSecret = "fission-archive-upload"
+
+// archiveUploadToken returns $FISSION_ARCHIVE_UPLOAD_TOKEN or, if the
+// controller is reached through a port forward, the token in the Secret of
+// the Fission install. It returns "" if there's none.
+func archiveUploadToken() string {
+	if token := os.Getenv("FISSION_ARCHIVE_UPLOAD_TOKEN"); len(token) > 0 {
+		return token
+	}
+	if len(os.Getenv("FISSION_URL")) > 0 {
+		// no kubeconfig is needed to reach the controller
+		return ""
+	}
+
+	ns := util.GetFissionNamespace()
+	if len(ns) == 0 {
+		ns = "fission"
+	}
+	_, kubeClient := util.GetKubernetesClient()
+	secret, err := kubeClient.CoreV1().Secrets(ns).Get(archiveUploadSecret, metav1.GetOptions{})
+	if err != nil {
+		log.Verbose(2, "Couldn't read the archive upload token from Secret %v/%v: %v", ns, archiveUploadSecret, err)
+		return ""
+	}
+	return string(secret.Data["token"])
 }
 
 func uploadArchive(ctx context.Context, client *client.Client, fileName string) *fv1.Archive {
@@ -561,15 +904,36 @@ func uploadArchive(ctx context.Context, client *client.Client, fileName string)
 		fileName = downloadToTempFile(fileName)
 	}
 
-	if fileSize(fileName) < types.ArchiveLiteralSizeLimit {
+	var literal []byte
+	var isLiteral bool
+	limit := literalSizeLimit(client)
+	if fileSize(fileName) < limit {
+		var err error
+		literal, isLiteral, err = readLiteral(fileName, limit)
+		util.CheckErr(err, fmt.Sprintf("read %v", fileName))
+	}
+
+	if isLiteral {
 		archive.Type = fv1.ArchiveTypeLiteral
-		archive.Literal = getContents(fileName)
+		archive.Literal = literal
 	} else {
-		u := strings.TrimSuffix(client.Url, "/") + "/proxy/storage"
-		ssClient := storageSvcClient.MakeClient(u)
-
-		// TODO add a progress bar
-		id, err := ssClient.Upload(ctx, fileName, nil)
+		var sent int64
+		total := fileSize(fileName)
+		start := time.Now()
+		stopProgress := startProgress(func() string {
+			return uploadProgress(filepath.Base(fileName), atomic.LoadInt64(&sent), total, time.Since(start))
+		})
+		id, err := client.ArchiveUploadWithProgress(ctx, fileName, archiveUploadToken(), func(n int64, _ int64) {
+			atomic.StoreInt64(&sent, n)
+		})
+		stopProgress()
+		if ctx.Err() != nil {
+			log.Fatal(fmt.Sprintf("Upload of %v interrupted", fileName))
+		}
+		if e, ok := err.(ferror.Error); ok && e.Code == ferror.ErrorNotAuthorized {
+			log.Fatal(fmt.Sprintf("Failed to upload file %v: %v, set FISSION_ARCHIVE_UPLOAD_TOKEN to the token of the '%v' Secret of the Fission install",
+				fileName, err, archiveUploadSecret))
+		}
 		util.CheckErr(err, fmt.Sprintf("upload file %v", fileName))
 
 		storageSvc, err := client.GetSvcURL("application=fission-storage")
@@ -609,7 +973,12 @@ func createPackage(c *cli.Context, client *client.Client, pkgNamespace string, e
 		pkgSpec.Deployment = *createArchive(client, deployArchiveFiles, noZip, specDir, specFile)
 		pkgName = util.KubifyName(fmt.Sprintf("%v-%v", path.Base(deployArchiveFiles[0]), uniuri.NewLen(4)))
 	}
-	if len(srcArchiveFiles) > 0 {
+	if gitArchive := getGitArchive(srcArchiveFiles, c.String("git-secret")); gitArchive != nil {
+		pkgSpec.Source = *gitArchive
+		pkgStatus = fv1.BuildStatusPending // set package build status to pending
+		repoName := strings.TrimSuffix(path.Base(strings.Replace(gitArchive.URL, ":", "/", -1)), ".git")
+		pkgName = util.KubifyName(fmt.Sprintf("%v-%v", repoName, uniuri.NewLen(4)))
+	} else if len(srcArchiveFiles) > 0 {
 		pkgSpec.Source = *createArchive(client, srcArchiveFiles, false, specDir, specFile)
 		pkgStatus = fv1.BuildStatusPending // set package build status to pending
 		pkgName = util.KubifyName(fmt.Sprintf("%v-%v", path.Base(srcArchiveFiles[0]), uniuri.NewLen(4)))
@@ -619,6 +988,17 @@ func createPackage(c *cli.Context, client *client.Client, pkgNamespace string, e
 		pkgSpec.BuildCommand = buildcmd
 	}
 
+	if buildSteps := c.StringSlice("buildstep"); len(buildSteps) > 0 {
+		steps, err := parseBuildSteps(buildSteps)
+		util.CheckErr(err, "parse build steps")
+		pkgSpec.BuildSteps = steps
+	}
+
+	buildEnv, err := parseBuildEnv(c.StringSlice("build-env"))
+	util.CheckErr(err, "parse build environment variables")
+	pkgSpec.BuildEnv = buildEnv
+	pkgSpec.BuildSecrets = c.StringSlice("build-secret")
+
 	if len(pkgName) == 0 {
 		pkgName = strings.ToLower(uuid.NewV4().String())
 	}
@@ -654,27 +1034,52 @@ func createPackage(c *cli.Context, client *client.Client, pkgNamespace string, e
 	}
 }
 
-func getContents(filePath string) []byte {
-	var code []byte
-	var err error
-
-	code, err = ioutil.ReadFile(filePath)
-	util.CheckErr(err, fmt.Sprintf("read %v", filePath))
-	return code
+// literalSizeLimit returns the archive literal size limit advertised by the
+// controller, or the default one for controllers that don't advertise it.
+func literalSizeLimit(client *client.Client) int64 {
+	serverInfo, err := client.ServerInfo()
+	if err != nil || serverInfo.ArchiveLiteralSizeLimit <= 0 {
+		return types.ArchiveLiteralSizeLimit
+	}
+	return serverInfo.ArchiveLiteralSizeLimit
 }
 
-func writeArchiveToFile(fileName string, reader io.Reader) error {
-	tmpDir, err := utils.GetTempDir()
+// readLiteral streams the file into an archive literal. It stops reading as
+// soon as the file goes past limit, e.g. if it grew after being sized, and
+// returns false so that the caller uploads it instead.
+func readLiteral(filePath string, limit int64) ([]byte, bool, error) {
+	f, err := os.Open(filePath)
 	if err != nil {
-		return err
+		return nil, false, err
 	}
+	defer f.Close()
 
-	path := filepath.Join(tmpDir, fileName+".tmp")
-	w, err := os.Create(path)
+	buf := &bytes.Buffer{}
+	if fi, err := f.Stat(); err == nil && fi.Size() < limit {
+		buf.Grow(int(fi.Size()))
+	}
+	n, err := io.Copy(buf, io.LimitReader(f, limit))
+	if err != nil {
+		return nil, false, err
+	}
+	if n >= limit {
+		return nil, false, nil
+	}
+	return buf.Bytes(), true, nil
+}
+
+// writeArchiveToFile writes the archive to a temp file next to fileName
+// and then renames it, so that fileName is never left half-written.
+func writeArchiveToFile(fileName string, reader io.Reader) error {
+	w, err := ioutil.TempFile(filepath.Dir(fileName), filepath.Base(fileName)+".*.tmp")
 	if err != nil {
 		return err
 	}
+	path := w.Name()
+	defer os.Remove(path)
+
 	_, err = io.Copy(w, reader)
+	w.Close()
 	if err != nil {
 		return err
 	}
@@ -0,0 +1,86 @@
+package fission_cli
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	fv1 "github.com/fission/fission/pkg/apis/fission.io/v1"
+)
+
+func TestReadLiteral(t *testing.T) {
+	dir, err := ioutil.TempDir("", "fission-literal")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	cases := []struct {
+		size      int
+		limit     int64
+		isLiteral bool
+	}{
+		{size: 0, limit: 16, isLiteral: true},
+		{size: 15, limit: 16, isLiteral: true},
+		{size: 16, limit: 16, isLiteral: false},
+		{size: 64, limit: 16, isLiteral: false},
+	}
+
+	for _, c := range cases {
+		contents := bytes.Repeat([]byte("a"), c.size)
+		fileName := filepath.Join(dir, "archive")
+		assert.NoError(t, ioutil.WriteFile(fileName, contents, 0644))
+
+		literal, isLiteral, err := readLiteral(fileName, c.limit)
+		assert.NoError(t, err)
+		assert.Equal(t, c.isLiteral, isLiteral, "size %v, limit %v", c.size, c.limit)
+		if c.isLiteral {
+			assert.Equal(t, len(contents), len(literal))
+		} else {
+			assert.Nil(t, literal)
+		}
+	}
+
+	_, _, err = readLiteral(filepath.Join(dir, "missing"), 16)
+	assert.Error(t, err)
+}
+
+func TestParseBuildSteps(t *testing.T) {
+	steps, err := parseBuildSteps([]string{
+		"deps=pip3 install -r requirements.txt -t ${SRC_PKG}",
+		"test@fission/python-builder=python3 -m pytest",
+		"copy=cp -r ${SRC_PKG} ${DEPLOY_PKG}",
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, []fv1.BuildStep{
+		{Name: "deps", Command: "pip3 install -r requirements.txt -t ${SRC_PKG}"},
+		{Name: "test", Command: "python3 -m pytest", Image: "fission/python-builder"},
+		{Name: "copy", Command: "cp -r ${SRC_PKG} ${DEPLOY_PKG}"},
+	}, steps)
+
+	for _, value := range []string{"deps", "deps=", "=make", "Deps=make"} {
+		_, err := parseBuildSteps([]string{value})
+		assert.Error(t, err, value)
+	}
+}
+
+func TestParseBuildEnv(t *testing.T) {
+	env, err := parseBuildEnv([]string{"NPM_REGISTRY=https://npm.example.com", "EXTRA_ARGS=--a=b", "EMPTY="})
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]string{
+		"NPM_REGISTRY": "https://npm.example.com",
+		"EXTRA_ARGS":   "--a=b",
+		"EMPTY":        "",
+	}, env)
+
+	env, err = parseBuildEnv(nil)
+	assert.NoError(t, err)
+	assert.Nil(t, env)
+
+	for _, value := range []string{"KEY", "=value"} {
+		_, err := parseBuildEnv([]string{value})
+		assert.Error(t, err, value)
+	}
+}
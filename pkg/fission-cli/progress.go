@@ -0,0 +1,113 @@
+/*
+Copyright 2016 The Fission Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package fission_cli
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+)
+
+// progressInterval is how often long operations print their progress.
+const progressInterval = 5 * time.Second
+
+var (
+	interruptOnce sync.Once
+	interruptCtx  context.Context
+)
+
+// interruptContext returns a context that's cancelled on the first Ctrl-C,
+// so that long operations stop waiting on the server and report where they
+// stopped. A second Ctrl-C exits right away. The signal handler is only
+// installed once an operation asks for the context, other commands keep the
+// default handling.
+func interruptContext() context.Context {
+	interruptOnce.Do(func() {
+		var cancel context.CancelFunc
+		interruptCtx, cancel = context.WithCancel(context.Background())
+
+		signals := make(chan os.Signal, 1)
+		signal.Notify(signals, os.Interrupt, syscall.SIGTERM)
+		go func() {
+			<-signals
+			// restore the default handling for the second signal
+			signal.Stop(signals)
+			fmt.Fprintln(os.Stderr, "Interrupted, stopping (press Ctrl-C again to exit right away)")
+			cancel()
+		}()
+	})
+	return interruptCtx
+}
+
+// startProgress prints the status every progressInterval until the returned
+// function is called. Progress goes to stderr, the output of commands stays
+// parsable.
+func startProgress(status func() string) (stop func()) {
+	done := make(chan struct{})
+	go func() {
+		ticker := time.NewTicker(progressInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-done:
+				return
+			case <-ticker.C:
+				fmt.Fprintln(os.Stderr, status())
+			}
+		}
+	}()
+
+	var once sync.Once
+	return func() {
+		once.Do(func() { close(done) })
+	}
+}
+
+// uploadProgress is the progress message of an archive upload.
+func uploadProgress(fileName string, sent int64, total int64, elapsed time.Duration) string {
+	percent := int64(100)
+	if total > 0 {
+		percent = sent * 100 / total
+	}
+	return fmt.Sprintf("uploading %v: %v of %v (%v%%), %v elapsed",
+		fileName, formatBytes(sent), formatBytes(total), percent, elapsed.Round(time.Second))
+}
+
+// buildProgress is the progress message of waiting for package builds.
+func buildProgress(pending []string, elapsed time.Duration) string {
+	return fmt.Sprintf("waiting for %v package build(s): %v, %v elapsed",
+		len(pending), strings.Join(pending, ", "), elapsed.Round(time.Second))
+}
+
+// formatBytes formats a size in bytes with a binary unit.
+func formatBytes(n int64) string {
+	const unit = 1024
+	if n < unit {
+		return fmt.Sprintf("%v B", n)
+	}
+	div, exp := int64(unit), 0
+	for m := n / unit; m >= unit; m /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %ciB", float64(n)/float64(div), "KMGTPE"[exp])
+}
@@ -0,0 +1,25 @@
+package fission_cli
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFormatBytes(t *testing.T) {
+	assert.Equal(t, "0 B", formatBytes(0))
+	assert.Equal(t, "1023 B", formatBytes(1023))
+	assert.Equal(t, "1.0 KiB", formatBytes(1024))
+	assert.Equal(t, "1.5 MiB", formatBytes(3*1024*1024/2))
+	assert.Equal(t, "2.0 GiB", formatBytes(2*1024*1024*1024))
+}
+
+func TestProgressMessages(t *testing.T) {
+	assert.Equal(t, "uploading app.zip: 512 B of 1.0 KiB (50%), 3s elapsed",
+		uploadProgress("app.zip", 512, 1024, 3200*time.Millisecond))
+	assert.Equal(t, "uploading empty.zip: 0 B of 0 B (100%), 0s elapsed",
+		uploadProgress("empty.zip", 0, 0, 0))
+	assert.Equal(t, "waiting for 2 package build(s): default:a, default:b, 1m30s elapsed",
+		buildProgress([]string{"default:a", "default:b"}, 90*time.Second))
+}
@@ -88,7 +88,10 @@ func recorderCreate(c *cli.Context) error {
 		return nil
 	}
 
-	_, err := client.RecorderCreate(recorder)
+	err := checkRecorderEnabled(getServerFeatures(client))
+	util.CheckErr(err, "create recorder")
+
+	_, err = client.RecorderCreate(recorder)
 	util.CheckErr(err, "create recorder")
 
 	fmt.Printf("recorder '%s' created\n", recName)
@@ -64,6 +64,46 @@ func recordsView(c *cli.Context) error {
 	return nil
 }
 
+func recordsExport(c *cli.Context) error {
+	fc := util.GetApiClient(c.GlobalString("server"))
+
+	to := c.String("to")
+	if len(to) == 0 || to == "-" {
+		err := fc.RecordsExport(os.Stdout)
+		util.CheckErr(err, "export records")
+		return nil
+	}
+
+	f, err := os.Create(to)
+	util.CheckErr(err, fmt.Sprintf("create file '%v'", to))
+	defer f.Close()
+
+	err = fc.RecordsExport(f)
+	util.CheckErr(err, "export records")
+
+	fmt.Printf("Exported records to '%v'\n", to)
+	return nil
+}
+
+func recordsImport(c *cli.Context) error {
+	fc := util.GetApiClient(c.GlobalString("server"))
+
+	from := c.String("from")
+	if len(from) == 0 {
+		log.Fatal("Need a file to import records from, use --from flag to specify")
+	}
+
+	f, err := os.Open(from)
+	util.CheckErr(err, fmt.Sprintf("open file '%v'", from))
+	defer f.Close()
+
+	count, err := fc.RecordsImport(f, c.String("recorder"))
+	util.CheckErr(err, "import records")
+
+	fmt.Printf("Imported %v records from '%v'\n", count, from)
+	return nil
+}
+
 func recordsAll(verbosity int, c *cli.Context) error {
 	fc := util.GetApiClient(c.GlobalString("server"))
 
@@ -34,7 +34,7 @@ func replay(c *cli.Context) error {
 		log.Fatal("Need a reqUID, use --reqUID flag to specify")
 	}
 
-	responses, err := fc.ReplayByReqUID(reqUID)
+	responses, err := fc.ReplayByReqUID(reqUID, c.String("target"), c.String("targetNamespace"))
 	util.CheckErr(err, "replay records")
 
 	w := tabwriter.NewWriter(os.Stdout, 0, 0, 1, ' ', 0)
@@ -0,0 +1,604 @@
+/*
+Copyright 2019 The Fission Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package fission_cli
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"os/signal"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/urfave/cli"
+	"golang.org/x/crypto/ssh/terminal"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/fission/fission/pkg/controller/client"
+	"github.com/fission/fission/pkg/fission-cli/cmd"
+	"github.com/fission/fission/pkg/fission-cli/util"
+)
+
+const (
+	shellPrompt = "fission> "
+
+	// names fetched from the server for completion are reused for a while,
+	// so that pressing tab repeatedly doesn't hit the server every time
+	shellNameCacheTTL = 5 * time.Second
+)
+
+var (
+	// shellDefaultFlags are the flags a session default is passed as, to
+	// the commands which have them.
+	shellDefaultFlags = map[string][]string{
+		"namespace": {"fnNamespace", cmd.ENVIRONMENT_NAMESPACE, "pkgNamespace", "triggerNamespace",
+			"recorderNamespace", "canaryNamespace", "aliasNamespace"},
+		"env": {"env"},
+	}
+
+	// shellNameFlags are the flags completed with the names of resources
+	// on the server. The kind of --name depends on the command.
+	shellNameFlags = map[string]string{
+		"env":      "environment",
+		"function": "function",
+		"pkg":      "package",
+		"pkgname":  "package",
+		"alias":    "alias",
+	}
+
+	shellCommandKinds = map[string]string{
+		"function":      "function",
+		"environment":   "environment",
+		"httptrigger":   "httptrigger",
+		"timetrigger":   "timetrigger",
+		"mqtrigger":     "mqtrigger",
+		"watch":         "watch",
+		"package":       "package",
+		"alias":         "alias",
+		"canary-config": "canaryconfig",
+	}
+
+	shellBuiltins = []string{"set", "unset", "defaults", "history", "help", "exit", "quit"}
+)
+
+type (
+	// fissionShell runs fission commands read from the terminal, with
+	// defaults for the namespace and environment kept for the session.
+	fissionShell struct {
+		app      *cli.App
+		self     string
+		server   string
+		client   *client.Client
+		defaults map[string]string
+		history  []string
+
+		namesLock sync.Mutex
+		names     map[string]shellNames
+	}
+
+	shellNames struct {
+		names     []string
+		fetchedAt time.Time
+	}
+)
+
+// shell starts an interactive shell. Each command runs in its own fission
+// process, so that a failing command doesn't end the session.
+func shell(c *cli.Context) error {
+	self, err := os.Executable()
+	util.CheckErr(err, "find fission executable")
+
+	// resolve the server once, so that the commands don't set up a port
+	// forward each
+	server := c.GlobalString(cmd.FISSION_SERVER)
+	if len(server) == 0 {
+		server = util.GetServerUrl()
+	}
+
+	sh := &fissionShell{
+		app:      c.App,
+		self:     self,
+		server:   server,
+		client:   util.GetApiClient(server),
+		defaults: make(map[string]string),
+		names:    make(map[string]shellNames),
+	}
+
+	fd := int(os.Stdin.Fd())
+	if !terminal.IsTerminal(fd) {
+		// commands piped to the shell
+		scanner := bufio.NewScanner(os.Stdin)
+		for scanner.Scan() {
+			if !sh.runLine(os.Stdout, scanner.Text()) {
+				break
+			}
+		}
+		util.CheckErr(scanner.Err(), "read commands")
+		return nil
+	}
+
+	fmt.Println("Fission shell. Type \"help\" for the shell commands, tab completes commands, flags and names, \"exit\" or Ctrl-D quits.")
+	term := terminal.NewTerminal(struct {
+		io.Reader
+		io.Writer
+	}{os.Stdin, os.Stdout}, shellPrompt)
+	term.AutoCompleteCallback = func(line string, pos int, key rune) (string, int, bool) {
+		if key != '\t' {
+			return "", 0, false
+		}
+		return sh.complete(term, line, pos)
+	}
+
+	for {
+		// the terminal is only raw while reading the line, the commands
+		// print to the terminal as usual
+		state, err := terminal.MakeRaw(fd)
+		util.CheckErr(err, "set up terminal")
+		line, err := term.ReadLine()
+		terminal.Restore(fd, state)
+		if err == io.EOF {
+			fmt.Println()
+			return nil
+		}
+		util.CheckErr(err, "read command")
+
+		if !sh.runLine(os.Stdout, line) {
+			return nil
+		}
+	}
+}
+
+// runLine runs one line of the shell, and returns false if the shell
+// should quit.
+func (sh *fissionShell) runLine(out io.Writer, line string) bool {
+	args, err := splitShellArgs(line)
+	if err != nil {
+		fmt.Fprintf(out, "Error: %v\n", err)
+		return true
+	}
+	if len(args) == 0 {
+		return true
+	}
+	// commands copied from scripts often start with the binary name
+	if args[0] == "fission" {
+		args = args[1:]
+		if len(args) == 0 {
+			return true
+		}
+	}
+	sh.history = append(sh.history, line)
+
+	switch args[0] {
+	case "exit", "quit":
+		return false
+	case "help":
+		fmt.Fprint(out, `Shell commands:
+  set <namespace|env> <value>  Use a default for the commands of this session
+  unset <namespace|env>        Remove a default
+  defaults                     Show the defaults
+  history                      Show the commands of this session
+  exit, quit                   Quit the shell
+Any other line runs a fission command, e.g. "fn list"; "fission --help" lists them.
+`)
+	case "set":
+		if len(args) != 3 || shellDefaultFlags[args[1]] == nil {
+			fmt.Fprintln(out, "Usage: set <namespace|env> <value>")
+			break
+		}
+		sh.defaults[args[1]] = args[2]
+	case "unset":
+		if len(args) != 2 {
+			fmt.Fprintln(out, "Usage: unset <namespace|env>")
+			break
+		}
+		delete(sh.defaults, args[1])
+	case "defaults":
+		keys := make([]string, 0, len(sh.defaults))
+		for k := range sh.defaults {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			fmt.Fprintf(out, "%v=%v\n", k, sh.defaults[k])
+		}
+	case "history":
+		for i, l := range sh.history {
+			fmt.Fprintf(out, "%5d  %v\n", i+1, l)
+		}
+	case "shell":
+		fmt.Fprintln(out, "Already in the fission shell")
+	default:
+		sh.runCommand(out, args)
+	}
+	return true
+}
+
+// runCommand runs a fission command with the session defaults.
+func (sh *fissionShell) runCommand(out io.Writer, args []string) {
+	args = applyShellDefaults(sh.app, args, sh.defaults)
+	args = append([]string{"--" + cmd.FISSION_SERVER, sh.server}, args...)
+
+	c := exec.Command(sh.self, args...)
+	c.Stdin = os.Stdin
+	c.Stdout = out
+	c.Stderr = os.Stderr
+
+	// Ctrl-C stops the command, not the shell
+	interrupts := make(chan os.Signal, 1)
+	signal.Notify(interrupts, os.Interrupt)
+	defer signal.Stop(interrupts)
+
+	err := c.Run()
+	if _, ok := err.(*exec.ExitError); err != nil && !ok {
+		fmt.Fprintf(out, "Error running command: %v\n", err)
+	}
+}
+
+// findShellCommand returns the command and, if given, the subcommand the
+// arguments run, and the index of the first argument after them.
+func findShellCommand(app *cli.App, args []string) (*cli.Command, int) {
+	if len(args) == 0 {
+		return nil, 0
+	}
+	command := app.Command(args[0])
+	if command == nil {
+		return nil, 0
+	}
+	if len(args) > 1 {
+		for _, sub := range command.Subcommands {
+			if sub.HasName(args[1]) {
+				return &sub, 2
+			}
+		}
+	}
+	return command, 1
+}
+
+// applyShellDefaults adds the session defaults as flags to the arguments,
+// for the flags the command has and that aren't given already. The flags
+// are put right after the command, since flags after positional arguments
+// aren't parsed.
+func applyShellDefaults(app *cli.App, args []string, defaults map[string]string) []string {
+	command, idx := findShellCommand(app, args)
+	if command == nil || len(defaults) == 0 {
+		return args
+	}
+
+	given := make(map[string]bool)
+	for _, arg := range args[idx:] {
+		if strings.HasPrefix(arg, "-") {
+			given[strings.SplitN(strings.TrimLeft(arg, "-"), "=", 2)[0]] = true
+		}
+	}
+
+	keys := make([]string, 0, len(defaults))
+	for k := range defaults {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	var defaultArgs []string
+	for _, key := range keys {
+		for _, flag := range command.Flags {
+			names := strings.Split(flag.GetName(), ",")
+			for i := range names {
+				names[i] = strings.TrimSpace(names[i])
+			}
+			if !containsString(shellDefaultFlags[key], names[0]) {
+				continue
+			}
+			isGiven := false
+			for _, name := range names {
+				isGiven = isGiven || given[name]
+			}
+			if !isGiven {
+				defaultArgs = append(defaultArgs, "--"+names[0], defaults[key])
+			}
+		}
+	}
+
+	result := append([]string{}, args[:idx]...)
+	result = append(result, defaultArgs...)
+	return append(result, args[idx:]...)
+}
+
+func containsString(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
+
+// splitShellArgs splits a line into arguments like a shell does, with
+// single and double quotes and backslash escapes.
+func splitShellArgs(line string) ([]string, error) {
+	var args []string
+	var arg strings.Builder
+	inArg := false
+	var quote rune
+	escaped := false
+
+	for _, r := range line {
+		switch {
+		case escaped:
+			arg.WriteRune(r)
+			escaped = false
+		case r == '\\' && quote != '\'':
+			escaped = true
+			inArg = true
+		case quote != 0:
+			if r == quote {
+				quote = 0
+			} else {
+				arg.WriteRune(r)
+			}
+		case r == '\'' || r == '"':
+			quote = r
+			inArg = true
+		case r == ' ' || r == '\t':
+			if inArg {
+				args = append(args, arg.String())
+				arg.Reset()
+				inArg = false
+			}
+		default:
+			arg.WriteRune(r)
+			inArg = true
+		}
+	}
+
+	if quote != 0 {
+		return nil, errors.New("unterminated quote")
+	}
+	if escaped {
+		return nil, errors.New("line ends with a backslash")
+	}
+	if inArg {
+		args = append(args, arg.String())
+	}
+	return args, nil
+}
+
+// complete completes the word before the cursor with a command, a flag,
+// or the name of a resource on the server.
+func (sh *fissionShell) complete(out io.Writer, line string, pos int) (string, int, bool) {
+	words := strings.Fields(line[:pos])
+	word := ""
+	if len(words) > 0 && !strings.HasSuffix(line[:pos], " ") {
+		word = words[len(words)-1]
+		words = words[:len(words)-1]
+	}
+	if len(words) > 0 && words[0] == "fission" {
+		words = words[1:]
+	}
+
+	candidates := sh.candidates(words, word)
+	var matches []string
+	for _, c := range candidates {
+		if strings.HasPrefix(c, word) {
+			matches = append(matches, c)
+		}
+	}
+	if len(matches) == 0 {
+		return "", 0, false
+	}
+
+	completion := commonPrefix(matches)
+	if len(matches) == 1 {
+		completion += " "
+	} else if completion == word {
+		sort.Strings(matches)
+		fmt.Fprintln(out, strings.Join(matches, "  "))
+		return "", 0, false
+	}
+
+	start := pos - len(word)
+	return line[:start] + completion + line[pos:], start + len(completion), true
+}
+
+// candidates returns the words that may follow the given words.
+func (sh *fissionShell) candidates(words []string, word string) []string {
+	if len(words) == 0 {
+		var names []string
+		for _, c := range sh.app.VisibleCommands() {
+			names = append(names, c.Names()...)
+		}
+		return append(names, shellBuiltins...)
+	}
+
+	if words[0] == "set" || words[0] == "unset" {
+		if len(words) == 1 {
+			return []string{"namespace", "env"}
+		}
+		if len(words) == 2 && words[1] == "env" {
+			return sh.resourceNames("environment")
+		}
+		return nil
+	}
+
+	top := sh.app.Command(words[0])
+	if top == nil {
+		return nil
+	}
+	if len(words) == 1 && len(top.Subcommands) > 0 {
+		var names []string
+		for _, c := range top.Subcommands {
+			if !c.Hidden {
+				names = append(names, c.Names()...)
+			}
+		}
+		return names
+	}
+
+	command, _ := findShellCommand(sh.app, words)
+	if strings.HasPrefix(word, "-") {
+		var flags []string
+		for _, flag := range command.Flags {
+			name := strings.TrimSpace(strings.Split(flag.GetName(), ",")[0])
+			flags = append(flags, "--"+name)
+		}
+		return flags
+	}
+
+	// complete the value of the flag before the word
+	prev := strings.TrimLeft(words[len(words)-1], "-")
+	if prev == words[len(words)-1] {
+		return nil
+	}
+	if prev == cmd.RESOURCE_NAME {
+		if kind, ok := shellCommandKinds[top.Name]; ok {
+			return sh.resourceNames(kind)
+		}
+		return nil
+	}
+	if kind, ok := shellNameFlags[prev]; ok {
+		return sh.resourceNames(kind)
+	}
+	return nil
+}
+
+// resourceNames returns the names of the resources of a kind in the
+// namespace of the session.
+func (sh *fissionShell) resourceNames(kind string) []string {
+	ns := sh.defaults["namespace"]
+	if len(ns) == 0 {
+		ns = metav1.NamespaceDefault
+	}
+	key := kind + "/" + ns
+
+	sh.namesLock.Lock()
+	defer sh.namesLock.Unlock()
+	if cached, ok := sh.names[key]; ok && time.Since(cached.fetchedAt) < shellNameCacheTTL {
+		return cached.names
+	}
+
+	names, err := listResourceNames(sh.client, kind, ns)
+	if err != nil {
+		// completion is best effort, the command reports the error
+		return nil
+	}
+	sh.names[key] = shellNames{names: names, fetchedAt: time.Now()}
+	return names
+}
+
+func listResourceNames(fclient *client.Client, kind string, ns string) ([]string, error) {
+	var metas []metav1.ObjectMeta
+	switch kind {
+	case "function":
+		objs, err := fclient.FunctionList(ns)
+		if err != nil {
+			return nil, err
+		}
+		for _, o := range objs {
+			metas = append(metas, o.Metadata)
+		}
+	case "environment":
+		objs, err := fclient.EnvironmentList(ns)
+		if err != nil {
+			return nil, err
+		}
+		for _, o := range objs {
+			metas = append(metas, o.Metadata)
+		}
+	case "package":
+		objs, err := fclient.PackageList(ns)
+		if err != nil {
+			return nil, err
+		}
+		for _, o := range objs {
+			metas = append(metas, o.Metadata)
+		}
+	case "httptrigger":
+		objs, err := fclient.HTTPTriggerList(ns)
+		if err != nil {
+			return nil, err
+		}
+		for _, o := range objs {
+			metas = append(metas, o.Metadata)
+		}
+	case "timetrigger":
+		objs, err := fclient.TimeTriggerList(ns)
+		if err != nil {
+			return nil, err
+		}
+		for _, o := range objs {
+			metas = append(metas, o.Metadata)
+		}
+	case "mqtrigger":
+		objs, err := fclient.MessageQueueTriggerList("", ns)
+		if err != nil {
+			return nil, err
+		}
+		for _, o := range objs {
+			metas = append(metas, o.Metadata)
+		}
+	case "watch":
+		objs, err := fclient.WatchList(ns)
+		if err != nil {
+			return nil, err
+		}
+		for _, o := range objs {
+			metas = append(metas, o.Metadata)
+		}
+	case "alias":
+		objs, err := fclient.FunctionAliasList(ns)
+		if err != nil {
+			return nil, err
+		}
+		for _, o := range objs {
+			metas = append(metas, o.Metadata)
+		}
+	case "canaryconfig":
+		objs, err := fclient.CanaryConfigList(ns)
+		if err != nil {
+			return nil, err
+		}
+		for _, o := range objs {
+			metas = append(metas, o.Metadata)
+		}
+	default:
+		return nil, fmt.Errorf("unknown resource kind %v", kind)
+	}
+
+	names := make([]string, 0, len(metas))
+	for _, m := range metas {
+		// message queue triggers of all namespaces are listed
+		if m.Namespace == ns {
+			names = append(names, m.Name)
+		}
+	}
+	sort.Strings(names)
+	return names, nil
+}
+
+// commonPrefix returns the longest prefix of all the strings.
+func commonPrefix(strs []string) string {
+	prefix := strs[0]
+	for _, s := range strs[1:] {
+		for !strings.HasPrefix(s, prefix) {
+			prefix = prefix[:len(prefix)-1]
+		}
+	}
+	return prefix
+}
@@ -0,0 +1,50 @@
+package fission_cli
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/urfave/cli"
+)
+
+func TestSplitShellArgs(t *testing.T) {
+	args, err := splitShellArgs(`fn test --name hello -H 'X-Foo: bar' --body "{\"a\": 1}"  `)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"fn", "test", "--name", "hello", "-H", "X-Foo: bar", "--body", `{"a": 1}`}, args)
+
+	args, err = splitShellArgs(`fn list --fns ""`)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"fn", "list", "--fns", ""}, args)
+
+	_, err = splitShellArgs(`fn test --name 'hello`)
+	assert.NotNil(t, err)
+}
+
+func TestApplyShellDefaults(t *testing.T) {
+	app := cli.NewApp()
+	app.Commands = []cli.Command{
+		{Name: "function", Aliases: []string{"fn"}, Subcommands: []cli.Command{
+			{Name: "create", Flags: []cli.Flag{
+				cli.StringFlag{Name: "name"},
+				cli.StringFlag{Name: "env"},
+				cli.StringFlag{Name: "fnNamespace, fns"},
+			}},
+		}},
+	}
+	defaults := map[string]string{"namespace": "dev", "env": "nodejs"}
+
+	assert.Equal(t, []string{"fn", "create", "--env", "nodejs", "--fnNamespace", "dev", "--name", "foo"},
+		applyShellDefaults(app, []string{"fn", "create", "--name", "foo"}, defaults))
+
+	// given flags win over the defaults, also by alias
+	assert.Equal(t, []string{"fn", "create", "--env", "nodejs", "--name", "foo", "--fns=prod"},
+		applyShellDefaults(app, []string{"fn", "create", "--name", "foo", "--fns=prod"}, defaults))
+
+	assert.Equal(t, []string{"unknown", "cmd"}, applyShellDefaults(app, []string{"unknown", "cmd"}, defaults))
+}
+
+func TestCommonPrefix(t *testing.T) {
+	assert.Equal(t, "hello-", commonPrefix([]string{"hello-world", "hello-fission"}))
+	assert.Equal(t, "foo", commonPrefix([]string{"foo"}))
+	assert.Equal(t, "", commonPrefix([]string{"foo", "bar"}))
+}
@@ -17,7 +17,6 @@ limitations under the License.
 package fission_cli
 
 import (
-	"bytes"
 	"context"
 	"fmt"
 	"io/ioutil"
@@ -43,7 +42,6 @@ import (
 	"github.com/fission/fission/pkg/fission-cli/cmd/spec"
 	"github.com/fission/fission/pkg/fission-cli/log"
 	"github.com/fission/fission/pkg/fission-cli/util"
-	"github.com/fission/fission/pkg/types"
 )
 
 // writeDeploymentConfig serializes the DeploymentConfig to YAML and writes it to a new
@@ -124,7 +122,7 @@ func specInit(c *cli.Context) error {
 func specValidate(c *cli.Context) error {
 	// this will error on parse errors and on duplicates
 	specDir := cmd.GetSpecDir(urfavecli.Parse(c))
-	fr, err := readSpecs(specDir)
+	fr, err := readAppSpecs(c, specDir)
 	util.CheckErr(err, "read specs")
 
 	// this does the rest of the checks, like dangling refs
@@ -139,6 +137,22 @@ func specValidate(c *cli.Context) error {
 // readSpecs reads all specs in the specified directory and returns a parsed set of
 // fission resources.
 func readSpecs(specDir string) (*spec.FissionResources, error) {
+	return readTemplatedSpecs(specDir, nil)
+}
+
+// readAppSpecs reads the specs with the variables and the overlay given
+// by --env-file and --overlay.
+func readAppSpecs(c *cli.Context, specDir string) (*spec.FissionResources, error) {
+	tmpl, err := getSpecTemplate(c)
+	if err != nil {
+		return nil, err
+	}
+	return readTemplatedSpecs(specDir, tmpl)
+}
+
+// readTemplatedSpecs reads the specs like readSpecs, substituting the
+// variables of the template and patching the specs with its overlay.
+func readTemplatedSpecs(specDir string, tmpl *specTemplate) (*spec.FissionResources, error) {
 
 	// make sure spec directory exists before continue
 	if _, err := os.Stat(specDir); os.IsNotExist(err) {
@@ -160,50 +174,31 @@ func readSpecs(specDir string) (*spec.FissionResources, error) {
 		},
 	}
 
-	result := &multierror.Error{}
-
-	// Users can organize the specdir into subdirs if they want to.
-	err := filepath.Walk(specDir, func(path string, info os.FileInfo, err error) error {
+	// Users can organize the specdir into subdirs if they want to, except
+	// for the overlays, which are only read when one is chosen.
+	overlaysDir := filepath.Join(specDir, specOverlaysDir)
+	docs, err := readSpecDocs(specDir, tmpl, overlaysDir)
+	if err != nil {
+		return nil, err
+	}
+	if tmpl != nil && len(tmpl.overlay) > 0 {
+		docs, err = applyOverlay(docs, filepath.Join(overlaysDir, tmpl.overlay), tmpl)
 		if err != nil {
-			return err
+			return nil, err
 		}
+	}
 
-		// For now just read YAML files. We'll add jsonnet at some point. Skip
-		// unsupported files.
-		if !(strings.HasSuffix(path, ".yaml") || strings.HasSuffix(path, ".yml")) {
-			return nil
-		}
-		// read
-		b, err := ioutil.ReadFile(path)
+	result := &multierror.Error{}
+	for _, doc := range docs {
+		// parse this document and add whatever is in it to fr
+		err = fr.ParseYaml(doc.data, &spec.Location{
+			Path: doc.path,
+			Line: doc.line,
+		})
 		if err != nil {
+			// collect all errors so user can fix them all
 			result = multierror.Append(result, err)
-			return nil
-		}
-		// handle the case where there are multiple YAML docs per file. go-yaml
-		// doesn't support this directly, yet.
-		docs := bytes.Split(b, []byte("\n---"))
-		lines := 1
-		for _, doc := range docs {
-			d := []byte(strings.TrimSpace(string(doc)))
-			if len(d) != 0 {
-				// parse this document and add whatever is in it to fr
-				err = fr.ParseYaml(d, &spec.Location{
-					Path: path,
-					Line: lines,
-				})
-				if err != nil {
-					// collect all errors so user can fix them all
-					result = multierror.Append(result, err)
-				}
-			}
-			// the separator occupies one line, hence the +1
-			lines += strings.Count(string(doc), "\n") + 1
 		}
-		return nil
-	})
-
-	if err != nil {
-		return nil, err
 	}
 	if err = result.ErrorOrNil(); err != nil {
 		return nil, err
@@ -245,19 +240,57 @@ func waitForFileWatcherToSettleDown(watcher *fsnotify.Watcher) error {
 // etc, while doing an apply, they will get a partially applied deployment.  However,
 // they can retry their apply command once they're back online.
 func specApply(c *cli.Context) error {
-	fclient := util.GetApiClient(c.GlobalString("server"))
 	specDir := cmd.GetSpecDir(urfavecli.Parse(c))
 
 	deleteResources := c.Bool("delete")
 	watchResources := c.Bool("watch")
 	waitForBuild := c.Bool("wait")
 
+	// render doesn't need the cluster, so check it before creating the client
+	if c.Bool("render") {
+		if watchResources || waitForBuild || c.Bool("dry-run") {
+			log.Fatal("--render can't be used with --watch, --wait or --dry-run")
+		}
+
+		fr, err := readAppSpecs(c, specDir)
+		util.CheckErr(err, "read specs")
+
+		err = fr.Validate(c)
+		util.CheckErr(err, "validate specs")
+
+		err = renderResources(os.Stdout, specDir, fr)
+		util.CheckErr(err, "render specs")
+		return nil
+	}
+
+	fclient := util.GetApiClient(c.GlobalString("server"))
+
+	if c.Bool("dry-run") {
+		if watchResources || waitForBuild {
+			log.Fatal("--dry-run can't be used with --watch or --wait")
+		}
+
+		fr, err := readAppSpecs(c, specDir)
+		util.CheckErr(err, "read specs")
+
+		err = fr.Validate(c)
+		util.CheckErr(err, "validate specs")
+
+		diffs, err := diffResources(fclient, specDir, fr, deleteResources)
+		util.CheckErr(err, "compute spec diff")
+		printResourceDiffs(diffs, "Dry run")
+		return nil
+	}
+
 	var watcher *fsnotify.Watcher
 	var pbw *packageBuildWatcher
 
 	if watchResources || waitForBuild {
 		// init package build watcher
 		pbw = makePackageBuildWatcher(fclient)
+		pbw.logLines = c.Int("build-log-lines")
+		// a failed build doesn't end a watch, it's fixed by the next change
+		pbw.failFast = !watchResources && c.BoolT("fail-fast")
 	}
 
 	if watchResources {
@@ -282,7 +315,7 @@ func specApply(c *cli.Context) error {
 
 	for {
 		// read all specs
-		fr, err := readSpecs(specDir)
+		fr, err := readAppSpecs(c, specDir)
 		util.CheckErr(err, "read specs")
 
 		// validate
@@ -299,7 +332,8 @@ func specApply(c *cli.Context) error {
 			pbw.addPackages(pkgMetas)
 		}
 
-		ctx, pkgWatchCancel := context.WithCancel(context.Background())
+		// Ctrl-C stops waiting for builds or watching files
+		ctx, pkgWatchCancel := context.WithCancel(interruptContext())
 
 		if watchResources {
 			// if we're watching for files, we don't need to wait for builds to complete
@@ -307,6 +341,20 @@ func specApply(c *cli.Context) error {
 		} else if waitForBuild {
 			// synchronously wait for build if --wait was specified
 			pbw.watch(ctx)
+
+			report := pbw.report()
+			if file := c.String("build-report"); len(file) > 0 {
+				err = report.writeReport(file)
+				util.CheckErr(err, "write build report")
+			}
+			if len(report.Failed) > 0 {
+				pkgWatchCancel()
+				log.Fatal(fmt.Sprintf("Package builds failed: %v", strings.Join(report.Failed, ", ")))
+			}
+			if interruptContext().Err() != nil {
+				pkgWatchCancel()
+				log.Fatal(fmt.Sprintf("Interrupted while waiting for package builds, still running in the cluster: %v", strings.Join(report.Pending, ", ")))
+			}
 		}
 
 		if !watchResources {
@@ -337,6 +385,10 @@ func specApply(c *cli.Context) error {
 				break waitloop
 			case err := <-watcher.Errors:
 				util.CheckErr(err, "watching files")
+			case <-interruptContext().Done():
+				pkgWatchCancel()
+				fmt.Println("Stopped watching files")
+				return nil
 			}
 		}
 	}
@@ -410,7 +462,8 @@ func specDestroy(c *cli.Context) error {
 }
 
 // applyArchives figures out the set of archives that need to be uploaded, and uploads them.
-func applyArchives(fclient *client.Client, specDir string, fr *spec.FissionResources) error {
+// If dryRun is true, archives are not uploaded and packages reference them by checksum only.
+func applyArchives(fclient *client.Client, specDir string, fr *spec.FissionResources, dryRun bool) error {
 
 	// archive:// URL -> archive map.
 	archiveFiles := make(map[string]fv1.Archive)
@@ -419,8 +472,9 @@ func applyArchives(fclient *client.Client, specDir string, fr *spec.FissionResou
 	// point at archive URLs.
 
 	// create archives locally and calculate checksums
+	limit := literalSizeLimit(fclient)
 	for _, aus := range fr.ArchiveUploadSpecs {
-		ar, err := localArchiveFromSpec(specDir, &aus)
+		ar, err := localArchiveFromSpec(specDir, &aus, limit)
 		if err != nil {
 			return err
 		}
@@ -452,12 +506,15 @@ func applyArchives(fclient *client.Client, specDir string, fr *spec.FissionResou
 			fmt.Printf("archive %v exists, not uploading\n", name)
 			ar.URL = url
 			archiveFiles[name] = ar
+		} else if dryRun {
+			fmt.Printf("archive %v would be uploaded\n", name)
+			ar.URL = ""
+			archiveFiles[name] = ar
 		} else {
 			// doesn't exist, upload
 			fmt.Printf("uploading archive %v\n", name)
 			// ar.URL is actually a local filename at this stage
-			ctx := context.Background()
-			uploadedAr := uploadArchive(ctx, fclient, ar.URL)
+			uploadedAr := uploadArchive(interruptContext(), fclient, ar.URL)
 			archiveFiles[name] = *uploadedAr
 		}
 	}
@@ -486,7 +543,7 @@ func applyResources(fclient *client.Client, specDir string, fr *spec.FissionReso
 	applyStatus := make(map[string]spec.ResourceApplyStatus)
 
 	// upload archives that need to be uploaded. Changes archive references in fr.Packages.
-	err := applyArchives(fclient, specDir, fr)
+	err := applyArchives(fclient, specDir, fr, false)
 	if err != nil {
 		return nil, nil, err
 	}
@@ -507,6 +564,10 @@ func applyResources(fclient *client.Client, specDir string, fr *spec.FissionReso
 	// of the package. This ensures that various caches can invalidate themselves
 	// when the package changes.
 	for i, f := range fr.Functions {
+		if f.Spec.InvokeStrategy.ExecutionStrategy.ExecutorType == fv1.ExecutorTypeContainer || f.Spec.PrebuiltImage != nil {
+			// container functions and functions with a prebuilt image have no package
+			continue
+		}
 		k := mapKey(&metav1.ObjectMeta{
 			Namespace: f.Spec.Package.PackageRef.Namespace,
 			Name:      f.Spec.Package.PackageRef.Name,
@@ -557,8 +618,9 @@ func applyResources(fclient *client.Client, specDir string, fr *spec.FissionReso
 }
 
 // localArchiveFromSpec creates an archive on the local filesystem from the given spec,
-// and returns its path and checksum.
-func localArchiveFromSpec(specDir string, aus *spec.ArchiveUploadSpec) (*fv1.Archive, error) {
+// and returns its path and checksum. Archives smaller than literalLimit are
+// returned as literals.
+func localArchiveFromSpec(specDir string, aus *spec.ArchiveUploadSpec, literalLimit int64) (*fv1.Archive, error) {
 	// get root dir
 	var rootDir string
 	if len(aus.RootDir) == 0 {
@@ -621,29 +683,33 @@ func localArchiveFromSpec(specDir string, aus *spec.ArchiveUploadSpec) (*fv1.Arc
 	}
 
 	// figure out if we're making a literal or a URL-based archive
-	if fileSize(archiveFileName) < types.ArchiveLiteralSizeLimit {
-		contents := getContents(archiveFileName)
-		return &fv1.Archive{
-			Type:    fv1.ArchiveTypeLiteral,
-			Literal: contents,
-		}, nil
-	} else {
-		// checksum
-		csum, err := fileChecksum(archiveFileName)
+	if fileSize(archiveFileName) < literalLimit {
+		contents, isLiteral, err := readLiteral(archiveFileName, literalLimit)
 		if err != nil {
-			return nil, fmt.Errorf("failed to calculate archive checksum for %v (%v): %v", aus.Name, archiveFileName, err)
+			return nil, err
 		}
+		if isLiteral {
+			return &fv1.Archive{
+				Type:    fv1.ArchiveTypeLiteral,
+				Literal: contents,
+			}, nil
+		}
+	}
 
-		// archive object
-		return &fv1.Archive{
-			Type: fv1.ArchiveTypeUrl,
-			// we should be actually be adding a "file://" prefix, but this archive is only an
-			// intermediate step, so just the path works fine.
-			URL:      archiveFileName,
-			Checksum: *csum,
-		}, nil
-
+	// checksum
+	csum, err := fileChecksum(archiveFileName)
+	if err != nil {
+		return nil, fmt.Errorf("failed to calculate archive checksum for %v (%v): %v", aus.Name, archiveFileName, err)
 	}
+
+	// archive object
+	return &fv1.Archive{
+		Type: fv1.ArchiveTypeUrl,
+		// we should be actually be adding a "file://" prefix, but this archive is only an
+		// intermediate step, so just the path works fine.
+		URL:      archiveFileName,
+		Checksum: *csum,
+	}, nil
 }
 
 // specHelm creates a helm chart from a spec directory and a
@@ -664,6 +730,22 @@ func applyDeploymentConfig(m *metav1.ObjectMeta, fr *spec.FissionResources) {
 	m.Annotations[spec.FISSION_DEPLOYMENT_UID_KEY] = fr.DeploymentConfig.UID
 }
 
+// applySpecChecksum records the checksum of the applied spec on the
+// resource, so that spec drift can find changes made outside of the specs.
+func applySpecChecksum(m *metav1.ObjectMeta, objSpec interface{}) {
+	if m.Annotations == nil {
+		m.Annotations = make(map[string]string)
+	}
+	m.Annotations[spec.FISSION_SPEC_CHECKSUM_KEY] = specChecksum(objSpec)
+}
+
+// hasSpecChecksum returns true if the existing resource records the spec
+// checksum of the desired one.
+func hasSpecChecksum(existing *metav1.ObjectMeta, desired *metav1.ObjectMeta) bool {
+	checksum, ok := existing.Annotations[spec.FISSION_SPEC_CHECKSUM_KEY]
+	return ok && checksum == desired.Annotations[spec.FISSION_SPEC_CHECKSUM_KEY]
+}
+
 func hasDeploymentConfig(m *metav1.ObjectMeta, fr *spec.FissionResources) bool {
 	if m.Annotations == nil {
 		return false
@@ -686,7 +768,11 @@ func waitForPackageBuild(fclient *client.Client, pkg *fv1.Package) (*fv1.Package
 		}
 
 		// TODO watch instead
-		time.Sleep(time.Second)
+		select {
+		case <-interruptContext().Done():
+			return nil, fmt.Errorf("interrupted while waiting for the build of package %v", pkg.Metadata.Name)
+		case <-time.After(time.Second):
+		}
 
 		var err error
 		pkg, err = fclient.PackageGet(&pkg.Metadata)
@@ -696,6 +782,19 @@ func waitForPackageBuild(fclient *client.Client, pkg *fv1.Package) (*fv1.Package
 	}
 }
 
+// keepPackage returns true if the existing package doesn't need to be updated
+// to the desired one: either the spec is the same, or the package is built from
+// the same source with the same environment and build command.
+func keepPackage(existingObj *fv1.Package, o *fv1.Package) bool {
+	if reflect.DeepEqual(existingObj.Spec, o.Spec) {
+		return true
+	}
+	return reflect.DeepEqual(existingObj.Spec.Environment, o.Spec.Environment) &&
+		!reflect.DeepEqual(existingObj.Spec.Source, fv1.Archive{}) &&
+		reflect.DeepEqual(existingObj.Spec.Source, o.Spec.Source) &&
+		existingObj.Spec.BuildCommand == o.Spec.BuildCommand
+}
+
 func applyPackages(fclient *client.Client, fr *spec.FissionResources, delete bool) (map[string]metav1.ObjectMeta, *spec.ResourceApplyStatus, error) {
 	// get list
 	allObjs, err := fclient.PackageList(metav1.NamespaceAll)
@@ -727,6 +826,7 @@ func applyPackages(fclient *client.Client, fr *spec.FissionResources, delete boo
 	for _, o := range fr.Packages {
 		// apply deploymentConfig so we can find our objects on future apply invocations
 		applyDeploymentConfig(&o.Metadata, fr)
+		applySpecChecksum(&o.Metadata, o.Spec)
 
 		// index desired state
 		desired[mapKey(&o.Metadata)] = true
@@ -735,20 +835,23 @@ func applyPackages(fclient *client.Client, fr *spec.FissionResources, delete boo
 		existingObj, ok := existent[mapKey(&o.Metadata)]
 		if ok {
 			// ok, a resource with the same name exists, is it the same?
-			keep := false
-			if reflect.DeepEqual(existingObj.Spec, o.Spec) {
-				keep = true
-			} else if reflect.DeepEqual(existingObj.Spec.Environment, o.Spec.Environment) &&
-				!reflect.DeepEqual(existingObj.Spec.Source, fv1.Archive{}) &&
-				reflect.DeepEqual(existingObj.Spec.Source, o.Spec.Source) &&
-				existingObj.Spec.BuildCommand == o.Spec.BuildCommand {
-
-				keep = true
-			}
+			keep := keepPackage(&existingObj, &o)
 
 			if keep && existingObj.Status.BuildStatus == fv1.BuildStatusSucceeded {
-				// nothing to do on the server
-				metadataMap[mapKey(&o.Metadata)] = existingObj.Metadata
+				if hasSpecChecksum(&existingObj.Metadata, &o.Metadata) {
+					// nothing to do on the server
+					metadataMap[mapKey(&o.Metadata)] = existingObj.Metadata
+				} else {
+					// only record the spec checksum, updating the spec would
+					// drop the deployment archive of the build
+					existingObj.Metadata.Annotations[spec.FISSION_SPEC_CHECKSUM_KEY] = o.Metadata.Annotations[spec.FISSION_SPEC_CHECKSUM_KEY]
+					newmeta, err := fclient.PackageUpdate(&existingObj)
+					if err != nil {
+						return nil, nil, err
+					}
+					ras.Updated = append(ras.Updated, newmeta)
+					metadataMap[mapKey(&o.Metadata)] = *newmeta
+				}
 			} else {
 				// update
 				o.Metadata.ResourceVersion = existingObj.Metadata.ResourceVersion
@@ -838,6 +941,7 @@ func applyFunctions(fclient *client.Client, fr *spec.FissionResources, delete bo
 	for _, o := range fr.Functions {
 		// apply deploymentConfig so we can find our objects on future apply invocations
 		applyDeploymentConfig(&o.Metadata, fr)
+		applySpecChecksum(&o.Metadata, o.Spec)
 
 		// index desired state
 		desired[mapKey(&o.Metadata)] = true
@@ -846,7 +950,7 @@ func applyFunctions(fclient *client.Client, fr *spec.FissionResources, delete bo
 		existingObj, ok := existent[mapKey(&o.Metadata)]
 		if ok {
 			// ok, a resource with the same name exists, is it the same?
-			if reflect.DeepEqual(existingObj.Spec, o.Spec) {
+			if reflect.DeepEqual(existingObj.Spec, o.Spec) && hasSpecChecksum(&existingObj.Metadata, &o.Metadata) {
 				// nothing to do on the server
 				metadataMap[mapKey(&o.Metadata)] = existingObj.Metadata
 			} else {
@@ -921,6 +1025,7 @@ func applyEnvironments(fclient *client.Client, fr *spec.FissionResources, delete
 	for _, o := range fr.Environments {
 		// apply deploymentConfig so we can find our objects on future apply invocations
 		applyDeploymentConfig(&o.Metadata, fr)
+		applySpecChecksum(&o.Metadata, o.Spec)
 
 		// index desired state
 		desired[mapKey(&o.Metadata)] = true
@@ -929,7 +1034,7 @@ func applyEnvironments(fclient *client.Client, fr *spec.FissionResources, delete
 		existingObj, ok := existent[mapKey(&o.Metadata)]
 		if ok {
 			// ok, a resource with the same name exists, is it the same?
-			if reflect.DeepEqual(existingObj.Spec, o.Spec) {
+			if reflect.DeepEqual(existingObj.Spec, o.Spec) && hasSpecChecksum(&existingObj.Metadata, &o.Metadata) {
 				// nothing to do on the server
 				metadataMap[mapKey(&o.Metadata)] = existingObj.Metadata
 			} else {
@@ -1004,6 +1109,7 @@ func applyHTTPTriggers(fclient *client.Client, fr *spec.FissionResources, delete
 	for _, o := range fr.HttpTriggers {
 		// apply deploymentConfig so we can find our objects on future apply invocations
 		applyDeploymentConfig(&o.Metadata, fr)
+		applySpecChecksum(&o.Metadata, o.Spec)
 
 		// index desired state
 		desired[mapKey(&o.Metadata)] = true
@@ -1012,7 +1118,7 @@ func applyHTTPTriggers(fclient *client.Client, fr *spec.FissionResources, delete
 		existingObj, ok := existent[mapKey(&o.Metadata)]
 		if ok {
 			// ok, a resource with the same name exists, is it the same?
-			if reflect.DeepEqual(existingObj.Spec, o.Spec) {
+			if reflect.DeepEqual(existingObj.Spec, o.Spec) && hasSpecChecksum(&existingObj.Metadata, &o.Metadata) {
 				// nothing to do on the server
 				metadataMap[mapKey(&o.Metadata)] = existingObj.Metadata
 			} else {
@@ -1087,6 +1193,7 @@ func applyKubernetesWatchTriggers(fclient *client.Client, fr *spec.FissionResour
 	for _, o := range fr.KubernetesWatchTriggers {
 		// apply deploymentConfig so we can find our objects on future apply invocations
 		applyDeploymentConfig(&o.Metadata, fr)
+		applySpecChecksum(&o.Metadata, o.Spec)
 
 		// index desired state
 		desired[mapKey(&o.Metadata)] = true
@@ -1095,7 +1202,7 @@ func applyKubernetesWatchTriggers(fclient *client.Client, fr *spec.FissionResour
 		existingObj, ok := existent[mapKey(&o.Metadata)]
 		if ok {
 			// ok, a resource with the same name exists, is it the same?
-			if reflect.DeepEqual(existingObj.Spec, o.Spec) {
+			if reflect.DeepEqual(existingObj.Spec, o.Spec) && hasSpecChecksum(&existingObj.Metadata, &o.Metadata) {
 				// nothing to do on the server
 				metadataMap[mapKey(&o.Metadata)] = existingObj.Metadata
 			} else {
@@ -1170,6 +1277,7 @@ func applyTimeTriggers(fclient *client.Client, fr *spec.FissionResources, delete
 	for _, o := range fr.TimeTriggers {
 		// apply deploymentConfig so we can find our objects on future apply invocations
 		applyDeploymentConfig(&o.Metadata, fr)
+		applySpecChecksum(&o.Metadata, o.Spec)
 
 		// index desired state
 		desired[mapKey(&o.Metadata)] = true
@@ -1178,7 +1286,7 @@ func applyTimeTriggers(fclient *client.Client, fr *spec.FissionResources, delete
 		existingObj, ok := existent[mapKey(&o.Metadata)]
 		if ok {
 			// ok, a resource with the same name exists, is it the same?
-			if reflect.DeepEqual(existingObj.Spec, o.Spec) {
+			if reflect.DeepEqual(existingObj.Spec, o.Spec) && hasSpecChecksum(&existingObj.Metadata, &o.Metadata) {
 				// nothing to do on the server
 				metadataMap[mapKey(&o.Metadata)] = existingObj.Metadata
 			} else {
@@ -1253,6 +1361,7 @@ func applyMessageQueueTriggers(fclient *client.Client, fr *spec.FissionResources
 	for _, o := range fr.MessageQueueTriggers {
 		// apply deploymentConfig so we can find our objects on future apply invocations
 		applyDeploymentConfig(&o.Metadata, fr)
+		applySpecChecksum(&o.Metadata, o.Spec)
 
 		// index desired state
 		desired[mapKey(&o.Metadata)] = true
@@ -1261,7 +1370,7 @@ func applyMessageQueueTriggers(fclient *client.Client, fr *spec.FissionResources
 		existingObj, ok := existent[mapKey(&o.Metadata)]
 		if ok {
 			// ok, a resource with the same name exists, is it the same?
-			if reflect.DeepEqual(existingObj.Spec, o.Spec) {
+			if reflect.DeepEqual(existingObj.Spec, o.Spec) && hasSpecChecksum(&existingObj.Metadata, &o.Metadata) {
 				// nothing to do on the server
 				metadataMap[mapKey(&o.Metadata)] = existingObj.Metadata
 			} else {
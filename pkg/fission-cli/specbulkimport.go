@@ -0,0 +1,388 @@
+/*
+Copyright 2019 The Fission Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package fission_cli
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/ghodss/yaml"
+	multierror "github.com/hashicorp/go-multierror"
+	"github.com/pkg/errors"
+	"github.com/urfave/cli"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	fv1 "github.com/fission/fission/pkg/apis/fission.io/v1"
+	"github.com/fission/fission/pkg/fission-cli/cliwrapper/driver/urfavecli"
+	"github.com/fission/fission/pkg/fission-cli/cmd"
+	"github.com/fission/fission/pkg/fission-cli/cmd/spec"
+	"github.com/fission/fission/pkg/fission-cli/log"
+	"github.com/fission/fission/pkg/fission-cli/util"
+	"github.com/fission/fission/pkg/types"
+	"github.com/fission/fission/pkg/utils"
+)
+
+type (
+	// bulkImportManifest is the list of functions to generate specs for.
+	bulkImportManifest struct {
+		Functions []bulkImportFunction `json:"functions"`
+	}
+
+	// bulkImportFunction is a function of the manifest. The paths are
+	// relative to the current directory, like the ones of --src and
+	// --deploy of "fission fn create".
+	bulkImportFunction struct {
+		Name       string     `json:"name"`
+		Namespace  string     `json:"namespace,omitempty"`
+		Env        string     `json:"env"`
+		Src        stringList `json:"src,omitempty"`
+		Deploy     stringList `json:"deploy,omitempty"`
+		Entrypoint string     `json:"entrypoint,omitempty"`
+		BuildCmd   string     `json:"buildcmd,omitempty"`
+		Route      string     `json:"route,omitempty"`
+		Method     stringList `json:"method,omitempty"`
+	}
+
+	// stringList is a list that may also be given as a single string.
+	stringList []string
+)
+
+func (l *stringList) UnmarshalJSON(b []byte) error {
+	var s string
+	if err := json.Unmarshal(b, &s); err == nil {
+		*l = stringList{s}
+		return nil
+	}
+	var list []string
+	if err := json.Unmarshal(b, &list); err != nil {
+		return err
+	}
+	*l = list
+	return nil
+}
+
+// specBulkImport generates the archive, package, function and HTTP trigger
+// specs of the functions of a YAML or CSV manifest, for migrating many
+// existing functions at once. Functions already in the specs are skipped,
+// so that the manifest can be imported again after adding functions.
+func specBulkImport(c *cli.Context) error {
+	manifestFile := c.Args().First()
+	if len(manifestFile) == 0 {
+		log.Fatal("Need the manifest file, e.g. \"fission spec bulk-import functions.yaml\"")
+	}
+	specDir := cmd.GetSpecDir(urfavecli.Parse(c))
+
+	manifest, err := readBulkImportManifest(manifestFile)
+	util.CheckErr(err, "read manifest")
+
+	fr, err := readSpecs(specDir)
+	util.CheckErr(err, "read specs")
+	existing := make(map[string]bool)
+	for _, fn := range fr.Functions {
+		existing[mapKey(&fn.Metadata)] = true
+	}
+	for _, ht := range fr.HttpTriggers {
+		existing["HTTPTrigger/"+mapKey(&ht.Metadata)] = true
+	}
+
+	defaultNamespace := c.String("fnNamespace")
+	imported, skipped := 0, 0
+	for i := range manifest.Functions {
+		f := &manifest.Functions[i]
+		if len(f.Namespace) == 0 {
+			f.Namespace = defaultNamespace
+		}
+
+		fnMeta := &metav1.ObjectMeta{Name: f.Name, Namespace: f.Namespace}
+		if existing[mapKey(fnMeta)] {
+			fmt.Printf("Skipping function %v, it already exists in the specs\n", f.Name)
+			skipped++
+			continue
+		}
+
+		resources, err := bulkImportResources(f)
+		util.CheckErr(err, fmt.Sprintf("generate specs of function %v", f.Name))
+		for _, r := range resources {
+			if ht, ok := r.(fv1.HTTPTrigger); ok && existing["HTTPTrigger/"+mapKey(&ht.Metadata)] {
+				log.Fatal(fmt.Sprintf("An HTTP trigger named '%v' already exists in the specs", ht.Metadata.Name))
+			}
+		}
+
+		specFile := fmt.Sprintf("function-%v.yaml", f.Name)
+		for _, r := range resources {
+			err = spec.SpecSaveInDir(r, specDir, specFile)
+			util.CheckErr(err, fmt.Sprintf("write spec file %v", specFile))
+		}
+		imported++
+	}
+
+	// make sure the spec directory can be applied as it is
+	fr, err = readSpecs(specDir)
+	util.CheckErr(err, "read specs")
+	err = fr.Validate(c)
+	util.CheckErr(err, "validate specs")
+
+	fmt.Printf("Imported %v, skipped %v\n", pluralize(imported, "function"), pluralize(skipped, "function"))
+	return nil
+}
+
+// readBulkImportManifest reads a YAML manifest, or a CSV one if the file
+// name ends with .csv, and checks the functions of it.
+func readBulkImportManifest(manifestFile string) (*bulkImportManifest, error) {
+	f, err := os.Open(manifestFile)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var manifest *bulkImportManifest
+	if strings.EqualFold(filepath.Ext(manifestFile), ".csv") {
+		manifest, err = parseBulkImportCSV(f)
+	} else {
+		manifest, err = parseBulkImportYAML(f)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	result := &multierror.Error{}
+	names := make(map[string]bool)
+	for i, fn := range manifest.Functions {
+		if len(fn.Name) == 0 {
+			result = multierror.Append(result, fmt.Errorf("function %v has no name", i+1))
+			continue
+		}
+		if names[fn.Namespace+"/"+fn.Name] {
+			result = multierror.Append(result, fmt.Errorf("function %v is in the manifest more than once", fn.Name))
+		}
+		names[fn.Namespace+"/"+fn.Name] = true
+		if len(fn.Env) == 0 {
+			result = multierror.Append(result, fmt.Errorf("function %v has no env", fn.Name))
+		}
+		if len(fn.Src) == 0 && len(fn.Deploy) == 0 {
+			result = multierror.Append(result, fmt.Errorf("function %v has neither src nor deploy", fn.Name))
+		}
+	}
+	if err := result.ErrorOrNil(); err != nil {
+		return nil, err
+	}
+	return manifest, nil
+}
+
+// parseBulkImportYAML parses a manifest with a list of functions under the
+// "functions" key.
+func parseBulkImportYAML(r io.Reader) (*bulkImportManifest, error) {
+	b, err := ioutil.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	var manifest bulkImportManifest
+	err = yaml.Unmarshal(b, &manifest)
+	if err != nil {
+		return nil, errors.Wrap(err, "error parsing manifest")
+	}
+	return &manifest, nil
+}
+
+// parseBulkImportCSV parses a manifest with a header row naming the
+// columns, e.g. "name,env,src,route". Lists, like several src paths, are
+// separated with semicolons.
+func parseBulkImportCSV(r io.Reader) (*bulkImportManifest, error) {
+	reader := csv.NewReader(r)
+	reader.TrimLeadingSpace = true
+	reader.Comment = '#'
+	records, err := reader.ReadAll()
+	if err != nil {
+		return nil, errors.Wrap(err, "error parsing manifest")
+	}
+	if len(records) == 0 {
+		return &bulkImportManifest{}, nil
+	}
+
+	header := records[0]
+	manifest := &bulkImportManifest{}
+	for _, record := range records[1:] {
+		// reuse the field names of the YAML manifest
+		fields := make(map[string]interface{})
+		for i, column := range header {
+			column = strings.ToLower(strings.TrimSpace(column))
+			value := strings.TrimSpace(record[i])
+			if len(value) == 0 {
+				continue
+			}
+			switch column {
+			case "src", "deploy", "method":
+				var list []string
+				for _, v := range strings.Split(value, ";") {
+					if v = strings.TrimSpace(v); len(v) > 0 {
+						list = append(list, v)
+					}
+				}
+				fields[column] = list
+			default:
+				fields[column] = value
+			}
+		}
+
+		b, err := json.Marshal(fields)
+		if err != nil {
+			return nil, err
+		}
+		var fn bulkImportFunction
+		err = json.Unmarshal(b, &fn)
+		if err != nil {
+			return nil, errors.Wrap(err, "error parsing manifest")
+		}
+		manifest.Functions = append(manifest.Functions, fn)
+	}
+	return manifest, nil
+}
+
+// bulkImportResources returns the specs of a function of the manifest. The
+// names are derived from the function name, so that importing the same
+// manifest into another spec directory gives the same specs.
+func bulkImportResources(f *bulkImportFunction) ([]interface{}, error) {
+	var resources []interface{}
+
+	pkg := fv1.Package{
+		Metadata: metav1.ObjectMeta{
+			Name:      util.KubifyName(f.Name + "-pkg"),
+			Namespace: f.Namespace,
+		},
+		Spec: fv1.PackageSpec{
+			Environment: fv1.EnvironmentReference{
+				Name:      f.Env,
+				Namespace: f.Namespace,
+			},
+			BuildCommand: f.BuildCmd,
+		},
+		Status: fv1.PackageStatus{
+			BuildStatus:         fv1.BuildStatusNone,
+			LastUpdateTimestamp: time.Now().UTC(),
+		},
+	}
+
+	for _, archive := range []struct {
+		suffix string
+		globs  []string
+		ar     *fv1.Archive
+	}{
+		{"deploy", f.Deploy, &pkg.Spec.Deployment},
+		{"src", f.Src, &pkg.Spec.Source},
+	} {
+		if len(archive.globs) == 0 {
+			continue
+		}
+		for _, glob := range archive.globs {
+			if isHTTPURL(glob) {
+				return nil, fmt.Errorf("URL %v can't be imported, use \"fission fn create --spec\" for remote archives", glob)
+			}
+			files, err := utils.FindAllGlobs([]string{glob})
+			if err != nil {
+				return nil, err
+			}
+			if len(files) == 0 {
+				return nil, fmt.Errorf("error finding any files with path \"%v\"", glob)
+			}
+		}
+
+		aus := spec.ArchiveUploadSpec{
+			Name:         util.KubifyName(f.Name + "-" + archive.suffix),
+			IncludeGlobs: archive.globs,
+		}
+		resources = append(resources, aus)
+		*archive.ar = fv1.Archive{
+			Type: fv1.ArchiveTypeUrl,
+			URL:  spec.ARCHIVE_URL_PREFIX + aus.Name,
+		}
+	}
+	if len(f.Src) > 0 {
+		pkg.Status.BuildStatus = fv1.BuildStatusPending
+	}
+	resources = append(resources, pkg)
+
+	fn := fv1.Function{
+		Metadata: metav1.ObjectMeta{
+			Name:      f.Name,
+			Namespace: f.Namespace,
+		},
+		Spec: fv1.FunctionSpec{
+			Environment: fv1.EnvironmentReference{
+				Name:      f.Env,
+				Namespace: f.Namespace,
+			},
+			Package: fv1.FunctionPackageRef{
+				FunctionName: f.Entrypoint,
+				PackageRef: fv1.PackageRef{
+					Name:      pkg.Metadata.Name,
+					Namespace: pkg.Metadata.Namespace,
+				},
+			},
+			InvokeStrategy: fv1.InvokeStrategy{
+				StrategyType: fv1.StrategyTypeExecution,
+				ExecutionStrategy: fv1.ExecutionStrategy{
+					ExecutorType: types.ExecutorTypePoolmgr,
+				},
+			},
+			FunctionTimeout: 60,
+		},
+	}
+	resources = append(resources, fn)
+
+	if len(f.Route) > 0 {
+		url := f.Route
+		if !strings.HasPrefix(url, "/") {
+			url = "/" + url
+		}
+		var methods []string
+		for _, m := range f.Method {
+			if m == fv1.HTTPMethodAny {
+				methods = append(methods, m)
+			} else {
+				methods = append(methods, getMethod(m))
+			}
+		}
+		if len(methods) == 0 {
+			methods = []string{http.MethodGet}
+		}
+
+		ht := fv1.HTTPTrigger{
+			Metadata: metav1.ObjectMeta{
+				Name:      f.Name,
+				Namespace: f.Namespace,
+			},
+			Spec: fv1.HTTPTriggerSpec{
+				RelativeURL: url,
+				FunctionReference: fv1.FunctionReference{
+					Type: fv1.FunctionReferenceTypeFunctionName,
+					Name: f.Name,
+				},
+			},
+		}
+		setMethods(&ht.Spec, methods)
+		resources = append(resources, ht)
+	}
+	return resources, nil
+}
@@ -0,0 +1,80 @@
+package fission_cli
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	fv1 "github.com/fission/fission/pkg/apis/fission.io/v1"
+	"github.com/fission/fission/pkg/fission-cli/cmd/spec"
+)
+
+func TestParseBulkImportManifest(t *testing.T) {
+	m, err := parseBulkImportYAML(strings.NewReader(`functions:
+- name: hello
+  env: nodejs
+  deploy: scripts/hello.js
+  route: /hello
+- name: report
+  env: python
+  src: [reports/*.py, reports/requirements.txt]
+  method: [GET, POST]
+`))
+	assert.Nil(t, err)
+	assert.Len(t, m.Functions, 2)
+	assert.Equal(t, stringList{"scripts/hello.js"}, m.Functions[0].Deploy)
+	assert.Equal(t, stringList{"reports/*.py", "reports/requirements.txt"}, m.Functions[1].Src)
+
+	m, err = parseBulkImportCSV(strings.NewReader(`name,env,src,route,method
+# migrated from cron
+hello,nodejs,,/hello,
+report,python,reports/*.py;reports/requirements.txt,/report,GET;POST
+`))
+	assert.Nil(t, err)
+	assert.Len(t, m.Functions, 2)
+	assert.Equal(t, "hello", m.Functions[0].Name)
+	assert.Equal(t, "/hello", m.Functions[0].Route)
+	assert.Empty(t, m.Functions[0].Src)
+	assert.Equal(t, stringList{"reports/*.py", "reports/requirements.txt"}, m.Functions[1].Src)
+	assert.Equal(t, stringList{"GET", "POST"}, m.Functions[1].Method)
+}
+
+func TestBulkImportResources(t *testing.T) {
+	dir, err := ioutil.TempDir("", "fission-bulk-import")
+	assert.Nil(t, err)
+	defer os.RemoveAll(dir)
+	script := filepath.Join(dir, "hello.js")
+	assert.Nil(t, ioutil.WriteFile(script, []byte("module.exports = async () => ({body: 'hello'})"), 0644))
+
+	resources, err := bulkImportResources(&bulkImportFunction{
+		Name:      "hello",
+		Namespace: "default",
+		Env:       "nodejs",
+		Deploy:    stringList{script},
+		Route:     "hello",
+	})
+	assert.Nil(t, err)
+	assert.Len(t, resources, 4)
+
+	aus := resources[0].(spec.ArchiveUploadSpec)
+	assert.Equal(t, "hello-deploy", aus.Name)
+	pkg := resources[1].(fv1.Package)
+	assert.Equal(t, "archive://hello-deploy", pkg.Spec.Deployment.URL)
+	assert.Equal(t, fv1.BuildStatus(fv1.BuildStatusNone), pkg.Status.BuildStatus)
+	fn := resources[2].(fv1.Function)
+	assert.Equal(t, pkg.Metadata.Name, fn.Spec.Package.PackageRef.Name)
+	ht := resources[3].(fv1.HTTPTrigger)
+	assert.Equal(t, "/hello", ht.Spec.RelativeURL)
+	assert.Equal(t, "GET", ht.Spec.Method)
+
+	_, err = bulkImportResources(&bulkImportFunction{
+		Name: "missing",
+		Env:  "nodejs",
+		Src:  stringList{filepath.Join(dir, "missing", "*.js")},
+	})
+	assert.NotNil(t, err)
+}
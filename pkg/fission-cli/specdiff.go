@@ -0,0 +1,481 @@
+/*
+Copyright 2019 The Fission Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package fission_cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"reflect"
+	"sort"
+	"text/tabwriter"
+
+	"github.com/pkg/errors"
+	"github.com/urfave/cli"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	fv1 "github.com/fission/fission/pkg/apis/fission.io/v1"
+	"github.com/fission/fission/pkg/controller/client"
+	"github.com/fission/fission/pkg/fission-cli/cliwrapper/driver/urfavecli"
+	"github.com/fission/fission/pkg/fission-cli/cmd"
+	"github.com/fission/fission/pkg/fission-cli/cmd/spec"
+	"github.com/fission/fission/pkg/fission-cli/util"
+)
+
+const (
+	diffActionCreate    = "create"
+	diffActionUpdate    = "update"
+	diffActionDelete    = "delete"
+	diffActionUnchanged = "unchanged"
+
+	// placeholder of the resource version of a package that is going to be
+	// created or updated, since it's only known after the package is applied.
+	pendingResourceVersion = "<pending>"
+)
+
+type (
+	// resourceDiff is the change spec apply makes to a single resource.
+	resourceDiff struct {
+		kind    string
+		meta    *metav1.ObjectMeta
+		action  string
+		note    string
+		changes []fieldChange
+	}
+
+	// fieldChange is the change of a single field of a resource spec.
+	fieldChange struct {
+		path     string
+		oldValue interface{}
+		newValue interface{}
+	}
+
+	// specObject is the metadata and spec of a resource of any kind.
+	specObject struct {
+		meta *metav1.ObjectMeta
+		spec interface{}
+		// note is set if the resource is going to be updated even if the spec doesn't change
+		note string
+	}
+)
+
+// specDiff prints the differences between the local specs and the live
+// objects on the cluster, including resources deleted from the specs. It
+// exits with status 1 if there are differences, so it can be used to gate
+// changes in CI.
+func specDiff(c *cli.Context) error {
+	specDir := cmd.GetSpecDir(urfavecli.Parse(c))
+	fclient := util.GetApiClient(c.GlobalString("server"))
+
+	fr, err := readAppSpecs(c, specDir)
+	util.CheckErr(err, "read specs")
+
+	err = fr.Validate(c)
+	util.CheckErr(err, "validate specs")
+
+	diffs, err := diffResources(fclient, specDir, fr, true)
+	util.CheckErr(err, "compute spec diff")
+
+	if printResourceDiffs(diffs, "Diff") > 0 {
+		os.Exit(1)
+	}
+	return nil
+}
+
+// specList lists the resources defined in the specs along with their
+// deployment status on the cluster.
+func specList(c *cli.Context) error {
+	specDir := cmd.GetSpecDir(urfavecli.Parse(c))
+	fclient := util.GetApiClient(c.GlobalString("server"))
+
+	fr, err := readAppSpecs(c, specDir)
+	util.CheckErr(err, "read specs")
+
+	diffs, err := diffResources(fclient, specDir, fr, true)
+	util.CheckErr(err, "get deployment status")
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 1, ' ', 0)
+	fmt.Fprintf(w, "%v\t%v\t%v\t%v\n", "KIND", "NAME", "NAMESPACE", "STATUS")
+	for _, d := range diffs {
+		fmt.Fprintf(w, "%v\t%v\t%v\t%v\n", d.kind, d.meta.Name, d.meta.Namespace, deploymentStatus(d))
+	}
+	w.Flush()
+	return nil
+}
+
+// deploymentStatus describes how a resource on the cluster relates to the specs.
+func deploymentStatus(d resourceDiff) string {
+	switch d.action {
+	case diffActionCreate:
+		return "not deployed"
+	case diffActionUpdate:
+		return "modified"
+	case diffActionDelete:
+		return "removed from specs"
+	default:
+		return "deployed"
+	}
+}
+
+// diffResources computes the changes that applyResources would make to the
+// cluster, without changing anything. Archives are not uploaded either.
+func diffResources(fclient *client.Client, specDir string, fr *spec.FissionResources, delete bool) ([]resourceDiff, error) {
+	err := applyArchives(fclient, specDir, fr, true)
+	if err != nil {
+		return nil, err
+	}
+
+	var diffs []resourceDiff
+
+	allEnvs, err := fclient.EnvironmentList(metav1.NamespaceAll)
+	if err != nil {
+		return nil, errors.Wrap(err, "error listing environments")
+	}
+	var existing, desired []specObject
+	for i := range allEnvs {
+		existing = append(existing, specObject{meta: &allEnvs[i].Metadata, spec: allEnvs[i].Spec})
+	}
+	for i := range fr.Environments {
+		desired = append(desired, specObject{meta: &fr.Environments[i].Metadata, spec: fr.Environments[i].Spec})
+	}
+	diffs = append(diffs, diffSpecObjects("environment", fr, existing, desired, delete)...)
+
+	allPkgs, err := fclient.PackageList(metav1.NamespaceAll)
+	if err != nil {
+		return nil, errors.Wrap(err, "error listing packages")
+	}
+	existingPkgs := make(map[string]fv1.Package)
+	existing, desired = nil, nil
+	for i := range allPkgs {
+		existing = append(existing, specObject{meta: &allPkgs[i].Metadata, spec: allPkgs[i].Spec})
+		if hasDeploymentConfig(&allPkgs[i].Metadata, fr) {
+			existingPkgs[mapKey(&allPkgs[i].Metadata)] = allPkgs[i]
+		}
+	}
+	for i := range fr.Packages {
+		o := specObject{meta: &fr.Packages[i].Metadata, spec: fr.Packages[i].Spec}
+		// same as applyPackages, a package whose source doesn't change is
+		// not updated unless its last build failed.
+		if existingObj, ok := existingPkgs[mapKey(o.meta)]; ok {
+			if keepPackage(&existingObj, &fr.Packages[i]) {
+				if existingObj.Status.BuildStatus == fv1.BuildStatusSucceeded {
+					o.spec = existingObj.Spec
+				} else {
+					o.note = fmt.Sprintf("rebuild, last build status is %v", existingObj.Status.BuildStatus)
+				}
+			}
+		}
+		desired = append(desired, o)
+	}
+	pkgDiffs := diffSpecObjects("package", fr, existing, desired, delete)
+	diffs = append(diffs, pkgDiffs...)
+
+	// Functions reference the resource version of their package, which
+	// changes if the package is created or updated.
+	specPkgs := make(map[string]bool)
+	for i := range fr.Packages {
+		specPkgs[mapKey(&fr.Packages[i].Metadata)] = true
+	}
+	changedPkgs := make(map[string]bool)
+	for _, d := range pkgDiffs {
+		if d.action == diffActionCreate || d.action == diffActionUpdate {
+			changedPkgs[mapKey(d.meta)] = true
+		}
+	}
+	allFns, err := fclient.FunctionList(metav1.NamespaceAll)
+	if err != nil {
+		return nil, errors.Wrap(err, "error listing functions")
+	}
+	existing, desired = nil, nil
+	for i := range allFns {
+		existing = append(existing, specObject{meta: &allFns[i].Metadata, spec: allFns[i].Spec})
+	}
+	for _, f := range fr.Functions {
+		ref := &f.Spec.Package.PackageRef
+		if f.Spec.InvokeStrategy.ExecutionStrategy.ExecutorType == fv1.ExecutorTypeContainer || f.Spec.PrebuiltImage != nil {
+			desired = append(desired, specObject{meta: f.Metadata.DeepCopy(), spec: f.Spec})
+			continue
+		}
+		k := mapKey(&metav1.ObjectMeta{Namespace: ref.Namespace, Name: ref.Name})
+		if !specPkgs[k] {
+			return nil, fmt.Errorf("function %v/%v references package %v/%v, which doesn't exist in the specs",
+				f.Metadata.Namespace, f.Metadata.Name, ref.Namespace, ref.Name)
+		}
+		if changedPkgs[k] {
+			ref.ResourceVersion = pendingResourceVersion
+		} else {
+			ref.ResourceVersion = existingPkgs[k].Metadata.ResourceVersion
+		}
+		desired = append(desired, specObject{meta: f.Metadata.DeepCopy(), spec: f.Spec})
+	}
+	diffs = append(diffs, diffSpecObjects("function", fr, existing, desired, delete)...)
+
+	allHTs, err := fclient.HTTPTriggerList(metav1.NamespaceAll)
+	if err != nil {
+		return nil, errors.Wrap(err, "error listing HTTP triggers")
+	}
+	existing, desired = nil, nil
+	for i := range allHTs {
+		existing = append(existing, specObject{meta: &allHTs[i].Metadata, spec: allHTs[i].Spec})
+	}
+	for i := range fr.HttpTriggers {
+		desired = append(desired, specObject{meta: &fr.HttpTriggers[i].Metadata, spec: fr.HttpTriggers[i].Spec})
+	}
+	diffs = append(diffs, diffSpecObjects("HTTPTrigger", fr, existing, desired, delete)...)
+
+	allWatches, err := fclient.WatchList(metav1.NamespaceAll)
+	if err != nil {
+		return nil, errors.Wrap(err, "error listing kubernetes watch triggers")
+	}
+	existing, desired = nil, nil
+	for i := range allWatches {
+		existing = append(existing, specObject{meta: &allWatches[i].Metadata, spec: allWatches[i].Spec})
+	}
+	for i := range fr.KubernetesWatchTriggers {
+		desired = append(desired, specObject{meta: &fr.KubernetesWatchTriggers[i].Metadata, spec: fr.KubernetesWatchTriggers[i].Spec})
+	}
+	diffs = append(diffs, diffSpecObjects("KubernetesWatchTrigger", fr, existing, desired, delete)...)
+
+	allTTs, err := fclient.TimeTriggerList(metav1.NamespaceAll)
+	if err != nil {
+		return nil, errors.Wrap(err, "error listing time triggers")
+	}
+	existing, desired = nil, nil
+	for i := range allTTs {
+		existing = append(existing, specObject{meta: &allTTs[i].Metadata, spec: allTTs[i].Spec})
+	}
+	for i := range fr.TimeTriggers {
+		desired = append(desired, specObject{meta: &fr.TimeTriggers[i].Metadata, spec: fr.TimeTriggers[i].Spec})
+	}
+	diffs = append(diffs, diffSpecObjects("TimeTrigger", fr, existing, desired, delete)...)
+
+	allMQTs, err := fclient.MessageQueueTriggerList("", metav1.NamespaceAll)
+	if err != nil {
+		return nil, errors.Wrap(err, "error listing message queue triggers")
+	}
+	existing, desired = nil, nil
+	for i := range allMQTs {
+		existing = append(existing, specObject{meta: &allMQTs[i].Metadata, spec: allMQTs[i].Spec})
+	}
+	for i := range fr.MessageQueueTriggers {
+		desired = append(desired, specObject{meta: &fr.MessageQueueTriggers[i].Metadata, spec: fr.MessageQueueTriggers[i].Spec})
+	}
+	diffs = append(diffs, diffSpecObjects("MessageQueueTrigger", fr, existing, desired, delete)...)
+
+	return diffs, nil
+}
+
+// diffSpecObjects compares the desired resources of a kind with the ones
+// created by the same deployment on the cluster.
+func diffSpecObjects(kind string, fr *spec.FissionResources, existing []specObject, desired []specObject, delete bool) []resourceDiff {
+	// filter and index, same as the apply functions
+	existent := make(map[string]specObject)
+	for _, o := range existing {
+		if hasDeploymentConfig(o.meta, fr) {
+			existent[mapKey(o.meta)] = o
+		}
+	}
+
+	var diffs []resourceDiff
+	wanted := make(map[string]bool)
+	for _, o := range desired {
+		k := mapKey(o.meta)
+		wanted[k] = true
+
+		existingObj, ok := existent[k]
+		if !ok {
+			diffs = append(diffs, resourceDiff{
+				kind:    kind,
+				meta:    o.meta,
+				action:  diffActionCreate,
+				changes: diffSpecs(nil, o.spec),
+			})
+			continue
+		}
+
+		d := resourceDiff{
+			kind:    kind,
+			meta:    existingObj.meta,
+			action:  diffActionUnchanged,
+			changes: diffSpecs(existingObj.spec, o.spec),
+		}
+		if len(d.changes) > 0 || len(o.note) > 0 {
+			d.action = diffActionUpdate
+			d.note = o.note
+		}
+		diffs = append(diffs, d)
+	}
+
+	if delete {
+		for _, o := range existing {
+			if !hasDeploymentConfig(o.meta, fr) || wanted[mapKey(o.meta)] {
+				continue
+			}
+			diffs = append(diffs, resourceDiff{
+				kind:    kind,
+				meta:    o.meta,
+				action:  diffActionDelete,
+				changes: diffSpecs(o.spec, nil),
+			})
+		}
+	}
+
+	return diffs
+}
+
+// diffSpecs returns the field level changes between two specs, compared
+// by their JSON representation so that paths match the spec files.
+func diffSpecs(oldSpec interface{}, newSpec interface{}) []fieldChange {
+	var changes []fieldChange
+	diffFields("", toJSONValue(oldSpec), toJSONValue(newSpec), &changes)
+	return changes
+}
+
+func toJSONValue(obj interface{}) interface{} {
+	if obj == nil {
+		return nil
+	}
+	b, err := json.Marshal(obj)
+	if err != nil {
+		return nil
+	}
+	var v interface{}
+	err = json.Unmarshal(b, &v)
+	if err != nil {
+		return nil
+	}
+	return v
+}
+
+func diffFields(path string, oldValue interface{}, newValue interface{}, changes *[]fieldChange) {
+	// list every field of created or deleted objects
+	if _, ok := newValue.(map[string]interface{}); ok && oldValue == nil {
+		oldValue = map[string]interface{}{}
+	}
+	if _, ok := oldValue.(map[string]interface{}); ok && newValue == nil {
+		newValue = map[string]interface{}{}
+	}
+
+	switch o := oldValue.(type) {
+	case map[string]interface{}:
+		n, ok := newValue.(map[string]interface{})
+		if !ok {
+			break
+		}
+		keys := make(map[string]bool)
+		for k := range o {
+			keys[k] = true
+		}
+		for k := range n {
+			keys[k] = true
+		}
+		sortedKeys := make([]string, 0, len(keys))
+		for k := range keys {
+			sortedKeys = append(sortedKeys, k)
+		}
+		sort.Strings(sortedKeys)
+		for _, k := range sortedKeys {
+			p := k
+			if len(path) > 0 {
+				p = path + "." + k
+			}
+			diffFields(p, o[k], n[k], changes)
+		}
+		return
+
+	case []interface{}:
+		n, ok := newValue.([]interface{})
+		if !ok {
+			break
+		}
+		for i := 0; i < len(o) || i < len(n); i++ {
+			var ov, nv interface{}
+			if i < len(o) {
+				ov = o[i]
+			}
+			if i < len(n) {
+				nv = n[i]
+			}
+			diffFields(fmt.Sprintf("%v[%v]", path, i), ov, nv, changes)
+		}
+		return
+	}
+
+	if !reflect.DeepEqual(oldValue, newValue) {
+		*changes = append(*changes, fieldChange{
+			path:     path,
+			oldValue: oldValue,
+			newValue: newValue,
+		})
+	}
+}
+
+// printResourceDiffs prints the changes spec apply would make to the
+// cluster, and returns the number of resources to change.
+func printResourceDiffs(diffs []resourceDiff, summary string) int {
+	counts := make(map[string]int)
+	for _, d := range diffs {
+		counts[d.action]++
+		if d.action == diffActionUnchanged {
+			continue
+		}
+
+		var sign string
+		switch d.action {
+		case diffActionCreate:
+			sign = "+"
+		case diffActionUpdate:
+			sign = "~"
+		case diffActionDelete:
+			sign = "-"
+		}
+		fmt.Printf("%v %v %v/%v (%v)\n", sign, d.kind, d.meta.Namespace, d.meta.Name, d.action)
+		if len(d.note) > 0 {
+			fmt.Printf("    # %v\n", d.note)
+		}
+		for _, c := range d.changes {
+			switch d.action {
+			case diffActionCreate:
+				fmt.Printf("    %v: %v\n", c.path, formatDiffValue(c.newValue))
+			case diffActionDelete:
+				fmt.Printf("    %v: %v\n", c.path, formatDiffValue(c.oldValue))
+			default:
+				fmt.Printf("    %v: %v -> %v\n", c.path, formatDiffValue(c.oldValue), formatDiffValue(c.newValue))
+			}
+		}
+	}
+
+	changed := counts[diffActionCreate] + counts[diffActionUpdate] + counts[diffActionDelete]
+	if changed == 0 {
+		fmt.Println("Everything up to date.")
+		return 0
+	}
+	fmt.Printf("\n%v: %v to create, %v to update, %v to delete, %v unchanged.\n", summary,
+		counts[diffActionCreate], counts[diffActionUpdate], counts[diffActionDelete], counts[diffActionUnchanged])
+	return changed
+}
+
+func formatDiffValue(v interface{}) string {
+	if v == nil {
+		return "<none>"
+	}
+	b, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Sprintf("%v", v)
+	}
+	return string(b)
+}
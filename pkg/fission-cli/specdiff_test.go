@@ -0,0 +1,76 @@
+package fission_cli
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	fv1 "github.com/fission/fission/pkg/apis/fission.io/v1"
+	"github.com/fission/fission/pkg/fission-cli/cmd/spec"
+)
+
+func TestDiffSpecs(t *testing.T) {
+	oldSpec := fv1.TimeTriggerSpec{
+		Cron: "@every 1m",
+		FunctionReference: fv1.FunctionReference{
+			Type: fv1.FunctionReferenceTypeFunctionName,
+			Name: "foo",
+		},
+	}
+	newSpec := oldSpec
+	newSpec.Cron = "@every 5m"
+
+	assert.Empty(t, diffSpecs(oldSpec, oldSpec))
+
+	changes := diffSpecs(oldSpec, newSpec)
+	assert.Equal(t, []fieldChange{{path: "cron", oldValue: "@every 1m", newValue: "@every 5m"}}, changes)
+
+	// every field of a created object is listed
+	changes = diffSpecs(nil, newSpec)
+	assert.Len(t, changes, 3)
+	for _, c := range changes {
+		assert.Nil(t, c.oldValue)
+	}
+}
+
+func TestDiffSpecObjects(t *testing.T) {
+	fr := &spec.FissionResources{
+		DeploymentConfig: spec.DeploymentConfig{UID: "uid"},
+	}
+	annotations := map[string]string{spec.FISSION_DEPLOYMENT_UID_KEY: "uid"}
+
+	existing := []specObject{
+		{meta: &metav1.ObjectMeta{Name: "same", Annotations: annotations}, spec: fv1.TimeTriggerSpec{Cron: "@hourly"}},
+		{meta: &metav1.ObjectMeta{Name: "changed", Annotations: annotations}, spec: fv1.TimeTriggerSpec{Cron: "@hourly"}},
+		{meta: &metav1.ObjectMeta{Name: "removed", Annotations: annotations}, spec: fv1.TimeTriggerSpec{Cron: "@hourly"}},
+		// not created by this deployment, never touched
+		{meta: &metav1.ObjectMeta{Name: "other"}, spec: fv1.TimeTriggerSpec{Cron: "@hourly"}},
+	}
+	desired := []specObject{
+		{meta: &metav1.ObjectMeta{Name: "same"}, spec: fv1.TimeTriggerSpec{Cron: "@hourly"}},
+		{meta: &metav1.ObjectMeta{Name: "changed"}, spec: fv1.TimeTriggerSpec{Cron: "@daily"}},
+		{meta: &metav1.ObjectMeta{Name: "added"}, spec: fv1.TimeTriggerSpec{Cron: "@daily"}},
+	}
+
+	actions := func(diffs []resourceDiff) map[string]string {
+		m := make(map[string]string)
+		for _, d := range diffs {
+			m[d.meta.Name] = d.action
+		}
+		return m
+	}
+
+	assert.Equal(t, map[string]string{
+		"same":    diffActionUnchanged,
+		"changed": diffActionUpdate,
+		"added":   diffActionCreate,
+	}, actions(diffSpecObjects("TimeTrigger", fr, existing, desired, false)))
+
+	assert.Equal(t, map[string]string{
+		"same":    diffActionUnchanged,
+		"changed": diffActionUpdate,
+		"added":   diffActionCreate,
+		"removed": diffActionDelete,
+	}, actions(diffSpecObjects("TimeTrigger", fr, existing, desired, true)))
+}
@@ -0,0 +1,185 @@
+/*
+Copyright 2019 The Fission Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package fission_cli
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"reflect"
+	"text/tabwriter"
+
+	"github.com/pkg/errors"
+	"github.com/urfave/cli"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	fv1 "github.com/fission/fission/pkg/apis/fission.io/v1"
+	"github.com/fission/fission/pkg/controller/client"
+	"github.com/fission/fission/pkg/fission-cli/cliwrapper/driver/urfavecli"
+	"github.com/fission/fission/pkg/fission-cli/cmd"
+	"github.com/fission/fission/pkg/fission-cli/cmd/spec"
+	"github.com/fission/fission/pkg/fission-cli/util"
+)
+
+const (
+	driftStatusInSync  = "in sync"
+	driftStatusDrifted = "drifted"
+	// the resource was applied before spec checksums were recorded
+	driftStatusUnknown = "unknown"
+)
+
+// resourceDrift is the drift status of a resource deployed from the specs.
+type resourceDrift struct {
+	kind   string
+	meta   *metav1.ObjectMeta
+	status string
+}
+
+// specDrift reports the resources of the deployment whose live spec no
+// longer matches the spec checksum recorded by the last spec apply, i.e.
+// resources changed outside of the specs, for example with kubectl edit.
+// It exits with status 1 if any resource drifted, so it can be used to
+// raise alerts in GitOps setups.
+func specDrift(c *cli.Context) error {
+	specDir := cmd.GetSpecDir(urfavecli.Parse(c))
+	fclient := util.GetApiClient(c.GlobalString("server"))
+
+	fr, err := readAppSpecs(c, specDir)
+	util.CheckErr(err, "read specs")
+
+	drifts, err := deployedResourceDrifts(fclient, fr)
+	util.CheckErr(err, "check spec drift")
+
+	drifted := 0
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 1, ' ', 0)
+	fmt.Fprintf(w, "%v\t%v\t%v\t%v\n", "KIND", "NAME", "NAMESPACE", "STATUS")
+	for _, d := range drifts {
+		fmt.Fprintf(w, "%v\t%v\t%v\t%v\n", d.kind, d.meta.Name, d.meta.Namespace, d.status)
+		if d.status == driftStatusDrifted {
+			drifted++
+		}
+	}
+	w.Flush()
+
+	if drifted > 0 {
+		fmt.Printf("\n%v drifted from the last applied specs.\n", pluralize(drifted, "resource"))
+		os.Exit(1)
+	}
+	return nil
+}
+
+// deployedResourceDrifts returns the drift status of every resource on the
+// cluster created by the deployment of the specs.
+func deployedResourceDrifts(fclient *client.Client, fr *spec.FissionResources) ([]resourceDrift, error) {
+	var drifts []resourceDrift
+	add := func(kind string, m *metav1.ObjectMeta, objSpec interface{}) {
+		if hasDeploymentConfig(m, fr) {
+			drifts = append(drifts, resourceDrift{kind: kind, meta: m, status: driftStatus(m, objSpec)})
+		}
+	}
+
+	envs, err := fclient.EnvironmentList(metav1.NamespaceAll)
+	if err != nil {
+		return nil, errors.Wrap(err, "error listing environments")
+	}
+	for i := range envs {
+		add("environment", &envs[i].Metadata, envs[i].Spec)
+	}
+
+	pkgs, err := fclient.PackageList(metav1.NamespaceAll)
+	if err != nil {
+		return nil, errors.Wrap(err, "error listing packages")
+	}
+	for i := range pkgs {
+		add("package", &pkgs[i].Metadata, pkgs[i].Spec)
+	}
+
+	fns, err := fclient.FunctionList(metav1.NamespaceAll)
+	if err != nil {
+		return nil, errors.Wrap(err, "error listing functions")
+	}
+	for i := range fns {
+		add("function", &fns[i].Metadata, fns[i].Spec)
+	}
+
+	hts, err := fclient.HTTPTriggerList(metav1.NamespaceAll)
+	if err != nil {
+		return nil, errors.Wrap(err, "error listing HTTP triggers")
+	}
+	for i := range hts {
+		add("HTTPTrigger", &hts[i].Metadata, hts[i].Spec)
+	}
+
+	watches, err := fclient.WatchList(metav1.NamespaceAll)
+	if err != nil {
+		return nil, errors.Wrap(err, "error listing kubernetes watch triggers")
+	}
+	for i := range watches {
+		add("KubernetesWatchTrigger", &watches[i].Metadata, watches[i].Spec)
+	}
+
+	tts, err := fclient.TimeTriggerList(metav1.NamespaceAll)
+	if err != nil {
+		return nil, errors.Wrap(err, "error listing time triggers")
+	}
+	for i := range tts {
+		add("TimeTrigger", &tts[i].Metadata, tts[i].Spec)
+	}
+
+	mqts, err := fclient.MessageQueueTriggerList("", metav1.NamespaceAll)
+	if err != nil {
+		return nil, errors.Wrap(err, "error listing message queue triggers")
+	}
+	for i := range mqts {
+		add("MessageQueueTrigger", &mqts[i].Metadata, mqts[i].Spec)
+	}
+
+	return drifts, nil
+}
+
+// driftStatus compares the live spec of a resource with the spec checksum
+// recorded on it by spec apply.
+func driftStatus(m *metav1.ObjectMeta, objSpec interface{}) string {
+	checksum, ok := m.Annotations[spec.FISSION_SPEC_CHECKSUM_KEY]
+	if !ok {
+		return driftStatusUnknown
+	}
+	if checksum != specChecksum(objSpec) {
+		return driftStatusDrifted
+	}
+	return driftStatusInSync
+}
+
+// specChecksum returns the SHA256 checksum of the JSON representation of
+// the spec of a resource.
+func specChecksum(objSpec interface{}) string {
+	// the deployment archive of a package built from source is set by
+	// the builder, it's not part of the applied spec
+	if pkgSpec, ok := objSpec.(fv1.PackageSpec); ok && !reflect.DeepEqual(pkgSpec.Source, fv1.Archive{}) {
+		pkgSpec.Deployment = fv1.Archive{}
+		objSpec = pkgSpec
+	}
+
+	b, err := json.Marshal(objSpec)
+	if err != nil {
+		return ""
+	}
+	sum := sha256.Sum256(b)
+	return hex.EncodeToString(sum[:])
+}
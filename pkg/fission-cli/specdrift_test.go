@@ -0,0 +1,51 @@
+package fission_cli
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	fv1 "github.com/fission/fission/pkg/apis/fission.io/v1"
+)
+
+func TestDriftStatus(t *testing.T) {
+	ttSpec := fv1.TimeTriggerSpec{
+		Cron: "@every 1m",
+		FunctionReference: fv1.FunctionReference{
+			Type: fv1.FunctionReferenceTypeFunctionName,
+			Name: "foo",
+		},
+	}
+
+	m := &metav1.ObjectMeta{Name: "tt"}
+	assert.Equal(t, driftStatusUnknown, driftStatus(m, ttSpec))
+
+	applySpecChecksum(m, ttSpec)
+	assert.Equal(t, driftStatusInSync, driftStatus(m, ttSpec))
+
+	edited := ttSpec
+	edited.Cron = "@every 5m"
+	assert.Equal(t, driftStatusDrifted, driftStatus(m, edited))
+}
+
+func TestPackageSpecChecksum(t *testing.T) {
+	pkgSpec := fv1.PackageSpec{
+		Environment: fv1.EnvironmentReference{Namespace: "default", Name: "python"},
+		Source: fv1.Archive{
+			Type: fv1.ArchiveTypeUrl,
+			URL:  "http://storagesvc/archive?id=src",
+		},
+	}
+	built := pkgSpec
+	built.Deployment = fv1.Archive{
+		Type: fv1.ArchiveTypeUrl,
+		URL:  "http://storagesvc/archive?id=deploy",
+	}
+
+	// the build output doesn't count as drift
+	assert.Equal(t, specChecksum(pkgSpec), specChecksum(built))
+
+	built.BuildCommand = "./build.sh"
+	assert.NotEqual(t, specChecksum(pkgSpec), specChecksum(built))
+}
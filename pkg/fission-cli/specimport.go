@@ -0,0 +1,397 @@
+/*
+Copyright 2019 The Fission Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package fission_cli
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/urfave/cli"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	fv1 "github.com/fission/fission/pkg/apis/fission.io/v1"
+	"github.com/fission/fission/pkg/controller/client"
+	"github.com/fission/fission/pkg/fission-cli/cliwrapper/driver/urfavecli"
+	"github.com/fission/fission/pkg/fission-cli/cmd"
+	"github.com/fission/fission/pkg/fission-cli/cmd/spec"
+	"github.com/fission/fission/pkg/fission-cli/util"
+)
+
+// specImportArchiveDir is the directory, next to the spec directory, the
+// archives of imported packages are written to.
+const specImportArchiveDir = "archives"
+
+type (
+	// importedResource is a resource of the cluster written into the
+	// spec directory.
+	importedResource struct {
+		kind     string
+		metadata *metav1.ObjectMeta
+		specFile string
+		// specs are the spec documents of the resource, e.g. a package
+		// and the ArchiveUploadSpecs of its archives.
+		specs []interface{}
+		// adopt annotates the resource on the cluster with the deployment
+		// config of the specs.
+		adopt func(*spec.FissionResources) error
+	}
+)
+
+// specImport writes the environments, packages, functions and triggers of
+// a namespace into the spec directory, so that resources created with the
+// CLI can be managed with "fission spec apply" from then on. The archives
+// stored by the controller are downloaded next to the spec directory.
+// Resources already in the specs are skipped.
+func specImport(c *cli.Context) error {
+	fclient := util.GetApiClient(c.GlobalString("server"))
+	specDir := cmd.GetSpecDir(urfavecli.Parse(c))
+	namespace := c.String("namespace")
+
+	fr, err := readSpecs(specDir)
+	util.CheckErr(err, "read specs")
+	existing := make(map[string]bool)
+	for _, o := range fr.Environments {
+		existing["Environment/"+mapKey(&o.Metadata)] = true
+	}
+	for _, o := range fr.Packages {
+		existing["Package/"+mapKey(&o.Metadata)] = true
+	}
+	for _, o := range fr.Functions {
+		existing["Function/"+mapKey(&o.Metadata)] = true
+	}
+	for _, o := range fr.HttpTriggers {
+		existing["HTTPTrigger/"+mapKey(&o.Metadata)] = true
+	}
+	for _, o := range fr.TimeTriggers {
+		existing["TimeTrigger/"+mapKey(&o.Metadata)] = true
+	}
+	for _, o := range fr.MessageQueueTriggers {
+		existing["MessageQueueTrigger/"+mapKey(&o.Metadata)] = true
+	}
+	for _, o := range fr.KubernetesWatchTriggers {
+		existing["KubernetesWatchTrigger/"+mapKey(&o.Metadata)] = true
+	}
+
+	resources, err := getImportedResources(fclient, namespace, filepath.Clean(specDir+"/.."))
+	util.CheckErr(err, fmt.Sprintf("read resources of namespace %v", namespace))
+
+	imported, skipped := make([]importedResource, 0), 0
+	for _, r := range resources {
+		if existing[r.kind+"/"+mapKey(r.metadata)] {
+			fmt.Printf("Skipping %v %v, it already exists in the specs\n", r.kind, r.metadata.Name)
+			skipped++
+			continue
+		}
+		for _, s := range r.specs {
+			err = spec.SpecSaveInDir(s, specDir, r.specFile)
+			util.CheckErr(err, fmt.Sprintf("write spec file %v", r.specFile))
+		}
+		imported = append(imported, r)
+	}
+
+	// make sure the spec directory can be applied as it is
+	fr, err = readSpecs(specDir)
+	util.CheckErr(err, "read specs")
+	err = fr.Validate(c)
+	util.CheckErr(err, "validate specs")
+
+	if c.Bool("adopt") {
+		for _, r := range imported {
+			err = r.adopt(fr)
+			util.CheckErr(err, fmt.Sprintf("adopt %v %v", r.kind, r.metadata.Name))
+		}
+	}
+
+	fmt.Printf("Imported %v, skipped %v\n", pluralize(len(imported), "resource"), pluralize(skipped, "resource"))
+	if !c.Bool("adopt") && len(imported) > 0 {
+		fmt.Println("Use --adopt to let \"fission spec apply\" update the imported resources on the cluster")
+	}
+	return nil
+}
+
+// getImportedResources reads the resources of a namespace and returns
+// their specs. Archives are written under rootDir.
+func getImportedResources(fclient *client.Client, namespace string, rootDir string) ([]importedResource, error) {
+	var resources []importedResource
+
+	envs, err := fclient.EnvironmentList(namespace)
+	if err != nil {
+		return nil, err
+	}
+	for i := range envs {
+		o := envs[i]
+		resources = append(resources, importedResource{
+			kind:     "Environment",
+			metadata: &o.Metadata,
+			specFile: fmt.Sprintf("env-%v.yaml", o.Metadata.Name),
+			specs:    []interface{}{fv1.Environment{Metadata: importMetadata(o.Metadata), Spec: o.Spec}},
+			adopt: func(fr *spec.FissionResources) error {
+				applyDeploymentConfig(&o.Metadata, fr)
+				_, err := fclient.EnvironmentUpdate(&o)
+				return err
+			},
+		})
+	}
+
+	pkgs, err := fclient.PackageList(namespace)
+	if err != nil {
+		return nil, err
+	}
+	for i := range pkgs {
+		o := pkgs[i]
+		specs, err := importPackage(fclient, &o, rootDir)
+		if err != nil {
+			return nil, errors.Wrapf(err, "error importing package %v", o.Metadata.Name)
+		}
+		resources = append(resources, importedResource{
+			kind:     "Package",
+			metadata: &o.Metadata,
+			specFile: fmt.Sprintf("package-%v.yaml", o.Metadata.Name),
+			specs:    specs,
+			adopt: func(fr *spec.FissionResources) error {
+				applyDeploymentConfig(&o.Metadata, fr)
+				_, err := fclient.PackageUpdate(&o)
+				return err
+			},
+		})
+	}
+
+	fns, err := fclient.FunctionList(namespace)
+	if err != nil {
+		return nil, err
+	}
+	for i := range fns {
+		o := fns[i]
+		fn := fv1.Function{Metadata: importMetadata(o.Metadata), Spec: o.Spec}
+		fn.Spec.Package.PackageRef.ResourceVersion = ""
+		resources = append(resources, importedResource{
+			kind:     "Function",
+			metadata: &o.Metadata,
+			specFile: fmt.Sprintf("function-%v.yaml", o.Metadata.Name),
+			specs:    []interface{}{fn},
+			adopt: func(fr *spec.FissionResources) error {
+				applyDeploymentConfig(&o.Metadata, fr)
+				_, err := fclient.FunctionUpdate(&o)
+				return err
+			},
+		})
+	}
+
+	hts, err := fclient.HTTPTriggerList(namespace)
+	if err != nil {
+		return nil, err
+	}
+	for i := range hts {
+		o := hts[i]
+		resources = append(resources, importedResource{
+			kind:     "HTTPTrigger",
+			metadata: &o.Metadata,
+			specFile: fmt.Sprintf("route-%v.yaml", o.Metadata.Name),
+			specs:    []interface{}{fv1.HTTPTrigger{Metadata: importMetadata(o.Metadata), Spec: o.Spec}},
+			adopt: func(fr *spec.FissionResources) error {
+				applyDeploymentConfig(&o.Metadata, fr)
+				_, err := fclient.HTTPTriggerUpdate(&o)
+				return err
+			},
+		})
+	}
+
+	tts, err := fclient.TimeTriggerList(namespace)
+	if err != nil {
+		return nil, err
+	}
+	for i := range tts {
+		o := tts[i]
+		resources = append(resources, importedResource{
+			kind:     "TimeTrigger",
+			metadata: &o.Metadata,
+			specFile: fmt.Sprintf("timetrigger-%v.yaml", o.Metadata.Name),
+			specs:    []interface{}{fv1.TimeTrigger{Metadata: importMetadata(o.Metadata), Spec: o.Spec}},
+			adopt: func(fr *spec.FissionResources) error {
+				applyDeploymentConfig(&o.Metadata, fr)
+				_, err := fclient.TimeTriggerUpdate(&o)
+				return err
+			},
+		})
+	}
+
+	mqts, err := fclient.MessageQueueTriggerList("", namespace)
+	if err != nil {
+		return nil, err
+	}
+	for i := range mqts {
+		o := mqts[i]
+		resources = append(resources, importedResource{
+			kind:     "MessageQueueTrigger",
+			metadata: &o.Metadata,
+			specFile: fmt.Sprintf("mqtrigger-%v.yaml", o.Metadata.Name),
+			specs:    []interface{}{fv1.MessageQueueTrigger{Metadata: importMetadata(o.Metadata), Spec: o.Spec}},
+			adopt: func(fr *spec.FissionResources) error {
+				applyDeploymentConfig(&o.Metadata, fr)
+				_, err := fclient.MessageQueueTriggerUpdate(&o)
+				return err
+			},
+		})
+	}
+
+	watches, err := fclient.WatchList(namespace)
+	if err != nil {
+		return nil, err
+	}
+	for i := range watches {
+		o := watches[i]
+		resources = append(resources, importedResource{
+			kind:     "KubernetesWatchTrigger",
+			metadata: &o.Metadata,
+			specFile: fmt.Sprintf("kubewatch-%v.yaml", o.Metadata.Name),
+			specs:    []interface{}{fv1.KubernetesWatchTrigger{Metadata: importMetadata(o.Metadata), Spec: o.Spec}},
+			adopt: func(fr *spec.FissionResources) error {
+				applyDeploymentConfig(&o.Metadata, fr)
+				_, err := fclient.WatchUpdate(&o)
+				return err
+			},
+		})
+	}
+
+	return resources, nil
+}
+
+// importMetadata returns the metadata of a resource to write in its spec,
+// without the fields set by the server and the annotations of spec apply.
+func importMetadata(m metav1.ObjectMeta) metav1.ObjectMeta {
+	meta := metav1.ObjectMeta{
+		Name:      m.Name,
+		Namespace: m.Namespace,
+		Labels:    m.Labels,
+	}
+	for k, v := range m.Annotations {
+		if k == spec.FISSION_DEPLOYMENT_NAME_KEY || k == spec.FISSION_DEPLOYMENT_UID_KEY || k == spec.FISSION_SPEC_CHECKSUM_KEY {
+			continue
+		}
+		if meta.Annotations == nil {
+			meta.Annotations = make(map[string]string)
+		}
+		meta.Annotations[k] = v
+	}
+	return meta
+}
+
+// importPackage returns the specs of a package and of its archives. The
+// source archive is imported if there is one, and the package rebuilt from
+// it on apply, otherwise the deployment archive is.
+func importPackage(fclient *client.Client, pkg *fv1.Package, rootDir string) ([]interface{}, error) {
+	p := fv1.Package{
+		Metadata: importMetadata(pkg.Metadata),
+		Spec:     pkg.Spec,
+		Status: fv1.PackageStatus{
+			BuildStatus:         fv1.BuildStatusNone,
+			LastUpdateTimestamp: time.Now().UTC(),
+		},
+	}
+
+	var specs []interface{}
+	if !isEmptyArchive(&pkg.Spec.Source) {
+		p.Spec.Deployment = fv1.Archive{}
+		p.Status.BuildStatus = fv1.BuildStatusPending
+		aus, err := importArchive(fclient, &p.Spec.Source, util.KubifyName(pkg.Metadata.Name+"-src"), rootDir)
+		if err != nil {
+			return nil, err
+		}
+		if aus != nil {
+			specs = append(specs, *aus)
+		}
+	} else if !isEmptyArchive(&pkg.Spec.Deployment) {
+		aus, err := importArchive(fclient, &p.Spec.Deployment, util.KubifyName(pkg.Metadata.Name+"-deploy"), rootDir)
+		if err != nil {
+			return nil, err
+		}
+		if aus != nil {
+			specs = append(specs, *aus)
+		}
+	}
+	return append(specs, p), nil
+}
+
+// isEmptyArchive returns true if the archive has no content.
+func isEmptyArchive(ar *fv1.Archive) bool {
+	return len(ar.Literal) == 0 && len(ar.URL) == 0
+}
+
+// importArchive writes the content of an archive stored in the package or
+// by the storage service to a file under rootDir, and points the archive to
+// an ArchiveUploadSpec of the file. Git repositories and archives at other
+// URLs are left as they are, and nil is returned for them.
+func importArchive(fclient *client.Client, ar *fv1.Archive, name string, rootDir string) (*spec.ArchiveUploadSpec, error) {
+	var reader io.Reader
+	switch {
+	case ar.Type == fv1.ArchiveTypeLiteral:
+		reader = bytes.NewReader(ar.Literal)
+	case ar.Type == fv1.ArchiveTypeUrl && isStorageServiceURL(ar.URL):
+		readCloser := downloadStoragesvcURL(fclient, ar.URL)
+		if readCloser == nil {
+			return nil, fmt.Errorf("invalid archive URL %v", ar.URL)
+		}
+		defer readCloser.Close()
+		reader = readCloser
+	default:
+		return nil, nil
+	}
+
+	// zip files are uploaded as they are, other files are the
+	// single-file archives of e.g. "fission fn create --code"
+	br := bufio.NewReader(reader)
+	fileName := name
+	if magic, _ := br.Peek(4); bytes.Equal(magic, []byte("PK\x03\x04")) {
+		fileName += ".zip"
+	}
+	relativePath := filepath.Join(specImportArchiveDir, fileName)
+
+	err := os.MkdirAll(filepath.Join(rootDir, specImportArchiveDir), 0755)
+	if err != nil {
+		return nil, err
+	}
+	err = writeArchiveToFile(filepath.Join(rootDir, relativePath), br)
+	if err != nil {
+		return nil, err
+	}
+
+	*ar = fv1.Archive{
+		Type: fv1.ArchiveTypeUrl,
+		URL:  spec.ARCHIVE_URL_PREFIX + name,
+	}
+	return &spec.ArchiveUploadSpec{
+		Name:         name,
+		IncludeGlobs: []string{relativePath},
+	}, nil
+}
+
+// isStorageServiceURL returns true if the archive is stored by the storage
+// service of Fission.
+func isStorageServiceURL(archiveURL string) bool {
+	u, err := url.Parse(archiveURL)
+	if err != nil {
+		return false
+	}
+	return u.Path == "/v1/archive" && len(u.Query().Get("id")) > 0
+}
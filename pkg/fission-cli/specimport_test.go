@@ -0,0 +1,95 @@
+package fission_cli
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	fv1 "github.com/fission/fission/pkg/apis/fission.io/v1"
+	"github.com/fission/fission/pkg/fission-cli/cmd/spec"
+)
+
+func TestImportMetadata(t *testing.T) {
+	m := importMetadata(metav1.ObjectMeta{
+		Name:            "hello",
+		Namespace:       "default",
+		ResourceVersion: "1234",
+		UID:             "5678",
+		Labels:          map[string]string{"team": "payments"},
+		Annotations: map[string]string{
+			spec.FISSION_DEPLOYMENT_UID_KEY: "abcd",
+			"owner":                         "alice",
+		},
+	})
+	assert.Equal(t, metav1.ObjectMeta{
+		Name:        "hello",
+		Namespace:   "default",
+		Labels:      map[string]string{"team": "payments"},
+		Annotations: map[string]string{"owner": "alice"},
+	}, m)
+}
+
+func TestImportPackage(t *testing.T) {
+	rootDir, err := ioutil.TempDir("", "fission-spec-import")
+	assert.NoError(t, err)
+	defer os.RemoveAll(rootDir)
+
+	zip := []byte("PK\x03\x04 not really a zip")
+	pkg := &fv1.Package{
+		Metadata: metav1.ObjectMeta{Name: "hello-pkg", Namespace: "default", ResourceVersion: "1"},
+		Spec: fv1.PackageSpec{
+			Environment: fv1.EnvironmentReference{Name: "nodejs", Namespace: "default"},
+			Deployment:  fv1.Archive{Type: fv1.ArchiveTypeLiteral, Literal: zip},
+		},
+		Status: fv1.PackageStatus{BuildStatus: fv1.BuildStatusSucceeded},
+	}
+	specs, err := importPackage(nil, pkg, rootDir)
+	assert.NoError(t, err)
+	if assert.Len(t, specs, 2) {
+		aus := specs[0].(spec.ArchiveUploadSpec)
+		assert.Equal(t, "hello-pkg-deploy", aus.Name)
+		assert.Equal(t, []string{filepath.Join("archives", "hello-pkg-deploy.zip")}, aus.IncludeGlobs)
+		b, err := ioutil.ReadFile(filepath.Join(rootDir, aus.IncludeGlobs[0]))
+		assert.NoError(t, err)
+		assert.Equal(t, zip, b)
+
+		p := specs[1].(fv1.Package)
+		assert.Equal(t, fv1.Archive{Type: fv1.ArchiveTypeUrl, URL: "archive://hello-pkg-deploy"}, p.Spec.Deployment)
+		assert.Equal(t, fv1.BuildStatus(fv1.BuildStatusNone), p.Status.BuildStatus)
+		assert.Empty(t, p.Metadata.ResourceVersion)
+	}
+	// the package on the cluster is left untouched
+	assert.Equal(t, fv1.ArchiveTypeLiteral, pkg.Spec.Deployment.Type)
+
+	// packages with a source are rebuilt from it
+	pkg.Metadata.Name = "built-pkg"
+	pkg.Spec.Source = fv1.Archive{Type: fv1.ArchiveTypeLiteral, Literal: []byte("console.log('hi')")}
+	specs, err = importPackage(nil, pkg, rootDir)
+	assert.NoError(t, err)
+	if assert.Len(t, specs, 2) {
+		aus := specs[0].(spec.ArchiveUploadSpec)
+		assert.Equal(t, []string{filepath.Join("archives", "built-pkg-src")}, aus.IncludeGlobs)
+		p := specs[1].(fv1.Package)
+		assert.Equal(t, "archive://built-pkg-src", p.Spec.Source.URL)
+		assert.Equal(t, fv1.Archive{}, p.Spec.Deployment)
+		assert.Equal(t, fv1.BuildStatus(fv1.BuildStatusPending), p.Status.BuildStatus)
+	}
+
+	// git repositories are cloned by the builder, nothing to download
+	pkg.Spec.Source = fv1.Archive{Type: fv1.ArchiveTypeGit, URL: "https://github.com/fission/examples.git"}
+	specs, err = importPackage(nil, pkg, rootDir)
+	assert.NoError(t, err)
+	if assert.Len(t, specs, 1) {
+		assert.Equal(t, pkg.Spec.Source, specs[0].(fv1.Package).Spec.Source)
+	}
+}
+
+func TestIsStorageServiceURL(t *testing.T) {
+	assert.True(t, isStorageServiceURL("http://storagesvc.fission/v1/archive?id=%2Ffission%2Ffission-functions%2Fabc"))
+	assert.False(t, isStorageServiceURL("https://example.com/hello.zip"))
+	assert.False(t, isStorageServiceURL("http://storagesvc.fission/v1/archive"))
+}
@@ -0,0 +1,180 @@
+/*
+Copyright 2019 The Fission Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package fission_cli
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"regexp"
+
+	"github.com/ghodss/yaml"
+	"github.com/pkg/errors"
+	"github.com/urfave/cli"
+	apiv1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	fv1 "github.com/fission/fission/pkg/apis/fission.io/v1"
+	"github.com/fission/fission/pkg/fission-cli/cliwrapper/driver/urfavecli"
+	"github.com/fission/fission/pkg/fission-cli/cmd"
+	"github.com/fission/fission/pkg/fission-cli/cmd/spec"
+	"github.com/fission/fission/pkg/fission-cli/log"
+	"github.com/fission/fission/pkg/fission-cli/util"
+)
+
+// lintViolation is a resource in the specs breaking one of the lint rules.
+type lintViolation struct {
+	location spec.Location
+	kind     string
+	name     string
+	message  string
+}
+
+// specLint checks the specs against the lint rules of the spec directory, or
+// of the file given with --rules. It exits with status 1 if any resource
+// breaks a rule, so it can be used to gate changes in CI.
+func specLint(c *cli.Context) error {
+	specDir := cmd.GetSpecDir(urfavecli.Parse(c))
+
+	fr, err := readAppSpecs(c, specDir)
+	util.CheckErr(err, "read specs")
+
+	config := fr.LintConfig
+	if rulesFile := c.String("rules"); len(rulesFile) > 0 {
+		config, err = readLintConfig(rulesFile)
+		util.CheckErr(err, "read lint rules")
+	}
+	if config == nil {
+		log.Fatal(fmt.Sprintf("No lint rules found, add a LintConfig to %v or use --rules", specDir))
+	}
+
+	violations, err := lintSpecs(fr, config)
+	util.CheckErr(err, "lint specs")
+
+	for _, v := range violations {
+		fmt.Printf("%v: %v '%v': %v\n", v.location, v.kind, v.name, v.message)
+	}
+	if len(violations) > 0 {
+		fmt.Printf("%v lint %v\n", len(violations), pluralize(len(violations), "violation"))
+		os.Exit(1)
+	}
+	return nil
+}
+
+func readLintConfig(path string) (*spec.LintConfig, error) {
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var config spec.LintConfig
+	err = yaml.Unmarshal(b, &config)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to parse %v", path)
+	}
+	return &config, nil
+}
+
+// lintSpecs returns the violations of the lint rules by the resources in the
+// specs.
+func lintSpecs(fr *spec.FissionResources, config *spec.LintConfig) ([]lintViolation, error) {
+	var namePattern *regexp.Regexp
+	if len(config.NamePattern) > 0 {
+		var err error
+		namePattern, err = regexp.Compile(config.NamePattern)
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid name pattern %q", config.NamePattern)
+		}
+	}
+
+	var violations []lintViolation
+	report := func(kind string, m *metav1.ObjectMeta, format string, args ...interface{}) {
+		violations = append(violations, lintViolation{
+			location: fr.SourceMap.Locations[kind][m.Namespace][m.Name],
+			kind:     kind,
+			name:     m.Name,
+			message:  fmt.Sprintf(format, args...),
+		})
+	}
+
+	lintMetadata := func(kind string, m *metav1.ObjectMeta) {
+		if namePattern != nil && !namePattern.MatchString(m.Name) {
+			report(kind, m, "name doesn't match %q", config.NamePattern)
+		}
+		for _, key := range config.RequiredLabels {
+			if _, ok := m.Labels[key]; !ok {
+				report(kind, m, "missing label %q", key)
+			}
+		}
+		for _, key := range config.RequiredAnnotations {
+			if _, ok := m.Annotations[key]; !ok {
+				report(kind, m, "missing annotation %q", key)
+			}
+		}
+	}
+
+	lintLimits := func(kind string, m *metav1.ObjectMeta, resources apiv1.ResourceRequirements) {
+		if !config.RequireResourceLimits {
+			return
+		}
+		if resources.Limits.Cpu().IsZero() {
+			report(kind, m, "missing CPU limit")
+		}
+		if resources.Limits.Memory().IsZero() {
+			report(kind, m, "missing memory limit")
+		}
+	}
+
+	for i := range fr.Environments {
+		env := &fr.Environments[i]
+		lintMetadata("Environment", &env.Metadata)
+		lintLimits("Environment", &env.Metadata, env.Spec.Resources)
+	}
+	for i := range fr.Packages {
+		lintMetadata("Package", &fr.Packages[i].Metadata)
+	}
+	for i := range fr.Functions {
+		fn := &fr.Functions[i]
+		lintMetadata("Function", &fn.Metadata)
+
+		executorType := fn.Spec.InvokeStrategy.ExecutionStrategy.ExecutorType
+		if len(executorType) == 0 {
+			executorType = fv1.ExecutorTypePoolmgr
+		}
+		for _, forbidden := range config.ForbiddenExecutorTypes {
+			if executorType == forbidden {
+				report("Function", &fn.Metadata, "executor type %q is not allowed", executorType)
+			}
+		}
+		if executorType == fv1.ExecutorTypeNewdeploy || executorType == fv1.ExecutorTypeContainer {
+			lintLimits("Function", &fn.Metadata, fn.Spec.Resources)
+		}
+	}
+	for i := range fr.HttpTriggers {
+		lintMetadata("HTTPTrigger", &fr.HttpTriggers[i].Metadata)
+	}
+	for i := range fr.KubernetesWatchTriggers {
+		lintMetadata("KubernetesWatchTrigger", &fr.KubernetesWatchTriggers[i].Metadata)
+	}
+	for i := range fr.TimeTriggers {
+		lintMetadata("TimeTrigger", &fr.TimeTriggers[i].Metadata)
+	}
+	for i := range fr.MessageQueueTriggers {
+		lintMetadata("MessageQueueTrigger", &fr.MessageQueueTriggers[i].Metadata)
+	}
+
+	return violations, nil
+}
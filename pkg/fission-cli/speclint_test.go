@@ -0,0 +1,67 @@
+package fission_cli
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	apiv1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	fv1 "github.com/fission/fission/pkg/apis/fission.io/v1"
+	"github.com/fission/fission/pkg/fission-cli/cmd/spec"
+)
+
+func TestLintSpecs(t *testing.T) {
+	limits := apiv1.ResourceRequirements{
+		Limits: apiv1.ResourceList{
+			apiv1.ResourceCPU:    resource.MustParse("100m"),
+			apiv1.ResourceMemory: resource.MustParse("128Mi"),
+		},
+	}
+	fr := &spec.FissionResources{
+		Functions: []fv1.Function{
+			{
+				Metadata: metav1.ObjectMeta{Name: "team-a-hello", Labels: map[string]string{"owner": "a"}},
+			},
+			{
+				Metadata: metav1.ObjectMeta{Name: "hello", Labels: map[string]string{"owner": "a"}},
+				Spec: fv1.FunctionSpec{
+					InvokeStrategy: fv1.InvokeStrategy{
+						ExecutionStrategy: fv1.ExecutionStrategy{ExecutorType: fv1.ExecutorTypeNewdeploy},
+					},
+				},
+			},
+		},
+		Environments: []fv1.Environment{
+			{
+				Metadata: metav1.ObjectMeta{Name: "team-a-node", Labels: map[string]string{"owner": "a"}},
+				Spec:     fv1.EnvironmentSpec{Resources: limits},
+			},
+		},
+	}
+	config := &spec.LintConfig{
+		NamePattern:            "^team-",
+		RequiredLabels:         []string{"owner"},
+		ForbiddenExecutorTypes: []fv1.ExecutorType{fv1.ExecutorTypeNewdeploy},
+		RequireResourceLimits:  true,
+	}
+
+	violations, err := lintSpecs(fr, config)
+	assert.Nil(t, err)
+	messages := make([]string, 0, len(violations))
+	for _, v := range violations {
+		assert.Equal(t, "hello", v.name)
+		messages = append(messages, v.message)
+	}
+	assert.Equal(t, []string{
+		`name doesn't match "^team-"`,
+		`executor type "newdeploy" is not allowed`,
+		"missing CPU limit",
+		"missing memory limit",
+	}, messages)
+
+	config.NamePattern = "("
+	_, err = lintSpecs(fr, config)
+	assert.NotNil(t, err)
+}
@@ -0,0 +1,138 @@
+/*
+Copyright 2019 The Fission Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package fission_cli
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/ghodss/yaml"
+
+	fv1 "github.com/fission/fission/pkg/apis/fission.io/v1"
+	"github.com/fission/fission/pkg/fission-cli/cmd/spec"
+	"github.com/fission/fission/pkg/types"
+)
+
+// renderArchives resolves the archive references of packages to the
+// checksums of the local archives, without uploading anything. Literal
+// contents are dropped, only their checksums are kept. Rendering doesn't
+// talk to the controller, so the default literal size limit is used.
+func renderArchives(specDir string, fr *spec.FissionResources) error {
+	archives := make(map[string]fv1.Archive)
+	for _, aus := range fr.ArchiveUploadSpecs {
+		ar, err := localArchiveFromSpec(specDir, &aus, types.ArchiveLiteralSizeLimit)
+		if err != nil {
+			return err
+		}
+		if ar.Type == fv1.ArchiveTypeLiteral {
+			h := sha256.Sum256(ar.Literal)
+			ar.Checksum = fv1.Checksum{
+				Type: fv1.ChecksumTypeSHA256,
+				Sum:  hex.EncodeToString(h[:]),
+			}
+		}
+		archives[fmt.Sprintf("%v%v", spec.ARCHIVE_URL_PREFIX, aus.Name)] = fv1.Archive{
+			Type:     ar.Type,
+			Checksum: ar.Checksum,
+		}
+	}
+
+	for i := range fr.Packages {
+		for _, ar := range []*fv1.Archive{&fr.Packages[i].Spec.Source, &fr.Packages[i].Spec.Deployment} {
+			if !strings.HasPrefix(ar.URL, spec.ARCHIVE_URL_PREFIX) {
+				continue
+			}
+			rendered, ok := archives[ar.URL]
+			if !ok {
+				return fmt.Errorf("unknown archive name %v", strings.TrimPrefix(ar.URL, spec.ARCHIVE_URL_PREFIX))
+			}
+			*ar = rendered
+		}
+	}
+	return nil
+}
+
+// renderResources writes all resources in the specs as a multi-document
+// YAML, the way they would be created by spec apply.
+func renderResources(w io.Writer, specDir string, fr *spec.FissionResources) error {
+	err := renderArchives(specDir, fr)
+	if err != nil {
+		return err
+	}
+
+	var objs []interface{}
+	for i := range fr.Environments {
+		o := &fr.Environments[i]
+		applyDeploymentConfig(&o.Metadata, fr)
+		o.TypeMeta.APIVersion, o.TypeMeta.Kind = fv1.CRD_VERSION, "Environment"
+		objs = append(objs, o)
+	}
+	for i := range fr.Packages {
+		o := &fr.Packages[i]
+		applyDeploymentConfig(&o.Metadata, fr)
+		o.TypeMeta.APIVersion, o.TypeMeta.Kind = fv1.CRD_VERSION, "Package"
+		objs = append(objs, o)
+	}
+	for i := range fr.Functions {
+		o := &fr.Functions[i]
+		applyDeploymentConfig(&o.Metadata, fr)
+		o.TypeMeta.APIVersion, o.TypeMeta.Kind = fv1.CRD_VERSION, "Function"
+		objs = append(objs, o)
+	}
+	for i := range fr.HttpTriggers {
+		o := &fr.HttpTriggers[i]
+		applyDeploymentConfig(&o.Metadata, fr)
+		o.TypeMeta.APIVersion, o.TypeMeta.Kind = fv1.CRD_VERSION, "HTTPTrigger"
+		objs = append(objs, o)
+	}
+	for i := range fr.KubernetesWatchTriggers {
+		o := &fr.KubernetesWatchTriggers[i]
+		applyDeploymentConfig(&o.Metadata, fr)
+		o.TypeMeta.APIVersion, o.TypeMeta.Kind = fv1.CRD_VERSION, "KubernetesWatchTrigger"
+		objs = append(objs, o)
+	}
+	for i := range fr.TimeTriggers {
+		o := &fr.TimeTriggers[i]
+		applyDeploymentConfig(&o.Metadata, fr)
+		o.TypeMeta.APIVersion, o.TypeMeta.Kind = fv1.CRD_VERSION, "TimeTrigger"
+		objs = append(objs, o)
+	}
+	for i := range fr.MessageQueueTriggers {
+		o := &fr.MessageQueueTriggers[i]
+		applyDeploymentConfig(&o.Metadata, fr)
+		o.TypeMeta.APIVersion, o.TypeMeta.Kind = fv1.CRD_VERSION, "MessageQueueTrigger"
+		objs = append(objs, o)
+	}
+
+	for i, o := range objs {
+		data, err := yaml.Marshal(o)
+		if err != nil {
+			return err
+		}
+		if i > 0 {
+			fmt.Fprintln(w, "---")
+		}
+		_, err = w.Write(data)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,302 @@
+/*
+Copyright 2019 The Fission Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package fission_cli
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/ghodss/yaml"
+	"github.com/pkg/errors"
+	"github.com/urfave/cli"
+)
+
+// specOverlaysDir is the directory in the spec directory holding one
+// directory of overlay specs per deployment environment, e.g.
+// specs/overlays/prod.
+const specOverlaysDir = "overlays"
+
+var (
+	// ${VAR} is replaced with the value of VAR, $$ with a literal $.
+	specVariable     = regexp.MustCompile(`\$\$|\$\{([a-zA-Z_][a-zA-Z0-9_]*)\}`)
+	specVariableName = regexp.MustCompile(`^[a-zA-Z_][a-zA-Z0-9_]*$`)
+)
+
+type (
+	// specTemplate substitutes variables in the specs and patches the
+	// specs with the ones of an overlay, so that the same specs can be
+	// applied to several deployment environments.
+	specTemplate struct {
+		vars    map[string]string
+		overlay string
+	}
+
+	// specDoc is one YAML document of the specs.
+	specDoc struct {
+		data     []byte
+		path     string
+		line     int
+		resource string
+	}
+)
+
+// getSpecTemplate returns the template given with --env-file and
+// --overlay, or nil if neither is given.
+func getSpecTemplate(c *cli.Context) (*specTemplate, error) {
+	envFile := c.String("env-file")
+	overlay := c.String("overlay")
+	if len(envFile) == 0 && len(overlay) == 0 {
+		return nil, nil
+	}
+
+	tmpl := &specTemplate{
+		vars:    make(map[string]string),
+		overlay: overlay,
+	}
+	if len(envFile) > 0 {
+		vars, err := readEnvFile(envFile)
+		if err != nil {
+			return nil, errors.Wrapf(err, "error reading env file %v", envFile)
+		}
+		tmpl.vars = vars
+	}
+	return tmpl, nil
+}
+
+// readEnvFile reads KEY=value lines. Blank lines and lines starting with #
+// are skipped, and values may be quoted.
+func readEnvFile(path string) (map[string]string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	vars := make(map[string]string)
+	scanner := bufio.NewScanner(f)
+	lineNum := 0
+	for scanner.Scan() {
+		lineNum++
+		line := strings.TrimSpace(scanner.Text())
+		if len(line) == 0 || strings.HasPrefix(line, "#") {
+			continue
+		}
+		line = strings.TrimPrefix(line, "export ")
+
+		kv := strings.SplitN(line, "=", 2)
+		key := strings.TrimSpace(kv[0])
+		if len(kv) != 2 || !specVariableName.MatchString(key) {
+			return nil, fmt.Errorf("invalid variable at line %v, expected KEY=value", lineNum)
+		}
+		value := strings.TrimSpace(kv[1])
+		if len(value) >= 2 && (value[0] == '"' || value[0] == '\'') && value[len(value)-1] == value[0] {
+			value = value[1 : len(value)-1]
+		}
+		vars[key] = value
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return vars, nil
+}
+
+// substitute replaces the variables in b with the values of the env file,
+// falling back to the environment of the CLI. Undefined variables are an
+// error, rather than silently becoming empty strings.
+func (tmpl *specTemplate) substitute(b []byte) ([]byte, error) {
+	if tmpl == nil {
+		return b, nil
+	}
+
+	undefined := make(map[string]bool)
+	result := specVariable.ReplaceAllFunc(b, func(match []byte) []byte {
+		if string(match) == "$$" {
+			return []byte("$")
+		}
+		name := string(match[2 : len(match)-1])
+		if value, ok := tmpl.vars[name]; ok {
+			return []byte(value)
+		}
+		if value, ok := os.LookupEnv(name); ok {
+			return []byte(value)
+		}
+		undefined[name] = true
+		return match
+	})
+
+	if len(undefined) > 0 {
+		names := make([]string, 0, len(undefined))
+		for name := range undefined {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		return nil, fmt.Errorf("undefined variables: %v", strings.Join(names, ", "))
+	}
+	return result, nil
+}
+
+// readSpecDocs reads the YAML documents of the files in dir, with the
+// variables substituted. Directories in skipDirs are not read.
+func readSpecDocs(dir string, tmpl *specTemplate, skipDirs ...string) ([]specDoc, error) {
+	docs := make([]specDoc, 0)
+	err := filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if info.IsDir() {
+			for _, skipDir := range skipDirs {
+				if filepath.Clean(path) == filepath.Clean(skipDir) {
+					return filepath.SkipDir
+				}
+			}
+			return nil
+		}
+
+		// For now just read YAML files. We'll add jsonnet at some point. Skip
+		// unsupported files.
+		if !(strings.HasSuffix(path, ".yaml") || strings.HasSuffix(path, ".yml")) {
+			return nil
+		}
+		b, err := ioutil.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		b, err = tmpl.substitute(b)
+		if err != nil {
+			return errors.Wrapf(err, "error substituting variables in %v", path)
+		}
+
+		docs = append(docs, splitSpecDocs(b, path)...)
+		return nil
+	})
+	return docs, err
+}
+
+// splitSpecDocs returns the YAML documents of a file.
+func splitSpecDocs(b []byte, path string) []specDoc {
+	docs := make([]specDoc, 0)
+
+	// handle the case where there are multiple YAML docs per file. go-yaml
+	// doesn't support this directly, yet.
+	lines := 1
+	for _, doc := range bytes.Split(b, []byte("\n---")) {
+		d := []byte(strings.TrimSpace(string(doc)))
+		if len(d) != 0 {
+			docs = append(docs, specDoc{
+				data:     d,
+				path:     path,
+				line:     lines,
+				resource: specDocResource(d),
+			})
+		}
+		// the separator occupies one line, hence the +1
+		lines += strings.Count(string(doc), "\n") + 1
+	}
+	return docs
+}
+
+// specDocResource returns the kind, namespace and name of the resource in
+// the document, or an empty string if it can't be parsed; the parse errors
+// are reported when the document is parsed into the resources.
+func specDocResource(doc []byte) string {
+	var obj struct {
+		Kind     string `json:"kind"`
+		Metadata struct {
+			Name      string `json:"name"`
+			Namespace string `json:"namespace"`
+		} `json:"metadata"`
+	}
+	if err := yaml.Unmarshal(doc, &obj); err != nil || len(obj.Kind) == 0 || len(obj.Metadata.Name) == 0 {
+		return ""
+	}
+	return fmt.Sprintf("%v/%v/%v", obj.Kind, obj.Metadata.Namespace, obj.Metadata.Name)
+}
+
+// applyOverlay patches the documents of the base specs with the documents of
+// the same resource in the overlay directory, as JSON merge patches (RFC 7386).
+// Resources only in the overlay are added to the specs.
+func applyOverlay(docs []specDoc, overlayDir string, tmpl *specTemplate) ([]specDoc, error) {
+	if _, err := os.Stat(overlayDir); err != nil {
+		return nil, errors.Wrapf(err, "error reading overlay %v", overlayDir)
+	}
+	patches, err := readSpecDocs(overlayDir, tmpl)
+	if err != nil {
+		return nil, err
+	}
+
+	index := make(map[string]int)
+	for i, doc := range docs {
+		if len(doc.resource) > 0 {
+			index[doc.resource] = i
+		}
+	}
+
+	for _, patch := range patches {
+		i, ok := index[patch.resource]
+		if !ok || len(patch.resource) == 0 {
+			docs = append(docs, patch)
+			continue
+		}
+		merged, err := mergeSpecDocs(docs[i].data, patch.data)
+		if err != nil {
+			return nil, errors.Wrapf(err, "error applying overlay %v:%v", patch.path, patch.line)
+		}
+		docs[i].data = merged
+	}
+	return docs, nil
+}
+
+// mergeSpecDocs applies the patch document to the base document.
+func mergeSpecDocs(base []byte, patch []byte) ([]byte, error) {
+	var baseObj, patchObj map[string]interface{}
+	if err := yaml.Unmarshal(base, &baseObj); err != nil {
+		return nil, err
+	}
+	if err := yaml.Unmarshal(patch, &patchObj); err != nil {
+		return nil, err
+	}
+	return yaml.Marshal(mergePatch(baseObj, patchObj))
+}
+
+// mergePatch merges patch into target: objects are merged recursively, null
+// removes a field, and any other value, including lists, replaces the target.
+func mergePatch(target map[string]interface{}, patch map[string]interface{}) map[string]interface{} {
+	if target == nil {
+		target = make(map[string]interface{})
+	}
+	for key, value := range patch {
+		if value == nil {
+			delete(target, key)
+			continue
+		}
+		patchMap, ok := value.(map[string]interface{})
+		if !ok {
+			target[key] = value
+			continue
+		}
+		targetMap, _ := target[key].(map[string]interface{})
+		target[key] = mergePatch(targetMap, patchMap)
+	}
+	return target
+}
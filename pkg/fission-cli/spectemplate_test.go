@@ -0,0 +1,96 @@
+package fission_cli
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSpecTemplateSubstitute(t *testing.T) {
+	var tmpl *specTemplate
+	b, err := tmpl.substitute([]byte("image: ${IMAGE}"))
+	assert.Nil(t, err)
+	assert.Equal(t, "image: ${IMAGE}", string(b))
+
+	tmpl = &specTemplate{vars: map[string]string{"IMAGE": "fission/node-env:prod"}}
+	b, err = tmpl.substitute([]byte("image: ${IMAGE}\ncmd: echo $${IMAGE}"))
+	assert.Nil(t, err)
+	assert.Equal(t, "image: fission/node-env:prod\ncmd: echo ${IMAGE}", string(b))
+
+	_, err = tmpl.substitute([]byte("replicas: ${REPLICAS_UNDEFINED}"))
+	assert.NotNil(t, err)
+	assert.Contains(t, err.Error(), "REPLICAS_UNDEFINED")
+}
+
+func TestReadEnvFile(t *testing.T) {
+	dir, err := ioutil.TempDir("", "fission-spec-template")
+	assert.Nil(t, err)
+	defer os.RemoveAll(dir)
+
+	envFile := filepath.Join(dir, "prod.env")
+	err = ioutil.WriteFile(envFile, []byte("# prod\n\nIMAGE=fission/node-env:prod\nexport HOST=\"example.com\"\n"), 0644)
+	assert.Nil(t, err)
+
+	vars, err := readEnvFile(envFile)
+	assert.Nil(t, err)
+	assert.Equal(t, map[string]string{"IMAGE": "fission/node-env:prod", "HOST": "example.com"}, vars)
+
+	err = ioutil.WriteFile(envFile, []byte("not a variable\n"), 0644)
+	assert.Nil(t, err)
+	_, err = readEnvFile(envFile)
+	assert.NotNil(t, err)
+}
+
+func TestSpecOverlay(t *testing.T) {
+	specDir, err := ioutil.TempDir("", "fission-spec-overlay")
+	assert.Nil(t, err)
+	defer os.RemoveAll(specDir)
+
+	err = ioutil.WriteFile(filepath.Join(specDir, "env.yaml"), []byte(`apiVersion: fission.io/v1
+kind: Environment
+metadata:
+  name: nodejs
+  namespace: default
+spec:
+  version: 2
+  poolsize: 3
+  runtime:
+    image: ${IMAGE}
+`), 0644)
+	assert.Nil(t, err)
+
+	overlayDir := filepath.Join(specDir, specOverlaysDir, "prod")
+	assert.Nil(t, os.MkdirAll(overlayDir, 0755))
+	err = ioutil.WriteFile(filepath.Join(overlayDir, "env.yaml"), []byte(`kind: Environment
+metadata:
+  name: nodejs
+  namespace: default
+spec:
+  poolsize: 10
+`), 0644)
+	assert.Nil(t, err)
+
+	tmpl := &specTemplate{vars: map[string]string{"IMAGE": "fission/node-env:prod"}}
+
+	// overlays aren't read unless one is chosen
+	fr, err := readTemplatedSpecs(specDir, tmpl)
+	assert.Nil(t, err)
+	assert.Len(t, fr.Environments, 1)
+	assert.Equal(t, 3, fr.Environments[0].Spec.Poolsize)
+	assert.Equal(t, "fission/node-env:prod", fr.Environments[0].Spec.Runtime.Image)
+
+	tmpl.overlay = "prod"
+	fr, err = readTemplatedSpecs(specDir, tmpl)
+	assert.Nil(t, err)
+	assert.Len(t, fr.Environments, 1)
+	assert.Equal(t, 10, fr.Environments[0].Spec.Poolsize)
+	assert.Equal(t, "fission/node-env:prod", fr.Environments[0].Spec.Runtime.Image)
+	assert.Equal(t, 2, fr.Environments[0].Spec.Version)
+
+	tmpl.overlay = "missing"
+	_, err = readTemplatedSpecs(specDir, tmpl)
+	assert.NotNil(t, err)
+}
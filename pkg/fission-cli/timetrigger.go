@@ -42,12 +42,23 @@ func getAPITimeInfo(client *client.Client) time.Time {
 	return serverInfo.ServerTime.CurrentTime
 }
 
-func getCronNextNActivationTime(cronSpec string, serverTime time.Time, round int) error {
+func getCronNextNActivationTime(cronSpec string, timezone string, serverTime time.Time, round int) error {
 	sched, err := cron.Parse(cronSpec)
 	if err != nil {
 		return err
 	}
 
+	// the schedule is evaluated in the time zone of the trigger
+	loc := time.UTC
+	if len(timezone) > 0 {
+		loc, err = time.LoadLocation(timezone)
+		if err != nil {
+			return err
+		}
+	}
+	serverTime = serverTime.In(loc)
+
+	fmt.Printf("Time Zone: \t%v\n", loc)
 	fmt.Printf("Current Server Time: \t%v\n", serverTime.Format(time.RFC3339))
 
 	for i := 0; i < round; i++ {
@@ -83,7 +94,8 @@ func ttCreate(c *cli.Context) error {
 			Namespace: fnNamespace,
 		},
 		Spec: fv1.TimeTriggerSpec{
-			Cron: cronSpec,
+			Cron:     cronSpec,
+			Timezone: c.String("timezone"),
 			FunctionReference: fv1.FunctionReference{
 				Type: fv1.FunctionReferenceTypeFunctionName,
 				Name: fnName,
@@ -104,7 +116,7 @@ func ttCreate(c *cli.Context) error {
 
 	fmt.Printf("trigger '%v' created\n", name)
 
-	err = getCronNextNActivationTime(cronSpec, getAPITimeInfo(client), 1)
+	err = getCronNextNActivationTime(cronSpec, tt.Spec.Timezone, getAPITimeInfo(client), 1)
 	util.CheckErr(err, "pass cron spec examination")
 
 	return err
@@ -135,6 +147,11 @@ func ttUpdate(c *cli.Context) error {
 		updated = true
 	}
 
+	if c.IsSet("timezone") {
+		tt.Spec.Timezone = c.String("timezone")
+		updated = true
+	}
+
 	// TODO : During update, function has to be in the same ns as the trigger object
 	// but since we are not checking this for other triggers too, not sure if we need a check here.
 
@@ -145,7 +162,7 @@ func ttUpdate(c *cli.Context) error {
 	}
 
 	if !updated {
-		log.Fatal("Nothing to update. Use --cron or --function.")
+		log.Fatal("Nothing to update. Use --cron, --timezone or --function.")
 	}
 
 	_, err = client.TimeTriggerUpdate(tt)
@@ -153,7 +170,7 @@ func ttUpdate(c *cli.Context) error {
 
 	fmt.Printf("trigger '%v' updated\n", ttName)
 
-	err = getCronNextNActivationTime(newCron, getAPITimeInfo(client), 1)
+	err = getCronNextNActivationTime(tt.Spec.Cron, tt.Spec.Timezone, getAPITimeInfo(client), 1)
 	util.CheckErr(err, "pass cron spec examination")
 
 	return nil
@@ -181,15 +198,19 @@ func ttList(c *cli.Context) error {
 	client := util.GetApiClient(c.GlobalString("server"))
 	ttNs := c.String("triggerns")
 
-	tts, err := client.TimeTriggerList(ttNs)
+	tts, err := client.TimeTriggerListWithOptions(ttNs, listOptions(c))
 	util.CheckErr(err, "list Time triggers")
 
 	w := tabwriter.NewWriter(os.Stdout, 0, 0, 1, ' ', 0)
 
-	fmt.Fprintf(w, "%v\t%v\t%v\n", "NAME", "CRON", "FUNCTION_NAME")
+	fmt.Fprintf(w, "%v\t%v\t%v\t%v\n", "NAME", "CRON", "TIMEZONE", "FUNCTION_NAME")
 	for _, tt := range tts {
-		fmt.Fprintf(w, "%v\t%v\t%v\n",
-			tt.Metadata.Name, tt.Spec.Cron, tt.Spec.FunctionReference.Name)
+		timezone := tt.Spec.Timezone
+		if len(timezone) == 0 {
+			timezone = "UTC"
+		}
+		fmt.Fprintf(w, "%v\t%v\t%v\t%v\n",
+			tt.Metadata.Name, tt.Spec.Cron, timezone, tt.Spec.FunctionReference.Name)
 	}
 	w.Flush()
 
@@ -205,7 +226,7 @@ func ttTest(c *cli.Context) error {
 		log.Fatal("Need a cron spec like '0 30 * * * *', '@every 1h30m', or '@hourly'; use --cron")
 	}
 
-	err := getCronNextNActivationTime(cronSpec, getAPITimeInfo(client), round)
+	err := getCronNextNActivationTime(cronSpec, c.String("timezone"), getAPITimeInfo(client), round)
 	util.CheckErr(err, "pass cron spec examination")
 
 	return nil
@@ -0,0 +1,117 @@
+/*
+Copyright 2019 The Fission Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package fission_cli
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/urfave/cli"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	fv1 "github.com/fission/fission/pkg/apis/fission.io/v1"
+	"github.com/fission/fission/pkg/fission-cli/log"
+	"github.com/fission/fission/pkg/fission-cli/util"
+	"github.com/fission/fission/pkg/utils"
+)
+
+// tokenCreate prints credentials for a HTTP trigger with auth: a token
+// signed with the key of a JWT trigger, or a new random key for an API
+// key trigger, which has to be added to the secret of the trigger.
+func tokenCreate(c *cli.Context) error {
+	client := util.GetApiClient(c.GlobalString("server"))
+
+	htName := c.String("httptrigger")
+	if len(htName) == 0 {
+		log.Fatal("Need the name of the trigger, use --httptrigger")
+	}
+	ht, err := client.HTTPTriggerGet(&metav1.ObjectMeta{
+		Name:      htName,
+		Namespace: c.String("triggerNamespace"),
+	})
+	util.CheckErr(err, "get HTTP trigger")
+
+	auth := ht.Spec.Auth
+	if auth == nil {
+		log.Fatal(fmt.Sprintf("HTTP trigger '%v' has no auth, use 'fission httptrigger update --auth'", htName))
+	}
+
+	switch auth.Type {
+	case fv1.HTTPTriggerAuthAPIKey:
+		subject := c.String("subject")
+		if len(subject) == 0 {
+			log.Fatal("Need the name of the client the API key is given to, use --subject")
+		}
+		key, err := makeAPIKey()
+		util.CheckErr(err, "generate API key")
+
+		fmt.Println(key)
+		log.Info(fmt.Sprintf("add the key to secret '%v' to enable it, e.g.\n  kubectl -n %v patch secret %v -p '{\"stringData\":{\"%v\":\"%v\"}}'\nand send it in the X-Fission-Api-Key header",
+			auth.Secret, ht.Metadata.Namespace, auth.Secret, subject, key))
+		return nil
+
+	case fv1.HTTPTriggerAuthJWT:
+		secret, err := client.SecretGet(&metav1.ObjectMeta{
+			Name:      auth.Secret,
+			Namespace: ht.Metadata.Namespace,
+		})
+		util.CheckErr(err, fmt.Sprintf("get secret '%v' of the trigger", auth.Secret))
+
+		token, err := makeTriggerToken(auth, secret.Data, c.String("subject"), c.Duration("ttl"), time.Now())
+		util.CheckErr(err, "create token")
+		fmt.Println(token)
+		return nil
+
+	default:
+		log.Fatal(fmt.Sprintf("unknown auth type '%v' of HTTP trigger '%v'", auth.Type, htName))
+	}
+	return nil
+}
+
+// makeTriggerToken returns a JSON Web Token accepted by a JWT trigger,
+// valid for ttl, or without expiry if ttl is 0.
+func makeTriggerToken(auth *fv1.HTTPTriggerAuth, secretData map[string][]byte, subject string, ttl time.Duration, now time.Time) (string, error) {
+	key := secretData[fv1.HTTPTriggerAuthJWTKey]
+	if len(key) == 0 {
+		return "", errors.Errorf("secret '%v' has no '%v' entry with the signing key", auth.Secret, fv1.HTTPTriggerAuthJWTKey)
+	}
+
+	claims := &utils.JWTClaims{
+		Subject:  subject,
+		Issuer:   auth.Issuer,
+		IssuedAt: now.Unix(),
+	}
+	if len(auth.Audience) > 0 {
+		claims.Audience = utils.JWTAudience{auth.Audience}
+	}
+	if ttl > 0 {
+		claims.ExpiresAt = now.Add(ttl).Unix()
+	}
+	return utils.SignJWT(claims, key)
+}
+
+func makeAPIKey() (string, error) {
+	b := make([]byte, 24)
+	_, err := rand.Read(b)
+	if err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
@@ -0,0 +1,48 @@
+package fission_cli
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	fv1 "github.com/fission/fission/pkg/apis/fission.io/v1"
+	"github.com/fission/fission/pkg/utils"
+)
+
+func TestMakeTriggerToken(t *testing.T) {
+	auth := &fv1.HTTPTriggerAuth{
+		Type:     fv1.HTTPTriggerAuthJWT,
+		Secret:   "admin-auth",
+		Issuer:   "ops",
+		Audience: "admin",
+	}
+	key := []byte("s3cr3t")
+	now := time.Now()
+
+	token, err := makeTriggerToken(auth, map[string][]byte{fv1.HTTPTriggerAuthJWTKey: key}, "alice", time.Hour, now)
+	assert.NoError(t, err)
+
+	claims, err := utils.VerifyJWT(token, key, now)
+	assert.NoError(t, err)
+	assert.Equal(t, "alice", claims.Subject)
+	assert.Equal(t, "ops", claims.Issuer)
+	assert.True(t, claims.Audience.Contains("admin"))
+	assert.Equal(t, now.Add(time.Hour).Unix(), claims.ExpiresAt)
+
+	// the token expires after the ttl
+	_, err = utils.VerifyJWT(token, key, now.Add(2*time.Hour))
+	assert.Error(t, err)
+
+	_, err = makeTriggerToken(auth, map[string][]byte{}, "alice", time.Hour, now)
+	assert.Error(t, err)
+}
+
+func TestMakeAPIKey(t *testing.T) {
+	k1, err := makeAPIKey()
+	assert.NoError(t, err)
+	k2, err := makeAPIKey()
+	assert.NoError(t, err)
+	assert.Len(t, k1, 48)
+	assert.NotEqual(t, k1, k2)
+}
@@ -125,7 +125,7 @@ func wList(c *cli.Context) error {
 
 	wNs := c.String("triggerns")
 
-	ws, err := client.WatchList(wNs)
+	ws, err := client.WatchListWithOptions(wNs, listOptions(c))
 	util.CheckErr(err, "list watches")
 
 	w := tabwriter.NewWriter(os.Stdout, 0, 0, 1, ' ', 0)
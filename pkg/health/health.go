@@ -0,0 +1,168 @@
+/*
+Copyright 2019 The Fission Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package health
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+const (
+	// checkTimeout is how long readiness waits for a dependency check.
+	checkTimeout = 5 * time.Second
+
+	statusOK = "ok"
+)
+
+type (
+	// Check returns an error if a dependency of a component is unavailable.
+	Check func() error
+
+	// Checker serves the liveness and readiness endpoints of a component.
+	// Liveness only tells the component is running, readiness runs the
+	// dependency checks of the component and reports each of them, so that
+	// the degraded dependency can be told.
+	Checker struct {
+		component string
+		lock      sync.RWMutex
+		checks    map[string]Check
+	}
+
+	// Status is the readiness of a component, Checks maps the names of the
+	// dependency checks to "ok" or the error of the check.
+	Status struct {
+		Component string            `json:"component"`
+		Ready     bool              `json:"ready"`
+		Checks    map[string]string `json:"checks"`
+	}
+)
+
+// MakeChecker returns a checker of the given component without checks.
+func MakeChecker(component string) *Checker {
+	return &Checker{
+		component: component,
+		checks:    make(map[string]Check),
+	}
+}
+
+// AddCheck adds a dependency check run on readiness.
+func (c *Checker) AddCheck(name string, check Check) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	c.checks[name] = check
+}
+
+// Run runs the dependency checks concurrently, a check which doesn't
+// return in time fails.
+func (c *Checker) Run() Status {
+	c.lock.RLock()
+	checks := make(map[string]Check, len(c.checks))
+	for name, check := range c.checks {
+		checks[name] = check
+	}
+	c.lock.RUnlock()
+
+	status := Status{
+		Component: c.component,
+		Ready:     true,
+		Checks:    make(map[string]string, len(checks)),
+	}
+	var lock sync.Mutex
+	var wg sync.WaitGroup
+	for name, check := range checks {
+		wg.Add(1)
+		go func(name string, check Check) {
+			defer wg.Done()
+			result := make(chan error, 1)
+			go func() {
+				result <- check()
+			}()
+			var err error
+			select {
+			case err = <-result:
+			case <-time.After(checkTimeout):
+				err = fmt.Errorf("no result in %v", checkTimeout)
+			}
+
+			lock.Lock()
+			defer lock.Unlock()
+			if err != nil {
+				status.Ready = false
+				status.Checks[name] = err.Error()
+			} else {
+				status.Checks[name] = statusOK
+			}
+		}(name, check)
+	}
+	wg.Wait()
+	return status
+}
+
+// HealthzHandler serves liveness, the component is alive as long as it
+// serves requests.
+func (c *Checker) HealthzHandler(w http.ResponseWriter, r *http.Request) {
+	w.WriteHeader(http.StatusOK)
+	w.Write([]byte(statusOK))
+}
+
+// ReadyzHandler serves readiness, with the status of the checks in the
+// body. It responds 503 Service Unavailable if a check failed.
+func (c *Checker) ReadyzHandler(w http.ResponseWriter, r *http.Request) {
+	status := c.Run()
+	body, err := json.Marshal(status)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	if !status.Ready {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}
+	w.Write(body)
+}
+
+// Serve serves /healthz and /readyz at the given address, for the
+// components without an HTTP server of their own.
+func (c *Checker) Serve(logger *zap.Logger, addr string) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/healthz", c.HealthzHandler)
+	mux.HandleFunc("/readyz", c.ReadyzHandler)
+	err := http.ListenAndServe(addr, mux)
+	logger.Fatal("done listening", zap.Error(err))
+}
+
+// HTTPCheck returns a check of a service, which succeeds if GET of the
+// given URL responds with 2xx, e.g. the /healthz of another component.
+func HTTPCheck(url string) Check {
+	client := &http.Client{Timeout: checkTimeout}
+	return func() error {
+		resp, err := client.Get(url)
+		if err != nil {
+			return err
+		}
+		defer resp.Body.Close()
+		if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+			return fmt.Errorf("%v responded with status %v", url, resp.Status)
+		}
+		return nil
+	}
+}
@@ -0,0 +1,60 @@
+/*
+Copyright 2019 The Fission Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package health
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReadyz(t *testing.T) {
+	checker := MakeChecker("test")
+	checker.AddCheck("good", func() error { return nil })
+
+	rec := httptest.NewRecorder()
+	checker.ReadyzHandler(rec, httptest.NewRequest(http.MethodGet, "/readyz", nil))
+	assert.Equal(t, http.StatusOK, rec.Code)
+
+	checker.AddCheck("bad", func() error { return errors.New("unreachable") })
+	rec = httptest.NewRecorder()
+	checker.ReadyzHandler(rec, httptest.NewRequest(http.MethodGet, "/readyz", nil))
+	assert.Equal(t, http.StatusServiceUnavailable, rec.Code)
+
+	var status Status
+	err := json.Unmarshal(rec.Body.Bytes(), &status)
+	assert.Nil(t, err)
+	assert.Equal(t, "test", status.Component)
+	assert.False(t, status.Ready)
+	assert.Equal(t, map[string]string{"good": "ok", "bad": "unreachable"}, status.Checks)
+}
+
+func TestHTTPCheck(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/healthz" {
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer ts.Close()
+
+	assert.Nil(t, HTTPCheck(ts.URL+"/healthz")())
+	assert.NotNil(t, HTTPCheck(ts.URL+"/missing")())
+}
@@ -42,6 +42,11 @@ type (
 	ServerInfo struct {
 		Build      BuildMeta `json:"Build,omitempty"`
 		ServerTime Time      `json:"ServerTime,omitempty"`
+
+		// ArchiveLiteralSizeLimit is the max size in bytes of an archive
+		// stored as a literal in a package, larger archives must be
+		// uploaded to the storage service.
+		ArchiveLiteralSizeLimit int64 `json:"ArchiveLiteralSizeLimit,omitempty"`
 	}
 )
 
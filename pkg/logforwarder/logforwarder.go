@@ -0,0 +1,309 @@
+/*
+Copyright 2019 The Fission Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package logforwarder
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"os"
+	"os/signal"
+	"strconv"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	influxdbClient "github.com/influxdata/influxdb/client/v2"
+	"github.com/pkg/errors"
+	"go.uber.org/zap"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+
+	"github.com/fission/fission/pkg/crd"
+	"github.com/fission/fission/pkg/fission-cli/logdb"
+	"github.com/fission/fission/pkg/types"
+)
+
+const (
+	// measurement is the influxdb measurement of the forwarded logs, the
+	// CLI queries all the measurements starting with "log".
+	measurement = "log.sidecar"
+
+	flushInterval   = 5 * time.Second
+	reconnectDelay  = 3 * time.Second
+	maxPendingLines = 10000
+	maxLineSize     = 1024 * 1024
+)
+
+type (
+	// logForwarder follows the logs of a container of its own pod and
+	// writes them to the log database, in the same format as the logger
+	// DaemonSet.
+	logForwarder struct {
+		logger           *zap.Logger
+		kubernetesClient *kubernetes.Clientset
+		httpClient       *http.Client
+
+		podName      string
+		podNamespace string
+		container    string
+		endpoint     string
+
+		// labels are the function labels of the pod, pool pods get them
+		// once they are specialized.
+		labels map[string]string
+
+		// containerID is the ID of the followed container, reported as
+		// the container of the log entries.
+		containerID string
+
+		lock    sync.Mutex
+		pending []logLine
+	}
+
+	logLine struct {
+		time    time.Time
+		message string
+	}
+)
+
+func makeLogForwarder(logger *zap.Logger, kubernetesClient *kubernetes.Clientset) (*logForwarder, error) {
+	f := &logForwarder{
+		logger:           logger,
+		kubernetesClient: kubernetesClient,
+		httpClient:       &http.Client{Timeout: 10 * time.Second},
+		podName:          os.Getenv(types.LogForwarderPodNameEnv),
+		podNamespace:     os.Getenv(types.LogForwarderPodNamespaceEnv),
+		container:        os.Getenv(types.LogForwarderContainerEnv),
+		endpoint:         os.Getenv(types.LogForwarderEndpointEnv),
+	}
+	if len(f.podName) == 0 || len(f.podNamespace) == 0 || len(f.container) == 0 || len(f.endpoint) == 0 {
+		return nil, fmt.Errorf("%v, %v, %v and %v must be set",
+			types.LogForwarderPodNameEnv, types.LogForwarderPodNamespaceEnv,
+			types.LogForwarderContainerEnv, types.LogForwarderEndpointEnv)
+	}
+	if _, err := url.Parse(f.endpoint); err != nil {
+		return nil, errors.Wrapf(err, "invalid %v", types.LogForwarderEndpointEnv)
+	}
+	return f, nil
+}
+
+// follow streams the logs of the container, it reconnects when the stream
+// ends, e.g. when the container restarts, and resumes after the last line
+// read.
+func (f *logForwarder) follow() {
+	var lastTime time.Time
+	for {
+		opts := &corev1.PodLogOptions{
+			Container:  f.container,
+			Follow:     true,
+			Timestamps: true,
+		}
+		if !lastTime.IsZero() {
+			sinceTime := metav1.NewTime(lastTime)
+			opts.SinceTime = &sinceTime
+		}
+
+		stream, err := f.kubernetesClient.CoreV1().Pods(f.podNamespace).GetLogs(f.podName, opts).Stream()
+		if err != nil {
+			f.logger.Error("error streaming container logs", zap.Error(err), zap.String("container", f.container))
+			time.Sleep(reconnectDelay)
+			continue
+		}
+
+		scanner := bufio.NewScanner(stream)
+		scanner.Buffer(make([]byte, 64*1024), maxLineSize)
+		for scanner.Scan() {
+			line, err := parseLogLine(scanner.Text())
+			if err != nil {
+				f.logger.Debug("skipping log line", zap.Error(err))
+				continue
+			}
+			// the since time has a precision of a second, so the lines
+			// read before reconnecting are streamed again
+			if !line.time.After(lastTime) {
+				continue
+			}
+			lastTime = line.time
+			f.add(line)
+		}
+		if err := scanner.Err(); err != nil {
+			f.logger.Error("error reading container logs", zap.Error(err), zap.String("container", f.container))
+		}
+		stream.Close()
+		time.Sleep(reconnectDelay)
+	}
+}
+
+// parseLogLine parses a log line streamed with timestamps, which is the
+// RFC3339 time of the line followed by a space and the message.
+func parseLogLine(text string) (logLine, error) {
+	parts := strings.SplitN(text, " ", 2)
+	t, err := time.Parse(time.RFC3339Nano, parts[0])
+	if err != nil {
+		return logLine{}, errors.Wrap(err, "error parsing log line timestamp")
+	}
+	line := logLine{time: t}
+	if len(parts) == 2 {
+		line.message = parts[1]
+	}
+	return line, nil
+}
+
+func (f *logForwarder) add(line logLine) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	f.pending = append(f.pending, line)
+	// drop the oldest lines if the log database can't keep up
+	if len(f.pending) > maxPendingLines {
+		f.pending = f.pending[len(f.pending)-maxPendingLines:]
+	}
+}
+
+// flush writes the pending lines to the log database. The lines are kept
+// until the pod is specialized, and if the write fails.
+func (f *logForwarder) flush() {
+	f.lock.Lock()
+	lines := f.pending
+	f.pending = nil
+	f.lock.Unlock()
+
+	if len(lines) == 0 {
+		return
+	}
+
+	err := f.loadPodLabels()
+	if err == nil {
+		err = f.write(lines)
+	}
+	if err != nil {
+		f.logger.Debug("keeping logs to forward", zap.Error(err), zap.Int("lines", len(lines)))
+		f.lock.Lock()
+		f.pending = append(lines, f.pending...)
+		if len(f.pending) > maxPendingLines {
+			f.pending = f.pending[len(f.pending)-maxPendingLines:]
+		}
+		f.lock.Unlock()
+	}
+}
+
+// loadPodLabels gets the function labels of the pod, until it's specialized.
+func (f *logForwarder) loadPodLabels() error {
+	if f.labels != nil {
+		return nil
+	}
+
+	pod, err := f.kubernetesClient.CoreV1().Pods(f.podNamespace).Get(f.podName, metav1.GetOptions{})
+	if err != nil {
+		return errors.Wrap(err, "error getting pod")
+	}
+	if len(pod.Labels[types.FUNCTION_UID]) == 0 {
+		return errors.New("pod isn't specialized yet")
+	}
+	for _, status := range pod.Status.ContainerStatuses {
+		if status.Name == f.container {
+			// e.g. docker://f4ca66baaa71...
+			parts := strings.SplitN(status.ContainerID, "://", 2)
+			f.containerID = parts[len(parts)-1]
+		}
+	}
+	f.labels = pod.Labels
+	return nil
+}
+
+// write sends the lines to the log database in the influxdb line protocol.
+func (f *logForwarder) write(lines []logLine) error {
+	body, err := f.makePoints(lines)
+	if err != nil {
+		return err
+	}
+
+	q := url.Values{}
+	q.Set("db", logdb.INFLUXDB_DATABASE)
+	resp, err := f.httpClient.Post(f.endpoint+"?"+q.Encode(), "text/plain", bytes.NewReader(body))
+	if err != nil {
+		return errors.Wrap(err, "error writing logs")
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		msg, _ := ioutil.ReadAll(resp.Body)
+		return fmt.Errorf("error writing logs: %v: %v", resp.Status, string(msg))
+	}
+	return nil
+}
+
+func (f *logForwarder) makePoints(lines []logLine) ([]byte, error) {
+	var buf bytes.Buffer
+	for i, line := range lines {
+		tags := map[string]string{
+			"kubernetes_labels_functionUid": f.labels[types.FUNCTION_UID],
+			// lines of the same time are different points
+			"_seq": strconv.Itoa(i),
+		}
+		fields := map[string]interface{}{
+			"log":                            line.message,
+			"kubernetes_pod_name":            f.podName,
+			"kubernetes_namespace_name":      f.podNamespace,
+			"kubernetes_docker_id":           f.containerID,
+			"kubernetes_labels_functionName": f.labels[types.FUNCTION_NAME],
+		}
+		pt, err := influxdbClient.NewPoint(measurement, tags, fields, line.time)
+		if err != nil {
+			return nil, errors.Wrap(err, "error making log point")
+		}
+		buf.WriteString(pt.String())
+		buf.WriteByte('\n')
+	}
+	return buf.Bytes(), nil
+}
+
+// Start runs the log forwarder sidecar of a function pod, it writes the
+// pending logs once more when the pod is terminated.
+func Start(logger *zap.Logger) error {
+	_, kubernetesClient, _, err := crd.MakeFissionClient()
+	if err != nil {
+		return errors.Wrap(err, "error making kubernetes client")
+	}
+
+	f, err := makeLogForwarder(logger.Named("log_forwarder"), kubernetesClient)
+	if err != nil {
+		return err
+	}
+
+	go f.follow()
+
+	sigs := make(chan os.Signal, 1)
+	signal.Notify(sigs, syscall.SIGTERM, os.Interrupt)
+
+	ticker := time.NewTicker(flushInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			f.flush()
+		case <-sigs:
+			f.flush()
+			return nil
+		}
+	}
+}
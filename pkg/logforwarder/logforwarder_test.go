@@ -0,0 +1,67 @@
+/*
+Copyright 2019 The Fission Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package logforwarder
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/fission/fission/pkg/types"
+)
+
+func TestParseLogLine(t *testing.T) {
+	line, err := parseLogLine("2019-11-20T08:09:10.123456789Z hello world")
+	assert.NoError(t, err)
+	assert.Equal(t, time.Date(2019, 11, 20, 8, 9, 10, 123456789, time.UTC), line.time.UTC())
+	assert.Equal(t, "hello world", line.message)
+
+	line, err = parseLogLine("2019-11-20T08:09:10Z")
+	assert.NoError(t, err)
+	assert.Equal(t, "", line.message)
+
+	_, err = parseLogLine("hello world")
+	assert.Error(t, err)
+}
+
+func TestMakePoints(t *testing.T) {
+	f := &logForwarder{
+		podName:      "pod",
+		podNamespace: "fission-function",
+		containerID:  "f4ca66baaa71",
+		labels: map[string]string{
+			types.FUNCTION_UID:  "uid",
+			types.FUNCTION_NAME: "hello",
+		},
+	}
+	now := time.Now()
+	body, err := f.makePoints([]logLine{
+		{time: now, message: "first line"},
+		{time: now, message: "second line"},
+	})
+	assert.NoError(t, err)
+
+	points := strings.Split(strings.TrimSuffix(string(body), "\n"), "\n")
+	assert.Len(t, points, 2)
+	// points of the same time differ by the sequence tag
+	assert.True(t, strings.HasPrefix(points[0], "log.sidecar,_seq=0,kubernetes_labels_functionUid=uid "))
+	assert.True(t, strings.HasPrefix(points[1], "log.sidecar,_seq=1,kubernetes_labels_functionUid=uid "))
+	assert.Contains(t, points[0], `log="first line"`)
+	assert.Contains(t, points[0], `kubernetes_labels_functionName="hello"`)
+}
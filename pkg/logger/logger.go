@@ -25,7 +25,6 @@ import (
 	"time"
 
 	"github.com/fission/fission/pkg/types"
-	"github.com/fission/fission/pkg/utils"
 	"go.uber.org/zap"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -50,7 +49,9 @@ func makePodLoggerController(zapLogger *zap.Logger, k8sClientSet *kubernetes.Cli
 		k8sCache.ResourceEventHandlerFuncs{
 			AddFunc: func(obj interface{}) {
 				pod := obj.(*corev1.Pod)
-				if !isValidFunctionPodOnNode(pod) || !utils.IsReadyPod(pod) {
+				// not ready pods are included, the logs of crash looping
+				// function pods are the ones needed the most
+				if !isValidFunctionPodOnNode(pod) {
 					return
 				}
 				err := createLogSymlinks(zapLogger, pod)
@@ -62,7 +63,9 @@ func makePodLoggerController(zapLogger *zap.Logger, k8sClientSet *kubernetes.Cli
 			},
 			UpdateFunc: func(_, obj interface{}) {
 				pod := obj.(*corev1.Pod)
-				if !isValidFunctionPodOnNode(pod) || !utils.IsReadyPod(pod) {
+				// not ready pods are included, the logs of crash looping
+				// function pods are the ones needed the most
+				if !isValidFunctionPodOnNode(pod) {
 					return
 				}
 				err := createLogSymlinks(zapLogger, pod)
@@ -81,27 +84,40 @@ func makePodLoggerController(zapLogger *zap.Logger, k8sClientSet *kubernetes.Cli
 
 func createLogSymlinks(zapLogger *zap.Logger, pod *corev1.Pod) error {
 	for _, container := range pod.Status.ContainerStatuses {
-		containerUID, err := parseContainerString(container.ContainerID)
-		if err != nil {
-			zapLogger.Error("error parsing container uid",
-				zap.String("container", container.Name),
-				zap.String("pod", pod.Name),
-				zap.String("namespace", pod.Namespace),
-				zap.Error(err))
-			continue
+		containerIDs := []string{container.ContainerID}
+		// the previous container of a restarted pod, its log file is kept
+		// by the kubelet until the next restart
+		if terminated := container.LastTerminationState.Terminated; terminated != nil && len(terminated.ContainerID) > 0 {
+			containerIDs = append(containerIDs, terminated.ContainerID)
 		}
-		containerLogPath := getLogPath(originalContainerLogPath, pod.Name, pod.Namespace, container.Name, containerUID)
-		symlinkLogPath := getLogPath(fissionSymlinkPath, pod.Name, pod.Namespace, container.Name, containerUID)
 
-		// check whether a symlink exists, if yes then ignore it
-		if _, err := os.Stat(symlinkLogPath); os.IsNotExist(err) {
-			err := os.Symlink(containerLogPath, symlinkLogPath)
+		for _, containerID := range containerIDs {
+			if len(containerID) == 0 {
+				// the container hasn't been created yet
+				continue
+			}
+			containerUID, err := parseContainerString(containerID)
 			if err != nil {
-				zapLogger.Error("error creating symlink",
+				zapLogger.Error("error parsing container uid",
 					zap.String("container", container.Name),
 					zap.String("pod", pod.Name),
 					zap.String("namespace", pod.Namespace),
 					zap.Error(err))
+				continue
+			}
+			containerLogPath := getLogPath(originalContainerLogPath, pod.Name, pod.Namespace, container.Name, containerUID)
+			symlinkLogPath := getLogPath(fissionSymlinkPath, pod.Name, pod.Namespace, container.Name, containerUID)
+
+			// check whether a symlink exists, if yes then ignore it
+			if _, err := os.Stat(symlinkLogPath); os.IsNotExist(err) {
+				err := os.Symlink(containerLogPath, symlinkLogPath)
+				if err != nil {
+					zapLogger.Error("error creating symlink",
+						zap.String("container", container.Name),
+						zap.String("pod", pod.Name),
+						zap.String("namespace", pod.Namespace),
+						zap.Error(err))
+				}
 			}
 		}
 	}
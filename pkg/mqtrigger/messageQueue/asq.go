@@ -19,18 +19,14 @@ package messageQueue
 import (
 	"bytes"
 	"encoding/base64"
-	"fmt"
 	"io/ioutil"
 	"net/http"
 	"os"
 	"strconv"
-	"strings"
 	"sync"
 	"time"
 
 	"github.com/Azure/azure-sdk-for-go/storage"
-	"github.com/fission/fission/pkg/types"
-	"github.com/fission/fission/pkg/utils"
 	"github.com/pkg/errors"
 	"go.uber.org/zap"
 
@@ -63,6 +59,7 @@ type AzureStorageConnection struct {
 
 // AzureQueueSubscription represents an Azure storage message queue subscription.
 type AzureQueueSubscription struct {
+	trigger         *fv1.MessageQueueTrigger
 	queue           AzureQueue
 	queueName       string
 	outputQueueName string
@@ -204,21 +201,20 @@ func newAzureStorageConnection(logger *zap.Logger, routerURL string, config Mess
 func (asc AzureStorageConnection) subscribe(trigger *fv1.MessageQueueTrigger) (messageQueueSubscription, error) {
 	asc.logger.Info("subscribing to Azure storage queue", zap.String("queue", trigger.Spec.Topic))
 
-	if trigger.Spec.FunctionReference.Type != types.FunctionReferenceTypeFunctionName {
-		return nil, fmt.Errorf("unsupported function reference type (%v) for trigger %q", trigger.Spec.FunctionReference.Type, trigger.Metadata.Name)
+	fnPath, err := functionPath(trigger)
+	if err != nil {
+		return nil, err
 	}
 
 	subscription := &AzureQueueSubscription{
+		trigger:         trigger,
 		queue:           asc.service.GetQueue(trigger.Spec.Topic),
 		queueName:       trigger.Spec.Topic,
 		outputQueueName: trigger.Spec.ResponseTopic,
-		// with the addition of multi-tenancy, the users can create functions in any namespace. however,
-		// the triggers can only be created in the same namespace as the function.
-		// so essentially, function namespace = trigger namespace.
-		functionURL: asc.routerURL + "/" + strings.TrimPrefix(utils.UrlForFunction(trigger.Spec.FunctionReference.Name, trigger.Metadata.Namespace), "/"),
-		contentType: trigger.Spec.ContentType,
-		unsubscribe: make(chan bool),
-		done:        make(chan bool),
+		functionURL:     asc.routerURL + "/" + fnPath,
+		contentType:     trigger.Spec.ContentType,
+		unsubscribe:     make(chan bool),
+		done:            make(chan bool),
 	}
 
 	go runAzureQueueSubscription(asc, subscription)
@@ -303,10 +299,17 @@ func pollAzureQueueSubscription(conn AzureStorageConnection, sub *AzureQueueSubs
 func invokeTriggeredFunction(conn AzureStorageConnection, sub *AzureQueueSubscription, message AzureMessage) {
 	defer message.Delete(nil)
 
+	start := time.Now()
+	success := false
+	defer func() {
+		observeMessage(sub.trigger, start, success)
+	}()
+
 	conn.logger.Info("making HTTP request to invoke function", zap.String("function_url", sub.functionURL))
 
 	for i := 0; i <= AzureQueueRetryLimit; i++ {
 		if i > 0 {
+			observeRetry(sub.trigger)
 			conn.logger.Info("retrying function invocation", zap.Int("retry", i), zap.String("function_url", sub.functionURL))
 		}
 		request, err := http.NewRequest("POST", sub.functionURL, bytes.NewReader(message.Bytes()))
@@ -326,6 +329,7 @@ func invokeTriggeredFunction(conn AzureStorageConnection, sub *AzureQueueSubscri
 
 		response, err := conn.httpClient.Do(request)
 		if err != nil {
+			observeError(sub.trigger, errorTypeInvocation)
 			conn.logger.Error("sending function invocation request failed", zap.Error(err), zap.String("function_url", sub.functionURL))
 			continue
 		}
@@ -333,11 +337,13 @@ func invokeTriggeredFunction(conn AzureStorageConnection, sub *AzureQueueSubscri
 
 		body, err := ioutil.ReadAll(response.Body)
 		if err != nil {
+			observeError(sub.trigger, errorTypeInvocation)
 			conn.logger.Error("failed to read response body from function invocation", zap.Error(err), zap.String("function_url", sub.functionURL))
 			continue
 		}
 
 		if response.StatusCode < 200 || response.StatusCode >= 300 {
+			observeError(sub.trigger, errorTypeInvocation)
 			conn.logger.Error("function invocation request returned a failure status code",
 				zap.String("function_url", sub.functionURL),
 				zap.String("body", string(body)),
@@ -345,10 +351,12 @@ func invokeTriggeredFunction(conn AzureStorageConnection, sub *AzureQueueSubscri
 			continue
 		}
 
+		success = true
 		if len(sub.outputQueueName) > 0 {
 			outputQueue := conn.service.GetQueue(sub.outputQueueName)
 			err = outputQueue.Create(nil)
 			if err != nil {
+				observeError(sub.trigger, errorTypePublish)
 				conn.logger.Error("failed to create output queue",
 					zap.Error(err),
 					zap.String("output_queue", sub.outputQueueName),
@@ -359,6 +367,7 @@ func invokeTriggeredFunction(conn AzureStorageConnection, sub *AzureQueueSubscri
 			outputMessage := outputQueue.NewMessage(string(body))
 			err = outputMessage.Put(nil)
 			if err != nil {
+				observeError(sub.trigger, errorTypePublish)
 				conn.logger.Error("failed to post response body from function invocation to output queue",
 					zap.String("output_queue", sub.outputQueueName),
 					zap.String("function_url", sub.functionURL))
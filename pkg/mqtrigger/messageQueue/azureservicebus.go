@@ -0,0 +1,356 @@
+/*
+Copyright 2019 The Fission Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package messageQueue
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	servicebus "github.com/Azure/azure-service-bus-go"
+	"github.com/pkg/errors"
+	"go.uber.org/zap"
+	"k8s.io/client-go/kubernetes"
+
+	fv1 "github.com/fission/fission/pkg/apis/fission.io/v1"
+)
+
+const (
+	// A session is released once no message of it arrived for this long,
+	// so that the trigger moves on to the other sessions.
+	azureServiceBusSessionIdleTimeout = 10 * time.Second
+
+	// Wait before accepting a session again after a failure, e.g. when
+	// no session has messages.
+	azureServiceBusSessionRetryInterval = time.Second
+)
+
+type (
+	AzureServiceBus struct {
+		logger     *zap.Logger
+		routerUrl  string
+		connStr    string
+		kubeClient kubernetes.Interface
+	}
+
+	// azureServiceBusSubscription holds the namespace client of a trigger,
+	// cancelling the context stops receiving messages.
+	azureServiceBusSubscription struct {
+		receiver azureServiceBusReceiver
+		senders  azureServiceBusSenders
+		cancel   context.CancelFunc
+		done     chan struct{}
+	}
+
+	// azureServiceBusReceiver is the queue or the topic subscription a
+	// trigger receives messages from.
+	azureServiceBusReceiver interface {
+		Receive(ctx context.Context, handler servicebus.Handler) error
+		Close(ctx context.Context) error
+	}
+
+	// azureServiceBusSenders are the queues or topics the responses and the
+	// errors of the function are sent to, nil if not set. Service Bus sends
+	// to queues and topics alike by their entity path.
+	azureServiceBusSenders struct {
+		response *servicebus.Queue
+		error    *servicebus.Queue
+	}
+
+	// azureServiceBusSessionHandler receives the messages of a session, and
+	// closes the session once it's idle.
+	azureServiceBusSessionHandler struct {
+		handle func(ctx context.Context, msg *servicebus.Message) error
+		timer  *time.Timer
+	}
+)
+
+func makeAzureServiceBusMessageQueue(logger *zap.Logger, kubeClient kubernetes.Interface, routerUrl string, mqCfg MessageQueueConfig) (MessageQueue, error) {
+	if len(routerUrl) == 0 {
+		return nil, errors.New("the router URL is empty")
+	}
+
+	// the triggers may have a connection string of their own, so it's
+	// optional here
+	asb := AzureServiceBus{
+		logger:     logger.Named("azure_service_bus"),
+		routerUrl:  routerUrl,
+		connStr:    mqCfg.Url,
+		kubeClient: kubeClient,
+	}
+
+	logger.Info("created azure service bus queue", zap.Bool("default_connection_string", len(mqCfg.Url) > 0))
+
+	return asb, nil
+}
+
+func isTopicValidForAzureServiceBus(topic string) bool {
+	return fv1.IsValidAzureServiceBusName(topic)
+}
+
+// connectionString returns the connection string of the namespace of a
+// trigger, from the secret of the trigger if it has one, or the one
+// configured for the mqtrigger deployment otherwise.
+func (asb AzureServiceBus) connectionString(trigger *fv1.MessageQueueTrigger) (string, error) {
+	secret, err := getTriggerSecret(asb.kubeClient, trigger)
+	if err != nil {
+		return "", err
+	}
+	if secret != nil {
+		connStr, ok := secret[secretAzureConnectionStringKey]
+		if !ok {
+			return "", errors.Errorf("no %q key in the secret of trigger %v", secretAzureConnectionStringKey, trigger.Metadata.Name)
+		}
+		return string(connStr), nil
+	}
+	if len(asb.connStr) == 0 {
+		return "", errors.Errorf("no connection string for trigger %v, set a secret in the trigger or MESSAGE_QUEUE_URL of the mqtrigger deployment", trigger.Metadata.Name)
+	}
+	return asb.connStr, nil
+}
+
+func (asb AzureServiceBus) subscribe(trigger *fv1.MessageQueueTrigger) (messageQueueSubscription, error) {
+	asb.logger.Info("inside azure service bus subscribe", zap.String("trigger", trigger.Metadata.Name))
+
+	connStr, err := asb.connectionString(trigger)
+	if err != nil {
+		return nil, err
+	}
+	ns, err := servicebus.NewNamespace(servicebus.NamespaceWithConnectionString(connStr))
+	if err != nil {
+		return nil, errors.Wrapf(err, "error connecting azure service bus trigger %v", trigger.Metadata.Name)
+	}
+
+	config := trigger.Spec.AzureServiceBus
+	if config == nil {
+		config = &fv1.AzureServiceBusConfig{}
+	}
+
+	var receiver azureServiceBusReceiver
+	var receiveSession func(ctx context.Context, handler servicebus.SessionHandler) error
+	if len(config.Subscription) > 0 {
+		topic, err := ns.NewTopic(trigger.Spec.Topic)
+		if err != nil {
+			return nil, errors.Wrapf(err, "error opening topic %v", trigger.Spec.Topic)
+		}
+		sub, err := topic.NewSubscription(config.Subscription)
+		if err != nil {
+			return nil, errors.Wrapf(err, "error opening subscription %v of topic %v", config.Subscription, trigger.Spec.Topic)
+		}
+		receiver = sub
+		receiveSession = func(ctx context.Context, handler servicebus.SessionHandler) error {
+			// nil accepts the next session with messages
+			return sub.NewSession(nil).ReceiveOne(ctx, handler)
+		}
+	} else {
+		queue, err := ns.NewQueue(trigger.Spec.Topic)
+		if err != nil {
+			return nil, errors.Wrapf(err, "error opening queue %v", trigger.Spec.Topic)
+		}
+		receiver = queue
+		receiveSession = func(ctx context.Context, handler servicebus.SessionHandler) error {
+			return queue.NewSession(nil).ReceiveOne(ctx, handler)
+		}
+	}
+
+	var senders azureServiceBusSenders
+	if len(trigger.Spec.ResponseTopic) > 0 {
+		senders.response, err = ns.NewQueue(trigger.Spec.ResponseTopic)
+		if err != nil {
+			return nil, errors.Wrapf(err, "error opening response topic %v", trigger.Spec.ResponseTopic)
+		}
+	}
+	if len(trigger.Spec.ErrorTopic) > 0 {
+		senders.error, err = ns.NewQueue(trigger.Spec.ErrorTopic)
+		if err != nil {
+			return nil, errors.Wrapf(err, "error opening error topic %v", trigger.Spec.ErrorTopic)
+		}
+	}
+
+	asb.logger.Info("created a new subscriber",
+		zap.String("input topic", trigger.Spec.Topic),
+		zap.String("subscription", config.Subscription),
+		zap.Bool("sessions", config.Sessions),
+		zap.String("output topic", trigger.Spec.ResponseTopic),
+		zap.String("error topic", trigger.Spec.ErrorTopic),
+		zap.String("trigger name", trigger.Metadata.Name),
+		zap.String("function namespace", trigger.Metadata.Namespace),
+		zap.String("function name", trigger.Spec.FunctionReference.Name))
+
+	handle := func(ctx context.Context, msg *servicebus.Message) error {
+		asb.logger.Debug("calling message handler", zap.String("message", string(msg.Data)))
+		azureServiceBusMsgHandler(ctx, &asb, senders, trigger, msg)
+		return nil
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		if !config.Sessions {
+			err := receiver.Receive(ctx, servicebus.HandlerFunc(handle))
+			if err != nil && ctx.Err() == nil {
+				asb.logger.Error("error receiving messages", zap.Error(err), zap.String("trigger", trigger.Metadata.Name))
+			}
+			return
+		}
+
+		// Sessions are processed one at a time, the messages of a session
+		// in order.
+		for ctx.Err() == nil {
+			err := receiveSession(ctx, &azureServiceBusSessionHandler{handle: handle})
+			if err != nil && ctx.Err() == nil {
+				asb.logger.Debug("error receiving session", zap.Error(err), zap.String("trigger", trigger.Metadata.Name))
+				select {
+				case <-ctx.Done():
+				case <-time.After(azureServiceBusSessionRetryInterval):
+				}
+			}
+		}
+	}()
+
+	return azureServiceBusSubscription{
+		receiver: receiver,
+		senders:  senders,
+		cancel:   cancel,
+		done:     done,
+	}, nil
+}
+
+func (asb AzureServiceBus) unsubscribe(subscription messageQueueSubscription) error {
+	s := subscription.(azureServiceBusSubscription)
+	s.cancel()
+	<-s.done
+
+	ctx := context.Background()
+	for _, q := range []*servicebus.Queue{s.senders.response, s.senders.error} {
+		if q != nil {
+			q.Close(ctx)
+		}
+	}
+	return s.receiver.Close(ctx)
+}
+
+func (h *azureServiceBusSessionHandler) Start(ms *servicebus.MessageSession) error {
+	h.timer = time.AfterFunc(azureServiceBusSessionIdleTimeout, ms.Close)
+	return nil
+}
+
+func (h *azureServiceBusSessionHandler) Handle(ctx context.Context, msg *servicebus.Message) error {
+	// the session isn't idle while the function runs
+	h.timer.Stop()
+	defer h.timer.Reset(azureServiceBusSessionIdleTimeout)
+	return h.handle(ctx, msg)
+}
+
+func (h *azureServiceBusSessionHandler) End() {
+	h.timer.Stop()
+}
+
+// azureServiceBusHeaders returns the request headers of the user
+// properties of a message.
+func azureServiceBusHeaders(msg *servicebus.Message) map[string]string {
+	headers := make(map[string]string, len(msg.UserProperties))
+	for k, v := range msg.UserProperties {
+		headers[k] = fmt.Sprint(v)
+	}
+	return headers
+}
+
+// azureServiceBusReply returns a response or an error message sent for a
+// message, in the session of the message if it has one.
+func azureServiceBusReply(msg *servicebus.Message, data []byte, header http.Header) *servicebus.Message {
+	reply := servicebus.NewMessage(data)
+	reply.SessionID = msg.SessionID
+	if len(header) > 0 {
+		reply.UserProperties = make(map[string]interface{}, len(header))
+		for k, v := range header {
+			reply.UserProperties[k] = strings.Join(v, ",")
+		}
+		reply.ContentType = header.Get("Content-Type")
+	}
+	return reply
+}
+
+// azureServiceBusMsgHandler invokes the function of the trigger with a
+// message. The message is completed if the function succeeds, and
+// dead-lettered with the error otherwise, so the dead-letter queue of the
+// queue or subscription holds the failed messages along with the error
+// topic.
+func azureServiceBusMsgHandler(ctx context.Context, asb *AzureServiceBus, senders azureServiceBusSenders, trigger *fv1.MessageQueueTrigger, msg *servicebus.Message) {
+	start := time.Now()
+	success := false
+	defer func() {
+		observeMessage(trigger, start, success)
+	}()
+
+	// Set the headers came from the user properties of the message
+	headers := http.Header{}
+	for k, v := range azureServiceBusHeaders(msg) {
+		headers.Set(k, v)
+	}
+	if msg.SessionID != nil {
+		headers.Set("X-Fission-MQTrigger-SessionId", *msg.SessionID)
+	}
+
+	resp, body, err := invokeFunction(ctx, asb.logger, asb.routerUrl, trigger, msg.Data, headers)
+	if err != nil {
+		azureServiceBusErrorHandler(ctx, asb.logger, senders.error, trigger, msg, err)
+		return
+	}
+	success = true
+
+	if senders.response != nil {
+		err = senders.response.Send(ctx, azureServiceBusReply(msg, body, resp.Header))
+		if err != nil {
+			observeError(trigger, errorTypePublish)
+			asb.logger.Warn("failed to publish response body from function invocation to topic",
+				zap.Error(err),
+				zap.String("topic", trigger.Spec.ResponseTopic),
+				zap.String("function", trigger.Spec.FunctionReference.Name))
+		}
+	}
+	err = msg.Complete(ctx)
+	if err != nil {
+		asb.logger.Error("failed to complete message", zap.Error(err), zap.String("trigger", trigger.Metadata.Name))
+	}
+}
+
+// azureServiceBusErrorHandler sends the error of a message to the error
+// topic, if the trigger has one, and dead-letters the message.
+func azureServiceBusErrorHandler(ctx context.Context, logger *zap.Logger, errorTopic *servicebus.Queue, trigger *fv1.MessageQueueTrigger, msg *servicebus.Message, err error) {
+	if errorTopic != nil {
+		e := errorTopic.Send(ctx, azureServiceBusReply(msg, []byte(err.Error()), nil))
+		if e != nil {
+			observeError(trigger, errorTypePublish)
+			logger.Error("failed to publish message to error topic",
+				zap.Error(e),
+				zap.String("trigger", trigger.Metadata.Name),
+				zap.String("message", err.Error()),
+				zap.String("topic", trigger.Spec.ErrorTopic))
+		}
+	}
+
+	logger.Error("dead-lettering message of failed function invocation",
+		zap.String("message", err.Error()), zap.String("trigger", trigger.Metadata.Name), zap.String("function", trigger.Spec.FunctionReference.Name))
+	e := msg.DeadLetter(ctx, err)
+	if e != nil {
+		logger.Error("failed to dead-letter message", zap.Error(e), zap.String("trigger", trigger.Metadata.Name))
+	}
+}
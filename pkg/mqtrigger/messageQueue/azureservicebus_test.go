@@ -0,0 +1,93 @@
+/*
+Copyright 2019 The Fission Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package messageQueue
+
+import (
+	"net/http"
+	"testing"
+
+	servicebus "github.com/Azure/azure-service-bus-go"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+	apiv1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+
+	fv1 "github.com/fission/fission/pkg/apis/fission.io/v1"
+)
+
+func TestAzureServiceBusConnectionString(t *testing.T) {
+	kubeClient := fake.NewSimpleClientset(
+		&apiv1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Name: "sb", Namespace: "default"},
+			Data: map[string][]byte{
+				"connectionString": []byte("Endpoint=sb://trigger.servicebus.windows.net/"),
+			},
+		},
+		&apiv1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Name: "creds", Namespace: "default"},
+			Data: map[string][]byte{
+				"username": []byte("fission"),
+			},
+		})
+	mq, err := makeAzureServiceBusMessageQueue(zap.NewNop(), kubeClient, "http://router", MessageQueueConfig{
+		MQType: fv1.MessageQueueTypeAzureServiceBus,
+	})
+	assert.Nil(t, err)
+	asb := mq.(AzureServiceBus)
+
+	trigger := &fv1.MessageQueueTrigger{
+		Metadata: metav1.ObjectMeta{Name: "foo", Namespace: "default"},
+		Spec: fv1.MessageQueueTriggerSpec{
+			MessageQueueType: fv1.MessageQueueTypeAzureServiceBus,
+			Topic:            "orders",
+		},
+	}
+	_, err = asb.connectionString(trigger)
+	assert.EqualError(t, err, "no connection string for trigger foo, set a secret in the trigger or MESSAGE_QUEUE_URL of the mqtrigger deployment")
+
+	asb.connStr = "Endpoint=sb://default.servicebus.windows.net/"
+	connStr, err := asb.connectionString(trigger)
+	assert.Nil(t, err)
+	assert.Equal(t, "Endpoint=sb://default.servicebus.windows.net/", connStr)
+
+	// the secret of the trigger takes precedence
+	trigger.Spec.Secret = "sb"
+	connStr, err = asb.connectionString(trigger)
+	assert.Nil(t, err)
+	assert.Equal(t, "Endpoint=sb://trigger.servicebus.windows.net/", connStr)
+
+	trigger.Spec.Secret = "creds"
+	_, err = asb.connectionString(trigger)
+	assert.EqualError(t, err, `no "connectionString" key in the secret of trigger foo`)
+}
+
+func TestAzureServiceBusReply(t *testing.T) {
+	sessionID := "order-42"
+	msg := servicebus.NewMessage([]byte("request"))
+	msg.SessionID = &sessionID
+	msg.UserProperties = map[string]interface{}{"X-Priority": 3}
+	assert.Equal(t, map[string]string{"X-Priority": "3"}, azureServiceBusHeaders(msg))
+
+	header := http.Header{}
+	header.Set("Content-Type", "application/json")
+	reply := azureServiceBusReply(msg, []byte("response"), header)
+	assert.Equal(t, []byte("response"), reply.Data)
+	assert.Equal(t, &sessionID, reply.SessionID, "replies are sent in the session of the message")
+	assert.Equal(t, "application/json", reply.ContentType)
+	assert.Equal(t, "application/json", reply.UserProperties["Content-Type"])
+}
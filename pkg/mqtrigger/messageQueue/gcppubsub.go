@@ -0,0 +1,300 @@
+/*
+Copyright 2019 The Fission Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package messageQueue
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"cloud.google.com/go/pubsub"
+	"github.com/pkg/errors"
+	"go.uber.org/zap"
+	"google.golang.org/api/option"
+	"k8s.io/client-go/kubernetes"
+
+	fv1 "github.com/fission/fission/pkg/apis/fission.io/v1"
+)
+
+// Ack deadline of the subscriptions created for triggers if not set.
+const defaultGCPPubSubAckDeadline = 10 * time.Second
+
+type (
+	GCPPubSub struct {
+		logger     *zap.Logger
+		routerUrl  string
+		projectID  string
+		kubeClient kubernetes.Interface
+	}
+
+	// gcpPubSubSubscription holds the client of a trigger, cancelling the
+	// context stops receiving messages.
+	gcpPubSubSubscription struct {
+		client       *pubsub.Client
+		subscription *pubsub.Subscription
+		topics       gcpPubSubTopics
+		cancel       context.CancelFunc
+		done         chan struct{}
+		// owned is set if the subscription is named after the trigger,
+		// it's deleted along with the trigger then.
+		owned bool
+	}
+
+	// gcpPubSubTopics are the topics the responses and the errors of the
+	// function are published to, nil if not set.
+	gcpPubSubTopics struct {
+		responseTopic *pubsub.Topic
+		errorTopic    *pubsub.Topic
+	}
+)
+
+func makeGCPPubSubMessageQueue(logger *zap.Logger, kubeClient kubernetes.Interface, routerUrl string, mqCfg MessageQueueConfig) (MessageQueue, error) {
+	if len(routerUrl) == 0 {
+		return nil, errors.New("the router URL is empty")
+	}
+
+	// the triggers may set their own project, so it's optional here
+	projectID := os.Getenv("GCP_PROJECT_ID")
+
+	ps := GCPPubSub{
+		logger:     logger.Named("gcp_pubsub"),
+		routerUrl:  routerUrl,
+		projectID:  projectID,
+		kubeClient: kubeClient,
+	}
+
+	logger.Info("created gcp pubsub queue", zap.String("project", projectID))
+
+	return ps, nil
+}
+
+func isTopicValidForGCPPubSub(topic string) bool {
+	return fv1.IsValidGCPPubSubName(topic)
+}
+
+// getTriggerClient returns a Pub/Sub client of the project of a trigger,
+// authenticated with the service account key of the trigger secret if it
+// has one, or with the application default credentials of the mqtrigger
+// deployment otherwise.
+func (ps GCPPubSub) getTriggerClient(ctx context.Context, trigger *fv1.MessageQueueTrigger) (*pubsub.Client, error) {
+	projectID := ps.projectID
+	if trigger.Spec.GCPPubSub != nil && len(trigger.Spec.GCPPubSub.ProjectID) > 0 {
+		projectID = trigger.Spec.GCPPubSub.ProjectID
+	}
+	if len(projectID) == 0 {
+		return nil, errors.Errorf("no GCP project for trigger %v, set it in the trigger or GCP_PROJECT_ID of the mqtrigger deployment", trigger.Metadata.Name)
+	}
+
+	var opts []option.ClientOption
+	secret, err := getTriggerSecret(ps.kubeClient, trigger)
+	if err != nil {
+		return nil, err
+	}
+	if secret != nil {
+		credentials, ok := secret[secretGCPCredentialsKey]
+		if !ok {
+			return nil, errors.Errorf("no %q key in the secret of trigger %v", secretGCPCredentialsKey, trigger.Metadata.Name)
+		}
+		opts = append(opts, option.WithCredentialsJSON(credentials))
+	}
+
+	client, err := pubsub.NewClient(ctx, projectID, opts...)
+	if err != nil {
+		return nil, errors.Wrapf(err, "error creating gcp pubsub client of project %v", projectID)
+	}
+	return client, nil
+}
+
+// gcpPubSubSubscriptionID returns the subscription a trigger pulls messages
+// from, and whether it's named after the trigger.
+func gcpPubSubSubscriptionID(trigger *fv1.MessageQueueTrigger) (string, bool) {
+	if trigger.Spec.GCPPubSub != nil && len(trigger.Spec.GCPPubSub.Subscription) > 0 {
+		return trigger.Spec.GCPPubSub.Subscription, false
+	}
+	return fmt.Sprintf("fission-%v", trigger.Metadata.UID), true
+}
+
+// getSubscription returns the subscription of a trigger, creating it on the
+// topic of the trigger if it doesn't exist.
+func (ps GCPPubSub) getSubscription(ctx context.Context, client *pubsub.Client, trigger *fv1.MessageQueueTrigger) (*pubsub.Subscription, bool, error) {
+	id, owned := gcpPubSubSubscriptionID(trigger)
+	sub := client.Subscription(id)
+	exists, err := sub.Exists(ctx)
+	if err != nil {
+		return nil, false, errors.Wrapf(err, "error checking subscription %v", id)
+	}
+	if exists {
+		return sub, owned, nil
+	}
+
+	ackDeadline := defaultGCPPubSubAckDeadline
+	if trigger.Spec.GCPPubSub != nil && trigger.Spec.GCPPubSub.AckDeadline > 0 {
+		ackDeadline = time.Duration(trigger.Spec.GCPPubSub.AckDeadline) * time.Second
+	}
+	sub, err = client.CreateSubscription(ctx, id, pubsub.SubscriptionConfig{
+		Topic:       client.Topic(trigger.Spec.Topic),
+		AckDeadline: ackDeadline,
+	})
+	if err != nil {
+		return nil, false, errors.Wrapf(err, "error creating subscription %v of topic %v", id, trigger.Spec.Topic)
+	}
+	ps.logger.Info("created subscription", zap.String("subscription", id), zap.String("topic", trigger.Spec.Topic))
+	return sub, owned, nil
+}
+
+func (ps GCPPubSub) subscribe(trigger *fv1.MessageQueueTrigger) (messageQueueSubscription, error) {
+	ps.logger.Info("inside gcp pubsub subscribe", zap.String("trigger", trigger.Metadata.Name))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	client, err := ps.getTriggerClient(ctx, trigger)
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+
+	sub, owned, err := ps.getSubscription(ctx, client, trigger)
+	if err != nil {
+		client.Close()
+		cancel()
+		return nil, errors.Wrapf(err, "error subscribing gcp pubsub trigger %v", trigger.Metadata.Name)
+	}
+	// Messages are processed one by one like with the other message
+	// queues, the streaming pull extends their ack deadline meanwhile.
+	sub.ReceiveSettings.MaxOutstandingMessages = 1
+
+	var topics gcpPubSubTopics
+	if len(trigger.Spec.ResponseTopic) > 0 {
+		topics.responseTopic = client.Topic(trigger.Spec.ResponseTopic)
+	}
+	if len(trigger.Spec.ErrorTopic) > 0 {
+		topics.errorTopic = client.Topic(trigger.Spec.ErrorTopic)
+	}
+
+	ps.logger.Info("created a new subscriber",
+		zap.String("subscription", sub.ID()),
+		zap.String("input topic", trigger.Spec.Topic),
+		zap.String("output topic", trigger.Spec.ResponseTopic),
+		zap.String("error topic", trigger.Spec.ErrorTopic),
+		zap.String("trigger name", trigger.Metadata.Name),
+		zap.String("function namespace", trigger.Metadata.Namespace),
+		zap.String("function name", trigger.Spec.FunctionReference.Name))
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		err := sub.Receive(ctx, func(ctx context.Context, m *pubsub.Message) {
+			ps.logger.Debug("calling message handler", zap.String("message", string(m.Data)))
+			gcpPubSubMsgHandler(ctx, &ps, topics, trigger, m)
+			// Failed messages are acknowledged too, the function is
+			// retried up to MaxRetries times and the error is published to
+			// the error topic instead of Pub/Sub redelivering it forever.
+			m.Ack()
+		})
+		if err != nil {
+			ps.logger.Error("error receiving messages", zap.Error(err), zap.String("trigger", trigger.Metadata.Name))
+		}
+	}()
+
+	return gcpPubSubSubscription{
+		client:       client,
+		subscription: sub,
+		topics:       topics,
+		cancel:       cancel,
+		done:         done,
+		owned:        owned,
+	}, nil
+}
+
+func (ps GCPPubSub) unsubscribe(subscription messageQueueSubscription) error {
+	s := subscription.(gcpPubSubSubscription)
+	s.cancel()
+	<-s.done
+	defer s.client.Close()
+	for _, t := range []*pubsub.Topic{s.topics.responseTopic, s.topics.errorTopic} {
+		if t != nil {
+			t.Stop()
+		}
+	}
+
+	if s.owned {
+		err := s.subscription.Delete(context.Background())
+		if err != nil {
+			return errors.Wrapf(err, "error deleting subscription %v", s.subscription.ID())
+		}
+	}
+	return nil
+}
+
+func gcpPubSubMsgHandler(ctx context.Context, ps *GCPPubSub, topics gcpPubSubTopics, trigger *fv1.MessageQueueTrigger, m *pubsub.Message) {
+	start := time.Now()
+	success := false
+	defer func() {
+		observeMessage(trigger, start, success)
+	}()
+
+	// Set the headers came from the message attributes
+	headers := http.Header{}
+	for k, v := range m.Attributes {
+		headers.Set(k, v)
+	}
+
+	resp, body, err := invokeFunction(ctx, ps.logger, ps.routerUrl, trigger, m.Data, headers)
+	if err != nil {
+		gcpPubSubErrorHandler(ctx, ps.logger, topics.errorTopic, trigger, err)
+		return
+	}
+	success = true
+	if topics.responseTopic != nil {
+		attributes := make(map[string]string, len(resp.Header))
+		for k, v := range resp.Header {
+			attributes[k] = strings.Join(v, ",")
+		}
+		_, err = topics.responseTopic.Publish(ctx, &pubsub.Message{
+			Data:       body,
+			Attributes: attributes,
+		}).Get(ctx)
+		if err != nil {
+			observeError(trigger, errorTypePublish)
+			ps.logger.Warn("failed to publish response body from function invocation to topic",
+				zap.Error(err),
+				zap.String("topic", trigger.Spec.ResponseTopic),
+				zap.String("function", trigger.Spec.FunctionReference.Name))
+		}
+	}
+}
+
+func gcpPubSubErrorHandler(ctx context.Context, logger *zap.Logger, errorTopic *pubsub.Topic, trigger *fv1.MessageQueueTrigger, err error) {
+	if errorTopic != nil {
+		_, e := errorTopic.Publish(ctx, &pubsub.Message{
+			Data: []byte(err.Error()),
+		}).Get(ctx)
+		if e != nil {
+			observeError(trigger, errorTypePublish)
+			logger.Error("failed to publish message to error topic",
+				zap.Error(e),
+				zap.String("trigger", trigger.Metadata.Name),
+				zap.String("message", err.Error()),
+				zap.String("topic", trigger.Spec.ErrorTopic))
+		}
+	} else {
+		logger.Error("message received to publish to error topic, but no error topic was set",
+			zap.String("message", err.Error()), zap.String("trigger", trigger.Metadata.Name), zap.String("function", trigger.Spec.FunctionReference.Name))
+	}
+}
@@ -0,0 +1,79 @@
+/*
+Copyright 2019 The Fission Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package messageQueue
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+	apiv1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+
+	fv1 "github.com/fission/fission/pkg/apis/fission.io/v1"
+)
+
+func TestGCPPubSubSubscriptionID(t *testing.T) {
+	trigger := &fv1.MessageQueueTrigger{
+		Metadata: metav1.ObjectMeta{Name: "foo", Namespace: "default", UID: "1234"},
+		Spec: fv1.MessageQueueTriggerSpec{
+			MessageQueueType: fv1.MessageQueueTypeGCPPubSub,
+			Topic:            "orders",
+		},
+	}
+	id, owned := gcpPubSubSubscriptionID(trigger)
+	assert.Equal(t, "fission-1234", id)
+	assert.True(t, owned)
+
+	trigger.Spec.GCPPubSub = &fv1.GCPPubSubConfig{Subscription: "orders-sub"}
+	id, owned = gcpPubSubSubscriptionID(trigger)
+	assert.Equal(t, "orders-sub", id)
+	assert.False(t, owned)
+}
+
+func TestGCPPubSubTriggerClientErrors(t *testing.T) {
+	kubeClient := fake.NewSimpleClientset(&apiv1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "creds", Namespace: "default"},
+		Data: map[string][]byte{
+			"username": []byte("fission"),
+		},
+	})
+	mq, err := makeGCPPubSubMessageQueue(zap.NewNop(), kubeClient, "http://router", MessageQueueConfig{
+		MQType: fv1.MessageQueueTypeGCPPubSub,
+	})
+	assert.Nil(t, err)
+	ps := mq.(GCPPubSub)
+	ps.projectID = ""
+
+	trigger := &fv1.MessageQueueTrigger{
+		Metadata: metav1.ObjectMeta{Name: "foo", Namespace: "default"},
+		Spec: fv1.MessageQueueTriggerSpec{
+			MessageQueueType: fv1.MessageQueueTypeGCPPubSub,
+			Topic:            "orders",
+		},
+	}
+	_, err = ps.getTriggerClient(context.Background(), trigger)
+	assert.EqualError(t, err, "no GCP project for trigger foo, set it in the trigger or GCP_PROJECT_ID of the mqtrigger deployment")
+
+	// the secret needs a service account key
+	trigger.Spec.GCPPubSub = &fv1.GCPPubSubConfig{ProjectID: "my-project"}
+	trigger.Spec.Secret = "creds"
+	_, err = ps.getTriggerClient(context.Background(), trigger)
+	assert.EqualError(t, err, `no "credentials.json" key in the secret of trigger foo`)
+}
@@ -17,34 +17,42 @@ limitations under the License.
 package messageQueue
 
 import (
+	"crypto/tls"
 	"fmt"
 	"io/ioutil"
 	"net/http"
 	"os"
 	"strings"
+	"time"
 
 	sarama "github.com/Shopify/sarama"
 	cluster "github.com/bsm/sarama-cluster"
-	"github.com/fission/fission/pkg/types"
-	"github.com/fission/fission/pkg/utils"
 	"github.com/pkg/errors"
 	"go.uber.org/zap"
+	"k8s.io/client-go/kubernetes"
 
 	fv1 "github.com/fission/fission/pkg/apis/fission.io/v1"
 )
 
 type (
 	Kafka struct {
-		logger    *zap.Logger
-		routerUrl string
-		brokers   []string
-		version   sarama.KafkaVersion
+		logger     *zap.Logger
+		routerUrl  string
+		brokers    []string
+		version    sarama.KafkaVersion
+		kubeClient kubernetes.Interface
 	}
 )
 
-func makeKafkaMessageQueue(logger *zap.Logger, routerUrl string, mqCfg MessageQueueConfig) (MessageQueue, error) {
-	if len(routerUrl) == 0 || len(mqCfg.Url) == 0 {
-		return nil, errors.New("the router URL or MQ URL is empty")
+func makeKafkaMessageQueue(logger *zap.Logger, kubeClient kubernetes.Interface, routerUrl string, mqCfg MessageQueueConfig) (MessageQueue, error) {
+	if len(routerUrl) == 0 {
+		return nil, errors.New("the router URL is empty")
+	}
+
+	// The MQ URL may be empty if every trigger specifies its own brokers.
+	var brokers []string
+	if len(mqCfg.Url) > 0 {
+		brokers = strings.Split(mqCfg.Url, ",")
 	}
 	mqKafkaVersion := os.Getenv("MESSAGE_QUEUE_KAFKA_VERSION")
 
@@ -58,10 +66,11 @@ func makeKafkaMessageQueue(logger *zap.Logger, routerUrl string, mqCfg MessageQu
 	}
 
 	kafka := Kafka{
-		logger:    logger.Named("kafka"),
-		routerUrl: routerUrl,
-		brokers:   strings.Split(mqCfg.Url, ","),
-		version:   kafkaVersion,
+		logger:     logger.Named("kafka"),
+		routerUrl:  routerUrl,
+		brokers:    brokers,
+		version:    kafkaVersion,
+		kubeClient: kubeClient,
 	}
 
 	logger.Info("created kafka queue", zap.Any("kafka brokers", kafka.brokers),
@@ -70,21 +79,44 @@ func makeKafkaMessageQueue(logger *zap.Logger, routerUrl string, mqCfg MessageQu
 	return kafka, nil
 }
 
+// checkConnection checks the default brokers, the brokers of the triggers
+// are not checked.
+func (kafka Kafka) checkConnection() error {
+	if len(kafka.brokers) == 0 {
+		return nil
+	}
+	return dialCheck(kafka.brokers)
+}
+
 func isTopicValidForKafka(topic string) bool {
 	return true
 }
 
 func (kafka Kafka) subscribe(trigger *fv1.MessageQueueTrigger) (messageQueueSubscription, error) {
-	kafka.logger.Info("inside kakfa subscribe", zap.Any("trigger", trigger))
-	kafka.logger.Info("brokers set", zap.Strings("brokers", kafka.brokers))
+	kafka.logger.Info("inside kakfa subscribe", zap.String("trigger", trigger.Metadata.Name))
+
+	brokers, consumerGroup := kafka.getTriggerBrokersAndGroup(trigger)
+	if len(brokers) == 0 {
+		return nil, errors.Errorf("no kafka brokers configured for trigger %v", trigger.Metadata.Name)
+	}
+
+	secret, err := getTriggerSecret(kafka.kubeClient, trigger)
+	if err != nil {
+		return nil, err
+	}
 
 	// Create new consumer
 	consumerConfig := cluster.NewConfig()
 	consumerConfig.Consumer.Return.Errors = true
 	consumerConfig.Group.Return.Notifications = true
 	consumerConfig.Config.Version = kafka.version
-	consumer, err := cluster.NewConsumer(kafka.brokers, string(trigger.Metadata.UID), []string{trigger.Spec.Topic}, consumerConfig)
-	kafka.logger.Info("created a new consumer", zap.Strings("brokers", kafka.brokers),
+	err = setKafkaNetConfig(&consumerConfig.Config, trigger.Spec.Kafka, secret)
+	if err != nil {
+		return nil, errors.Wrapf(err, "error setting up the kafka connection of trigger %v", trigger.Metadata.Name)
+	}
+	consumer, err := cluster.NewConsumer(brokers, consumerGroup, []string{trigger.Spec.Topic}, consumerConfig)
+	kafka.logger.Info("created a new consumer", zap.Strings("brokers", brokers),
+		zap.String("consumer group", consumerGroup),
 		zap.String("input topic", trigger.Spec.Topic),
 		zap.String("output topic", trigger.Spec.ResponseTopic),
 		zap.String("error topic", trigger.Spec.ErrorTopic),
@@ -93,7 +125,7 @@ func (kafka Kafka) subscribe(trigger *fv1.MessageQueueTrigger) (messageQueueSubs
 		zap.String("function name", trigger.Spec.FunctionReference.Name))
 
 	if err != nil {
-		panic(err)
+		return nil, errors.Wrapf(err, "error creating kafka consumer for trigger %v", trigger.Metadata.Name)
 	}
 
 	// Create new producer
@@ -102,8 +134,10 @@ func (kafka Kafka) subscribe(trigger *fv1.MessageQueueTrigger) (messageQueueSubs
 	producerConfig.Producer.Retry.Max = 10
 	producerConfig.Producer.Return.Successes = true
 	producerConfig.Version = kafka.version
-	producer, err := sarama.NewSyncProducer(kafka.brokers, producerConfig)
-	kafka.logger.Info("created a new producer", zap.Strings("brokers", kafka.brokers),
+	// the secret was validated when setting up the consumer
+	setKafkaNetConfig(producerConfig, trigger.Spec.Kafka, secret)
+	producer, err := sarama.NewSyncProducer(brokers, producerConfig)
+	kafka.logger.Info("created a new producer", zap.Strings("brokers", brokers),
 		zap.String("input topic", trigger.Spec.Topic),
 		zap.String("output topic", trigger.Spec.ResponseTopic),
 		zap.String("error topic", trigger.Spec.ErrorTopic),
@@ -112,7 +146,8 @@ func (kafka Kafka) subscribe(trigger *fv1.MessageQueueTrigger) (messageQueueSubs
 		zap.String("function name", trigger.Spec.FunctionReference.Name))
 
 	if err != nil {
-		panic(err)
+		consumer.Close()
+		return nil, errors.Wrapf(err, "error creating kafka producer for trigger %v", trigger.Metadata.Name)
 	}
 
 	// consume errors
@@ -133,7 +168,10 @@ func (kafka Kafka) subscribe(trigger *fv1.MessageQueueTrigger) (messageQueueSubs
 	go func() {
 		for msg := range consumer.Messages() {
 			kafka.logger.Debug("calling message handler", zap.String("message", string(msg.Value[:])))
-			if kafkaMsgHandler(&kafka, producer, trigger, msg) {
+			start := time.Now()
+			ok := kafkaMsgHandler(&kafka, producer, trigger, msg)
+			observeMessage(trigger, start, ok)
+			if ok {
 				consumer.MarkOffset(msg, "") // mark message as processed
 			}
 		}
@@ -142,6 +180,51 @@ func (kafka Kafka) subscribe(trigger *fv1.MessageQueueTrigger) (messageQueueSubs
 	return consumer, nil
 }
 
+// getTriggerBrokersAndGroup returns the brokers and the consumer group of a trigger.
+// The brokers configured for the deployment and the trigger UID are used unless
+// the trigger overrides them.
+func (kafka Kafka) getTriggerBrokersAndGroup(trigger *fv1.MessageQueueTrigger) ([]string, string) {
+	brokers := kafka.brokers
+	consumerGroup := string(trigger.Metadata.UID)
+	if trigger.Spec.Kafka != nil {
+		if len(trigger.Spec.Kafka.Brokers) > 0 {
+			brokers = trigger.Spec.Kafka.Brokers
+		}
+		if len(trigger.Spec.Kafka.ConsumerGroup) > 0 {
+			consumerGroup = trigger.Spec.Kafka.ConsumerGroup
+		}
+	}
+	return brokers, consumerGroup
+}
+
+// setKafkaNetConfig applies the SASL and TLS settings of a trigger to the
+// sarama config. The SASL credentials only come from the trigger secret, the
+// certificates in it enable TLS as well.
+func setKafkaNetConfig(config *sarama.Config, kafkaConfig *fv1.KafkaConfig, secret map[string][]byte) error {
+	if username, ok := secret[secretUsernameKey]; ok {
+		// sarama uses the PLAIN mechanism by default
+		config.Net.SASL.Enable = true
+		config.Net.SASL.User = string(username)
+		config.Net.SASL.Password = string(secret[secretPasswordKey])
+	}
+
+	tlsConfig, err := getSecretTLSConfig(secret)
+	if err != nil {
+		return err
+	}
+	if kafkaConfig != nil && kafkaConfig.TLS != nil && kafkaConfig.TLS.Enabled {
+		if tlsConfig == nil {
+			tlsConfig = &tls.Config{}
+		}
+		tlsConfig.InsecureSkipVerify = kafkaConfig.TLS.InsecureSkipVerify
+	}
+	if tlsConfig != nil {
+		config.Net.TLS.Enable = true
+		config.Net.TLS.Config = tlsConfig
+	}
+	return nil
+}
+
 func (kafka Kafka) unsubscribe(subscription messageQueueSubscription) error {
 	return subscription.(*cluster.Consumer).Close()
 }
@@ -149,13 +232,14 @@ func (kafka Kafka) unsubscribe(subscription messageQueueSubscription) error {
 func kafkaMsgHandler(kafka *Kafka, producer sarama.SyncProducer, trigger *fv1.MessageQueueTrigger, msg *sarama.ConsumerMessage) bool {
 	var value string = string(msg.Value[:])
 	// Support other function ref types
-	if trigger.Spec.FunctionReference.Type != types.FunctionReferenceTypeFunctionName {
+	fnPath, err := functionPath(trigger)
+	if err != nil {
 		kafka.logger.Fatal("unsupported function reference type for trigger",
 			zap.Any("function_reference_type", trigger.Spec.FunctionReference.Type),
 			zap.String("trigger", trigger.Metadata.Name))
 	}
 
-	url := kafka.routerUrl + "/" + strings.TrimPrefix(utils.UrlForFunction(trigger.Spec.FunctionReference.Name, trigger.Metadata.Namespace), "/")
+	url := kafka.routerUrl + "/" + fnPath
 	kafka.logger.Debug("making HTTP request", zap.String("url", url))
 
 	// Generate the Headers
@@ -193,9 +277,13 @@ func kafkaMsgHandler(kafka *Kafka, producer sarama.SyncProducer, trigger *fv1.Me
 	// Make the request
 	var resp *http.Response
 	for attempt := 0; attempt <= trigger.Spec.MaxRetries; attempt++ {
+		if attempt > 0 {
+			observeRetry(trigger)
+		}
 		// Make the request
 		resp, err = http.DefaultClient.Do(req)
 		if err != nil {
+			observeError(trigger, errorTypeInvocation)
 			kafka.logger.Error("sending function invocation request failed",
 				zap.Error(err),
 				zap.String("function_url", url),
@@ -209,6 +297,7 @@ func kafkaMsgHandler(kafka *Kafka, producer sarama.SyncProducer, trigger *fv1.Me
 			// Success, quit retrying
 			break
 		}
+		observeError(trigger, errorTypeInvocation)
 	}
 
 	if resp == nil {
@@ -256,6 +345,7 @@ func kafkaMsgHandler(kafka *Kafka, producer sarama.SyncProducer, trigger *fv1.Me
 			Headers: kafkaRecordHeaders,
 		})
 		if err != nil {
+			observeError(trigger, errorTypePublish)
 			kafka.logger.Warn("failed to publish response body from function invocation to topic",
 				zap.Error(err),
 				zap.String("topic", trigger.Spec.Topic),
@@ -273,6 +363,7 @@ func errorHandler(logger *zap.Logger, trigger *fv1.MessageQueueTrigger, producer
 			Value: sarama.StringEncoder(err.Error()),
 		})
 		if e != nil {
+			observeError(trigger, errorTypePublish)
 			logger.Error("failed to publish message to error topic",
 				zap.Error(e),
 				zap.String("trigger", trigger.Metadata.Name),
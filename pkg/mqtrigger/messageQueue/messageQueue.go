@@ -17,19 +17,30 @@ limitations under the License.
 package messageQueue
 
 import (
-	"errors"
+	"bytes"
+	"context"
 	"fmt"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"strings"
 	"time"
 
-	"github.com/fission/fission/pkg/types"
-	"github.com/fission/fission/pkg/utils"
+	"github.com/pkg/errors"
 	"go.uber.org/zap"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
 
 	fv1 "github.com/fission/fission/pkg/apis/fission.io/v1"
 	"github.com/fission/fission/pkg/crd"
+	"github.com/fission/fission/pkg/types"
+	"github.com/fission/fission/pkg/utils"
 )
 
+// dialCheckTimeout is the timeout of the connection checks of the message
+// queue servers.
+const dialCheckTimeout = 3 * time.Second
+
 const (
 	ADD_TRIGGER requestType = iota
 	DELETE_TRIGGER
@@ -51,6 +62,12 @@ type (
 		unsubscribe(triggerSub messageQueueSubscription) error
 	}
 
+	// connectionChecker is implemented by the message queues which can
+	// check whether the message queue server is reachable.
+	connectionChecker interface {
+		checkConnection() error
+	}
+
 	MessageQueueTriggerManager struct {
 		logger        *zap.Logger
 		reqChan       chan request
@@ -76,7 +93,7 @@ type (
 	}
 )
 
-func MakeMessageQueueTriggerManager(logger *zap.Logger, fissionClient *crd.FissionClient, routerUrl string, mqConfig MessageQueueConfig) *MessageQueueTriggerManager {
+func MakeMessageQueueTriggerManager(logger *zap.Logger, fissionClient *crd.FissionClient, kubeClient kubernetes.Interface, routerUrl string, mqConfig MessageQueueConfig) *MessageQueueTriggerManager {
 	var messageQueue MessageQueue
 	var err error
 
@@ -92,7 +109,15 @@ func MakeMessageQueueTriggerManager(logger *zap.Logger, fissionClient *crd.Fissi
 	case types.MessageQueueTypeASQ:
 		messageQueue, err = newAzureStorageConnection(logger, routerUrl, mqConfig)
 	case types.MessageQueueTypeKafka:
-		messageQueue, err = makeKafkaMessageQueue(logger, routerUrl, mqConfig)
+		messageQueue, err = makeKafkaMessageQueue(logger, kubeClient, routerUrl, mqConfig)
+	case types.MessageQueueTypeRabbitMQ:
+		messageQueue, err = makeRabbitMQMessageQueue(logger, kubeClient, routerUrl, mqConfig)
+	case types.MessageQueueTypeGCPPubSub:
+		messageQueue, err = makeGCPPubSubMessageQueue(logger, kubeClient, routerUrl, mqConfig)
+	case types.MessageQueueTypeAzureServiceBus:
+		messageQueue, err = makeAzureServiceBusMessageQueue(logger, kubeClient, routerUrl, mqConfig)
+	case types.MessageQueueTypeNatsJetStream:
+		messageQueue, err = makeNatsJetStreamMessageQueue(logger, kubeClient, routerUrl, mqConfig)
 	default:
 		err = fmt.Errorf("no supported message queue type found for %q", mqConfig.MQType)
 	}
@@ -105,6 +130,32 @@ func MakeMessageQueueTriggerManager(logger *zap.Logger, fissionClient *crd.Fissi
 	return &mqTriggerMgr
 }
 
+// CheckConnection returns an error if the message queue server isn't
+// reachable. The message queues which connect per trigger, to the server
+// of the trigger, are not checked.
+func (mqt *MessageQueueTriggerManager) CheckConnection() error {
+	checker, ok := mqt.messageQueue.(connectionChecker)
+	if !ok {
+		return nil
+	}
+	return checker.checkConnection()
+}
+
+// dialCheck returns an error if none of the addresses accepts a TCP
+// connection.
+func dialCheck(addrs []string) error {
+	var err error
+	for _, addr := range addrs {
+		var conn net.Conn
+		conn, err = net.DialTimeout("tcp", addr, dialCheckTimeout)
+		if err == nil {
+			conn.Close()
+			return nil
+		}
+	}
+	return err
+}
+
 func (mqt *MessageQueueTriggerManager) service() {
 	for {
 		req := <-mqt.reqChan
@@ -235,6 +286,111 @@ func IsTopicValid(mqType string, topic string) bool {
 		return isTopicValidForNats(topic)
 	case fv1.MessageQueueTypeKafka:
 		return isTopicValidForKafka(topic)
+	case fv1.MessageQueueTypeRabbitMQ:
+		return isTopicValidForRabbitMQ(topic)
+	case fv1.MessageQueueTypeGCPPubSub:
+		return isTopicValidForGCPPubSub(topic)
+	case fv1.MessageQueueTypeAzureServiceBus:
+		return isTopicValidForAzureServiceBus(topic)
+	case fv1.MessageQueueTypeNatsJetStream:
+		return isTopicValidForNatsJetStream(topic)
 	}
 	return false
 }
+
+// functionPath returns the path of the internal router URL of the function
+// or function alias a trigger references, without the leading slash.
+func functionPath(trigger *fv1.MessageQueueTrigger) (string, error) {
+	// with the addition of multi-tenancy, the users can create functions in any namespace. however,
+	// the triggers can only be created in the same namespace as the function.
+	// so essentially, function namespace = trigger namespace.
+	var url string
+	switch trigger.Spec.FunctionReference.Type {
+	case types.FunctionReferenceTypeFunctionName:
+		url = utils.UrlForFunction(trigger.Spec.FunctionReference.Name, trigger.Metadata.Namespace)
+	case types.FunctionReferenceTypeFunctionAlias:
+		url = utils.UrlForFunctionAlias(trigger.Spec.FunctionReference.Name, trigger.Metadata.Namespace)
+	default:
+		return "", fmt.Errorf("unsupported function reference type (%v) for trigger %q", trigger.Spec.FunctionReference.Type, trigger.Metadata.Name)
+	}
+	return strings.TrimPrefix(url, "/"), nil
+}
+
+// invokeFunction posts a message to the function of a trigger through the
+// router. The headers of the message are passed on, along with the headers
+// telling the function about the topics of the trigger. The call is retried
+// up to MaxRetries times while it fails or the function doesn't respond with
+// 200. It returns the last response and its body, the error is set unless
+// the function succeeded.
+func invokeFunction(ctx context.Context, logger *zap.Logger, routerUrl string, trigger *fv1.MessageQueueTrigger,
+	body []byte, headers http.Header) (*http.Response, []byte, error) {
+	fnPath, err := functionPath(trigger)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	url := routerUrl + "/" + fnPath
+	logger.Debug("making HTTP request", zap.String("url", url))
+
+	fissionHeaders := map[string]string{
+		"X-Fission-MQTrigger-Topic":      trigger.Spec.Topic,
+		"X-Fission-MQTrigger-RespTopic":  trigger.Spec.ResponseTopic,
+		"X-Fission-MQTrigger-ErrorTopic": trigger.Spec.ErrorTopic,
+		"Content-Type":                   trigger.Spec.ContentType,
+	}
+
+	var resp *http.Response
+	for attempt := 0; attempt <= trigger.Spec.MaxRetries; attempt++ {
+		if attempt > 0 {
+			observeRetry(trigger)
+		}
+		// Create request, the body can't be reused across attempts
+		req, err := http.NewRequest("POST", url, bytes.NewReader(body))
+		if err != nil {
+			return nil, nil, errors.Wrap(err, "failed to create HTTP request to invoke function")
+		}
+		for k, v := range headers {
+			req.Header[k] = v
+		}
+		for k, v := range fissionHeaders {
+			req.Header.Set(k, v)
+		}
+
+		resp, err = http.DefaultClient.Do(req.WithContext(ctx))
+		if err != nil {
+			observeError(trigger, errorTypeInvocation)
+			logger.Error("sending function invocation request failed",
+				zap.Error(err),
+				zap.String("function_url", url),
+				zap.String("trigger", trigger.Metadata.Name))
+			continue
+		}
+		if resp.StatusCode == http.StatusOK {
+			// Success, quit retrying
+			break
+		}
+		observeError(trigger, errorTypeInvocation)
+		if attempt < trigger.Spec.MaxRetries {
+			resp.Body.Close()
+		}
+	}
+
+	if resp == nil {
+		return nil, nil, errors.New("every function invocation retry failed; final retry gave empty response")
+	}
+	defer resp.Body.Close()
+	respBody, err := ioutil.ReadAll(resp.Body)
+
+	logger.Debug("got response from function invocation",
+		zap.String("function_url", url),
+		zap.String("trigger", trigger.Metadata.Name),
+		zap.String("body", string(respBody)))
+
+	if err != nil {
+		return resp, respBody, errors.Wrapf(err, "request body error: %v", string(respBody))
+	}
+	if resp.StatusCode != http.StatusOK {
+		return resp, respBody, fmt.Errorf("request returned failure: %v", resp.StatusCode)
+	}
+	return resp, respBody, nil
+}
@@ -0,0 +1,102 @@
+/*
+Copyright 2019 The Fission Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package messageQueue
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	fv1 "github.com/fission/fission/pkg/apis/fission.io/v1"
+	"github.com/fission/fission/pkg/types"
+)
+
+func invokeFunctionTrigger(maxRetries int) *fv1.MessageQueueTrigger {
+	return &fv1.MessageQueueTrigger{
+		Metadata: metav1.ObjectMeta{Name: "trigger", Namespace: "default"},
+		Spec: fv1.MessageQueueTriggerSpec{
+			FunctionReference: fv1.FunctionReference{
+				Type: types.FunctionReferenceTypeFunctionName,
+				Name: "hello",
+			},
+			Topic:         "input",
+			ResponseTopic: "output",
+			ContentType:   "application/json",
+			MaxRetries:    maxRetries,
+		},
+	}
+}
+
+func TestInvokeFunction(t *testing.T) {
+	calls := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		assert.Equal(t, "/fission-function/hello", r.URL.Path)
+		assert.Equal(t, "input", r.Header.Get("X-Fission-MQTrigger-Topic"))
+		assert.Equal(t, "output", r.Header.Get("X-Fission-MQTrigger-RespTopic"))
+		assert.Equal(t, "application/json", r.Header.Get("Content-Type"))
+		assert.Equal(t, "value", r.Header.Get("X-Custom"))
+		body, err := ioutil.ReadAll(r.Body)
+		assert.NoError(t, err)
+		assert.Equal(t, "message", string(body))
+		if calls < 3 {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		w.Write([]byte("response"))
+	}))
+	defer server.Close()
+
+	headers := http.Header{}
+	headers.Set("X-Custom", "value")
+	headers.Set("Content-Type", "text/plain")
+	resp, body, err := invokeFunction(context.Background(), zap.NewNop(), server.URL, invokeFunctionTrigger(2), []byte("message"), headers)
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, "response", string(body))
+	assert.Equal(t, 3, calls)
+}
+
+func TestInvokeFunctionFailure(t *testing.T) {
+	calls := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		w.WriteHeader(http.StatusBadGateway)
+		w.Write([]byte("bad gateway"))
+	}))
+	defer server.Close()
+
+	resp, body, err := invokeFunction(context.Background(), zap.NewNop(), server.URL, invokeFunctionTrigger(1), []byte("message"), http.Header{})
+	assert.EqualError(t, err, "request returned failure: 502")
+	assert.Equal(t, http.StatusBadGateway, resp.StatusCode)
+	assert.Equal(t, "bad gateway", string(body))
+	assert.Equal(t, 2, calls)
+}
+
+func TestInvokeFunctionUnsupportedReference(t *testing.T) {
+	trigger := invokeFunctionTrigger(0)
+	trigger.Spec.FunctionReference.Type = "unknown"
+
+	_, _, err := invokeFunction(context.Background(), zap.NewNop(), "http://router", trigger, []byte("message"), http.Header{})
+	assert.EqualError(t, err, `unsupported function reference type (unknown) for trigger "trigger"`)
+}
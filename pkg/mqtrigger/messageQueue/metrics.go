@@ -0,0 +1,100 @@
+/*
+Copyright 2019 The Fission Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package messageQueue
+
+import (
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+
+	fv1 "github.com/fission/fission/pkg/apis/fission.io/v1"
+)
+
+const (
+	// errorTypeInvocation is a failed attempt to invoke the function,
+	// errorTypePublish a failure to publish the response or the error of
+	// the function to its topic.
+	errorTypeInvocation = "invocation"
+	errorTypePublish    = "publish"
+)
+
+var (
+	// messages processed by the triggers
+	// trigger: the name of the trigger
+	// topic: the topic the trigger subscribes to
+	// function: the function the trigger invokes
+	// status: success if the function handled the message, failure otherwise
+	messagesProcessed = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "fission_mqtrigger_messages_processed_total",
+			Help: "Count of messages processed by the message queue triggers",
+		},
+		[]string{"trigger", "topic", "function", "status"},
+	)
+	messageProcessingDuration = prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Name:    "fission_mqtrigger_message_processing_duration_seconds",
+			Help:    "Duration of processing a message, retries of the function included.",
+			Buckets: prometheus.DefBuckets,
+		},
+		[]string{"trigger", "topic", "function"},
+	)
+	invocationRetries = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "fission_mqtrigger_retries_total",
+			Help: "Count of retried function invocations of the message queue triggers",
+		},
+		[]string{"trigger", "topic", "function"},
+	)
+	// type: invocation or publish
+	triggerErrors = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "fission_mqtrigger_errors_total",
+			Help: "Count of errors of the message queue triggers",
+		},
+		[]string{"trigger", "topic", "function", "type"},
+	)
+)
+
+func init() {
+	prometheus.MustRegister(messagesProcessed)
+	prometheus.MustRegister(messageProcessingDuration)
+	prometheus.MustRegister(invocationRetries)
+	prometheus.MustRegister(triggerErrors)
+}
+
+// observeMessage records a message of the trigger processed since start.
+func observeMessage(trigger *fv1.MessageQueueTrigger, start time.Time, success bool) {
+	status := "success"
+	if !success {
+		status = "failure"
+	}
+	fn := trigger.Spec.FunctionReference.Name
+	messagesProcessed.WithLabelValues(trigger.Metadata.Name, trigger.Spec.Topic, fn, status).Inc()
+	messageProcessingDuration.WithLabelValues(trigger.Metadata.Name, trigger.Spec.Topic, fn).Observe(time.Since(start).Seconds())
+}
+
+// observeRetry records a retried invocation of the function of the trigger.
+func observeRetry(trigger *fv1.MessageQueueTrigger) {
+	invocationRetries.WithLabelValues(trigger.Metadata.Name, trigger.Spec.Topic, trigger.Spec.FunctionReference.Name).Inc()
+}
+
+// observeError records an error of the trigger, of errorTypeInvocation or
+// errorTypePublish.
+func observeError(trigger *fv1.MessageQueueTrigger, errType string) {
+	triggerErrors.WithLabelValues(trigger.Metadata.Name, trigger.Spec.Topic, trigger.Spec.FunctionReference.Name, errType).Inc()
+}
@@ -18,18 +18,17 @@ package messageQueue
 
 import (
 	"bytes"
+	"errors"
 	"fmt"
 	"io/ioutil"
 	"net/http"
-	"strings"
+	"time"
 
 	ns "github.com/nats-io/go-nats-streaming"
 	nsUtil "github.com/nats-io/nats-streaming-server/util"
 	"go.uber.org/zap"
 
 	fv1 "github.com/fission/fission/pkg/apis/fission.io/v1"
-	"github.com/fission/fission/pkg/types"
-	"github.com/fission/fission/pkg/utils"
 )
 
 const (
@@ -69,6 +68,14 @@ func makeNatsMessageQueue(logger *zap.Logger, routerUrl string, mqCfg MessageQue
 	return nats, nil
 }
 
+func (nats Nats) checkConnection() error {
+	conn := nats.nsConn.NatsConn()
+	if conn == nil || !conn.IsConnected() {
+		return errors.New("not connected to the NATS streaming server")
+	}
+	return nil
+}
+
 func (nats Nats) subscribe(trigger *fv1.MessageQueueTrigger) (messageQueueSubscription, error) {
 	subj := trigger.Spec.Topic
 
@@ -104,18 +111,21 @@ func isTopicValidForNats(topic string) bool {
 
 func msgHandler(nats *Nats, trigger *fv1.MessageQueueTrigger) func(*ns.Msg) {
 	return func(msg *ns.Msg) {
+		start := time.Now()
+		success := false
+		defer func() {
+			observeMessage(trigger, start, success)
+		}()
 
 		// Support other function ref types
-		if trigger.Spec.FunctionReference.Type != types.FunctionReferenceTypeFunctionName {
+		fnPath, err := functionPath(trigger)
+		if err != nil {
 			nats.logger.Fatal("unsupported function reference type for trigger",
 				zap.Any("function_reference_type", trigger.Spec.FunctionReference.Type),
 				zap.String("trigger", trigger.Metadata.Name))
 		}
 
-		// with the addition of multi-tenancy, the users can create functions in any namespace. however,
-		// the triggers can only be created in the same namespace as the function.
-		// so essentially, function namespace = trigger namespace.
-		url := nats.routerUrl + "/" + strings.TrimPrefix(utils.UrlForFunction(trigger.Spec.FunctionReference.Name, trigger.Metadata.Namespace), "/")
+		url := nats.routerUrl + "/" + fnPath
 		nats.logger.Debug("making HTTP request", zap.String("url", url))
 
 		headers := map[string]string{
@@ -141,9 +151,13 @@ func msgHandler(nats *Nats, trigger *fv1.MessageQueueTrigger) func(*ns.Msg) {
 
 		var resp *http.Response
 		for attempt := 0; attempt <= trigger.Spec.MaxRetries; attempt++ {
+			if attempt > 0 {
+				observeRetry(trigger)
+			}
 			// Make the request
 			resp, err = http.DefaultClient.Do(req)
 			if err != nil {
+				observeError(trigger, errorTypeInvocation)
 				nats.logger.Error("sending function invocation request failed",
 					zap.Error(err),
 					zap.String("function_url", url),
@@ -157,6 +171,7 @@ func msgHandler(nats *Nats, trigger *fv1.MessageQueueTrigger) func(*ns.Msg) {
 				// Success, quit retrying
 				break
 			}
+			observeError(trigger, errorTypeInvocation)
 		}
 
 		if resp == nil {
@@ -182,6 +197,7 @@ func msgHandler(nats *Nats, trigger *fv1.MessageQueueTrigger) func(*ns.Msg) {
 			if len(trigger.Spec.ErrorTopic) > 0 && len(body) > 0 {
 				publishErr := nats.nsConn.Publish(trigger.Spec.ErrorTopic, body)
 				if publishErr != nil {
+					observeError(trigger, errorTypePublish)
 					nats.logger.Error("failed to publish function invocation error to error topic",
 						zap.Error(publishErr),
 						zap.String("topic", trigger.Spec.ErrorTopic),
@@ -194,6 +210,8 @@ func msgHandler(nats *Nats, trigger *fv1.MessageQueueTrigger) func(*ns.Msg) {
 			return
 		}
 
+		success = true
+
 		// Trigger acks message only if a request was processed successfully
 		err = msg.Ack()
 		if err != nil {
@@ -206,6 +224,7 @@ func msgHandler(nats *Nats, trigger *fv1.MessageQueueTrigger) func(*ns.Msg) {
 		if len(trigger.Spec.ResponseTopic) > 0 {
 			err = nats.nsConn.Publish(trigger.Spec.ResponseTopic, body)
 			if err != nil {
+				observeError(trigger, errorTypePublish)
 				nats.logger.Error("failed to publish message with function invocation response to topic",
 					zap.Error(err),
 					zap.String("topic", trigger.Spec.ResponseTopic),
@@ -0,0 +1,379 @@
+/*
+Copyright 2019 The Fission Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package messageQueue
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/nats-io/nats.go"
+	"github.com/pkg/errors"
+	"go.uber.org/zap"
+	"k8s.io/client-go/kubernetes"
+
+	fv1 "github.com/fission/fission/pkg/apis/fission.io/v1"
+)
+
+const (
+	// Defaults of the consumers created for triggers.
+	natsJetStreamDefaultAckWait    = 30 * time.Second
+	natsJetStreamDefaultMaxDeliver = 1
+)
+
+type (
+	NatsJetStream struct {
+		logger     *zap.Logger
+		routerUrl  string
+		url        string
+		kubeClient kubernetes.Interface
+
+		// connection of the triggers without a secret
+		conn *nats.Conn
+	}
+
+	// natsJetStreamSubscription holds the subscription of a trigger to its
+	// consumer, and the connection of the trigger if it has a secret.
+	natsJetStreamSubscription struct {
+		sub  *nats.Subscription
+		conn *nats.Conn
+	}
+)
+
+func makeNatsJetStreamMessageQueue(logger *zap.Logger, kubeClient kubernetes.Interface, routerUrl string, mqCfg MessageQueueConfig) (MessageQueue, error) {
+	if len(routerUrl) == 0 || len(mqCfg.Url) == 0 {
+		return nil, errors.New("the router URL or the NATS URL is empty")
+	}
+
+	njs := NatsJetStream{
+		logger:     logger.Named("nats_jetstream"),
+		routerUrl:  routerUrl,
+		url:        mqCfg.Url,
+		kubeClient: kubeClient,
+	}
+	conn, err := njs.connect()
+	if err != nil {
+		return nil, err
+	}
+	njs.conn = conn
+
+	logger.Info("created nats jetstream queue", zap.String("url", mqCfg.Url))
+	return njs, nil
+}
+
+// connect connects to the NATS server with the options, reconnecting
+// whenever the connection is lost.
+func (njs NatsJetStream) connect(opts ...nats.Option) (*nats.Conn, error) {
+	opts = append(opts,
+		nats.Name("fission-mqtrigger"),
+		nats.MaxReconnects(-1),
+		nats.DisconnectErrHandler(func(_ *nats.Conn, err error) {
+			njs.logger.Warn("disconnected from the nats server", zap.Error(err))
+		}),
+		nats.ReconnectHandler(func(conn *nats.Conn) {
+			njs.logger.Info("reconnected to the nats server", zap.String("url", conn.ConnectedUrl()))
+		}))
+	conn, err := nats.Connect(njs.url, opts...)
+	if err != nil {
+		return nil, errors.Wrapf(err, "error connecting to nats server %v", njs.url)
+	}
+	return conn, nil
+}
+
+func (njs NatsJetStream) checkConnection() error {
+	if !njs.conn.IsConnected() {
+		return errors.New("not connected to the NATS server")
+	}
+	return nil
+}
+
+// triggerConnection returns a new connection with the credentials and the
+// TLS settings of the secret of a trigger, or nil if the trigger has no
+// secret and shares the connection of the mqtrigger.
+func (njs NatsJetStream) triggerConnection(trigger *fv1.MessageQueueTrigger) (*nats.Conn, error) {
+	secret, err := getTriggerSecret(njs.kubeClient, trigger)
+	if err != nil || secret == nil {
+		return nil, err
+	}
+
+	var opts []nats.Option
+	if username, ok := secret[secretUsernameKey]; ok {
+		opts = append(opts, nats.UserInfo(string(username), string(secret[secretPasswordKey])))
+	}
+	tlsConfig, err := getSecretTLSConfig(secret)
+	if err != nil {
+		return nil, err
+	}
+	if tlsConfig != nil {
+		opts = append(opts, nats.Secure(tlsConfig))
+	}
+	return njs.connect(opts...)
+}
+
+// natsJetStreamConsumerConfig returns the config of the consumer of a
+// trigger, with the defaults of the unset settings.
+func natsJetStreamConsumerConfig(trigger *fv1.MessageQueueTrigger) *nats.ConsumerConfig {
+	config := trigger.Spec.NatsJetStream
+	cc := &nats.ConsumerConfig{
+		Durable:        config.Durable,
+		DeliverSubject: nats.NewInbox(),
+		DeliverPolicy:  nats.DeliverAllPolicy,
+		AckPolicy:      nats.AckExplicitPolicy,
+		AckWait:        time.Duration(config.AckWait) * time.Second,
+		// the trigger gives up on messages after MaxDeliver failures, the
+		// messages of a stopped mqtrigger are always redelivered
+		MaxDeliver:    -1,
+		FilterSubject: trigger.Spec.Topic,
+	}
+	if len(cc.Durable) == 0 {
+		cc.Durable = fmt.Sprintf("fission-%v", trigger.Metadata.UID)
+	}
+	if cc.AckWait == 0 {
+		cc.AckWait = natsJetStreamDefaultAckWait
+	}
+	return cc
+}
+
+// natsJetStreamMaxDeliver returns the number of times a message the
+// function of a trigger fails on is delivered.
+func natsJetStreamMaxDeliver(trigger *fv1.MessageQueueTrigger) int {
+	if trigger.Spec.NatsJetStream.MaxDeliver > 0 {
+		return trigger.Spec.NatsJetStream.MaxDeliver
+	}
+	return natsJetStreamDefaultMaxDeliver
+}
+
+// ensureConsumer creates the durable consumer of a trigger if it doesn't
+// exist. The consumer isn't owned by the subscription, so that it's kept
+// when the trigger is updated and its messages aren't delivered again.
+func (njs NatsJetStream) ensureConsumer(js nats.JetStreamContext, trigger *fv1.MessageQueueTrigger, cc *nats.ConsumerConfig) error {
+	stream := trigger.Spec.NatsJetStream.Stream
+	info, err := js.ConsumerInfo(stream, cc.Durable)
+	if err == nil {
+		// consumers can't be changed, the existing one is used as is
+		if info.Config.FilterSubject != cc.FilterSubject || info.Config.AckWait != cc.AckWait {
+			njs.logger.Warn("the existing consumer has different settings than the trigger, delete the consumer to apply them",
+				zap.String("stream", stream),
+				zap.String("consumer", cc.Durable),
+				zap.String("trigger", trigger.Metadata.Name))
+		}
+		return nil
+	}
+
+	_, err = js.AddConsumer(stream, cc)
+	if err != nil {
+		return errors.Wrapf(err, "error creating consumer %v of stream %v", cc.Durable, stream)
+	}
+	return nil
+}
+
+func (njs NatsJetStream) subscribe(trigger *fv1.MessageQueueTrigger) (messageQueueSubscription, error) {
+	njs.logger.Info("inside nats jetstream subscribe", zap.String("trigger", trigger.Metadata.Name))
+
+	if trigger.Spec.NatsJetStream == nil {
+		return nil, errors.Errorf("no stream set in trigger %v", trigger.Metadata.Name)
+	}
+
+	tconn, err := njs.triggerConnection(trigger)
+	if err != nil {
+		return nil, err
+	}
+	conn := njs.conn
+	if tconn != nil {
+		conn = tconn
+	}
+	closeTriggerConn := func() {
+		if tconn != nil {
+			tconn.Close()
+		}
+	}
+
+	js, err := conn.JetStream()
+	if err != nil {
+		closeTriggerConn()
+		return nil, errors.Wrap(err, "error getting jetstream context")
+	}
+
+	cc := natsJetStreamConsumerConfig(trigger)
+	err = njs.ensureConsumer(js, trigger, cc)
+	if err != nil {
+		closeTriggerConn()
+		return nil, err
+	}
+
+	stream := trigger.Spec.NatsJetStream.Stream
+	sub, err := js.Subscribe(trigger.Spec.Topic, func(msg *nats.Msg) {
+		natsJetStreamMsgHandler(&njs, js, trigger, cc, msg)
+	}, nats.BindStream(stream), nats.Durable(cc.Durable), nats.ManualAck())
+	if err != nil {
+		closeTriggerConn()
+		return nil, errors.Wrapf(err, "error subscribing to consumer %v of stream %v", cc.Durable, stream)
+	}
+
+	njs.logger.Info("created a new subscriber",
+		zap.String("input topic", trigger.Spec.Topic),
+		zap.String("stream", stream),
+		zap.String("consumer", cc.Durable),
+		zap.String("output topic", trigger.Spec.ResponseTopic),
+		zap.String("error topic", trigger.Spec.ErrorTopic),
+		zap.String("trigger name", trigger.Metadata.Name),
+		zap.String("function namespace", trigger.Metadata.Namespace),
+		zap.String("function name", trigger.Spec.FunctionReference.Name))
+
+	return natsJetStreamSubscription{sub: sub, conn: tconn}, nil
+}
+
+func (njs NatsJetStream) unsubscribe(subscription messageQueueSubscription) error {
+	s := subscription.(natsJetStreamSubscription)
+	// the consumer of a bound subscription is kept
+	err := s.sub.Unsubscribe()
+	if s.conn != nil {
+		s.conn.Close()
+	}
+	return err
+}
+
+func isTopicValidForNatsJetStream(topic string) bool {
+	return fv1.IsValidNatsSubject(topic)
+}
+
+// natsJetStreamMsgID returns an ID of a message, unique in its stream. The
+// responses and the errors of a message are published with it, so that
+// JetStream drops the duplicates of redelivered messages.
+func natsJetStreamMsgID(meta *nats.MsgMetadata) string {
+	return fmt.Sprintf("%v-%v", meta.Stream, meta.Sequence.Stream)
+}
+
+// natsJetStreamReply returns a response or an error message published for
+// a message.
+func natsJetStreamReply(subject string, data []byte, header http.Header) *nats.Msg {
+	reply := nats.NewMsg(subject)
+	reply.Data = data
+	for k, v := range header {
+		reply.Header[k] = v
+	}
+	return reply
+}
+
+// natsJetStreamMsgHandler invokes the function of the trigger with a
+// message. The message is acknowledged if the function succeeds. Otherwise
+// it's redelivered until it was delivered MaxDeliver times, and then sent
+// to the error topic and terminated.
+func natsJetStreamMsgHandler(njs *NatsJetStream, js nats.JetStreamContext, trigger *fv1.MessageQueueTrigger, cc *nats.ConsumerConfig, msg *nats.Msg) {
+	start := time.Now()
+	success := false
+	defer func() {
+		observeMessage(trigger, start, success)
+	}()
+
+	meta, err := msg.Metadata()
+	if err != nil {
+		njs.logger.Error("error reading metadata of message, not a jetstream message",
+			zap.Error(err),
+			zap.String("trigger", trigger.Metadata.Name))
+		return
+	}
+	msgID := natsJetStreamMsgID(meta)
+
+	// keep the message from being redelivered while the function runs
+	done := make(chan struct{})
+	defer close(done)
+	go func() {
+		ticker := time.NewTicker(cc.AckWait / 2)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-done:
+				return
+			case <-ticker.C:
+				if err := msg.InProgress(); err != nil {
+					njs.logger.Warn("failed to extend ack wait of message", zap.Error(err), zap.String("trigger", trigger.Metadata.Name))
+				}
+			}
+		}
+	}()
+
+	// Set the headers came from the message
+	headers := http.Header{}
+	for k, v := range msg.Header {
+		headers[k] = v
+	}
+	headers.Set("X-Fission-MQTrigger-MessageId", msgID)
+
+	resp, body, err := invokeFunction(context.Background(), njs.logger, njs.routerUrl, trigger, msg.Data, headers)
+	if err != nil {
+		natsJetStreamErrorHandler(njs.logger, js, trigger, msg, meta, err)
+		return
+	}
+	success = true
+
+	if len(trigger.Spec.ResponseTopic) > 0 {
+		_, err = js.PublishMsg(natsJetStreamReply(trigger.Spec.ResponseTopic, body, resp.Header), nats.MsgId(msgID))
+		if err != nil {
+			observeError(trigger, errorTypePublish)
+			njs.logger.Warn("failed to publish response body from function invocation to topic",
+				zap.Error(err),
+				zap.String("topic", trigger.Spec.ResponseTopic),
+				zap.String("function", trigger.Spec.FunctionReference.Name))
+		}
+	}
+
+	// the ack is confirmed by the server, the message isn't redelivered
+	// once it returns
+	err = msg.AckSync()
+	if err != nil {
+		njs.logger.Error("failed to ack message", zap.Error(err), zap.String("trigger", trigger.Metadata.Name))
+	}
+}
+
+// natsJetStreamErrorHandler redelivers a message the function failed on,
+// or sends the error to the error topic, if the trigger has one, and
+// terminates the message once it was delivered MaxDeliver times.
+func natsJetStreamErrorHandler(logger *zap.Logger, js nats.JetStreamContext, trigger *fv1.MessageQueueTrigger,
+	msg *nats.Msg, meta *nats.MsgMetadata, err error) {
+
+	if int(meta.NumDelivered) < natsJetStreamMaxDeliver(trigger) {
+		logger.Warn("function invocation failed, message will be redelivered",
+			zap.String("message", err.Error()),
+			zap.String("trigger", trigger.Metadata.Name),
+			zap.Uint64("delivered", meta.NumDelivered),
+			zap.String("function", trigger.Spec.FunctionReference.Name))
+		if e := msg.Nak(); e != nil {
+			logger.Error("failed to nak message", zap.Error(e), zap.String("trigger", trigger.Metadata.Name))
+		}
+		return
+	}
+
+	if len(trigger.Spec.ErrorTopic) > 0 {
+		_, e := js.PublishMsg(natsJetStreamReply(trigger.Spec.ErrorTopic, []byte(err.Error()), nil), nats.MsgId(natsJetStreamMsgID(meta)))
+		if e != nil {
+			observeError(trigger, errorTypePublish)
+			logger.Error("failed to publish message to error topic",
+				zap.Error(e),
+				zap.String("trigger", trigger.Metadata.Name),
+				zap.String("message", err.Error()),
+				zap.String("topic", trigger.Spec.ErrorTopic))
+		}
+	}
+
+	logger.Error("terminating message of failed function invocation",
+		zap.String("message", err.Error()), zap.String("trigger", trigger.Metadata.Name), zap.String("function", trigger.Spec.FunctionReference.Name))
+	if e := msg.Term(); e != nil {
+		logger.Error("failed to terminate message", zap.Error(e), zap.String("trigger", trigger.Metadata.Name))
+	}
+}
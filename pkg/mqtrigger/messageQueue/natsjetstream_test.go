@@ -0,0 +1,72 @@
+/*
+Copyright 2019 The Fission Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package messageQueue
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/nats-io/nats.go"
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	fv1 "github.com/fission/fission/pkg/apis/fission.io/v1"
+)
+
+func TestNatsJetStreamConsumerConfig(t *testing.T) {
+	trigger := &fv1.MessageQueueTrigger{
+		Metadata: metav1.ObjectMeta{Name: "foo", Namespace: "default", UID: "1234"},
+		Spec: fv1.MessageQueueTriggerSpec{
+			MessageQueueType: fv1.MessageQueueTypeNatsJetStream,
+			Topic:            "orders.created",
+			NatsJetStream:    &fv1.NatsJetStreamConfig{Stream: "ORDERS"},
+		},
+	}
+
+	cc := natsJetStreamConsumerConfig(trigger)
+	assert.Equal(t, "fission-1234", cc.Durable)
+	assert.Equal(t, "orders.created", cc.FilterSubject)
+	assert.Equal(t, 30*time.Second, cc.AckWait)
+	assert.Equal(t, nats.AckExplicitPolicy, cc.AckPolicy)
+	assert.Equal(t, -1, cc.MaxDeliver, "messages of a stopped mqtrigger are always redelivered")
+	assert.Equal(t, 1, natsJetStreamMaxDeliver(trigger))
+
+	trigger.Spec.NatsJetStream = &fv1.NatsJetStreamConfig{
+		Stream:     "ORDERS",
+		Durable:    "billing",
+		AckWait:    120,
+		MaxDeliver: 5,
+	}
+	cc = natsJetStreamConsumerConfig(trigger)
+	assert.Equal(t, "billing", cc.Durable)
+	assert.Equal(t, 2*time.Minute, cc.AckWait)
+	assert.Equal(t, 5, natsJetStreamMaxDeliver(trigger))
+}
+
+func TestNatsJetStreamReply(t *testing.T) {
+	meta := &nats.MsgMetadata{Stream: "ORDERS"}
+	meta.Sequence.Stream = 42
+	assert.Equal(t, "ORDERS-42", natsJetStreamMsgID(meta))
+
+	header := http.Header{}
+	header.Set("Content-Type", "application/json")
+	reply := natsJetStreamReply("orders.processed", []byte("response"), header)
+	assert.Equal(t, "orders.processed", reply.Subject)
+	assert.Equal(t, []byte("response"), reply.Data)
+	assert.Equal(t, "application/json", reply.Header.Get("Content-Type"))
+}
@@ -0,0 +1,279 @@
+/*
+Copyright 2019 The Fission Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package messageQueue
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/streadway/amqp"
+	"go.uber.org/zap"
+	"k8s.io/client-go/kubernetes"
+
+	fv1 "github.com/fission/fission/pkg/apis/fission.io/v1"
+)
+
+type (
+	RabbitMQ struct {
+		logger     *zap.Logger
+		routerUrl  string
+		url        string
+		kubeClient kubernetes.Interface
+	}
+
+	// rabbitMQSubscription holds the connection of a trigger, closing it
+	// cancels the consumer and closes the channel.
+	rabbitMQSubscription struct {
+		conn *amqp.Connection
+	}
+)
+
+func makeRabbitMQMessageQueue(logger *zap.Logger, kubeClient kubernetes.Interface, routerUrl string, mqCfg MessageQueueConfig) (MessageQueue, error) {
+	if len(routerUrl) == 0 {
+		return nil, errors.New("the router URL is empty")
+	}
+	if len(mqCfg.Url) == 0 {
+		return nil, errors.New("the rabbitmq URL is empty")
+	}
+
+	u, err := url.Parse(mqCfg.Url)
+	if err != nil {
+		return nil, errors.Wrap(err, "error parsing rabbitmq URL")
+	}
+
+	rabbitmq := RabbitMQ{
+		logger:     logger.Named("rabbitmq"),
+		routerUrl:  routerUrl,
+		url:        mqCfg.Url,
+		kubeClient: kubeClient,
+	}
+
+	logger.Info("created rabbitmq queue", zap.String("host", u.Host), zap.String("vhost", u.Path))
+
+	return rabbitmq, nil
+}
+
+// checkConnection checks the default server, the servers of the triggers
+// are not checked.
+func (rabbitmq RabbitMQ) checkConnection() error {
+	uri, err := amqp.ParseURI(rabbitmq.url)
+	if err != nil {
+		return errors.Wrap(err, "error parsing rabbitmq URL")
+	}
+	return dialCheck([]string{net.JoinHostPort(uri.Host, strconv.Itoa(uri.Port))})
+}
+
+func isTopicValidForRabbitMQ(topic string) bool {
+	return fv1.IsValidRabbitMQQueue(topic)
+}
+
+// getTriggerConnection returns the AMQP URL of a trigger, with the
+// credentials of the trigger secret if it has one, and the TLS config
+// with the certificates of the secret, if any.
+func (rabbitmq RabbitMQ) getTriggerConnection(trigger *fv1.MessageQueueTrigger) (string, *tls.Config, error) {
+	secret, err := getTriggerSecret(rabbitmq.kubeClient, trigger)
+	if err != nil {
+		return "", nil, err
+	}
+	if secret == nil {
+		return rabbitmq.url, nil, nil
+	}
+
+	tlsConfig, err := getSecretTLSConfig(secret)
+	if err != nil {
+		return "", nil, err
+	}
+
+	username, ok := secret[secretUsernameKey]
+	if !ok {
+		return rabbitmq.url, tlsConfig, nil
+	}
+	u, err := url.Parse(rabbitmq.url)
+	if err != nil {
+		return "", nil, errors.Wrap(err, "error parsing rabbitmq URL")
+	}
+	u.User = url.UserPassword(string(username), string(secret[secretPasswordKey]))
+	return u.String(), tlsConfig, nil
+}
+
+func (rabbitmq RabbitMQ) subscribe(trigger *fv1.MessageQueueTrigger) (messageQueueSubscription, error) {
+	rabbitmq.logger.Info("inside rabbitmq subscribe", zap.String("trigger", trigger.Metadata.Name))
+
+	var exchange, routingKey string
+	prefetch := 1
+	if trigger.Spec.RabbitMQ != nil {
+		exchange = trigger.Spec.RabbitMQ.Exchange
+		routingKey = trigger.Spec.RabbitMQ.RoutingKey
+		if trigger.Spec.RabbitMQ.Prefetch > 0 {
+			prefetch = trigger.Spec.RabbitMQ.Prefetch
+		}
+	}
+	if len(routingKey) == 0 {
+		routingKey = trigger.Spec.Topic
+	}
+
+	u, tlsConfig, err := rabbitmq.getTriggerConnection(trigger)
+	if err != nil {
+		return nil, err
+	}
+
+	var conn *amqp.Connection
+	if tlsConfig != nil {
+		conn, err = amqp.DialTLS(u, tlsConfig)
+	} else {
+		conn, err = amqp.Dial(u)
+	}
+	if err != nil {
+		return nil, errors.Wrapf(err, "error connecting to rabbitmq for trigger %v", trigger.Metadata.Name)
+	}
+
+	deliveries, ch, err := func() (<-chan amqp.Delivery, *amqp.Channel, error) {
+		ch, err := conn.Channel()
+		if err != nil {
+			return nil, nil, errors.Wrap(err, "error opening channel")
+		}
+		err = ch.Qos(prefetch, 0, false)
+		if err != nil {
+			return nil, nil, errors.Wrap(err, "error setting prefetch count")
+		}
+		_, err = ch.QueueDeclare(trigger.Spec.Topic, true, false, false, false, nil)
+		if err != nil {
+			return nil, nil, errors.Wrapf(err, "error declaring queue %v", trigger.Spec.Topic)
+		}
+		// Every queue is bound to the default exchange with its name,
+		// other exchanges have to be declared by the user beforehand.
+		if len(exchange) > 0 {
+			err = ch.QueueBind(trigger.Spec.Topic, routingKey, exchange, false, nil)
+			if err != nil {
+				return nil, nil, errors.Wrapf(err, "error binding queue %v to exchange %v", trigger.Spec.Topic, exchange)
+			}
+		}
+		deliveries, err := ch.Consume(trigger.Spec.Topic, string(trigger.Metadata.UID), false, false, false, false, nil)
+		if err != nil {
+			return nil, nil, errors.Wrapf(err, "error consuming from queue %v", trigger.Spec.Topic)
+		}
+		return deliveries, ch, nil
+	}()
+	if err != nil {
+		conn.Close()
+		return nil, errors.Wrapf(err, "error subscribing rabbitmq trigger %v", trigger.Metadata.Name)
+	}
+
+	rabbitmq.logger.Info("created a new consumer",
+		zap.String("exchange", exchange),
+		zap.String("routing key", routingKey),
+		zap.Int("prefetch", prefetch),
+		zap.String("input queue", trigger.Spec.Topic),
+		zap.String("output topic", trigger.Spec.ResponseTopic),
+		zap.String("error topic", trigger.Spec.ErrorTopic),
+		zap.String("trigger name", trigger.Metadata.Name),
+		zap.String("function namespace", trigger.Metadata.Namespace),
+		zap.String("function name", trigger.Spec.FunctionReference.Name))
+
+	// consume connection errors
+	go func() {
+		for err := range conn.NotifyClose(make(chan *amqp.Error, 1)) {
+			rabbitmq.logger.Error("connection closed", zap.Error(err), zap.String("trigger", trigger.Metadata.Name))
+		}
+	}()
+
+	// consume messages
+	go func() {
+		for d := range deliveries {
+			rabbitmq.logger.Debug("calling message handler", zap.String("message", string(d.Body)))
+			start := time.Now()
+			ok := rabbitMQMsgHandler(&rabbitmq, ch, exchange, trigger, &d)
+			observeMessage(trigger, start, ok)
+			if ok {
+				d.Ack(false)
+			} else {
+				// Failed messages are not requeued to avoid redelivering them
+				// forever, a dead letter exchange of the queue receives them.
+				d.Nack(false, false)
+			}
+		}
+	}()
+
+	return rabbitMQSubscription{conn: conn}, nil
+}
+
+func (rabbitmq RabbitMQ) unsubscribe(subscription messageQueueSubscription) error {
+	return subscription.(rabbitMQSubscription).conn.Close()
+}
+
+func rabbitMQMsgHandler(rabbitmq *RabbitMQ, ch *amqp.Channel, exchange string, trigger *fv1.MessageQueueTrigger, d *amqp.Delivery) bool {
+	// Set the headers came from the AMQP message
+	msgHeaders := http.Header{}
+	for k, v := range d.Headers {
+		msgHeaders.Set(k, fmt.Sprintf("%v", v))
+	}
+
+	resp, body, err := invokeFunction(context.Background(), rabbitmq.logger, rabbitmq.routerUrl, trigger, d.Body, msgHeaders)
+	if err != nil {
+		rabbitMQErrorHandler(rabbitmq.logger, trigger, ch, exchange, err)
+		return false
+	}
+	if len(trigger.Spec.ResponseTopic) > 0 {
+		headers := amqp.Table{}
+		for k, v := range resp.Header {
+			headers[k] = strings.Join(v, ",")
+		}
+		err = ch.Publish(exchange, trigger.Spec.ResponseTopic, false, false, amqp.Publishing{
+			Headers:       headers,
+			ContentType:   resp.Header.Get("Content-Type"),
+			CorrelationId: d.CorrelationId,
+			Body:          body,
+		})
+		if err != nil {
+			observeError(trigger, errorTypePublish)
+			rabbitmq.logger.Warn("failed to publish response body from function invocation to topic",
+				zap.Error(err),
+				zap.String("topic", trigger.Spec.ResponseTopic),
+				zap.String("function", trigger.Spec.FunctionReference.Name))
+			return false
+		}
+	}
+	return true
+}
+
+func rabbitMQErrorHandler(logger *zap.Logger, trigger *fv1.MessageQueueTrigger, ch *amqp.Channel, exchange string, err error) {
+	if len(trigger.Spec.ErrorTopic) > 0 {
+		e := ch.Publish(exchange, trigger.Spec.ErrorTopic, false, false, amqp.Publishing{
+			ContentType: "text/plain",
+			Body:        []byte(err.Error()),
+		})
+		if e != nil {
+			observeError(trigger, errorTypePublish)
+			logger.Error("failed to publish message to error topic",
+				zap.Error(e),
+				zap.String("trigger", trigger.Metadata.Name),
+				zap.String("message", err.Error()),
+				zap.String("topic", trigger.Spec.ErrorTopic))
+		}
+	} else {
+		logger.Error("message received to publish to error topic, but no error topic was set",
+			zap.String("message", err.Error()), zap.String("trigger", trigger.Metadata.Name), zap.String("function", trigger.Spec.FunctionReference.Name))
+	}
+}
@@ -0,0 +1,87 @@
+/*
+Copyright 2019 The Fission Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package messageQueue
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+
+	"github.com/pkg/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+
+	fv1 "github.com/fission/fission/pkg/apis/fission.io/v1"
+)
+
+// Keys of the secret of a trigger, see MessageQueueTriggerSpec.Secret.
+const (
+	secretUsernameKey   = "username"
+	secretPasswordKey   = "password"
+	secretCACertKey     = "ca.crt"
+	secretClientCertKey = "tls.crt"
+	secretClientKeyKey  = "tls.key"
+	// service account key of GCP Pub/Sub
+	secretGCPCredentialsKey = "credentials.json"
+	// connection string of an Azure Service Bus namespace
+	secretAzureConnectionStringKey = "connectionString"
+)
+
+// getTriggerSecret returns the data of the connection secret of a trigger,
+// or nil if the trigger has none.
+func getTriggerSecret(kubeClient kubernetes.Interface, trigger *fv1.MessageQueueTrigger) (map[string][]byte, error) {
+	secretName := trigger.Spec.Secret
+	if len(secretName) == 0 && trigger.Spec.RabbitMQ != nil {
+		secretName = trigger.Spec.RabbitMQ.CredentialSecret
+	}
+	if len(secretName) == 0 {
+		return nil, nil
+	}
+
+	secret, err := kubeClient.CoreV1().Secrets(trigger.Metadata.Namespace).Get(secretName, metav1.GetOptions{})
+	if err != nil {
+		return nil, errors.Wrapf(err, "error getting secret %v of trigger %v", secretName, trigger.Metadata.Name)
+	}
+	return secret.Data, nil
+}
+
+// getSecretTLSConfig returns the TLS config with the CA certificate and the
+// client certificate in the secret, or nil if the secret has neither.
+func getSecretTLSConfig(data map[string][]byte) (*tls.Config, error) {
+	caCert, hasCA := data[secretCACertKey]
+	cert, hasCert := data[secretClientCertKey]
+	key, hasKey := data[secretClientKeyKey]
+	if !hasCA && !hasCert && !hasKey {
+		return nil, nil
+	}
+
+	config := &tls.Config{}
+	if hasCA {
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(caCert) {
+			return nil, errors.Errorf("no valid PEM certificate found in the %q key of the secret", secretCACertKey)
+		}
+		config.RootCAs = pool
+	}
+	if hasCert || hasKey {
+		certificate, err := tls.X509KeyPair(cert, key)
+		if err != nil {
+			return nil, errors.Wrapf(err, "error loading the client certificate from the %q and %q keys of the secret", secretClientCertKey, secretClientKeyKey)
+		}
+		config.Certificates = []tls.Certificate{certificate}
+	}
+	return config, nil
+}
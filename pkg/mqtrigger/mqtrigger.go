@@ -17,17 +17,38 @@ limitations under the License.
 package mqtrigger
 
 import (
+	"net/http"
 	"os"
 
 	"github.com/pkg/errors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
 	"go.uber.org/zap"
 
 	"github.com/fission/fission/pkg/crd"
+	"github.com/fission/fission/pkg/health"
 	"github.com/fission/fission/pkg/mqtrigger/messageQueue"
 )
 
+const (
+	// healthAddr is the address the message queue trigger manager serves
+	// /healthz and /readyz at.
+	healthAddr = ":8888"
+
+	// metricAddr is the address the metrics of the triggers are served at.
+	metricAddr = ":8080"
+)
+
+func serveMetric(logger *zap.Logger) {
+	// Expose the registered metrics via HTTP.
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", promhttp.Handler())
+	err := http.ListenAndServe(metricAddr, mux)
+
+	logger.Fatal("done listening on metrics endpoint", zap.Error(err))
+}
+
 func Start(logger *zap.Logger, routerUrl string) error {
-	fissionClient, _, _, err := crd.MakeFissionClient()
+	fissionClient, kubeClient, _, err := crd.MakeFissionClient()
 	if err != nil {
 		return errors.Wrap(err, "failed to get fission or kubernetes client")
 	}
@@ -44,6 +65,12 @@ func Start(logger *zap.Logger, routerUrl string) error {
 		MQType: mqType,
 		Url:    mqUrl,
 	}
-	messageQueue.MakeMessageQueueTriggerManager(logger, fissionClient, routerUrl, mqCfg)
+	mqTriggerMgr := messageQueue.MakeMessageQueueTriggerManager(logger, fissionClient, kubeClient, routerUrl, mqCfg)
+
+	checker := health.MakeChecker("mqtrigger")
+	checker.AddCheck("crd", fissionClient.CheckCRDs)
+	checker.AddCheck("messagequeue", mqTriggerMgr.CheckConnection)
+	go checker.Serve(logger, healthAddr)
+	go serveMetric(logger)
 	return nil
 }
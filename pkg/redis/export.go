@@ -0,0 +1,156 @@
+/*
+Copyright 2019 The Fission Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package redis
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/gomodule/redigo/redis"
+	"github.com/pkg/errors"
+	"go.uber.org/zap"
+
+	"github.com/fission/fission/pkg/redis/build/gen"
+)
+
+// ExportedRecord is a recorded request in the export format of records,
+// which is one JSON object per line.
+type ExportedRecord struct {
+	ReqUID    string               `json:"reqUID"`
+	Timestamp int64                `json:"timestamp"`
+	Trigger   string               `json:"trigger,omitempty"`
+	Req       *redisCache.Request  `json:"req"`
+	Resp      *redisCache.Response `json:"resp,omitempty"`
+}
+
+// RecordsExport writes all the records to w, one ExportedRecord per line.
+func RecordsExport(logger *zap.Logger, w io.Writer) (int, error) {
+	client, err := NewClient()
+	if err != nil {
+		return 0, errors.Wrap(err, "failed to create redis client")
+	}
+	defer client.Close()
+
+	enc := json.NewEncoder(w)
+	count := 0
+	iter := 0
+	for {
+		arr, err := redis.Values(client.Do("SCAN", iter))
+		if err != nil {
+			return count, err
+		}
+		iter, _ = redis.Int(arr[0], nil)
+		keys, _ := redis.Strings(arr[1], nil)
+		for _, key := range keys {
+			if !strings.HasPrefix(key, "REQ") {
+				continue
+			}
+
+			vals, err := redis.Values(client.Do("HMGET", key, "ReqResponse", "Timestamp"))
+			if err != nil {
+				logger.Error("error retrieving request from redis", zap.Error(err), zap.String("id", key))
+				return count, err
+			}
+			data, err := redis.Bytes(vals[0], nil)
+			if err != nil {
+				return count, errors.Wrapf(err, "error retrieving request %v", key)
+			}
+			// records without a timestamp are still exported
+			timestamp, _ := redis.Int64(vals[1], nil)
+
+			entry, err := deserializeReqResponse(data, key)
+			if err != nil {
+				logger.Error("error deserializing request from redis", zap.Error(err), zap.String("id", key))
+				return count, err
+			}
+
+			err = enc.Encode(ExportedRecord{
+				ReqUID:    key,
+				Timestamp: timestamp,
+				Trigger:   entry.Trigger,
+				Req:       entry.Req,
+				Resp:      entry.Resp,
+			})
+			if err != nil {
+				return count, errors.Wrap(err, "error writing record")
+			}
+			count++
+		}
+		if iter == 0 {
+			break
+		}
+	}
+
+	return count, nil
+}
+
+// RecordsImport stores the records read from r, one ExportedRecord per line,
+// and adds them to the recorder if recorderName isn't empty, so that they can
+// be filtered by function or trigger. Records with the same reqUID are
+// overwritten.
+func RecordsImport(logger *zap.Logger, r io.Reader, recorderName string) (int, error) {
+	client, err := NewClient()
+	if err != nil {
+		return 0, errors.Wrap(err, "failed to create redis client")
+	}
+	defer client.Close()
+
+	dec := json.NewDecoder(r)
+	count := 0
+	for {
+		var record ExportedRecord
+		err := dec.Decode(&record)
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			return count, errors.Wrapf(err, "error decoding record %v", count+1)
+		}
+
+		if !strings.HasPrefix(record.ReqUID, "REQ") || record.Req == nil {
+			return count, fmt.Errorf("invalid record %v: a record needs a reqUID starting with REQ and a request", count+1)
+		}
+
+		data, err := proto.Marshal(&redisCache.UniqueRequest{
+			Req:     record.Req,
+			Resp:    record.Resp,
+			Trigger: record.Trigger,
+		})
+		if err != nil {
+			return count, errors.Wrapf(err, "error marshalling record %v", record.ReqUID)
+		}
+
+		_, err = client.Do("HMSET", record.ReqUID, "ReqResponse", data, "Timestamp", record.Timestamp, "Trigger", record.Trigger)
+		if err != nil {
+			logger.Error("error saving request", zap.Error(err), zap.String("id", record.ReqUID))
+			return count, err
+		}
+
+		if len(recorderName) > 0 {
+			_, err = client.Do("LPUSH", recorderName, record.ReqUID)
+			if err != nil {
+				logger.Error("error saving recorder-request pair", zap.Error(err), zap.String("id", record.ReqUID))
+				return count, err
+			}
+		}
+		count++
+	}
+
+	return count, nil
+}
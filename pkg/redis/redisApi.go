@@ -22,6 +22,7 @@ import (
 	"fmt"
 	"io/ioutil"
 	"net/http"
+	"net/url"
 	"strconv"
 	"strings"
 	"time"
@@ -344,7 +345,10 @@ func includesTrigger(triggers []string, query string) bool {
 	return false
 }
 
-func ReplayByReqUID(logger *zap.Logger, routerUrl string, queriedID string) ([]byte, error) {
+// ReplayByReqUID replays the request with the given reqUID. If targetPath
+// isn't empty, the request is sent to that router path instead of its
+// original one, e.g. to replay traffic against a different function.
+func ReplayByReqUID(logger *zap.Logger, routerUrl string, queriedID string, targetPath string) ([]byte, error) {
 	client, err := NewClient()
 	if err != nil {
 		return nil, errors.Wrap(err, "failed to create redis client")
@@ -367,7 +371,7 @@ func ReplayByReqUID(logger *zap.Logger, routerUrl string, queriedID string) ([]b
 		return nil, err
 	}
 
-	replayed, err := ReplayRequest(routerUrl, entry.Req)
+	replayed, err := ReplayRequest(routerUrl, entry.Req, targetPath)
 	if err != nil {
 		logger.Error("error replaying request", zap.Error(err))
 		return nil, err
@@ -382,10 +386,18 @@ func ReplayByReqUID(logger *zap.Logger, routerUrl string, queriedID string) ([]b
 	return resp, nil
 }
 
-func ReplayRequest(routerUrl string, request *redisCache.Request) ([]string, error) {
+func ReplayRequest(routerUrl string, request *redisCache.Request, targetPath string) ([]string, error) {
 	path := request.URL["Path"] // Includes slash prefix
 	payload := request.URL["Payload"]
 
+	if len(targetPath) > 0 {
+		var err error
+		path, err = retargetPath(path, targetPath)
+		if err != nil {
+			return nil, err
+		}
+	}
+
 	targetUrl := fmt.Sprintf("%v%v", routerUrl, path)
 
 	var req *http.Request
@@ -421,3 +433,15 @@ func ReplayRequest(routerUrl string, request *redisCache.Request) ([]string, err
 
 	return []string{bodyStr}, nil
 }
+
+// retargetPath replaces the path of a recorded request with targetPath,
+// keeping the query string of the recorded request.
+func retargetPath(recordedPath string, targetPath string) (string, error) {
+	u, err := url.Parse(recordedPath)
+	if err != nil {
+		return "", errors.Wrapf(err, "error parsing recorded path %v", recordedPath)
+	}
+	u.Path = targetPath
+	u.RawPath = ""
+	return u.String(), nil
+}
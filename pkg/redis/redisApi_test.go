@@ -0,0 +1,38 @@
+/*
+Copyright 2018 The Fission Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package redis
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRetargetPath(t *testing.T) {
+	for _, tc := range []struct {
+		recorded string
+		target   string
+		expected string
+	}{
+		{"/hello", "/fission-function/hello-v2", "/fission-function/hello-v2"},
+		{"/hello?name=fission&x=1", "/fission-function/staging/hello", "/fission-function/staging/hello?name=fission&x=1"},
+	} {
+		path, err := retargetPath(tc.recorded, tc.target)
+		assert.NoError(t, err)
+		assert.Equal(t, tc.expected, path)
+	}
+}
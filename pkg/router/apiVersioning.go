@@ -0,0 +1,78 @@
+/*
+Copyright 2019 The Fission Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package router
+
+import (
+	"mime"
+	"net/http"
+	"regexp"
+	"sort"
+	"strings"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	fv1 "github.com/fission/fission/pkg/apis/fission.io/v1"
+)
+
+// vendorMediaTypeVersion matches the version of vendor media types, e.g.
+// the 2 of application/vnd.example.v2+json.
+var vendorMediaTypeVersion = regexp.MustCompile(`^vnd\..*\.v([a-zA-Z0-9._\-]+?)(\+.*)?$`)
+
+// getAPIVersion returns the API version of a request, from the version
+// header or, if enabled, the Accept header. It's empty if the request has
+// no version.
+func getAPIVersion(versioning *fv1.APIVersioning, req *http.Request) string {
+	header := versioning.Header
+	if len(header) == 0 {
+		header = fv1.APIVersionHeaderDefault
+	}
+	if version := strings.TrimSpace(req.Header.Get(header)); len(version) > 0 {
+		return version
+	}
+	if !versioning.Accept {
+		return ""
+	}
+
+	for _, accept := range req.Header[http.CanonicalHeaderKey("Accept")] {
+		for _, mediaRange := range strings.Split(accept, ",") {
+			mediaType, params, err := mime.ParseMediaType(mediaRange)
+			if err != nil {
+				continue
+			}
+			if version := params["version"]; len(version) > 0 {
+				return version
+			}
+			if i := strings.Index(mediaType, "/"); i >= 0 {
+				if m := vendorMediaTypeVersion.FindStringSubmatch(mediaType[i+1:]); m != nil {
+					return m[1]
+				}
+			}
+		}
+	}
+	return ""
+}
+
+// apiVersions returns the sorted versions of a trigger with API
+// versioning.
+func apiVersions(versionedFunctions map[string]*metav1.ObjectMeta) []string {
+	versions := make([]string, 0, len(versionedFunctions))
+	for version := range versionedFunctions {
+		versions = append(versions, version)
+	}
+	sort.Strings(versions)
+	return versions
+}
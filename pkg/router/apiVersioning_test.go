@@ -0,0 +1,74 @@
+package router
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	fv1 "github.com/fission/fission/pkg/apis/fission.io/v1"
+)
+
+func TestGetAPIVersion(t *testing.T) {
+	versioning := &fv1.APIVersioning{Functions: map[string]string{"2": "users-v2"}}
+	for _, test := range []struct {
+		name    string
+		header  string
+		value   string
+		accept  bool
+		version string
+	}{
+		{"no version", "", "", false, ""},
+		{"default header", fv1.APIVersionHeaderDefault, " 2 ", false, "2"},
+		{"accept ignored", "Accept", "application/json; version=2", false, ""},
+		{"accept parameter", "Accept", "text/html, application/json; version=3", true, "3"},
+		{"vendor media type", "Accept", "application/vnd.example.v2+json", true, "2"},
+		{"vendor media type without suffix", "Accept", "application/vnd.example.v2.1", true, "2.1"},
+		{"accept without version", "Accept", "application/json", true, ""},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			versioning.Accept = test.accept
+			req := httptest.NewRequest("GET", "/users", nil)
+			if len(test.header) > 0 {
+				req.Header.Set(test.header, test.value)
+			}
+			assert.Equal(t, test.version, getAPIVersion(versioning, req))
+		})
+	}
+
+	// the header takes precedence over the Accept header
+	versioning = &fv1.APIVersioning{Header: "Api-Version", Accept: true}
+	req := httptest.NewRequest("GET", "/users", nil)
+	req.Header.Set("Api-Version", "1")
+	req.Header.Set("Accept", "application/json; version=2")
+	assert.Equal(t, "1", getAPIVersion(versioning, req))
+}
+
+func TestUnsupportedAPIVersion(t *testing.T) {
+	trigger := &fv1.HTTPTrigger{
+		Metadata: metav1.ObjectMeta{Name: "users", Namespace: metav1.NamespaceDefault},
+		Spec: fv1.HTTPTriggerSpec{
+			APIVersioning: &fv1.APIVersioning{Functions: map[string]string{"1": "users", "2": "users-v2"}},
+		},
+	}
+	fh := functionHandler{
+		logger:      zap.NewNop(),
+		httpTrigger: trigger,
+		function:    &metav1.ObjectMeta{Name: "users", Namespace: metav1.NamespaceDefault},
+		versionedFunctions: map[string]*metav1.ObjectMeta{
+			"1": {Name: "users", Namespace: metav1.NamespaceDefault},
+			"2": {Name: "users-v2", Namespace: metav1.NamespaceDefault},
+		},
+	}
+
+	req := httptest.NewRequest("GET", "/users", nil)
+	req.Header.Set(fv1.APIVersionHeaderDefault, "3")
+	w := httptest.NewRecorder()
+	fh.handler(w, req)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Equal(t, errorClassUnsupportedVersion, w.Header().Get(HEADER_ERROR_CLASS))
+	assert.Contains(t, w.Body.String(), "1, 2")
+}
@@ -0,0 +1,249 @@
+/*
+Copyright 2019 The Fission Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package router
+
+import (
+	"crypto/subtle"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	"go.uber.org/zap"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+
+	fv1 "github.com/fission/fission/pkg/apis/fission.io/v1"
+	"github.com/fission/fission/pkg/utils"
+)
+
+const (
+	// HEADER_API_KEY carries the API key of requests to triggers with
+	// API key auth. Router removes it before calling the function.
+	HEADER_API_KEY = "X-Fission-Api-Key"
+
+	// HEADER_AUTH_SUBJECT is set by router on authenticated requests to
+	// the subject of the token, or the name of the API key.
+	HEADER_AUTH_SUBJECT = "X-Fission-Auth-Subject"
+
+	// authSecretTTL is how long the keys of a trigger are cached, i.e.
+	// how long a rotated or revoked key keeps working.
+	authSecretTTL = 30 * time.Second
+)
+
+type (
+	// authenticator checks the credentials of the requests of HTTP
+	// triggers with auth against the keys in the secrets of the
+	// triggers, so that functions can be exposed without an API gateway
+	// in front of router.
+	authenticator struct {
+		logger    *zap.Logger
+		getSecret func(namespace, name string) (map[string][]byte, error)
+
+		lock    sync.Mutex
+		secrets map[metadataKey]*authSecret
+	}
+
+	authSecret struct {
+		// ready is closed once the secret is read, requests needing the
+		// secret meanwhile wait for it instead of reading it again.
+		ready   chan struct{}
+		data    map[string][]byte
+		err     error
+		fetched time.Time
+	}
+
+	// authError is a request that failed authentication, as opposed to
+	// keys that cannot be read.
+	authError struct {
+		error
+	}
+)
+
+func makeAuthenticator(logger *zap.Logger, kubeClient *kubernetes.Clientset) *authenticator {
+	return &authenticator{
+		logger: logger.Named("authenticator"),
+		getSecret: func(namespace, name string) (map[string][]byte, error) {
+			secret, err := kubeClient.CoreV1().Secrets(namespace).Get(name, metav1.GetOptions{})
+			if err != nil {
+				return nil, err
+			}
+			return secret.Data, nil
+		},
+		secrets: make(map[metadataKey]*authSecret),
+	}
+}
+
+// secretData returns the data of a secret, from the cache if it was read
+// recently. Failures are cached too, so that a missing secret doesn't
+// make router hit the API server on every request.
+func (a *authenticator) secretData(namespace, name string) (map[string][]byte, error) {
+	key := metadataKey{Name: name, Namespace: namespace}
+
+	a.lock.Lock()
+	s, ok := a.secrets[key]
+	if ok && !s.expired() {
+		a.lock.Unlock()
+		<-s.ready
+		return s.data, s.err
+	}
+	s = &authSecret{ready: make(chan struct{})}
+	a.secrets[key] = s
+	a.lock.Unlock()
+
+	// the secret is read without holding the lock, so that the requests
+	// of other triggers don't wait for the API server.
+	s.data, s.err = a.getSecret(namespace, name)
+	if s.err != nil {
+		a.logger.Error("error reading auth secret of trigger", zap.Error(s.err),
+			zap.String("secret", name), zap.String("namespace", namespace))
+	}
+	s.fetched = time.Now()
+	close(s.ready)
+	return s.data, s.err
+}
+
+// expired returns true if the secret was read longer than authSecretTTL
+// ago. A secret still being read isn't expired.
+func (s *authSecret) expired() bool {
+	select {
+	case <-s.ready:
+		return time.Since(s.fetched) > authSecretTTL
+	default:
+		return false
+	}
+}
+
+// authenticate checks the credentials of a request to the trigger and
+// returns the authenticated subject. It returns an authError if the
+// credentials are missing or invalid.
+func (a *authenticator) authenticate(trigger *fv1.HTTPTrigger, r *http.Request) (string, error) {
+	auth := trigger.Spec.Auth
+
+	data, err := a.secretData(trigger.Metadata.Namespace, auth.Secret)
+	if err != nil {
+		return "", errors.Wrap(err, "error reading the keys of the trigger")
+	}
+
+	switch auth.Type {
+	case fv1.HTTPTriggerAuthJWT:
+		return authenticateJWT(auth, data, r)
+	case fv1.HTTPTriggerAuthAPIKey:
+		return authenticateAPIKey(data, r)
+	default:
+		return "", errors.Errorf("unknown auth type '%v'", auth.Type)
+	}
+}
+
+func authenticateJWT(auth *fv1.HTTPTriggerAuth, data map[string][]byte, r *http.Request) (string, error) {
+	key, ok := data[fv1.HTTPTriggerAuthJWTKey]
+	if !ok || len(key) == 0 {
+		return "", errors.Errorf("the secret has no '%v' entry", fv1.HTTPTriggerAuthJWTKey)
+	}
+
+	token := bearerToken(r)
+	if len(token) == 0 {
+		return "", authError{errors.New("missing bearer token in the Authorization header")}
+	}
+
+	claims, err := utils.VerifyJWT(token, key, time.Now())
+	if err != nil {
+		return "", authError{err}
+	}
+	if len(auth.Issuer) > 0 && claims.Issuer != auth.Issuer {
+		return "", authError{errors.New("token of another issuer")}
+	}
+	if len(auth.Audience) > 0 && !claims.Audience.Contains(auth.Audience) {
+		return "", authError{errors.New("token for another audience")}
+	}
+	return claims.Subject, nil
+}
+
+func authenticateAPIKey(data map[string][]byte, r *http.Request) (string, error) {
+	apiKey := r.Header.Get(HEADER_API_KEY)
+	if len(apiKey) == 0 {
+		return "", authError{errors.Errorf("missing API key in the %v header", HEADER_API_KEY)}
+	}
+
+	// compare with all the keys, so that the time taken doesn't tell
+	// which one is close to the given key
+	subject := ""
+	for name, key := range data {
+		if len(key) > 0 && subtle.ConstantTimeCompare([]byte(apiKey), key) == 1 {
+			subject = name
+		}
+	}
+	if len(subject) == 0 {
+		return "", authError{errors.New("invalid API key")}
+	}
+	return subject, nil
+}
+
+func bearerToken(r *http.Request) string {
+	header := r.Header.Get("Authorization")
+	if len(header) < len("Bearer ") || !strings.EqualFold(header[:len("Bearer ")], "Bearer ") {
+		return ""
+	}
+	return strings.TrimSpace(header[len("Bearer "):])
+}
+
+// checkAuth authenticates the request if the trigger has auth and writes
+// the error response if it fails. It returns whether the request may
+// proceed; the subject then replaces any subject header the client sent.
+func (fh *functionHandler) checkAuth(w http.ResponseWriter, r *http.Request) bool {
+	r.Header.Del(HEADER_AUTH_SUBJECT)
+	if fh.httpTrigger == nil || fh.httpTrigger.Spec.Auth == nil {
+		return true
+	}
+	defer r.Header.Del(HEADER_API_KEY)
+
+	if fh.authenticator == nil {
+		fh.problem(r, http.StatusInternalServerError, errorClassAuthUnavailable, "authentication is not available").
+			write(w)
+		return false
+	}
+
+	subject, err := fh.authenticator.authenticate(fh.httpTrigger, r)
+	if err != nil {
+		if authErr, ok := err.(authError); ok {
+			fh.logger.Debug("rejecting unauthenticated request", zap.Error(authErr.error),
+				zap.String("trigger_name", fh.httpTrigger.Metadata.Name))
+			scheme := "Bearer"
+			if fh.httpTrigger.Spec.Auth.Type == fv1.HTTPTriggerAuthAPIKey {
+				scheme = "ApiKey"
+			}
+			w.Header().Set("WWW-Authenticate", scheme+` realm="fission"`)
+			fh.problem(r, http.StatusUnauthorized, errorClassUnauthorized, authErr.Error()).
+				withHint("send the credentials of the trigger, see 'fission token create'").
+				write(w)
+			return false
+		}
+
+		fh.logger.Error("error authenticating request", zap.Error(err),
+			zap.String("trigger_name", fh.httpTrigger.Metadata.Name))
+		fh.problem(r, http.StatusInternalServerError, errorClassAuthUnavailable, "the keys of the trigger cannot be read").
+			withHint("check the auth secret of the trigger").
+			write(w)
+		return false
+	}
+
+	if len(subject) > 0 {
+		r.Header.Set(HEADER_AUTH_SUBJECT, subject)
+	}
+	return true
+}
@@ -0,0 +1,192 @@
+/*
+Copyright 2019 The Fission Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package router
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	fv1 "github.com/fission/fission/pkg/apis/fission.io/v1"
+	"github.com/fission/fission/pkg/utils"
+)
+
+func makeTestAuthHandler(auth *fv1.HTTPTriggerAuth, secrets map[string]map[string][]byte, reads *int) *functionHandler {
+	return &functionHandler{
+		logger: zap.NewNop(),
+		httpTrigger: &fv1.HTTPTrigger{
+			Metadata: metav1.ObjectMeta{Name: "admin", Namespace: metav1.NamespaceDefault},
+			Spec:     fv1.HTTPTriggerSpec{Auth: auth},
+		},
+		authenticator: &authenticator{
+			logger: zap.NewNop(),
+			getSecret: func(namespace, name string) (map[string][]byte, error) {
+				*reads++
+				data, ok := secrets[name]
+				if !ok {
+					return nil, errors.Errorf("secret %v not found", name)
+				}
+				return data, nil
+			},
+			secrets: make(map[metadataKey]*authSecret),
+		},
+	}
+}
+
+func TestAuthJWT(t *testing.T) {
+	key := []byte("s3cr3t")
+	reads := 0
+	fh := makeTestAuthHandler(&fv1.HTTPTriggerAuth{
+		Type:     fv1.HTTPTriggerAuthJWT,
+		Secret:   "admin-auth",
+		Issuer:   "ops",
+		Audience: "admin",
+	}, map[string]map[string][]byte{"admin-auth": {fv1.HTTPTriggerAuthJWTKey: key}}, &reads)
+
+	sign := func(claims *utils.JWTClaims, key []byte) string {
+		token, err := utils.SignJWT(claims, key)
+		assert.NoError(t, err)
+		return token
+	}
+	valid := &utils.JWTClaims{Subject: "alice", Issuer: "ops", Audience: utils.JWTAudience{"admin"},
+		ExpiresAt: time.Now().Add(time.Hour).Unix()}
+
+	cases := []struct {
+		name          string
+		authorization string
+		status        int
+	}{
+		{"valid token", "Bearer " + sign(valid, key), http.StatusOK},
+		{"no token", "", http.StatusUnauthorized},
+		{"wrong key", "Bearer " + sign(valid, []byte("other")), http.StatusUnauthorized},
+		{"expired", "Bearer " + sign(&utils.JWTClaims{Issuer: "ops", Audience: utils.JWTAudience{"admin"},
+			ExpiresAt: time.Now().Add(-time.Hour).Unix()}, key), http.StatusUnauthorized},
+		{"wrong issuer", "Bearer " + sign(&utils.JWTClaims{Issuer: "dev", Audience: utils.JWTAudience{"admin"}}, key), http.StatusUnauthorized},
+		{"wrong audience", "Bearer " + sign(&utils.JWTClaims{Issuer: "ops", Audience: utils.JWTAudience{"web"}}, key), http.StatusUnauthorized},
+	}
+
+	for _, c := range cases {
+		req := httptest.NewRequest("GET", "/admin", nil)
+		if len(c.authorization) > 0 {
+			req.Header.Set("Authorization", c.authorization)
+		}
+		// clients cannot choose the subject
+		req.Header.Set(HEADER_AUTH_SUBJECT, "root")
+		w := httptest.NewRecorder()
+
+		ok := fh.checkAuth(w, req)
+		if c.status == http.StatusOK {
+			assert.True(t, ok, c.name)
+			assert.Equal(t, "alice", req.Header.Get(HEADER_AUTH_SUBJECT), c.name)
+			continue
+		}
+		assert.False(t, ok, c.name)
+		assert.Equal(t, c.status, w.Code, c.name)
+		assert.Equal(t, errorClassUnauthorized, w.Header().Get(HEADER_ERROR_CLASS), c.name)
+		assert.Contains(t, w.Header().Get("WWW-Authenticate"), "Bearer", c.name)
+	}
+
+	// the secret is cached
+	assert.Equal(t, 1, reads)
+}
+
+func TestAuthAPIKey(t *testing.T) {
+	reads := 0
+	fh := makeTestAuthHandler(&fv1.HTTPTriggerAuth{
+		Type:   fv1.HTTPTriggerAuthAPIKey,
+		Secret: "admin-keys",
+	}, map[string]map[string][]byte{"admin-keys": {"ci": []byte("key-1"), "oncall": []byte("key-2")}}, &reads)
+
+	req := httptest.NewRequest("GET", "/admin", nil)
+	req.Header.Set(HEADER_API_KEY, "key-2")
+	assert.True(t, fh.checkAuth(httptest.NewRecorder(), req))
+	assert.Equal(t, "oncall", req.Header.Get(HEADER_AUTH_SUBJECT))
+	// the key isn't passed on to the function
+	assert.Empty(t, req.Header.Get(HEADER_API_KEY))
+
+	req = httptest.NewRequest("GET", "/admin", nil)
+	req.Header.Set(HEADER_API_KEY, "key-3")
+	w := httptest.NewRecorder()
+	assert.False(t, fh.checkAuth(w, req))
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+
+	// requests are rejected if the keys cannot be read
+	fh.httpTrigger.Spec.Auth.Secret = "missing"
+	req = httptest.NewRequest("GET", "/admin", nil)
+	req.Header.Set(HEADER_API_KEY, "key-2")
+	w = httptest.NewRecorder()
+	assert.False(t, fh.checkAuth(w, req))
+	assert.Equal(t, http.StatusInternalServerError, w.Code)
+	assert.Equal(t, errorClassAuthUnavailable, w.Header().Get(HEADER_ERROR_CLASS))
+
+	// triggers without auth let all requests through
+	fh.httpTrigger.Spec.Auth = nil
+	assert.True(t, fh.checkAuth(httptest.NewRecorder(), httptest.NewRequest("GET", "/admin", nil)))
+}
+
+func TestAuthSecretReads(t *testing.T) {
+	var reads int32
+	unblock := make(chan struct{})
+	a := &authenticator{
+		logger: zap.NewNop(),
+		getSecret: func(namespace, name string) (map[string][]byte, error) {
+			atomic.AddInt32(&reads, 1)
+			if name == "slow" {
+				<-unblock
+			}
+			return map[string][]byte{"name": []byte(name)}, nil
+		},
+		secrets: make(map[metadataKey]*authSecret),
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 3; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			data, err := a.secretData(metav1.NamespaceDefault, "slow")
+			assert.NoError(t, err)
+			assert.Equal(t, "slow", string(data["name"]))
+		}()
+	}
+
+	// a slow API server read doesn't hold up the secrets of other triggers
+	done := make(chan struct{})
+	go func() {
+		data, err := a.secretData(metav1.NamespaceDefault, "fast")
+		assert.NoError(t, err)
+		assert.Equal(t, "fast", string(data["name"]))
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("reading a secret waited for the read of another secret")
+	}
+
+	close(unblock)
+	wg.Wait()
+	assert.Equal(t, int32(2), atomic.LoadInt32(&reads), "concurrent requests should share a read")
+}
@@ -0,0 +1,157 @@
+/*
+Copyright 2019 The Fission Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package router
+
+import (
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+type (
+	// circuitBreakerParams configures the per-function circuit breakers.
+	circuitBreakerParams struct {
+		// failureThreshold is the number of consecutive failed requests
+		// after which the breaker of a function opens. A value less than
+		// or equal to zero disables the circuit breaker.
+		failureThreshold int
+
+		// cooldown is the period of time router fails fast for a function
+		// once its breaker is open. After the cooldown, a single request is
+		// let through to probe whether the function has recovered.
+		cooldown time.Duration
+	}
+
+	// circuitBreaker tracks the consecutive failures of a function.
+	circuitBreaker struct {
+		resourceVersion string
+		failures        int
+		openUntil       time.Time
+	}
+
+	// circuitBreakerMap holds the circuit breakers for all functions router
+	// has proxied requests to. It prevents router from repeatedly triggering
+	// specializations for a function that keeps failing, which would exhaust
+	// the pool and overload the executor.
+	circuitBreakerMap struct {
+		logger   *zap.Logger
+		params   circuitBreakerParams
+		lock     sync.Mutex
+		breakers map[metadataKey]*circuitBreaker
+	}
+)
+
+func makeCircuitBreakerMap(logger *zap.Logger, params circuitBreakerParams) *circuitBreakerMap {
+	return &circuitBreakerMap{
+		logger:   logger.Named("circuit_breaker_map"),
+		params:   params,
+		breakers: make(map[metadataKey]*circuitBreaker),
+	}
+}
+
+// breakerKey ignores the resource version so that a function update
+// replaces the breaker of the old version instead of leaking it.
+func breakerKey(m *metav1.ObjectMeta) metadataKey {
+	return metadataKey{
+		Name:      m.Name,
+		Namespace: m.Namespace,
+	}
+}
+
+func (cbm *circuitBreakerMap) enabled() bool {
+	return cbm != nil && cbm.params.failureThreshold > 0
+}
+
+// allow checks whether a request to the function should be proxied. If the
+// breaker is open, it returns false along with the remaining cooldown time.
+func (cbm *circuitBreakerMap) allow(m *metav1.ObjectMeta) (bool, time.Duration) {
+	if !cbm.enabled() {
+		return true, 0
+	}
+
+	cbm.lock.Lock()
+	defer cbm.lock.Unlock()
+
+	cb, ok := cbm.breakers[breakerKey(m)]
+	if !ok || cb.resourceVersion != m.ResourceVersion || cb.failures < cbm.params.failureThreshold {
+		return true, 0
+	}
+
+	now := time.Now()
+	if now.Before(cb.openUntil) {
+		return false, cb.openUntil.Sub(now)
+	}
+
+	// Half-open: let this request probe the function and keep failing
+	// fast for others until the probe reports back.
+	cb.openUntil = now.Add(cbm.params.cooldown)
+	return true, 0
+}
+
+// recordFailure counts a failed request and opens the breaker once the
+// failure threshold is reached.
+func (cbm *circuitBreakerMap) recordFailure(m *metav1.ObjectMeta) {
+	if !cbm.enabled() {
+		return
+	}
+
+	cbm.lock.Lock()
+	defer cbm.lock.Unlock()
+
+	key := breakerKey(m)
+	cb, ok := cbm.breakers[key]
+	if !ok || cb.resourceVersion != m.ResourceVersion {
+		cb = &circuitBreaker{resourceVersion: m.ResourceVersion}
+		cbm.breakers[key] = cb
+	}
+
+	cb.failures++
+	if cb.failures >= cbm.params.failureThreshold {
+		cb.openUntil = time.Now().Add(cbm.params.cooldown)
+		if cb.failures == cbm.params.failureThreshold {
+			cbm.logger.Info("circuit breaker opened for function",
+				zap.String("function_name", m.Name),
+				zap.String("function_namespace", m.Namespace),
+				zap.Int("failures", cb.failures),
+				zap.Duration("cooldown", cbm.params.cooldown))
+		}
+	}
+}
+
+// recordSuccess closes the breaker of the function.
+func (cbm *circuitBreakerMap) recordSuccess(m *metav1.ObjectMeta) {
+	if !cbm.enabled() {
+		return
+	}
+
+	cbm.lock.Lock()
+	defer cbm.lock.Unlock()
+
+	key := breakerKey(m)
+	cb, ok := cbm.breakers[key]
+	if !ok {
+		return
+	}
+	if cb.failures >= cbm.params.failureThreshold {
+		cbm.logger.Info("circuit breaker closed for function",
+			zap.String("function_name", m.Name),
+			zap.String("function_namespace", m.Namespace))
+	}
+	delete(cbm.breakers, key)
+}
@@ -0,0 +1,122 @@
+/*
+Copyright 2019 The Fission Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package router
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	fv1 "github.com/fission/fission/pkg/apis/fission.io/v1"
+	"github.com/fission/fission/pkg/types"
+)
+
+func TestCircuitBreaker(t *testing.T) {
+	logger, err := zap.NewDevelopment()
+	assert.Nil(t, err)
+
+	cbm := makeCircuitBreakerMap(logger, circuitBreakerParams{
+		failureThreshold: 2,
+		cooldown:         100 * time.Millisecond,
+	})
+	fn := &metav1.ObjectMeta{Name: "foo", Namespace: metav1.NamespaceDefault, ResourceVersion: "1"}
+
+	cbm.recordFailure(fn)
+	ok, _ := cbm.allow(fn)
+	assert.True(t, ok, "breaker should stay closed below the threshold")
+
+	cbm.recordFailure(fn)
+	ok, retryAfter := cbm.allow(fn)
+	assert.False(t, ok, "breaker should open at the threshold")
+	assert.True(t, retryAfter > 0)
+
+	// a new version of the function is not affected by the old breaker
+	updated := fn.DeepCopy()
+	updated.ResourceVersion = "2"
+	ok, _ = cbm.allow(updated)
+	assert.True(t, ok)
+
+	// after the cooldown only one probing request goes through
+	time.Sleep(150 * time.Millisecond)
+	ok, _ = cbm.allow(fn)
+	assert.True(t, ok)
+	ok, _ = cbm.allow(fn)
+	assert.False(t, ok)
+
+	cbm.recordSuccess(fn)
+	ok, _ = cbm.allow(fn)
+	assert.True(t, ok, "breaker should close after a success")
+
+	// disabled breaker never rejects requests
+	var disabled *circuitBreakerMap
+	disabled.recordFailure(fn)
+	ok, _ = disabled.allow(fn)
+	assert.True(t, ok)
+}
+
+func TestCircuitBreakerServerErrors(t *testing.T) {
+	backendServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer backendServer.Close()
+	backendURL, err := url.Parse(backendServer.URL)
+	assert.Nil(t, err)
+
+	logger, err := zap.NewDevelopment()
+	assert.Nil(t, err)
+
+	fn := &metav1.ObjectMeta{Name: "foo", Namespace: metav1.NamespaceDefault}
+	fmap := makeFunctionServiceMap(logger, 0)
+	fmap.assign(fn, backendURL)
+
+	fh := &functionHandler{
+		logger:   logger,
+		fmap:     fmap,
+		function: fn,
+		tsRoundTripperParams: &tsRoundTripperParams{
+			timeout:         50 * time.Millisecond,
+			timeoutExponent: 2,
+			maxRetries:      1,
+		},
+		httpTrigger: &fv1.HTTPTrigger{
+			Metadata: metav1.ObjectMeta{Name: "xxx", Namespace: metav1.NamespaceDefault},
+			Spec: fv1.HTTPTriggerSpec{
+				FunctionReference: fv1.FunctionReference{Type: types.FunctionReferenceTypeFunctionName},
+			},
+		},
+		circuitBreakers: makeCircuitBreakerMap(logger, circuitBreakerParams{
+			failureThreshold: 2,
+			cooldown:         time.Minute,
+		}),
+	}
+	server := httptest.NewServer(http.HandlerFunc(fh.handler))
+	defer server.Close()
+
+	// responses with server errors count as failures of the function
+	for _, code := range []int{http.StatusInternalServerError, http.StatusInternalServerError, http.StatusServiceUnavailable} {
+		resp, err := http.Get(server.URL)
+		assert.Nil(t, err)
+		resp.Body.Close()
+		assert.Equal(t, code, resp.StatusCode)
+	}
+}
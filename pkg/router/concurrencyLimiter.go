@@ -0,0 +1,135 @@
+/*
+Copyright 2019 The Fission Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package router
+
+import (
+	"context"
+	"errors"
+	"sync"
+
+	"go.uber.org/zap"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	fv1 "github.com/fission/fission/pkg/apis/fission.io/v1"
+)
+
+var errConcurrencyLimitExceeded = errors.New("function concurrency limit exceeded")
+
+type (
+	// concurrencyLimiter caps the in-flight requests of a function.
+	concurrencyLimiter struct {
+		concurrency int
+		queueLength int
+		slots       chan struct{}
+		queued      int
+	}
+
+	// concurrencyLimiterMap holds the concurrency limiters of the functions
+	// with a concurrency limit. It gives functions backed by fragile
+	// downstreams backpressure instead of unlimited fan-out.
+	concurrencyLimiterMap struct {
+		logger   *zap.Logger
+		lock     sync.Mutex
+		limiters map[metadataKey]*concurrencyLimiter
+	}
+)
+
+func makeConcurrencyLimiterMap(logger *zap.Logger) *concurrencyLimiterMap {
+	return &concurrencyLimiterMap{
+		logger:   logger.Named("concurrency_limiter_map"),
+		limiters: make(map[metadataKey]*concurrencyLimiter),
+	}
+}
+
+// sync updates the limiters to the concurrency limits of the functions.
+// A limiter is replaced only if the limits of its function changed, the
+// requests holding a slot of the replaced limiter release it to the old one.
+func (clm *concurrencyLimiterMap) sync(functions []fv1.Function) {
+	clm.lock.Lock()
+	defer clm.lock.Unlock()
+
+	limiters := make(map[metadataKey]*concurrencyLimiter)
+	for i := range functions {
+		fn := &functions[i]
+		if fn.Spec.Concurrency <= 0 {
+			continue
+		}
+		key := breakerKey(&fn.Metadata)
+		l, ok := clm.limiters[key]
+		if !ok || l.concurrency != fn.Spec.Concurrency || l.queueLength != fn.Spec.RequestQueueLength {
+			clm.logger.Info("setting concurrency limit for function",
+				zap.String("function_name", fn.Metadata.Name),
+				zap.String("function_namespace", fn.Metadata.Namespace),
+				zap.Int("concurrency", fn.Spec.Concurrency),
+				zap.Int("queue_length", fn.Spec.RequestQueueLength))
+			l = &concurrencyLimiter{
+				concurrency: fn.Spec.Concurrency,
+				queueLength: fn.Spec.RequestQueueLength,
+				slots:       make(chan struct{}, fn.Spec.Concurrency),
+			}
+		}
+		limiters[key] = l
+	}
+	clm.limiters = limiters
+}
+
+// acquire takes a slot for a request to the function, waiting in the queue
+// of the function if all slots are taken. It returns a function to release
+// the slot, or errConcurrencyLimitExceeded if the queue is full.
+func (clm *concurrencyLimiterMap) acquire(ctx context.Context, m *metav1.ObjectMeta) (func(), error) {
+	if clm == nil {
+		return func() {}, nil
+	}
+
+	clm.lock.Lock()
+	l, ok := clm.limiters[breakerKey(m)]
+	if !ok {
+		clm.lock.Unlock()
+		return func() {}, nil
+	}
+
+	release := func() { <-l.slots }
+
+	select {
+	case l.slots <- struct{}{}:
+		clm.lock.Unlock()
+		return release, nil
+	default:
+	}
+
+	if l.queued >= l.queueLength {
+		clm.lock.Unlock()
+		return nil, errConcurrencyLimitExceeded
+	}
+	l.queued++
+	clm.lock.Unlock()
+
+	defer func() {
+		clm.lock.Lock()
+		l.queued--
+		clm.lock.Unlock()
+	}()
+
+	// blocked senders are served in order, so queued requests
+	// take the released slots before new requests do.
+	select {
+	case l.slots <- struct{}{}:
+		return release, nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
@@ -0,0 +1,79 @@
+/*
+Copyright 2019 The Fission Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package router
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	fv1 "github.com/fission/fission/pkg/apis/fission.io/v1"
+)
+
+func TestConcurrencyLimiter(t *testing.T) {
+	clm := makeConcurrencyLimiterMap(zap.NewNop())
+	fn := fv1.Function{
+		Metadata: metav1.ObjectMeta{Name: "foo", Namespace: metav1.NamespaceDefault},
+		Spec:     fv1.FunctionSpec{Concurrency: 1, RequestQueueLength: 1},
+	}
+	clm.sync([]fv1.Function{fn})
+	ctx := context.Background()
+
+	// functions without a limit are not limited
+	release, err := clm.acquire(ctx, &metav1.ObjectMeta{Name: "bar", Namespace: metav1.NamespaceDefault})
+	assert.Nil(t, err)
+	release()
+
+	release, err = clm.acquire(ctx, &fn.Metadata)
+	assert.Nil(t, err)
+
+	// the second request waits in the queue until the first one completes
+	acquired := make(chan func())
+	go func() {
+		r, err := clm.acquire(ctx, &fn.Metadata)
+		assert.Nil(t, err)
+		acquired <- r
+	}()
+	time.Sleep(50 * time.Millisecond)
+
+	// the queue is full
+	_, err = clm.acquire(ctx, &fn.Metadata)
+	assert.Equal(t, errConcurrencyLimitExceeded, err)
+
+	release()
+	select {
+	case r := <-acquired:
+		r()
+	case <-time.After(time.Second):
+		t.Fatal("queued request did not get a slot")
+	}
+
+	// queued requests give up when the client goes away
+	fn.Spec.RequestQueueLength = 2
+	clm.sync([]fv1.Function{fn})
+	release, err = clm.acquire(ctx, &fn.Metadata)
+	assert.Nil(t, err)
+	defer release()
+	timeoutCtx, cancel := context.WithTimeout(ctx, 10*time.Millisecond)
+	defer cancel()
+	_, err = clm.acquire(timeoutCtx, &fn.Metadata)
+	assert.Equal(t, context.DeadlineExceeded, err)
+}
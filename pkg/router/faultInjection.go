@@ -0,0 +1,75 @@
+/*
+Copyright 2019 The Fission Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package router
+
+import (
+	"math/rand"
+	"net/http"
+	"time"
+
+	"go.uber.org/zap"
+
+	fv1 "github.com/fission/fission/pkg/apis/fission.io/v1"
+)
+
+// HEADER_FAULT_INJECTED is set on the responses of the requests router
+// injected a fault into, so that callers can tell them from real failures.
+const HEADER_FAULT_INJECTED = "X-Fission-Fault-Injected"
+
+// injectFault delays or aborts the request according to the fault injection
+// of the trigger. It returns false if the request must not be routed to the
+// function, either because it was aborted or the client went away during
+// the delay.
+func injectFault(logger *zap.Logger, fault *fv1.FaultInjection, w http.ResponseWriter, r *http.Request) bool {
+	if fault == nil {
+		return true
+	}
+
+	if faultChosen(fault.DelayPercentage) && fault.Delay > 0 {
+		delay := time.Duration(fault.Delay) * time.Millisecond
+		logger.Debug("injecting delay", zap.Duration("delay", delay))
+		w.Header().Add(HEADER_FAULT_INJECTED, "delay")
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-timer.C:
+		case <-r.Context().Done():
+			timer.Stop()
+			return false
+		}
+	}
+
+	if faultChosen(fault.AbortPercentage) {
+		status := fault.AbortStatus
+		if status == 0 {
+			status = http.StatusServiceUnavailable
+		}
+		logger.Debug("injecting abort", zap.Int("status", status))
+		w.Header().Add(HEADER_FAULT_INJECTED, "abort")
+		makeProblem(r, status, errorClassFaultInjected, "fault injected by fission router").
+			withHint("the fault injection of the trigger aborted the request").
+			write(w)
+		return false
+	}
+
+	return true
+}
+
+// faultChosen returns true for the given percentage of calls.
+func faultChosen(percentage int) bool {
+	return percentage > 0 && rand.Intn(100) < percentage
+}
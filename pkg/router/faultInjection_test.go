@@ -0,0 +1,55 @@
+/*
+Copyright 2019 The Fission Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package router
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+
+	fv1 "github.com/fission/fission/pkg/apis/fission.io/v1"
+)
+
+func TestInjectFault(t *testing.T) {
+	logger := zap.NewNop()
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+
+	w := httptest.NewRecorder()
+	assert.True(t, injectFault(logger, nil, w, req))
+	assert.True(t, injectFault(logger, &fv1.FaultInjection{}, w, req))
+	assert.Empty(t, w.Header().Get(HEADER_FAULT_INJECTED))
+
+	w = httptest.NewRecorder()
+	start := time.Now()
+	assert.True(t, injectFault(logger, &fv1.FaultInjection{DelayPercentage: 100, Delay: 50}, w, req))
+	assert.True(t, time.Since(start) >= 50*time.Millisecond)
+	assert.Equal(t, "delay", w.Header().Get(HEADER_FAULT_INJECTED))
+
+	w = httptest.NewRecorder()
+	assert.False(t, injectFault(logger, &fv1.FaultInjection{AbortPercentage: 100}, w, req))
+	assert.Equal(t, http.StatusServiceUnavailable, w.Code)
+	assert.Equal(t, "abort", w.Header().Get(HEADER_FAULT_INJECTED))
+	assert.Equal(t, errorClassFaultInjected, w.Header().Get(HEADER_ERROR_CLASS))
+
+	w = httptest.NewRecorder()
+	assert.False(t, injectFault(logger, &fv1.FaultInjection{AbortPercentage: 100, AbortStatus: http.StatusTooManyRequests}, w, req))
+	assert.Equal(t, http.StatusTooManyRequests, w.Code)
+}
@@ -22,15 +22,19 @@ import (
 	"fmt"
 	"io"
 	"io/ioutil"
+	"math"
 	"math/rand"
 	"net"
 	"net/http"
 	"net/http/httputil"
 	"net/url"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/pkg/errors"
 	"go.opencensus.io/plugin/ochttp"
+	"go.opencensus.io/trace"
 	"go.uber.org/zap"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	k8stypes "k8s.io/apimachinery/pkg/types"
@@ -42,7 +46,7 @@ import (
 	executorClient "github.com/fission/fission/pkg/executor/client"
 	"github.com/fission/fission/pkg/redis"
 	"github.com/fission/fission/pkg/throttler"
-	"github.com/fission/fission/pkg/types"
+	"github.com/fission/fission/pkg/utils"
 )
 
 const (
@@ -61,11 +65,22 @@ type (
 		httpTrigger              *fv1.HTTPTrigger
 		functionMetadataMap      map[string]*metav1.ObjectMeta
 		fnWeightDistributionList []FunctionWeightDistribution
+		versionedFunctions       map[string]*metav1.ObjectMeta
 		tsRoundTripperParams     *tsRoundTripperParams
 		recorderName             string
 		isDebugEnv               bool
 		svcAddrUpdateThrottler   *throttler.Throttler
 		functionTimeoutMap       map[k8stypes.UID]int
+		isolatedFunctions        map[k8stypes.UID]bool
+		circuitBreakers          *circuitBreakerMap
+		concurrencyLimiters      *concurrencyLimiterMap
+		rateLimiters             *rateLimiterMap
+		loadShedders             *loadShedderMap
+		authenticator            *authenticator
+
+		// forwardPath is the path the request is forwarded to the
+		// function with, for prefix triggers.
+		forwardPath string
 	}
 
 	tsRoundTripperParams struct {
@@ -86,13 +101,30 @@ type (
 		// remove it from cache and try to get a new one from executor.
 		// Default svcAddrRetryCount is 5.
 		svcAddrRetryCount int
+
+		// circuitBreaker configures how many consecutive failures of a function
+		// router tolerates before failing fast for a cooldown period.
+		circuitBreaker circuitBreakerParams
+
+		// websocketIdleTimeout is the max time a websocket connection can
+		// stay open without traffic, 0 means no limit.
+		websocketIdleTimeout time.Duration
+
+		// upstreamTimeouts are the default connect, response header and
+		// total timeouts of the calls to functions.
+		upstreamTimeouts upstreamTimeoutParams
+
+		// rateLimitTrustForwardedFor identifies the clients of per client
+		// rate limits by the X-Forwarded-For header.
+		rateLimitTrustForwardedFor bool
 	}
 
 	// A layer on top of http.DefaultTransport, with retries.
 	RetryingRoundTripper struct {
 		logger      *zap.Logger
 		funcHandler *functionHandler
-		timeout     int
+		timeouts    upstreamTimeouts
+		retries     retryPolicy
 	}
 
 	// To keep the request body open during retries, we create an interface with Close operation being a no-op.
@@ -187,15 +219,40 @@ func (roundTripper RetryingRoundTripper) RoundTrip(req *http.Request) (*http.Res
 	}
 	if roundTripper.funcHandler.httpTrigger != nil {
 		httpMetricLabels.host = roundTripper.funcHandler.httpTrigger.Spec.Host
-		httpMetricLabels.path = roundTripper.funcHandler.httpTrigger.Spec.RelativeURL
+		httpMetricLabels.path = roundTripper.funcHandler.httpTrigger.Spec.GetPath()
 	}
 
 	// set the timeout for transport context
 	transport := roundTripper.getDefaultTransport()
-	ocRoundTripper := &ochttp.Transport{Base: transport}
+	// The span context is passed on to the function in the W3C Trace
+	// Context and B3 headers, so that spans of the function join the trace.
+	ocRoundTripper := &ochttp.Transport{
+		Base:        transport,
+		Propagation: utils.TracePropagation,
+		FormatSpanName: func(*http.Request) string {
+			return "router.invokeFunction"
+		},
+	}
 
 	executingTimeout := roundTripper.funcHandler.tsRoundTripperParams.timeout
 
+	// a cold start must finish before the caller stops waiting
+	deadline := getRequestDeadline(req, startTime)
+
+	// A call answered with 5xx has consumed the request body, so the body
+	// is kept to send it again.
+	var retryBody []byte
+	// gRPC streams are not buffered, gRPC errors come with status 200 anyway.
+	if roundTripper.retries.serverError && req.Body != nil && !roundTripper.funcHandler.isGRPC() {
+		body, err := ioutil.ReadAll(req.Body)
+		if err != nil {
+			return nil, err
+		}
+		req.Body.Close()
+		retryBody = body
+		req.Body = ioutil.NopCloser(bytes.NewReader(retryBody))
+	}
+
 	// wrap the req.Body with another ReadCloser interface.
 	if req.Body != nil {
 		req.Body = &fakeCloseReadCloser{req.Body}
@@ -226,29 +283,61 @@ func (roundTripper RetryingRoundTripper) RoundTrip(req *http.Request) (*http.Res
 
 	var resp *http.Response
 
-	for i := 0; i < roundTripper.funcHandler.tsRoundTripperParams.maxRetries; i++ {
+	// The pod of an isolated function serves this request only, it's
+	// released once the response is done, or on return without one.
+	var isolatedUrl *url.URL
+	defer func() {
+		if isolatedUrl != nil {
+			roundTripper.funcHandler.releaseService(isolatedUrl)
+		}
+	}()
+
+	for i := 0; i < roundTripper.retries.attempts; i++ {
+		if i > 0 && retryBody != nil {
+			req.Body = &fakeCloseReadCloser{ioutil.NopCloser(bytes.NewReader(retryBody))}
+		}
+
 		// set service url of target service of request only when
 		// trying to get new service url from cache/executor.
 		if retryCounter == 0 {
 			// get function service url from cache or executor
-			serviceUrl, serviceUrlFromCache, err = roundTripper.funcHandler.getServiceEntry()
+			ctx, span := trace.StartSpan(req.Context(), "router.getServiceEntry")
+			span.AddAttributes(utils.FunctionTraceAttributes(fnMeta.Name, fnMeta.Namespace)...)
+			serviceUrl, serviceUrlFromCache, err = roundTripper.funcHandler.getServiceEntry(ctx, deadline)
 			if err != nil {
+				span.SetStatus(trace.Status{Code: trace.StatusCodeUnavailable, Message: err.Error()})
+			}
+			span.End()
+			if err != nil {
+				if !isClientFailure(req.Context(), deadline, err) {
+					roundTripper.funcHandler.circuitBreakers.recordFailure(fnMeta)
+				}
+
 				// We might want a specific error code or header for fission failures as opposed to
 				// user function bugs.
 				statusCode, errMsg := ferror.GetHTTPError(err)
 				if roundTripper.funcHandler.isDebugEnv {
-					return &http.Response{
+					p := roundTripper.funcHandler.problem(req, statusCode, errorClassFunctionUnavailable, errMsg)
+					body := p.body()
+					resp := &http.Response{
 						StatusCode:    statusCode,
 						Proto:         req.Proto,
 						ProtoMajor:    req.ProtoMajor,
 						ProtoMinor:    req.ProtoMinor,
-						Body:          ioutil.NopCloser(bytes.NewBufferString(errMsg)),
-						ContentLength: int64(len(errMsg)),
+						Body:          ioutil.NopCloser(bytes.NewReader(body)),
+						ContentLength: int64(len(body)),
 						Request:       req,
 						Header:        make(http.Header, 0),
-					}, nil
+					}
+					p.header(resp.Header)
+					return resp, nil
 				}
-				return nil, ferror.MakeError(http.StatusInternalServerError, err.Error())
+				// keep the error code of the executor, the proxy error
+				// handler tells these apart from the errors of upstream.
+				if _, ok := err.(ferror.Error); !ok {
+					err = ferror.MakeError(ferror.ErrorInternal, err.Error())
+				}
+				return nil, err
 			}
 
 			// service url maybe nil if router cannot find one in cache,
@@ -264,6 +353,14 @@ func (roundTripper RetryingRoundTripper) RoundTrip(req *http.Request) (*http.Res
 				go roundTripper.funcHandler.tapService(serviceUrl)
 			}
 
+			// a retry never goes to the pod of a previous attempt
+			if roundTripper.funcHandler.isIsolated() {
+				if isolatedUrl != nil {
+					roundTripper.funcHandler.releaseService(isolatedUrl)
+				}
+				isolatedUrl = serviceUrl
+			}
+
 			// modify the request to reflect the service url
 			// this service url may have come from the cache lookup or from executor response
 			req.URL.Scheme = serviceUrl.Scheme
@@ -274,7 +371,17 @@ func (roundTripper RetryingRoundTripper) RoundTrip(req *http.Request) (*http.Res
 			// multiple functions per container, we could use the
 			// function metadata here.
 			// leave the query string intact (req.URL.RawQuery)
-			req.URL.Path = "/"
+			// gRPC servers route calls by the /package.Service/Method path,
+			// so it's kept for gRPC.
+			// Prefix triggers forward the path under the prefix, for
+			// functions routing the requests themselves.
+			if !roundTripper.funcHandler.isGRPC() {
+				req.URL.Path = "/"
+				if len(roundTripper.funcHandler.forwardPath) > 0 {
+					req.URL.Path = roundTripper.funcHandler.forwardPath
+					req.URL.RawPath = ""
+				}
+			}
 
 			// Overwrite request host with internal host,
 			// or request will be blocked in some situations
@@ -283,32 +390,90 @@ func (roundTripper RetryingRoundTripper) RoundTrip(req *http.Request) (*http.Res
 		}
 
 		// over-riding default settings.
+		dialTimeout := executingTimeout
+		if roundTripper.timeouts.connect > 0 {
+			dialTimeout = roundTripper.timeouts.connect
+		}
 		transport.DialContext = (&net.Dialer{
-			Timeout:   executingTimeout,
+			Timeout:   dialTimeout,
 			KeepAlive: roundTripper.funcHandler.tsRoundTripperParams.keepAliveTime,
 		}).DialContext
+		transport.ResponseHeaderTimeout = roundTripper.timeouts.responseHeader
 
 		overhead := time.Since(startTime)
 
 		roundTripper.logger.Debug("request headers", zap.Any("headers", req.Header))
 
-		// Creating context for client
-		if roundTripper.timeout <= 0 {
-			roundTripper.timeout = fv1.DEFAULT_FUNCTION_TIMEOUT
+		if roundTripper.funcHandler.isWebsocketAllowed(req) {
+			// Websocket connections are long-lived, so they are not bound to the
+			// function timeout but closed once idle. The connection must not be
+			// wrapped by the tracing transport, reverse proxy needs to write to it.
+			resp, err = transport.RoundTrip(req)
+			if err == nil && resp.StatusCode == http.StatusSwitchingProtocols {
+				if conn, ok := resp.Body.(io.ReadWriteCloser); ok && roundTripper.funcHandler.tsRoundTripperParams.websocketIdleTimeout > 0 {
+					resp.Body = newIdleTimeoutConn(conn, roundTripper.funcHandler.tsRoundTripperParams.websocketIdleTimeout)
+				}
+			}
+		} else if roundTripper.funcHandler.isGRPC() {
+			// gRPC streams stay open as long as the caller keeps them, so
+			// they are not bound to the function timeout; the deadline of
+			// a call is sent by the client in the grpc-timeout header.
+			grpcTransport := getGRPCTransport(dialTimeout, roundTripper.funcHandler.tsRoundTripperParams.keepAliveTime)
+			grpcRoundTripper := &ochttp.Transport{
+				Base:           grpcTransport,
+				Propagation:    utils.TracePropagation,
+				FormatSpanName: ocRoundTripper.FormatSpanName,
+			}
+			// the reverse proxy drops the hop-by-hop TE header, which
+			// gRPC servers require
+			req.Header.Set("Te", "trailers")
+			resp, err = grpcRoundTripper.RoundTrip(req)
+			if err == nil {
+				resp.Body = &transportClosingBody{ReadCloser: resp.Body, transport: grpcTransport}
+			} else {
+				grpcTransport.CloseIdleConnections()
+			}
+		} else {
+			roundTripper.logger.Debug("Creating context for request for ", zap.Duration("time", roundTripper.timeouts.total))
+			// pass request context as parent context for the case
+			// that user aborts connection before timeout. Otherwise,
+			// the request won't be canceled until the deadline exceeded
+			// which may be a potential security issue.
+			ctx, closeCtx := context.WithTimeout(req.Context(), roundTripper.timeouts.total)
+
+			// forward the request to the function service
+			resp, err = ocRoundTripper.RoundTrip(req.WithContext(ctx))
+			closeCtx()
 		}
 
-		roundTripper.logger.Debug("Creating context for request for ", zap.Any("time", roundTripper.timeout))
-		// pass request context as parent context for the case
-		// that user aborts connection before timeout. Otherwise,
-		// the request won't be canceled until the deadline exceeded
-		// which may be a potential security issue.
-		ctx, closeCtx := context.WithTimeout(req.Context(), time.Duration(roundTripper.timeout)*time.Second)
-
-		// forward the request to the function service
-		resp, err = ocRoundTripper.RoundTrip(req.WithContext(ctx))
-		closeCtx()
+		if err == nil && roundTripper.retries.serverError &&
+			resp.StatusCode >= http.StatusInternalServerError && i < roundTripper.retries.attempts-1 {
+			roundTripper.logger.Debug("function responded with server error - backing off before retrying",
+				zap.String("url", req.URL.Host),
+				zap.String("function_name", fnMeta.Name),
+				zap.Int("status_code", resp.StatusCode))
+			resp.Body.Close()
+			time.Sleep(executingTimeout)
+			executingTimeout = executingTimeout * time.Duration(roundTripper.funcHandler.tsRoundTripperParams.timeoutExponent)
+			continue
+		}
 
 		if err == nil {
+			// a function responding with server errors is failing as well
+			if resp.StatusCode >= http.StatusInternalServerError {
+				roundTripper.funcHandler.circuitBreakers.recordFailure(fnMeta)
+			} else {
+				roundTripper.funcHandler.circuitBreakers.recordSuccess(fnMeta)
+			}
+
+			if isolatedUrl != nil {
+				u := isolatedUrl
+				resp.Body = releaseOnClose(resp.Body, func() {
+					roundTripper.funcHandler.releaseService(u)
+				})
+				isolatedUrl = nil
+			}
+
 			// Track metrics
 			httpMetricLabels.code = resp.StatusCode
 			funcMetricLabels.cached = serviceUrlFromCache
@@ -334,11 +499,12 @@ func (roundTripper RetryingRoundTripper) RoundTrip(req *http.Request) (*http.Res
 
 			// return response back to user
 			return resp, nil
-		} else if i >= roundTripper.funcHandler.tsRoundTripperParams.maxRetries-1 {
+		} else if i >= roundTripper.retries.attempts-1 {
 			// return here if we are in the last round
 			roundTripper.logger.Error("error getting response from function",
 				zap.String("function_name", fnMeta.Name),
 				zap.Error(err))
+			roundTripper.funcHandler.circuitBreakers.recordFailure(fnMeta)
 			return nil, err
 		}
 
@@ -354,9 +520,26 @@ func (roundTripper RetryingRoundTripper) RoundTrip(req *http.Request) (*http.Res
 
 		// if transport.RoundTrip returns a non-network dial error (e.g. "context canceled"), then relay it back to user
 		if !isNetDialErr {
+			// a function timing out is failing, a client going away isn't
+			if req.Context().Err() == nil {
+				roundTripper.funcHandler.circuitBreakers.recordFailure(fnMeta)
+			}
 			return resp, err
 		}
 
+		// the retry policy of the trigger may not retry connect failures,
+		// a stale cache entry is still invalidated for the next request.
+		if !roundTripper.retries.connectFailure {
+			roundTripper.logger.Error("error connecting to function",
+				zap.String("function_name", fnMeta.Name),
+				zap.Error(err))
+			if serviceUrlFromCache {
+				roundTripper.funcHandler.fmap.remove(fnMeta)
+			}
+			roundTripper.funcHandler.circuitBreakers.recordFailure(fnMeta)
+			return nil, err
+		}
+
 		// Check whether an error is an timeout error ("dial tcp i/o timeout").
 		// If it's not a timeout error or retryCounter exceeded pre-defined threshold,
 		// we assume the entry in router cache is stale, invalidate it.
@@ -392,6 +575,7 @@ func (roundTripper RetryingRoundTripper) RoundTrip(req *http.Request) (*http.Res
 
 	e := errors.New("Unable to get service url for connection")
 	roundTripper.logger.Error(e.Error(), zap.String("function_name", fnMeta.Name))
+	roundTripper.funcHandler.circuitBreakers.recordFailure(fnMeta)
 	return nil, e
 }
 
@@ -421,26 +605,133 @@ func (fh *functionHandler) tapService(serviceUrl *url.URL) {
 	fh.executor.TapService(serviceUrl)
 }
 
+func (fh *functionHandler) triggerName() string {
+	if fh.httpTrigger == nil {
+		return ""
+	}
+	return fh.httpTrigger.Metadata.Name
+}
+
+// problem returns an error response of the router for the function
+// and trigger of the handler.
+func (fh *functionHandler) problem(req *http.Request, status int, errorClass string, detail string) *problem {
+	return makeProblem(req, status, errorClass, detail).
+		withFunction(fh.function).
+		withTrigger(fh.triggerName())
+}
+
 func (fh functionHandler) handler(responseWriter http.ResponseWriter, request *http.Request) {
-	if fh.httpTrigger != nil && fh.httpTrigger.Spec.FunctionReference.Type == types.FunctionReferenceTypeFunctionWeights {
-		// canary deployment. need to determine the function to send request to now
+	// request id, also returned to the client to find the logs of the invocation
+	reqID := setRequestIDHeader(request)
+	responseWriter.Header().Set(HEADER_REQUEST_ID, reqID)
+
+	// gRPC needs HTTP/2 for streams and trailers all the way to the caller
+	if fh.isGRPC() && request.ProtoMajor != 2 {
+		fh.problem(request, http.StatusHTTPVersionNotSupported, errorClassHTTP2Required, "gRPC calls need HTTP/2").
+			withHint("call the router with HTTP/2, enable cleartext HTTP/2 (router.http2Cleartext) or TLS on the router").
+			write(responseWriter)
+		return
+	}
+
+	if fh.httpTrigger != nil {
+		if ok, retryAfter := fh.rateLimiters.allow(&fh.httpTrigger.Metadata, request); !ok {
+			fh.logger.Debug("trigger rate limit exceeded, rejecting request",
+				zap.String("trigger_name", fh.httpTrigger.Metadata.Name),
+				zap.Duration("retry_after", retryAfter))
+			responseWriter.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(retryAfter.Seconds()))))
+			fh.problem(request, http.StatusTooManyRequests, errorClassRateLimited, "rate limit of the trigger exceeded").
+				withHint("retry after the time given in the Retry-After header").
+				write(responseWriter)
+			return
+		}
+
+		release, ok := fh.loadShedders.tryAcquire(&fh.httpTrigger.Metadata)
+		if !ok {
+			fh.logger.Debug("trigger concurrency cap reached, shedding request",
+				zap.String("trigger_name", fh.httpTrigger.Metadata.Name),
+				zap.Int("max_concurrent_requests", fh.httpTrigger.Spec.MaxConcurrentRequests))
+			responseWriter.Header().Set("Retry-After", strconv.Itoa(loadShedRetryAfter))
+			fh.problem(request, http.StatusServiceUnavailable, errorClassLoadShed, "concurrency cap of the trigger reached").
+				withHint("retry after the time given in the Retry-After header").
+				write(responseWriter)
+			return
+		}
+		defer release()
+	}
+
+	if !fh.checkAuth(responseWriter, request) {
+		return
+	}
+
+	versioned := false
+	if fh.versionedFunctions != nil {
+		if version := getAPIVersion(fh.httpTrigger.Spec.APIVersioning, request); len(version) > 0 {
+			fnMetadata, ok := fh.versionedFunctions[version]
+			if !ok {
+				fh.problem(request, http.StatusBadRequest, errorClassUnsupportedVersion, fmt.Sprintf("API version %q is not supported", version)).
+					withHint(fmt.Sprintf("use one of the versions %v", strings.Join(apiVersions(fh.versionedFunctions), ", "))).
+					write(responseWriter)
+				return
+			}
+			fh.function = fnMetadata
+			versioned = true
+		}
+	}
+
+	if !versioned && len(fh.fnWeightDistributionList) > 0 {
+		// canary deployment or weighted function alias. need to determine
+		// the function to send request to now
 		fnMetadata := getCanaryBackend(fh.functionMetadataMap, fh.fnWeightDistributionList)
 		if fnMetadata == nil {
 			fh.logger.Error("could not get canary backend",
 				zap.Any("metadataMap", fh.functionMetadataMap),
 				zap.Any("distributionList", fh.fnWeightDistributionList))
-			// TODO : write error to responseWrite and return response
+			fh.problem(request, http.StatusInternalServerError, errorClassNoBackend, "could not choose a function backend").
+				withHint("check the function weights of the trigger").
+				write(responseWriter)
 			return
 		}
 		fh.function = fnMetadata
 		fh.logger.Debug("chosen function backend's metadata", zap.Any("metadata", fh.function))
 	}
 
+	// fail fast if the function kept failing recently, instead of
+	// triggering another specialization that is likely doomed.
+	if ok, retryAfter := fh.circuitBreakers.allow(fh.function); !ok {
+		fh.logger.Debug("circuit breaker is open, rejecting request",
+			zap.String("function_name", fh.function.Name),
+			zap.Duration("retry_after", retryAfter))
+		responseWriter.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(retryAfter.Seconds()))))
+		fh.problem(request, http.StatusServiceUnavailable, errorClassCircuitOpen, fmt.Sprintf("function %v is failing", fh.function.Name)).
+			withHint("check the function logs, requests are allowed again after the Retry-After time").
+			write(responseWriter)
+		return
+	}
+
 	// set record id
 	setRecordRequestIDHeader(fh.recorderName, request)
 
+	if fh.httpTrigger != nil && !injectFault(fh.logger, fh.httpTrigger.Spec.FaultInjection, responseWriter, request) {
+		return
+	}
+
+	release, err := fh.concurrencyLimiters.acquire(request.Context(), fh.function)
+	if err != nil {
+		fh.logger.Debug("function concurrency limit reached, rejecting request",
+			zap.String("function_name", fh.function.Name),
+			zap.Error(err))
+		fh.problem(request, http.StatusTooManyRequests, errorClassConcurrencyLimited, fmt.Sprintf("function %v is at its concurrency limit", fh.function.Name)).
+			withHint("retry later, or raise the concurrency limit of the function").
+			write(responseWriter)
+		return
+	}
+	defer release()
+
 	// url path
 	setPathInfoToHeader(request)
+	if fh.httpTrigger != nil && len(fh.httpTrigger.Spec.Prefix) > 0 {
+		fh.forwardPath = setPrefixPathToHeader(fh.httpTrigger, request)
+	}
 
 	// system params
 	setFunctionMetadataToHeader(fh.function, request)
@@ -452,9 +743,9 @@ func (fh functionHandler) handler(responseWriter http.ResponseWriter, request *h
 		}
 	}
 
-	var timeout int = fv1.DEFAULT_FUNCTION_TIMEOUT
+	var fnTimeout int
 	if fh.functionTimeoutMap != nil {
-		timeout = fh.functionTimeoutMap[fh.function.GetUID()]
+		fnTimeout = fh.functionTimeoutMap[fh.function.GetUID()]
 	}
 
 	proxy := &httputil.ReverseProxy{
@@ -462,9 +753,20 @@ func (fh functionHandler) handler(responseWriter http.ResponseWriter, request *h
 		Transport: &RetryingRoundTripper{
 			logger:      fh.logger.Named("roundtripper"),
 			funcHandler: &fh,
-			timeout:     timeout,
+			timeouts:    getUpstreamTimeouts(fh.tsRoundTripperParams.upstreamTimeouts, fnTimeout, fh.httpTrigger),
+			retries:     getRetryPolicy(fh.tsRoundTripperParams.maxRetries, fh.httpTrigger),
+		},
+		// the request id header of the response is set by the router
+		ModifyResponse: func(resp *http.Response) error {
+			resp.Header.Del(HEADER_REQUEST_ID)
+			recordInvocationUsage(fh.logger, fh.function, resp)
+			return nil
 		},
-		ErrorHandler: getProxyErrorHandler(fh.logger, fh.function),
+		ErrorHandler: getProxyErrorHandler(fh.logger, fh.function, fh.triggerName()),
+	}
+	if fh.isGRPC() {
+		// send the messages of gRPC streams as soon as they arrive
+		proxy.FlushInterval = -1
 	}
 
 	proxy.ServeHTTP(responseWriter, request)
@@ -506,9 +808,13 @@ func getCanaryBackend(fnMetadatamap map[string]*metav1.ObjectMeta, fnWtDistribut
 }
 
 // getProxyErrorHandler returns a reverse proxy error handler
-func getProxyErrorHandler(logger *zap.Logger, fnMeta *metav1.ObjectMeta) func(rw http.ResponseWriter, req *http.Request, err error) {
+func getProxyErrorHandler(logger *zap.Logger, fnMeta *metav1.ObjectMeta, trigger string) func(rw http.ResponseWriter, req *http.Request, err error) {
 	return func(rw http.ResponseWriter, req *http.Request, err error) {
 		status := http.StatusBadGateway
+		errorClass := errorClassUpstream
+		// errors of the transport hold internal addresses, don't return them
+		detail := "error sending request to function"
+		hint := "check the function logs with the request id"
 		switch err {
 		case context.Canceled:
 			// 499 CLIENT CLOSED REQUEST
@@ -516,18 +822,58 @@ func getProxyErrorHandler(logger *zap.Logger, fnMeta *metav1.ObjectMeta) func(rw
 			// when a client closes the connection while nginx is processing the request.
 			// Reference: https://httpstatuses.com/499
 			status = 499
+			errorClass = errorClassClientClosed
+			detail = "client closed the request"
+			hint = ""
 			logger.Debug("client closes the connection",
 				zap.Any("function", fnMeta), zap.Any("request_header", req.Header))
 		case context.DeadlineExceeded:
 			status = http.StatusGatewayTimeout
+			errorClass = errorClassTimeout
+			detail = "function did not respond in time"
+			hint = "check the timeout of the function and the trigger"
 			logger.Error("function not responses before the timeout",
-				zap.Any("function", fnMeta), zap.Any("request_header", req.Header))
+				zap.Any("function", fnMeta), zap.String("request_id", req.Header.Get(HEADER_REQUEST_ID)),
+				zap.Any("request_header", req.Header))
 		default:
+			// the function service couldn't be got from the executor,
+			// the status stays 502 as before.
+			if fe, ok := err.(ferror.Error); ok {
+				errorClass = errorClassFunctionUnavailable
+				detail = "function could not be started"
+				hint = "check the function and the pods of its environment"
+				if fe.Code == ferror.ErrorRequestTimeout {
+					status = http.StatusGatewayTimeout
+					errorClass = errorClassTimeout
+					detail = "function could not be started before the request deadline"
+					hint = "raise the request timeout or keep warm pods of the function"
+				}
+				logger.Error("error getting function service",
+					zap.Error(err), zap.Any("function", fnMeta), zap.String("request_id", req.Header.Get(HEADER_REQUEST_ID)))
+				break
+			}
+
+			// the response header timeout of the transport is a timeout error
+			if netErr, ok := err.(net.Error); ok && netErr.Timeout() {
+				status = http.StatusGatewayTimeout
+				errorClass = errorClassTimeout
+				detail = "function did not respond in time"
+				hint = "check the timeout of the function and the trigger"
+				logger.Error("function not responses before the response header timeout",
+					zap.Error(err), zap.Any("function", fnMeta), zap.String("request_id", req.Header.Get(HEADER_REQUEST_ID)),
+					zap.Any("request_header", req.Header))
+				break
+			}
+
 			logger.Error("error sending request to function",
-				zap.Error(err), zap.Any("function", fnMeta), zap.Any("request_header", req.Header))
+				zap.Error(err), zap.Any("function", fnMeta), zap.String("request_id", req.Header.Get(HEADER_REQUEST_ID)),
+				zap.Any("request_header", req.Header))
 		}
-		// TODO: return error message that contains traceable UUID back to user. Issue #693
-		rw.WriteHeader(status)
+		makeProblem(req, status, errorClass, detail).
+			withFunction(fnMeta).
+			withTrigger(trigger).
+			withHint(hint).
+			write(rw)
 	}
 }
 
@@ -574,21 +920,64 @@ func (roundTripper RetryingRoundTripper) addForwardedHostHeader(req *http.Reques
 	req.Header.Set(X_FORWARDED_HOST, req.Host)
 }
 
-// getServiceEntry is a short-hand for developers to get service url entry that may returns from executor or cache
-func (fh *functionHandler) getServiceEntry() (serviceUrl *url.URL, serviceUrlFromCache bool, err error) {
+// isClientFailure tells whether getting the service of a function failed
+// because of the client, its deadline passed or it went away. These
+// failures don't count against the circuit breaker of the function.
+func isClientFailure(ctx context.Context, deadline time.Time, err error) bool {
+	if fe, ok := errors.Cause(err).(ferror.Error); ok && fe.Code == ferror.ErrorRequestTimeout {
+		return true
+	}
+	if !deadline.IsZero() && !time.Now().Before(deadline) {
+		return true
+	}
+	return ctx.Err() != nil
+}
+
+// getServiceEntry is a short-hand for developers to get service url entry that may returns from executor or cache.
+// A zero deadline means the caller waits as long as it takes the executor to respond.
+func (fh *functionHandler) getServiceEntry(ctx context.Context, deadline time.Time) (serviceUrl *url.URL, serviceUrlFromCache bool, err error) {
+	span := trace.FromContext(ctx)
+
+	// an isolated function gets a fresh pod for each invocation, so its
+	// address is neither cached nor shared with other requests
+	isolated := fh.isIsolated()
+
 	// try to find service url from cache first
-	serviceUrl, err = fh.getServiceEntryFromCache()
-	if err == nil && serviceUrl != nil {
-		return serviceUrl, true, nil
-	} else if err != nil {
-		return nil, false, err
+	if !isolated {
+		serviceUrl, err = fh.getServiceEntryFromCache()
+		if err == nil && serviceUrl != nil {
+			span.AddAttributes(trace.BoolAttribute(utils.TraceAttrColdStart, false))
+			return serviceUrl, true, nil
+		} else if err != nil {
+			return nil, false, err
+		}
 	}
 
 	// cache miss or nil entry in cache
-
-	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	span.AddAttributes(trace.BoolAttribute(utils.TraceAttrColdStart, true))
+
+	// The executor request is shared by all requests waiting for the function,
+	// so it must not be canceled with this request. Only the span is carried
+	// over for the executor spans to join the trace. It is still bound by the
+	// deadline of the caller, the executor doesn't specialize a pod nobody
+	// would wait for.
+	executorDeadline := time.Now().Add(30 * time.Second)
+	if !deadline.IsZero() && deadline.Before(executorDeadline) {
+		executorDeadline = deadline
+	}
+	if time.Until(executorDeadline) <= 0 {
+		return nil, false, ferror.MakeError(ferror.ErrorRequestTimeout,
+			fmt.Sprintf("request deadline exceeded before getting service for function %v", fh.function.Name))
+	}
+	ctx, cancel := context.WithDeadline(trace.NewContext(context.Background(), span), executorDeadline)
 	defer cancel()
 
+	// the caller releases the pod even if the request is gone meanwhile
+	if isolated {
+		serviceUrl, err = fh.getIsolatedServiceEntryFromExecutor(ctx)
+		return serviceUrl, false, err
+	}
+
 	// Use throttle to limit the total amount of requests sent
 	// to the executor to prevent it from overloaded.
 	recordObj, err := fh.svcAddrUpdateThrottler.RunOnce(
@@ -667,7 +1056,7 @@ func (fh *functionHandler) getServiceEntryFromCache() (serviceUrl *url.URL, err
 // getServiceEntryFromExecutor returns service url entry returns from executor
 func (fh *functionHandler) getServiceEntryFromExecutor(ctx context.Context) (*url.URL, error) {
 	// send a request to executor to specialize a new pod
-	service, err := fh.executor.GetServiceForFunction(ctx, fh.function)
+	service, coldStart, err := fh.executor.GetServiceForFunction(ctx, fh.function)
 	if err != nil {
 		statusCode, errMsg := ferror.GetHTTPError(err)
 		fh.logger.Error("error from GetServiceForFunction",
@@ -677,6 +1066,9 @@ func (fh *functionHandler) getServiceEntryFromExecutor(ctx context.Context) (*ur
 			zap.Int("status_code", statusCode))
 		return nil, err
 	}
+	if coldStart {
+		functionStats.recordColdStart(fh.function.Namespace, fh.function.Name)
+	}
 
 	// parse the address into url
 	serviceUrl, err := url.Parse(fmt.Sprintf("http://%v", service))
@@ -18,6 +18,7 @@ package router
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
 	"log"
 	"net/http"
@@ -31,6 +32,7 @@ import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 
 	fv1 "github.com/fission/fission/pkg/apis/fission.io/v1"
+	ferror "github.com/fission/fission/pkg/error"
 	"github.com/fission/fission/pkg/types"
 )
 
@@ -97,7 +99,7 @@ func TestFunctionProxying(t *testing.T) {
 func TestProxyErrorHandler(t *testing.T) {
 	logger, err := zap.NewDevelopment()
 	assert.Nil(t, err)
-	errHandler := getProxyErrorHandler(logger, nil)
+	errHandler := getProxyErrorHandler(logger, &metav1.ObjectMeta{Name: "foo", Namespace: "bar"}, "foo-trigger")
 
 	req, err := http.NewRequest("GET", "http://foobar.com", nil)
 	assert.Nil(t, err)
@@ -114,4 +116,42 @@ func TestProxyErrorHandler(t *testing.T) {
 	respRecorder = httptest.NewRecorder()
 	errHandler(respRecorder, req, errors.New("dummy"))
 	assert.Equal(t, http.StatusBadGateway, respRecorder.Code)
+	assert.Equal(t, problemContentType, respRecorder.Header().Get("Content-Type"))
+	assert.Equal(t, errorClassUpstream, respRecorder.Header().Get(HEADER_ERROR_CLASS))
+
+	var p problem
+	assert.Nil(t, json.Unmarshal(respRecorder.Body.Bytes(), &p))
+	assert.Equal(t, http.StatusBadGateway, p.Status)
+	assert.Equal(t, errorClassUpstream, p.ErrorClass)
+	assert.Equal(t, "foo", p.Function)
+	assert.Equal(t, "bar", p.Namespace)
+	assert.Equal(t, "foo-trigger", p.Trigger)
+	assert.NotContains(t, p.Detail, "dummy")
+
+	respRecorder = httptest.NewRecorder()
+	errHandler(respRecorder, req, ferror.MakeError(ferror.ErrorRequestTimeout, "deadline"))
+	assert.Equal(t, http.StatusGatewayTimeout, respRecorder.Code)
+	assert.Equal(t, errorClassTimeout, respRecorder.Header().Get(HEADER_ERROR_CLASS))
+
+	respRecorder = httptest.NewRecorder()
+	errHandler(respRecorder, req, ferror.MakeError(ferror.ErrorInternal, "no pods"))
+	assert.Equal(t, http.StatusBadGateway, respRecorder.Code)
+	assert.Equal(t, errorClassFunctionUnavailable, respRecorder.Header().Get(HEADER_ERROR_CLASS))
+}
+
+func TestIsClientFailure(t *testing.T) {
+	executorErr := ferror.MakeError(ferror.ErrorInternal, "executor failed")
+
+	assert.False(t, isClientFailure(context.Background(), time.Time{}, executorErr))
+	assert.False(t, isClientFailure(context.Background(), time.Now().Add(time.Minute), errors.New("executor failed")))
+
+	// the deadline of the client passed before or while getting the service
+	assert.True(t, isClientFailure(context.Background(), time.Time{},
+		ferror.MakeError(ferror.ErrorRequestTimeout, "request deadline exceeded")))
+	assert.True(t, isClientFailure(context.Background(), time.Now().Add(-time.Second), executorErr))
+
+	// the client went away
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	assert.True(t, isClientFailure(ctx, time.Time{}, executorErr))
 }
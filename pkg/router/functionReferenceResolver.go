@@ -38,8 +38,9 @@ type (
 		// FunctionReference -> function metadata
 		refCache *cache.Cache
 
-		stopCh chan struct{}
-		store  k8sCache.Store
+		stopCh     chan struct{}
+		store      k8sCache.Store
+		aliasStore k8sCache.Store
 	}
 
 	resolveResultType int
@@ -57,6 +58,14 @@ type (
 		resolveResultType
 		functionMetadataMap        map[string]*metav1.ObjectMeta
 		functionWtDistributionList []FunctionWeightDistribution
+
+		// aliasName is the name of the function alias the function
+		// reference was resolved through, if any.
+		aliasName string
+
+		// versionedFunctions are the functions of the API versions of
+		// a trigger with API versioning.
+		versionedFunctions map[string]*metav1.ObjectMeta
 	}
 
 	// namespacedTriggerReference is just a trigger reference plus a
@@ -73,10 +82,11 @@ const (
 	resolveResultMultipleFunctions
 )
 
-func makeFunctionReferenceResolver(store k8sCache.Store) *functionReferenceResolver {
+func makeFunctionReferenceResolver(store k8sCache.Store, aliasStore k8sCache.Store) *functionReferenceResolver {
 	frr := &functionReferenceResolver{
-		refCache: cache.MakeCache(time.Minute, 0),
-		store:    store,
+		refCache:   cache.MakeCache(time.Minute, 0),
+		store:      store,
+		aliasStore: aliasStore,
 	}
 	return frr
 }
@@ -122,7 +132,13 @@ func (frr *functionReferenceResolver) resolve(trigger fv1.HTTPTrigger) (*resolve
 		}
 
 	case fv1.FunctionReferenceTypeFunctionWeights:
-		rr, err = frr.resolveByFunctionWeights(nfr.namespace, &trigger.Spec.FunctionReference)
+		rr, err = frr.resolveByFunctionWeights(nfr.namespace, trigger.Spec.FunctionReference.FunctionWeights)
+		if err != nil {
+			return nil, err
+		}
+
+	case fv1.FunctionReferenceTypeFunctionAlias:
+		rr, err = frr.resolveByAlias(nfr.namespace, trigger.Spec.FunctionReference.Name)
 		if err != nil {
 			return nil, err
 		}
@@ -131,6 +147,17 @@ func (frr *functionReferenceResolver) resolve(trigger fv1.HTTPTrigger) (*resolve
 		return nil, fmt.Errorf("Unrecognized function reference type %v", trigger.Spec.FunctionReference.Type)
 	}
 
+	if trigger.Spec.APIVersioning != nil {
+		rr.versionedFunctions = make(map[string]*metav1.ObjectMeta, len(trigger.Spec.APIVersioning.Functions))
+		for version, functionName := range trigger.Spec.APIVersioning.Functions {
+			vr, err := frr.resolveByName(nfr.namespace, functionName)
+			if err != nil {
+				return nil, fmt.Errorf("error resolving function of API version %v: %v", version, err)
+			}
+			rr.versionedFunctions[version] = vr.functionMetadataMap[functionName]
+		}
+	}
+
 	// cache resolve result
 	frr.refCache.Set(nfr, *rr)
 
@@ -165,13 +192,13 @@ func (frr *functionReferenceResolver) resolveByName(namespace, name string) (*re
 	return &rr, nil
 }
 
-func (frr *functionReferenceResolver) resolveByFunctionWeights(namespace string, fr *fv1.FunctionReference) (*resolveResult, error) {
+func (frr *functionReferenceResolver) resolveByFunctionWeights(namespace string, functionWeights map[string]int) (*resolveResult, error) {
 
 	functionMetadataMap := make(map[string]*metav1.ObjectMeta, 0)
 	fnWtDistrList := make([]FunctionWeightDistribution, 0)
 	sumPrefix := 0
 
-	for functionName, functionWeight := range fr.FunctionWeights {
+	for functionName, functionWeight := range functionWeights {
 		// get function from cache
 		obj, isExist, err := frr.store.Get(&fv1.Function{
 			Metadata: metav1.ObjectMeta{
@@ -206,6 +233,42 @@ func (frr *functionReferenceResolver) resolveByFunctionWeights(namespace string,
 	return &rr, nil
 }
 
+// resolveByAlias looks up a function alias by name in a namespace and
+// resolves the functions it points to.
+func (frr *functionReferenceResolver) resolveByAlias(namespace, name string) (*resolveResult, error) {
+	if frr.aliasStore == nil {
+		return nil, fmt.Errorf("function alias %v does not exist", name)
+	}
+	obj, isExist, err := frr.aliasStore.Get(&fv1.FunctionAlias{
+		Metadata: metav1.ObjectMeta{
+			Namespace: namespace,
+			Name:      name,
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+	if !isExist {
+		return nil, fmt.Errorf("function alias %v does not exist", name)
+	}
+
+	alias := obj.(*fv1.FunctionAlias)
+	var rr *resolveResult
+	if len(alias.Spec.FunctionWeights) == 1 {
+		for functionName := range alias.Spec.FunctionWeights {
+			rr, err = frr.resolveByName(namespace, functionName)
+		}
+	} else {
+		rr, err = frr.resolveByFunctionWeights(namespace, alias.Spec.FunctionWeights)
+	}
+	if err != nil {
+		return nil, err
+	}
+	rr.aliasName = alias.Metadata.Name
+
+	return rr, nil
+}
+
 func (frr *functionReferenceResolver) delete(namespace string, triggerName, triggerRV string) error {
 	nfr := namespacedTriggerReference{
 		namespace:              namespace,
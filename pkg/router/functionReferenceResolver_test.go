@@ -0,0 +1,76 @@
+/*
+Copyright 2019 The Fission Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package router
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	k8sCache "k8s.io/client-go/tools/cache"
+
+	fv1 "github.com/fission/fission/pkg/apis/fission.io/v1"
+)
+
+func TestResolveByAlias(t *testing.T) {
+	fnStore := k8sCache.NewStore(k8sCache.MetaNamespaceKeyFunc)
+	aliasStore := k8sCache.NewStore(k8sCache.MetaNamespaceKeyFunc)
+	for _, name := range []string{"foo-v1", "foo-v2"} {
+		err := fnStore.Add(&fv1.Function{
+			Metadata: metav1.ObjectMeta{Namespace: metav1.NamespaceDefault, Name: name},
+		})
+		assert.NoError(t, err)
+	}
+	aliases := []*fv1.FunctionAlias{
+		{
+			Metadata: metav1.ObjectMeta{Namespace: metav1.NamespaceDefault, Name: "prod"},
+			Spec:     fv1.FunctionAliasSpec{FunctionWeights: map[string]int{"foo-v1": 100}},
+		},
+		{
+			Metadata: metav1.ObjectMeta{Namespace: metav1.NamespaceDefault, Name: "staging"},
+			Spec:     fv1.FunctionAliasSpec{FunctionWeights: map[string]int{"foo-v1": 80, "foo-v2": 20}},
+		},
+		{
+			Metadata: metav1.ObjectMeta{Namespace: metav1.NamespaceDefault, Name: "broken"},
+			Spec:     fv1.FunctionAliasSpec{FunctionWeights: map[string]int{"bar": 100}},
+		},
+	}
+	for _, alias := range aliases {
+		err := aliasStore.Add(alias)
+		assert.NoError(t, err)
+	}
+	frr := makeFunctionReferenceResolver(fnStore, aliasStore)
+
+	rr, err := frr.resolveByAlias(metav1.NamespaceDefault, "prod")
+	assert.NoError(t, err)
+	assert.Equal(t, resolveResultSingleFunction, int(rr.resolveResultType))
+	assert.Equal(t, "prod", rr.aliasName)
+	assert.Contains(t, rr.functionMetadataMap, "foo-v1")
+
+	rr, err = frr.resolveByAlias(metav1.NamespaceDefault, "staging")
+	assert.NoError(t, err)
+	assert.Equal(t, resolveResultMultipleFunctions, int(rr.resolveResultType))
+	assert.Equal(t, "staging", rr.aliasName)
+	assert.Len(t, rr.functionWtDistributionList, 2)
+	assert.Equal(t, 100, rr.functionWtDistributionList[1].sumPrefix)
+
+	_, err = frr.resolveByAlias(metav1.NamespaceDefault, "broken")
+	assert.Error(t, err)
+
+	_, err = frr.resolveByAlias(metav1.NamespaceDefault, "missing")
+	assert.Error(t, err)
+}
@@ -0,0 +1,201 @@
+/*
+Copyright 2019 The Fission Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package router
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/fission/fission/pkg/types"
+)
+
+const (
+	// functionStatsInterval is the time span of a bucket of function stats,
+	// the stats can be queried at this granularity.
+	functionStatsInterval = 5 * time.Minute
+
+	// functionStatsRetention is how long router keeps the stats of a
+	// function, it's the longest window that can be queried.
+	functionStatsRetention = 24 * time.Hour
+)
+
+// functionStatsLatencyBounds are the upper bounds in seconds of the latency
+// buckets. They grow by 25% from 1ms to 10 minutes, so that a quantile
+// computed from the buckets is off by at most 25%.
+var functionStatsLatencyBounds = makeLatencyBounds(0.001, 600, 1.25)
+
+// functionStats are the stats of the functions invoked through this router,
+// they are served along with the metrics for the controller to aggregate
+// the stats of all routers.
+var functionStats = makeFunctionStatsStore(time.Now)
+
+type (
+	// functionStatsBucket counts the invocations of a function in a
+	// functionStatsInterval.
+	functionStatsBucket struct {
+		start       time.Time
+		invocations uint64
+		errors      uint64
+		coldStarts  uint64
+		// latency counts the invocations per latency bucket, sparse as
+		// most functions hit a few of them.
+		latency map[int]uint64
+	}
+
+	// functionStatsStore keeps the stats of each function in buckets, oldest
+	// first, for the retention period.
+	functionStatsStore struct {
+		sync.Mutex
+		now       func() time.Time
+		functions map[string][]*functionStatsBucket
+		lastPrune time.Time
+	}
+)
+
+func makeLatencyBounds(min, max, factor float64) []float64 {
+	var bounds []float64
+	for b := min; b < max*factor; b *= factor {
+		bounds = append(bounds, b)
+	}
+	return bounds
+}
+
+func makeFunctionStatsStore(now func() time.Time) *functionStatsStore {
+	return &functionStatsStore{
+		now:       now,
+		functions: make(map[string][]*functionStatsBucket),
+	}
+}
+
+func functionStatsKey(namespace, name string) string {
+	return namespace + "/" + name
+}
+
+// recordCall counts an invocation of a function that took duration.
+func (s *functionStatsStore) recordCall(namespace, name string, duration time.Duration, failed bool) {
+	// the first bound not below the latency, the slower ones go to the
+	// bucket past the last bound
+	i := sort.SearchFloat64s(functionStatsLatencyBounds, duration.Seconds())
+
+	s.Lock()
+	defer s.Unlock()
+	b := s.bucket(namespace, name)
+	b.invocations++
+	if failed {
+		b.errors++
+	}
+	b.latency[i]++
+}
+
+// recordColdStart counts a pod specialized for a function.
+func (s *functionStatsStore) recordColdStart(namespace, name string) {
+	s.Lock()
+	defer s.Unlock()
+	s.bucket(namespace, name).coldStarts++
+}
+
+// bucket returns the current bucket of a function, the caller holds the lock.
+func (s *functionStatsStore) bucket(namespace, name string) *functionStatsBucket {
+	now := s.now()
+	if now.Sub(s.lastPrune) >= functionStatsInterval {
+		s.prune(now)
+		s.lastPrune = now
+	}
+
+	key := functionStatsKey(namespace, name)
+	buckets := s.functions[key]
+	start := now.Truncate(functionStatsInterval)
+	if len(buckets) > 0 && buckets[len(buckets)-1].start.Equal(start) {
+		return buckets[len(buckets)-1]
+	}
+	b := &functionStatsBucket{
+		start:   start,
+		latency: make(map[int]uint64),
+	}
+	s.functions[key] = append(buckets, b)
+	return b
+}
+
+// prune drops the buckets past the retention period, and the functions
+// without any bucket left.
+func (s *functionStatsStore) prune(now time.Time) {
+	oldest := now.Add(-functionStatsRetention)
+	for key, buckets := range s.functions {
+		i := 0
+		for i < len(buckets) && buckets[i].start.Before(oldest) {
+			i++
+		}
+		if i == len(buckets) {
+			delete(s.functions, key)
+		} else if i > 0 {
+			s.functions[key] = append([]*functionStatsBucket(nil), buckets[i:]...)
+		}
+	}
+}
+
+// counts sums up the buckets of a function overlapping the window ending now.
+func (s *functionStatsStore) counts(namespace, name string, since time.Duration) *types.FunctionStatsCounts {
+	counts := &types.FunctionStatsCounts{
+		LatencyBounds: functionStatsLatencyBounds,
+		LatencyCounts: make([]uint64, len(functionStatsLatencyBounds)+1),
+	}
+
+	s.Lock()
+	defer s.Unlock()
+	oldest := s.now().Add(-since).Truncate(functionStatsInterval)
+	for _, b := range s.functions[functionStatsKey(namespace, name)] {
+		if b.start.Before(oldest) {
+			continue
+		}
+		counts.Invocations += b.invocations
+		counts.Errors += b.errors
+		counts.ColdStarts += b.coldStarts
+		for i, n := range b.latency {
+			counts.LatencyCounts[i] += n
+		}
+	}
+	return counts
+}
+
+// handler serves the stats of the function in the namespace and name query
+// parameters over the window in the since parameter.
+func (s *functionStatsStore) handler(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	name := query.Get("name")
+	namespace := query.Get("namespace")
+	if len(name) == 0 || len(namespace) == 0 {
+		http.Error(w, "name and namespace of the function are required", http.StatusBadRequest)
+		return
+	}
+	since, err := time.ParseDuration(query.Get("since"))
+	if err != nil || since <= 0 {
+		http.Error(w, fmt.Sprintf("invalid time window %q", query.Get("since")), http.StatusBadRequest)
+		return
+	}
+
+	resp, err := json.Marshal(s.counts(namespace, name, since))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.Write(resp)
+}
@@ -0,0 +1,105 @@
+/*
+Copyright 2019 The Fission Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package router
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/fission/fission/pkg/types"
+)
+
+func TestFunctionStatsStore(t *testing.T) {
+	now := time.Date(2020, 1, 1, 12, 0, 0, 0, time.UTC)
+	s := makeFunctionStatsStore(func() time.Time { return now })
+
+	s.recordColdStart("default", "hello")
+	s.recordCall("default", "hello", 2*time.Millisecond, false)
+	s.recordCall("default", "hello", 3*time.Second, true)
+	s.recordCall("default", "other", time.Second, false)
+
+	now = now.Add(time.Hour)
+	s.recordCall("default", "hello", 10*time.Millisecond, false)
+	s.recordCall("default", "hello", time.Hour, false)
+
+	counts := s.counts("default", "hello", 10*time.Minute)
+	assert.Equal(t, uint64(2), counts.Invocations)
+	assert.Equal(t, uint64(0), counts.Errors)
+	assert.Equal(t, uint64(0), counts.ColdStarts)
+
+	counts = s.counts("default", "hello", 2*time.Hour)
+	assert.Equal(t, uint64(4), counts.Invocations)
+	assert.Equal(t, uint64(1), counts.Errors)
+	assert.Equal(t, uint64(1), counts.ColdStarts)
+	assert.Len(t, counts.LatencyCounts, len(counts.LatencyBounds)+1)
+
+	var total uint64
+	for _, n := range counts.LatencyCounts {
+		total += n
+	}
+	assert.Equal(t, uint64(4), total)
+	assert.Equal(t, uint64(1), counts.LatencyCounts[len(counts.LatencyBounds)], "an hour is past the last bound")
+
+	// the stats of a function are dropped once they are past the retention
+	now = now.Add(functionStatsRetention + functionStatsInterval)
+	s.recordColdStart("default", "hello")
+	counts = s.counts("default", "hello", functionStatsRetention)
+	assert.Equal(t, uint64(0), counts.Invocations)
+	assert.Equal(t, uint64(1), counts.ColdStarts)
+	assert.NotContains(t, s.functions, functionStatsKey("default", "other"))
+}
+
+func TestFunctionStatsLatencyBucket(t *testing.T) {
+	s := makeFunctionStatsStore(time.Now)
+	for _, d := range []time.Duration{0, time.Millisecond, 1500 * time.Microsecond, 42 * time.Millisecond, 10 * time.Minute} {
+		s.recordCall("default", "hello", d, false)
+		counts := s.counts("default", "hello", time.Minute)
+		for i, n := range counts.LatencyCounts {
+			if n == 0 {
+				continue
+			}
+			assert.True(t, d.Seconds() <= counts.LatencyBounds[i], d.String())
+			if i > 0 {
+				assert.True(t, d.Seconds() > counts.LatencyBounds[i-1], d.String())
+			}
+		}
+		s.functions = make(map[string][]*functionStatsBucket)
+	}
+}
+
+func TestFunctionStatsHandler(t *testing.T) {
+	s := makeFunctionStatsStore(time.Now)
+	s.recordCall("ns", "hello", time.Second, false)
+
+	w := httptest.NewRecorder()
+	s.handler(w, httptest.NewRequest("GET", "/stats/functions?namespace=ns&name=hello&since=1h", nil))
+	assert.Equal(t, http.StatusOK, w.Code)
+	counts := types.FunctionStatsCounts{}
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &counts))
+	assert.Equal(t, uint64(1), counts.Invocations)
+
+	for _, query := range []string{"namespace=ns&since=1h", "namespace=ns&name=hello", "namespace=ns&name=hello&since=-1h"} {
+		w = httptest.NewRecorder()
+		s.handler(w, httptest.NewRequest("GET", "/stats/functions?"+query, nil))
+		assert.Equal(t, http.StatusBadRequest, w.Code, query)
+	}
+}
@@ -0,0 +1,65 @@
+/*
+Copyright 2019 The Fission Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package router
+
+import (
+	"crypto/tls"
+	"io"
+	"net"
+	"time"
+
+	"golang.org/x/net/http2"
+
+	fv1 "github.com/fission/fission/pkg/apis/fission.io/v1"
+)
+
+type (
+	// transportClosingBody closes the connections of the per-request
+	// transport of a gRPC call once the response body is closed, which
+	// happens after the stream has ended.
+	transportClosingBody struct {
+		io.ReadCloser
+		transport *http2.Transport
+	}
+)
+
+func (b *transportClosingBody) Close() error {
+	err := b.ReadCloser.Close()
+	b.transport.CloseIdleConnections()
+	return err
+}
+
+// getGRPCTransport returns a transport speaking cleartext HTTP/2 (h2c) to
+// the function pod, as gRPC servers do without TLS.
+func getGRPCTransport(dialTimeout time.Duration, keepAlive time.Duration) *http2.Transport {
+	dialer := &net.Dialer{
+		Timeout:   dialTimeout,
+		KeepAlive: keepAlive,
+	}
+	return &http2.Transport{
+		AllowHTTP: true,
+		DialTLS: func(network, addr string, _ *tls.Config) (net.Conn, error) {
+			return dialer.Dial(network, addr)
+		},
+	}
+}
+
+// isGRPC returns true if the http trigger of the function handler proxies
+// gRPC calls to the function.
+func (fh *functionHandler) isGRPC() bool {
+	return fh.httpTrigger != nil && fh.httpTrigger.Spec.Protocol == fv1.HTTPTriggerProtocolGRPC
+}
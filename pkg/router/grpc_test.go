@@ -0,0 +1,59 @@
+package router
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+	"golang.org/x/net/http2"
+	"golang.org/x/net/http2/h2c"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	fv1 "github.com/fission/fission/pkg/apis/fission.io/v1"
+)
+
+func TestGRPCTransport(t *testing.T) {
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, 2, r.ProtoMajor)
+		w.Header().Set("Trailer", "Grpc-Status")
+		w.Header().Set("Content-Type", "application/grpc")
+		w.Write([]byte(r.URL.Path))
+		w.Header().Set("Grpc-Status", "0")
+	})
+	server := httptest.NewServer(h2c.NewHandler(handler, &http2.Server{}))
+	defer server.Close()
+
+	transport := getGRPCTransport(time.Second, time.Second)
+	req, err := http.NewRequest("POST", server.URL+"/helloworld.Greeter/SayHello", strings.NewReader(""))
+	assert.NoError(t, err)
+	resp, err := transport.RoundTrip(req)
+	assert.NoError(t, err)
+	resp.Body = &transportClosingBody{ReadCloser: resp.Body, transport: transport}
+	body, err := ioutil.ReadAll(resp.Body)
+	assert.NoError(t, err)
+	assert.NoError(t, resp.Body.Close())
+	assert.Equal(t, "/helloworld.Greeter/SayHello", string(body))
+	assert.Equal(t, "0", resp.Trailer.Get("Grpc-Status"))
+}
+
+func TestGRPCRequiresHTTP2(t *testing.T) {
+	fh := functionHandler{
+		logger: zap.NewNop(),
+		httpTrigger: &fv1.HTTPTrigger{
+			Metadata: metav1.ObjectMeta{Name: "greeter", Namespace: metav1.NamespaceDefault},
+			Spec:     fv1.HTTPTriggerSpec{Protocol: fv1.HTTPTriggerProtocolGRPC},
+		},
+		function: &metav1.ObjectMeta{Name: "greeter", Namespace: metav1.NamespaceDefault},
+	}
+
+	req := httptest.NewRequest("POST", "/helloworld.Greeter/SayHello", nil)
+	w := httptest.NewRecorder()
+	fh.handler(w, req)
+	assert.Equal(t, http.StatusHTTPVersionNotSupported, w.Code)
+	assert.Equal(t, errorClassHTTP2Required, w.Header().Get(HEADER_ERROR_CLASS))
+}
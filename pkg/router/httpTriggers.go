@@ -19,6 +19,8 @@ package router
 import (
 	"context"
 	"net/http"
+	"sort"
+	"strings"
 	"time"
 
 	"github.com/gorilla/mux"
@@ -53,11 +55,20 @@ type HTTPTriggerSet struct {
 	functions                  []fv1.Function
 	funcStore                  k8sCache.Store
 	funcController             k8sCache.Controller
+	aliases                    []fv1.FunctionAlias
+	aliasStore                 k8sCache.Store
+	aliasController            k8sCache.Controller
 	recorderSet                *RecorderSet
 	updateRouterRequestChannel chan struct{}
 	tsRoundTripperParams       *tsRoundTripperParams
 	isDebugEnv                 bool
 	svcAddrUpdateThrottler     *throttler.Throttler
+	circuitBreakers            *circuitBreakerMap
+	concurrencyLimiters        *concurrencyLimiterMap
+	rateLimiters               *rateLimiterMap
+	loadShedders               *loadShedderMap
+	authenticator              *authenticator
+	readiness                  *readinessGate
 }
 
 func makeHTTPTriggerSet(logger *zap.Logger, fmap *functionServiceMap, frmap *functionRecorderMap, trmap *triggerRecorderMap, fissionClient *crd.FissionClient,
@@ -75,6 +86,16 @@ func makeHTTPTriggerSet(logger *zap.Logger, fmap *functionServiceMap, frmap *fun
 		tsRoundTripperParams:       params,
 		isDebugEnv:                 isDebugEnv,
 		svcAddrUpdateThrottler:     actionThrottler,
+		concurrencyLimiters:        makeConcurrencyLimiterMap(logger),
+		loadShedders:               makeLoadShedderMap(logger),
+		readiness:                  &readinessGate{},
+	}
+	if params != nil {
+		httpTriggerSet.circuitBreakers = makeCircuitBreakerMap(logger, params.circuitBreaker)
+		httpTriggerSet.rateLimiters = makeRateLimiterMap(logger, params.rateLimitTrustForwardedFor)
+	}
+	if kubeClient != nil {
+		httpTriggerSet.authenticator = makeAuthenticator(logger, kubeClient)
 	}
 	var tStore, fnStore, rStore k8sCache.Store
 	var tController, fnController k8sCache.Controller
@@ -86,6 +107,7 @@ func makeHTTPTriggerSet(logger *zap.Logger, fmap *functionServiceMap, frmap *fun
 		fnStore, fnController = httpTriggerSet.initFunctionController()
 		httpTriggerSet.funcStore = fnStore
 		httpTriggerSet.funcController = fnController
+		httpTriggerSet.aliasStore, httpTriggerSet.aliasController = httpTriggerSet.initAliasController()
 	}
 	recorderSet = MakeRecorderSet(logger, httpTriggerSet, crdClient, rStore, frmap, trmap)
 	httpTriggerSet.recorderSet = recorderSet
@@ -98,7 +120,7 @@ func (ts *HTTPTriggerSet) subscribeRouter(ctx context.Context, mr *mutableRouter
 
 	if ts.fissionClient == nil {
 		// Used in tests only.
-		mr.updateRouter(ts.getRouter(nil))
+		mr.updateRouter(ts.getRouter(nil, nil))
 		ts.logger.Info("skipping continuous trigger updates")
 		return
 	}
@@ -106,6 +128,7 @@ func (ts *HTTPTriggerSet) subscribeRouter(ctx context.Context, mr *mutableRouter
 	go ts.syncTriggers()
 	go ts.runWatcher(ctx, ts.funcController)
 	go ts.runWatcher(ctx, ts.triggerController)
+	go ts.runWatcher(ctx, ts.aliasController)
 	if ts.recorderSet.recController != nil {
 		go ts.runWatcher(ctx, ts.recorderSet.recController)
 	} else {
@@ -121,11 +144,12 @@ func routerHealthHandler(w http.ResponseWriter, r *http.Request) {
 	w.WriteHeader(http.StatusOK)
 }
 
-func (ts *HTTPTriggerSet) getRouter(fnTimeoutMap map[types.UID]int) *mux.Router {
+func (ts *HTTPTriggerSet) getRouter(fnTimeoutMap map[types.UID]int, isolatedFns map[types.UID]bool) *mux.Router {
 	muxRouter := mux.NewRouter()
 
 	// HTTP triggers setup by the user
 	homeHandled := false
+	var prefixHandlers []*functionHandler
 	for i := range ts.triggers {
 		trigger := ts.triggers[i]
 
@@ -160,11 +184,18 @@ func (ts *HTTPTriggerSet) getRouter(fnTimeoutMap map[types.UID]int) *mux.Router
 			httpTrigger:              &trigger,
 			functionMetadataMap:      rr.functionMetadataMap,
 			fnWeightDistributionList: rr.functionWtDistributionList,
+			versionedFunctions:       rr.versionedFunctions,
 			tsRoundTripperParams:     ts.tsRoundTripperParams,
 			recorderName:             recorderName,
 			isDebugEnv:               ts.isDebugEnv,
 			svcAddrUpdateThrottler:   ts.svcAddrUpdateThrottler,
 			functionTimeoutMap:       fnTimeoutMap,
+			isolatedFunctions:        isolatedFns,
+			circuitBreakers:          ts.circuitBreakers,
+			concurrencyLimiters:      ts.concurrencyLimiters,
+			rateLimiters:             ts.rateLimiters,
+			loadShedders:             ts.loadShedders,
+			authenticator:            ts.authenticator,
 		}
 
 		// The functionHandler for HTTP trigger with fn reference type "FunctionReferenceTypeFunctionName",
@@ -174,18 +205,27 @@ func (ts *HTTPTriggerSet) getRouter(fnTimeoutMap map[types.UID]int) *mux.Router
 		// it's function metadata is decided dynamically before proxying the request in order to support canary
 		// deployment. For more details, please check "handler" function of functionHandler.
 
+		// The functionHandler For HTTP trigger with fn reference type "FunctionReferenceTypeFunctionAlias"
+		// is set up like either of the above, depending on the number of functions the alias points to.
+
 		if rr.resolveResultType == resolveResultSingleFunction {
 			for _, metadata := range fh.functionMetadataMap {
 				fh.function = metadata
 			}
 		}
 
-		ht := muxRouter.HandleFunc(trigger.Spec.RelativeURL, fh.handler)
-		ht.Methods(trigger.Spec.Method)
-		if trigger.Spec.Host != "" {
-			ht.Host(trigger.Spec.Host)
+		if len(trigger.Spec.Prefix) > 0 {
+			// routed after all other routes, see below
+			prefixHandlers = append(prefixHandlers, fh)
+			if trigger.Spec.Prefix == "/" && trigger.Spec.HasMethod(http.MethodGet) {
+				homeHandled = true
+			}
+			continue
 		}
-		if trigger.Spec.RelativeURL == "/" && trigger.Spec.Method == "GET" {
+
+		ht := muxRouter.HandleFunc(trigger.Spec.RelativeURL, fh.handler)
+		setTriggerRouteMatchers(ht, &trigger)
+		if trigger.Spec.RelativeURL == "/" && trigger.Spec.HasMethod(http.MethodGet) {
 			homeHandled = true
 		}
 	}
@@ -223,16 +263,83 @@ func (ts *HTTPTriggerSet) getRouter(fnTimeoutMap map[types.UID]int) *mux.Router
 			isDebugEnv:             ts.isDebugEnv,
 			svcAddrUpdateThrottler: ts.svcAddrUpdateThrottler,
 			functionTimeoutMap:     fnTimeoutMap,
+			isolatedFunctions:      isolatedFns,
+			circuitBreakers:        ts.circuitBreakers,
+			concurrencyLimiters:    ts.concurrencyLimiters,
 		}
 		muxRouter.HandleFunc(utils.UrlForFunction(function.Metadata.Name, function.Metadata.Namespace), fh.handler)
 	}
 
+	// Internal triggers for each function alias by name, for non-http
+	// triggers referencing aliases.
+	for _, alias := range ts.aliases {
+		m := alias.Metadata
+
+		rr, err := ts.resolver.resolveByAlias(m.Namespace, m.Name)
+		if err != nil {
+			// Ignore this route and let it 404.
+			ts.logger.Debug("error resolving function alias", zap.String("alias", m.Name), zap.Error(err))
+			continue
+		}
+
+		fh := &functionHandler{
+			logger:                   ts.logger.Named(m.Name),
+			fmap:                     ts.functionServiceMap,
+			frmap:                    ts.recorderSet.functionRecorderMap,
+			trmap:                    ts.recorderSet.triggerRecorderMap,
+			functionMetadataMap:      rr.functionMetadataMap,
+			fnWeightDistributionList: rr.functionWtDistributionList,
+			executor:                 ts.executor,
+			tsRoundTripperParams:     ts.tsRoundTripperParams,
+			isDebugEnv:               ts.isDebugEnv,
+			svcAddrUpdateThrottler:   ts.svcAddrUpdateThrottler,
+			functionTimeoutMap:       fnTimeoutMap,
+			isolatedFunctions:        isolatedFns,
+			circuitBreakers:          ts.circuitBreakers,
+			concurrencyLimiters:      ts.concurrencyLimiters,
+		}
+		if rr.resolveResultType == resolveResultSingleFunction {
+			for _, metadata := range fh.functionMetadataMap {
+				fh.function = metadata
+			}
+		}
+		muxRouter.HandleFunc(utils.UrlForFunctionAlias(m.Name, m.Namespace), fh.handler)
+	}
+
 	// Healthz endpoint for the router.
 	muxRouter.HandleFunc("/router-healthz", routerHealthHandler).Methods("GET")
+	muxRouter.HandleFunc("/router-readyz", ts.readiness.handler).Methods("GET")
+
+	// Prefix triggers match every path under the prefix, so they are
+	// routed last, the longest prefix first, to not shadow the routes of
+	// other triggers, functions and router itself.
+	sort.SliceStable(prefixHandlers, func(i, j int) bool {
+		return len(prefixHandlers[i].httpTrigger.Spec.Prefix) > len(prefixHandlers[j].httpTrigger.Spec.Prefix)
+	})
+	for _, fh := range prefixHandlers {
+		trigger := fh.httpTrigger
+		prefix := strings.TrimSuffix(trigger.Spec.Prefix, "/")
+		setTriggerRouteMatchers(muxRouter.PathPrefix(prefix+"/").HandlerFunc(fh.handler), trigger)
+		if len(prefix) > 0 {
+			// the prefix itself without the trailing slash
+			setTriggerRouteMatchers(muxRouter.HandleFunc(prefix, fh.handler), trigger)
+		}
+	}
 
 	return muxRouter
 }
 
+// setTriggerRouteMatchers makes the route of the trigger only match the
+// methods and host of the trigger.
+func setTriggerRouteMatchers(route *mux.Route, trigger *fv1.HTTPTrigger) {
+	if !trigger.Spec.HasMethod(fv1.HTTPMethodAny) {
+		route.Methods(trigger.Spec.GetMethods()...)
+	}
+	if trigger.Spec.Host != "" {
+		route.Host(trigger.Spec.Host)
+	}
+}
+
 func (ts *HTTPTriggerSet) updateTriggerStatusFailed(ht *fv1.HTTPTrigger, err error) {
 	// TODO
 }
@@ -317,6 +424,55 @@ func (ts *HTTPTriggerSet) initFunctionController() (k8sCache.Store, k8sCache.Con
 	return store, controller
 }
 
+func (ts *HTTPTriggerSet) initAliasController() (k8sCache.Store, k8sCache.Controller) {
+	resyncPeriod := 30 * time.Second
+	listWatch := k8sCache.NewListWatchFromClient(ts.crdClient, "functionaliases", metav1.NamespaceAll, fields.Everything())
+	store, controller := k8sCache.NewInformer(listWatch, &fv1.FunctionAlias{}, resyncPeriod,
+		k8sCache.ResourceEventHandlerFuncs{
+			AddFunc: func(obj interface{}) {
+				alias := obj.(*fv1.FunctionAlias)
+				ts.invalidateAliasReferences(alias)
+				ts.syncTriggers()
+			},
+			DeleteFunc: func(obj interface{}) {
+				alias, ok := obj.(*fv1.FunctionAlias)
+				if ok {
+					ts.invalidateAliasReferences(alias)
+				}
+				ts.syncTriggers()
+			},
+			UpdateFunc: func(oldObj interface{}, newObj interface{}) {
+				oldAlias := oldObj.(*fv1.FunctionAlias)
+				alias := newObj.(*fv1.FunctionAlias)
+
+				if oldAlias.Metadata.ResourceVersion == alias.Metadata.ResourceVersion {
+					return
+				}
+
+				ts.invalidateAliasReferences(alias)
+				ts.syncTriggers()
+			},
+		})
+	return store, controller
+}
+
+// invalidateAliasReferences drops the resolved function references of the
+// triggers referencing an alias, so that the triggers follow the alias.
+func (ts *HTTPTriggerSet) invalidateAliasReferences(alias *fv1.FunctionAlias) {
+	if ts.resolver == nil {
+		return
+	}
+	for key, rr := range ts.resolver.copy() {
+		if key.namespace == alias.Metadata.Namespace && rr.aliasName == alias.Metadata.Name {
+			ts.logger.Debug("invalidating resolver cache", zap.String("alias", alias.Metadata.Name))
+			err := ts.resolver.delete(key.namespace, key.triggerName, key.triggerResourceVersion)
+			if err != nil {
+				ts.logger.Error("error deleting functionReferenceResolver cache", zap.Error(err))
+			}
+		}
+	}
+}
+
 func (ts *HTTPTriggerSet) initRecorderController() (k8sCache.Store, k8sCache.Controller) {
 	resyncPeriod := 30 * time.Second
 	listWatch := k8sCache.NewListWatchFromClient(ts.crdClient, "recorders", metav1.NamespaceAll, fields.Everything())
@@ -359,19 +515,34 @@ func (ts *HTTPTriggerSet) updateRouter() {
 			triggers = append(triggers, *t.(*fv1.HTTPTrigger))
 		}
 		ts.triggers = triggers
+		ts.rateLimiters.sync(triggers)
+		ts.loadShedders.sync(triggers)
 
 		// get functions
 		latestFunctions := ts.funcStore.List()
 		functionTimeout := make(map[types.UID]int, len(latestFunctions))
+		isolatedFunctions := make(map[types.UID]bool)
 		functions := make([]fv1.Function, len(latestFunctions))
 		for _, f := range latestFunctions {
 			fn := *f.(*fv1.Function)
 			functionTimeout[fn.Metadata.UID] = fn.Spec.FunctionTimeout
+			if fn.Spec.InvokeStrategy.ExecutionStrategy.Isolation == fv1.IsolationModeInvocation {
+				isolatedFunctions[fn.Metadata.UID] = true
+			}
 			functions = append(functions, *f.(*fv1.Function))
 		}
 		ts.functions = functions
+		ts.concurrencyLimiters.sync(functions)
+
+		// get function aliases
+		latestAliases := ts.aliasStore.List()
+		aliases := make([]fv1.FunctionAlias, 0, len(latestAliases))
+		for _, a := range latestAliases {
+			aliases = append(aliases, *a.(*fv1.FunctionAlias))
+		}
+		ts.aliases = aliases
 
 		// make a new router and use it
-		ts.mutableRouter.updateRouter(ts.getRouter(functionTimeout))
+		ts.mutableRouter.updateRouter(ts.getRouter(functionTimeout, isolatedFunctions))
 	}
 }
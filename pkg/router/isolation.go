@@ -0,0 +1,108 @@
+/*
+Copyright 2019 The Fission Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package router
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/url"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+type (
+	// releasingBody releases the pod of an invocation of a function
+	// isolated per invocation once the reverse proxy is done with the
+	// response.
+	releasingBody struct {
+		io.ReadCloser
+		once    sync.Once
+		release func()
+	}
+
+	// releasingConn is a releasingBody of a websocket connection, the
+	// reverse proxy needs to write to it.
+	releasingConn struct {
+		io.ReadWriteCloser
+		once    sync.Once
+		release func()
+	}
+)
+
+func releaseOnClose(body io.ReadCloser, release func()) io.ReadCloser {
+	if conn, ok := body.(io.ReadWriteCloser); ok {
+		return &releasingConn{ReadWriteCloser: conn, release: release}
+	}
+	return &releasingBody{ReadCloser: body, release: release}
+}
+
+func (b *releasingBody) Close() error {
+	err := b.ReadCloser.Close()
+	b.once.Do(b.release)
+	return err
+}
+
+func (c *releasingConn) Close() error {
+	err := c.ReadWriteCloser.Close()
+	c.once.Do(c.release)
+	return err
+}
+
+// isIsolated returns whether each invocation of the function runs in a
+// fresh pod, see fv1.IsolationModeInvocation.
+func (fh *functionHandler) isIsolated() bool {
+	return fh.function != nil && fh.isolatedFunctions[fh.function.GetUID()]
+}
+
+// getIsolatedServiceEntryFromExecutor returns the address of a pod for
+// this invocation only, it is never cached.
+func (fh *functionHandler) getIsolatedServiceEntryFromExecutor(ctx context.Context) (*url.URL, error) {
+	service, coldStart, err := fh.executor.GetIsolatedServiceForFunction(ctx, fh.function)
+	if err != nil {
+		fh.logger.Error("error from GetIsolatedServiceForFunction",
+			zap.Error(err),
+			zap.String("function_name", fh.function.Name))
+		return nil, err
+	}
+	if coldStart {
+		functionStats.recordColdStart(fh.function.Namespace, fh.function.Name)
+	}
+	return url.Parse(fmt.Sprintf("http://%v", service))
+}
+
+// releaseService lets the executor delete the pod of an invocation of an
+// isolated function.
+func (fh *functionHandler) releaseService(serviceUrl *url.URL) {
+	if fh.executor == nil {
+		return
+	}
+	fnName := fh.function.Name
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+		defer cancel()
+		err := fh.executor.ReleaseService(ctx, serviceUrl)
+		if err != nil {
+			fh.logger.Error("error releasing isolated function service",
+				zap.Error(err),
+				zap.String("url", serviceUrl.String()),
+				zap.String("function_name", fnName))
+		}
+	}()
+}
@@ -0,0 +1,52 @@
+package router
+
+import (
+	"io"
+	"io/ioutil"
+	"net"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	k8stypes "k8s.io/apimachinery/pkg/types"
+)
+
+func TestReleaseOnClose(t *testing.T) {
+	released := 0
+	body := releaseOnClose(ioutil.NopCloser(strings.NewReader("hello")), func() { released++ })
+	_, ok := body.(io.ReadWriteCloser)
+	assert.False(t, ok)
+
+	data, err := ioutil.ReadAll(body)
+	assert.NoError(t, err)
+	assert.Equal(t, "hello", string(data))
+	assert.Equal(t, 0, released, "the pod is released once the response is closed")
+
+	body.Close()
+	body.Close()
+	assert.Equal(t, 1, released)
+
+	// the reverse proxy writes to the body of a websocket connection
+	client, server := net.Pipe()
+	defer server.Close()
+	conn := releaseOnClose(client, func() { released++ })
+	_, ok = conn.(io.ReadWriteCloser)
+	assert.True(t, ok)
+	conn.Close()
+	assert.Equal(t, 2, released)
+}
+
+func TestIsIsolated(t *testing.T) {
+	fh := &functionHandler{
+		function:          &metav1.ObjectMeta{Name: "foo", UID: "1"},
+		isolatedFunctions: map[k8stypes.UID]bool{"1": true},
+	}
+	assert.True(t, fh.isIsolated())
+
+	fh.function = &metav1.ObjectMeta{Name: "bar", UID: "2"}
+	assert.False(t, fh.isIsolated())
+
+	fh.function = nil
+	assert.False(t, fh.isIsolated())
+}
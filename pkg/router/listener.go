@@ -0,0 +1,171 @@
+/*
+Copyright 2019 The Fission Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package router
+
+import (
+	"crypto/tls"
+	"fmt"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	"go.uber.org/zap"
+	"golang.org/x/net/http2"
+	"golang.org/x/net/http2/h2c"
+)
+
+type (
+	// listenerParams configures the public listeners of router.
+	listenerParams struct {
+		// tlsPort is the port of the HTTPS listener, which is enabled when
+		// both certFile and keyFile are set. The plain HTTP listener stays
+		// enabled for fission components calling router in the cluster.
+		tlsPort  int
+		certFile string
+		keyFile  string
+
+		// h2c serves HTTP/2 without TLS on the plain HTTP listener, for
+		// clients or load balancers speaking cleartext HTTP/2. The HTTPS
+		// listener always negotiates HTTP/2 with ALPN.
+		h2c bool
+
+		// idleTimeout closes keep-alive connections idle for longer,
+		// 0 means no limit.
+		idleTimeout time.Duration
+	}
+
+	// certReloader serves the certificate in the cert and key files and
+	// reloads it once the files change, so that certificates renewed by
+	// cert-manager are picked up without restarting router.
+	certReloader struct {
+		logger   *zap.Logger
+		certFile string
+		keyFile  string
+
+		lock    sync.Mutex
+		cert    *tls.Certificate
+		modTime time.Time
+	}
+)
+
+func (params listenerParams) tlsEnabled() bool {
+	return len(params.certFile) > 0 && len(params.keyFile) > 0
+}
+
+// makeServers returns the plain HTTP server of router and, if TLS is
+// configured, the HTTPS server.
+func makeServers(logger *zap.Logger, port int, handler http.Handler, params listenerParams) ([]*http.Server, error) {
+	plainHandler := handler
+	if params.h2c {
+		plainHandler = h2c.NewHandler(handler, &http2.Server{IdleTimeout: params.idleTimeout})
+	}
+	servers := []*http.Server{
+		{
+			Addr:        fmt.Sprintf(":%v", port),
+			Handler:     plainHandler,
+			IdleTimeout: params.idleTimeout,
+		},
+	}
+	if !params.tlsEnabled() {
+		return servers, nil
+	}
+
+	reloader := &certReloader{
+		logger:   logger.Named("cert_reloader"),
+		certFile: params.certFile,
+		keyFile:  params.keyFile,
+	}
+	// fail at startup rather than on the first handshake
+	_, err := reloader.getCertificate(nil)
+	if err != nil {
+		return nil, err
+	}
+
+	tlsServer := &http.Server{
+		Addr:        fmt.Sprintf(":%v", params.tlsPort),
+		Handler:     handler,
+		IdleTimeout: params.idleTimeout,
+		TLSConfig: &tls.Config{
+			MinVersion:     tls.VersionTLS12,
+			GetCertificate: reloader.getCertificate,
+		},
+	}
+	err = http2.ConfigureServer(tlsServer, &http2.Server{IdleTimeout: params.idleTimeout})
+	if err != nil {
+		return nil, errors.Wrap(err, "error configuring HTTP/2")
+	}
+	return append(servers, tlsServer), nil
+}
+
+// listenAndServe serves requests on the server until it's shut down.
+func listenAndServe(logger *zap.Logger, server *http.Server) {
+	logger.Info("listening", zap.String("addr", server.Addr), zap.Bool("tls", server.TLSConfig != nil))
+
+	var err error
+	if server.TLSConfig != nil {
+		// the certificate is served by TLSConfig.GetCertificate
+		err = server.ListenAndServeTLS("", "")
+	} else {
+		err = server.ListenAndServe()
+	}
+	if err != http.ErrServerClosed {
+		logger.Fatal("error serving requests", zap.Error(err), zap.String("addr", server.Addr))
+	}
+}
+
+func (cr *certReloader) getCertificate(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+	info, err := os.Stat(cr.certFile)
+	if err != nil {
+		return cr.lastCertificate(errors.Wrapf(err, "error reading certificate file %v", cr.certFile))
+	}
+
+	cr.lock.Lock()
+	defer cr.lock.Unlock()
+
+	if cr.cert != nil && info.ModTime().Equal(cr.modTime) {
+		return cr.cert, nil
+	}
+
+	cert, err := tls.LoadX509KeyPair(cr.certFile, cr.keyFile)
+	if err != nil {
+		// the cert and key files may be in the middle of an update
+		if cr.cert != nil {
+			cr.logger.Error("error reloading certificate, keep serving the previous one", zap.Error(err))
+			return cr.cert, nil
+		}
+		return nil, errors.Wrap(err, "error loading certificate")
+	}
+	if cr.cert != nil {
+		cr.logger.Info("certificate reloaded", zap.String("cert_file", cr.certFile))
+	}
+	cr.cert = &cert
+	cr.modTime = info.ModTime()
+	return cr.cert, nil
+}
+
+func (cr *certReloader) lastCertificate(err error) (*tls.Certificate, error) {
+	cr.lock.Lock()
+	defer cr.lock.Unlock()
+
+	if cr.cert == nil {
+		return nil, err
+	}
+	cr.logger.Error("error checking certificate, keep serving the previous one", zap.Error(err))
+	return cr.cert, nil
+}
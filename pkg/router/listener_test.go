@@ -0,0 +1,45 @@
+/*
+Copyright 2019 The Fission Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package router
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+)
+
+func TestMakeServers(t *testing.T) {
+	handler := http.NotFoundHandler()
+
+	servers, err := makeServers(zap.NewNop(), 8888, handler, listenerParams{idleTimeout: time.Minute})
+	assert.Nil(t, err)
+	assert.Len(t, servers, 1)
+	assert.Equal(t, ":8888", servers[0].Addr)
+	assert.Equal(t, time.Minute, servers[0].IdleTimeout)
+	assert.Nil(t, servers[0].TLSConfig)
+
+	// router refuses to start with a certificate it can't load
+	_, err = makeServers(zap.NewNop(), 8888, handler, listenerParams{
+		tlsPort:  8443,
+		certFile: "/nonexistent/tls.crt",
+		keyFile:  "/nonexistent/tls.key",
+	})
+	assert.NotNil(t, err)
+}
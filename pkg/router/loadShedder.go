@@ -0,0 +1,121 @@
+/*
+Copyright 2019 The Fission Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package router
+
+import (
+	"sync"
+
+	"go.uber.org/zap"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	fv1 "github.com/fission/fission/pkg/apis/fission.io/v1"
+)
+
+// loadShedRetryAfter is the Retry-After in seconds of the requests shed
+// by a trigger at its concurrency cap.
+const loadShedRetryAfter = 1
+
+type (
+	// loadShedder counts the in-flight requests of a HTTP trigger.
+	loadShedder struct {
+		maxConcurrent int
+		inFlight      int
+	}
+
+	// loadShedderMap holds the load shedders of the HTTP triggers with a
+	// concurrency cap. Unlike the concurrency limit of a function, it
+	// never queues requests and is independent of the scaling of the
+	// function, so that bursty callers can't overload the downstream
+	// services the function shares with others.
+	loadShedderMap struct {
+		logger *zap.Logger
+
+		lock     sync.Mutex
+		shedders map[metadataKey]*loadShedder
+	}
+)
+
+func makeLoadShedderMap(logger *zap.Logger) *loadShedderMap {
+	return &loadShedderMap{
+		logger:   logger.Named("load_shedder_map"),
+		shedders: make(map[metadataKey]*loadShedder),
+	}
+}
+
+// sync updates the load shedders to the concurrency caps of the triggers.
+// The shedder of a trigger is kept across updates, so that the requests
+// in flight still count against a changed cap.
+func (lsm *loadShedderMap) sync(triggers []fv1.HTTPTrigger) {
+	if lsm == nil {
+		return
+	}
+
+	lsm.lock.Lock()
+	defer lsm.lock.Unlock()
+
+	shedders := make(map[metadataKey]*loadShedder)
+	for i := range triggers {
+		trigger := &triggers[i]
+		if trigger.Spec.MaxConcurrentRequests <= 0 {
+			continue
+		}
+		key := breakerKey(&trigger.Metadata)
+		s, ok := lsm.shedders[key]
+		if !ok {
+			s = &loadShedder{}
+		}
+		if s.maxConcurrent != trigger.Spec.MaxConcurrentRequests {
+			lsm.logger.Info("setting concurrency cap for trigger",
+				zap.String("trigger_name", trigger.Metadata.Name),
+				zap.String("trigger_namespace", trigger.Metadata.Namespace),
+				zap.Int("max_concurrent_requests", trigger.Spec.MaxConcurrentRequests))
+			s.maxConcurrent = trigger.Spec.MaxConcurrentRequests
+		}
+		shedders[key] = s
+	}
+	lsm.shedders = shedders
+}
+
+// tryAcquire counts a request against the concurrency cap of the trigger
+// without waiting. It returns a function to release the request, or false
+// if the trigger is at its cap and the request should be shed.
+func (lsm *loadShedderMap) tryAcquire(m *metav1.ObjectMeta) (func(), bool) {
+	if lsm == nil {
+		return func() {}, true
+	}
+
+	lsm.lock.Lock()
+	defer lsm.lock.Unlock()
+
+	s, ok := lsm.shedders[breakerKey(m)]
+	if !ok {
+		return func() {}, true
+	}
+	if s.inFlight >= s.maxConcurrent {
+		return nil, false
+	}
+	s.inFlight++
+
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			lsm.lock.Lock()
+			s.inFlight--
+			lsm.lock.Unlock()
+		})
+	}, true
+}
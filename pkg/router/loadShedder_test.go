@@ -0,0 +1,72 @@
+/*
+Copyright 2019 The Fission Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package router
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	fv1 "github.com/fission/fission/pkg/apis/fission.io/v1"
+)
+
+func TestLoadShedder(t *testing.T) {
+	lsm := makeLoadShedderMap(zap.NewNop())
+	trigger := fv1.HTTPTrigger{
+		Metadata: metav1.ObjectMeta{Name: "foo", Namespace: metav1.NamespaceDefault},
+		Spec:     fv1.HTTPTriggerSpec{MaxConcurrentRequests: 2},
+	}
+	lsm.sync([]fv1.HTTPTrigger{trigger})
+
+	// triggers without a cap are not limited
+	_, ok := lsm.tryAcquire(&metav1.ObjectMeta{Name: "bar", Namespace: metav1.NamespaceDefault})
+	assert.True(t, ok)
+
+	// requests above the cap are shed
+	release1, ok := lsm.tryAcquire(&trigger.Metadata)
+	assert.True(t, ok)
+	release2, ok := lsm.tryAcquire(&trigger.Metadata)
+	assert.True(t, ok)
+	_, ok = lsm.tryAcquire(&trigger.Metadata)
+	assert.False(t, ok)
+
+	// releasing twice frees a single slot
+	release1()
+	release1()
+	release3, ok := lsm.tryAcquire(&trigger.Metadata)
+	assert.True(t, ok)
+	_, ok = lsm.tryAcquire(&trigger.Metadata)
+	assert.False(t, ok)
+
+	// the requests in flight count against a lowered cap
+	trigger.Spec.MaxConcurrentRequests = 1
+	lsm.sync([]fv1.HTTPTrigger{trigger})
+	release2()
+	_, ok = lsm.tryAcquire(&trigger.Metadata)
+	assert.False(t, ok)
+	release3()
+	_, ok = lsm.tryAcquire(&trigger.Metadata)
+	assert.True(t, ok)
+
+	// removing the cap stops shedding
+	trigger.Spec.MaxConcurrentRequests = 0
+	lsm.sync([]fv1.HTTPTrigger{trigger})
+	_, ok = lsm.tryAcquire(&trigger.Metadata)
+	assert.True(t, ok)
+}
@@ -6,6 +6,7 @@ import (
 	"time"
 
 	"github.com/prometheus/client_golang/prometheus"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
 var globalFunctionCallCount uint64
@@ -70,7 +71,7 @@ var (
 		prometheus.SummaryOpts{
 			Name:       "fission_function_duration_seconds",
 			Help:       "Runtime duration of the Fission function.",
-			Objectives: map[float64]float64{0.5: 0.05, 0.9: 0.01, 0.99: 0.001},
+			Objectives: map[float64]float64{0.5: 0.05, 0.9: 0.01, 0.95: 0.005, 0.99: 0.001},
 		},
 		labelsStrings,
 	)
@@ -90,6 +91,31 @@ var (
 		},
 		labelsStrings,
 	)
+
+	// Resource usage reported by environments per invocation, see usage.go
+	functionUsageCPU = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "fission_function_usage_cpu_seconds_total",
+			Help: "CPU time used by the invocations of the function, as reported by its environment.",
+		},
+		[]string{"namespace", "name"},
+	)
+	functionUsageMemory = prometheus.NewSummaryVec(
+		prometheus.SummaryOpts{
+			Name:       "fission_function_usage_memory_bytes",
+			Help:       "Peak memory used by an invocation of the function, as reported by its environment.",
+			Objectives: map[float64]float64{0.5: 0.05, 0.9: 0.01, 0.99: 0.001},
+		},
+		[]string{"namespace", "name"},
+	)
+	functionCustomMetric = prometheus.NewSummaryVec(
+		prometheus.SummaryOpts{
+			Name:       "fission_function_custom_metric",
+			Help:       "Custom metrics reported by the invocations of the function.",
+			Objectives: map[float64]float64{0.5: 0.05, 0.9: 0.01, 0.99: 0.001},
+		},
+		[]string{"namespace", "name", "metric"},
+	)
 )
 
 func init() {
@@ -98,6 +124,9 @@ func init() {
 	prometheus.MustRegister(functionCallDuration)
 	prometheus.MustRegister(functionCallOverhead)
 	prometheus.MustRegister(functionCallResponseSize)
+	prometheus.MustRegister(functionUsageCPU)
+	prometheus.MustRegister(functionUsageMemory)
+	prometheus.MustRegister(functionCustomMetric)
 }
 
 func labelsToStrings(f *functionLabels, h *httpLabels) []string {
@@ -141,4 +170,18 @@ func functionCallCompleted(f *functionLabels, h *httpLabels, overhead, duration
 	if respSize != -1 {
 		functionCallResponseSize.WithLabelValues(l...).Observe(float64(respSize))
 	}
+
+	functionStats.recordCall(f.namespace, f.name, duration, h.code >= 400)
+}
+
+func functionUsageReported(fn *metav1.ObjectMeta, usage *invocationUsage) {
+	if usage.cpuSeconds != nil {
+		functionUsageCPU.WithLabelValues(fn.Namespace, fn.Name).Add(*usage.cpuSeconds)
+	}
+	if usage.memoryBytes != nil {
+		functionUsageMemory.WithLabelValues(fn.Namespace, fn.Name).Observe(*usage.memoryBytes)
+	}
+	for name, value := range usage.metrics {
+		functionCustomMetric.WithLabelValues(fn.Namespace, fn.Name, name).Observe(value)
+	}
 }
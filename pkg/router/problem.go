@@ -0,0 +1,117 @@
+/*
+Copyright 2019 The Fission Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package router
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	// HEADER_ERROR_CLASS is set on the error responses generated by the
+	// router, so that platform errors can be told apart from the errors
+	// returned by functions.
+	HEADER_ERROR_CLASS = "X-Fission-Error-Class"
+
+	problemContentType = "application/problem+json"
+
+	errorClassRateLimited         = "rate-limited"
+	errorClassCircuitOpen         = "circuit-open"
+	errorClassConcurrencyLimited  = "concurrency-limited"
+	errorClassLoadShed            = "load-shed"
+	errorClassFaultInjected       = "fault-injected"
+	errorClassNoBackend           = "no-backend"
+	errorClassFunctionUnavailable = "function-unavailable"
+	errorClassTimeout             = "timeout"
+	errorClassClientClosed        = "client-closed"
+	errorClassUpstream            = "upstream-error"
+	errorClassUnauthorized        = "unauthorized"
+	errorClassAuthUnavailable     = "auth-unavailable"
+	errorClassUnsupportedVersion  = "unsupported-api-version"
+	errorClassHTTP2Required       = "http2-required"
+)
+
+// problem is an error response of the router in the
+// application/problem+json format (RFC 7807).
+type problem struct {
+	Type   string `json:"type"`
+	Title  string `json:"title"`
+	Status int    `json:"status"`
+	Detail string `json:"detail,omitempty"`
+
+	ErrorClass string `json:"errorClass"`
+	Function   string `json:"function,omitempty"`
+	Namespace  string `json:"namespace,omitempty"`
+	Trigger    string `json:"trigger,omitempty"`
+	RequestID  string `json:"requestId,omitempty"`
+	Hint       string `json:"hint,omitempty"`
+}
+
+func makeProblem(req *http.Request, status int, errorClass string, detail string) *problem {
+	title := http.StatusText(status)
+	if len(title) == 0 {
+		title = strconv.Itoa(status)
+	}
+	return &problem{
+		Type:       "urn:fission:error:" + errorClass,
+		Title:      title,
+		Status:     status,
+		Detail:     detail,
+		ErrorClass: errorClass,
+		RequestID:  req.Header.Get(HEADER_REQUEST_ID),
+	}
+}
+
+func (p *problem) withFunction(fnMeta *metav1.ObjectMeta) *problem {
+	if fnMeta != nil {
+		p.Function = fnMeta.Name
+		p.Namespace = fnMeta.Namespace
+	}
+	return p
+}
+
+func (p *problem) withTrigger(trigger string) *problem {
+	p.Trigger = trigger
+	return p
+}
+
+func (p *problem) withHint(hint string) *problem {
+	p.Hint = hint
+	return p
+}
+
+func (p *problem) body() []byte {
+	// problem only holds strings and ints, marshalling cannot fail
+	body, _ := json.Marshal(p)
+	return body
+}
+
+func (p *problem) header(header http.Header) {
+	header.Set("Content-Type", problemContentType)
+	header.Set("X-Content-Type-Options", "nosniff")
+	header.Set(HEADER_ERROR_CLASS, p.ErrorClass)
+}
+
+// write writes the problem as the response, in place of http.Error.
+func (p *problem) write(w http.ResponseWriter) {
+	p.header(w.Header())
+	w.WriteHeader(p.Status)
+	w.Write(p.body())
+}
@@ -0,0 +1,194 @@
+/*
+Copyright 2019 The Fission Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package router
+
+import (
+	"net"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+	"golang.org/x/time/rate"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	fv1 "github.com/fission/fission/pkg/apis/fission.io/v1"
+)
+
+// clientLimiterCleanupInterval is how often the buckets of clients that
+// have been idle long enough to refill completely are dropped.
+const clientLimiterCleanupInterval = time.Minute
+
+type (
+	// triggerRateLimiter holds the token buckets of a HTTP trigger, a
+	// single bucket or one per client IP address.
+	triggerRateLimiter struct {
+		spec        fv1.RateLimit
+		limiter     *rate.Limiter
+		clients     map[string]*clientLimiter
+		lastCleanup time.Time
+	}
+
+	clientLimiter struct {
+		limiter  *rate.Limiter
+		lastSeen time.Time
+	}
+
+	// rateLimiterMap holds the rate limiters of the HTTP triggers with a
+	// rate limit. It protects functions from abusive clients without a
+	// separate API gateway in front of router.
+	rateLimiterMap struct {
+		logger *zap.Logger
+		// trustForwardedFor takes the client IP address from the
+		// X-Forwarded-For header, for router behind a load balancer
+		// or ingress controller.
+		trustForwardedFor bool
+
+		lock     sync.Mutex
+		limiters map[metadataKey]*triggerRateLimiter
+	}
+)
+
+func makeRateLimiterMap(logger *zap.Logger, trustForwardedFor bool) *rateLimiterMap {
+	return &rateLimiterMap{
+		logger:            logger.Named("rate_limiter_map"),
+		trustForwardedFor: trustForwardedFor,
+		limiters:          make(map[metadataKey]*triggerRateLimiter),
+	}
+}
+
+func (l *triggerRateLimiter) burst() int {
+	if l.spec.Burst > 0 {
+		return l.spec.Burst
+	}
+	return l.spec.RequestsPerSecond
+}
+
+func (l *triggerRateLimiter) newLimiter() *rate.Limiter {
+	return rate.NewLimiter(rate.Limit(l.spec.RequestsPerSecond), l.burst())
+}
+
+// sync updates the limiters to the rate limits of the triggers. A limiter
+// is replaced only if the rate limit of its trigger changed, so that the
+// buckets survive updates of the router.
+func (rlm *rateLimiterMap) sync(triggers []fv1.HTTPTrigger) {
+	if rlm == nil {
+		return
+	}
+
+	rlm.lock.Lock()
+	defer rlm.lock.Unlock()
+
+	limiters := make(map[metadataKey]*triggerRateLimiter)
+	for i := range triggers {
+		trigger := &triggers[i]
+		if trigger.Spec.RateLimit == nil || trigger.Spec.RateLimit.RequestsPerSecond <= 0 {
+			continue
+		}
+		key := breakerKey(&trigger.Metadata)
+		l, ok := rlm.limiters[key]
+		if !ok || l.spec != *trigger.Spec.RateLimit {
+			rlm.logger.Info("setting rate limit for trigger",
+				zap.String("trigger_name", trigger.Metadata.Name),
+				zap.String("trigger_namespace", trigger.Metadata.Namespace),
+				zap.Int("rps", trigger.Spec.RateLimit.RequestsPerSecond),
+				zap.Int("burst", trigger.Spec.RateLimit.Burst),
+				zap.Bool("per_client_ip", trigger.Spec.RateLimit.PerClientIP))
+			l = &triggerRateLimiter{
+				spec:        *trigger.Spec.RateLimit,
+				lastCleanup: time.Now(),
+			}
+			if l.spec.PerClientIP {
+				l.clients = make(map[string]*clientLimiter)
+			} else {
+				l.limiter = l.newLimiter()
+			}
+		}
+		limiters[key] = l
+	}
+	rlm.limiters = limiters
+}
+
+// allow takes a token for the request from the bucket of the trigger. It
+// returns false and the time until a token is available if the bucket is
+// empty.
+func (rlm *rateLimiterMap) allow(m *metav1.ObjectMeta, r *http.Request) (bool, time.Duration) {
+	if rlm == nil {
+		return true, 0
+	}
+
+	rlm.lock.Lock()
+	defer rlm.lock.Unlock()
+
+	l, ok := rlm.limiters[breakerKey(m)]
+	if !ok {
+		return true, 0
+	}
+
+	limiter := l.limiter
+	if l.spec.PerClientIP {
+		now := time.Now()
+		l.cleanup(now)
+
+		ip := rlm.clientIP(r)
+		c, ok := l.clients[ip]
+		if !ok {
+			c = &clientLimiter{limiter: l.newLimiter()}
+			l.clients[ip] = c
+		}
+		c.lastSeen = now
+		limiter = c.limiter
+	}
+
+	reservation := limiter.Reserve()
+	if delay := reservation.Delay(); delay > 0 {
+		// don't take the token of a rejected request
+		reservation.Cancel()
+		return false, delay
+	}
+	return true, 0
+}
+
+// cleanup drops the buckets of the clients idle long enough for their
+// bucket to be full again, a new bucket behaves the same.
+func (l *triggerRateLimiter) cleanup(now time.Time) {
+	if now.Sub(l.lastCleanup) < clientLimiterCleanupInterval {
+		return
+	}
+	l.lastCleanup = now
+
+	refill := time.Duration(float64(l.burst()) / float64(l.spec.RequestsPerSecond) * float64(time.Second))
+	for ip, c := range l.clients {
+		if now.Sub(c.lastSeen) > refill {
+			delete(l.clients, ip)
+		}
+	}
+}
+
+func (rlm *rateLimiterMap) clientIP(r *http.Request) string {
+	if rlm.trustForwardedFor {
+		if forwarded := r.Header.Get("X-Forwarded-For"); len(forwarded) > 0 {
+			return strings.TrimSpace(strings.Split(forwarded, ",")[0])
+		}
+	}
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
@@ -0,0 +1,67 @@
+/*
+Copyright 2019 The Fission Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package router
+
+import (
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	fv1 "github.com/fission/fission/pkg/apis/fission.io/v1"
+)
+
+func TestRateLimiter(t *testing.T) {
+	rlm := makeRateLimiterMap(zap.NewNop(), true)
+	trigger := fv1.HTTPTrigger{
+		Metadata: metav1.ObjectMeta{Name: "foo", Namespace: metav1.NamespaceDefault},
+		Spec:     fv1.HTTPTriggerSpec{RateLimit: &fv1.RateLimit{RequestsPerSecond: 1, Burst: 2}},
+	}
+	rlm.sync([]fv1.HTTPTrigger{trigger})
+	req := httptest.NewRequest("GET", "/foo", nil)
+
+	// triggers without a rate limit are not limited
+	ok, _ := rlm.allow(&metav1.ObjectMeta{Name: "bar", Namespace: metav1.NamespaceDefault}, req)
+	assert.True(t, ok)
+
+	// the burst is allowed, the next request has to wait for a token
+	for i := 0; i < 2; i++ {
+		ok, _ = rlm.allow(&trigger.Metadata, req)
+		assert.True(t, ok)
+	}
+	ok, retryAfter := rlm.allow(&trigger.Metadata, req)
+	assert.False(t, ok)
+	assert.True(t, retryAfter > 0)
+
+	// each client gets its own bucket
+	trigger.Spec.RateLimit.PerClientIP = true
+	rlm.sync([]fv1.HTTPTrigger{trigger})
+	req.Header.Set("X-Forwarded-For", "10.0.0.1, 10.0.0.2")
+	for i := 0; i < 2; i++ {
+		ok, _ = rlm.allow(&trigger.Metadata, req)
+		assert.True(t, ok)
+	}
+	ok, _ = rlm.allow(&trigger.Metadata, req)
+	assert.False(t, ok)
+
+	other := httptest.NewRequest("GET", "/foo", nil)
+	other.Header.Set("X-Forwarded-For", "10.0.0.3")
+	ok, _ = rlm.allow(&trigger.Metadata, other)
+	assert.True(t, ok)
+}
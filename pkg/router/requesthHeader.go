@@ -19,18 +19,42 @@ package router
 import (
 	"fmt"
 	"net/http"
+	"regexp"
 	"strings"
 
 	"github.com/gorilla/mux"
 	uuid "github.com/satori/go.uuid"
 
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	fv1 "github.com/fission/fission/pkg/apis/fission.io/v1"
 )
 
 const (
 	HEADERS_FISSION_FUNCTION_PREFIX = "Fission-Function"
+
+	// HEADER_REQUEST_ID is the ID of a function invocation. Environments
+	// tag the log lines written during the invocation with it, so that
+	// all logs of one invocation can be found with "fission fn logs --reqid".
+	HEADER_REQUEST_ID = "X-Fission-Request-Id"
+
+	// HEADER_REQUEST_TIMEOUT is the time in milliseconds the caller waits
+	// for the response. A cold start of the function isn't started, or is
+	// aborted, once the caller stops waiting.
+	HEADER_REQUEST_TIMEOUT = "X-Fission-Request-Timeout-Ms"
+
+	// HEADER_ENVOY_REQUEST_TIMEOUT is the same as HEADER_REQUEST_TIMEOUT,
+	// sent by Envoy, e.g. with Istio, for routes with a timeout.
+	HEADER_ENVOY_REQUEST_TIMEOUT = "X-Envoy-Expected-Rq-Timeout-Ms"
+
+	// HEADER_PATH_INFO is the path of a request to a prefix trigger
+	// after the prefix, for functions routing the requests themselves.
+	HEADER_PATH_INFO = "X-Fission-Path-Info"
 )
 
+// request IDs given by callers are written to logs, only allow safe characters
+var validRequestID = regexp.MustCompile(`^[a-zA-Z0-9._-]{1,128}$`)
+
 // setFunctionMetadataToHeaders set function metadatas to request header
 func setFunctionMetadataToHeader(meta *metav1.ObjectMeta, request *http.Request) {
 	request.Header.Set(fmt.Sprintf("X-%s-Uid", HEADERS_FISSION_FUNCTION_PREFIX), string(meta.UID))
@@ -49,6 +73,23 @@ func setPathInfoToHeader(request *http.Request) {
 	request.Header.Set("X-Fission-Full-Url", request.URL.String())
 }
 
+// setPrefixPathToHeader sets the path of a request to a prefix trigger
+// after the prefix to request header. It returns the path the request is
+// forwarded to the function with, the full path if the trigger keeps the
+// prefix.
+func setPrefixPathToHeader(trigger *fv1.HTTPTrigger, request *http.Request) string {
+	pathInfo := strings.TrimPrefix(request.URL.Path, strings.TrimSuffix(trigger.Spec.Prefix, "/"))
+	if !strings.HasPrefix(pathInfo, "/") {
+		pathInfo = "/" + pathInfo
+	}
+	request.Header.Set(HEADER_PATH_INFO, pathInfo)
+
+	if trigger.Spec.KeepPrefix {
+		return request.URL.Path
+	}
+	return pathInfo
+}
+
 // setRecordRequestIDHeader set record ID to request header
 func setRecordRequestIDHeader(recorderName string, request *http.Request) {
 	if len(recorderName) > 0 {
@@ -56,3 +97,15 @@ func setRecordRequestIDHeader(recorderName string, request *http.Request) {
 		request.Header.Set("X-Fission-ReqUID", reqUID)
 	}
 }
+
+// setRequestIDHeader sets the request ID to request header and returns it.
+// A valid ID given by the caller, e.g. a function calling another function,
+// is kept to correlate the logs of both invocations.
+func setRequestIDHeader(request *http.Request) string {
+	reqID := request.Header.Get(HEADER_REQUEST_ID)
+	if !validRequestID.MatchString(reqID) {
+		reqID = strings.ToLower(uuid.NewV4().String())
+		request.Header.Set(HEADER_REQUEST_ID, reqID)
+	}
+	return reqID
+}
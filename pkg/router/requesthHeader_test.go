@@ -0,0 +1,54 @@
+package router
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	fv1 "github.com/fission/fission/pkg/apis/fission.io/v1"
+)
+
+func TestSetRequestIDHeader(t *testing.T) {
+	req, err := http.NewRequest(http.MethodGet, "http://example.com/foo", nil)
+	assert.Nil(t, err)
+
+	reqID := setRequestIDHeader(req)
+	assert.NotEmpty(t, reqID)
+	assert.Equal(t, reqID, req.Header.Get(HEADER_REQUEST_ID))
+
+	// the id given by the caller is kept
+	req.Header.Set(HEADER_REQUEST_ID, "abc-123")
+	assert.Equal(t, "abc-123", setRequestIDHeader(req))
+
+	// unsafe ids are replaced
+	req.Header.Set(HEADER_REQUEST_ID, "abc\nlevel=error")
+	reqID = setRequestIDHeader(req)
+	assert.NotEqual(t, "abc\nlevel=error", reqID)
+	assert.Equal(t, reqID, req.Header.Get(HEADER_REQUEST_ID))
+}
+
+func TestSetPrefixPathToHeader(t *testing.T) {
+	trigger := &fv1.HTTPTrigger{Spec: fv1.HTTPTriggerSpec{Prefix: "/api/v1/"}}
+
+	for _, test := range []struct {
+		url      string
+		pathInfo string
+	}{
+		{"http://example.com/api/v1/users/1?expand=true", "/users/1"},
+		{"http://example.com/api/v1/", "/"},
+		{"http://example.com/api/v1", "/"},
+	} {
+		req, err := http.NewRequest(http.MethodGet, test.url, nil)
+		assert.Nil(t, err)
+		assert.Equal(t, test.pathInfo, setPrefixPathToHeader(trigger, req))
+		assert.Equal(t, test.pathInfo, req.Header.Get(HEADER_PATH_INFO))
+	}
+
+	// the full path is forwarded if the prefix is kept
+	trigger.Spec.KeepPrefix = true
+	req, err := http.NewRequest(http.MethodGet, "http://example.com/api/v1/users/1", nil)
+	assert.Nil(t, err)
+	assert.Equal(t, "/api/v1/users/1", setPrefixPathToHeader(trigger, req))
+	assert.Equal(t, "/users/1", req.Header.Get(HEADER_PATH_INFO))
+}
@@ -0,0 +1,61 @@
+/*
+Copyright 2019 The Fission Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package router
+
+import (
+	fv1 "github.com/fission/fission/pkg/apis/fission.io/v1"
+)
+
+// retryPolicy decides which failed calls to a function router retries.
+type retryPolicy struct {
+	// attempts is the max number of calls made for a request.
+	attempts int
+
+	// connectFailure retries the calls that failed to connect to the
+	// function pod.
+	connectFailure bool
+
+	// serverError retries the calls the function answered with a 5xx
+	// status, the request body is kept in memory to send it again.
+	serverError bool
+}
+
+// getRetryPolicy returns the retry policy of the calls to a function. The
+// policy of the trigger takes precedence over the router default, which
+// retries connect failures only.
+func getRetryPolicy(maxRetries int, trigger *fv1.HTTPTrigger) retryPolicy {
+	if trigger == nil || trigger.Spec.RetryPolicy == nil {
+		return retryPolicy{
+			attempts:       maxRetries,
+			connectFailure: true,
+		}
+	}
+
+	override := trigger.Spec.RetryPolicy
+	policy := retryPolicy{
+		attempts: override.Retries + 1,
+	}
+	for _, cond := range override.RetryOn {
+		switch cond {
+		case fv1.RetryOn5xx:
+			policy.serverError = true
+		case fv1.RetryOnConnectFailure:
+			policy.connectFailure = true
+		}
+	}
+	return policy
+}
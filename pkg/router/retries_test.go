@@ -0,0 +1,123 @@
+/*
+Copyright 2019 The Fission Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package router
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	fv1 "github.com/fission/fission/pkg/apis/fission.io/v1"
+	"github.com/fission/fission/pkg/types"
+)
+
+func TestGetRetryPolicy(t *testing.T) {
+	assert.Equal(t, retryPolicy{attempts: 10, connectFailure: true}, getRetryPolicy(10, nil))
+
+	trigger := &fv1.HTTPTrigger{
+		Spec: fv1.HTTPTriggerSpec{
+			RetryPolicy: &fv1.RetryPolicy{
+				Retries: 2,
+				RetryOn: []string{fv1.RetryOn5xx},
+			},
+		},
+	}
+	assert.Equal(t, retryPolicy{attempts: 3, serverError: true}, getRetryPolicy(10, trigger))
+
+	trigger.Spec.RetryPolicy = &fv1.RetryPolicy{}
+	assert.Equal(t, retryPolicy{attempts: 1}, getRetryPolicy(10, trigger))
+}
+
+func TestServerErrorRetries(t *testing.T) {
+	var calls int
+	backendServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		body, _ := ioutil.ReadAll(r.Body)
+		if calls < 3 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.Write(body)
+	}))
+	defer backendServer.Close()
+	backendURL, err := url.Parse(backendServer.URL)
+	panicIf(err)
+
+	fn := &metav1.ObjectMeta{Name: "foo", Namespace: metav1.NamespaceDefault}
+	logger, err := zap.NewDevelopment()
+	panicIf(err)
+
+	fmap := makeFunctionServiceMap(logger, 0)
+	fmap.assign(fn, backendURL)
+
+	httpTrigger := &fv1.HTTPTrigger{
+		Metadata: metav1.ObjectMeta{
+			Name:      "xxx",
+			Namespace: metav1.NamespaceDefault,
+		},
+		Spec: fv1.HTTPTriggerSpec{
+			FunctionReference: fv1.FunctionReference{
+				Type: types.FunctionReferenceTypeFunctionName,
+			},
+			RetryPolicy: &fv1.RetryPolicy{
+				Retries: 2,
+				RetryOn: []string{fv1.RetryOn5xx},
+			},
+		},
+	}
+
+	fh := &functionHandler{
+		logger:   logger,
+		fmap:     fmap,
+		function: fn,
+		tsRoundTripperParams: &tsRoundTripperParams{
+			timeout:         10 * time.Millisecond,
+			timeoutExponent: 2,
+			maxRetries:      10,
+		},
+		httpTrigger: httpTrigger,
+	}
+	functionHandlerServer := httptest.NewServer(http.HandlerFunc(fh.handler))
+	defer functionHandlerServer.Close()
+
+	// the body is sent again with every retry
+	resp, err := http.Post(functionHandlerServer.URL, "text/plain", strings.NewReader("hello"))
+	panicIf(err)
+	body, err := ioutil.ReadAll(resp.Body)
+	resp.Body.Close()
+	panicIf(err)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, "hello", string(body))
+	assert.Equal(t, 3, calls)
+
+	// the last response is returned once retries run out
+	calls = 0
+	httpTrigger.Spec.RetryPolicy.Retries = 1
+	resp, err = http.Post(functionHandlerServer.URL, "text/plain", strings.NewReader("hello"))
+	panicIf(err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusServiceUnavailable, resp.StatusCode)
+	assert.Equal(t, 2, calls)
+}
@@ -44,7 +44,10 @@ import (
 	"fmt"
 	"net/http"
 	"os"
+	"os/signal"
 	"strconv"
+	"strings"
+	"syscall"
 	"time"
 
 	"github.com/gorilla/mux"
@@ -55,7 +58,9 @@ import (
 
 	"github.com/fission/fission/pkg/crd"
 	executorClient "github.com/fission/fission/pkg/executor/client"
+	"github.com/fission/fission/pkg/health"
 	"github.com/fission/fission/pkg/throttler"
+	"github.com/fission/fission/pkg/utils"
 )
 
 // request url ---[mux]---> Function(name,uid) ----[fmap]----> k8s service url
@@ -68,20 +73,45 @@ func router(ctx context.Context, logger *zap.Logger, httpTriggerSet *HTTPTrigger
 	return mr
 }
 
-func serve(ctx context.Context, logger *zap.Logger, port int, httpTriggerSet *HTTPTriggerSet, resolver *functionReferenceResolver) {
+// serve serves requests until router receives SIGTERM, and then shuts
+// down gracefully so that rolling upgrades don't drop live requests.
+func serve(ctx context.Context, logger *zap.Logger, port int, httpTriggerSet *HTTPTriggerSet, resolver *functionReferenceResolver,
+	listener listenerParams, params shutdownParams) {
 	mr := router(ctx, logger, httpTriggerSet, resolver)
-	url := fmt.Sprintf(":%v", port)
-	http.ListenAndServe(url, &ochttp.Handler{
-		Handler: mr,
+	handler := &ochttp.Handler{
+		Handler:     mr,
+		Propagation: utils.TracePropagation,
 		StartOptions: trace.StartOptions{
 			Sampler: trace.AlwaysSample(),
 		},
-	})
+	}
+
+	servers, err := makeServers(logger, port, handler, listener)
+	if err != nil {
+		logger.Fatal("error setting up listeners", zap.Error(err))
+	}
+	for _, server := range servers {
+		go listenAndServe(logger, server)
+	}
+
+	sigs := make(chan os.Signal, 1)
+	signal.Notify(sigs, syscall.SIGTERM, os.Interrupt)
+	sig := <-sigs
+	logger.Info("received signal, shutting down router", zap.String("signal", sig.String()))
+
+	shutdown(logger, servers, httpTriggerSet.readiness, params)
 }
 
-func serveMetric(logger *zap.Logger) {
+func serveMetric(logger *zap.Logger, checker *health.Checker) {
 	// Expose the registered metrics via HTTP.
 	http.Handle("/metrics", promhttp.Handler())
+	// The router port serves the functions at any path, so the standard
+	// health endpoints are served along with the metrics.
+	http.HandleFunc("/healthz", checker.HealthzHandler)
+	http.HandleFunc("/readyz", checker.ReadyzHandler)
+	// The controller collects the function stats of all routers, see
+	// functionStats.go.
+	http.HandleFunc("/stats/functions", functionStats.handler)
 	err := http.ListenAndServe(metricAddr, nil)
 
 	logger.Fatal("done listening on metrics endpoint", zap.Error(err))
@@ -182,6 +212,113 @@ func Start(logger *zap.Logger, port int, executorUrl string) {
 			zap.Duration("default", svcAddrUpdateTimeout))
 	}
 
+	// circuitBreakerThreshold is the number of consecutive failures after which
+	// router fails fast for a function until the cooldown period passes.
+	circuitBreakerThresholdStr := os.Getenv("ROUTER_CIRCUIT_BREAKER_FAILURE_THRESHOLD")
+	circuitBreakerThreshold, err := strconv.Atoi(circuitBreakerThresholdStr)
+	if err != nil {
+		circuitBreakerThreshold = 0
+		logger.Error("failed to parse circuit breaker failure threshold from 'ROUTER_CIRCUIT_BREAKER_FAILURE_THRESHOLD' - circuit breaker disabled",
+			zap.Error(err),
+			zap.String("value", circuitBreakerThresholdStr))
+	}
+
+	circuitBreakerCooldownStr := os.Getenv("ROUTER_CIRCUIT_BREAKER_COOLDOWN")
+	circuitBreakerCooldown, err := time.ParseDuration(circuitBreakerCooldownStr)
+	if err != nil {
+		circuitBreakerCooldown = 30 * time.Second
+		logger.Error("failed to parse circuit breaker cooldown duration from 'ROUTER_CIRCUIT_BREAKER_COOLDOWN' - set to the default value",
+			zap.Error(err),
+			zap.String("value", circuitBreakerCooldownStr),
+			zap.Duration("default", circuitBreakerCooldown))
+	}
+
+	// websocketIdleTimeout is how long router keeps a websocket connection
+	// open without any traffic in either direction.
+	websocketIdleTimeoutStr := os.Getenv("ROUTER_WEBSOCKET_IDLE_TIMEOUT")
+	websocketIdleTimeout, err := time.ParseDuration(websocketIdleTimeoutStr)
+	if err != nil {
+		websocketIdleTimeout = 5 * time.Minute
+		logger.Error("failed to parse websocket idle timeout duration from 'ROUTER_WEBSOCKET_IDLE_TIMEOUT' - set to the default value",
+			zap.Error(err),
+			zap.String("value", websocketIdleTimeoutStr),
+			zap.Duration("default", websocketIdleTimeout))
+	}
+
+	// The connect, response header and total timeouts of the calls to
+	// functions, triggers can override them. They are unset by default,
+	// which keeps the dial timeout growing with the retry backoff and the
+	// total timeout at the function timeout.
+	upstreamTimeouts := upstreamTimeoutParams{
+		connectTimeout:        getOptionalDurationEnv(logger, "ROUTER_ROUND_TRIP_CONNECT_TIMEOUT"),
+		responseHeaderTimeout: getOptionalDurationEnv(logger, "ROUTER_ROUND_TRIP_RESPONSE_HEADER_TIMEOUT"),
+		totalTimeout:          getOptionalDurationEnv(logger, "ROUTER_ROUND_TRIP_TOTAL_TIMEOUT"),
+	}
+
+	// Per client rate limits identify clients by the X-Forwarded-For header
+	// only if router is told to trust it, otherwise clients could pick
+	// their own bucket.
+	rateLimitTrustForwardedForStr := os.Getenv("ROUTER_RATE_LIMIT_TRUST_FORWARDED_FOR")
+	rateLimitTrustForwardedFor, err := strconv.ParseBool(rateLimitTrustForwardedForStr)
+	if err != nil && len(rateLimitTrustForwardedForStr) > 0 {
+		logger.Error("failed to parse rate limit trust forwarded for option from 'ROUTER_RATE_LIMIT_TRUST_FORWARDED_FOR' - disabled",
+			zap.Error(err),
+			zap.String("value", rateLimitTrustForwardedForStr))
+	}
+
+	// shutdownReadinessGracePeriod should be longer than the time it takes
+	// for kubernetes to notice the failed readiness probe and update the
+	// endpoints of the router service.
+	shutdownReadinessGracePeriodStr := os.Getenv("ROUTER_SHUTDOWN_READINESS_GRACE_PERIOD")
+	shutdownReadinessGracePeriod, err := time.ParseDuration(shutdownReadinessGracePeriodStr)
+	if err != nil {
+		shutdownReadinessGracePeriod = 5 * time.Second
+		logger.Error("failed to parse shutdown readiness grace period from 'ROUTER_SHUTDOWN_READINESS_GRACE_PERIOD' - set to the default value",
+			zap.Error(err),
+			zap.String("value", shutdownReadinessGracePeriodStr),
+			zap.Duration("default", shutdownReadinessGracePeriod))
+	}
+
+	shutdownDrainTimeoutStr := os.Getenv("ROUTER_SHUTDOWN_DRAIN_TIMEOUT")
+	shutdownDrainTimeout, err := time.ParseDuration(shutdownDrainTimeoutStr)
+	if err != nil {
+		shutdownDrainTimeout = 30 * time.Second
+		logger.Error("failed to parse shutdown drain timeout from 'ROUTER_SHUTDOWN_DRAIN_TIMEOUT' - set to the default value",
+			zap.Error(err),
+			zap.String("value", shutdownDrainTimeoutStr),
+			zap.Duration("default", shutdownDrainTimeout))
+	}
+
+	// The router terminates TLS itself when a certificate is mounted, for
+	// clusters without an ingress controller in front of it.
+	tlsPortStr := os.Getenv("ROUTER_TLS_PORT")
+	tlsPort, err := strconv.Atoi(tlsPortStr)
+	if err != nil {
+		tlsPort = 8443
+		if len(tlsPortStr) > 0 {
+			logger.Error("failed to parse TLS port from 'ROUTER_TLS_PORT' - set to the default value",
+				zap.Error(err),
+				zap.String("value", tlsPortStr),
+				zap.Int("default", tlsPort))
+		}
+	}
+
+	h2cStr := os.Getenv("ROUTER_HTTP2_CLEARTEXT")
+	h2c, err := strconv.ParseBool(h2cStr)
+	if err != nil && len(h2cStr) > 0 {
+		logger.Error("failed to parse HTTP/2 cleartext option from 'ROUTER_HTTP2_CLEARTEXT' - disabled",
+			zap.Error(err),
+			zap.String("value", h2cStr))
+	}
+
+	listener := listenerParams{
+		tlsPort:     tlsPort,
+		certFile:    os.Getenv("ROUTER_TLS_CERT_FILE"),
+		keyFile:     os.Getenv("ROUTER_TLS_KEY_FILE"),
+		h2c:         h2c,
+		idleTimeout: getOptionalDurationEnv(logger, "ROUTER_HTTP_IDLE_TIMEOUT"),
+	}
+
 	triggers, _, fnStore := makeHTTPTriggerSet(logger.Named("triggerset"), fmap, frmap, trmap, fissionClient, kubeClient, executor, restClient, &tsRoundTripperParams{
 		timeout:           timeout,
 		timeoutExponent:   timeoutExponent,
@@ -189,14 +326,45 @@ func Start(logger *zap.Logger, port int, executorUrl string) {
 		keepAliveTime:     keepAliveTime,
 		maxRetries:        maxRetries,
 		svcAddrRetryCount: svcAddrRetryCount,
+		circuitBreaker: circuitBreakerParams{
+			failureThreshold: circuitBreakerThreshold,
+			cooldown:         circuitBreakerCooldown,
+		},
+		websocketIdleTimeout:       websocketIdleTimeout,
+		upstreamTimeouts:           upstreamTimeouts,
+		rateLimitTrustForwardedFor: rateLimitTrustForwardedFor,
 	}, isDebugEnv, throttler.MakeThrottler(svcAddrUpdateTimeout))
 
-	resolver := makeFunctionReferenceResolver(fnStore)
+	resolver := makeFunctionReferenceResolver(fnStore, triggers.aliasStore)
 
-	go serveMetric(logger)
+	checker := health.MakeChecker("router")
+	checker.AddCheck("crd", fissionClient.CheckCRDs)
+	checker.AddCheck("executor", health.HTTPCheck(strings.TrimSuffix(executorUrl, "/")+"/healthz"))
+	checker.AddCheck("shutdown", triggers.readiness.check)
+	go serveMetric(logger, checker)
 
 	logger.Info("starting router", zap.Int("port", port))
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
-	serve(ctx, logger, port, triggers, resolver)
+	serve(ctx, logger, port, triggers, resolver, listener, shutdownParams{
+		readinessGracePeriod: shutdownReadinessGracePeriod,
+		drainTimeout:         shutdownDrainTimeout,
+	})
+}
+
+// getOptionalDurationEnv returns the duration of an environment variable,
+// or 0 if the variable is unset or invalid.
+func getOptionalDurationEnv(logger *zap.Logger, name string) time.Duration {
+	value := os.Getenv(name)
+	if len(value) == 0 {
+		return 0
+	}
+	d, err := time.ParseDuration(value)
+	if err != nil || d < 0 {
+		logger.Error(fmt.Sprintf("failed to parse duration from '%v' - ignored", name),
+			zap.Error(err),
+			zap.String("value", value))
+		return 0
+	}
+	return d
 }
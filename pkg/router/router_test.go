@@ -78,7 +78,7 @@ func TestRouter(t *testing.T) {
 		})
 
 	// set up the resolver's cache for this function
-	frr := makeFunctionReferenceResolver(nil)
+	frr := makeFunctionReferenceResolver(nil, nil)
 	nfr := namespacedTriggerReference{
 		namespace:              metav1.NamespaceDefault,
 		triggerName:            "xxx",
@@ -98,7 +98,7 @@ func TestRouter(t *testing.T) {
 	port := 4242
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
-	go serve(ctx, logger, port, triggers, frr)
+	go serve(ctx, logger, port, triggers, frr, listenerParams{}, shutdownParams{})
 	time.Sleep(100 * time.Millisecond)
 
 	// hit the router
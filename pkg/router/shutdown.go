@@ -0,0 +1,107 @@
+/*
+Copyright 2019 The Fission Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package router
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+type (
+	// shutdownParams configures the graceful shutdown of router.
+	shutdownParams struct {
+		// readinessGracePeriod is how long router keeps accepting requests
+		// after failing readiness, so that its endpoint is removed from the
+		// service before it stops listening.
+		readinessGracePeriod time.Duration
+
+		// drainTimeout is how long router waits for in-flight requests to
+		// finish once it stops accepting connections.
+		drainTimeout time.Duration
+	}
+
+	// readinessGate fails the readiness probe of router once it starts
+	// shutting down.
+	readinessGate struct {
+		draining int32
+	}
+)
+
+func (rg *readinessGate) drain() {
+	atomic.StoreInt32(&rg.draining, 1)
+}
+
+func (rg *readinessGate) isDraining() bool {
+	return atomic.LoadInt32(&rg.draining) == 1
+}
+
+func (rg *readinessGate) check() error {
+	if rg.isDraining() {
+		return errors.New("router is shutting down")
+	}
+	return nil
+}
+
+func (rg *readinessGate) handler(w http.ResponseWriter, r *http.Request) {
+	if rg.isDraining() {
+		http.Error(w, "router is shutting down", http.StatusServiceUnavailable)
+		return
+	}
+	w.WriteHeader(http.StatusOK)
+}
+
+// shutdown fails readiness, waits for the endpoint of router to be removed
+// from the service, and then stops the servers, which stop accepting new
+// connections and wait for in-flight requests to finish.
+func shutdown(logger *zap.Logger, servers []*http.Server, readiness *readinessGate, params shutdownParams) {
+	logger.Info("failing readiness before shutting down",
+		zap.Duration("readiness_grace_period", params.readinessGracePeriod))
+	readiness.drain()
+	// ask keep-alive clients to reconnect, which gets them to other router instances
+	for _, server := range servers {
+		server.SetKeepAlivesEnabled(false)
+	}
+	time.Sleep(params.readinessGracePeriod)
+
+	logger.Info("draining in-flight requests", zap.Duration("drain_timeout", params.drainTimeout))
+	ctx, cancel := context.WithTimeout(context.Background(), params.drainTimeout)
+	defer cancel()
+
+	var wg sync.WaitGroup
+	var failed int32
+	for _, server := range servers {
+		wg.Add(1)
+		go func(server *http.Server) {
+			defer wg.Done()
+			err := server.Shutdown(ctx)
+			if err != nil {
+				atomic.StoreInt32(&failed, 1)
+				logger.Error("error draining in-flight requests", zap.Error(err), zap.String("addr", server.Addr))
+			}
+		}(server)
+	}
+	wg.Wait()
+	if atomic.LoadInt32(&failed) == 0 {
+		logger.Info("all in-flight requests finished")
+	}
+}
@@ -0,0 +1,88 @@
+/*
+Copyright 2019 The Fission Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package router
+
+import (
+	"io/ioutil"
+	"net"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+)
+
+func TestGracefulShutdown(t *testing.T) {
+	readiness := &readinessGate{}
+	requestStarted := make(chan struct{})
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/router-readyz", readiness.handler)
+	mux.HandleFunc("/slow", func(w http.ResponseWriter, r *http.Request) {
+		close(requestStarted)
+		time.Sleep(200 * time.Millisecond)
+		w.Write([]byte("done"))
+	})
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.Nil(t, err)
+	server := &http.Server{Handler: mux}
+	go server.Serve(listener)
+	url := "http://" + listener.Addr().String()
+
+	resp, err := http.Get(url + "/router-readyz")
+	assert.Nil(t, err)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	resp.Body.Close()
+
+	// the in-flight request completes even though shutdown starts meanwhile
+	result := make(chan string)
+	go func() {
+		resp, err := http.Get(url + "/slow")
+		if err != nil {
+			result <- err.Error()
+			return
+		}
+		defer resp.Body.Close()
+		body, _ := ioutil.ReadAll(resp.Body)
+		result <- string(body)
+	}()
+	<-requestStarted
+
+	done := make(chan struct{})
+	go func() {
+		shutdown(zap.NewNop(), []*http.Server{server}, readiness, shutdownParams{
+			readinessGracePeriod: 50 * time.Millisecond,
+			drainTimeout:         time.Second,
+		})
+		close(done)
+	}()
+
+	time.Sleep(10 * time.Millisecond)
+	resp, err = http.Get(url + "/router-readyz")
+	assert.Nil(t, err)
+	assert.Equal(t, http.StatusServiceUnavailable, resp.StatusCode)
+	resp.Body.Close()
+
+	assert.Equal(t, "done", <-result)
+	<-done
+
+	// new connections are refused after shutdown
+	_, err = http.Get(url + "/router-readyz")
+	assert.NotNil(t, err)
+}
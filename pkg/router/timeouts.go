@@ -0,0 +1,102 @@
+/*
+Copyright 2019 The Fission Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package router
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	fv1 "github.com/fission/fission/pkg/apis/fission.io/v1"
+)
+
+type (
+	// upstreamTimeouts are the timeouts of the calls router makes to a function.
+	upstreamTimeouts struct {
+		// connect is the dial timeout of a connection to a function pod,
+		// 0 means the dial timeout grows with the retry backoff.
+		connect time.Duration
+
+		// responseHeader is the max time to wait for the response headers
+		// once the request is sent, 0 means no limit.
+		responseHeader time.Duration
+
+		// total is the max time of a call to the function.
+		total time.Duration
+	}
+
+	// upstreamTimeoutParams are the router defaults of the timeouts.
+	upstreamTimeoutParams struct {
+		connectTimeout        time.Duration
+		responseHeaderTimeout time.Duration
+
+		// totalTimeout applies to functions without a function timeout,
+		// 0 means fv1.DEFAULT_FUNCTION_TIMEOUT.
+		totalTimeout time.Duration
+	}
+)
+
+// getUpstreamTimeouts returns the timeouts of a call to a function. The
+// timeouts of the trigger take precedence over the function timeout, which
+// takes precedence over the router defaults.
+func getUpstreamTimeouts(params upstreamTimeoutParams, fnTimeout int, trigger *fv1.HTTPTrigger) upstreamTimeouts {
+	timeouts := upstreamTimeouts{
+		connect:        params.connectTimeout,
+		responseHeader: params.responseHeaderTimeout,
+		total:          params.totalTimeout,
+	}
+	if fnTimeout > 0 {
+		timeouts.total = time.Duration(fnTimeout) * time.Second
+	}
+	if timeouts.total <= 0 {
+		timeouts.total = time.Duration(fv1.DEFAULT_FUNCTION_TIMEOUT) * time.Second
+	}
+
+	if trigger == nil || trigger.Spec.Timeouts == nil {
+		return timeouts
+	}
+	override := trigger.Spec.Timeouts
+	if override.ConnectTimeout > 0 {
+		timeouts.connect = time.Duration(override.ConnectTimeout) * time.Millisecond
+	}
+	if override.ResponseHeaderTimeout > 0 {
+		timeouts.responseHeader = time.Duration(override.ResponseHeaderTimeout) * time.Millisecond
+	}
+	if override.TotalTimeout > 0 {
+		timeouts.total = time.Duration(override.TotalTimeout) * time.Millisecond
+	}
+	return timeouts
+}
+
+// getRequestDeadline returns the time by which the caller of the request
+// stops waiting for the response, i.e. the earliest of the deadline of the
+// request context and of the request timeout headers counted from start.
+// It returns the zero time if the caller didn't set any.
+func getRequestDeadline(req *http.Request, start time.Time) time.Time {
+	deadline, _ := req.Context().Deadline()
+	for _, header := range []string{HEADER_REQUEST_TIMEOUT, HEADER_ENVOY_REQUEST_TIMEOUT} {
+		ms, err := strconv.ParseInt(req.Header.Get(header), 10, 64)
+		if err != nil || ms <= 0 {
+			continue
+		}
+		d := start.Add(time.Duration(ms) * time.Millisecond)
+		if deadline.IsZero() || d.Before(deadline) {
+			deadline = d
+		}
+	}
+	return deadline
+}
@@ -0,0 +1,77 @@
+/*
+Copyright 2019 The Fission Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package router
+
+import (
+	"context"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	fv1 "github.com/fission/fission/pkg/apis/fission.io/v1"
+)
+
+func TestGetUpstreamTimeouts(t *testing.T) {
+	timeouts := getUpstreamTimeouts(upstreamTimeoutParams{}, 0, nil)
+	assert.Equal(t, upstreamTimeouts{total: 60 * time.Second}, timeouts)
+
+	params := upstreamTimeoutParams{
+		connectTimeout:        time.Second,
+		responseHeaderTimeout: 10 * time.Second,
+		totalTimeout:          30 * time.Second,
+	}
+	timeouts = getUpstreamTimeouts(params, 0, nil)
+	assert.Equal(t, upstreamTimeouts{connect: time.Second, responseHeader: 10 * time.Second, total: 30 * time.Second}, timeouts)
+
+	// the function timeout takes precedence over the router default
+	timeouts = getUpstreamTimeouts(params, 120, nil)
+	assert.Equal(t, 120*time.Second, timeouts.total)
+
+	trigger := &fv1.HTTPTrigger{
+		Spec: fv1.HTTPTriggerSpec{
+			Timeouts: &fv1.UpstreamTimeouts{
+				ResponseHeaderTimeout: 90000,
+				TotalTimeout:          300000,
+			},
+		},
+	}
+	timeouts = getUpstreamTimeouts(params, 120, trigger)
+	assert.Equal(t, upstreamTimeouts{connect: time.Second, responseHeader: 90 * time.Second, total: 5 * time.Minute}, timeouts)
+}
+
+func TestGetRequestDeadline(t *testing.T) {
+	start := time.Now()
+
+	req := httptest.NewRequest("GET", "/", nil)
+	assert.True(t, getRequestDeadline(req, start).IsZero())
+
+	req.Header.Set(HEADER_REQUEST_TIMEOUT, "invalid")
+	assert.True(t, getRequestDeadline(req, start).IsZero())
+
+	req.Header.Set(HEADER_REQUEST_TIMEOUT, "5000")
+	assert.Equal(t, start.Add(5*time.Second), getRequestDeadline(req, start))
+
+	// the earliest deadline wins
+	req.Header.Set(HEADER_ENVOY_REQUEST_TIMEOUT, "2000")
+	assert.Equal(t, start.Add(2*time.Second), getRequestDeadline(req, start))
+
+	ctx, cancel := context.WithDeadline(req.Context(), start.Add(time.Second))
+	defer cancel()
+	assert.Equal(t, start.Add(time.Second), getRequestDeadline(req.WithContext(ctx), start))
+}
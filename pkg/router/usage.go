@@ -0,0 +1,178 @@
+/*
+Copyright 2019 The Fission Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package router
+
+import (
+	"io"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"go.uber.org/zap"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	// HEADER_USAGE_CPU is the CPU time in seconds an invocation used,
+	// reported by the environment in a response header or trailer.
+	HEADER_USAGE_CPU = "X-Fission-Usage-Cpu-Seconds"
+
+	// HEADER_USAGE_MEMORY is the peak memory in bytes an invocation used.
+	HEADER_USAGE_MEMORY = "X-Fission-Usage-Memory-Bytes"
+
+	// HEADER_METRIC_PREFIX prefixes custom metrics of an invocation, e.g.
+	// "X-Fission-Metric-Rows-Processed: 42" reports rows_processed.
+	HEADER_METRIC_PREFIX = "X-Fission-Metric-"
+
+	// maxCustomMetrics bounds the custom metrics of a response, every
+	// metric name is a label value of the custom metric summary.
+	maxCustomMetrics = 10
+)
+
+var validMetricName = regexp.MustCompile(`^[a-z][a-z0-9_]{0,62}$`)
+
+type (
+	// invocationUsage is the resource usage and the custom metrics an
+	// environment reported for an invocation.
+	invocationUsage struct {
+		cpuSeconds  *float64
+		memoryBytes *float64
+		metrics     map[string]float64
+	}
+
+	// usageTrailerReader records the usage reported in the trailers of a
+	// response once its body is read.
+	usageTrailerReader struct {
+		io.ReadCloser
+		logger *zap.Logger
+		resp   *http.Response
+		record func(*invocationUsage)
+		done   bool
+	}
+)
+
+func (u *invocationUsage) empty() bool {
+	return u.cpuSeconds == nil && u.memoryBytes == nil && len(u.metrics) == 0
+}
+
+// extractUsage parses the usage headers, and removes them so they aren't
+// returned to the caller. Invalid values are ignored.
+func extractUsage(logger *zap.Logger, header http.Header) *invocationUsage {
+	usage := &invocationUsage{
+		metrics: make(map[string]float64),
+	}
+	for key, values := range header {
+		if len(values) == 0 {
+			continue
+		}
+
+		var name string
+		switch {
+		case key == HEADER_USAGE_CPU, key == HEADER_USAGE_MEMORY:
+		case strings.HasPrefix(key, HEADER_METRIC_PREFIX):
+			name = strings.ToLower(strings.Replace(strings.TrimPrefix(key, HEADER_METRIC_PREFIX), "-", "_", -1))
+		default:
+			continue
+		}
+		header.Del(key)
+
+		value, err := strconv.ParseFloat(strings.TrimSpace(values[0]), 64)
+		if err != nil || value < 0 {
+			logger.Debug("ignoring invalid usage reported by function", zap.String("header", key), zap.String("value", values[0]))
+			continue
+		}
+
+		switch key {
+		case HEADER_USAGE_CPU:
+			usage.cpuSeconds = &value
+		case HEADER_USAGE_MEMORY:
+			usage.memoryBytes = &value
+		default:
+			if !validMetricName.MatchString(name) || len(usage.metrics) >= maxCustomMetrics {
+				logger.Debug("ignoring custom metric reported by function", zap.String("header", key))
+				continue
+			}
+			usage.metrics[name] = value
+		}
+	}
+	return usage
+}
+
+// hasUsageTrailers returns true if a response announced usage trailers.
+func hasUsageTrailers(resp *http.Response) bool {
+	for key := range resp.Trailer {
+		if key == HEADER_USAGE_CPU || key == HEADER_USAGE_MEMORY || strings.HasPrefix(key, HEADER_METRIC_PREFIX) {
+			return true
+		}
+	}
+	return false
+}
+
+func (r *usageTrailerReader) Read(p []byte) (int, error) {
+	n, err := r.ReadCloser.Read(p)
+	if err == io.EOF && !r.done {
+		// trailers are only available after the body is read, and are
+		// copied to the caller by the proxy after this
+		r.done = true
+		r.record(extractUsage(r.logger, r.resp.Trailer))
+	}
+	return n, err
+}
+
+// recordInvocationUsage reads the usage reported by the function in the
+// headers of the response, and in its trailers once the body is read, into
+// the usage metrics of the function.
+func recordInvocationUsage(logger *zap.Logger, fn *metav1.ObjectMeta, resp *http.Response) {
+	record := func(usage *invocationUsage) {
+		if usage.empty() {
+			return
+		}
+		functionUsageReported(fn, usage)
+
+		var reqID string
+		if resp.Request != nil {
+			reqID = resp.Request.Header.Get(HEADER_REQUEST_ID)
+		}
+		fields := []zap.Field{
+			zap.String("function_name", fn.Name),
+			zap.String("function_namespace", fn.Namespace),
+			zap.String("request_id", reqID),
+		}
+		if usage.cpuSeconds != nil {
+			fields = append(fields, zap.Float64("cpu_seconds", *usage.cpuSeconds))
+		}
+		if usage.memoryBytes != nil {
+			fields = append(fields, zap.Float64("memory_bytes", *usage.memoryBytes))
+		}
+		for name, value := range usage.metrics {
+			fields = append(fields, zap.Float64("metric_"+name, value))
+		}
+		logger.Debug("function invocation usage", fields...)
+	}
+
+	record(extractUsage(logger, resp.Header))
+
+	if hasUsageTrailers(resp) {
+		resp.Body = &usageTrailerReader{
+			ReadCloser: resp.Body,
+			logger:     logger,
+			resp:       resp,
+			record:     record,
+		}
+	}
+}
@@ -0,0 +1,88 @@
+/*
+Copyright 2019 The Fission Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package router
+
+import (
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+)
+
+func TestExtractUsage(t *testing.T) {
+	header := http.Header{}
+	header.Set("Content-Type", "text/plain")
+	header.Set(HEADER_USAGE_CPU, "0.25")
+	header.Set(HEADER_USAGE_MEMORY, "1048576")
+	header.Set("X-Fission-Metric-Rows-Processed", "42")
+	header.Set("X-Fission-Metric-Bad", "not-a-number")
+	header.Set("X-Fission-Metric-9lives", "1")
+
+	usage := extractUsage(zap.NewNop(), header)
+	assert.Equal(t, 0.25, *usage.cpuSeconds)
+	assert.Equal(t, float64(1048576), *usage.memoryBytes)
+	assert.Equal(t, map[string]float64{"rows_processed": 42}, usage.metrics)
+
+	// only the usage headers are removed from the response
+	assert.Equal(t, http.Header{"Content-Type": []string{"text/plain"}}, header)
+
+	assert.True(t, extractUsage(zap.NewNop(), http.Header{}).empty())
+}
+
+func TestExtractUsageLimitsCustomMetrics(t *testing.T) {
+	header := http.Header{}
+	for _, c := range "abcdefghijklmnop" {
+		header.Set(HEADER_METRIC_PREFIX+string(c), "1")
+	}
+	usage := extractUsage(zap.NewNop(), header)
+	assert.Len(t, usage.metrics, maxCustomMetrics)
+	assert.Empty(t, header)
+}
+
+func TestUsageTrailerReader(t *testing.T) {
+	resp := &http.Response{
+		Header: http.Header{},
+		Body:   ioutil.NopCloser(strings.NewReader("hello")),
+		Trailer: http.Header{
+			HEADER_USAGE_CPU: []string{"1.5"},
+			"X-Other":        []string{"kept"},
+		},
+	}
+	assert.True(t, hasUsageTrailers(resp))
+
+	var reported []*invocationUsage
+	resp.Body = &usageTrailerReader{
+		ReadCloser: resp.Body,
+		logger:     zap.NewNop(),
+		resp:       resp,
+		record: func(usage *invocationUsage) {
+			reported = append(reported, usage)
+		},
+	}
+
+	body, err := ioutil.ReadAll(resp.Body)
+	assert.NoError(t, err)
+	assert.Equal(t, "hello", string(body))
+
+	assert.Len(t, reported, 1)
+	assert.Equal(t, 1.5, *reported[0].cpuSeconds)
+	assert.Equal(t, http.Header{"X-Other": []string{"kept"}}, resp.Trailer)
+	assert.False(t, hasUsageTrailers(resp))
+}
@@ -26,7 +26,7 @@ import (
 
 func GetIngressSpec(namespace string, trigger *fv1.HTTPTrigger) *v1beta1.Ingress {
 	// TODO: remove backward compatibility
-	host, path := trigger.Spec.Host, trigger.Spec.RelativeURL
+	host, path := trigger.Spec.Host, trigger.Spec.GetPath()
 	if len(trigger.Spec.IngressConfig.Host) > 0 && len(trigger.Spec.IngressConfig.Path) > 0 {
 		host, path = trigger.Spec.IngressConfig.Host, trigger.Spec.IngressConfig.Path
 	}
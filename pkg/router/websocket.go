@@ -0,0 +1,91 @@
+/*
+Copyright 2019 The Fission Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package router
+
+import (
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+type (
+	// idleTimeoutConn closes the underlying connection if there is no
+	// read or write for the timeout. Since the reverse proxy copies data
+	// of both directions through the function side of a websocket
+	// connection, it's enough to track that side only.
+	idleTimeoutConn struct {
+		io.ReadWriteCloser
+		timeout   time.Duration
+		timer     *time.Timer
+		closeOnce sync.Once
+	}
+)
+
+func newIdleTimeoutConn(conn io.ReadWriteCloser, timeout time.Duration) *idleTimeoutConn {
+	c := &idleTimeoutConn{
+		ReadWriteCloser: conn,
+		timeout:         timeout,
+	}
+	c.timer = time.AfterFunc(timeout, func() {
+		c.Close()
+	})
+	return c
+}
+
+func (c *idleTimeoutConn) Read(p []byte) (int, error) {
+	n, err := c.ReadWriteCloser.Read(p)
+	c.timer.Reset(c.timeout)
+	return n, err
+}
+
+func (c *idleTimeoutConn) Write(p []byte) (int, error) {
+	n, err := c.ReadWriteCloser.Write(p)
+	c.timer.Reset(c.timeout)
+	return n, err
+}
+
+func (c *idleTimeoutConn) Close() error {
+	var err error
+	c.closeOnce.Do(func() {
+		c.timer.Stop()
+		err = c.ReadWriteCloser.Close()
+	})
+	return err
+}
+
+// isWebsocketUpgrade checks whether the request is a websocket handshake.
+func isWebsocketUpgrade(req *http.Request) bool {
+	if !strings.EqualFold(req.Header.Get("Upgrade"), "websocket") {
+		return false
+	}
+	for _, v := range req.Header["Connection"] {
+		for _, token := range strings.Split(v, ",") {
+			if strings.EqualFold(strings.TrimSpace(token), "upgrade") {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// isWebsocketAllowed returns true if the request is a websocket handshake
+// and the http trigger of the function handler allows websocket.
+func (fh *functionHandler) isWebsocketAllowed(req *http.Request) bool {
+	return fh.httpTrigger != nil && fh.httpTrigger.Spec.AllowWebsocket && isWebsocketUpgrade(req)
+}
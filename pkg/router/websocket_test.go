@@ -0,0 +1,59 @@
+/*
+Copyright 2019 The Fission Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package router
+
+import (
+	"net"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIsWebsocketUpgrade(t *testing.T) {
+	req, err := http.NewRequest(http.MethodGet, "http://example.com/ws", nil)
+	assert.Nil(t, err)
+	assert.False(t, isWebsocketUpgrade(req))
+
+	req.Header.Set("Upgrade", "websocket")
+	assert.False(t, isWebsocketUpgrade(req))
+
+	req.Header.Set("Connection", "keep-alive, Upgrade")
+	assert.True(t, isWebsocketUpgrade(req))
+
+	req.Header.Set("Upgrade", "h2c")
+	assert.False(t, isWebsocketUpgrade(req))
+}
+
+func TestIdleTimeoutConn(t *testing.T) {
+	server, client := net.Pipe()
+	defer client.Close()
+
+	conn := newIdleTimeoutConn(server, 100*time.Millisecond)
+
+	// traffic keeps the connection open
+	go client.Write([]byte("ping"))
+	buf := make([]byte, 4)
+	_, err := conn.Read(buf)
+	assert.Nil(t, err)
+
+	// the connection is closed once idle
+	time.Sleep(200 * time.Millisecond)
+	_, err = conn.Read(buf)
+	assert.NotNil(t, err)
+}
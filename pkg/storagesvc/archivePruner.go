@@ -22,6 +22,7 @@ import (
 	"go.uber.org/zap"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 
+	fv1 "github.com/fission/fission/pkg/apis/fission.io/v1"
 	"github.com/fission/fission/pkg/crd"
 )
 
@@ -101,7 +102,8 @@ func (pruner *ArchivePruner) getOrphanArchives() {
 			}
 			archivesRefByPkgs = append(archivesRefByPkgs, archiveID)
 		}
-		if pkg.Spec.Source.URL != "" {
+		// git sources are not stored in storagesvc
+		if pkg.Spec.Source.URL != "" && pkg.Spec.Source.Type != fv1.ArchiveTypeGit {
 			archiveID, err = getQueryParamValue(pkg.Spec.Source.URL, "id")
 			if err != nil {
 				pruner.logger.Error("error extracting value of archiveID from source url",
@@ -111,6 +113,29 @@ func (pruner *ArchivePruner) getOrphanArchives() {
 			}
 			archivesRefByPkgs = append(archivesRefByPkgs, archiveID)
 		}
+		if pkg.Status.BuildLogURL != "" {
+			archiveID, err = getQueryParamValue(pkg.Status.BuildLogURL, "id")
+			if err != nil {
+				pruner.logger.Error("error extracting value of archiveID from build log url",
+					zap.Error(err),
+					zap.String("url", pkg.Status.BuildLogURL))
+				return
+			}
+			archivesRefByPkgs = append(archivesRefByPkgs, archiveID)
+		}
+		for _, attempt := range pkg.Status.BuildAttempts {
+			if attempt.BuildLogURL == "" || attempt.BuildLogURL == pkg.Status.BuildLogURL {
+				continue
+			}
+			archiveID, err = getQueryParamValue(attempt.BuildLogURL, "id")
+			if err != nil {
+				pruner.logger.Error("error extracting value of archiveID from build attempt log url",
+					zap.Error(err),
+					zap.String("url", attempt.BuildLogURL))
+				return
+			}
+			archivesRefByPkgs = append(archivesRefByPkgs, archiveID)
+		}
 	}
 
 	pruner.logger.Debug("archives referenced by packagese", zap.Strings("archives", archivesRefByPkgs))
@@ -0,0 +1,513 @@
+/*
+Copyright 2017 The Fission Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package storagesvc
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	EncryptionTypeSecret = "secret"
+	EncryptionTypeVault  = "vault"
+
+	// encryptionMagic starts the encrypted archives, archives without it
+	// were stored before encryption was enabled and are served as is.
+	encryptionMagic = "FISSENC1"
+
+	// archives are encrypted in chunks, so that they are streamed and
+	// no unauthenticated plaintext is ever served
+	encryptionChunkSize = 64 * 1024
+
+	// the nonce of a chunk is a random prefix of the archive, the chunk
+	// counter and the last chunk flag, so that chunks can't be reordered
+	// and the archive can't be truncated
+	noncePrefixSize = 7
+
+	// chunkHeaderSize is the last chunk flag and the ciphertext length
+	chunkHeaderSize = 1 + 4
+
+	dataKeySize         = 32
+	dataKeyCacheMaxSize = 1024
+)
+
+var ErrArchiveEncrypted = errors.New("archive is encrypted, but archive encryption isn't configured")
+
+type (
+	// keyProvider generates the data keys archives are encrypted with,
+	// and decrypts them. The data key of an archive is stored with it,
+	// encrypted by a key that never leaves the Secret or the KMS.
+	keyProvider interface {
+		// generateDataKey returns a new data key and its encrypted form.
+		generateDataKey() (key []byte, encryptedKey []byte, err error)
+
+		// decryptDataKey returns the data key of its encrypted form.
+		decryptDataKey(encryptedKey []byte) ([]byte, error)
+	}
+
+	// dataKey is the key of an archive and its encrypted form.
+	dataKey struct {
+		key       []byte
+		encrypted []byte
+	}
+
+	// archiveEncrypter encrypts the archives with AES-GCM.
+	archiveEncrypter struct {
+		keys keyProvider
+
+		// decrypted data keys, so that the KMS isn't called for every
+		// download of an archive
+		cacheLock sync.Mutex
+		cache     map[string][]byte
+	}
+
+	// secretKeyProvider encrypts the data keys with the keys of a
+	// Kubernetes Secret mounted to a directory. The ID of the key is
+	// stored with the data key, so that keys can be rotated by adding a
+	// new key and making it the active one.
+	secretKeyProvider struct {
+		keys      map[string][]byte
+		activeKey string
+	}
+
+	// vaultKeyProvider generates and decrypts the data keys with a key
+	// of the Vault Transit secrets engine.
+	vaultKeyProvider struct {
+		address   string
+		key       string
+		token     string
+		tokenFile string
+		client    *http.Client
+	}
+)
+
+// makeArchiveEncrypterFromEnv returns the archive encrypter configured by
+// the environment variables of storagesvc, or nil if encryption is
+// disabled.
+func makeArchiveEncrypterFromEnv() (*archiveEncrypter, error) {
+	var keys keyProvider
+	var err error
+	switch encryptionType := os.Getenv("ARCHIVE_ENCRYPTION"); encryptionType {
+	case "":
+		return nil, nil
+	case EncryptionTypeSecret:
+		keys, err = makeSecretKeyProvider(os.Getenv("ARCHIVE_ENCRYPTION_KEY_DIR"), os.Getenv("ARCHIVE_ENCRYPTION_ACTIVE_KEY"))
+	case EncryptionTypeVault:
+		keys, err = makeVaultKeyProvider(os.Getenv("VAULT_ADDR"), os.Getenv("VAULT_TRANSIT_KEY"),
+			os.Getenv("VAULT_TOKEN"), os.Getenv("VAULT_TOKEN_FILE"))
+	default:
+		return nil, fmt.Errorf("unsupported archive encryption type %q, use %v or %v", encryptionType, EncryptionTypeSecret, EncryptionTypeVault)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return makeArchiveEncrypter(keys), nil
+}
+
+func makeArchiveEncrypter(keys keyProvider) *archiveEncrypter {
+	return &archiveEncrypter{
+		keys:  keys,
+		cache: make(map[string][]byte),
+	}
+}
+
+func (e *archiveEncrypter) newDataKey() (*dataKey, error) {
+	key, encrypted, err := e.keys.generateDataKey()
+	if err != nil {
+		return nil, errors.Wrap(err, "error generating data key")
+	}
+	if len(key) != dataKeySize {
+		return nil, fmt.Errorf("data key must be %v bytes, got %v", dataKeySize, len(key))
+	}
+	if len(encrypted) > 0xffff {
+		return nil, errors.New("encrypted data key is too long")
+	}
+	return &dataKey{key: key, encrypted: encrypted}, nil
+}
+
+func (e *archiveEncrypter) getDataKey(encrypted []byte) ([]byte, error) {
+	e.cacheLock.Lock()
+	key, ok := e.cache[string(encrypted)]
+	e.cacheLock.Unlock()
+	if ok {
+		return key, nil
+	}
+
+	key, err := e.keys.decryptDataKey(encrypted)
+	if err != nil {
+		return nil, errors.Wrap(err, "error decrypting data key")
+	}
+
+	e.cacheLock.Lock()
+	if len(e.cache) >= dataKeyCacheMaxSize {
+		e.cache = make(map[string][]byte)
+	}
+	e.cache[string(encrypted)] = key
+	e.cacheLock.Unlock()
+	return key, nil
+}
+
+// encryptedSize returns the size of an encrypted archive.
+func encryptedSize(plainSize int64, dk *dataKey) int64 {
+	chunks := plainSize / encryptionChunkSize
+	if plainSize%encryptionChunkSize != 0 || plainSize == 0 {
+		chunks++
+	}
+	header := int64(len(encryptionMagic) + 2 + len(dk.encrypted) + noncePrefixSize)
+	return header + plainSize + chunks*int64(chunkHeaderSize+16)
+}
+
+func chunkNonce(prefix []byte, counter uint32, last bool) []byte {
+	nonce := make([]byte, noncePrefixSize+5)
+	copy(nonce, prefix)
+	binary.BigEndian.PutUint32(nonce[noncePrefixSize:], counter)
+	if last {
+		nonce[noncePrefixSize+4] = 1
+	}
+	return nonce
+}
+
+func newGCM(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// readChunk reads a chunk of plaintext, the chunk is only shorter than
+// buf at the end of r.
+func readChunk(r io.Reader, buf []byte) (int, error) {
+	n, err := io.ReadFull(r, buf)
+	if err == io.EOF || err == io.ErrUnexpectedEOF {
+		return n, nil
+	}
+	return n, err
+}
+
+// encrypt writes the archive read from r encrypted with the data key to w.
+func (e *archiveEncrypter) encrypt(w io.Writer, r io.Reader, dk *dataKey) error {
+	aead, err := newGCM(dk.key)
+	if err != nil {
+		return err
+	}
+	prefix := make([]byte, noncePrefixSize)
+	if _, err := rand.Read(prefix); err != nil {
+		return err
+	}
+
+	header := bytes.NewBufferString(encryptionMagic)
+	binary.Write(header, binary.BigEndian, uint16(len(dk.encrypted)))
+	header.Write(dk.encrypted)
+	header.Write(prefix)
+	if _, err := w.Write(header.Bytes()); err != nil {
+		return err
+	}
+
+	buf := make([]byte, encryptionChunkSize)
+	next := make([]byte, encryptionChunkSize)
+	n, err := readChunk(r, buf)
+	for counter := uint32(0); ; counter++ {
+		if err != nil {
+			return err
+		}
+
+		// a full chunk is the last one if nothing follows it
+		m := 0
+		last := n < encryptionChunkSize
+		if !last {
+			m, err = readChunk(r, next)
+			last = m == 0 && err == nil
+		}
+
+		sealed := aead.Seal(nil, chunkNonce(prefix, counter, last), buf[:n], nil)
+		chunkHeader := make([]byte, chunkHeaderSize)
+		if last {
+			chunkHeader[0] = 1
+		}
+		binary.BigEndian.PutUint32(chunkHeader[1:], uint32(len(sealed)))
+		if _, werr := w.Write(append(chunkHeader, sealed...)); werr != nil {
+			return werr
+		}
+		if last {
+			return nil
+		}
+		if counter == ^uint32(0) {
+			return errors.New("archive is too large to encrypt")
+		}
+		buf, next, n = next, buf, m
+	}
+}
+
+// isEncrypted returns whether the archive read from r is encrypted.
+func isEncrypted(r *bufio.Reader) bool {
+	magic, err := r.Peek(len(encryptionMagic))
+	return err == nil && string(magic) == encryptionMagic
+}
+
+// decrypt writes the archive read from r decrypted to w. A chunk is only
+// written after it's authenticated, a tampered or truncated archive fails
+// with an error after the chunks before it are written.
+func (e *archiveEncrypter) decrypt(w io.Writer, r io.Reader) error {
+	header := make([]byte, len(encryptionMagic)+2)
+	if _, err := io.ReadFull(r, header); err != nil {
+		return errors.Wrap(err, "error reading archive header")
+	}
+	if string(header[:len(encryptionMagic)]) != encryptionMagic {
+		return errors.New("archive is not encrypted")
+	}
+	encrypted := make([]byte, binary.BigEndian.Uint16(header[len(encryptionMagic):]))
+	if _, err := io.ReadFull(r, encrypted); err != nil {
+		return errors.Wrap(err, "error reading archive header")
+	}
+	prefix := make([]byte, noncePrefixSize)
+	if _, err := io.ReadFull(r, prefix); err != nil {
+		return errors.Wrap(err, "error reading archive header")
+	}
+
+	key, err := e.getDataKey(encrypted)
+	if err != nil {
+		return err
+	}
+	aead, err := newGCM(key)
+	if err != nil {
+		return err
+	}
+
+	chunkHeader := make([]byte, chunkHeaderSize)
+	ciphertext := make([]byte, encryptionChunkSize+aead.Overhead())
+	for counter := uint32(0); ; counter++ {
+		if _, err := io.ReadFull(r, chunkHeader); err != nil {
+			return errors.Wrap(err, "archive is truncated")
+		}
+		last := chunkHeader[0] == 1
+		size := binary.BigEndian.Uint32(chunkHeader[1:])
+		if size > uint32(len(ciphertext)) {
+			return errors.New("archive chunk is too large")
+		}
+		if _, err := io.ReadFull(r, ciphertext[:size]); err != nil {
+			return errors.Wrap(err, "archive is truncated")
+		}
+
+		plaintext, err := aead.Open(ciphertext[:0], chunkNonce(prefix, counter, last), ciphertext[:size], nil)
+		if err != nil {
+			return errors.Wrap(err, "error authenticating archive chunk")
+		}
+		if _, err := w.Write(plaintext); err != nil {
+			return err
+		}
+		if last {
+			return nil
+		}
+	}
+}
+
+func makeSecretKeyProvider(dir string, activeKey string) (*secretKeyProvider, error) {
+	if len(dir) == 0 {
+		return nil, errors.New("need the directory of the archive encryption keys")
+	}
+	files, err := ioutil.ReadDir(dir)
+	if err != nil {
+		return nil, errors.Wrap(err, "error reading archive encryption keys")
+	}
+
+	p := &secretKeyProvider{
+		keys:      make(map[string][]byte),
+		activeKey: activeKey,
+	}
+	for _, f := range files {
+		// skip the internal files of the volume of a mounted secret
+		if strings.HasPrefix(f.Name(), "..") || f.IsDir() {
+			continue
+		}
+		content, err := ioutil.ReadFile(filepath.Join(dir, f.Name()))
+		if err != nil {
+			return nil, errors.Wrapf(err, "error reading archive encryption key %v", f.Name())
+		}
+		key, err := parseKey(content)
+		if err != nil {
+			return nil, errors.Wrapf(err, "error parsing archive encryption key %v", f.Name())
+		}
+		p.keys[f.Name()] = key
+	}
+
+	if len(p.activeKey) == 0 {
+		if len(p.keys) != 1 {
+			return nil, fmt.Errorf("found %v archive encryption keys, set the active one", len(p.keys))
+		}
+		for name := range p.keys {
+			p.activeKey = name
+		}
+	}
+	if _, ok := p.keys[p.activeKey]; !ok {
+		return nil, fmt.Errorf("active archive encryption key %v not found", p.activeKey)
+	}
+	return p, nil
+}
+
+// parseKey parses a 32 byte AES key, raw or base64 encoded.
+func parseKey(content []byte) ([]byte, error) {
+	if len(content) == dataKeySize {
+		return content, nil
+	}
+	key, err := base64.StdEncoding.DecodeString(strings.TrimSpace(string(content)))
+	if err != nil || len(key) != dataKeySize {
+		return nil, fmt.Errorf("key must be %v bytes, raw or base64 encoded", dataKeySize)
+	}
+	return key, nil
+}
+
+// generateDataKey encrypts a random data key with the active key, the
+// encrypted form is the key name length, key name, nonce and ciphertext.
+func (p *secretKeyProvider) generateDataKey() ([]byte, []byte, error) {
+	key := make([]byte, dataKeySize)
+	if _, err := rand.Read(key); err != nil {
+		return nil, nil, err
+	}
+	aead, err := newGCM(p.keys[p.activeKey])
+	if err != nil {
+		return nil, nil, err
+	}
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, nil, err
+	}
+
+	encrypted := []byte{byte(len(p.activeKey))}
+	encrypted = append(encrypted, p.activeKey...)
+	encrypted = append(encrypted, nonce...)
+	encrypted = aead.Seal(encrypted, nonce, key, []byte(p.activeKey))
+	return key, encrypted, nil
+}
+
+func (p *secretKeyProvider) decryptDataKey(encrypted []byte) ([]byte, error) {
+	if len(encrypted) == 0 || len(encrypted) < 1+int(encrypted[0]) {
+		return nil, errors.New("malformed data key")
+	}
+	name := string(encrypted[1 : 1+int(encrypted[0])])
+	kek, ok := p.keys[name]
+	if !ok {
+		return nil, fmt.Errorf("archive encryption key %v not found", name)
+	}
+	aead, err := newGCM(kek)
+	if err != nil {
+		return nil, err
+	}
+	rest := encrypted[1+len(name):]
+	if len(rest) < aead.NonceSize() {
+		return nil, errors.New("malformed data key")
+	}
+	return aead.Open(nil, rest[:aead.NonceSize()], rest[aead.NonceSize():], []byte(name))
+}
+
+func makeVaultKeyProvider(address string, key string, token string, tokenFile string) (*vaultKeyProvider, error) {
+	if len(address) == 0 || len(key) == 0 {
+		return nil, errors.New("need the Vault address and the name of the transit key")
+	}
+	if len(token) == 0 && len(tokenFile) == 0 {
+		return nil, errors.New("need a Vault token or token file")
+	}
+	return &vaultKeyProvider{
+		address:   strings.TrimSuffix(address, "/"),
+		key:       key,
+		token:     token,
+		tokenFile: tokenFile,
+		client:    &http.Client{Timeout: 10 * time.Second},
+	}, nil
+}
+
+// call calls an endpoint of the transit secrets engine and returns the
+// base64 plaintext and the ciphertext of the response.
+func (p *vaultKeyProvider) call(endpoint string, body interface{}) (string, string, error) {
+	token := p.token
+	if len(p.tokenFile) > 0 {
+		// the token file is read for every call, the token may be renewed
+		content, err := ioutil.ReadFile(p.tokenFile)
+		if err != nil {
+			return "", "", errors.Wrap(err, "error reading Vault token")
+		}
+		token = strings.TrimSpace(string(content))
+	}
+
+	payload, err := json.Marshal(body)
+	if err != nil {
+		return "", "", err
+	}
+	req, err := http.NewRequest(http.MethodPost, fmt.Sprintf("%v/v1/transit/%v/%v", p.address, endpoint, p.key), bytes.NewReader(payload))
+	if err != nil {
+		return "", "", err
+	}
+	req.Header.Set("X-Vault-Token", token)
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return "", "", errors.Wrap(err, "error calling Vault")
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return "", "", fmt.Errorf("Vault returned status %v", resp.StatusCode)
+	}
+
+	var result struct {
+		Data struct {
+			Plaintext  string `json:"plaintext"`
+			Ciphertext string `json:"ciphertext"`
+		} `json:"data"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return "", "", errors.Wrap(err, "error decoding Vault response")
+	}
+	return result.Data.Plaintext, result.Data.Ciphertext, nil
+}
+
+func (p *vaultKeyProvider) generateDataKey() ([]byte, []byte, error) {
+	plaintext, ciphertext, err := p.call("datakey/plaintext", map[string]int{"bits": dataKeySize * 8})
+	if err != nil {
+		return nil, nil, err
+	}
+	key, err := base64.StdEncoding.DecodeString(plaintext)
+	if err != nil {
+		return nil, nil, errors.Wrap(err, "error decoding data key")
+	}
+	return key, []byte(ciphertext), nil
+}
+
+func (p *vaultKeyProvider) decryptDataKey(encrypted []byte) ([]byte, error) {
+	plaintext, _, err := p.call("decrypt", map[string]string{"ciphertext": string(encrypted)})
+	if err != nil {
+		return nil, err
+	}
+	return base64.StdEncoding.DecodeString(plaintext)
+}
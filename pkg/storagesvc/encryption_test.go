@@ -0,0 +1,101 @@
+/*
+Copyright 2017 The Fission Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package storagesvc
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/rand"
+	"encoding/base64"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func makeTestEncrypter(t *testing.T, keys map[string]string, activeKey string) *archiveEncrypter {
+	dir, err := ioutil.TempDir("", "archive-keys")
+	assert.Nil(t, err)
+	defer os.RemoveAll(dir)
+	for name, key := range keys {
+		assert.Nil(t, ioutil.WriteFile(filepath.Join(dir, name), []byte(key), 0600))
+	}
+	p, err := makeSecretKeyProvider(dir, activeKey)
+	assert.Nil(t, err)
+	return makeArchiveEncrypter(p)
+}
+
+func randomKey(t *testing.T) string {
+	key := make([]byte, dataKeySize)
+	_, err := rand.Read(key)
+	assert.Nil(t, err)
+	return base64.StdEncoding.EncodeToString(key)
+}
+
+func TestArchiveEncryption(t *testing.T) {
+	e := makeTestEncrypter(t, map[string]string{"key1": randomKey(t)}, "")
+
+	for _, size := range []int{0, 1, encryptionChunkSize, 3*encryptionChunkSize + 17} {
+		plaintext := make([]byte, size)
+		_, err := rand.Read(plaintext)
+		assert.Nil(t, err)
+
+		dk, err := e.newDataKey()
+		assert.Nil(t, err)
+		var encrypted bytes.Buffer
+		assert.Nil(t, e.encrypt(&encrypted, bytes.NewReader(plaintext), dk))
+		assert.Equal(t, encryptedSize(int64(size), dk), int64(encrypted.Len()))
+		assert.True(t, isEncrypted(bufio.NewReader(bytes.NewReader(encrypted.Bytes()))))
+
+		var decrypted bytes.Buffer
+		assert.Nil(t, e.decrypt(&decrypted, bytes.NewReader(encrypted.Bytes())))
+		assert.True(t, bytes.Equal(plaintext, decrypted.Bytes()))
+
+		// tampered and truncated archives are rejected
+		tampered := append([]byte{}, encrypted.Bytes()...)
+		tampered[len(tampered)-1] ^= 1
+		assert.NotNil(t, e.decrypt(ioutil.Discard, bytes.NewReader(tampered)))
+		if size > encryptionChunkSize {
+			truncated := encrypted.Bytes()[:encrypted.Len()-(17+chunkHeaderSize+16)]
+			assert.NotNil(t, e.decrypt(ioutil.Discard, bytes.NewReader(truncated)))
+		}
+	}
+
+	// plaintext archives aren't taken for encrypted ones
+	assert.False(t, isEncrypted(bufio.NewReader(bytes.NewReader([]byte("PK\x03\x04")))))
+}
+
+func TestSecretKeyRotation(t *testing.T) {
+	key1, key2 := randomKey(t), randomKey(t)
+	old := makeTestEncrypter(t, map[string]string{"key1": key1}, "")
+	dk, err := old.newDataKey()
+	assert.Nil(t, err)
+	var encrypted bytes.Buffer
+	assert.Nil(t, old.encrypt(&encrypted, bytes.NewReader([]byte("source")), dk))
+
+	// archives encrypted with the previous key are still readable
+	rotated := makeTestEncrypter(t, map[string]string{"key1": key1, "key2": key2}, "key2")
+	var decrypted bytes.Buffer
+	assert.Nil(t, rotated.decrypt(&decrypted, bytes.NewReader(encrypted.Bytes())))
+	assert.Equal(t, "source", decrypted.String())
+
+	// but not once the previous key is removed
+	removed := makeTestEncrypter(t, map[string]string{"key2": key2}, "")
+	assert.NotNil(t, removed.decrypt(ioutil.Discard, bytes.NewReader(encrypted.Bytes())))
+}
@@ -29,6 +29,8 @@ import (
 	_ "github.com/graymeta/stow/local"
 	"go.opencensus.io/plugin/ochttp"
 	"go.uber.org/zap"
+
+	"github.com/fission/fission/pkg/health"
 )
 
 type (
@@ -152,6 +154,8 @@ func (ss *StorageService) downloadHandler(w http.ResponseWriter, r *http.Request
 			http.Error(w, "Error opening item", http.StatusBadRequest)
 		} else if err == ErrWritingFileIntoResponse {
 			http.Error(w, "Error writing response", http.StatusInternalServerError)
+		} else if err == ErrDecryptingItem || err == ErrArchiveEncrypted {
+			http.Error(w, "Error decrypting item", http.StatusInternalServerError)
 		}
 		return
 	}
@@ -176,6 +180,10 @@ func (ss *StorageService) Start(port int) {
 	r.HandleFunc("/v1/archive", ss.deleteHandler).Methods("DELETE")
 	r.HandleFunc("/healthz", ss.healthHandler).Methods("GET")
 
+	checker := health.MakeChecker("storagesvc")
+	checker.AddCheck("storage", ss.storageClient.checkStorage)
+	r.HandleFunc("/readyz", checker.ReadyzHandler).Methods("GET")
+
 	address := fmt.Sprintf(":%v", port)
 
 	err := http.ListenAndServe(address, &ochttp.Handler{
@@ -191,6 +199,13 @@ func RunStorageService(logger *zap.Logger, storageType StorageType, storagePath
 	if err != nil {
 		logger.Fatal("error creating stowClient", zap.Error(err))
 	}
+	storageClient.encrypter, err = makeArchiveEncrypterFromEnv()
+	if err != nil {
+		logger.Fatal("error setting up archive encryption", zap.Error(err))
+	}
+	if storageClient.encrypter != nil {
+		logger.Info("archive encryption enabled", zap.String("type", os.Getenv("ARCHIVE_ENCRYPTION")))
+	}
 
 	// create http handlers
 	storageService := MakeStorageService(logger, storageClient, port)
@@ -17,6 +17,7 @@ limitations under the License.
 package storagesvc
 
 import (
+	"bufio"
 	"io"
 	"mime/multipart"
 	"os"
@@ -44,6 +45,10 @@ type (
 		config    *storageConfig
 		location  stow.Location
 		container stow.Container
+
+		// encrypter encrypts the archives at rest, nil if encryption
+		// is disabled
+		encrypter *archiveEncrypter
 	}
 )
 
@@ -58,6 +63,7 @@ var (
 	ErrOpeningItem             = errors.New("unable to open item")
 	ErrWritingFile             = errors.New("unable to write file")
 	ErrWritingFileIntoResponse = errors.New("unable to copy item into http response")
+	ErrDecryptingItem          = errors.New("unable to decrypt item")
 )
 
 func MakeStowClient(logger *zap.Logger, storageType StorageType, storagePath string, containerName string) (*StowClient, error) {
@@ -113,8 +119,26 @@ func (client *StowClient) putFile(file multipart.File, fileSize int64) (string,
 	// should we just use handler.Filename? what are the constraints here?
 	uploadName := uuid.NewV4().String()
 
+	var r io.Reader = file
+	if client.encrypter != nil {
+		dk, err := client.encrypter.newDataKey()
+		if err != nil {
+			client.logger.Error("error getting archive data key",
+				zap.Error(err),
+				zap.String("file", uploadName))
+			return "", ErrWritingFile
+		}
+		pr, pw := io.Pipe()
+		go func() {
+			pw.CloseWithError(client.encrypter.encrypt(pw, file, dk))
+		}()
+		defer pr.Close()
+		r = pr
+		fileSize = encryptedSize(fileSize, dk)
+	}
+
 	// save the file to the storage backend
-	item, err := client.container.Put(uploadName, file, int64(fileSize), nil)
+	item, err := client.container.Put(uploadName, r, int64(fileSize), nil)
 	if err != nil {
 		client.logger.Error("error writing file on storage",
 			zap.Error(err),
@@ -143,9 +167,22 @@ func (client *StowClient) copyFileToStream(fileId string, w io.Writer) error {
 	}
 	defer f.Close()
 
-	_, err = io.Copy(w, f)
-	if err != nil {
-		return ErrWritingFileIntoResponse
+	// archives stored before encryption was enabled are not encrypted
+	r := bufio.NewReader(f)
+	if isEncrypted(r) {
+		if client.encrypter == nil {
+			return ErrArchiveEncrypted
+		}
+		err = client.encrypter.decrypt(w, r)
+		if err != nil {
+			client.logger.Error("error decrypting file", zap.Error(err), zap.String("file", fileId))
+			return ErrDecryptingItem
+		}
+	} else {
+		_, err = io.Copy(w, r)
+		if err != nil {
+			return ErrWritingFileIntoResponse
+		}
 	}
 
 	client.logger.Debug("successfully wrote file into httpresponse", zap.String("file", fileId))
@@ -157,6 +194,13 @@ func (client *StowClient) removeFileByID(itemID string) error {
 	return client.container.RemoveItem(itemID)
 }
 
+// checkStorage returns an error if the items of the container can't be
+// listed, e.g. the volume or the bucket is unavailable.
+func (client *StowClient) checkStorage() error {
+	_, _, err := client.container.Items(stow.NoPrefix, stow.CursorStart, 1)
+	return err
+}
+
 // filter defines an interface to filter out items from a set of items
 type filter func(stow.Item, interface{}) bool
 
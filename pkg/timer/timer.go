@@ -17,6 +17,8 @@ limitations under the License.
 package timer
 
 import (
+	"time"
+
 	"github.com/robfig/cron"
 	"go.uber.org/zap"
 
@@ -93,8 +95,8 @@ func (timer *Timer) syncCron(triggers []fv1.TimeTrigger) error {
 	for _, t := range triggers {
 		triggerMap[crd.CacheKey(&t.Metadata)] = true
 		if item, ok := timer.triggers[crd.CacheKey(&t.Metadata)]; ok {
-			// update cron if the cron spec changed
-			if item.trigger.Spec.Cron != t.Spec.Cron {
+			// update cron if the cron spec or the time zone changed
+			if item.trigger.Spec.Cron != t.Spec.Cron || item.trigger.Spec.Timezone != t.Spec.Timezone {
 				// if there is an cron running, stop it
 				if item.cron != nil {
 					item.cron.Stop()
@@ -126,7 +128,22 @@ func (timer *Timer) syncCron(triggers []fv1.TimeTrigger) error {
 }
 
 func (timer *Timer) newCron(t fv1.TimeTrigger) *cron.Cron {
-	c := cron.New()
+	// The schedule is evaluated in the time zone of the trigger, so
+	// the invocations follow the wall clock of that zone across DST changes.
+	loc := time.UTC
+	if len(t.Spec.Timezone) > 0 {
+		var err error
+		loc, err = time.LoadLocation(t.Spec.Timezone)
+		if err != nil {
+			timer.logger.Error("failed to load time zone of time trigger - cron not added",
+				zap.Error(err),
+				zap.String("trigger", t.Metadata.Name),
+				zap.String("timezone", t.Spec.Timezone))
+			return nil
+		}
+	}
+
+	c := cron.NewWithLocation(loc)
 	c.AddFunc(t.Spec.Cron, func() {
 		headers := map[string]string{
 			"X-Fission-Timer-Name": t.Metadata.Name,
@@ -138,6 +155,6 @@ func (timer *Timer) newCron(t fv1.TimeTrigger) *cron.Cron {
 		(*timer.publisher).Publish("", headers, utils.UrlForFunction(t.Spec.FunctionReference.Name, t.Metadata.Namespace))
 	})
 	c.Start()
-	timer.logger.Info("added new cron for time trigger", zap.String("trigger", t.Metadata.Name))
+	timer.logger.Info("added new cron for time trigger", zap.String("trigger", t.Metadata.Name), zap.String("timezone", loc.String()))
 	return c
 }
@@ -17,6 +17,9 @@ limitations under the License.
 package types
 
 import (
+	"encoding/json"
+	"time"
+
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 
 	fv1 "github.com/fission/fission/pkg/apis/fission.io/v1"
@@ -66,6 +69,13 @@ type (
 		FunctionMetadata *metav1.ObjectMeta
 
 		EnvVersion int `json:"envVersion"`
+
+		// Checksum of the file fetched into FilePath, unset if it's
+		// a directory. Runtimes supporting the checksum handshake
+		// return the SHA-256 of the file they load in the
+		// ChecksumHeader of the specialize response, so that fetcher
+		// knows the runtime loaded the same file. Optional.
+		Checksum *fv1.Checksum `json:"checksum,omitempty"`
 	}
 
 	// ArchiveUploadRequest send from builder manager describes which
@@ -82,12 +92,221 @@ type (
 		ArchiveDownloadUrl string       `json:"archiveDownloadUrl"`
 		Checksum           fv1.Checksum `json:"checksum"`
 	}
+
+	// PackageBuildLog is the full build log of a package, stored in the
+	// storage service and referenced by the package status.
+	PackageBuildLog struct {
+		Steps []PackageBuildLogStep `json:"steps"`
+	}
+
+	// PackageBuildLogStep is the log of a single step of a package build.
+	PackageBuildLogStep struct {
+		Name string `json:"name"`
+		Log  string `json:"log"`
+	}
+
+	// FunctionProfileRequest asks the executor to capture a profile of
+	// the specialized pods of a function.
+	FunctionProfileRequest struct {
+		Function metav1.ObjectMeta `json:"function"`
+
+		// Type of the profile, e.g. cpu or heap. The environment
+		// decides which types are supported.
+		Type string `json:"type"`
+
+		// Seconds is the duration of the capture.
+		Seconds int `json:"seconds"`
+	}
+
+	// FunctionProfile is the profile captured from a single pod.
+	FunctionProfile struct {
+		Pod         string `json:"pod"`
+		ContentType string `json:"contentType,omitempty"`
+		Data        []byte `json:"data,omitempty"`
+		Error       string `json:"error,omitempty"`
+	}
+
+	FunctionProfileResponse struct {
+		Profiles []FunctionProfile `json:"profiles"`
+	}
+
+	// FunctionMetrics summarizes the invocations of a function over a time
+	// window, as recorded by Prometheus.
+	FunctionMetrics struct {
+		Window      time.Duration `json:"window"`
+		Invocations float64       `json:"invocations"`
+		Errors      float64       `json:"errors"`
+		ColdStarts  float64       `json:"coldStarts"`
+
+		// LatencyP50 to LatencyP99 are the highest quantiles of the
+		// function duration in seconds reported in the window, 0 if
+		// there's no data.
+		LatencyP50 float64 `json:"latencyP50"`
+		LatencyP90 float64 `json:"latencyP90"`
+		LatencyP95 float64 `json:"latencyP95"`
+		LatencyP99 float64 `json:"latencyP99"`
+
+		// InvocationSeries is the number of invocations in each of the
+		// equal intervals of the window, oldest first.
+		InvocationSeries []float64 `json:"invocationSeries,omitempty"`
+	}
+
+	// FunctionStatsCounts are the invocations of a function recorded by a
+	// router instance over a time window.
+	FunctionStatsCounts struct {
+		Invocations uint64 `json:"invocations"`
+		Errors      uint64 `json:"errors"`
+		ColdStarts  uint64 `json:"coldStarts"`
+
+		// LatencyBounds are the upper bounds in seconds of the latency
+		// buckets, LatencyCounts has one more bucket for the invocations
+		// slower than the last bound.
+		LatencyBounds []float64 `json:"latencyBounds"`
+		LatencyCounts []uint64  `json:"latencyCounts"`
+	}
+
+	// FunctionStats summarizes the invocations of a function over a time
+	// window, as recorded by the routers.
+	FunctionStats struct {
+		Window      time.Duration `json:"window"`
+		Invocations uint64        `json:"invocations"`
+		Errors      uint64        `json:"errors"`
+		ColdStarts  uint64        `json:"coldStarts"`
+
+		// LatencyP50, LatencyP95 and LatencyP99 are the quantiles of the
+		// function duration in seconds, 0 if there's no invocation.
+		LatencyP50 float64 `json:"latencyP50"`
+		LatencyP95 float64 `json:"latencyP95"`
+		LatencyP99 float64 `json:"latencyP99"`
+
+		// Routers is the number of router instances the stats were
+		// collected from, Unreachable the number of the ones that
+		// couldn't be queried.
+		Routers     int `json:"routers"`
+		Unreachable int `json:"unreachable,omitempty"`
+	}
+
+	// PackageRefreshStrategy decides when functions pick up a rebuilt package.
+	PackageRefreshStrategy string
+
+	// PackageRefreshRequest asks the executor to replace the pods of the
+	// functions using a package, so that they run the rebuilt package.
+	PackageRefreshRequest struct {
+		Package  metav1.ObjectMeta      `json:"package"`
+		Strategy PackageRefreshStrategy `json:"strategy"`
+	}
+
+	// PackageRefreshResponse lists the functions refreshed, or marked to be
+	// refreshed, by the executor.
+	PackageRefreshResponse struct {
+		Functions []string `json:"functions"`
+	}
+
+	// AuditEvent records a create, update or delete of a resource
+	// through the controller API.
+	AuditEvent struct {
+		ID   string    `json:"id"`
+		Time time.Time `json:"time"`
+		// ClientUser is the user the client asserts in the
+		// AuditUserHeader. The controller doesn't authenticate clients,
+		// so it's informational and can't be trusted.
+		ClientUser string `json:"clientUser"`
+		RemoteAddr string `json:"remoteAddr"`
+		Operation  string `json:"operation"`
+		Kind       string `json:"kind"`
+		Namespace  string `json:"namespace"`
+		Name       string `json:"name"`
+		// OldObject is the resource before an update or delete, NewObject
+		// the resource of a create or update as sent by the client.
+		OldObject json.RawMessage `json:"oldObject,omitempty"`
+		NewObject json.RawMessage `json:"newObject,omitempty"`
+		// Truncated is set if the objects were too large to be recorded.
+		Truncated bool `json:"truncated,omitempty"`
+	}
+
+	// LogDBInfo lists the types of log databases the controller can
+	// query function logs from.
+	LogDBInfo struct {
+		// Default is used if the client doesn't pick a type.
+		Default string   `json:"default"`
+		Types   []string `json:"types"`
+	}
+
+	// FeatureInfo lists the optional features and components installed
+	// along with the controller, so that clients can check them before
+	// creating resources that depend on them.
+	FeatureInfo struct {
+		LogDB LogDBInfo `json:"logDB"`
+		// MessageQueueTypes are the message queue types with a
+		// deployed trigger, nil if unknown.
+		MessageQueueTypes []string `json:"messageQueueTypes"`
+		Canary            bool     `json:"canary"`
+		Recorder          bool     `json:"recorder"`
+	}
+)
+
+const (
+	AuditOperationCreate = "create"
+	AuditOperationUpdate = "update"
+	AuditOperationDelete = "delete"
+
+	// AuditUserHeader carries the user of the CLI in requests to the
+	// controller, for the audit log.
+	AuditUserHeader = "X-Fission-User"
+)
+
+const (
+	// PackageRefreshImmediate replaces the pods of the functions right away.
+	PackageRefreshImmediate PackageRefreshStrategy = "immediate"
+
+	// PackageRefreshLazy replaces the pods of a function when the router
+	// next asks the executor for the function service, i.e. on the next
+	// request after the router cache of the function expires.
+	PackageRefreshLazy PackageRefreshStrategy = "lazy"
+)
+
+const (
+	// ProfileTypeCPU is served by environments at /debug/pprof/profile,
+	// other profile types at /debug/pprof/<type>.
+	ProfileTypeCPU = "cpu"
+
+	// MaxProfileSeconds bounds the duration of a profile capture.
+	MaxProfileSeconds = 300
+)
+
+const (
+	// DeadlineTimeoutHeader carries the time in milliseconds the router is
+	// still willing to wait for the service of a function, so that the
+	// executor doesn't specialize pods for requests nobody waits for.
+	DeadlineTimeoutHeader = "X-Fission-Deadline-Timeout-Ms"
+
+	// ColdStartHeader is set by the executor when it specialized a pod to
+	// get the service of a function, i.e. the invocation is a cold start.
+	ColdStartHeader = "X-Fission-Cold-Start"
+)
+
+const (
+	// FunctionLoadPath is where environments that support multiplexing
+	// load another function from the package of an already specialized
+	// pod. The request is a FunctionLoadRequest; requests for the function
+	// are told apart by the X-Fission-Function-Name and
+	// X-Fission-Function-Namespace headers set by the router.
+	FunctionLoadPath = "/v3/load"
+)
+
+const (
+	// ListContinueHeader carries the token to get the next page of a list
+	// from the controller, it's empty on the last page.
+	ListContinueHeader = "X-Fission-Continue"
 )
 
 const (
 	FETCH_SOURCE = iota
 	FETCH_DEPLOYMENT
 	FETCH_URL // remove this?
+	// FETCH_NONE fetches no code, it's baked into the image of a function
+	// with a prebuilt image.
+	FETCH_NONE
 )
 
 const EXECUTOR_INSTANCEID_LABEL = fv1.EXECUTOR_INSTANCEID_LABEL
@@ -104,6 +323,10 @@ const (
 
 	// ArchiveTypeUrl means the package contents are at the specified URL.
 	ArchiveTypeUrl = fv1.ArchiveTypeUrl
+
+	// ArchiveTypeGit means the package contents are in the Git repository
+	// at the specified URL, which is cloned at build time.
+	ArchiveTypeGit = fv1.ArchiveTypeGit
 )
 
 const (
@@ -133,6 +356,7 @@ const (
 const (
 	ExecutorTypePoolmgr   = fv1.ExecutorTypePoolmgr
 	ExecutorTypeNewdeploy = fv1.ExecutorTypeNewdeploy
+	ExecutorTypeContainer = fv1.ExecutorTypeContainer
 )
 
 const (
@@ -147,9 +371,38 @@ const (
 )
 
 const (
-	MessageQueueTypeNats  = fv1.MessageQueueTypeNats
-	MessageQueueTypeASQ   = fv1.MessageQueueTypeASQ
-	MessageQueueTypeKafka = fv1.MessageQueueTypeKafka
+	// RuntimeSocketEnv is the environment variable of the runtime
+	// container with the path of the unix socket to serve the
+	// specialize request on, besides port 8888. Fetcher prefers the
+	// socket once it exists.
+	RuntimeSocketEnv = "FISSION_RUNTIME_SOCKET"
+
+	// RuntimeSocketName is the name of the runtime socket in the
+	// shared volume of fetcher and runtime containers.
+	RuntimeSocketName = ".fission-runtime.sock"
+
+	// ChecksumHeader carries the checksum of the loaded package in
+	// the specialize response of the runtime.
+	ChecksumHeader = "X-Fission-Checksum"
+)
+
+const (
+	// The environment variables of the log forwarder sidecar, the pod and
+	// container to forward the logs of and the URL to write them to.
+	LogForwarderPodNameEnv      = "POD_NAME"
+	LogForwarderPodNamespaceEnv = "POD_NAMESPACE"
+	LogForwarderContainerEnv    = "LOG_FORWARDER_CONTAINER"
+	LogForwarderEndpointEnv     = "LOG_FORWARDER_ENDPOINT"
+)
+
+const (
+	MessageQueueTypeNats            = fv1.MessageQueueTypeNats
+	MessageQueueTypeASQ             = fv1.MessageQueueTypeASQ
+	MessageQueueTypeKafka           = fv1.MessageQueueTypeKafka
+	MessageQueueTypeRabbitMQ        = fv1.MessageQueueTypeRabbitMQ
+	MessageQueueTypeGCPPubSub       = fv1.MessageQueueTypeGCPPubSub
+	MessageQueueTypeAzureServiceBus = fv1.MessageQueueTypeAzureServiceBus
+	MessageQueueTypeNatsJetStream   = fv1.MessageQueueTypeNatsJetStream
 )
 
 const (
@@ -160,6 +413,9 @@ const (
 	//   Set of function references (recursively), by percentage of traffic
 	FunctionReferenceTypeFunctionWeights = fv1.FunctionReferenceTypeFunctionWeights
 
+	// Function alias by name
+	FunctionReferenceTypeFunctionAlias = fv1.FunctionReferenceTypeFunctionAlias
+
 	// Other function reference types we'd like to support:
 	//   Versioned function, latest version
 	//   Versioned function. by semver "latest compatible"
@@ -167,9 +423,25 @@ const (
 )
 
 const (
+	// ArchiveLiteralSizeLimit is the default max size of an archive stored
+	// as a literal in a package, the controller may be configured with
+	// another one with ARCHIVE_LITERAL_SIZE_LIMIT.
 	ArchiveLiteralSizeLimit int64 = 256 * 1024
 )
 
+const (
+	BuildStepPrepare = "prepare"
+	BuildStepFetch   = "fetch"
+	BuildStepBuild   = "build"
+	BuildStepUpload  = "upload"
+	BuildStepUpdate  = "update"
+	BuildStepHooks   = "hooks"
+
+	// BuildLogSizeLimit is the max size of the build log kept in the
+	// package status, the full log is kept in the storage service.
+	BuildLogSizeLimit = 16 * 1024
+)
+
 const (
 	FissionBuilderSA = "fission-builder"
 	FissionFetcherSA = "fission-fetcher"
@@ -180,6 +452,9 @@ const (
 	PackageGetterCR = "package-getter"
 	PackageGetterRB = "package-getter-binding"
 
+	PodLogReaderCR = "pod-log-reader"
+	PodLogReaderRB = "pod-log-reader-binding"
+
 	ClusterRole = "ClusterRole"
 )
 
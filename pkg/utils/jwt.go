@@ -0,0 +1,172 @@
+/*
+Copyright 2019 The Fission Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package utils
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/base64"
+	"encoding/json"
+	"hash"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// jwtLeeway is the clock skew tolerated when checking the expiry and
+// not-before times of a token.
+const jwtLeeway = time.Minute
+
+type (
+	// JWTClaims are the registered claims of a JSON Web Token that fission
+	// checks. Tokens may have other claims, they are ignored.
+	JWTClaims struct {
+		Subject   string      `json:"sub,omitempty"`
+		Issuer    string      `json:"iss,omitempty"`
+		Audience  JWTAudience `json:"aud,omitempty"`
+		ExpiresAt int64       `json:"exp,omitempty"`
+		NotBefore int64       `json:"nbf,omitempty"`
+		IssuedAt  int64       `json:"iat,omitempty"`
+	}
+
+	// JWTAudience is the aud claim, a single string or a list of them.
+	JWTAudience []string
+
+	jwtHeader struct {
+		Algorithm string `json:"alg"`
+		Type      string `json:"typ,omitempty"`
+	}
+)
+
+func (aud *JWTAudience) UnmarshalJSON(b []byte) error {
+	var s string
+	if err := json.Unmarshal(b, &s); err == nil {
+		*aud = JWTAudience{s}
+		return nil
+	}
+	var l []string
+	if err := json.Unmarshal(b, &l); err != nil {
+		return errors.New("aud must be a string or a list of strings")
+	}
+	*aud = l
+	return nil
+}
+
+func (aud JWTAudience) MarshalJSON() ([]byte, error) {
+	if len(aud) == 1 {
+		return json.Marshal(aud[0])
+	}
+	return json.Marshal([]string(aud))
+}
+
+// Contains returns whether audience is one of the audiences of the claim.
+func (aud JWTAudience) Contains(audience string) bool {
+	for _, a := range aud {
+		if a == audience {
+			return true
+		}
+	}
+	return false
+}
+
+func jwtHash(alg string) (func() hash.Hash, error) {
+	switch alg {
+	case "HS256":
+		return sha256.New, nil
+	case "HS384":
+		return sha512.New384, nil
+	case "HS512":
+		return sha512.New, nil
+	default:
+		return nil, errors.Errorf("unsupported token algorithm '%v', only HS256, HS384 and HS512 are supported", alg)
+	}
+}
+
+func jwtSignature(h func() hash.Hash, key []byte, signingInput string) []byte {
+	mac := hmac.New(h, key)
+	mac.Write([]byte(signingInput))
+	return mac.Sum(nil)
+}
+
+// SignJWT returns a HS256 JSON Web Token with the claims, signed with
+// the key.
+func SignJWT(claims *JWTClaims, key []byte) (string, error) {
+	header, err := json.Marshal(jwtHeader{Algorithm: "HS256", Type: "JWT"})
+	if err != nil {
+		return "", err
+	}
+	payload, err := json.Marshal(claims)
+	if err != nil {
+		return "", err
+	}
+
+	signingInput := base64.RawURLEncoding.EncodeToString(header) + "." +
+		base64.RawURLEncoding.EncodeToString(payload)
+	signature := jwtSignature(sha256.New, key, signingInput)
+	return signingInput + "." + base64.RawURLEncoding.EncodeToString(signature), nil
+}
+
+// VerifyJWT checks the HMAC signature of a JSON Web Token with the key
+// and that it's valid at the given time, and returns its claims.
+func VerifyJWT(token string, key []byte, now time.Time) (*JWTClaims, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return nil, errors.New("malformed token")
+	}
+
+	b, err := base64.RawURLEncoding.DecodeString(parts[0])
+	if err != nil {
+		return nil, errors.Wrap(err, "malformed token header")
+	}
+	header := jwtHeader{}
+	err = json.Unmarshal(b, &header)
+	if err != nil {
+		return nil, errors.Wrap(err, "malformed token header")
+	}
+	h, err := jwtHash(header.Algorithm)
+	if err != nil {
+		return nil, err
+	}
+
+	signature, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return nil, errors.Wrap(err, "malformed token signature")
+	}
+	if !hmac.Equal(signature, jwtSignature(h, key, parts[0]+"."+parts[1])) {
+		return nil, errors.New("invalid token signature")
+	}
+
+	b, err = base64.RawURLEncoding.DecodeString(parts[1])
+	if err != nil {
+		return nil, errors.Wrap(err, "malformed token claims")
+	}
+	claims := &JWTClaims{}
+	err = json.Unmarshal(b, claims)
+	if err != nil {
+		return nil, errors.Wrap(err, "malformed token claims")
+	}
+
+	if claims.ExpiresAt > 0 && now.After(time.Unix(claims.ExpiresAt, 0).Add(jwtLeeway)) {
+		return nil, errors.New("token expired")
+	}
+	if claims.NotBefore > 0 && now.Add(jwtLeeway).Before(time.Unix(claims.NotBefore, 0)) {
+		return nil, errors.New("token not valid yet")
+	}
+	return claims, nil
+}
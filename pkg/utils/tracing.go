@@ -0,0 +1,71 @@
+/*
+Copyright 2019 The Fission Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package utils
+
+import (
+	"net/http"
+
+	"go.opencensus.io/plugin/ochttp/propagation/b3"
+	"go.opencensus.io/plugin/ochttp/propagation/tracecontext"
+	"go.opencensus.io/trace"
+	"go.opencensus.io/trace/propagation"
+)
+
+// Span attributes shared by fission components.
+const (
+	TraceAttrFunctionName      = "fission.function.name"
+	TraceAttrFunctionNamespace = "fission.function.namespace"
+	TraceAttrColdStart         = "fission.cold_start"
+	TraceAttrExecutorType      = "fission.executor_type"
+	TraceAttrPackageName       = "fission.package.name"
+	TraceAttrPackageNamespace  = "fission.package.namespace"
+)
+
+// TracePropagation reads and writes the span context of HTTP requests in
+// both the W3C Trace Context format, used by OpenTelemetry SDKs, and the B3
+// format, used by earlier fission versions and Zipkin clients.
+var TracePropagation propagation.HTTPFormat = multiTraceFormat{
+	&tracecontext.HTTPFormat{},
+	&b3.HTTPFormat{},
+}
+
+type multiTraceFormat []propagation.HTTPFormat
+
+// SpanContextFromRequest returns the span context of the first format found in the request.
+func (formats multiTraceFormat) SpanContextFromRequest(req *http.Request) (trace.SpanContext, bool) {
+	for _, f := range formats {
+		if sc, ok := f.SpanContextFromRequest(req); ok {
+			return sc, true
+		}
+	}
+	return trace.SpanContext{}, false
+}
+
+// SpanContextToRequest writes the span context to the request in every format.
+func (formats multiTraceFormat) SpanContextToRequest(sc trace.SpanContext, req *http.Request) {
+	for _, f := range formats {
+		f.SpanContextToRequest(sc, req)
+	}
+}
+
+// FunctionTraceAttributes returns the span attributes of a function.
+func FunctionTraceAttributes(name, namespace string) []trace.Attribute {
+	return []trace.Attribute{
+		trace.StringAttribute(TraceAttrFunctionName, name),
+		trace.StringAttribute(TraceAttrFunctionNamespace, namespace),
+	}
+}
@@ -36,6 +36,16 @@ func UrlForFunction(name, namespace string) string {
 	return fmt.Sprintf("%v/%v", prefix, name)
 }
 
+// UrlForFunctionAlias returns the router URL of a function alias, which
+// callers and non-http triggers referencing the alias route into.
+func UrlForFunctionAlias(name, namespace string) string {
+	prefix := "/fission-alias"
+	if namespace != metav1.NamespaceDefault {
+		prefix = fmt.Sprintf("/fission-alias/%s", namespace)
+	}
+	return fmt.Sprintf("%v/%v", prefix, name)
+}
+
 // IsNetworkError returns true if an error is a network error, and false otherwise.
 func IsNetworkError(err error) bool {
 	_, ok := err.(net.Error)